@@ -0,0 +1,404 @@
+// cmd/analyze/main.go
+// 批量复盘：读目录下的对局记录（与 cmd/hexxagon/replay 相同的 JSON：[{winner, steps:[{move}]}]），
+// 对每一手重新给全部着法打分，统计双方的准确率、失误分级，并导出 CSV：
+//
+//	moves.csv    每一手：实际着法、引擎最佳、分差、失误等级
+//	games.csv    每局每方的汇总
+//	summary.csv  全部对局按执子方汇总
+//	puzzles.csv  习题候选：只有一步明显最好（最佳与次佳分差够大）的局面
+//
+// 分数为 α-β 根方视角分（同 cmd/search_tree），损失 = 最佳着法分 − 实际着法分。
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"hexxagon_go/internal/cli"
+	"hexxagon_go/internal/game"
+)
+
+type step struct {
+	Move game.Move `json:"move"`
+}
+
+type match struct {
+	Winner string `json:"winner"`
+	Steps  []step `json:"steps"`
+}
+
+// record 一个文件里的一局
+type record struct {
+	file  string
+	index int // 文件内第几局，从 1 开始
+	match match
+}
+
+// 失误等级，按损失阈值划分
+const (
+	classBest       = "best"
+	classGood       = "good"
+	classInaccuracy = "inaccuracy"
+	classMistake    = "mistake"
+	classBlunder    = "blunder"
+)
+
+type thresholds struct {
+	inaccuracy, mistake, blunder int
+}
+
+func (t thresholds) classify(loss int) string {
+	switch {
+	case loss <= 0:
+		return classBest
+	case loss >= t.blunder:
+		return classBlunder
+	case loss >= t.mistake:
+		return classMistake
+	case loss >= t.inaccuracy:
+		return classInaccuracy
+	}
+	return classGood
+}
+
+// moveResult 一手棋的复盘结果
+type moveResult struct {
+	rec        *record
+	ply        int
+	player     game.CellState
+	position   string
+	played     game.Move
+	best       game.Move
+	playedScr  int
+	bestScr    int
+	secondScr  int // 次佳着法分；只有一步可走时等于 bestScr
+	legalMoves int
+	depth      int // 实际完成的搜索深度
+	class      string
+}
+
+func (m moveResult) loss() int { return m.bestScr - m.playedScr }
+
+// sideStats 一方的汇总
+type sideStats struct {
+	moves, accurate, matched         int
+	lossSum                          int
+	inaccuracies, mistakes, blunders int
+}
+
+func (s *sideStats) add(m moveResult) {
+	s.moves++
+	s.lossSum += m.loss()
+	switch m.class {
+	case classBest:
+		s.matched++
+		s.accurate++
+	case classGood:
+		s.accurate++
+	case classInaccuracy:
+		s.inaccuracies++
+	case classMistake:
+		s.mistakes++
+	case classBlunder:
+		s.blunders++
+	}
+}
+
+// accuracy 损失低于“不精确”阈值的着法占比（%）
+func (s sideStats) accuracy() float64 {
+	if s.moves == 0 {
+		return 0
+	}
+	return 100 * float64(s.accurate) / float64(s.moves)
+}
+
+func (s sideStats) avgLoss() float64 {
+	if s.moves == 0 {
+		return 0
+	}
+	return float64(s.lossSum) / float64(s.moves)
+}
+
+func (s sideStats) fields() []string {
+	return []string{
+		strconv.Itoa(s.moves),
+		fmt.Sprintf("%.1f", s.accuracy()),
+		fmt.Sprintf("%.1f", 100*float64(s.matched)/float64(max(s.moves, 1))),
+		fmt.Sprintf("%.1f", s.avgLoss()),
+		strconv.Itoa(s.inaccuracies),
+		strconv.Itoa(s.mistakes),
+		strconv.Itoa(s.blunders),
+	}
+}
+
+var statsHeader = []string{"moves", "accuracy_pct", "best_match_pct", "avg_loss", "inaccuracies", "mistakes", "blunders"}
+
+// loadRecords 读 dir 下所有 .json；文件内容可以是对局数组，也可以是单局
+func loadRecords(dir string) ([]*record, error) {
+	var out []*record
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".json") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var matches []match
+		if err := json.Unmarshal(data, &matches); err != nil {
+			var one match
+			if err1 := json.Unmarshal(data, &one); err1 != nil {
+				log.Printf("跳过 %s：不是对局记录: %v", path, err)
+				return nil
+			}
+			matches = []match{one}
+		}
+		rel, _ := filepath.Rel(dir, path)
+		for i, m := range matches {
+			out = append(out, &record{file: rel, index: i + 1, match: m})
+		}
+		return nil
+	})
+	return out, err
+}
+
+// scorePosition 按预算逐层加深给 side 的全部着法打分；一层开始了就跑完，超时不再开下一层
+func scorePosition(b *game.Board, side game.CellState, maxDepth int, budget time.Duration) (*game.SearchTree, int) {
+	start := time.Now()
+	var tree *game.SearchTree
+	depth := 0
+	for d := 1; d <= maxDepth; d++ {
+		if tree != nil && budget > 0 && time.Since(start) >= budget {
+			break
+		}
+		tree = game.ExportSearchTree(b, side, game.SearchTreeOptions{Depth: int64(d), AllowJump: true, Plies: 1})
+		depth = d
+	}
+	return tree, depth
+}
+
+// analyzeGame 逐手复盘一局
+func analyzeGame(rec *record, maxDepth int, budget time.Duration, th thresholds) ([]moveResult, error) {
+	moves := make([]game.Move, len(rec.match.Steps))
+	for i, s := range rec.match.Steps {
+		moves[i] = s.Move
+	}
+	if _, err := game.ReplayMoves(nil, moves); err != nil {
+		return nil, err
+	}
+
+	st := game.NewGameState(4)
+	out := make([]moveResult, 0, len(moves))
+	for ply, mv := range moves {
+		side := st.CurrentPlayer
+		tree, depth := scorePosition(st.Board.Clone(), side, maxDepth, budget)
+		r := moveResult{
+			rec:        rec,
+			ply:        ply + 1,
+			player:     side,
+			position:   st.PositionString(),
+			played:     mv,
+			legalMoves: len(tree.Moves),
+			depth:      depth,
+		}
+		// Moves 已按分数从高到低排好
+		if len(tree.Moves) > 0 {
+			top := tree.Moves[0]
+			r.best = game.Move{From: top.From, To: top.To}
+			r.bestScr, r.secondScr = top.Score, top.Score
+			if len(tree.Moves) > 1 {
+				r.secondScr = tree.Moves[1].Score
+			}
+		}
+		for _, n := range tree.Moves {
+			if n.From == mv.From && n.To == mv.To {
+				r.playedScr = n.Score
+				break
+			}
+		}
+		r.class = th.classify(r.loss())
+		out = append(out, r)
+
+		if _, _, err := st.MakeMove(mv); err != nil {
+			return out, err // ReplayMoves 已校验过，不会走到这里
+		}
+	}
+	return out, nil
+}
+
+func moveText(mv game.Move) string {
+	return fmt.Sprintf("(%d,%d)->(%d,%d)", mv.From.Q, mv.From.R, mv.To.Q, mv.To.R)
+}
+
+func sideText(s game.CellState) string {
+	if s == game.PlayerA {
+		return "red"
+	}
+	return "white"
+}
+
+// csvFile 建一个 CSV 并先写表头
+type csvFile struct {
+	f *os.File
+	w *csv.Writer
+}
+
+func createCSV(dir, name string, header []string) (*csvFile, error) {
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return nil, err
+	}
+	w := csv.NewWriter(f)
+	if err := w.Write(header); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &csvFile{f: f, w: w}, nil
+}
+
+func (c *csvFile) write(row []string) {
+	if err := c.w.Write(row); err != nil {
+		log.Fatalf("写 %s 失败: %v", c.f.Name(), err)
+	}
+}
+
+func (c *csvFile) close() {
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		log.Fatalf("写 %s 失败: %v", c.f.Name(), err)
+	}
+	if err := c.f.Close(); err != nil {
+		log.Fatalf("关闭 %s 失败: %v", c.f.Name(), err)
+	}
+	fmt.Printf("已写入: %s\n", c.f.Name())
+}
+
+func main() {
+	var (
+		inDir         = flag.String("in", "games", "对局记录目录（递归读取 *.json）")
+		budget        = flag.Duration("budget", 0, "每个局面的时间预算：超过后不再加深（已开始的一层会跑完；0=只按 -depth）")
+		nn            = flag.Bool("nn", false, "双方都用 ONNX 评估（默认都用静态评估）")
+		inaccuracy    = flag.Int("inaccuracy", 20, "损失达到多少分算不精确")
+		mistake       = flag.Int("mistake", 60, "损失达到多少分算失误")
+		blunder       = flag.Int("blunder", 150, "损失达到多少分算败着")
+		puzzleGap     = flag.Int("puzzle-gap", 150, "最佳着法领先次佳多少分算习题候选")
+		backendReport = flag.Bool("backend-report", false, "初始化 NN 后端并打印能力报告（EP、FP16、批量会话、建会话与热身耗时）")
+	)
+	common := cli.Common{Out: "analysis", Depth: 2}
+	common.Register(flag.CommandLine, cli.Out|cli.Depth)
+	cli.Parse("analyze")
+	if common.Depth < 1 {
+		log.Fatalf("-depth 须 >= 1: %d", common.Depth)
+	}
+	th := thresholds{inaccuracy: *inaccuracy, mistake: *mistake, blunder: *blunder}
+	if th.inaccuracy <= 0 || th.mistake < th.inaccuracy || th.blunder < th.mistake {
+		log.Fatalf("阈值须满足 0 < inaccuracy <= mistake <= blunder: %d/%d/%d", th.inaccuracy, th.mistake, th.blunder)
+	}
+	if *backendReport {
+		game.WriteBackendReport(os.Stderr)
+	}
+	game.UseONNXForPlayerA = *nn
+	game.UseONNXForPlayerB = *nn
+
+	recs, err := loadRecords(*inDir)
+	if err != nil {
+		log.Fatalf("读取对局失败: %v", err)
+	}
+	if len(recs) == 0 {
+		log.Fatalf("%s 下没有对局记录", *inDir)
+	}
+	sort.SliceStable(recs, func(i, j int) bool { return recs[i].file < recs[j].file })
+	if err := os.MkdirAll(common.Out, 0755); err != nil {
+		log.Fatal(err)
+	}
+
+	movesCSV, err := createCSV(common.Out, "moves.csv", []string{
+		"file", "game", "ply", "player", "position", "played", "best",
+		"played_score", "best_score", "loss", "class", "legal_moves", "depth",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	gameHeader := []string{"file", "game", "plies", "winner", "side"}
+	gamesCSV, err := createCSV(common.Out, "games.csv", append(gameHeader, statsHeader...))
+	if err != nil {
+		log.Fatal(err)
+	}
+	puzzlesCSV, err := createCSV(common.Out, "puzzles.csv", []string{
+		"file", "game", "ply", "player", "position", "solution", "gap", "played", "solved",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	total := map[game.CellState]*sideStats{game.PlayerA: {}, game.PlayerB: {}}
+	analyzed, puzzles := 0, 0
+	start := time.Now()
+	fmt.Printf("复盘 %d 局（深度 %d，评估 %s）\n", len(recs), common.Depth, game.EvaluatorID())
+	for _, rec := range recs {
+		results, err := analyzeGame(rec, common.Depth, *budget, th)
+		if err != nil {
+			log.Printf("跳过 %s 第 %d 局: %v", rec.file, rec.index, err)
+			continue
+		}
+		analyzed++
+		per := map[game.CellState]*sideStats{game.PlayerA: {}, game.PlayerB: {}}
+		for _, r := range results {
+			per[r.player].add(r)
+			total[r.player].add(r)
+			movesCSV.write([]string{
+				rec.file, strconv.Itoa(rec.index), strconv.Itoa(r.ply), sideText(r.player), r.position,
+				moveText(r.played), moveText(r.best), strconv.Itoa(r.playedScr), strconv.Itoa(r.bestScr),
+				strconv.Itoa(r.loss()), r.class, strconv.Itoa(r.legalMoves), strconv.Itoa(r.depth),
+			})
+			if gap := r.bestScr - r.secondScr; r.legalMoves > 1 && gap >= *puzzleGap {
+				puzzles++
+				puzzlesCSV.write([]string{
+					rec.file, strconv.Itoa(rec.index), strconv.Itoa(r.ply), sideText(r.player), r.position,
+					moveText(r.best), strconv.Itoa(gap), moveText(r.played), strconv.FormatBool(r.class == classBest),
+				})
+			}
+		}
+		for _, side := range []game.CellState{game.PlayerA, game.PlayerB} {
+			s := per[side]
+			row := []string{rec.file, strconv.Itoa(rec.index), strconv.Itoa(len(results)), rec.match.Winner, sideText(side)}
+			gamesCSV.write(append(row, s.fields()...))
+		}
+		fmt.Printf("%s #%d: %d 手  红 准确率 %.1f%% 败着 %d  白 准确率 %.1f%% 败着 %d\n",
+			rec.file, rec.index, len(results),
+			per[game.PlayerA].accuracy(), per[game.PlayerA].blunders,
+			per[game.PlayerB].accuracy(), per[game.PlayerB].blunders)
+	}
+	movesCSV.close()
+	gamesCSV.close()
+	puzzlesCSV.close()
+
+	summaryCSV, err := createCSV(common.Out, "summary.csv", append([]string{"side", "games"}, statsHeader...))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, side := range []game.CellState{game.PlayerA, game.PlayerB} {
+		summaryCSV.write(append([]string{sideText(side), strconv.Itoa(analyzed)}, total[side].fields()...))
+	}
+	summaryCSV.close()
+
+	fmt.Printf("共复盘 %d/%d 局，习题候选 %d 个，用时 %s\n", analyzed, len(recs), puzzles, time.Since(start).Round(time.Millisecond))
+	for _, side := range []game.CellState{game.PlayerA, game.PlayerB} {
+		s := total[side]
+		fmt.Printf("  %-5s %d 手  准确率 %.1f%%  平均损失 %.1f  不精确 %d  失误 %d  败着 %d\n",
+			sideText(side), s.moves, s.accuracy(), s.avgLoss(), s.inaccuracies, s.mistakes, s.blunders)
+	}
+}
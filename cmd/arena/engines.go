@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"hexxagon_go/internal/game"
+	"hexxagon_go/internal/rating"
+)
+
+// 参赛引擎的写法：名字[:键=值,键=值]，例如
+//
+//	ab:depth=3          静态评估 α-β（game.FindBestMoveAtDepth，不用 ONNX）
+//	nn:depth=2          同上，叶子用 ONNX 评估
+//	hybrid:depth=2      静态与 NN 按阶段混合（game.FindBestMoveAtDepthHybrid）
+//	twophase:depth=2    选子、落子分两层的 α-β（game.FindBestMoveTwoPhase）
+//	mcts:sims=800       MCTS（game.FindBestMoveMCTS）；time=500ms 改为按时间；
+//	                    workers=8 改用多协程 MCTS（game.FindBestMoveMCTSParallel），再加 nn=true 叶子攒批走 NN
+//	random、greedy、perfect  内置基线对手（game.BaselineMove）；perfect 须加 jump=false
+//
+// 都可以加 jump=false 禁止跳跃。
+
+var engineKinds = append([]string{"ab", "nn", "hybrid", "twophase", "mcts"}, game.BaselineBots...)
+
+// engineSpec 解析后的一个参赛引擎
+type engineSpec struct {
+	Name  string // 对局日志里的参赛者名（"A ..." / "B ..."）
+	Label string // 命令行原样，输出里用
+	Kind  string
+	Depth int
+	Sims  int
+	Time  time.Duration
+	Jump  bool
+	Par   game.ParallelMCTSConfig // Workers > 1 时 mcts 走多协程版
+	rng   *rand.Rand              // 基线对手用
+}
+
+// parseEngine 解析 "kind[:k=v,...]"
+func parseEngine(s string, seed int64) (*engineSpec, error) {
+	kind, opts, _ := strings.Cut(s, ":")
+	e := &engineSpec{Label: s, Kind: kind, Depth: 2, Sims: 800, Jump: true, rng: rand.New(rand.NewSource(seed))}
+	known := false
+	for _, k := range engineKinds {
+		known = known || k == kind
+	}
+	if !known {
+		return nil, fmt.Errorf("engine %q: unknown kind %q (want %s)", s, kind, strings.Join(engineKinds, "/"))
+	}
+	if opts == "" {
+		return e.checked()
+	}
+	simsSet := false
+	for _, kv := range strings.Split(opts, ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("engine %q: option %q is not key=value", s, kv)
+		}
+		var err error
+		switch k {
+		case "depth":
+			e.Depth, err = strconv.Atoi(v)
+			if err == nil && e.Depth < 1 {
+				err = fmt.Errorf("must be >= 1")
+			}
+		case "sims":
+			e.Sims, err = strconv.Atoi(v)
+			simsSet = true
+		case "time":
+			e.Time, err = time.ParseDuration(v)
+		case "jump":
+			e.Jump, err = strconv.ParseBool(v)
+		case "workers":
+			e.Par.Workers, err = strconv.Atoi(v)
+		case "nn":
+			e.Par.NNLeaves, err = strconv.ParseBool(v)
+		default:
+			err = fmt.Errorf("unknown option")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("engine %q: %s: %w", s, k, err)
+		}
+	}
+	if e.Time > 0 && !simsSet {
+		e.Sims = 0 // 只给了 time：按时间停，不限次数
+	}
+	return e.checked()
+}
+
+// checked 基线对手须能按 Jump 的设置下（perfect 只解不跳跃的对局）
+func (e *engineSpec) checked() (*engineSpec, error) {
+	if game.IsBaselineBot(e.Kind) {
+		if err := game.CheckBaselineBot(e.Kind, e.Jump); err != nil {
+			return nil, fmt.Errorf("engine %q: %w", e.Label, err)
+		}
+	}
+	return e, nil
+}
+
+// usesTT 是否走进程内的置换表
+func (e *engineSpec) usesTT() bool {
+	switch e.Kind {
+	case "ab", "nn", "hybrid", "twophase":
+		return true
+	}
+	return false
+}
+
+// move 为 side 选一手。ONNX 开关是按执子方的全局变量，由调用方先按双方引擎设好
+func (e *engineSpec) move(b *game.Board, side game.CellState) (game.Move, bool, error) {
+	switch e.Kind {
+	case "ab", "nn":
+		mv, ok := game.FindBestMoveAtDepth(b, side, int64(e.Depth), e.Jump)
+		return mv, ok, nil
+	case "hybrid":
+		mv, ok := game.FindBestMoveAtDepthHybrid(b, side, int64(e.Depth), e.Jump)
+		return mv, ok, nil
+	case "twophase":
+		mv, ok := game.FindBestMoveTwoPhase(b, side, int64(e.Depth), e.Jump)
+		return mv, ok, nil
+	case "mcts":
+		if e.Par.Workers > 1 || e.Par.NNLeaves {
+			mv, ok := game.FindBestMoveMCTSParallel(b, side, e.Sims, e.Time, e.Jump, e.Par)
+			return mv, ok, nil
+		}
+		mv, ok := game.FindBestMoveMCTS(b, side, e.Sims, e.Time, e.Jump)
+		return mv, ok, nil
+	}
+	return game.BaselineMove(e.Kind, b, side, e.Jump, e.rng)
+}
+
+// usesNN 叶子是否走 ONNX 评估
+func (e *engineSpec) usesNN() bool {
+	return e.Kind == "nn" || e.Kind == "hybrid" || (e.Kind == "mcts" && e.Par.NNLeaves)
+}
+
+// canonical 规范写法：省略的参数补上默认值、按固定顺序写出，"ab" 与 "ab:depth=2" 在账本里是同一个名字
+func (e *engineSpec) canonical() string {
+	var opts []string
+	switch e.Kind {
+	case "ab", "nn", "hybrid", "twophase":
+		opts = append(opts, "depth="+strconv.Itoa(e.Depth))
+	case "mcts":
+		if e.Sims > 0 {
+			opts = append(opts, "sims="+strconv.Itoa(e.Sims))
+		}
+		if e.Time > 0 {
+			opts = append(opts, "time="+e.Time.String())
+		}
+		if e.Par.Workers > 1 {
+			opts = append(opts, "workers="+strconv.Itoa(e.Par.Workers))
+		}
+		if e.Par.NNLeaves {
+			opts = append(opts, "nn=true")
+		}
+	}
+	if !e.Jump {
+		opts = append(opts, "jump=false")
+	}
+	if len(opts) == 0 {
+		return e.Kind
+	}
+	return e.Kind + ":" + strings.Join(opts, ",")
+}
+
+// ratingPlayer 账本里的身份：规范写法 + 所用评估器的身份（模型 CRC、静态评估版本、规则变体），
+// 换了模型同一写法也是新的参赛者
+func (e *engineSpec) ratingPlayer() rating.Player {
+	return rating.NewPlayer(e.canonical(), game.EvaluatorIDFor(e.usesNN(), e.usesNN()))
+}
@@ -0,0 +1,366 @@
+// cmd/arena/main.go
+// 引擎对战场：任意两个进程内引擎按名字选（静态 α-β、NN、hybrid、两阶段、MCTS、基线对手，写法见 engines.go），
+// 每个开局下一对棋（A 执红、执白各一局），抵消开局本身的先后手偏向。
+// 开局来自 -openings 文件（每行一个 PositionString），没给时按 -seed 随机走 -random-open 手生成。
+// 给了 -sprt 时每下完一对检验一次，得出结论即停；结束时打印 Elo 差估计与 95% 置信区间，
+// 并把每个开局的战绩写到 -out（CSV），找出哪些开局对哪一方特别有利。
+// 每局结果还追加到 -ledger 账本（见 internal/rating），跨次运行累积，用 cmd/rating 看各版本的等级分。
+//
+//	arena -a ab:depth=3 -b mcts:sims=2000 -games 400 -sprt 0,30
+//	arena -a hybrid:depth=2 -b nn:depth=2 -openings openings.txt -out by_opening.csv
+//
+// 两个引擎在同一进程里轮流走：ONNX 开关按执子方设，双方都用置换表时每手前清空，免得一方读到另一方存下的分数。
+// 与 battle_eval_nn（只比两种 α-β 评估）相比，这里引擎任选；外部引擎、循环赛用 tournament。
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"hexxagon_go/internal/cli"
+	"hexxagon_go/internal/elo"
+	"hexxagon_go/internal/game"
+	"hexxagon_go/internal/matchlog"
+	"hexxagon_go/internal/rating"
+)
+
+// openingStats 一个开局下 A 的战绩
+type openingStats struct {
+	Position string
+	W, D, L  int     // A 的胜、和、负
+	Red      float64 // A 执红各局的得分之和
+	White    float64 // A 执白各局的得分之和
+}
+
+func (o *openingStats) add(score float64, aRed bool) {
+	switch score {
+	case 1:
+		o.W++
+	case 0:
+		o.L++
+	default:
+		o.D++
+	}
+	if aRed {
+		o.Red += score
+	} else {
+		o.White += score
+	}
+}
+
+func (o *openingStats) games() int { return o.W + o.D + o.L }
+
+// loadOpenings 读开局文件：每行一个 PositionString，空行和 # 开头的行跳过
+func loadOpenings(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []string
+	sc := bufio.NewScanner(f)
+	for line := 1; sc.Scan(); line++ {
+		s := strings.TrimSpace(sc.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		st, err := game.ParsePosition(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if st.GameOver {
+			return nil, fmt.Errorf("%s:%d: position is already over", path, line)
+		}
+		out = append(out, s)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%s: no openings", path)
+	}
+	return out, nil
+}
+
+// randomOpenings 从标准开局双方各随机走 plies 手，生成至多 n 个互不相同、未终局的开局
+func randomOpenings(radius, plies, n int, rng *rand.Rand) []string {
+	seen := map[string]bool{}
+	var out []string
+	for tries := 0; len(out) < n && tries < 20*n; tries++ {
+		st := game.NewGameState(radius)
+		for i := 0; i < 2*plies && !st.GameOver; i++ {
+			moves := game.GenerateMoves(st.Board, st.CurrentPlayer)
+			if len(moves) == 0 {
+				break
+			}
+			st.MakeMove(moves[rng.Intn(len(moves))])
+		}
+		pos := st.PositionString()
+		if st.GameOver || seen[pos] {
+			continue
+		}
+		seen[pos] = true
+		out = append(out, pos)
+	}
+	return out
+}
+
+// playGame 从 pos 下一局，red 执红（PlayerA）。返回红方得分 1/0.5/0；
+// 引擎声称无着可走或走了非法着判负（reason 非空）。recs 非 nil 时逐手追加 matchlog 记录（含结束行）
+func playGame(red, white *engineSpec, pos string, maxPlies int, recs *[]matchlog.Record) (score float64, reason string, err error) {
+	st, err := game.ParsePosition(pos)
+	if err != nil {
+		return 0, "", err
+	}
+	game.UseONNXForPlayerA, game.UseONNXForPlayerB = red.Kind == "nn", white.Kind == "nn"
+	shareTT := red.usesTT() && white.usesTT()
+
+	for ply := 0; ply < maxPlies && !st.GameOver; ply++ {
+		if st.AdjudicateIfBlocked() {
+			break
+		}
+		side := st.CurrentPlayer
+		e := red
+		if side == game.PlayerB {
+			e = white
+		}
+		if shareTT {
+			game.ClearTT()
+		}
+		t0 := time.Now()
+		mv, ok, err := e.move(st.Board, side)
+		if err != nil {
+			return 0, "", fmt.Errorf("%s: %w", e.Label, err)
+		}
+		rec := matchlog.MoveRecord(st.Board, side, mv, ply, time.Since(t0))
+		if !ok {
+			reason = fmt.Sprintf("%s ply %d: claimed no move but legal moves exist", e.Label, ply)
+		} else if _, _, merr := st.MakeMove(mv); merr != nil {
+			reason = fmt.Sprintf("%s ply %d: %v", e.Label, ply, merr)
+		}
+		if reason != "" {
+			score = 1
+			if side == game.PlayerA {
+				score = 0
+			}
+			break
+		}
+		if recs != nil {
+			rec.Player, rec.Depth = e.Name, e.Depth
+			*recs = append(*recs, rec)
+		}
+	}
+
+	if reason == "" {
+		winner := st.Winner
+		if !st.GameOver {
+			switch {
+			case st.ScoreA > st.ScoreB:
+				winner = game.PlayerA
+			case st.ScoreB > st.ScoreA:
+				winner = game.PlayerB
+			default:
+				winner = game.Empty
+			}
+		}
+		score = map[game.CellState]float64{game.PlayerA: 1, game.PlayerB: 0, game.Empty: 0.5}[winner]
+	}
+	if recs != nil {
+		winner := map[float64]game.CellState{1: game.PlayerA, 0: game.PlayerB, 0.5: game.Empty}[score]
+		*recs = append(*recs, matchlog.EndRecord(len(*recs), winner, reason))
+	}
+	return score, reason, nil
+}
+
+// writeOpenings 每个开局一行：A 的胜和负、执红执白的得分与总得分率
+func writeOpenings(path string, stats []*openingStats) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	w.Write([]string{"position", "games", "a_wins", "draws", "b_wins", "a_points_red", "a_points_white", "a_score"})
+	for _, o := range stats {
+		if o.games() == 0 {
+			continue
+		}
+		score := (float64(o.W) + 0.5*float64(o.D)) / float64(o.games())
+		w.Write([]string{o.Position, strconv.Itoa(o.games()), strconv.Itoa(o.W), strconv.Itoa(o.D), strconv.Itoa(o.L),
+			strconv.FormatFloat(o.Red, 'f', -1, 64), strconv.FormatFloat(o.White, 'f', -1, 64), strconv.FormatFloat(score, 'f', 3, 64)})
+	}
+	w.Flush()
+	return errors.Join(w.Error(), f.Close())
+}
+
+func main() {
+	common := cli.Common{Radius: 4, Seed: time.Now().UnixNano(), Out: "arena_openings.csv"}
+	common.Register(flag.CommandLine, cli.Radius|cli.Seed|cli.Out|cli.Rules)
+	var (
+		specA         = flag.String("a", "ab:depth=2", "引擎 A："+strings.Join(engineKinds, "/")+"，可带 :depth=N、sims=N、time=500ms、jump=false；mcts 另有 workers=N、nn=true")
+		specB         = flag.String("b", "mcts:sims=800", "引擎 B，写法同 -a")
+		games         = flag.Int("games", 200, "最多下几局（每个开局 A 执红、执白各一局，取偶数）")
+		sprtFlag      = flag.String("sprt", "", "启用 SPRT：elo0,elo1[,alpha,beta]（A 相对 B 的 Elo 差），每下完一对检验，得出结论即停")
+		openingsPath  = flag.String("openings", "", "开局文件：每行一个 PositionString（# 开头为注释），轮流使用；空=随机开局")
+		randomOpen    = flag.Int("random-open", 2, "随机开局时双方各走几手")
+		maxPlies      = flag.Int("max-plies", 300, "单局最多手数，到了按子数判")
+		matchLog      = flag.String("matchlog", "", "逐手对局日志（JSON Lines，格式见 internal/matchlog）；空=不写")
+		ledgerPath    = flag.String("ledger", "ratings.jsonl", "战绩账本（追加写，见 internal/rating）；空=不记")
+		backendReport = flag.Bool("backend-report", false, "初始化 NN 后端并打印能力报告（EP、FP16、批量会话、建会话与热身耗时）")
+	)
+	cli.Parse("arena")
+	if *games < 2 {
+		log.Fatalf("-games 须 >= 2: %d", *games)
+	}
+	if *backendReport {
+		game.WriteBackendReport(os.Stderr)
+	}
+	if err := common.ApplyRules(); err != nil {
+		log.Fatal(err)
+	}
+	sprt, err := elo.ParseSPRT(*sprtFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	a, err := parseEngine(*specA, common.Seed+1)
+	if err != nil {
+		log.Fatal(err)
+	}
+	b, err := parseEngine(*specB, common.Seed+2)
+	if err != nil {
+		log.Fatal(err)
+	}
+	a.Name, b.Name = "A "+a.Label, "B "+b.Label
+
+	pairs := *games / 2
+	var openings []string
+	if *openingsPath != "" {
+		if openings, err = loadOpenings(*openingsPath); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		openings = randomOpenings(common.Radius, *randomOpen, pairs, rand.New(rand.NewSource(common.Seed)))
+		if len(openings) == 0 {
+			log.Fatalf("半径 %d、随机 %d 手生成不出开局", common.Radius, *randomOpen)
+		}
+	}
+	stats := make([]*openingStats, len(openings))
+	for i, pos := range openings {
+		stats[i] = &openingStats{Position: pos}
+	}
+
+	ml, err := matchlog.Create(*matchLog, "arena")
+	if err != nil {
+		log.Fatalf("创建对局日志失败: %v", err)
+	}
+	defer ml.Close()
+	ledger, err := rating.OpenLedger(*ledgerPath, "arena")
+	if err != nil {
+		log.Fatalf("打开战绩账本失败: %v", err)
+	}
+	defer ledger.Close()
+	var playerA, playerB rating.Player
+	if ledger != nil {
+		playerA, playerB = a.ratingPlayer(), b.ratingPlayer()
+	}
+
+	fmt.Printf("A = %s，B = %s；%d 个开局，最多 %d 局\n", a.Label, b.Label, len(openings), 2*pairs)
+	start := time.Now()
+	var w, d, l, forfeits, gameNo int
+	verdict := 0
+	for p := 0; p < pairs && verdict == 0; p++ {
+		o := stats[p%len(stats)]
+		for _, aRed := range []bool{true, false} {
+			red, white := a, b
+			if !aRed {
+				red, white = b, a
+			}
+			var recs *[]matchlog.Record
+			if ml != nil {
+				recs = new([]matchlog.Record)
+			}
+			redScore, reason, err := playGame(red, white, o.Position, *maxPlies, recs)
+			if err != nil {
+				log.Fatal(err)
+			}
+			gameNo++
+			if recs != nil {
+				if err := ml.WriteGame(gameNo, *recs); err != nil {
+					log.Fatalf("写对局日志失败: %v", err)
+				}
+			}
+			if reason != "" {
+				forfeits++
+				log.Printf("判负: %s", reason)
+			}
+			score := redScore
+			if !aRed {
+				score = 1 - redScore
+			}
+			o.add(score, aRed)
+			if err := ledger.Append(rating.Result{Player: playerA, Opponent: playerB, Score: score, Red: aRed}); err != nil {
+				log.Fatalf("写战绩账本失败: %v", err)
+			}
+			switch score {
+			case 1:
+				w++
+			case 0:
+				l++
+			default:
+				d++
+			}
+		}
+		if sprt != nil {
+			var llr float64
+			if verdict, llr = sprt.Decide(w, d, l); verdict != 0 {
+				log.Printf("SPRT 结束: LLR %.2f，接受 %s", llr, map[int]string{1: "H1", -1: "H0"}[verdict])
+			}
+		}
+		if (p+1)%10 == 0 {
+			diff, lo, hi := elo.Estimate(w, d, l)
+			log.Printf("进度 %d/%d 局 | A 胜 %d 和 %d 负 %d | Elo %+.0f [%+.0f, %+.0f]", gameNo, 2*pairs, w, d, l, diff, lo, hi)
+		}
+	}
+
+	n := w + d + l
+	diff, lo, hi := elo.Estimate(w, d, l)
+	fmt.Printf("\n===== %s vs %s =====\n", a.Label, b.Label)
+	fmt.Printf("%d 局（用时 %v）：A 胜 %d | 和 %d | B 胜 %d | 判负 %d\n", n, time.Since(start).Round(time.Second), w, d, l, forfeits)
+	fmt.Printf("A 得分率 %.1f%%，Elo 差 %+.0f（95%% 区间 [%+.0f, %+.0f]）\n", 100*(float64(w)+0.5*float64(d))/float64(n), diff, lo, hi)
+	if sprt != nil {
+		_, llr := sprt.Decide(w, d, l)
+		fmt.Printf("SPRT [%.1f, %.1f] alpha=%.2f beta=%.2f: LLR %+.2f  %s\n", sprt.Elo0, sprt.Elo1, sprt.Alpha, sprt.Beta, llr,
+			map[int]string{1: "接受 H1", -1: "接受 H0", 0: "未决"}[verdict])
+	}
+
+	// 按开局：一对棋里 A 两局全胜 / 全负的开局多，说明开局本身偏向明显，或者两者在某类局面上差距大
+	var sweepA, sweepB, played int
+	for _, o := range stats {
+		if o.games() == 0 {
+			continue
+		}
+		played++
+		switch {
+		case o.L == 0 && o.D == 0:
+			sweepA++
+		case o.W == 0 && o.D == 0:
+			sweepB++
+		}
+	}
+	fmt.Printf("开局 %d 个：A 全胜 %d 个，B 全胜 %d 个\n", played, sweepA, sweepB)
+	if err := writeOpenings(common.Out, stats); err != nil {
+		log.Fatalf("写 %s 失败: %v", common.Out, err)
+	}
+	fmt.Printf("按开局的战绩已写入: %s\n", common.Out)
+	if ledger != nil {
+		fmt.Printf("%d 局已追加到战绩账本 %s（rating -ledger %s 查看等级分）\n", n, *ledgerPath, *ledgerPath)
+	}
+}
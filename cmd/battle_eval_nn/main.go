@@ -9,11 +9,15 @@ import (
 	"math/rand"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	// TODO: 把这个路径改成你项目里 game 包的真实模块路径
+	"hexxagon_go/internal/cli"
 	game "hexxagon_go/internal/game"
+	"hexxagon_go/internal/matchlog"
+	"hexxagon_go/internal/rating"
 )
 
 // 两个搜索函数的统一签名（与你现有的一致）
@@ -44,12 +48,14 @@ type frameRow struct {
 // 一盘棋：aFirst 决定谁先手（奇数局让 Hybrid 先；偶数局 Base 先）
 // A 使用 fnA，B 使用 fnB。为了对战公平，不做你那些额外过滤，完全按函数本身逻辑来。
 // 用 GameState 初始化 & 推进，对战 Hybrid vs Base
+// recs 非 nil 时逐手追加 matchlog 记录（含结束行）
 func playOneGame(
 	radius int,
 	aFirst bool,
 	depthA, depthB int64,
 	allowJump bool,
 	fnA, fnB searchFn,
+	recs *[]matchlog.Record,
 ) (winner int, frames []frameRow) {
 
 	st := game.NewGameState(radius)
@@ -63,12 +69,15 @@ func playOneGame(
 		var mv game.Move
 		var ok bool
 		var tag string
+		depth := depthB
+		nodes0 := atomic.LoadInt64(&game.NodesSearched)
+		t0 := time.Now()
 
 		if aFirst {
 			// A=Hybrid, B=Base
 			if cur == game.PlayerA {
 				mv, ok = fnA(st.Board, cur, depthA, allowJump)
-				tag = "Hybrid"
+				tag, depth = "Hybrid", depthA
 			} else {
 				mv, ok = fnB(st.Board, cur, depthB, allowJump)
 				tag = "Base"
@@ -80,15 +89,23 @@ func playOneGame(
 				tag = "Base"
 			} else {
 				mv, ok = fnA(st.Board, cur, depthA, allowJump)
-				tag = "Hybrid"
+				tag, depth = "Hybrid", depthA
 			}
 		}
+		took := time.Since(t0)
 
 		if !ok {
 			// 当前方无合法着法 → 终局
 			break
 		}
 
+		if recs != nil {
+			r := matchlog.MoveRecord(st.Board, cur, mv, ply-1, took)
+			r.Player, r.Depth = tag, int(depth)
+			r.Nodes = atomic.LoadInt64(&game.NodesSearched) - nodes0
+			*recs = append(*recs, r)
+		}
+
 		// 用 GameState 推进（会处理感染、LastMove/GameOver 等）
 		st.MakeMove(mv)
 
@@ -121,8 +138,22 @@ func playOneGame(
 	default:
 		winner = 0
 	}
+	if recs != nil {
+		w := map[int]game.CellState{+1: game.PlayerA, -1: game.PlayerB, 0: game.Empty}[winner]
+		*recs = append(*recs, matchlog.EndRecord(len(frames), w, ""))
+	}
 	return
 }
+
+// ratingPlayer 与 arena 的规范写法一致（"nn:depth=2"、"ab:depth=3,jump=false"），哈希含评估器身份
+func ratingPlayer(kind string, depth int, allowJump, nn bool) rating.Player {
+	name := fmt.Sprintf("%s:depth=%d", kind, depth)
+	if !allowJump {
+		name += ",jump=false"
+	}
+	return rating.NewPlayer(name, game.EvaluatorIDFor(nn, nn))
+}
+
 func writeCSV(path string, rows [][]string) error {
 	f, err := os.Create(path)
 	if err != nil {
@@ -147,19 +178,46 @@ func main() {
 	rand.Seed(time.Now().UnixNano())
 
 	var (
-		games     = flag.Int("games", 100, "对战总局数")
-		radius    = flag.Int("radius", 4, "棋盘半径（4=9x9）")
-		depthA    = flag.Int("depth_hybrid", 2, "Hybrid 搜索深度")
-		depthB    = flag.Int("depth_base", 3, "Base 搜索深度")
-		allowJump = flag.Bool("allow_jump", true, "是否允许跳跃（传给AI层的门控）")
-		outCSV    = flag.String("out", "hybrid_vs_base_samples.csv", "采样CSV输出路径")
+		games         = flag.Int("games", 100, "对战总局数")
+		depthA        = flag.Int("depth_hybrid", 2, "Hybrid 搜索深度")
+		depthB        = flag.Int("depth_base", 3, "Base 搜索深度")
+		allowJump     = flag.Bool("allow_jump", true, "是否允许跳跃（传给AI层的门控）")
+		matchLog      = flag.String("matchlog", "", "逐手对局日志（JSON Lines，格式见 internal/matchlog）；空=不写")
+		ledgerPath    = flag.String("ledger", "ratings.jsonl", "战绩账本（追加写，见 internal/rating）；空=不记")
+		backendReport = flag.Bool("backend-report", false, "初始化 NN 后端并打印能力报告（EP、FP16、批量会话、建会话与热身耗时）")
 	)
-	flag.Parse()
+	common := cli.Common{Radius: 4, Out: "hybrid_vs_base_samples.csv"}
+	common.Register(flag.CommandLine, cli.Radius|cli.Out)
+	cli.Parse("battle_eval_nn")
+	if common.Radius > game.DefaultBoardRadius {
+		// 大盘上 ONNX 一方也会退回静态评估，比出来的只是静态对静态
+		log.Fatalf("-radius %d: 神经网络输入只装得下半径 %d", common.Radius, game.DefaultBoardRadius)
+	}
+	if *backendReport {
+		game.WriteBackendReport(os.Stderr)
+	}
 
 	// 绑定搜索：统一用当前 αβ 实现，区别在于 Evaluate 是否启用 ONNX。
 	// 我们通过切换 UseONNXForPlayerA/B 来实现“ONNX vs 旧评估”。
 	fnSearch := game.FindBestMoveAtDepth
 
+	ml, err := matchlog.Create(*matchLog, "battle_eval_nn")
+	if err != nil {
+		log.Fatalf("创建对局日志失败: %v", err)
+	}
+	defer ml.Close()
+	ledger, err := rating.OpenLedger(*ledgerPath, "battle_eval_nn")
+	if err != nil {
+		log.Fatalf("打开战绩账本失败: %v", err)
+	}
+	defer ledger.Close()
+	// 账本里的身份与 arena 的 nn/ab 引擎同名同哈希，两个工具的战绩合在一起算等级分
+	var hybridID, baseID rating.Player
+	if ledger != nil {
+		hybridID = ratingPlayer("nn", *depthA, *allowJump, true)
+		baseID = ratingPlayer("ab", *depthB, *allowJump, false)
+	}
+
 	aWins, bWins, draws := 0, 0, 0
 	rows := [][]string{{"game", "ply", "empties", "piece_diff", "mover_ai"}} // mover_ai: 执棋方标签（Hybrid/Base）
 
@@ -177,7 +235,16 @@ func main() {
 			game.UseONNXForPlayerB = true
 		}
 
-		w, frames := playOneGame(*radius, aFirst, int64(*depthA), int64(*depthB), *allowJump, fnSearch, fnSearch)
+		var recs *[]matchlog.Record
+		if ml != nil {
+			recs = new([]matchlog.Record)
+		}
+		w, frames := playOneGame(common.Radius, aFirst, int64(*depthA), int64(*depthB), *allowJump, fnSearch, fnSearch, recs)
+		if recs != nil {
+			if err := ml.WriteGame(g, *recs); err != nil {
+				log.Fatalf("写对局日志失败: %v", err)
+			}
+		}
 
 		switch w {
 		case +1: // A 赢
@@ -195,6 +262,14 @@ func main() {
 		default:
 			draws++
 		}
+		hybridRed := aFirst // Hybrid 执红（PlayerA）
+		score := map[int]float64{+1: 1, -1: 0, 0: 0.5}[w]
+		if !hybridRed {
+			score = 1 - score
+		}
+		if err := ledger.Append(rating.Result{Player: hybridID, Opponent: baseID, Score: score, Red: hybridRed}); err != nil {
+			log.Fatalf("写战绩账本失败: %v", err)
+		}
 
 		// 写帧
 		for _, fr := range frames {
@@ -216,8 +291,8 @@ func main() {
 	fmt.Printf("总局数: %d（轮流先手）\n", *games)
 	fmt.Printf("Hybrid 胜: %d | Base 胜: %d | 平: %d\n", aWins, bWins, draws)
 
-	if err := writeCSV(*outCSV, rows); err != nil {
+	if err := writeCSV(common.Out, rows); err != nil {
 		log.Fatalf("写CSV失败: %v", err)
 	}
-	fmt.Printf("采样已写入: %s（列: game, ply, empties, piece_diff, mover_ai）\n", *outCSV)
-}
\ No newline at end of file
+	fmt.Printf("采样已写入: %s（列: game, ply, empties, piece_diff, mover_ai）\n", common.Out)
+}
@@ -2,16 +2,24 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"math/rand"
 	"os"
 	"runtime/pprof"
+	"sort"
 	"time"
 
+	"hexxagon_go/internal/cli"
 	"hexxagon_go/internal/game"
 )
 
 func main() {
+	backendReport := flag.Bool("backend-report", false, "初始化 NN 后端并打印能力报告（EP、FP16、批量会话、建会话与热身耗时）")
+	cli.Parse("bench_perf")
+	if *backendReport {
+		game.WriteBackendReport(os.Stderr)
+	}
 	rand.Seed(time.Now().UnixNano())
 
 	// 开启 CPU Profile
@@ -41,6 +49,9 @@ func main() {
 	depth := 2 // 深度设为 2，兼顾速度与真实负载
 	maxMoves := 100
 	
+	game.EnableSearchTelemetry(true)
+	game.ResetSearchStats()
+
 	start := time.Now()
 	for i := 0; i < maxMoves; i++ {
 		if st.GameOver {
@@ -62,5 +73,41 @@ func main() {
 	elapsed := time.Since(start)
 
 	fmt.Printf("Total time for full game: %v\n", elapsed)
+	printSearchStats(game.GetSearchStats())
 	fmt.Println("Profile saved to cpu_onnx.prof. Run 'go tool pprof -http=:8080 cpu_onnx.prof' to view the heatmap.")
 }
+
+// printSearchStats 输出各剪枝技术的效果，便于把提速归因到具体技术
+func printSearchStats(s game.SearchStats) {
+	pct := func(a, b uint64) float64 {
+		if b == 0 {
+			return 0
+		}
+		return 100 * float64(a) / float64(b)
+	}
+	fmt.Println("=== Search stats ===")
+	fmt.Printf("nodes %d, TT probes %d, hits %d (%.1f%%), TT cutoffs %d\n",
+		s.Nodes, s.TTProbes, s.TTHits, pct(s.TTHits, s.TTProbes), s.TTCutoffs)
+	fmt.Printf("TT %dMB, stores %d, replaced %d (%.1f%%), hashfull %.1f%%\n",
+		game.TTSizeMB(), s.TTStores, s.TTReplace, pct(s.TTReplace, s.TTStores), float64(s.HashFull)/10)
+	cuts := s.CutoffsMax + s.CutoffsMin
+	fmt.Printf("beta cutoffs %d, alpha cutoffs %d, first-move %.1f%%, NN batch leaves %d\n",
+		s.CutoffsMax, s.CutoffsMin, pct(s.FirstMoveCutoffs, cuts), s.NNBatchLeaves)
+	if s.PolicyPruneCalls > 0 {
+		fmt.Printf("policy prune: %d calls, removed %d/%d (%.1f%%)\n",
+			s.PolicyPruneCalls, s.PolicyPruneRemoved, s.PolicyPruneIn, pct(s.PolicyPruneRemoved, s.PolicyPruneIn))
+	}
+	names := make([]string, 0, len(s.Filters))
+	for name := range s.Filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := s.Filters[name]
+		if f.Calls == 0 {
+			continue
+		}
+		fmt.Printf("filter %-30s calls %8d  removed %8d/%-8d (%.1f%%)\n",
+			name, f.Calls, f.Removed, f.In, pct(f.Removed, f.In))
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"sort"
+
+	"hexxagon_go/internal/game"
+	"hexxagon_go/internal/samplefmt"
+)
+
+// 自博弈分片 → 对局。分片里只有局面（执子方视角的 grid3 张量）、对局编号、手数和胜负，没有着法，
+// 着法由相邻两手的局面反推：找一步（或两步）合法着法把前一个局面走成后一个。
+//
+//   - 随机开局之后第 0 手是红方，之后严格交替（selfplay 无子可走即终局），所以手数的奇偶就是执子方；
+//     开局用该局第一个样本的局面，写成 Start。
+//   - 最后一手走完后的局面不进样本，反推不出来：还原出的对局总是少最后一手，Result 取自样本的胜负标签。
+//   - -augment 的对称像只取原样那份；联赛对局只有当前模型一方的样本，中间隔一手，按两步反推。
+//   - -dedup、-reservoir 抽掉的手隔得更远，反推到断开处为止，打上 Truncated 标签。
+//
+// selfplay 只用原版规则，这里也按原版规则走。
+
+type chunkSample struct {
+	state    []float32
+	value    int32 // 执子方视角的胜负
+	ply      int
+	game     uint32
+	opponent uint32
+}
+
+func readChunkPB(path string) ([]chunkSample, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := samplefmt.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	if n := shapeLen(r.Header.StateShape); n != game.TensorLen {
+		return nil, fmt.Errorf("state shape %v, want grid3 (%d values)", r.Header.StateShape, game.TensorLen)
+	}
+	var samples []chunkSample
+	var s samplefmt.Sample
+	for {
+		if err := r.Next(&s); err == io.EOF {
+			return samples, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("sample %d: %w", len(samples), err)
+		}
+		samples = append(samples, chunkSample{
+			state:    append([]float32(nil), s.State...),
+			value:    s.Value,
+			ply:      int(s.Ply),
+			game:     s.Game,
+			opponent: s.Opponent,
+		})
+	}
+}
+
+func shapeLen(shape []uint32) int {
+	n := 1
+	for _, d := range shape {
+		n *= int(d)
+	}
+	return n
+}
+
+// readChunkRaw 读 base_X.bin、base_Z.bin、base_M.bin
+func readChunkRaw(base string) ([]chunkSample, error) {
+	x, err := os.ReadFile(base + "_X.bin")
+	if err != nil {
+		return nil, err
+	}
+	z, err := os.ReadFile(base + "_Z.bin")
+	if err != nil {
+		return nil, err
+	}
+	m, err := os.ReadFile(base + "_M.bin")
+	if err != nil {
+		return nil, err
+	}
+	n := len(z)
+	if len(x) != n*game.TensorLen*4 || len(m) != n*samplefmt.AuxRecordSize {
+		return nil, fmt.Errorf("%d labels, but X has %d bytes and M %d bytes (want %d and %d)",
+			n, len(x), len(m), n*game.TensorLen*4, n*samplefmt.AuxRecordSize)
+	}
+	aux, err := samplefmt.DecodeAux(m)
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]chunkSample, n)
+	for i := range samples {
+		state := make([]float32, game.TensorLen)
+		for j := range state {
+			state[j] = math.Float32frombits(binary.LittleEndian.Uint32(x[(i*game.TensorLen+j)*4:]))
+		}
+		samples[i] = chunkSample{
+			state:    state,
+			value:    int32(int8(z[i])),
+			ply:      int(aux[i].Ply),
+			game:     aux[i].Game,
+			opponent: aux[i].Opponent,
+		}
+	}
+	return samples, nil
+}
+
+// gamesFromSamples 按对局编号分组（按首次出现的顺序）并还原每一局
+func gamesFromSamples(samples []chunkSample, source string) []game.GameRecord {
+	game.SetRules(game.Rules{})
+	var order []uint32
+	byGame := map[uint32][]chunkSample{}
+	for _, s := range samples {
+		if _, ok := byGame[s.game]; !ok {
+			order = append(order, s.game)
+		}
+		byGame[s.game] = append(byGame[s.game], s)
+	}
+	games := make([]game.GameRecord, 0, len(order))
+	for _, id := range order {
+		g, err := rebuildGame(byGame[id], source)
+		if err != nil {
+			log.Printf("%s game %d: %v，跳过", source, id, err)
+			continue
+		}
+		games = append(games, g)
+	}
+	return games
+}
+
+// sideAt 第 ply 手的执子方
+func sideAt(ply int) game.CellState {
+	if ply%2 == 0 {
+		return game.PlayerA
+	}
+	return game.PlayerB
+}
+
+// decodeSample 样本的局面换回红白：DecodeBoardTensor 把执子方当作红方
+func decodeSample(s chunkSample) (*game.Board, error) {
+	b, err := game.DecodeBoardTensor(s.state)
+	if err != nil || sideAt(s.ply) == game.PlayerA {
+		return b, err
+	}
+	cells := make([]game.CellState, game.BoardN)
+	for i, c := range b.Cells {
+		switch c {
+		case game.PlayerA:
+			c = game.PlayerB
+		case game.PlayerB:
+			c = game.PlayerA
+		}
+		cells[i] = c
+	}
+	return game.BoardFromCells(cells)
+}
+
+func rebuildGame(samples []chunkSample, source string) (game.GameRecord, error) {
+	// 同一手的对称像排在原样之后，稳定排序后每手取第一个
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].ply < samples[j].ply })
+	first := samples[0]
+	b, err := decodeSample(first)
+	if err != nil {
+		return game.GameRecord{}, fmt.Errorf("ply %d: %w", first.ply, err)
+	}
+	start := &game.GameState{Board: b, CurrentPlayer: sideAt(first.ply)}
+	g := game.GameRecord{
+		Tags:   map[string]string{"Source": fmt.Sprintf("%s game %d", source, first.game)},
+		Start:  start.PositionString(),
+		Result: game.ResultDraw,
+	}
+	switch {
+	case first.value > 0:
+		g.Result = game.ResultOf(start.CurrentPlayer)
+	case first.value < 0:
+		g.Result = game.ResultOf(game.Opponent(start.CurrentPlayer))
+	}
+	if first.opponent != 0 {
+		g.Tags["Opponent"] = fmt.Sprintf("league#%d", first.opponent)
+	}
+	prev, prevPly := b, first.ply
+	for _, s := range samples[1:] {
+		if s.ply == prevPly {
+			continue
+		}
+		next, err := decodeSample(s)
+		if err != nil {
+			return game.GameRecord{}, fmt.Errorf("ply %d: %w", s.ply, err)
+		}
+		path, ok := findMoves(prev, sideAt(prevPly), next, s.ply-prevPly)
+		if !ok {
+			g.Tags["Truncated"] = fmt.Sprintf("ply %d", prevPly)
+			break
+		}
+		g.Moves = append(g.Moves, path...)
+		prev, prevPly = next, s.ply
+	}
+	return g, nil
+}
+
+// findMoves 从 b（side 走）出发，找 n 手（1 或 2）把局面走成 target；b 用完原样恢复
+func findMoves(b *game.Board, side game.CellState, target *game.Board, n int) ([]game.Move, bool) {
+	if n < 1 || n > 2 {
+		return nil, false
+	}
+	for _, mv := range game.GenerateMoves(b, side) {
+		_, u := mv.MakeMove(b, side)
+		var rest []game.Move
+		ok := b.Cells == target.Cells
+		if n == 2 {
+			rest, ok = findMoves(b, game.Opponent(side), target, 1)
+		}
+		b.UnmakeMove(u)
+		if ok {
+			return append([]game.Move{mv}, rest...), true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,376 @@
+// cmd/convert/main.go
+// 对局格式互转：录像 JSON（hexxagon -record）、HGN 文本（见 game/notation.go）、自博弈分片（selfplay 的 .pb 或 X/P/Z/M 裸数组）
+// 读进来都变成 HGN 对局，再按 -to 写出：
+//
+//	convert -o games.hgn games.json runs/sp1            录像与一整个自博弈目录 → HGN
+//	convert -to replay -o games.json games.hgn          HGN → 录像（hexxagon -mode replay、cmd/analyze 能读）
+//	convert -to pb -o imported.pb games.hgn             HGN → 样本流：策略目标为实际着法的落点（one-hot），价值为终局胜负
+//
+// 参数可以是文件或目录；目录下只找分片（*.pb、*_X.bin，同一分片两种都有时取 .pb）和 *.hgn，录像 JSON 须直接给出文件名。
+// .npz、.tfrecord 分片读不了（只有写出端），只有这两种格式的分片直接报错，请让 selfplay 同时写 pb 或 raw。
+// 写出前每盘都按自己的规则从开局重放一遍，非法着法直接报出是哪个文件的哪一盘。
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"hexxagon_go/internal/cli"
+	"hexxagon_go/internal/game"
+	"hexxagon_go/internal/samplefmt"
+)
+
+// 输出格式，对应 -to
+const (
+	toHGN    = "hgn"
+	toReplay = "replay"
+	toPB     = "pb"
+)
+
+// replayStep / replayMatch 与 hexxagon -record 写的录像一致（ui.ReplayMatch 第 2 版）
+type replayStep struct {
+	Move     game.Move `json:"move"`
+	Comment  string    `json:"comment,omitempty"`
+	Position string    `json:"position,omitempty"`
+}
+
+type replayMatch struct {
+	Version int          `json:"version"`
+	Winner  string       `json:"winner"` // "red"、"white"、"draw"；结果不明时为空
+	Steps   []replayStep `json:"steps"`
+	Start   string       `json:"start,omitempty"`
+	Rules   string       `json:"rules,omitempty"`
+}
+
+const replayVersion = 2
+
+func main() {
+	to := flag.String("to", toHGN, "输出格式: hgn（对局文本）、replay（录像 JSON）或 pb（samplefmt 样本流）")
+	out := flag.String("o", "", "输出文件；空=标准输出（pb 须指定文件）")
+	cli.Parse("convert")
+	// 标准输出留给转换结果（game 包的 init 把日志改到了标准输出）
+	log.SetOutput(os.Stderr)
+	if flag.NArg() == 0 {
+		log.Fatal("用法: convert [-to hgn|replay|pb] [-o 输出] 文件或目录...")
+	}
+	switch *to {
+	case toHGN, toReplay:
+	case toPB:
+		if *out == "" {
+			log.Fatal("-to pb 须用 -o 指定输出文件")
+		}
+	default:
+		log.Fatalf("未知输出格式 %q（可选 %s/%s/%s）", *to, toHGN, toReplay, toPB)
+	}
+
+	inputs, err := expandInputs(flag.Args())
+	if err != nil {
+		log.Fatal(err)
+	}
+	var games []game.GameRecord
+	for _, path := range inputs {
+		gs, err := readGames(path)
+		if err != nil {
+			log.Fatalf("%s: %v", path, err)
+		}
+		log.Printf("%s: %d 盘", path, len(gs))
+		games = append(games, gs...)
+	}
+	for i, g := range games {
+		if _, err := replayRecord(g); err != nil {
+			log.Fatalf("第 %d 盘（%s）: %v", i+1, g.Tags["Source"], err)
+		}
+	}
+
+	w := io.Writer(os.Stdout)
+	var f *os.File
+	if *out != "" {
+		if f, err = os.Create(*out); err != nil {
+			log.Fatal(err)
+		}
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	switch *to {
+	case toHGN:
+		err = writeHGN(bw, games)
+	case toReplay:
+		err = writeReplays(bw, games)
+	case toPB:
+		err = writePB(bw, games)
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+	if f != nil {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		log.Fatalf("写出失败: %v", err)
+	}
+	log.Printf("共 %d 盘 → %s", len(games), *to)
+}
+
+// unreadableChunkExts selfplay 能写、这里读不了的分片格式
+var unreadableChunkExts = []string{".npz", ".tfrecord"}
+
+// expandInputs 目录展开成其中的分片与 HGN 文件（按路径排序）；同一分片既有 .pb 又有 _X.bin 时只取 .pb。
+// 只有 .npz/.tfrecord、没有可读格式的分片报错，不悄悄跳过
+func expandInputs(args []string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		st, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !st.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+		var found, unreadable []string
+		pb, raw := map[string]bool{}, map[string]bool{}
+		err = filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			switch {
+			case strings.HasSuffix(path, ".pb"):
+				pb[strings.TrimSuffix(path, ".pb")] = true
+				found = append(found, path)
+			case strings.HasSuffix(path, "_X.bin"):
+				raw[strings.TrimSuffix(path, "_X.bin")] = true
+				found = append(found, path)
+			case strings.HasSuffix(path, ".hgn"):
+				found = append(found, path)
+			case unreadableChunk(path):
+				unreadable = append(unreadable, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range unreadable {
+			base := strings.TrimSuffix(path, filepath.Ext(path))
+			if !pb[base] && !raw[base] {
+				return nil, fmt.Errorf("%s: %s 分片读不了，需要同名的 .pb 或 _X.bin", path, filepath.Ext(path))
+			}
+		}
+		sort.Strings(found)
+		for _, path := range found {
+			if strings.HasSuffix(path, "_X.bin") && pb[strings.TrimSuffix(path, "_X.bin")] {
+				continue
+			}
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// readGames 按扩展名认格式
+func readGames(path string) ([]game.GameRecord, error) {
+	switch {
+	case strings.HasSuffix(path, ".hgn"):
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return game.ParseGames(f)
+	case strings.HasSuffix(path, ".json"):
+		return readReplays(path)
+	case strings.HasSuffix(path, ".pb"):
+		samples, err := readChunkPB(path)
+		if err != nil {
+			return nil, err
+		}
+		return gamesFromSamples(samples, filepath.Base(path)), nil
+	case strings.HasSuffix(path, "_X.bin"):
+		samples, err := readChunkRaw(strings.TrimSuffix(path, "_X.bin"))
+		if err != nil {
+			return nil, err
+		}
+		return gamesFromSamples(samples, filepath.Base(strings.TrimSuffix(path, "_X.bin"))), nil
+	}
+	if unreadableChunk(path) {
+		return nil, fmt.Errorf("%s 分片读不了，请用同名的 .pb 或 _X.bin", filepath.Ext(path))
+	}
+	return nil, fmt.Errorf("不认识的文件类型（可用 .hgn、.json、.pb、_X.bin）")
+}
+
+func unreadableChunk(path string) bool {
+	for _, ext := range unreadableChunkExts {
+		if strings.HasSuffix(path, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func readReplays(path string) ([]game.GameRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var matches []replayMatch
+	if err := json.Unmarshal(data, &matches); err != nil {
+		return nil, err
+	}
+	games := make([]game.GameRecord, len(matches))
+	for i, m := range matches {
+		g := game.GameRecord{
+			Tags:   map[string]string{"Source": fmt.Sprintf("%s#%d", filepath.Base(path), i+1)},
+			Rules:  m.Rules,
+			Start:  m.Start,
+			Result: game.ResultUnknown,
+		}
+		switch m.Winner {
+		case "red":
+			g.Result = game.ResultRed
+		case "white":
+			g.Result = game.ResultWhite
+		case "draw":
+			g.Result = game.ResultDraw
+		}
+		for _, st := range m.Steps {
+			g.Moves = append(g.Moves, st.Move)
+		}
+		games[i] = g
+	}
+	return games, nil
+}
+
+// replayRecord 按本盘规则从开局重放，逐手配上解说和走完后的局面
+func replayRecord(g game.GameRecord) ([]replayStep, error) {
+	rules, err := game.ParseRules(g.Rules)
+	if err != nil {
+		return nil, err
+	}
+	game.SetRules(rules)
+	st, err := g.StartState()
+	if err != nil {
+		return nil, err
+	}
+	steps := make([]replayStep, len(g.Moves))
+	for i, mv := range g.Moves {
+		if err := game.ValidateMove(st, mv); err != nil {
+			return nil, &game.MoveError{Ply: i, Move: mv, Player: st.CurrentPlayer, Reason: err.Error()}
+		}
+		steps[i] = replayStep{Move: mv, Comment: game.CommentMove(st.Board, st.CurrentPlayer, mv)}
+		if _, _, err := st.MakeMove(mv); err != nil {
+			return nil, &game.MoveError{Ply: i, Move: mv, Player: st.CurrentPlayer, Reason: err.Error()}
+		}
+		steps[i].Position = st.PositionString()
+	}
+	return steps, nil
+}
+
+func writeHGN(w io.Writer, games []game.GameRecord) error {
+	for _, g := range games {
+		if err := game.WriteGame(w, g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeReplays(w io.Writer, games []game.GameRecord) error {
+	matches := make([]replayMatch, len(games))
+	for i, g := range games {
+		steps, err := replayRecord(g)
+		if err != nil {
+			return err
+		}
+		matches[i] = replayMatch{Version: replayVersion, Steps: steps, Start: g.Start, Rules: g.Rules}
+		if winner, ok := g.Winner(); ok {
+			matches[i].Winner = map[game.CellState]string{game.PlayerA: "red", game.PlayerB: "white", game.Empty: "draw"}[winner]
+		}
+	}
+	data, err := json.MarshalIndent(matches, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// writePB 每手一个样本：执子方视角的局面、实际着法落点的 one-hot 策略、终局胜负；结果不明的盘没有价值标签，跳过，
+// 半径 5、6 的盘编码不进 9×9 张量，也跳过
+func writePB(w io.Writer, games []game.GameRecord) error {
+	enc, _ := game.Encoder(game.FeaturesGrid3)
+	pw, err := samplefmt.NewWriter(w, samplefmt.Header{
+		StateShape: []uint32{uint32(enc.Planes), game.GridSize, game.GridSize},
+		PolicyLen:  game.GridSize * game.GridSize,
+		Generator:  "hexxagon_go/convert",
+		Planes:     enc.PlaneNames,
+	})
+	if err != nil {
+		return err
+	}
+	written, skipped := 0, 0
+	for _, g := range games {
+		winner, ok := g.Winner()
+		if !ok {
+			skipped++
+			continue
+		}
+		rules, err := game.ParseRules(g.Rules)
+		if err != nil {
+			return err
+		}
+		game.SetRules(rules)
+		st, err := g.StartState()
+		if err != nil {
+			return err
+		}
+		if !game.FitsNN(st.Board) {
+			skipped++ // 半径 5、6 的盘 9×9 张量装不下
+			continue
+		}
+		for ply, mv := range g.Moves {
+			side := st.CurrentPlayer
+			t := game.EncodeBoardTensor(st.Board, side)
+			policy := make([]float32, game.GridSize*game.GridSize)
+			policy[game.AxialToIndex(mv.To)] = 1
+			s := samplefmt.Sample{
+				State:   t[:],
+				Policy:  policy,
+				Ply:     uint32(ply),
+				Empties: uint32(st.Board.CountPieces(game.Empty)),
+				Phase:   uint32(game.GamePhase(st.Board)),
+				Game:    uint32(written),
+				Key:     game.PositionKey(st.Board, side),
+			}
+			switch winner {
+			case side:
+				s.Value = 1
+			case game.Empty:
+			default:
+				s.Value = -1
+			}
+			if err := pw.Write(&s); err != nil {
+				return err
+			}
+			if _, _, err := st.MakeMove(mv); err != nil {
+				return err
+			}
+		}
+		written++
+	}
+	if skipped > 0 {
+		log.Printf("%d 盘结果不明，没有价值标签，未写入", skipped)
+	}
+	log.Printf("%d 盘、%d 个样本", written, pw.Count())
+	return pw.Flush()
+}
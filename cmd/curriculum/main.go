@@ -0,0 +1,299 @@
+// cmd/curriculum/main.go
+// 从 selfplay 数据里挖“浅搜会走错、深搜才找得到”的局面，按至少要搜几层才找到正解分级导出：
+//
+//	go run ./cmd/curriculum -dir selfplay_out -out curriculum -max-depth 4
+//
+// 每个局面从 1 层搜到 -max-depth 层（与 cmd/analyze 同口径，给全部着法打分）；最深一层的最佳着法为正解，
+// 且须领先次佳至少 -min-gap 分，否则算不出唯一正解、不要。定级 = 从这一层起一直选正解的最浅深度；
+// 1 层就对的局面没有分歧，也不要。每一级写两份：
+//
+//	puzzles_d<N>.csv   习题：与 cmd/analyze 的 puzzles.csv 同样以 position/solution 为主，另带浅搜会走的错着
+//	eval_d<N>.jsonl    评测集：每行一个局面、正解与深搜的全部着法分，用来看新模型的策略头能解到第几级
+//
+// 样本张量里行棋方记为红方（见 game.DecodeBoardTensor），导出的局面都是红方走。
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"hexxagon_go/internal/cli"
+	"hexxagon_go/internal/engine"
+	"hexxagon_go/internal/game"
+	"hexxagon_go/internal/samplefmt"
+)
+
+// graded 一个定了级的局面
+type graded struct {
+	position string
+	empties  int
+	phase    int
+	solution game.Move
+	shallow  game.Move // 1 层搜索选的着法
+	solve    int       // 至少搜几层才稳定选正解
+	gap      int       // 最深一层正解领先次佳的分数
+	scores   []*game.SearchTreeNode
+	source   string // 分片名#样本序号
+}
+
+// evalLine eval_d<N>.jsonl 的一行
+type evalLine struct {
+	Position   string         `json:"position"`
+	Best       string         `json:"best"`
+	Shallow    string         `json:"shallow"`
+	SolveDepth int            `json:"solve_depth"`
+	Gap        int            `json:"gap"`
+	Empties    int            `json:"empties"`
+	Scores     map[string]int `json:"scores"` // 最深一层每个着法的分（行棋方视角）
+	Source     string         `json:"source"`
+}
+
+// chunkBases 列出目录下所有分片的前缀（chunk_00001 之类）；裸数组与 .pb 都有时只算一次
+func chunkBases(dir string) ([]string, error) {
+	seen := make(map[string]bool)
+	for _, suffix := range []string{"_X.bin", ".pb"} {
+		matches, err := filepath.Glob(filepath.Join(dir, "chunk_*"+suffix))
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			seen[strings.TrimSuffix(m, suffix)] = true
+		}
+	}
+	bases := make([]string, 0, len(seen))
+	for b := range seen {
+		bases = append(bases, b)
+	}
+	sort.Strings(bases)
+	return bases, nil
+}
+
+// readStates 读一个分片的全部状态张量：有 _X.bin 读裸数组，否则读 .pb
+func readStates(base string) ([][]float32, error) {
+	if f, err := os.Open(base + "_X.bin"); err == nil {
+		defer f.Close()
+		fi, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		if fi.Size()%(game.TensorLen*4) != 0 {
+			return nil, fmt.Errorf("%s_X.bin: %d bytes, not a multiple of %d", base, fi.Size(), game.TensorLen*4)
+		}
+		flat := make([]float32, fi.Size()/4)
+		if err := binary.Read(bufio.NewReader(f), binary.LittleEndian, flat); err != nil {
+			return nil, fmt.Errorf("%s_X.bin: %w", base, err)
+		}
+		out := make([][]float32, len(flat)/game.TensorLen)
+		for i := range out {
+			out[i] = flat[i*game.TensorLen : (i+1)*game.TensorLen]
+		}
+		return out, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	f, err := os.Open(base + ".pb")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := samplefmt.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s.pb: %w", base, err)
+	}
+	var out [][]float32
+	for {
+		var s samplefmt.Sample
+		if err := r.Next(&s); err == io.EOF {
+			return out, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("%s.pb: %w", base, err)
+		}
+		out = append(out, s.State)
+	}
+}
+
+func topMove(t *game.SearchTree) game.Move {
+	return game.Move{From: t.Moves[0].From, To: t.Moves[0].To}
+}
+
+// grade 逐层加深给 b（红方走）定级；没有分歧或最深一层分不出唯一正解时 ok=false
+func grade(b *game.Board, maxDepth, minGap int) (g graded, ok bool) {
+	bests := make([]game.Move, maxDepth+1)
+	var deep *game.SearchTree
+	for d := 1; d <= maxDepth; d++ {
+		deep = game.ExportSearchTree(b, game.PlayerA, game.SearchTreeOptions{Depth: int64(d), AllowJump: true, Plies: 1})
+		if len(deep.Moves) < 2 {
+			return g, false
+		}
+		bests[d] = topMove(deep)
+	}
+	gap := deep.Moves[0].Score - deep.Moves[1].Score
+	if gap < minGap {
+		return g, false
+	}
+	solve := maxDepth
+	for d := maxDepth - 1; d >= 1 && bests[d] == bests[maxDepth]; d-- {
+		solve = d
+	}
+	if solve == 1 {
+		return g, false
+	}
+	return graded{
+		position: deep.Position,
+		empties:  b.EmptyMask().Count(),
+		phase:    game.GamePhase(b),
+		solution: bests[maxDepth],
+		shallow:  bests[1],
+		solve:    solve,
+		gap:      gap,
+		scores:   deep.Moves,
+	}, true
+}
+
+func writePuzzles(path string, set []graded) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	w.Write([]string{"position", "player", "solution", "solve_depth", "gap", "shallow", "empties", "phase", "source"})
+	for _, g := range set {
+		w.Write([]string{
+			g.position, "red", engine.FormatMove(g.solution), strconv.Itoa(g.solve), strconv.Itoa(g.gap),
+			engine.FormatMove(g.shallow), strconv.Itoa(g.empties), strconv.Itoa(g.phase), g.source,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeEval(path string, set []graded) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false) // 着法里的 '>' 原样写出
+	for _, g := range set {
+		line := evalLine{
+			Position: g.position, Best: engine.FormatMove(g.solution), Shallow: engine.FormatMove(g.shallow),
+			SolveDepth: g.solve, Gap: g.gap, Empties: g.empties, Source: g.source,
+			Scores: make(map[string]int, len(g.scores)),
+		}
+		for _, n := range g.scores {
+			line.Scores[engine.FormatMove(game.Move{From: n.From, To: n.To})] = n.Score
+		}
+		if err := enc.Encode(line); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func main() {
+	var (
+		dir      = flag.String("dir", "selfplay_out", "selfplay 输出目录（裸数组或 .pb 分片）")
+		outDir   = flag.String("out", "curriculum", "输出目录")
+		maxDepth = flag.Int("max-depth", 4, "参照搜索深度：这一层的最佳着法当作正解；级别为 2..max-depth")
+		minGap   = flag.Int("min-gap", 60, "正解在最深一层须领先次佳多少分，否则算不出唯一正解")
+		limit    = flag.Int("limit", 0, "最多检查多少个不同局面（0=全部）")
+		perLevel = flag.Int("per-level", 0, "每一级最多导出多少个（0=不限）")
+		nn       = flag.Bool("nn", false, "用 ONNX 评估（默认静态评估，结果可复现、不依赖模型）")
+	)
+	cli.Parse("curriculum")
+	if *maxDepth < 2 || *maxDepth > 8 {
+		log.Fatalf("-max-depth 须在 2..8: %d", *maxDepth)
+	}
+	game.UseONNXForPlayerA = *nn
+	game.UseONNXForPlayerB = *nn
+
+	bases, err := chunkBases(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(bases) == 0 {
+		log.Fatalf("%s 下没有分片", *dir)
+	}
+	if err := os.MkdirAll(*outDir, 0755); err != nil {
+		log.Fatal(err)
+	}
+
+	levels := make([][]graded, *maxDepth+1)
+	seen := make(map[uint64]bool)
+	checked, bad := 0, 0
+	start := time.Now()
+	fmt.Printf("检查 %s（%d 个分片，深度 1..%d，评估 %s）\n", *dir, len(bases), *maxDepth, game.EvaluatorID())
+scan:
+	for _, base := range bases {
+		states, err := readStates(base)
+		if err != nil {
+			log.Printf("跳过 %s: %v", filepath.Base(base), err)
+			continue
+		}
+		for i, t := range states {
+			if *limit > 0 && checked >= *limit {
+				break scan
+			}
+			b, err := game.DecodeBoardTensor(t)
+			if err != nil {
+				bad++
+				continue
+			}
+			key := game.PositionKey(b, game.PlayerA)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			checked++
+			g, ok := grade(b, *maxDepth, *minGap)
+			if !ok || (*perLevel > 0 && len(levels[g.solve]) >= *perLevel) {
+				continue
+			}
+			g.source = fmt.Sprintf("%s#%d", filepath.Base(base), i)
+			levels[g.solve] = append(levels[g.solve], g)
+		}
+		if checked > 0 && checked%1000 < len(states) {
+			fmt.Printf("  已检查 %d 个局面，用时 %s\n", checked, time.Since(start).Round(time.Second))
+		}
+	}
+
+	total := 0
+	for d := 2; d <= *maxDepth; d++ {
+		set := levels[d]
+		total += len(set)
+		puzzles := filepath.Join(*outDir, fmt.Sprintf("puzzles_d%d.csv", d))
+		eval := filepath.Join(*outDir, fmt.Sprintf("eval_d%d.jsonl", d))
+		if err := writePuzzles(puzzles, set); err != nil {
+			log.Fatalf("写 %s 失败: %v", puzzles, err)
+		}
+		if err := writeEval(eval, set); err != nil {
+			log.Fatalf("写 %s 失败: %v", eval, err)
+		}
+		fmt.Printf("  %d 层才解出: %5d 个  -> %s, %s\n", d, len(set), puzzles, eval)
+	}
+	fmt.Printf("共检查 %d 个不同局面，导出 %d 个（%.1f%%），无法解码 %d，用时 %s\n",
+		checked, total, 100*float64(total)/float64(max(checked, 1)), bad, time.Since(start).Round(time.Millisecond))
+}
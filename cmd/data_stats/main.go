@@ -0,0 +1,339 @@
+// cmd/data_stats/main.go
+// 训练数据质检：读取 selfplay 输出的分片，统计策略熵分布、价值标签平衡、重复局面率、
+// 平均对局长度与阶段覆盖，在花 GPU 时间训练前发现退化的数据。
+package main
+
+import (
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"hexxagon_go/internal/cli"
+	"hexxagon_go/internal/game"
+	"hexxagon_go/internal/samplefmt"
+)
+
+const policyLen = game.GridSize * game.GridSize
+
+type stats struct {
+	samples int
+	chunks  int
+
+	entropy     []float64 // 每个样本的策略熵（nat）
+	oneHot      int       // 熵 < 0.01：访问全压在一个着法上
+	values      [3]int    // -1 / 0 / +1
+	uniqueKeys  map[uint64]struct{}
+	phases      [3]int
+	gameLengths []int
+	curLen      int // 当前对局已读到的样本数（对局可能跨分片）
+	prevPieces  int
+	badSamples  int // 无法解码的样本
+
+	gameMaxPly map[uint32]int // 有 _M.bin 时按对局编号统计长度（抽样/打乱后也准确）
+	opponents  map[uint32]int // league 对手编号 → 样本数
+}
+
+// chunkBases 列出目录下所有分片的前缀（chunk_00001 之类）；裸数组与 .pb 都有时只算一次
+func chunkBases(dir string) ([]string, error) {
+	seen := make(map[string]bool)
+	for _, suffix := range []string{"_Z.bin", ".pb"} {
+		matches, err := filepath.Glob(filepath.Join(dir, "chunk_*"+suffix))
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			seen[strings.TrimSuffix(m, suffix)] = true
+		}
+	}
+	bases := make([]string, 0, len(seen))
+	for b := range seen {
+		bases = append(bases, b)
+	}
+	sort.Strings(bases)
+	return bases, nil
+}
+
+// readProtoChunk 读 .pb 分片，转成与裸数组相同的 X/P/Z 与辅助字段
+func readProtoChunk(base string) (x, p []float32, z []int8, aux []samplefmt.AuxRecord, err error) {
+	f, err := os.Open(base + ".pb")
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	defer f.Close()
+	r, err := samplefmt.NewReader(f)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	var s samplefmt.Sample
+	for {
+		err := r.Next(&s)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("%s.pb: %w", base, err)
+		}
+		if len(s.State) != game.TensorLen || len(s.Policy) != policyLen {
+			return nil, nil, nil, nil, fmt.Errorf("%s.pb: sample shape %d/%d, want %d/%d",
+				base, len(s.State), len(s.Policy), game.TensorLen, policyLen)
+		}
+		x = append(x, s.State...)
+		p = append(p, s.Policy...)
+		z = append(z, int8(s.Value))
+		aux = append(aux, samplefmt.SampleAux(&s))
+	}
+	return x, p, z, aux, nil
+}
+
+// readAux 读 _M.bin；文件不存在或是别的记录格式时返回 nil（按旧数据处理）
+func readAux(base string, n int) ([]samplefmt.AuxRecord, error) {
+	m, err := os.ReadFile(base + "_M.bin")
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(m) != n*samplefmt.AuxRecordSize {
+		log.Printf("%s_M.bin: %d bytes, want %d; ignoring aux", filepath.Base(base), len(m), n*samplefmt.AuxRecordSize)
+		return nil, nil
+	}
+	return samplefmt.DecodeAux(m)
+}
+
+// readChunk 读入一个分片的 X/P/Z；样本数以 Z 的长度为准，并核对 X/P 的大小
+func readChunk(base string) (x, p []float32, z []int8, err error) {
+	zb, err := os.ReadFile(base + "_Z.bin")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	n := len(zb)
+	z = make([]int8, n)
+	for i, b := range zb {
+		z[i] = int8(b)
+	}
+	if x, err = readFloats(base+"_X.bin", n*game.TensorLen); err != nil {
+		return nil, nil, nil, err
+	}
+	if p, err = readFloats(base+"_P.bin", n*policyLen); err != nil {
+		return nil, nil, nil, err
+	}
+	return x, p, z, nil
+}
+
+func readFloats(path string, want int) ([]float32, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if fi.Size() != int64(want)*4 {
+		return nil, fmt.Errorf("%s: %d bytes, want %d", path, fi.Size(), want*4)
+	}
+	out := make([]float32, want)
+	return out, binary.Read(f, binary.LittleEndian, out)
+}
+
+func entropy(p []float32) float64 {
+	h := 0.0
+	for _, v := range p {
+		if v > 0 {
+			h -= float64(v) * math.Log(float64(v))
+		}
+	}
+	return h
+}
+
+func (s *stats) addChunk(x, p []float32, z []int8, aux []samplefmt.AuxRecord) {
+	s.chunks++
+	for i := range z {
+		if aux != nil {
+			a := aux[i]
+			if int(a.Ply) >= s.gameMaxPly[a.Game] {
+				s.gameMaxPly[a.Game] = int(a.Ply) + 1
+			}
+			s.opponents[a.Opponent]++
+		}
+		s.samples++
+		h := entropy(p[i*policyLen : (i+1)*policyLen])
+		s.entropy = append(s.entropy, h)
+		if h < 0.01 {
+			s.oneHot++
+		}
+		if v := z[i]; v >= -1 && v <= 1 {
+			s.values[v+1]++
+		}
+
+		b, err := game.DecodeBoardTensor(x[i*game.TensorLen : (i+1)*game.TensorLen])
+		if err != nil {
+			s.badSamples++
+			continue
+		}
+		s.uniqueKeys[game.PositionKey(b, game.PlayerA)] = struct{}{}
+		s.phases[game.GamePhase(b)]++
+		if aux != nil {
+			continue
+		}
+
+		// 旧分片里没有对局边界：同一局内棋子总数不减，回落即视为新的一局（selfplay 按整局顺序写入）
+		pieces := b.CountPieces(game.PlayerA) + b.CountPieces(game.PlayerB)
+		if s.curLen > 0 && pieces < s.prevPieces {
+			s.gameLengths = append(s.gameLengths, s.curLen)
+			s.curLen = 0
+		}
+		s.curLen++
+		s.prevPieces = pieces
+	}
+}
+
+func percentile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(q*float64(len(sorted)-1))]
+}
+
+func main() {
+	var (
+		dir        = flag.String("dir", "selfplay_out", "selfplay 输出目录")
+		maxDup     = flag.Float64("max_dup", 0.3, "重复局面率超过此值报警")
+		minEntropy = flag.Float64("min_entropy", 0.3, "平均策略熵（nat）低于此值报警")
+		maxSkew    = flag.Float64("max_skew", 0.15, "胜负标签 |胜-负|/样本数 超过此值报警")
+		maxDraw    = flag.Float64("max_draw", 0.5, "和棋标签占比超过此值报警")
+		minLen     = flag.Float64("min_len", 20, "平均对局长度低于此值报警")
+		minPhase   = flag.Float64("min_phase", 0.05, "任一阶段样本占比低于此值报警")
+		strict     = flag.Bool("strict", false, "有报警时以非零状态退出（便于训练流水线拦截）")
+	)
+	cli.Parse("data_stats")
+
+	bases, err := chunkBases(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(bases) == 0 {
+		log.Fatalf("%s 下没有分片", *dir)
+	}
+
+	s := &stats{uniqueKeys: make(map[uint64]struct{}), gameMaxPly: make(map[uint32]int), opponents: make(map[uint32]int)}
+	for _, base := range bases {
+		x, p, z, err := readChunk(base)
+		if os.IsNotExist(err) {
+			// 只有 .pb 的分片（selfplay -format proto）
+			var aux []samplefmt.AuxRecord
+			if x, p, z, aux, err = readProtoChunk(base); err == nil {
+				s.addChunk(x, p, z, aux)
+				continue
+			}
+		} else if err == nil {
+			var aux []samplefmt.AuxRecord
+			if aux, err = readAux(base, len(z)); err == nil {
+				s.addChunk(x, p, z, aux)
+				continue
+			}
+		}
+		log.Printf("跳过 %s: %v", filepath.Base(base), err)
+	}
+	if s.samples == 0 {
+		log.Fatal("没有可用样本")
+	}
+	if s.curLen > 0 {
+		s.gameLengths = append(s.gameLengths, s.curLen)
+	}
+	for _, l := range s.gameMaxPly {
+		s.gameLengths = append(s.gameLengths, l)
+	}
+
+	n := float64(s.samples)
+	sorted := append([]float64(nil), s.entropy...)
+	sort.Float64s(sorted)
+	meanH := 0.0
+	for _, h := range sorted {
+		meanH += h
+	}
+	meanH /= n
+	dupRate := 1 - float64(len(s.uniqueKeys))/float64(s.samples-s.badSamples)
+	totalLen := 0
+	for _, l := range s.gameLengths {
+		totalLen += l
+	}
+	meanLen := float64(totalLen) / float64(len(s.gameLengths))
+	skew := math.Abs(float64(s.values[2]-s.values[0])) / n
+	drawRate := float64(s.values[1]) / n
+
+	fmt.Printf("===== %s：%d 个分片，%d 个样本，约 %d 局 =====\n", *dir, s.chunks, s.samples, len(s.gameLengths))
+	fmt.Printf("策略熵(nat): 均值 %.3f | p10 %.3f | p50 %.3f | p90 %.3f | 单点策略 %.1f%%\n",
+		meanH, percentile(sorted, 0.1), percentile(sorted, 0.5), percentile(sorted, 0.9), 100*float64(s.oneHot)/n)
+	fmt.Printf("价值标签: 负 %.1f%% | 和 %.1f%% | 胜 %.1f%%\n",
+		100*float64(s.values[0])/n, 100*drawRate, 100*float64(s.values[2])/n)
+	fmt.Printf("重复局面: %.1f%%（不同局面 %d）\n", 100*dupRate, len(s.uniqueKeys))
+	fmt.Printf("平均对局长度: %.1f 手\n", meanLen)
+	fmt.Printf("阶段覆盖: 开局 %.1f%% | 中局 %.1f%% | 残局 %.1f%%\n",
+		100*float64(s.phases[0])/n, 100*float64(s.phases[1])/n, 100*float64(s.phases[2])/n)
+	if len(s.opponents) > 1 {
+		ids := make([]int, 0, len(s.opponents))
+		for id := range s.opponents {
+			ids = append(ids, int(id))
+		}
+		sort.Ints(ids)
+		parts := make([]string, len(ids))
+		for i, id := range ids {
+			name := fmt.Sprintf("#%d", id)
+			if id == 0 {
+				name = "自对弈"
+			}
+			parts[i] = fmt.Sprintf("%s %.1f%%", name, 100*float64(s.opponents[uint32(id)])/n)
+		}
+		fmt.Printf("对手分布: %s\n", strings.Join(parts, " | "))
+	}
+	if s.badSamples > 0 {
+		fmt.Printf("无法解码的样本: %d\n", s.badSamples)
+	}
+
+	var warns []string
+	if dupRate > *maxDup {
+		warns = append(warns, fmt.Sprintf("重复局面率 %.1f%% 过高（开局随机性不足？）", 100*dupRate))
+	}
+	if meanH < *minEntropy {
+		warns = append(warns, fmt.Sprintf("平均策略熵 %.3f 过低（策略塌缩或模拟次数太少？）", meanH))
+	}
+	if skew > *maxSkew {
+		warns = append(warns, fmt.Sprintf("胜负标签失衡 %.1f%%", 100*skew))
+	}
+	if drawRate > *maxDraw {
+		warns = append(warns, fmt.Sprintf("和棋标签占 %.1f%%", 100*drawRate))
+	}
+	if meanLen < *minLen {
+		warns = append(warns, fmt.Sprintf("平均对局长度 %.1f 过短", meanLen))
+	}
+	for i, name := range []string{"开局", "中局", "残局"} {
+		if r := float64(s.phases[i]) / n; r < *minPhase {
+			warns = append(warns, fmt.Sprintf("%s样本仅 %.1f%%", name, 100*r))
+		}
+	}
+	if s.badSamples > 0 {
+		warns = append(warns, fmt.Sprintf("%d 个样本无法解码", s.badSamples))
+	}
+
+	if len(warns) == 0 {
+		fmt.Println("\n未发现异常")
+		return
+	}
+	fmt.Println("\n报警:")
+	for _, w := range warns {
+		fmt.Println("  - " + w)
+	}
+	if *strict {
+		os.Exit(1)
+	}
+}
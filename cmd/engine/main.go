@@ -0,0 +1,90 @@
+// cmd/engine/main.go
+// 独立引擎进程：在 stdin/stdout 上说 internal/engine 定义的文本协议。
+// UI 可用 -engine-path 指向它（或任何兼容的引擎），ORT/TensorRT 崩溃不会带崩界面。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"hexxagon_go/internal/cli"
+	"hexxagon_go/internal/engine"
+	"hexxagon_go/internal/game"
+)
+
+func main() {
+	nnA := flag.Bool("nn-a", game.UseONNXForPlayerA, "A 方使用 ONNX 评估")
+	nnB := flag.Bool("nn-b", game.UseONNXForPlayerB, "B 方使用 ONNX 评估")
+	ttFile := flag.String("tt-file", "", "置换表持久化文件：启动时加载、退出时保存（空=不落盘）")
+	hash := flag.Int("hash", game.TTSizeMB(), "置换表大小（MB，按 2 的幂向下取整）；协议里也可用 hash 命令改")
+	backendReport := flag.Bool("backend-report", false, "初始化 NN 后端并打印能力报告（EP、FP16、批量会话、建会话与热身耗时）")
+	var common cli.Common
+	common.Register(flag.CommandLine, cli.Rules)
+	bot := flag.String("bot", "", fmt.Sprintf("改用内置基线对手应着（%s），不搜索", strings.Join(game.BaselineBots, "/")))
+	negBookPath := flag.String("negbook", "", "负面开局库（cmd/negbook 生成）：根层避开已知输棋线；空=不用")
+	negBookAvoid := flag.Bool("negbook-avoid", true, "加载了负面开局库时是否避开；分析时设为 false 看原始搜索结果")
+	verify := flag.String("verify", game.CurrentOptions().Search.Verify.Mode.String(),
+		fmt.Sprintf("最后一层用 α-β/MCTS 互相复核（%s），每步回 info verify 分歧统计", strings.Join(game.VerifyModeNames, "/")))
+	parityW := flag.Int("parity-w", game.CurrentOptions().Eval.ParityW, "残局奇偶项权重（0=关闭），tournament 里给两个参赛者不同取值即可对比")
+	cli.Parse("engine")
+
+	// stdout 是协议通道：留给 Serve 独占，其余代码里的 fmt.Print（如 ORT 的颜色复位）改走 stderr
+	proto := os.Stdout
+	os.Stdout = os.Stderr
+	log.SetOutput(os.Stderr)
+	log.SetPrefix("[engine] ")
+	if *backendReport {
+		game.WriteBackendReport(os.Stderr)
+	}
+
+	if err := common.ApplyRules(); err != nil {
+		log.Fatal(err)
+	}
+	verifyMode, err := game.ParseVerifyMode(*verify)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts := game.CurrentOptions()
+	opts.Eval.ParityW = *parityW
+	opts.Search.Verify.Mode = verifyMode
+	opts.Search.AvoidLosing = *negBookAvoid
+	if err := game.ApplyOptions(opts); err != nil {
+		log.Fatal(err)
+	}
+	game.UseONNXForPlayerA = *nnA
+	game.UseONNXForPlayerB = *nnB
+	if *nnA || *nnB {
+		game.PreloadModels()
+	}
+	if *negBookPath != "" {
+		nb, err := game.LoadNegBook(*negBookPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		game.SetNegBook(nb)
+		log.Printf("负面开局库已加载: %d 条线，已知输棋线 %d 条", nb.Len(), nb.LosingCount())
+	}
+	if *hash != game.TTSizeMB() {
+		log.Printf("置换表 %dMB", game.ResizeTT(*hash))
+	}
+	if *ttFile != "" {
+		if n, err := game.LoadTT(*ttFile); err != nil {
+			log.Printf("置换表未加载: %v", err)
+		} else {
+			log.Printf("置换表已加载: %d 条", n)
+		}
+	}
+
+	if err := engine.ServeBot(os.Stdin, proto, *bot); err != nil {
+		log.Printf("协议循环退出: %v", err)
+	}
+
+	if *ttFile != "" {
+		if err := game.SaveTT(*ttFile); err != nil {
+			log.Printf("置换表保存失败: %v", err)
+		}
+	}
+}
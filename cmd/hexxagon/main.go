@@ -2,11 +2,16 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio"
+	"hexxagon_go/internal/cli"
+	"hexxagon_go/internal/engine"
 	"hexxagon_go/internal/game"
+	"hexxagon_go/internal/net"
 	"hexxagon_go/internal/ui"
 	"log"
+	"os"
 )
 
 //import _ "net/http/pprof"
@@ -45,15 +50,175 @@ func main() {
 	)
 
 	// —— 新增：启动参数 —— //
-	modeFlag := flag.String("mode", "pve", "游戏模式: pve(人机) 或 pvp(人人)")
-	depthFlag := flag.Int("depth", 1, "人机搜索深度 (ONNX 建议 1 或 2)")
+	modeFlag := flag.String("mode", "pve", "游戏模式: pve(人机)、pvp(人人)、net(联机，配 -net-listen 或 -net-connect)、demo(AI 对 AI 连续演示，轮换布局与难度；对局中按 D 也可进入)、ava(AI 对 AI 观战，双方按 -red/-white 各自配置) 或 replay(播放 -replay 录像)")
+	redFlag := flag.String("red", "", "-mode ava 红方 AI: 引擎[:键=值,...]，引擎为 ab/mcts 或 random/greedy，键为 depth/sims/time/nn，如 ab:depth=3,nn=false、mcts:sims=800,nn=true；空=按 -depth/-eval 的 α-β")
+	whiteFlag := flag.String("white", "", "白方 AI（-mode ava，或 pve 下的 AI 一方，此时代替 -depth/-eval/-bot）；写法同 -red")
+	netListenFlag := flag.String("net-listen", "", "联机主机：在该地址等对方连入，如 :7777")
+	netConnectFlag := flag.String("net-connect", "", "联机客户端：连到主机地址，如 192.168.1.5:7777；地图、先手与规则以主机为准")
+	netSideFlag := flag.String("net-side", "red", "联机主机执哪一方: red(先手) 或 white")
+	depthFlag := flag.Int("depth", 1, fmt.Sprintf("人机搜索深度 1..%d (ONNX 建议 1 或 2)", ui.MaxAIDepth))
+	evalFlag := flag.String("eval", ui.EvalNN, "AI 评估函数: nn(神经网络) 或 static(手写静态评估)")
+	budgetFlag := flag.Duration("think-budget", 0, "AI 单步搜索时间上限（如 500ms、2s）：迭代加深到 -depth 层或到点为止，到点用已完成的最深一层结果（0=只按 -depth）")
+	timeFlag := flag.Duration("time", 0, "AI 每步按时间搜索（如 500ms、2s）：迭代加深到点为止，不受 -depth 限制，演示模式同样适用；给了就不看 -think-budget（0=按 -depth）")
 	// 支持 -tip / -tips 两个别名
 	showScoresFlag := flag.Bool("tip", false, "是否展示玩家棋子评分")
+	recordFlag := flag.String("record", "", "录像文件：每盘终局把着法追加进去（JSON，-mode replay 可播放）；空=不录")
+	replayFlag := flag.String("replay", "", "-mode replay 播放的录像文件")
+	replayDelayFlag := flag.Duration("replay-delay", ui.DefaultReplayDelay, "回放每手间隔；播放中 Up/Down 加速/减速，Space 暂停，Left/Right 单步，PgUp/PgDn 换盘")
+	saveFlag := flag.String("save", "", "对局中按 S 存档、按 L 读档的文件（空=有 -load 时用它，否则 "+ui.DefaultSaveFile+"）")
+	loadFlag := flag.String("load", "", "从存档接着下（pve/pvp）；规则以存档为准")
+	heatmapFlag := flag.Bool("heatmap", false, "终局时显示争夺热力图（每格易手次数）；按 H 切换（人机对局轮到人时 H 是走法提示）")
 	flag.BoolVar(showScoresFlag, "tips", false, "是否展示玩家棋子评分 (同 -tip)")
-	flag.Parse()
-	aiEnabled := (*modeFlag == "pve") // pve=启用 AI，pvp=禁用 AI
-	aiDepth := *depthFlag
-	showScores := *showScoresFlag
+	ttFileFlag := flag.String("tt-file", "", "置换表持久化文件：启动时加载、退出时保存（空=不落盘）")
+	hashFlag := flag.Int("hash", game.TTSizeMB(), "置换表大小（MB，按 2 的幂向下取整）；F3 性能浮层显示占用率")
+	thinkMinFlag := flag.Duration("think-min", ui.DefaultPacing.MinThink, "AI 最短思考展示时间")
+	thinkMaxFlag := flag.Duration("think-max", ui.DefaultPacing.MaxThink, "AI 思考展示时间上限（在 min~max 间随机）")
+	instantForcedFlag := flag.Bool("instant-forced", ui.DefaultPacing.InstantForced, "只有一步可走时 AI 立即应着")
+	enginePathFlag := flag.String("engine-path", "", "外部引擎可执行文件（如 cmd/engine）；空=进程内搜索")
+	rulesFlag := flag.String("rules", "standard", "规则变体: standard(原版)、cascade(连锁感染：新翻的子继续感染相邻对方子)、limited[:N](每颗原始棋子一脉合计只能克隆 N 次，默认 3)；可用 + 组合，如 cascade+limited:2")
+	fogFlag := flag.Bool("fog", false, "迷雾变体：只看得见己方棋子距离 2 以内的格子")
+	fogAIFlag := flag.String("fog-ai", ui.FogAISample, "迷雾下 AI 的搜索方式: sample(按自己视野抽样确定化局面后投票) 或 full(直接看完整局面)")
+	fogSamplesFlag := flag.Int("fog-samples", 8, "-fog-ai sample 时每步抽样的局面数")
+	mapFlag := flag.String("map", ui.MapClassic, "开局地图: classic(原版三障碍)、random(随机对称障碍，保证双方公平) 或 small(半径 2 小棋盘)")
+	radiusFlag := flag.Int("radius", game.DefaultBoardRadius, fmt.Sprintf("棋盘半径 1..%d（盘外格为障碍；大于 %d 时神经网络输入装不下，改用静态评估）", game.MaxBoardRadius, game.DefaultBoardRadius))
+	mapBlocksFlag := flag.Int("map-blocks", ui.DefaultGameConfig().MapBlocks, fmt.Sprintf("随机地图的障碍数 0..%d", game.MaxRandomBlocks))
+	mapSeedFlag := flag.Int64("map-seed", 0, "随机地图种子（0=随机取一个；界面左上角会显示，发给别人即可复现同一张图）")
+	blitzFlag := flag.Bool("blitz", false, "快棋：动画加速、音效精简，每步限时，超时随机代走")
+	blitzLimitFlag := flag.Duration("blitz-limit", ui.DefaultBlitzMoveLimit, "快棋每步限时（AI 搜索也不超过它）")
+	difficultyFlag := flag.String("difficulty", "", "难度档位（如 beginner/easy/medium/hard/expert，见 -ladder），覆盖 -depth、-eval、-bot")
+	ladderFlag := flag.String("ladder", "", "难度阶梯配置（cmd/ladder 校准输出）；空=内置默认阶梯")
+	optionsFlag := flag.String("options", "", "搜索/评估参数文件（JSON）；改动、SIGHUP 或按 F5 时热加载，AI 空闲时生效")
+	botFlag := flag.String("bot", "", "最低难度：AI 改用内置基线对手 random（随机）或 greedy（贪心吃子），忽略 -depth")
+	botScriptFlag := flag.String("bot-script", "", "脚本对手：AI 按该 Starlark 脚本（score 给每个合法着法打分取最高，或 choose 直接挑一手，接口见 internal/botscript）走棋，忽略 -depth")
+	var ortCfg game.ORTConfig
+	flag.IntVar(&ortCfg.IntraOpThreads, "ort-threads", 0, "ORT 算子内并行线程数（0=ORT 默认，即全部物理核；CPU 推理时调小可避免与搜索线程抢核）")
+	flag.IntVar(&ortCfg.InterOpThreads, "ort-inter-threads", 0, "ORT 算子间并行线程数（0=ORT 默认）")
+	flag.BoolVar(&ortCfg.NoCPUMemArena, "ort-no-arena", false, "关闭 ORT CPU 内存池")
+	flag.StringVar(&ortCfg.GraphOpt, "ort-opt", "", "ORT 图优化级别 none/basic/extended/all（空=默认 all）")
+	backendReport := flag.Bool("backend-report", false, "初始化 NN 后端并打印能力报告（EP、FP16、批量会话、建会话与热身耗时）")
+	reportFile := flag.String("report-file", "hexxagon_report.txt", "会话报告（运行环境、NN 后端、崩溃栈），报 bug 时附上；空=不写")
+	cli.Parse("hexxagon")
+	if err := ortCfg.Validate(); err != nil {
+		log.Fatalf("ORT 参数错误: %v", err)
+	}
+	game.SetORTConfig(ortCfg)
+	if *reportFile != "" {
+		if f, err := openSessionReport(*reportFile); err != nil {
+			log.Printf("会话报告未创建: %v", err)
+		} else {
+			defer f.Close()
+		}
+	}
+	if *backendReport {
+		game.WriteBackendReport(os.Stderr)
+	}
+	rules, err := game.ParseRules(*rulesFlag)
+	if err != nil {
+		log.Fatalf("参数错误: %v", err)
+	}
+	cfg := ui.GameConfig{
+		Mode:       *modeFlag,
+		Depth:      *depthFlag,
+		Evaluator:  *evalFlag,
+		TimeBudget: *budgetFlag,
+		SearchTime: *timeFlag,
+		ShowTips:   *showScoresFlag,
+		Heatmap:    *heatmapFlag,
+		Pacing:     ui.Pacing{MinThink: *thinkMinFlag, MaxThink: *thinkMaxFlag, InstantForced: *instantForcedFlag},
+		Bot:        *botFlag,
+		BotScript:  *botScriptFlag,
+		Rules:      rules,
+		Fog:        *fogFlag,
+		FogAI:      *fogAIFlag,
+		FogSamples: *fogSamplesFlag,
+		Map:        *mapFlag,
+		MapBlocks:  *mapBlocksFlag,
+		MapSeed:    *mapSeedFlag,
+
+		BoardRadius: *radiusFlag,
+
+		Blitz:          *blitzFlag,
+		BlitzMoveLimit: *blitzLimitFlag,
+
+		RecordFile:  *recordFlag,
+		ReplayFile:  *replayFlag,
+		ReplayDelay: *replayDelayFlag,
+
+		OptionsFile: *optionsFlag,
+
+		SaveFile: *saveFlag,
+	}
+	if *loadFlag != "" {
+		s, err := game.ReadSave(*loadFlag)
+		if err != nil {
+			log.Fatalf("读档失败: %v", err)
+		}
+		if cfg.Rules, err = s.ParsedRules(); err != nil {
+			log.Fatalf("读档失败: %v", err)
+		}
+		if cfg.Rules != rules {
+			log.Printf("按存档的规则 %s 开局（忽略 -rules %s）", cfg.Rules, rules)
+		}
+		cfg.Resume = &s
+		if cfg.SaveFile == "" {
+			cfg.SaveFile = *loadFlag
+		}
+	}
+	if cfg.SaveFile == "" {
+		cfg.SaveFile = ui.DefaultSaveFile
+	}
+	if *difficultyFlag != "" {
+		ladder := game.DefaultLadder()
+		if *ladderFlag != "" {
+			if ladder, err = game.LoadLadder(*ladderFlag); err != nil {
+				log.Fatalf("读取难度阶梯失败: %v", err)
+			}
+		}
+		p, err := ladder.Preset(*difficultyFlag)
+		if err != nil {
+			log.Fatalf("参数错误: %v", err)
+		}
+		cfg.ApplyPreset(p)
+		log.Printf("难度 %s: %s（约 %+.0f Elo，相对随机基线）", p.Name, p.Setting(), p.Elo)
+	}
+	for _, side := range []struct {
+		spec string
+		dst  **ui.AIPlayer
+	}{{*redFlag, &cfg.Red}, {*whiteFlag, &cfg.White}} {
+		if side.spec == "" {
+			continue
+		}
+		if *side.dst, err = ui.ParseAIPlayer(side.spec, cfg.DefaultAIPlayer()); err != nil {
+			log.Fatalf("参数错误: %v", err)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("参数错误: %v", err)
+	}
+	var remoteSide game.CellState
+	if cfg.Mode == "net" {
+		if (*netListenFlag == "") == (*netConnectFlag == "") {
+			log.Fatal("参数错误: -mode net 须且只须指定 -net-listen 或 -net-connect 之一")
+		}
+		switch *netSideFlag {
+		case "red":
+			remoteSide = game.PlayerB
+		case "white":
+			remoteSide = game.PlayerA
+		default:
+			log.Fatalf("参数错误: -net-side %q（可选 red/white）", *netSideFlag)
+		}
+	}
+	// 客户端先连上主机，按主机的规则建界面
+	var session *net.Session
+	if *netConnectFlag != "" {
+		if session, err = net.Dial(*netConnectFlag); err != nil {
+			log.Fatalf("连接主机失败: %v", err)
+		}
+		defer session.Close()
+		cfg.Rules = session.Rules()
+		log.Printf("已连上 %s，本方执 %s", *netConnectFlag, sideLabel(session.LocalSide()))
+	}
 
 	// 在后台立即开始初始化 ONNX/TensorRT 编译
 	game.PreloadModels()
@@ -63,22 +228,82 @@ func main() {
 		log.Fatal("audio context not initialized")
 	}
 
-	screen, err := ui.NewGameScreen(ctx, aiEnabled, aiDepth, showScores) // 传入 AI 开关和深度
+	screen, err := ui.NewGameScreen(ctx, cfg)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if *enginePathFlag != "" {
+		// 引擎必须按同一套规则、同样的评估搜索：-eval 与各方的 NN 开关已由 NewGameScreen 落到 game 包的开关上，
+		// 原样转给引擎的 -nn-a/-nn-b；-think-budget 每步随 go 命令的 movetime 下发（见 ui.searchWith）
+		args := []string{
+			fmt.Sprintf("-nn-a=%t", game.UseONNXForPlayerA),
+			fmt.Sprintf("-nn-b=%t", game.UseONNXForPlayerB),
+		}
+		if !cfg.Rules.IsStandard() {
+			args = append(args, "-rules", cfg.Rules.String())
+		}
+		eng, err := engine.Start(*enginePathFlag, args...)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer eng.Close()
+		log.Printf("使用外部引擎: %s", eng.Name)
+		if *hashFlag != game.TTSizeMB() {
+			if err := eng.SetHash(*hashFlag); err != nil {
+				log.Printf("外部引擎不接受 -hash: %v", err)
+			}
+		}
+		screen.SetEngine(eng)
+	}
+	if *netListenFlag != "" {
+		if session, err = net.Listen(*netListenFlag, screen.OpeningState(), remoteSide); err != nil {
+			log.Fatalf("联机监听失败: %v", err)
+		}
+		defer session.Close()
+		log.Printf("联机主机: 在 %s 等对方连入，本方执 %s", session.Addr(), sideLabel(session.LocalSide()))
+	}
+	if session != nil {
+		screen.SetRemote(session)
+	}
 	//ebiten.SetFPSMode(ebiten.FPSModeVsyncOffMinimum)
 	ebiten.SetVsyncEnabled(true)
 	ebiten.SetTPS(60)
 	ebiten.SetWindowSize(screenW*ScreenScale, screenH*ScreenScale)
-	ebiten.SetWindowTitle("Hexxagon")
+	title := "Hexxagon"
+	if !cfg.Rules.IsStandard() {
+		title += " (" + cfg.Rules.String() + " rules)"
+	}
+	ebiten.SetWindowTitle(title)
+
+	if *hashFlag != game.TTSizeMB() {
+		game.ResizeTT(*hashFlag)
+	}
+	if *ttFileFlag != "" {
+		if n, err := game.LoadTT(*ttFileFlag); err != nil {
+			log.Printf("置换表未加载: %v", err)
+		} else {
+			log.Printf("置换表已加载: %d 条", n)
+		}
+	}
 
 	if err := ebiten.RunGame(screen); err != nil {
 		log.Fatal(err)
 	}
+
+	if *ttFileFlag != "" {
+		if err := game.SaveTT(*ttFileFlag); err != nil {
+			log.Printf("置换表保存失败: %v", err)
+		}
+	}
+}
+
+func sideLabel(s game.CellState) string {
+	if s == game.PlayerA {
+		return "red"
+	}
+	return "white"
 }
 
-// $dll = "D:\go\ddddocr_go\gpu\onnxruntime.dll"
-// $env:ONNXRUNTIME_SHARED_LIBRARY_PATH = $dll
-// $env:Path = "D:\go\ddddocr_go\gpu;" + $env:Path
-// go build -ldflags="-s -w" -gcflags="all=-trimpath=${PWD}" -asmflags="all=-trimpath=${PWD}" -o hexxagon.exe .\cmd\hexxagon\main.go
+// 发布打包见 cmd/package：
+//   go run ./cmd/package -ort-dir D:\go\ddddocr_go\gpu
+// 会编译 hexxagon.exe，并把 ORT DLL、许可文件、启动脚本（可选 trt_cache）组装进 dist/ 再打 zip。
@@ -13,6 +13,7 @@ import (
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"hexxagon_go/internal/cli"
 	"hexxagon_go/internal/game"
 )
 
@@ -24,12 +25,55 @@ const (
 )
 
 type Step struct {
-	Move  game.Move      `json:"move"`
-	Board map[string]int `json:"board"`
+	Move     game.Move `json:"move"`
+	Position string    `json:"position,omitempty"` // 走完这手后的 PositionString；hexxagon -record 的老文件没有
 }
 type Match struct {
 	Winner string `json:"winner"`
 	Steps  []Step `json:"steps"`
+	Start  string `json:"start,omitempty"` // hexxagon -record 写的开局 PositionString；空=标准开局
+	Rules  string `json:"rules,omitempty"`
+}
+
+// replayTo 按本盘的开局与规则重放前 n 手
+func (m Match) replayTo(n int) (*game.GameState, error) {
+	rules, err := game.ParseRules(m.Rules)
+	if err != nil {
+		return nil, err
+	}
+	var start *game.GameState
+	if m.Start != "" {
+		if start, err = game.ParsePosition(m.Start); err != nil {
+			return nil, err
+		}
+	}
+	game.SetRules(rules)
+	return game.ReplayMoves(start, m.moves()[:n])
+}
+
+// verify 重放全部着法；带了局面的手，重放出来的局面须与录像一致（同 ui.ReplayMatch.verify）
+func (m Match) verify() error {
+	st, err := m.replayTo(0)
+	if err != nil {
+		return err
+	}
+	for i, step := range m.Steps {
+		if _, _, err := st.MakeMove(step.Move); err != nil {
+			return fmt.Errorf("move %d: %w", i+1, err)
+		}
+		if step.Position != "" && step.Position != st.PositionString() {
+			return fmt.Errorf("move %d: replayed position %s, record has %s", i+1, st.PositionString(), step.Position)
+		}
+	}
+	return nil
+}
+
+func (m Match) moves() []game.Move {
+	mv := make([]game.Move, len(m.Steps))
+	for i, st := range m.Steps {
+		mv[i] = st.Move
+	}
+	return mv
 }
 
 type ReplayGame struct {
@@ -51,16 +95,27 @@ func NewReplayGame(path string, delay time.Duration) (*ReplayGame, error) {
 	if err := json.Unmarshal(data, &matches); err != nil {
 		return nil, fmt.Errorf("unmarshal JSON: %w", err)
 	}
-	// 初始化第一盘、第一步前的 Board
-	state := game.NewGameState(boardRadius)
-	return &ReplayGame{
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%s: no matches", path)
+	}
+	// 先整盘校验一遍：非法走法、与录像对不上的局面直接报出第几盘第几手，而不是播放到一半画出错乱的局面
+	for i, m := range matches {
+		if err := m.verify(); err != nil {
+			return nil, fmt.Errorf("match %d: %w", i+1, err)
+		}
+	}
+	g := &ReplayGame{
 		matches:     matches,
 		mi:          0,
 		si:          -1, // -1 意味着先画初始局面
 		lastAdvance: time.Now(),
 		delay:       delay,
-		board:       state.Board,
-	}, nil
+	}
+	// 初始化第一盘、第一步前的 Board
+	if err := g.rebuild(); err != nil {
+		return nil, err
+	}
+	return g, nil
 }
 
 func (g *ReplayGame) Layout(outsideWidth, outsideHeight int) (w, h int) {
@@ -75,48 +130,47 @@ func (g *ReplayGame) Update() error {
 	// 右方向：单步前进
 	if inpututil.IsKeyJustPressed(ebiten.KeyRight) {
 		g.playing = false
-		g.advance()
+		if err := g.advance(); err != nil {
+			return err
+		}
 	}
 	// 左方向：单步后退
 	if inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
 		g.playing = false
-		g.rewind()
+		if err := g.rewind(); err != nil {
+			return err
+		}
 	}
 
 	// --- 2) 自动播放 ---
 	if g.playing && time.Since(g.lastAdvance) >= g.delay {
-		g.advance()
+		return g.advance()
 	}
 	return nil
 }
 
 // advance 做一步前进（或切到下盘／结束）
-func (g *ReplayGame) advance() {
+func (g *ReplayGame) advance() error {
 	g.lastAdvance = time.Now()
-	g.si++
 	if g.mi >= len(g.matches) {
-		return
+		return nil
 	}
-	match := g.matches[g.mi]
-	if g.si >= len(match.Steps) {
+	g.si++
+	if g.si >= len(g.matches[g.mi].Steps) {
+		if g.mi+1 >= len(g.matches) {
+			// 最后一盘已播完，停在终局
+			g.si--
+			g.playing = false
+			return nil
+		}
 		g.mi++
 		g.si = -1
-		g.board = game.NewGameState(boardRadius).Board
-		return
-	}
-	if g.si >= 0 {
-		step := match.Steps[g.si]
-		pl := game.PlayerA
-		if g.si%2 == 1 {
-			pl = game.PlayerB
-		}
-		step.Move.MakeMove(g.board, pl)
-		g.board.LastMove = step.Move
 	}
+	return g.rebuild()
 }
 
 // rewind 往回一步（重建到 si-1）
-func (g *ReplayGame) rewind() {
+func (g *ReplayGame) rewind() error {
 	if g.si < 0 {
 		// 如果已经在初始，那就退到上一盘最后一步
 		if g.mi > 0 {
@@ -126,17 +180,23 @@ func (g *ReplayGame) rewind() {
 	} else {
 		g.si--
 	}
-	// 重建 board 到当前 mi, si
-	g.board = game.NewGameState(boardRadius).Board
-	for i := 0; i <= g.si; i++ {
-		step := g.matches[g.mi].Steps[i]
-		pl := game.PlayerA
-		if i%2 == 1 {
-			pl = game.PlayerB
-		}
-		step.Move.MakeMove(g.board, pl)
-		g.board.LastMove = step.Move
+	return g.rebuild()
+}
+
+// rebuild 按本盘规则（含换手/终局）重放到当前 mi, si。录像里的局面只在加载时拿来核对，
+// 不直接解码：出错时把错误交给 Update 结束播放，不留着上一手的棋盘
+func (g *ReplayGame) rebuild() error {
+	m := g.matches[g.mi]
+	moves := m.moves()[:g.si+1]
+	st, err := m.replayTo(len(moves))
+	if err != nil {
+		return fmt.Errorf("replay: match %d step %d: %w", g.mi+1, g.si+1, err)
 	}
+	g.board = st.Board
+	if len(moves) > 0 {
+		g.board.LastMove = moves[len(moves)-1]
+	}
+	return nil
 }
 
 func (g *ReplayGame) Draw(screen *ebiten.Image) {
@@ -151,7 +211,7 @@ func (g *ReplayGame) Draw(screen *ebiten.Image) {
 		// 边
 		ebitenutil.DrawRect(screen, float64(x-hexSize), float64(y-2), float64(2*hexSize), 4, color.White)
 		// 棋子
-		st := g.board.Get(c)
+		st := g.board.GetI(game.IndexOf[c])
 		switch st {
 		case game.PlayerA:
 			ebitenutil.DrawRect(screen, float64(x-hexSize/2), float64(y-hexSize/2), float64(hexSize), float64(hexSize), color.RGBA{0xff, 0x00, 0x00, 0xff})
@@ -189,7 +249,7 @@ func (g *ReplayGame) Draw(screen *ebiten.Image) {
 func main() {
 	jsonPath := flag.String("in", "selfplay.json", "自对弈 JSON 文件")
 	delay := flag.Duration("delay", 300*time.Millisecond, "每步播放间隔")
-	flag.Parse()
+	cli.Parse("replay")
 
 	game, err := NewReplayGame(*jsonPath, *delay)
 	if err != nil {
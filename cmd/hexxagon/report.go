@@ -0,0 +1,60 @@
+// cmd/hexxagon/report.go
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"hexxagon_go/internal/game"
+)
+
+// 会话报告：每次启动覆盖写一份，记录运行环境和 NN 后端（含 GPU 回退原因）；
+// 进程崩溃时 Go 运行时把 panic 和全部栈追加到同一个文件（debug.SetCrashOutput），
+// 用户报 bug 时附上这一个文件就够了。
+
+// sessionReport 打开着的会话报告；NN 后端的探测在后台往里写
+type sessionReport struct {
+	f     *os.File
+	probe chan struct{} // 后台探测写完后关闭
+}
+
+// openSessionReport 创建报告文件并登记为崩溃输出；name 为相对路径时放在 exe 旁边
+func openSessionReport(name string) (*sessionReport, error) {
+	if !filepath.IsAbs(name) {
+		exe, _ := os.Executable()
+		name = filepath.Join(filepath.Dir(exe), name)
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(f, "hexxagon session %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(f, "go %s %s/%s cpus=%d\n", runtime.Version(), runtime.GOOS, runtime.GOARCH, runtime.NumCPU())
+	fmt.Fprintf(f, "args: %s\n", strings.Join(os.Args[1:], " "))
+	if err := debug.SetCrashOutput(f, debug.CrashOptions{}); err != nil {
+		fmt.Fprintf(f, "(crash output not installed: %v)\n", err)
+	}
+
+	// 后端初始化可能要几分钟（TensorRT 编译），结束后把报告补进来
+	r := &sessionReport{f: f, probe: make(chan struct{})}
+	go func() {
+		defer close(r.probe)
+		if err := game.EnsureNNBackend(); err != nil {
+			fmt.Fprintf(f, "NN backend init failed: %v\n", err)
+		}
+		fmt.Fprintln(f, game.GetBackendReport())
+	}()
+	return r, nil
+}
+
+// Close 等后台探测写完再关文件，并撤下崩溃输出
+func (r *sessionReport) Close() error {
+	<-r.probe
+	debug.SetCrashOutput(nil, debug.CrashOptions{})
+	return r.f.Close()
+}
@@ -0,0 +1,311 @@
+// cmd/import_screenshot/main.go
+// 从本游戏界面的截图里认出局面，输出 PositionString，方便报 bug 或分析别人发来的截图。
+// 做法很朴素：按界面的棋盘几何算出每格中心，取中心一小块的平均颜色，
+// 与棋子贴图算出的参考色比对（最近邻），背景黑色即障碍格。
+// 要求截图是窗口客户区（800×600 或其等比缩放），且没有选中棋子、提示圈和评分浮层。
+//
+//	go run ./cmd/import_screenshot -to-move white shot.png
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"image/png"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"hexxagon_go/internal/cli"
+	"hexxagon_go/internal/game"
+)
+
+// 与 internal/ui 的画面布局一致
+const (
+	windowW     = 800
+	windowH     = 600
+	boardRadius = 4
+)
+
+// 贴图缺失时用的默认值：hex_space.png 的宽高比与两种棋子的平均色
+var (
+	defaultTileAspect = 536.0 / 310.0
+	defaultRed        = rgb{200, 60, 60}
+	defaultWhite      = rgb{225, 225, 225}
+	emptyBase         = rgb{49, 83, 127} // 格子底色，见 ui.bakeBoardBase
+)
+
+type rgb struct{ r, g, b float64 }
+
+// chroma 去掉亮度只留色相/饱和度：棋盘有左上亮右下暗的渐变，直接比 RGB 会受位置影响
+func (c rgb) chroma() rgb {
+	s := c.r + c.g + c.b
+	if s == 0 {
+		return rgb{}
+	}
+	return rgb{c.r / s, c.g / s, c.b / s}
+}
+
+func (c rgb) luma() float64 { return 0.299*c.r + 0.587*c.g + 0.114*c.b }
+
+func dist(a, b rgb) float64 {
+	return math.Sqrt((a.r-b.r)*(a.r-b.r) + (a.g-b.g)*(a.g-b.g) + (a.b-b.b)*(a.b-b.b))
+}
+
+type templates struct {
+	tileAspect float64
+	ref        map[game.CellState]rgb // 已取 chroma
+}
+
+// loadTemplates 从 assets 目录读贴图算参考色；读不到就用内置默认值
+func loadTemplates(dir string) templates {
+	t := templates{
+		tileAspect: defaultTileAspect,
+		ref: map[game.CellState]rgb{
+			game.PlayerA: defaultRed.chroma(),
+			game.PlayerB: defaultWhite.chroma(),
+			game.Empty:   emptyBase.chroma(),
+		},
+	}
+	if img, err := loadPNG(filepath.Join(dir, "hex_space.png")); err == nil {
+		b := img.Bounds()
+		t.tileAspect = float64(b.Dx()) / float64(b.Dy())
+		if avg, ok := opaqueMean(img); ok {
+			// 格子贴图半透明叠在底色上，两者平均近似屏幕上的空格颜色
+			t.ref[game.Empty] = rgb{(avg.r + emptyBase.r) / 2, (avg.g + emptyBase.g) / 2, (avg.b + emptyBase.b) / 2}.chroma()
+		}
+	} else {
+		log.Printf("未读到格子贴图，使用内置几何: %v", err)
+	}
+	for side, name := range map[game.CellState]string{game.PlayerA: "red_piece.png", game.PlayerB: "white_piece.png"} {
+		img, err := loadPNG(filepath.Join(dir, name))
+		if err != nil {
+			log.Printf("未读到 %s，使用内置参考色: %v", name, err)
+			continue
+		}
+		if avg, ok := opaqueMean(img); ok {
+			t.ref[side] = avg.chroma()
+		}
+	}
+	return t
+}
+
+func loadPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+// opaqueMean 不透明像素的平均色
+func opaqueMean(img image.Image) (rgb, bool) {
+	var sum rgb
+	n := 0
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, a := img.At(x, y).RGBA()
+			if a < 0xC000 {
+				continue
+			}
+			sum.r += float64(r>>8) * 0xFFFF / float64(a)
+			sum.g += float64(g>>8) * 0xFFFF / float64(a)
+			sum.b += float64(bl>>8) * 0xFFFF / float64(a)
+			n++
+		}
+	}
+	if n == 0 {
+		return rgb{}, false
+	}
+	return rgb{sum.r / float64(n), sum.g / float64(n), sum.b / float64(n)}, true
+}
+
+// geometry 屏幕（800×600 坐标系）上每格中心与格子高度，公式同 ui.getBoardTransform
+type geometry struct {
+	orgX, orgY, tileW, tileH, vs float64
+}
+
+func boardGeometry(tileAspect float64) geometry {
+	// 只有宽高比要紧：先按高度 1 算，再整体缩放到窗口
+	tileW, tileH := tileAspect, 1.0
+	vs := tileH * math.Sqrt(3) / 2
+	n := float64(2*boardRadius + 1)
+	boardW := (n-1)*tileW*0.75 + tileW
+	boardH := vs*(n-1) + tileH
+	scale := math.Min(windowW/boardW, windowH/boardH)
+	return geometry{
+		orgX:  (windowW - boardW*scale) / 2,
+		orgY:  (windowH - boardH*scale) / 2,
+		tileW: tileW * scale,
+		tileH: tileH * scale,
+		vs:    vs * scale,
+	}
+}
+
+func (g geometry) center(c game.HexCoord) (float64, float64) {
+	x := (float64(c.Q)+boardRadius)*g.tileW*0.75 + g.tileW/2
+	y := (float64(c.R)+boardRadius+float64(c.Q)/2)*g.vs + g.tileH/2
+	return g.orgX + x, g.orgY + y
+}
+
+// sample 取以 (cx,cy) 为圆心、半径 rad 的圆内平均色；坐标按截图尺寸等比换算
+func sample(img image.Image, cx, cy, rad float64) rgb {
+	b := img.Bounds()
+	sx := float64(b.Dx()) / windowW
+	sy := float64(b.Dy()) / windowH
+	cx, cy = cx*sx+float64(b.Min.X), cy*sy+float64(b.Min.Y)
+	rx, ry := rad*sx, rad*sy
+	var sum rgb
+	n := 0
+	for y := int(cy - ry); y <= int(cy+ry); y++ {
+		for x := int(cx - rx); x <= int(cx+rx); x++ {
+			dx, dy := (float64(x)-cx)/rx, (float64(y)-cy)/ry
+			if dx*dx+dy*dy > 1 || !(image.Point{X: x, Y: y}).In(b) {
+				continue
+			}
+			r, g, bl, _ := img.At(x, y).RGBA()
+			sum.r += float64(r >> 8)
+			sum.g += float64(g >> 8)
+			sum.b += float64(bl >> 8)
+			n++
+		}
+	}
+	if n == 0 {
+		return rgb{}
+	}
+	return rgb{sum.r / float64(n), sum.g / float64(n), sum.b / float64(n)}
+}
+
+type cellGuess struct {
+	state  game.CellState
+	margin float64 // 最近与次近参考色的距离差，越小越拿不准
+}
+
+func classify(c rgb, t templates) cellGuess {
+	if c.luma() < 20 {
+		return cellGuess{state: game.Blocked, margin: 1}
+	}
+	ch := c.chroma()
+	best, second := math.Inf(1), math.Inf(1)
+	var state game.CellState
+	for s, ref := range t.ref {
+		d := dist(ch, ref)
+		switch {
+		case d < best:
+			best, second, state = d, best, s
+		case d < second:
+			second = d
+		}
+	}
+	return cellGuess{state: state, margin: second - best}
+}
+
+func symbol(s game.CellState) string {
+	switch s {
+	case game.PlayerA:
+		return "R"
+	case game.PlayerB:
+		return "W"
+	case game.Blocked:
+		return "#"
+	}
+	return "."
+}
+
+// printBoard 按行画出认出的局面，存疑的格子后面标 ?
+func printBoard(cells []game.CellState, unsure map[int]bool) {
+	for r := -boardRadius; r <= boardRadius; r++ {
+		var sb strings.Builder
+		sb.WriteString(strings.Repeat(" ", abs(r)))
+		for q := -boardRadius; q <= boardRadius; q++ {
+			i, ok := game.IndexOf[game.HexCoord{Q: q, R: r}]
+			if !ok || i >= len(cells) {
+				continue
+			}
+			mark := " "
+			if unsure[i] {
+				mark = "?"
+			}
+			sb.WriteString(symbol(cells[i]) + mark)
+		}
+		fmt.Fprintln(os.Stderr, sb.String())
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func main() {
+	assetsDir := flag.String("assets", filepath.Join("internal", "assets", "images"), "贴图目录，用来取格子宽高比与棋子参考色")
+	toMove := flag.String("to-move", "red", "截图时轮到谁走: red 或 white（截图看不出来）")
+	minMargin := flag.Float64("min-margin", 0.02, "最近与次近参考色的差小于它的格子标为存疑")
+	cli.Parse("import_screenshot")
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "用法: import_screenshot [参数] 截图.png")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+	side := game.PlayerA
+	switch *toMove {
+	case "red":
+	case "white":
+		side = game.PlayerB
+	default:
+		log.Fatalf("-to-move 只能是 red 或 white: %q", *toMove)
+	}
+
+	f, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	img, _, err := image.Decode(f)
+	f.Close()
+	if err != nil {
+		log.Fatalf("解码截图失败: %v", err)
+	}
+	if b := img.Bounds(); math.Abs(float64(b.Dx())/float64(b.Dy())-float64(windowW)/windowH) > 0.02 {
+		log.Printf("警告: 截图 %dx%d 不是 4:3，可能带了窗口边框，识别会偏", b.Dx(), b.Dy())
+	}
+
+	t := loadTemplates(*assetsDir)
+	g := boardGeometry(t.tileAspect)
+	cells := make([]game.CellState, game.CellCount(boardRadius))
+	unsure := map[int]bool{}
+	for i := range cells {
+		cx, cy := g.center(game.CoordOf[i])
+		guess := classify(sample(img, cx, cy, g.tileH*0.18), t)
+		cells[i] = guess.state
+		if guess.margin < *minMargin {
+			unsure[i] = true
+		}
+	}
+
+	b, err := game.BoardFromCells(cells)
+	if err != nil {
+		log.Fatal(err)
+	}
+	st := &game.GameState{Board: b, CurrentPlayer: side}
+	printBoard(cells, unsure)
+	fmt.Fprintf(os.Stderr, "红 %d  白 %d  障碍 %d  存疑 %d 格\n",
+		b.CountPieces(game.PlayerA), b.CountPieces(game.PlayerB), countState(cells, game.Blocked), len(unsure))
+	fmt.Println(st.PositionString())
+}
+
+func countState(cells []game.CellState, s game.CellState) int {
+	n := 0
+	for _, c := range cells {
+		if c == s {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,229 @@
+// cmd/ladder/main.go
+// 难度阶梯校准：让各档候选设置（基线对手 random/greedy、静态评估 d1..dN、可选 NN d1..dN）循环赛，
+// 拟合 Elo（随机基线 = 0），再给每档难度挑离目标 Elo 最近、且不弱于上一档的设置，写回阶梯配置。
+// 引擎有改进后重跑一次，"medium" 等名字对应的实际强度就能保持不变。
+//
+//	go run ./cmd/ladder -games 20 -out ladder.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"time"
+
+	"hexxagon_go/internal/cli"
+	"hexxagon_go/internal/elo"
+	"hexxagon_go/internal/game"
+)
+
+// candidate 一组可选的 AI 设置
+type candidate struct {
+	bot   string
+	depth int
+	nn    bool
+}
+
+func (c candidate) name() string {
+	if c.bot != "" {
+		return c.bot
+	}
+	return c.preset().Setting()
+}
+
+func (c candidate) preset() game.Preset {
+	if c.bot != "" {
+		return game.Preset{Bot: c.bot}
+	}
+	ev := game.PresetEvalStatic
+	if c.nn {
+		ev = game.PresetEvalNN
+	}
+	return game.Preset{Depth: c.depth, Evaluator: ev}
+}
+
+func (c candidate) move(st *game.GameState, rng *rand.Rand) (game.Move, bool) {
+	side := st.CurrentPlayer
+	if c.bot != "" {
+		mv, ok, err := game.BaselineMove(c.bot, st.Board, side, true, rng)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return mv, ok
+	}
+	// 同一时刻只有一方在搜索，两个开关都按这一方设
+	game.UseONNXForPlayerA, game.UseONNXForPlayerB = c.nn, c.nn
+	mv, _, ok := game.IterativeDeepening(st.Board, side, c.depth, true)
+	return mv, ok
+}
+
+// playGame 随机开局 open 手后由 a 执红、b 执白下完；返回红方得分 1/0.5/0
+func playGame(a, b candidate, open, maxPlies int, seed int64) float64 {
+	rng := rand.New(rand.NewSource(seed))
+	st := game.NewGameState(4)
+	players := map[game.CellState]candidate{game.PlayerA: a, game.PlayerB: b}
+	for ply := 0; ply < maxPlies && !st.GameOver; ply++ {
+		if st.AdjudicateIfBlocked() {
+			break
+		}
+		var mv game.Move
+		var ok bool
+		if ply < open {
+			mvs := game.GenerateMoves(st.Board, st.CurrentPlayer) // 被堵死的情况上面已裁定
+			mv, ok = mvs[rng.Intn(len(mvs))], true
+		} else {
+			mv, ok = players[st.CurrentPlayer].move(st, rng)
+		}
+		if !ok {
+			break
+		}
+		if _, _, err := st.MakeMove(mv); err != nil {
+			log.Fatalf("%s 走了非法着 %v: %v", players[st.CurrentPlayer].name(), mv, err)
+		}
+	}
+	winner := st.Winner
+	if !st.GameOver {
+		switch {
+		case st.ScoreA > st.ScoreB:
+			winner = game.PlayerA
+		case st.ScoreB > st.ScoreA:
+			winner = game.PlayerB
+		default:
+			winner = game.Empty
+		}
+	}
+	switch winner {
+	case game.PlayerA:
+		return 1
+	case game.PlayerB:
+		return 0
+	}
+	return 0.5
+}
+
+// assign 给每档挑离目标最近的候选；不比上一档弱，避免阶梯倒挂
+func assign(l *game.Ladder, cands []candidate, elo []float64) {
+	order := make([]int, len(cands))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return elo[order[a]] < elo[order[b]] })
+
+	floor := math.Inf(-1)
+	for k := range l.Presets {
+		p := &l.Presets[k]
+		pick := -1
+		for _, i := range order {
+			if elo[i] < floor {
+				continue
+			}
+			if pick < 0 || math.Abs(elo[i]-p.TargetElo) < math.Abs(elo[pick]-p.TargetElo) {
+				pick = i
+			}
+		}
+		if pick < 0 {
+			pick = order[len(order)-1] // 没有更强的了，停在最强一档
+		}
+		set := cands[pick].preset()
+		p.Bot, p.Depth, p.Evaluator = set.Bot, set.Depth, set.Evaluator
+		p.Elo = math.Round(elo[pick])
+		floor = elo[pick]
+	}
+}
+
+func main() {
+	var (
+		inPath        = flag.String("in", "", "已有阶梯配置，沿用其中的档位与目标 Elo（空=内置默认阶梯）")
+		games         = flag.Int("games", 10, "每对候选下几局（两两轮换先手，取偶数）")
+		maxDepth      = flag.Int("max-depth", 3, "参赛的最大搜索深度")
+		withNN        = flag.Bool("nn", false, "候选里加入 NN 评估 d1..max-depth")
+		open          = flag.Int("random-open", 2, "开局随机手数，让同一对候选下出不同的棋")
+		maxPlies      = flag.Int("max-plies", 300, "单局最多手数，到了按子数判")
+		backendReport = flag.Bool("backend-report", false, "初始化 NN 后端并打印能力报告（EP、FP16、批量会话、建会话与热身耗时）")
+	)
+	common := cli.Common{Out: "ladder.json", Seed: 1}
+	common.Register(flag.CommandLine, cli.Out|cli.Seed)
+	cli.Parse("ladder")
+	if *games < 2 || *maxDepth < 1 {
+		log.Fatalf("参数错误: -games 须 >= 2，-max-depth 须 >= 1")
+	}
+	if *backendReport {
+		game.WriteBackendReport(os.Stderr)
+	}
+
+	ladder := game.DefaultLadder()
+	if *inPath != "" {
+		var err error
+		if ladder, err = game.LoadLadder(*inPath); err != nil {
+			log.Fatalf("读取阶梯失败: %v", err)
+		}
+	}
+
+	cands := []candidate{{bot: game.BotRandom}, {bot: game.BotGreedy}}
+	for d := 1; d <= *maxDepth; d++ {
+		cands = append(cands, candidate{depth: d})
+	}
+	if *withNN {
+		for d := 1; d <= *maxDepth; d++ {
+			cands = append(cands, candidate{depth: d, nn: true})
+		}
+	}
+
+	n := len(cands)
+	points := make([][]float64, n)
+	played := make([][]float64, n)
+	for i := range points {
+		points[i] = make([]float64, n)
+		played[i] = make([]float64, n)
+	}
+	start := time.Now()
+	pairs := n * (n - 1) / 2
+	done := 0
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			// 每个开局下两盘，交换先手
+			for g := 0; g < *games/2; g++ {
+				s := common.Seed + int64(g)
+				si := playGame(cands[i], cands[j], *open, *maxPlies, s)
+				si += 1 - playGame(cands[j], cands[i], *open, *maxPlies, s)
+				points[i][j] += si
+				points[j][i] += 2 - si
+				played[i][j] += 2
+				played[j][i] += 2
+			}
+			done++
+			log.Printf("[%d/%d] %-10s vs %-10s  %.1f : %.1f", done, pairs,
+				cands[i].name(), cands[j].name(), points[i][j], points[j][i])
+		}
+	}
+
+	ratings := elo.BradleyTerry(points, played, 0) // 相对 random
+	fmt.Printf("\n候选设置（Elo 相对 random，每对 %d 局，用时 %s）\n", *games/2*2, time.Since(start).Round(time.Second))
+	for i, c := range cands {
+		var p, g float64
+		for j := range cands {
+			p += points[i][j]
+			g += played[i][j]
+		}
+		fmt.Printf("  %-10s Elo %+6.0f  得分 %.1f/%.0f\n", c.name(), ratings[i], p, g)
+	}
+
+	assign(ladder, cands, ratings)
+	game.UseONNXForPlayerA, game.UseONNXForPlayerB = *withNN, *withNN
+	ladder.Engine = game.EvaluatorID()
+	ladder.Calibrated = time.Now().Format("2006-01-02")
+	ladder.Games = *games / 2 * 2
+
+	fmt.Println("\n难度阶梯")
+	for _, p := range ladder.Presets {
+		fmt.Printf("  %-10s 目标 %+5.0f  实测 %+5.0f  %s\n", p.Name, p.TargetElo, p.Elo, p.Setting())
+	}
+	if err := ladder.Save(common.Out); err != nil {
+		log.Fatalf("写 %s 失败: %v", common.Out, err)
+	}
+	fmt.Printf("已写入: %s\n", common.Out)
+}
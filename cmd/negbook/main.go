@@ -0,0 +1,109 @@
+// cmd/negbook/main.go
+// 从 matchlog 日志（-matchlog 写出的 JSON Lines）统计负面开局库：
+// 每局前 -plies 手里，走子方最后输掉的 (局面, 着法) 记一负。败率达到 -threshold、
+// 样本不少于 -min-games 的线在引擎 -negbook 加载后会在根层被避开（见 internal/game/negbook.go）。
+//
+//	go run ./cmd/negbook -out negbook.json selfplay.jsonl tournament.jsonl
+//
+// 非正常结束（崩溃、超时、非法着、手数上限等，end 记录带 reason）的对局不计入。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"hexxagon_go/internal/cli"
+	"hexxagon_go/internal/engine"
+	"hexxagon_go/internal/game"
+	"hexxagon_go/internal/matchlog"
+)
+
+func main() {
+	common := cli.Common{Out: "negbook.json"}
+	common.Register(flag.CommandLine, cli.Out)
+	plies := flag.Int("plies", 16, "只统计每局前这么多手（开局线）")
+	threshold := flag.Float64("threshold", 0.7, "败率不低于它算已知输棋线")
+	minGames := flag.Int("min-games", 8, "样本不少于它才下结论（也是写进文件的最少对局数）")
+	cli.Parse("negbook")
+	if flag.NArg() == 0 {
+		log.Fatal("用法: negbook [-out negbook.json] 日志.jsonl...")
+	}
+	if *threshold <= 0 || *threshold > 1 || *minGames < 1 {
+		log.Fatalf("-threshold 须在 (0,1]、-min-games 须 >= 1")
+	}
+
+	nb := game.NewNegBook(*threshold, *minGames)
+	var games, skipped int
+	for _, path := range flag.Args() {
+		g, s, err := addLog(nb, path, *plies)
+		if err != nil {
+			log.Fatalf("%s: %v", path, err)
+		}
+		games += g
+		skipped += s
+	}
+	if err := nb.Save(common.Out); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%d 局（跳过非正常结束 %d 局），%d 条线，其中已知输棋线 %d 条 → %s\n",
+		games, skipped, nb.Len(), nb.LosingCount(), common.Out)
+}
+
+type pending struct {
+	hash uint64
+	side game.CellState
+	mv   game.Move
+}
+
+// addLog 读一个日志文件；同一局的行是连续的，遇到 end 记录时结算这一局
+func addLog(nb *game.NegBook, path string, plies int) (games, skipped int, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	var cur []pending
+	err = matchlog.Read(f, func(r matchlog.Record) error {
+		switch r.Event {
+		case matchlog.EventMove:
+			if r.Ply >= plies {
+				return nil
+			}
+			h, err := strconv.ParseUint(r.Hash, 16, 64)
+			if err != nil {
+				return fmt.Errorf("第 %d 局第 %d 手 hash: %w", r.Game, r.Ply, err)
+			}
+			mv, err := engine.ParseMove(r.Move)
+			if err != nil {
+				return fmt.Errorf("第 %d 局第 %d 手: %w", r.Game, r.Ply, err)
+			}
+			cur = append(cur, pending{hash: h, side: sideOf(r.Side), mv: mv})
+		case matchlog.EventEnd:
+			if r.Reason != "" {
+				skipped++
+			} else {
+				winner := sideOf(r.Winner)
+				for _, p := range cur {
+					nb.Add(p.hash, p.side, p.mv, winner == game.Opponent(p.side))
+				}
+				games++
+			}
+			cur = cur[:0]
+		}
+		return nil
+	})
+	return games, skipped, err
+}
+
+func sideOf(name string) game.CellState {
+	switch name {
+	case "red":
+		return game.PlayerA
+	case "white":
+		return game.PlayerB
+	}
+	return game.Empty
+}
@@ -0,0 +1,92 @@
+// cmd/nnserver/main.go
+// 推理服务：进程里只加载一次模型（GPU 上只建一份 TensorRT 引擎），同机的多个 selfplay 进程用 -nn_server 指过来共用。
+// 接口见 internal/game/remote_eval.go。
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"hexxagon_go/internal/cli"
+	"hexxagon_go/internal/game"
+)
+
+func main() {
+	listen := flag.String("listen", "127.0.0.1:7791", "HTTP 监听地址（只给本机进程用时保持 127.0.0.1）")
+	statsEvery := flag.Duration("stats_every", time.Minute, "推理统计日志间隔（0=不输出）")
+	backendReport := flag.Bool("backend-report", false, "初始化 NN 后端并打印能力报告（EP、FP16、批量会话、建会话与热身耗时）")
+	var oc game.ORTConfig
+	flag.IntVar(&oc.IntraOpThreads, "ort_threads", 0, "ORT 算子内并行线程数（0=ORT 默认）")
+	flag.IntVar(&oc.InterOpThreads, "ort_inter_threads", 0, "ORT 算子间并行线程数（0=ORT 默认）")
+	flag.BoolVar(&oc.NoCPUMemArena, "ort_no_arena", false, "关闭 ORT CPU 内存池（省常驻内存）")
+	flag.StringVar(&oc.GraphOpt, "ort_opt", "", "ORT 图优化级别 none/basic/extended/all（空=默认 all）")
+	cli.Parse("nnserver")
+	log.SetPrefix("[nnserver] ")
+
+	if err := oc.Validate(); err != nil {
+		log.Fatalf("-ort_*: %v", err)
+	}
+	game.SetORTConfig(oc)
+	if *backendReport {
+		game.WriteBackendReport(os.Stderr)
+	}
+
+	// 模型在开始接请求前建好（TensorRT 首次建引擎可能要几分钟），第一个客户端不用等
+	t0 := time.Now()
+	empty := game.NewGameState(game.DefaultBoardRadius).Board
+	if _, _, err := game.PolicyValueNN(empty, game.PlayerA); err != nil {
+		log.Fatalf("policy/value model: %v", err)
+	}
+	log.Printf("model ready in %s", time.Since(t0).Round(time.Millisecond))
+
+	srv := &http.Server{Addr: *listen, Handler: game.NNHandler(game.LocalNNBackend())}
+	go func() {
+		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+	log.Printf("listening on %s", *listen)
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	var tick <-chan time.Time
+	if *statsEvery > 0 {
+		t := time.NewTicker(*statsEvery)
+		defer t.Stop()
+		tick = t.C
+	}
+	for {
+		select {
+		case <-tick:
+			logStats()
+		case <-sig:
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			_ = srv.Shutdown(ctx)
+			cancel()
+			logStats()
+			game.ShutdownONNX()
+			return
+		}
+	}
+}
+
+func logStats() {
+	stats := game.GetNNStats()
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s := stats[name]
+		log.Printf("%s [%s]: %d calls, %d positions, occupancy %.0f%%, avg %s",
+			name, s.Provider, s.Calls, s.Positions, 100*s.Occupancy(), s.AvgLatency().Round(time.Microsecond))
+	}
+}
@@ -0,0 +1,234 @@
+// cmd/package/main.go
+// 组装发布目录并打成 zip：游戏可执行文件、ONNX Runtime 动态库、许可文件、带默认参数的启动脚本，
+// 可选附带预先生成的 trt_cache（TensorRT 引擎缓存，首次启动不必再编译几分钟）。
+//
+//	go run ./cmd/package -ort-dir D:\onnxruntime\lib -trt-cache .\trt_cache
+//	go run ./cmd/package -goos linux -goarch amd64 -ort-dir ./ort/lib
+//
+// 产物：<out>/hexxagon-<version>-<goos>-<goarch>/ 与同名 .zip
+package main
+
+import (
+	"archive/zip"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"hexxagon_go/internal/cli"
+)
+
+// ortLibs 各平台需要随包分发的 ORT 动态库（glob，相对 -ort-dir）；第一个是主库
+var ortLibs = map[string][]string{
+	"windows": {"onnxruntime.dll", "onnxruntime_providers_shared.dll", "onnxruntime_providers_cuda.dll", "onnxruntime_providers_tensorrt.dll", "DirectML.dll"},
+	"linux":   {"libonnxruntime.so*", "libonnxruntime_providers_*.so"},
+	"darwin":  {"libonnxruntime*.dylib"},
+}
+
+func main() {
+	var (
+		goos     = flag.String("goos", runtime.GOOS, "目标系统")
+		goarch   = flag.String("goarch", runtime.GOARCH, "目标架构")
+		version  = flag.String("version", "", "版本号（空=git describe，失败时为 dev）")
+		outDir   = flag.String("out", "dist", "输出目录")
+		ortDir   = flag.String("ort-dir", "", "ONNX Runtime 动态库所在目录（空=不附带；Windows 版 exe 内嵌了 CPU/DirectML 所需 DLL）")
+		trtCache = flag.String("trt-cache", "", "预先生成的 trt_cache 目录（空=不附带）")
+		args     = flag.String("args", "-depth 1 -tip", "启动脚本里的默认参数")
+		noZip    = flag.Bool("no-zip", false, "只组装目录，不打 zip")
+	)
+	cli.Parse("package")
+
+	if *version == "" {
+		*version = gitVersion()
+	}
+	name := fmt.Sprintf("hexxagon-%s-%s-%s", *version, *goos, *goarch)
+	stage := filepath.Join(*outDir, name)
+	if err := os.RemoveAll(stage); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.MkdirAll(stage, 0755); err != nil {
+		log.Fatal(err)
+	}
+
+	exe := "hexxagon"
+	if *goos == "windows" {
+		exe += ".exe"
+	}
+	if err := build(filepath.Join(stage, exe), *goos, *goarch); err != nil {
+		log.Fatalf("编译失败: %v", err)
+	}
+
+	if *ortDir != "" {
+		n, err := copyGlobs(*ortDir, stage, ortLibs[*goos])
+		if err != nil {
+			log.Fatal(err)
+		}
+		if n == 0 {
+			log.Fatalf("%s 下没有找到 %s 的 ONNX Runtime 库 %v", *ortDir, *goos, ortLibs[*goos])
+		}
+		if _, err := copyGlobs(*ortDir, filepath.Join(stage, "licenses", "onnxruntime"), []string{"LICENSE*", "ThirdPartyNotices*", "../LICENSE*", "../ThirdPartyNotices*"}); err != nil {
+			log.Fatal(err)
+		}
+	} else if *goos != "windows" {
+		log.Printf("警告：未指定 -ort-dir，%s 版需要系统自带 ONNX Runtime 才能用神经网络", *goos)
+	}
+
+	if _, err := copyGlobs(".", stage, []string{"README.md", "readme_english.md", "LICENSE*", "COPYING*", "NOTICE*"}); err != nil {
+		log.Fatal(err)
+	}
+	if *trtCache != "" {
+		if err := copyDir(*trtCache, filepath.Join(stage, "trt_cache")); err != nil {
+			log.Fatalf("复制 trt_cache 失败: %v", err)
+		}
+	}
+	if err := writeLauncher(stage, exe, *goos, *args); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("已组装: %s\n", stage)
+
+	if *noZip {
+		return
+	}
+	zipPath := stage + ".zip"
+	if err := zipDir(stage, name, zipPath); err != nil {
+		log.Fatalf("打包失败: %v", err)
+	}
+	fmt.Printf("已写入: %s\n", zipPath)
+}
+
+func gitVersion() string {
+	out, err := exec.Command("git", "describe", "--tags", "--always", "--dirty").Output()
+	if v := strings.TrimSpace(string(out)); err == nil && v != "" {
+		return v
+	}
+	return "dev"
+}
+
+func build(dst, goos, goarch string) error {
+	cmd := exec.Command("go", "build", "-trimpath",
+		"-ldflags", "-s -w",
+		"-o", dst, "./cmd/hexxagon")
+	cmd.Env = append(os.Environ(), "GOOS="+goos, "GOARCH="+goarch)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+// copyGlobs 把 srcDir 下匹配 patterns 的文件复制到 dstDir，返回复制的文件数
+func copyGlobs(srcDir, dstDir string, patterns []string) (int, error) {
+	n := 0
+	for _, p := range patterns {
+		matches, err := filepath.Glob(filepath.Join(srcDir, p))
+		if err != nil {
+			return n, err
+		}
+		for _, m := range matches {
+			if fi, err := os.Stat(m); err != nil || fi.IsDir() {
+				continue
+			}
+			if err := copyFile(m, filepath.Join(dstDir, filepath.Base(m))); err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+	return n, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	fi, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		return copyFile(path, filepath.Join(dst, rel))
+	})
+}
+
+// writeLauncher 写带默认参数的启动脚本；额外参数原样透传
+func writeLauncher(stage, exe, goos, args string) error {
+	if goos == "windows" {
+		script := "@echo off\r\ncd /d \"%~dp0\"\r\n" + exe + " " + args + " %*\r\n"
+		return os.WriteFile(filepath.Join(stage, "hexxagon.bat"), []byte(script), 0644)
+	}
+	script := "#!/bin/sh\ncd \"$(dirname \"$0\")\"\nexport LD_LIBRARY_PATH=\".:$LD_LIBRARY_PATH\"\nexec ./" + exe + " " + args + " \"$@\"\n"
+	return os.WriteFile(filepath.Join(stage, "hexxagon.sh"), []byte(script), 0755)
+}
+
+// zipDir 把 dir 打进 zipPath，zip 内顶层目录名为 root；保留可执行位
+func zipDir(dir, root, zipPath string) error {
+	f, err := os.Create(zipPath)
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(f)
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		hdr, err := zip.FileInfoHeader(fi)
+		if err != nil {
+			return err
+		}
+		hdr.Name = root + "/" + filepath.ToSlash(rel)
+		hdr.Method = zip.Deflate
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		_, err = io.Copy(w, in)
+		return err
+	})
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
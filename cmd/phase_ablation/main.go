@@ -4,17 +4,20 @@ import (
 	"flag"
 	"fmt"
 	"math/rand"
+	"os"
 	"time"
 
+	"hexxagon_go/internal/cli"
 	game "hexxagon_go/internal/game"
 )
 
 var (
-	radius     = flag.Int("radius", 4, "棋盘半径")
-	depthEval  = flag.Int("depth", 2, "搜索深度")
+	common = cli.Common{Radius: 4, Depth: 2, Seed: time.Now().UnixNano()}
+
 	samples    = flag.Int("n", 100, "每阶段采样局面数量")
 	randomOpen = flag.Int("random_open", 2, "开局随机回合数")
-	seed       = flag.Int64("seed", time.Now().UnixNano(), "随机种子")
+
+	backendReport = flag.Bool("backend-report", false, "初始化 NN 后端并打印能力报告（EP、FP16、批量会话、建会话与热身耗时）")
 )
 
 // --- 工具函数 ---
@@ -37,7 +40,7 @@ func pieceDiff(b *game.Board) int {
 
 // 从某阶段采样起始局面
 func sampleStateForPhase(rng *rand.Rand, phase string) *game.GameState {
-	st := game.NewGameState(*radius)
+	st := game.NewGameState(common.Radius)
 	// 随机开局若干手，打破对称
 	for i := 0; i < *randomOpen; i++ {
 		for _, pl := range []game.CellState{game.PlayerA, game.PlayerB} {
@@ -140,15 +143,19 @@ func duel(st0 *game.GameState, depth int64, phase string) int {
 }
 
 func main() {
-	flag.Parse()
-	rng := rand.New(rand.NewSource(*seed))
+	common.Register(flag.CommandLine, cli.Radius|cli.Depth|cli.Seed)
+	cli.Parse("phase_ablation")
+	if *backendReport {
+		game.WriteBackendReport(os.Stderr)
+	}
+	rng := rand.New(rand.NewSource(common.Seed))
 
 	phases := []string{"opening", "midgame", "endgame"}
 	for _, ph := range phases {
 		w, l, d := 0, 0, 0
 		for i := 0; i < *samples; i++ {
 			st := sampleStateForPhase(rng, ph)
-			res := duel(st, int64(*depthEval), ph)
+			res := duel(st, int64(common.Depth), ph)
 			switch res {
 			case +1:
 				w++
@@ -0,0 +1,55 @@
+// cmd/rating/main.go
+// 战绩账本（arena、battle_eval_nn 的 -ledger，格式见 internal/rating）→ 等级分表：
+// 每个参赛者（引擎写法 + 配置哈希）一行，Elo 为全部记录一次拟合，Glicko 按时间逐局更新并给出 ±2RD。
+// 同一写法出现多行即配置变了（多半是换了模型），对比这几行就是各版本的进步。
+//
+//	rating -ledger ratings.jsonl
+//	rating -ledger ratings.jsonl -tool arena -min-games 50
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"hexxagon_go/internal/cli"
+	"hexxagon_go/internal/rating"
+)
+
+func main() {
+	ledgerPath := flag.String("ledger", "ratings.jsonl", "战绩账本")
+	tool := flag.String("tool", "", "只算这个工具记下的对局（arena、battle_eval_nn）；空=全部")
+	minGames := flag.Int("min-games", 1, "局数不足的参赛者不列出（仍参与拟合）")
+	cli.Parse("rating")
+	// 标准输出留给表格（game 包的 init 把日志改到了标准输出）
+	log.SetOutput(os.Stderr)
+
+	results, err := rating.LoadLedger(*ledgerPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *tool != "" {
+		kept := results[:0]
+		for _, r := range results {
+			if r.Tool == *tool {
+				kept = append(kept, r)
+			}
+		}
+		results = kept
+	}
+	if len(results) == 0 {
+		log.Fatalf("%s: 没有对局记录", *ledgerPath)
+	}
+
+	table := rating.Rate(results)
+	fmt.Printf("%d 局，%d 个参赛者（Elo 平均 %d；Glicko 初值 %d±%d）\n\n", len(results), len(table), rating.BaseRating, rating.BaseRating, 2*rating.InitialRD)
+	fmt.Printf("%3s  %-25s %-6s %4s %4s %6s %-13s  %s\n", "#", "参赛者", "哈希", "局数", "得分率", "Elo", "  Glicko ±2RD", "最后一局")
+	for i, r := range table {
+		if r.Games() < *minGames {
+			continue
+		}
+		fmt.Printf("%3d  %-28s %-8s %6d %6.1f%% %6.0f %6.0f ±%-5.0f  %s\n", i+1, r.Player.Name, r.Player.Hash, r.Games(),
+			100*r.Score(), r.Elo, r.Glicko, 2*r.RD, r.Last.Local().Format("2006-01-02 15:04"))
+	}
+}
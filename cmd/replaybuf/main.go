@@ -0,0 +1,90 @@
+// cmd/replaybuf/main.go
+// 在线训练的回放缓冲服务：selfplay -push 把每局样本推进来，训练器从 /batch 随机取批，
+// 窗口、去重与磁盘布局见 internal/replaybuf。HTTP 与 gRPC（-grpc）两个端口提供同样的接口。
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"hexxagon_go/internal/cli"
+	"hexxagon_go/internal/replaybuf"
+)
+
+func main() {
+	listen := flag.String("listen", ":7790", "HTTP 监听地址")
+	grpcListen := flag.String("grpc", ":7791", "gRPC 监听地址（空=不开）")
+	dir := flag.String("dir", "replay_buffer", "段文件目录；重启后已有的段照旧算进窗口")
+	capacity := flag.Int("capacity", 1_000_000, "窗口内保留的样本数（按段淘汰，实际最多再多一个段）")
+	segment := flag.Int("segment", 20_000, "每个段文件的样本数")
+	statsEvery := flag.Duration("stats_every", time.Minute, "状态日志间隔（0=不输出）")
+	cli.Parse("replaybuf")
+	log.SetPrefix("[replaybuf] ")
+
+	buf, err := replaybuf.Open(replaybuf.Config{Dir: *dir, Capacity: *capacity, SegmentSize: *segment})
+	if err != nil {
+		log.Fatal(err)
+	}
+	st := buf.Stats()
+	log.Printf("%s: %d samples in %d segments, capacity %d", *dir, st.Samples, st.Segments, *capacity)
+
+	srv := &http.Server{Addr: *listen, Handler: replaybuf.Handler(buf)}
+	go func() {
+		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+	log.Printf("listening on %s", *listen)
+
+	var grpcSrv *grpc.Server
+	if *grpcListen != "" {
+		lis, err := net.Listen("tcp", *grpcListen)
+		if err != nil {
+			log.Fatal(err)
+		}
+		grpcSrv = replaybuf.NewGRPCServer(buf)
+		go func() {
+			if err := grpcSrv.Serve(lis); err != nil {
+				log.Fatal(err)
+			}
+		}()
+		log.Printf("gRPC listening on %s", *grpcListen)
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	var tick <-chan time.Time
+	if *statsEvery > 0 {
+		t := time.NewTicker(*statsEvery)
+		defer t.Stop()
+		tick = t.C
+	}
+	for {
+		select {
+		case <-tick:
+			st := buf.Stats()
+			log.Printf("window %d samples / %d segments; ingested %d, duplicates %d, evicted %d, served %d",
+				st.Samples, st.Segments, st.Ingested, st.Duplicates, st.Evicted, st.Served)
+		case <-sig:
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			_ = srv.Shutdown(ctx)
+			cancel()
+			if grpcSrv != nil {
+				grpcSrv.GracefulStop()
+			}
+			if err := buf.Close(); err != nil {
+				log.Printf("close: %v", err)
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// cmd/rollout_ab/main.go
+// 固定模拟次数下，对比 MCTS 的两种 rollout 策略：
+// 旧的“优先克隆 + 随机” vs epsilon-greedy top-k（廉价增量评估）
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"time"
+
+	"hexxagon_go/internal/cli"
+	"hexxagon_go/internal/elo"
+	"hexxagon_go/internal/game"
+)
+
+// 一盘棋：greedyIsA 决定 epsilon-greedy 策略执 A 还是执 B
+// 返回 +1 = greedy 胜，-1 = 旧策略胜，0 = 平
+func playOneGame(radius, sims int, greedyIsA bool, greedy, base game.RolloutConfig) int {
+	st := game.NewGameState(radius)
+
+	for ply := 0; !st.GameOver && ply < 400; ply++ {
+		cur := st.CurrentPlayer
+		rcfg := base
+		if (cur == game.PlayerA) == greedyIsA {
+			rcfg = greedy
+		}
+
+		mv, ok := game.FindBestMoveMCTSRollout(st.Board, cur, sims, 0, true, rcfg)
+		if !ok {
+			break
+		}
+		if _, _, err := st.MakeMove(mv); err != nil {
+			break
+		}
+	}
+
+	d := st.Board.CountPieces(game.PlayerA) - st.Board.CountPieces(game.PlayerB)
+	if !greedyIsA {
+		d = -d
+	}
+	switch {
+	case d > 0:
+		return +1
+	case d < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func main() {
+	rand.Seed(time.Now().UnixNano())
+
+	var (
+		games         = flag.Int("games", 50, "对战总局数（轮流先手）")
+		sims          = flag.Int("sims", 400, "每步 MCTS 模拟次数（双方相同）")
+		epsilon       = flag.Float64("eps", 0.25, "epsilon-greedy 的随机比例")
+		topK          = flag.Int("k", 3, "epsilon-greedy 的 top-k")
+		cutoff        = flag.Int("cutoff", 0, "greedy 方 rollout 走满多少步后改用 NN 价值（0=不截断）")
+		blend         = flag.Float64("blend", 0.7, "截断时 NN 价值的权重")
+		backendReport = flag.Bool("backend-report", false, "初始化 NN 后端并打印能力报告（EP、FP16、批量会话、建会话与热身耗时）")
+	)
+	common := cli.Common{Radius: 4}
+	common.Register(flag.CommandLine, cli.Radius)
+	cli.Parse("rollout_ab")
+	if *backendReport {
+		game.WriteBackendReport(os.Stderr)
+	}
+
+	greedy := game.RolloutConfig{Epsilon: *epsilon, TopK: *topK, NNCutoff: *cutoff, NNBlend: *blend}
+	base := game.RolloutConfig{}
+
+	wins, losses, draws := 0, 0, 0
+	start := time.Now()
+	for g := 1; g <= *games; g++ {
+		switch playOneGame(common.Radius, *sims, g%2 == 1, greedy, base) {
+		case +1:
+			wins++
+		case -1:
+			losses++
+		default:
+			draws++
+		}
+		if g%10 == 0 {
+			log.Printf("进度 %d/%d | greedy胜:%d 旧策略胜:%d 平:%d", g, *games, wins, losses, draws)
+		}
+	}
+
+	score := (float64(wins) + 0.5*float64(draws)) / float64(*games)
+	fmt.Printf("\n===== rollout: eps=%.2f k=%d cutoff=%d vs 旧策略 @ %d sims =====\n", *epsilon, *topK, *cutoff, *sims)
+	fmt.Printf("总局数: %d | greedy 胜: %d | 旧策略胜: %d | 平: %d\n", *games, wins, losses, draws)
+	fmt.Printf("得分率: %.3f | Elo 差约 %+.0f | 用时 %v\n", score, elo.FromScore(score), time.Since(start).Round(time.Second))
+}
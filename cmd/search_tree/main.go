@@ -0,0 +1,75 @@
+// cmd/search_tree/main.go
+// 对给定局面重新搜索一遍，把根着法（可选两层）的分数 / MCTS 访问数 / 剔除原因导出为 JSON 与 graphviz。
+// JSON 可用同目录的 viewer.html 在浏览器里打开查看。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"hexxagon_go/internal/cli"
+	"hexxagon_go/internal/game"
+)
+
+func main() {
+	var (
+		pos           = flag.String("pos", "", "局面串（GameState.PositionString 输出；空=标准开局）")
+		depth         = flag.Int("depth", 2, "α-β 搜索深度")
+		plies         = flag.Int("plies", 1, "导出层数：1=根着法，2=再展开对手应着")
+		sims          = flag.Int("sims", 0, "额外跑多少次 MCTS 模拟以填充访问数（0=不跑）")
+		allowJump     = flag.Bool("allow_jump", true, "是否允许跳跃")
+		nn            = flag.Bool("nn", false, "双方都用 ONNX 评估（默认都用静态评估）")
+		jsonOut       = flag.String("json", "search_tree.json", "JSON 输出路径（空=不写）")
+		dotOut        = flag.String("dot", "", "graphviz 输出路径（空=不写）")
+		backendReport = flag.Bool("backend-report", false, "初始化 NN 后端并打印能力报告（EP、FP16、批量会话、建会话与热身耗时）")
+	)
+	cli.Parse("search_tree")
+	if *backendReport {
+		game.WriteBackendReport(os.Stderr)
+	}
+
+	game.UseONNXForPlayerA = *nn
+	game.UseONNXForPlayerB = *nn
+
+	st := game.NewGameState(4)
+	if *pos != "" {
+		var err error
+		if st, err = game.ParsePosition(*pos); err != nil {
+			log.Fatalf("解析局面失败: %v", err)
+		}
+	}
+
+	tree := game.ExportSearchTree(st.Board, st.CurrentPlayer, game.SearchTreeOptions{
+		Depth:     int64(*depth),
+		AllowJump: *allowJump,
+		Plies:     *plies,
+		Sims:      *sims,
+	})
+
+	write := func(path string, fn func(f *os.File) error) {
+		if path == "" {
+			return
+		}
+		f, err := os.Create(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		if err := fn(f); err != nil {
+			log.Fatalf("写 %s 失败: %v", path, err)
+		}
+		fmt.Printf("已写入: %s\n", path)
+	}
+	write(*jsonOut, func(f *os.File) error { return tree.WriteJSON(f) })
+	write(*dotOut, func(f *os.File) error { return tree.WriteDOT(f) })
+
+	for _, n := range tree.Moves {
+		mark := " "
+		if n.Best {
+			mark = "*"
+		}
+		fmt.Printf("%s (%d,%d)->(%d,%d) score=%d visits=%d %s\n", mark, n.From.Q, n.From.R, n.To.Q, n.To.R, n.Score, n.Visits, n.Pruned)
+	}
+}
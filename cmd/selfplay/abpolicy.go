@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"hexxagon_go/internal/game"
+)
+
+// 策略目标的来源：默认是 MCTS 根访问分布；-policy ab 改用 α-β 根层各着法得分的 softmax，
+// 不用 NN 和 MCTS 模拟，CPU 上就能快速攒数据（质量取决于 -ab_depth 与静态评估）。
+
+const (
+	policyMCTS = "mcts"
+	policyAB   = "ab"
+)
+
+func parsePolicySource(s string) (string, error) {
+	switch s {
+	case policyMCTS, policyAB:
+		return s, nil
+	}
+	return "", fmt.Errorf("unknown -policy %q (want %s or %s)", s, policyMCTS, policyAB)
+}
+
+// scoreProbs softmax(score/temp)，按 scores 的顺序；temp 以评估分为单位（一子约 10 分）
+func scoreProbs(scores []game.RootScore, temp float64) []float64 {
+	best := scores[0].Score
+	for _, s := range scores {
+		best = max(best, s.Score)
+	}
+	p := make([]float64, len(scores))
+	sum := 0.0
+	for i, s := range scores {
+		p[i] = math.Exp(float64(s.Score-best) / temp)
+		sum += p[i]
+	}
+	for i := range p {
+		p[i] /= sum
+	}
+	return p
+}
+
+// scorePolicy α-β 得分的 softmax 按落点格累加，得到与 visitPolicy 同形状的软策略目标
+func scorePolicy(scores []game.RootScore, temp float64) []float32 {
+	out := make([]float32, game.GridSize*game.GridSize)
+	for i, p := range scoreProbs(scores, temp) {
+		out[game.AxialToIndex(scores[i].Move.To)] += float32(p)
+	}
+	return out
+}
+
+// pickScored 按 策略概率^(1/temp) 抽样选着，与 pickMove 对访问数的处理一致；temp 很小时取得分最高的着法
+func pickScored(scores []game.RootScore, scoreTemp, temp float64, r *rand.Rand) game.Move {
+	if temp < 1e-3 {
+		best := 0
+		for i, s := range scores {
+			if s.Score > scores[best].Score {
+				best = i
+			}
+		}
+		return scores[best].Move
+	}
+	w := scoreProbs(scores, scoreTemp*temp) // p^(1/temp) ∝ exp(score/(scoreTemp·temp))
+	x := r.Float64()
+	for i, wi := range w {
+		if x < wi {
+			return scores[i].Move
+		}
+		x -= wi
+	}
+	return scores[len(scores)-1].Move
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	"hexxagon_go/internal/elo"
+	"hexxagon_go/internal/game"
+)
+
+// evalConfig 生成过程中的强度抽查：当前模型（无噪声、取访问最多）对固定深度的静态 α-β
+type evalConfig struct {
+	Every int // 每生成多少局抽查一次；0 = 关闭
+	Games int // 每次抽查的局数（双方轮流先手）
+	Depth int // 静态引擎搜索深度
+	Sims  int // 模型一方每步模拟次数
+
+	Model game.PolicyValueModel // 当前模型；nil 用本进程的全局模型（-nn_server 时为远程）
+}
+
+// evalMatch 在后台跑抽查，结果追加到 <out>/eval_elo.tsv。
+// 上一轮还没下完时新的触发直接丢弃，抽查不会拖慢数据生成。
+type evalMatch struct {
+	cfg     evalConfig
+	path    string
+	trigger chan int
+	done    chan struct{}
+	r       *rand.Rand
+	prevElo float64
+	rounds  int
+}
+
+const evalMaxPlies = 400
+
+func startEvalMatch(outDir string, cfg evalConfig, seed int64) (*evalMatch, error) {
+	if cfg.Every <= 0 || cfg.Games <= 0 {
+		return nil, nil
+	}
+	path := filepath.Join(outDir, "eval_elo.tsv")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		header := "time\tgames_generated\twins\tdraws\tlosses\tscore\telo\telo_err\n"
+		if err := os.WriteFile(path, []byte(header), 0644); err != nil {
+			return nil, err
+		}
+	}
+	// 抽查只让静态引擎一方用 α-β；关掉全局的 ONNX 评估开关，保证它是纯静态评估
+	game.UseONNXForPlayerA = false
+	game.UseONNXForPlayerB = false
+
+	e := &evalMatch{
+		cfg:     cfg,
+		path:    path,
+		trigger: make(chan int, 1),
+		done:    make(chan struct{}),
+		r:       rand.New(rand.NewSource(seed)),
+	}
+	go e.loop()
+	return e, nil
+}
+
+// notify 由 writer 在收到第 games 局时调用
+func (e *evalMatch) notify(games int) {
+	if e == nil || games%e.cfg.Every != 0 {
+		return
+	}
+	select {
+	case e.trigger <- games:
+	default:
+		log.Printf("[eval] 上一轮抽查未结束，跳过第 %d 局处的抽查", games)
+	}
+}
+
+// close 等待进行中的抽查结束
+func (e *evalMatch) close() {
+	if e == nil {
+		return
+	}
+	close(e.trigger)
+	<-e.done
+}
+
+func (e *evalMatch) loop() {
+	defer close(e.done)
+	for games := range e.trigger {
+		if err := e.round(games); err != nil {
+			log.Printf("[eval] %v", err)
+		}
+	}
+}
+
+func (e *evalMatch) round(generated int) error {
+	start := time.Now()
+	var w, d, l int
+	for i := 0; i < e.cfg.Games; i++ {
+		modelSide := game.PlayerA
+		if i%2 == 1 {
+			modelSide = game.PlayerB
+		}
+		optionsGate.RLock()
+		winner := e.playGame(modelSide)
+		optionsGate.RUnlock()
+		switch winner {
+		case modelSide:
+			w++
+		case game.Empty:
+			d++
+		default:
+			l++
+		}
+	}
+
+	n := float64(w + d + l)
+	score := (float64(w) + 0.5*float64(d)) / n
+	diff, lo, hi := elo.Estimate(w, d, l)
+	errElo := (hi - lo) / 2 // 日志与 TSV 里记区间半宽
+	trend := ""
+	if e.rounds > 0 {
+		trend = fmt.Sprintf("，较上次 %+.0f", diff-e.prevElo)
+	}
+	e.prevElo = diff
+	e.rounds++
+	log.Printf("[eval] 第 %d 局处: 对静态 d%d %d胜 %d和 %d负，Elo %+.0f ± %.0f%s（%s）",
+		generated, e.cfg.Depth, w, d, l, diff, errElo, trend, time.Since(start).Round(time.Second))
+
+	f, err := os.OpenFile(e.path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "%s\t%d\t%d\t%d\t%d\t%.4f\t%.1f\t%.1f\n",
+		time.Now().Format(time.RFC3339), generated, w, d, l, score, diff, errElo)
+	return err
+}
+
+// playGame 下一局，返回胜方（Empty = 和棋）
+func (e *evalMatch) playGame(modelSide game.CellState) game.CellState {
+	st := game.NewGameState(4)
+	// 各随机走一手，避免每局都是同一盘
+	addRandomOpening(st, 1, e.r, nil)
+
+	for ply := 0; ply < evalMaxPlies && !st.GameOver; ply++ {
+		if st.AdjudicateIfBlocked() {
+			break
+		}
+		side := st.CurrentPlayer
+		var mv game.Move
+		var ok bool
+		if side == modelSide {
+			var rv []game.RootVisit
+			if rv, ok = game.RunMCTSRoot(st.Board, side, e.cfg.Sims, 0, true, game.MCTSOptions{Model: e.cfg.Model}); ok {
+				mv = pickMove(rv, 0, e.r)
+			}
+		} else {
+			mv, ok = game.FindBestMoveAtDepth(st.Board, side, int64(e.cfg.Depth), true)
+		}
+		if !ok {
+			break
+		}
+		if _, _, err := st.MakeMove(mv); err != nil {
+			break
+		}
+	}
+	if st.GameOver {
+		return st.Winner
+	}
+	return winnerValue(st)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"hexxagon_go/internal/game"
+)
+
+// leagueEntry 一个历史快照对手；ID 从 1 起，0 留给“当前模型自对弈”
+type leagueEntry struct {
+	ID     int     `json:"id"`
+	Path   string  `json:"path"`
+	Weight float64 `json:"weight"`
+
+	model *game.PVModel
+}
+
+// league 按权重从快照池里挑对手；Frac 是打 league 局（而非纯自对弈）的比例
+type league struct {
+	Entries []*leagueEntry `json:"entries"`
+	Frac    float64        `json:"frac"`
+	total   float64
+}
+
+// loadLeague 解析 "路径[:权重],..."，路径可以是通配符（匹配到的每个文件用同一权重），权重默认 1
+func loadLeague(spec string, frac float64) (*league, error) {
+	if spec == "" || frac <= 0 {
+		return nil, nil
+	}
+	l := &league{Frac: frac}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		pattern, weight := item, 1.0
+		// 用最后一个冒号切分，兼容 Windows 盘符
+		if i := strings.LastIndex(item, ":"); i > 0 {
+			if w, err := strconv.ParseFloat(item[i+1:], 64); err == nil {
+				pattern, weight = item[:i], w
+			}
+		}
+		if weight < 0 {
+			return nil, fmt.Errorf("league %q: negative weight", item)
+		}
+		paths, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("league %q: %w", item, err)
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("league %q: no such model", item)
+		}
+		for _, p := range paths {
+			m, err := game.LoadPVModel(p)
+			if err != nil {
+				l.close()
+				return nil, fmt.Errorf("league: %w", err)
+			}
+			l.Entries = append(l.Entries, &leagueEntry{ID: len(l.Entries) + 1, Path: p, Weight: weight, model: m})
+			l.total += weight
+		}
+	}
+	if l.total <= 0 {
+		l.close()
+		return nil, fmt.Errorf("league %q: all weights are zero", spec)
+	}
+	return l, nil
+}
+
+// pick 返回本局的对手；nil 表示打自对弈
+func (l *league) pick(r *rand.Rand) *leagueEntry {
+	if l == nil || r.Float64() >= l.Frac {
+		return nil
+	}
+	x := r.Float64() * l.total
+	for _, e := range l.Entries {
+		if x < e.Weight {
+			return e
+		}
+		x -= e.Weight
+	}
+	return l.Entries[len(l.Entries)-1]
+}
+
+func (l *league) close() {
+	if l == nil {
+		return
+	}
+	for _, e := range l.Entries {
+		if e.model != nil {
+			e.model.Close()
+		}
+	}
+}
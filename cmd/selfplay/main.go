@@ -7,12 +7,17 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"hexxagon_go/internal/cli"
 	"hexxagon_go/internal/game"
+	"hexxagon_go/internal/matchlog"
+	"hexxagon_go/internal/samplefmt"
 	"log"
+	"math"
 	"math/rand"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
 )
@@ -21,217 +26,746 @@ type rawSample struct {
 	state  []float32
 	policy []float32
 	side   game.CellState
+	key    uint64 // 局面 zobrist key（含执子方），用于去重
+	phase  int    // game.GamePhase
+	sym    int    // -augment 时由原局面经哪个对称变换得到（0=原样），终局归属要跟着变换
+	aux    samplefmt.AuxRecord
 }
 type finishedSample struct {
 	state  []float32
 	policy []float32
 	value  int8
+	key    uint64
+	phase  int
+	aux    samplefmt.AuxRecord
+	score  float32 // 执子方视角的终局子数差（归一化）
+	own    []int8  // 执子方视角的终局归属，同一局内按执子方共享
 }
 
-// chunkWriter 把样本写成分片：X.bin (float32)、P.bin (float32)、Z.bin (int8)，并写 meta.json 记录计数
+// chunkWriter 把样本写成分片：X.bin (float32)、P.bin (float32)、Z.bin (int8)，并写 meta.json 记录计数；
+// 开启 proto 时每个分片另写（或只写）一个自描述的 .pb 样本流（见 internal/samplefmt）。
 type chunkWriter struct {
 	outDir    string
 	chunkSize int
+	raw       bool // 写 X/P/Z/M 裸数组
+	proto     bool // 写 .pb
+	npz       bool // 写 .npz（分片写满才整体写出，见 npzchunk.go）
+	tfrecord  bool // 写 .tfrecord
+	targets   targetSet
+	runMeta   []byte
+
+	dedup   bool                // 跳过本次运行已写过的局面
+	seen    map[uint64]struct{} // dedup 用
+	skipped int
+	res     *phaseReservoir // 非 nil 时先按阶段蓄水池抽样，close 时统一写出
 
 	idx         int
 	count       int
 	currentBase string
+	open        bool
 	fx          *os.File
 	fp          *os.File
 	fz          *os.File
+	fm          *os.File
+	fs          *os.File // _S.bin：score 目标（-targets score）
+	fo          *os.File // _O.bin：ownership 目标（-targets ownership）
+	fpb         *os.File
+	pbw         *samplefmt.Writer
+	ftf         *os.File
+	tfw         *samplefmt.TFRecordWriter
+	npzBuf      []finishedSample // 当前分片攒着等写 .npz 的样本
+
+	games uint32     // 已收到的对局数
+	eval  *evalMatch // 非 nil 时每 K 局触发一次强度抽查
+	push  *pusher    // 非 nil 时每局样本另推一份给回放缓冲（-push）
+
+	progress *progress // 非 nil 时每局写完记一次进度，供 -resume 续跑（见 resume.go）
+}
+
+// gameResult worker 打完一局交给 writer 的结果；被丢弃的短局 samples 为空，也要交，进度里的随机数状态才连得上
+type gameResult struct {
+	worker  int
+	draws   uint64 // 打完这局时该 worker 已取的随机数次数
+	samples []finishedSample
 }
 
 func newChunkWriter(outDir string, chunkSize int) *chunkWriter {
-	return &chunkWriter{outDir: outDir, chunkSize: chunkSize}
+	return &chunkWriter{outDir: outDir, chunkSize: chunkSize, raw: true}
 }
 
-func (w *chunkWriter) rotate() error {
-	if w.fx != nil {
-		_ = w.fx.Close()
-		_ = w.fp.Close()
-		_ = w.fz.Close()
+// setFormat 解析 -format：raw / proto / npz / tfrecord 逗号分隔，both 即 raw,proto
+func (w *chunkWriter) setFormat(format string) error {
+	w.raw, w.proto, w.npz, w.tfrecord = false, false, false, false
+	for _, f := range strings.Split(format, ",") {
+		switch strings.TrimSpace(f) {
+		case "raw":
+			w.raw = true
+		case "proto":
+			w.proto = true
+		case "both":
+			w.raw, w.proto = true, true
+		case "npz":
+			w.npz = true
+		case "tfrecord":
+			w.tfrecord = true
+		default:
+			return fmt.Errorf("unknown -format %q (want raw, proto, both, npz or tfrecord, comma separated)", f)
+		}
+	}
+	return nil
+}
+
+// closeFiles 关闭当前分片的文件并写 meta
+func (w *chunkWriter) closeFiles() {
+	if !w.open {
+		return
+	}
+	w.open = false
+	if w.pbw != nil {
+		if err := w.pbw.Flush(); err != nil {
+			log.Printf("[writer] flush %s.pb: %v", w.currentBase, err)
+		}
+		w.pbw = nil
+	}
+	if w.tfw != nil {
+		if err := w.tfw.Flush(); err != nil {
+			log.Printf("[writer] flush %s.tfrecord: %v", w.currentBase, err)
+		}
+		w.tfw = nil
+	}
+	if len(w.npzBuf) > 0 {
+		if err := w.writeNPZ(); err != nil {
+			log.Printf("[writer] write %s.npz: %v", w.currentBase, err)
+		}
+		w.npzBuf = w.npzBuf[:0]
+	}
+	for _, f := range []*os.File{w.fx, w.fp, w.fz, w.fm, w.fs, w.fo, w.fpb, w.ftf} {
+		if f != nil {
+			_ = f.Close()
+		}
+	}
+	w.fx, w.fp, w.fz, w.fm, w.fs, w.fo, w.fpb, w.ftf = nil, nil, nil, nil, nil, nil, nil, nil
+	if w.count > 0 {
 		_ = w.writeMeta()
 	}
+}
+
+func (w *chunkWriter) rotate() error {
+	w.closeFiles()
 	w.idx++
 	w.count = 0
 	w.currentBase = fmt.Sprintf("chunk_%05d", w.idx)
-	xPath := filepath.Join(w.outDir, w.currentBase+"_X.bin")
-	pPath := filepath.Join(w.outDir, w.currentBase+"_P.bin")
-	zPath := filepath.Join(w.outDir, w.currentBase+"_Z.bin")
+	w.open = true
 
+	create := func(suffix string) (*os.File, error) {
+		return os.Create(filepath.Join(w.outDir, w.currentBase+suffix))
+	}
 	var err error
-	w.fx, err = os.Create(xPath)
-	if err != nil {
-		return err
+	if w.raw {
+		if w.fx, err = create("_X.bin"); err != nil {
+			return err
+		}
+		if w.fp, err = create("_P.bin"); err != nil {
+			return err
+		}
+		if w.fz, err = create("_Z.bin"); err != nil {
+			return err
+		}
+		if w.fm, err = create("_M.bin"); err != nil {
+			return err
+		}
+		if w.targets.Score {
+			if w.fs, err = create("_S.bin"); err != nil {
+				return err
+			}
+		}
+		if w.targets.Ownership {
+			if w.fo, err = create("_O.bin"); err != nil {
+				return err
+			}
+		}
 	}
-	w.fp, err = os.Create(pPath)
-	if err != nil {
-		return err
+	if w.proto {
+		if w.fpb, err = create(".pb"); err != nil {
+			return err
+		}
+		w.pbw, err = samplefmt.NewWriter(w.fpb, w.protoHeader())
+		if err != nil {
+			return err
+		}
 	}
-	w.fz, err = os.Create(zPath)
-	if err != nil {
-		return err
+	if w.tfrecord {
+		if w.ftf, err = create(".tfrecord"); err != nil {
+			return err
+		}
+		w.tfw = samplefmt.NewTFRecordWriter(w.ftf)
+		w.tfw.Score = w.targets.Score
 	}
 	return nil
 }
 
+// protoHeader .pb 分片与 -push 共用的样本流 Header
+func (w *chunkWriter) protoHeader() samplefmt.Header {
+	enc, _ := game.Encoder(game.FeaturesGrid3)
+	return samplefmt.Header{
+		StateShape:  []uint32{uint32(enc.Planes), game.GridSize, game.GridSize},
+		PolicyLen:   game.GridSize * game.GridSize,
+		Generator:   "hexxagon_go/selfplay",
+		Planes:      enc.PlaneNames,
+		RunMetaJSON: string(w.runMeta),
+	}
+}
+
+// protoSample s 的 samplefmt 形式；附加目标按 -targets 取舍
+func (w *chunkWriter) protoSample(s finishedSample) samplefmt.Sample {
+	ps := samplefmt.Sample{
+		State:    s.state,
+		Policy:   s.policy,
+		Value:    int32(s.value),
+		Ply:      uint32(s.aux.Ply),
+		Empties:  uint32(s.aux.Empties),
+		Phase:    uint32(s.aux.Phase),
+		Entropy:  s.aux.Entropy,
+		Game:     s.aux.Game,
+		Opponent: s.aux.Opponent,
+		Key:      s.key,
+	}
+	if w.targets.Score {
+		ps.Score = s.score
+	}
+	if w.targets.Ownership {
+		ps.Ownership = make([]int32, len(s.own))
+		for i, o := range s.own {
+			ps.Ownership[i] = int32(o)
+		}
+	}
+	return ps
+}
+
 func (w *chunkWriter) writeMeta() error {
 	meta := map[string]any{
 		"samples": w.count,
 	}
+	if w.raw {
+		meta["aux_dtype"] = samplefmt.AuxDtype
+		if w.targets.Score {
+			meta["score_dtype"] = "<f4"
+		}
+		if w.targets.Ownership {
+			meta["ownership_dtype"] = "i1"
+			meta["ownership_shape"] = []int{game.GridSize, game.GridSize}
+		}
+	}
+	if w.proto {
+		meta["proto_schema_version"] = samplefmt.SchemaVersion
+	}
+	if w.tfrecord {
+		// tf.train.Example 里的 X 是展平的，形状从这里取
+		h := w.protoHeader()
+		meta["tfrecord_state_shape"] = h.StateShape
+	}
 	b, _ := json.MarshalIndent(meta, "", "  ")
 	metaPath := filepath.Join(w.outDir, w.currentBase+"_meta.json")
 	return os.WriteFile(metaPath, b, 0644)
 }
 
 func (w *chunkWriter) writeSample(s finishedSample) error {
-	if w.fx == nil || w.count >= w.chunkSize {
+	if !w.open || w.count >= w.chunkSize {
 		if err := w.rotate(); err != nil {
 			return err
 		}
 	}
-	if err := binary.Write(w.fx, binary.LittleEndian, s.state); err != nil {
-		return err
+	if w.raw {
+		if err := binary.Write(w.fx, binary.LittleEndian, s.state); err != nil {
+			return err
+		}
+		if err := binary.Write(w.fp, binary.LittleEndian, s.policy); err != nil {
+			return err
+		}
+		if _, err := w.fz.Write([]byte{byte(s.value)}); err != nil {
+			return err
+		}
+		if err := binary.Write(w.fm, binary.LittleEndian, s.aux); err != nil {
+			return err
+		}
+		if w.fs != nil {
+			if err := binary.Write(w.fs, binary.LittleEndian, s.score); err != nil {
+				return err
+			}
+		}
+		if w.fo != nil {
+			if err := binary.Write(w.fo, binary.LittleEndian, s.own); err != nil {
+				return err
+			}
+		}
 	}
-	if err := binary.Write(w.fp, binary.LittleEndian, s.policy); err != nil {
-		return err
+	if w.npz {
+		w.npzBuf = append(w.npzBuf, s)
 	}
-	if _, err := w.fz.Write([]byte{byte(s.value)}); err != nil {
-		return err
+	if w.proto || w.tfrecord || w.push != nil {
+		ps := w.protoSample(s)
+		if w.proto {
+			if err := w.pbw.Write(&ps); err != nil {
+				return err
+			}
+		}
+		if w.tfrecord {
+			if err := w.tfw.Write(&ps); err != nil {
+				return err
+			}
+		}
+		w.push.add(ps)
 	}
 	w.count++
 	return nil
 }
 
 func (w *chunkWriter) close() {
-	if w.fx != nil {
-		_ = w.fx.Close()
-	}
-	if w.fp != nil {
-		_ = w.fp.Close()
-	}
-	if w.fz != nil {
-		_ = w.fz.Close()
-	}
-	if w.count > 0 {
-		_ = w.writeMeta()
-	}
+	w.closeFiles()
 }
 
-func (w *chunkWriter) run(ch <-chan []finishedSample, done chan<- struct{}) {
+func (w *chunkWriter) run(ch <-chan gameResult, done chan<- struct{}) {
 	defer close(done)
-	for batch := range ch {
-		for _, s := range batch {
+	for res := range ch {
+		if err := w.writeGame(res.samples); err != nil {
+			log.Printf("[writer] write sample failed: %v", err)
+			return
+		}
+		if w.progress != nil {
+			wr := &w.progress.Workers[res.worker]
+			wr.Draws = res.draws
+			wr.Games++
+			w.progress.Done++
+			if err := w.checkpoint(); err != nil {
+				log.Printf("[writer] checkpoint failed: %v", err)
+				return
+			}
+		}
+	}
+	if w.res != nil {
+		log.Printf("[writer] reservoir %s", w.res)
+		for _, s := range w.res.drain() {
 			if err := w.writeSample(s); err != nil {
 				log.Printf("[writer] write sample failed: %v", err)
 				return
 			}
 		}
+		w.push.flush()
+	}
+	if w.push != nil {
+		log.Printf("[writer] push %s", w.push)
+		w.push.close()
+	}
+	if w.dedup {
+		log.Printf("[writer] dedup skipped %d duplicate positions", w.skipped)
 	}
 	w.close()
 }
 
+// writeGame 写出一局的样本；没有样本的局不占对局编号
+func (w *chunkWriter) writeGame(batch []finishedSample) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	w.games++
+	w.eval.notify(int(w.games))
+	for _, s := range batch {
+		s.aux.Game = w.games
+		if w.dedup {
+			if _, dup := w.seen[s.key]; dup {
+				w.skipped++
+				continue
+			}
+			w.seen[s.key] = struct{}{}
+		}
+		if w.res != nil {
+			w.res.add(s)
+			continue
+		}
+		if err := w.writeSample(s); err != nil {
+			return err
+		}
+	}
+	w.push.flush()
+	return nil
+}
+
 // ------------------------------------
 
 func main() {
 	numGames := flag.Int("n", 2000, "要生成的对局数")
 	sims := flag.Int("sims", 800, "每步 MCTS 模拟次数")
-	workers := flag.Int("workers", 0, "并发局数（默认=CPU/2，至少1）")
-	outDir := flag.String("out", "selfplay_out", "输出目录")
+	common := cli.Common{Out: "selfplay_out", Seed: time.Now().UnixNano()}
+	common.Register(flag.CommandLine, cli.Workers|cli.Out|cli.Seed)
 	chunkSize := flag.Int("chunk", 5000, "每个分片的样本数")
-	seed := flag.Int64("seed", time.Now().UnixNano(), "随机种子")
-	flag.Parse()
+	dedup := flag.Bool("dedup", false, "跳过本次运行中已写过的局面（按 zobrist key）")
+	reservoir := flag.Int("reservoir", 0, "按阶段蓄水池抽样的总样本数（0=不抽样，全部写出）")
+	phaseMix := flag.String("phase_mix", "1,1,1", "蓄水池中 开局,中局,残局 的配比")
+	var pc playConfig
+	flag.Float64Var(&pc.Temp, "temp", 1.0, "前 temp_plies 手按 访问数^(1/temp) 抽样选着")
+	flag.IntVar(&pc.TempPlies, "temp_plies", 20, "使用 temp 的手数，之后改用 temp_final")
+	flag.Float64Var(&pc.TempFinal, "temp_final", 0, "temp_plies 之后的温度（0=取访问最多的着法）")
+	flag.BoolVar(&pc.Augment, "augment", false, "每个样本另写出棋盘 11 个旋转/镜像像（共 12 份），同样的对局数得到 12 倍数据；对称局面的重复像可配 -dedup 去掉")
+	flag.Float64Var(&pc.MCTS.DirichletAlpha, "dirichlet_alpha", 0.3, "根节点 Dirichlet 噪声 α（0=关闭）")
+	flag.Float64Var(&pc.MCTS.NoiseFrac, "noise_frac", 0.25, "根先验中噪声的权重 ε")
+	flag.Float64Var(&pc.MCTS.ForcedK, "forced_k", 0, "强制 playout 系数 k（KataGo 取 2；0=关闭）")
+	flag.BoolVar(&pc.MCTS.PruneTarget, "prune_target", true, "启用强制 playout 时，从策略目标中剪掉强制访问")
+	policySrc := flag.String("policy", policyMCTS, "策略目标来源：mcts（根访问分布）或 ab（α-β 根层得分的 softmax，不需要 NN/GPU）")
+	flag.IntVar(&pc.ABDepth, "ab_depth", 2, "-policy ab 时每个根着法的 α-β 深度")
+	flag.Float64Var(&pc.ABTemp, "ab_temp", 20, "-policy ab 时 softmax 温度，单位是评估分（一子约 10 分）")
+	leagueSpec := flag.String("league", "", "历史快照对手池：路径[:权重],...（路径可用通配符）")
+	leagueFrac := flag.Float64("league_frac", 0.5, "与快照对弈的局数比例，其余为自对弈")
+	var ec evalConfig
+	flag.IntVar(&ec.Every, "eval_every", 0, "每生成 K 局，用当前模型对静态引擎抽查一次强度（0=关闭）")
+	flag.IntVar(&ec.Games, "eval_games", 10, "每次抽查的局数")
+	flag.IntVar(&ec.Depth, "eval_depth", 2, "抽查时静态 α-β 的搜索深度")
+	flag.IntVar(&ec.Sims, "eval_sims", 0, "抽查时模型每步模拟次数（0=同 -sims）")
+	format := flag.String("format", "raw", "分片格式，可逗号分隔多选：raw（X/P/Z/M 裸数组）、proto（自描述 .pb，见 internal/samplefmt）、both（=raw,proto）、npz（np.load 直接读的压缩 .npz）、tfrecord（tf.train.Example，特征名 X/P/Z）")
+	targets := flag.String("targets", "", "除胜负外额外写出的价值目标：score（归一化子数差）、ownership（每格终局归属），逗号分隔")
+	statsEvery := flag.Duration("stats_every", 30*time.Second, "吞吐/推理/内存统计日志间隔（0=只在结束时输出）")
+	memLimit := flag.String("mem_limit", "", "Go 运行时内存软上限，如 6GiB（空=沿用 GOMEMLIMIT 环境变量，0=不限）")
+	freeEvery := flag.Duration("free_every", 0, "每隔多久调用 debug.FreeOSMemory 归还空闲内存（0=关闭）")
+	matchLog := flag.String("matchlog", "", "逐手对局日志（JSON Lines，格式见 internal/matchlog）；空=不写")
+	optionsPath := flag.String("options", "", "搜索/评估参数文件（JSON，见 game.Options）；改动、SIGHUP 或控制台输入 reload 时在两局之间热加载")
+	pushURL := flag.String("push", "", "回放缓冲地址（cmd/replaybuf，如 http://host:7790 或 grpc://host:7791）：每局样本另推一份过去，供在线训练；空=不推")
+	resume := flag.Bool("resume", false, "按 -out 下的 progress.json 续跑中断的任务：-n 为总局数，分片和对局编号接着写；-workers/-chunk/-format/-targets 须与原来一致，种子取原来的")
+	nnServer := flag.String("nn_server", "", "推理服务地址（cmd/nnserver，如 http://127.0.0.1:7791）：MCTS 的先验与估值都走它（-policy ab 不支持），同机多个进程共用一份 GPU 引擎；空=本进程自己加载模型")
+	backendReport := flag.Bool("backend-report", false, "初始化 NN 后端并打印能力报告（EP、FP16、批量会话、建会话与热身耗时）")
+	var oc game.ORTConfig
+	flag.IntVar(&oc.IntraOpThreads, "ort_threads", 0, "ORT 算子内并行线程数（0=ORT 默认；多 worker 走 CPU 推理时建议 1）")
+	flag.IntVar(&oc.InterOpThreads, "ort_inter_threads", 0, "ORT 算子间并行线程数（0=ORT 默认）")
+	flag.BoolVar(&oc.NoCPUMemArena, "ort_no_arena", false, "关闭 ORT CPU 内存池（省常驻内存）")
+	flag.StringVar(&oc.GraphOpt, "ort_opt", "", "ORT 图优化级别 none/basic/extended/all（空=默认 all）")
+	cli.Parse("selfplay")
+	pc.Sims = *sims
+	var err error
+	if pc.Policy, err = parsePolicySource(*policySrc); err != nil {
+		log.Fatal(err)
+	}
+	if err := pc.validate(); err != nil {
+		log.Fatal(err)
+	}
+	if pc.Policy == policyAB && (pc.ABDepth < 1 || pc.ABTemp <= 0) {
+		log.Fatalf("-policy ab needs -ab_depth >= 1 and -ab_temp > 0")
+	}
+	if pc.Policy == policyAB && *leagueSpec != "" {
+		log.Fatal("-league needs MCTS opponents; it cannot be combined with -policy ab")
+	}
+	if pc.Policy == policyAB && *nnServer != "" {
+		log.Fatal("-nn_server only serves MCTS priors and values; -policy ab evaluates leaves statically")
+	}
+	if pc.Policy == policyAB {
+		// 双方都用静态评估：α-β 叶子不走 NN，整个生成过程只吃 CPU
+		game.UseONNXForPlayerA, game.UseONNXForPlayerB = false, false
+	}
 
-	if *workers <= 0 {
-		*workers = runtime.NumCPU() / 2
-		if *workers < 1 {
-			*workers = 1
+	autoWorkers := common.Workers <= 0
+	if common.Workers <= 0 {
+		common.Workers = runtime.NumCPU() / 2
+		if common.Workers < 1 {
+			common.Workers = 1
 		}
 	}
-	if err := os.MkdirAll(*outDir, 0755); err != nil {
-		log.Fatalf("mkdir %s: %v", *outDir, err)
+	if err := os.MkdirAll(common.Out, 0755); err != nil {
+		log.Fatalf("mkdir %s: %v", common.Out, err)
+	}
+
+	var prog *progress
+	if *resume {
+		if *reservoir > 0 {
+			log.Fatal("-resume cannot be combined with -reservoir (samples are only written at the end)")
+		}
+		if prog, err = loadProgress(common.Out); err != nil {
+			log.Fatalf("-resume: %v", err)
+		}
+		if autoWorkers {
+			common.Workers = len(prog.Workers)
+		}
+		if err := prog.check(common.Workers, *chunkSize, *format, *targets); err != nil {
+			log.Fatalf("-resume: %v", err)
+		}
+		common.Seed = prog.Seed
+		log.Printf("resume: %d/%d games done, chunk %d (%d samples), seed %d", prog.Done, *numGames, prog.Chunk, prog.ChunkSamples, prog.Seed)
+		if *dedup {
+			log.Printf("resume: -dedup only sees positions written after resuming")
+		}
+	} else if *reservoir == 0 {
+		prog = newProgress(common.Seed, common.Workers, *chunkSize, *format, *targets)
 	}
 
 	// 初始化坐标/编码表
 	_ = game.AllCoords(4)
-	rand.Seed(*seed)
+	rand.Seed(common.Seed)
+
+	log.Printf("selfplay: games=%d sims=%d workers=%d out=%s chunk=%d", *numGames, *sims, common.Workers, common.Out, *chunkSize)
+	log.Printf("selfplay: %s", pc)
+	if err := mem.setup(*memLimit); err != nil {
+		log.Fatalf("-mem_limit: %v", err)
+	}
+	if err := oc.Validate(); err != nil {
+		log.Fatalf("-ort_*: %v", err)
+	}
+	game.SetORTConfig(oc)
+	log.Printf("selfplay: ort %s", oc)
+	if *backendReport {
+		game.WriteBackendReport(os.Stderr)
+	}
+	if *nnServer != "" {
+		re := game.NewRemoteEvaluator(*nnServer)
+		if _, err := re.Info(); err != nil {
+			log.Fatalf("-nn_server: %v", err)
+		}
+		pc.MCTS.Model = re
+		ec.Model = re
+		log.Printf("selfplay: nn server %s", *nnServer)
+	}
+	ow, err := startOptionsReload(*optionsPath)
+	if err != nil {
+		log.Fatalf("-options: %v", err)
+	}
+	if ow != nil {
+		defer ow.Stop()
+	}
+	lg, err := loadLeague(*leagueSpec, *leagueFrac)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer lg.close()
+	if lg != nil {
+		for _, e := range lg.Entries {
+			log.Printf("league #%d %s weight=%g", e.ID, e.Path, e.Weight)
+		}
+	}
+	var runMeta []byte
+	if *resume {
+		// .pb 分片头里带的是开跑时的 run_meta，续跑不改写
+		runMeta, err = os.ReadFile(filepath.Join(common.Out, "run_meta.json"))
+	} else {
+		runMeta, err = writeRunMeta(common.Out, common.Seed, pc, lg)
+	}
+	if err != nil {
+		log.Fatalf("run meta: %v", err)
+	}
 
-	log.Printf("selfplay: games=%d sims=%d workers=%d out=%s chunk=%d", *numGames, *sims, *workers, *outDir, *chunkSize)
+	openLog := matchlog.Create
+	if *resume {
+		openLog = matchlog.Append
+	}
+	ml, err := openLog(*matchLog, "selfplay")
+	if err != nil {
+		log.Fatalf("-matchlog: %v", err)
+	}
+	defer ml.Close()
 
-	jobs := make(chan int, *workers*2)
-	samplesCh := make(chan []finishedSample, *workers)
+	jobs := make(chan int, common.Workers*2)
+	samplesCh := make(chan gameResult, common.Workers)
 
+	writer := newChunkWriter(common.Out, *chunkSize)
+	if err := writer.setFormat(*format); err != nil {
+		log.Fatal(err)
+	}
+	if writer.npz {
+		// .npz 分片写满才落盘，中断时攒着的样本没有可续的文件
+		if *resume {
+			log.Fatal("-resume cannot be used with -format npz (npz chunks are only written when full)")
+		}
+		prog = nil
+	}
+	if writer.targets, err = parseTargets(*targets); err != nil {
+		log.Fatal(err)
+	}
+	writer.runMeta = runMeta
+	if *pushURL != "" {
+		if writer.push, err = newPusher(*pushURL, writer.protoHeader()); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *dedup {
+		writer.dedup = true
+		writer.seen = make(map[uint64]struct{})
+	}
+	if *reservoir > 0 {
+		res, err := newPhaseReservoir(*reservoir, *phaseMix, rand.New(rand.NewSource(common.Seed-1)))
+		if err != nil {
+			log.Fatal(err)
+		}
+		writer.res = res
+	}
+	if ec.Sims <= 0 {
+		ec.Sims = *sims
+	}
+	ev, err := startEvalMatch(common.Out, ec, common.Seed-2)
+	if err != nil {
+		log.Fatalf("eval: %v", err)
+	}
+	writer.eval = ev
+	if *resume {
+		if err := writer.resume(prog); err != nil {
+			log.Fatalf("-resume: %v", err)
+		}
+	} else {
+		writer.progress = prog
+	}
 	writerDone := make(chan struct{})
-	go newChunkWriter(*outDir, *chunkSize).run(samplesCh, writerDone)
+	go writer.run(samplesCh, writerDone)
+	statsStop := make(chan struct{})
+	tp.start = time.Now()
+	go tp.report(*statsEvery, common.Workers, *sims, statsStop)
+	go mem.freeLoop(*freeEvery, statsStop)
 
 	var wg sync.WaitGroup
-	for i := 0; i < *workers; i++ {
+	for i := 0; i < common.Workers; i++ {
 		wg.Add(1)
 		go func(wid int) {
 			defer wg.Done()
-			r := rand.New(rand.NewSource(*seed + int64(wid)))
-			for range jobs {
-				samps, ok := playOneGame(*sims, r)
-				if ok && len(samps) > 0 {
-					samplesCh <- samps
+			src := newCountingSource(common.Seed+int64(wid), 0)
+			if prog != nil {
+				src = newCountingSource(prog.Workers[wid].Seed, prog.Workers[wid].Draws)
+			}
+			r := rand.New(src)
+			for g := range jobs {
+				var recs *[]matchlog.Record
+				if ml != nil {
+					recs = new([]matchlog.Record)
+				}
+				optionsGate.RLock()
+				samps, ok := playOneGame(pc, lg.pick(r), r, recs)
+				optionsGate.RUnlock()
+				if recs != nil {
+					if err := ml.WriteGame(g, *recs); err != nil {
+						log.Fatalf("write matchlog: %v", err)
+					}
+				}
+				tp.games.Add(1)
+				if !ok {
+					samps = nil
 				}
+				tp.samples.Add(int64(len(samps)))
+				samplesCh <- gameResult{worker: wid, draws: src.draws, samples: samps}
 			}
 		}(i)
 	}
 
-	for g := 0; g < *numGames; g++ {
+	first := 0
+	if prog != nil {
+		first = prog.Done
+	}
+	for g := first; g < *numGames; g++ {
 		jobs <- g
 	}
 	close(jobs)
 	wg.Wait()
 	close(samplesCh)
 	<-writerDone
+	ev.close()
+	close(statsStop)
+	if err := tp.writeSummary(common.Out, common.Workers, *sims); err != nil {
+		log.Printf("write throughput summary: %v", err)
+	}
 	log.Println("selfplay done")
 }
 
-// playOneGame 打完一局，返回带价值标签的样本
-func playOneGame(sims int, r *rand.Rand) ([]finishedSample, bool) {
+// playOneGame 打完一局，返回带价值标签的样本。
+// opp 非 nil 时当前模型随机执一方、快照执另一方，只记录当前模型一方的样本。
+// recs 非 nil 时逐手追加 matchlog 记录（随机开局的几手记为 random，含结束行；太短被丢弃的局也记）。
+func playOneGame(pc playConfig, opp *leagueEntry, r *rand.Rand, recs *[]matchlog.Record) ([]finishedSample, bool) {
 	const maxMoves, minMoves = 400, 20
 	state := game.NewGameState(4)
 	player := game.PlayerA
 
-	addRandomOpening(state, 2, r)
+	var onOpening func(*game.Board, game.CellState, game.Move)
+	if recs != nil {
+		onOpening = func(b *game.Board, side game.CellState, mv game.Move) {
+			rec := matchlog.MoveRecord(b, side, mv, len(*recs), 0)
+			rec.Player = "random"
+			*recs = append(*recs, rec)
+		}
+	}
+	addRandomOpening(state, 2, r, onOpening)
 
 	raws := make([]rawSample, 0, 128)
+	oppSide, oppID := game.Empty, uint32(0)
+	if opp != nil {
+		oppSide, oppID = game.PlayerA, uint32(opp.ID)
+		if r.Intn(2) == 0 {
+			oppSide = game.PlayerB
+		}
+	}
 
+	plies := 0
 	for move := 0; move < maxMoves; move++ {
-		mv, visits, ok := game.FindBestMoveMCTSWithVisits(state.Board, player, sims, 0, true)
-		if !ok {
-			break
+		opt := pc.MCTS
+		opt.Rand = r
+		if player == oppSide {
+			opt.Model = opp.model
+		}
+		t0 := time.Now()
+		var mv game.Move
+		var policy []float32
+		if pc.Policy == policyAB {
+			scores := game.ScoreRootMoves(state.Board, player, pc.ABDepth, true)
+			if len(scores) == 0 {
+				break
+			}
+			policy = scorePolicy(scores, pc.ABTemp)
+			mv = pickScored(scores, pc.ABTemp, pc.temperature(move), r)
+		} else {
+			rv, ok := game.RunMCTSRoot(state.Board, player, pc.Sims, 0, true, opt)
+			if !ok {
+				break
+			}
+			policy = visitPolicy(rv)
+			mv = pickMove(rv, pc.temperature(move), r)
+		}
+		if recs != nil {
+			rec := matchlog.MoveRecord(state.Board, player, mv, len(*recs), time.Since(t0))
+			rec.Player, rec.Sims = "model", pc.Sims
+			if pc.Policy == policyAB {
+				rec.Player, rec.Sims, rec.Depth = "alphabeta", 0, pc.ABDepth
+			}
+			if player == oppSide {
+				rec.Player = fmt.Sprintf("league#%d", opp.ID)
+			}
+			*recs = append(*recs, rec)
 		}
 
-		// 记录样本
-		t := game.EncodeBoardTensor(state.Board, player)
-		stateCopy := make([]float32, len(t))
-		copy(stateCopy, t[:])
-		policy := normalizeVisits(visits)
-
-		raws = append(raws, rawSample{
-			state:  stateCopy,
-			policy: policy,
-			side:   player,
-		})
+		// 记录样本（快照一方的着法不进训练数据）；-augment 时 12 个对称像各记一份
+		if player != oppSide {
+			raws = append(raws, newRawSample(state.Board, player, policy, move, oppID))
+			if pc.Augment {
+				for sym := 1; sym < game.NumSymmetries; sym++ {
+					rs := newRawSample(game.TransformBoard(state.Board, sym), player, game.TransformPolicy(policy, sym), move, oppID)
+					rs.sym = sym
+					raws = append(raws, rs)
+				}
+			}
+		}
 
 		_, _, err := state.MakeMove(mv)
 		if err != nil {
 			break
 		}
+		plies++
+		tp.plies.Add(1)
 		if state.GameOver {
 			break
 		}
 		player = game.Opponent(player)
 	}
 
-	if len(raws) < minMoves {
+	winner := winnerValue(state)
+	if recs != nil {
+		reason := ""
+		if !state.GameOver && plies >= maxMoves {
+			reason = "max plies"
+		}
+		*recs = append(*recs, matchlog.EndRecord(len(*recs), winner, reason))
+	}
+	if plies < minMoves || len(raws) == 0 {
 		return nil, false
 	}
 
-	winner := winnerValue(state)
+	final := computeFinalTargets(state.Board)
 	finished := make([]finishedSample, len(raws))
 	for i, s := range raws {
 		val := int8(0)
@@ -245,15 +779,57 @@ func playOneGame(sims int, r *rand.Rand) ([]finishedSample, bool) {
 		default:
 			val = 0
 		}
+		aux := s.aux
+		aux.AbsValue = float32(math.Abs(float64(val)))
+		score, own := final.forSide(s.side)
+		if s.sym != 0 {
+			own = game.TransformGrid(own, s.sym)
+		}
 		finished[i] = finishedSample{
 			state:  s.state,
 			policy: s.policy,
 			value:  val,
+			key:    s.key,
+			phase:  s.phase,
+			aux:    aux,
+			score:  score,
+			own:    own,
 		}
 	}
 	return finished, true
 }
 
+// newRawSample policy 是按落点格索引的策略目标（visitPolicy 或 scorePolicy 的结果）
+func newRawSample(b *game.Board, player game.CellState, policy []float32, ply int, opp uint32) rawSample {
+	t := game.EncodeBoardTensor(b, player)
+	stateCopy := make([]float32, len(t))
+	copy(stateCopy, t[:])
+
+	return rawSample{
+		state:  stateCopy,
+		policy: policy,
+		side:   player,
+		key:    game.PositionKey(b, player),
+		phase:  game.GamePhase(b),
+		aux: samplefmt.AuxRecord{
+			Ply:      uint16(ply),
+			Empties:  uint8(b.CountPieces(game.Empty)),
+			Phase:    uint8(game.GamePhase(b)),
+			Entropy:  policyEntropy(policy),
+			Opponent: opp,
+		},
+	}
+}
+
+// visitPolicy MCTS 根节点访问数（剪枝后的 Target）按落点格累加后归一化
+func visitPolicy(rv []game.RootVisit) []float32 {
+	visits := make([]int, game.GridSize*game.GridSize)
+	for _, v := range rv {
+		visits[game.AxialToIndex(v.Move.To)] += v.Target
+	}
+	return normalizeVisits(visits)
+}
+
 // normalizeVisits 把访问次数归一化为概率；若全 0 则均匀分布
 func normalizeVisits(visits []int) []float32 {
 	out := make([]float32, len(visits))
@@ -275,6 +851,17 @@ func normalizeVisits(visits []int) []float32 {
 	return out
 }
 
+// policyEntropy 访问分布的熵（nat）
+func policyEntropy(p []float32) float32 {
+	h := 0.0
+	for _, v := range p {
+		if v > 0 {
+			h -= float64(v) * math.Log(float64(v))
+		}
+	}
+	return float32(h)
+}
+
 // winnerValue：返回 1/-1/0
 func winnerValue(st *game.GameState) game.CellState {
 	a := st.Board.CountPieces(game.PlayerA)
@@ -288,8 +875,8 @@ func winnerValue(st *game.GameState) game.CellState {
 	return game.Empty
 }
 
-// 随机开局：双方各走 n 手
-func addRandomOpening(st *game.GameState, n int, r *rand.Rand) {
+// 随机开局：双方各走 n 手；onMove 非 nil 时在每手落子前回调（写对局日志用）
+func addRandomOpening(st *game.GameState, n int, r *rand.Rand, onMove func(b *game.Board, side game.CellState, mv game.Move)) {
 	for i := 0; i < n; i++ {
 		for _, pl := range []game.CellState{game.PlayerA, game.PlayerB} {
 			moves := game.GenerateMoves(st.Board, pl)
@@ -297,6 +884,9 @@ func addRandomOpening(st *game.GameState, n int, r *rand.Rand) {
 				continue
 			}
 			mv := moves[r.Intn(len(moves))]
+			if onMove != nil {
+				onMove(st.Board, pl, mv)
+			}
 			_, _, _ = st.MakeMove(mv)
 		}
 	}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// 长时间自博弈的内存控制：-mem_limit 设置 Go 运行时的软上限（同 GOMEMLIMIT，逼近时 GC 更积极），
+// -free_every 定期把空闲堆页还给系统。统计日志里带上进程 RSS，超过上限 90% 时额外告警，
+// 免得在共享机器上被 OOM killer 无声杀掉。
+// 各 worker 是同一进程内的 goroutine，共用一个堆：RSS 只能按进程统计，日志里按 worker 平摊给个量级。
+
+type memMonitor struct {
+	limit   int64 // 生效的软上限；math.MaxInt64 = 不限
+	peakRSS atomic.Int64
+}
+
+var mem = &memMonitor{limit: math.MaxInt64}
+
+// memSummary 写进 throughput.json
+type memSummary struct {
+	RSS       int64  `json:"rss_bytes"`
+	PeakRSS   int64  `json:"peak_rss_bytes"`
+	HeapInuse uint64 `json:"heap_inuse_bytes"`
+	Limit     int64  `json:"limit_bytes,omitempty"` // 0 = 不限
+	NumGC     uint32 `json:"num_gc"`
+}
+
+// parseBytes 解析 "6GiB"、"512M"、"1e9" 之类的字节数
+func parseBytes(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	units := []struct {
+		suffix string
+		mul    float64
+	}{
+		{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40},
+		{"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12},
+		{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"T", 1 << 40},
+		{"B", 1},
+	}
+	mul := 1.0
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			s, mul = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.mul
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("bad byte size %q", s)
+	}
+	return int64(v * mul), nil
+}
+
+// setup 按 -mem_limit 设置软上限：空串保留 GOMEMLIMIT 环境变量的设置，"0" 取消上限
+func (m *memMonitor) setup(spec string) error {
+	if spec = strings.TrimSpace(spec); spec != "" {
+		limit, err := parseBytes(spec)
+		if err != nil {
+			return err
+		}
+		if limit == 0 {
+			limit = math.MaxInt64
+		}
+		debug.SetMemoryLimit(limit)
+	}
+	m.limit = debug.SetMemoryLimit(-1)
+	if m.limit != math.MaxInt64 {
+		log.Printf("selfplay: 内存软上限 %s", fmtBytes(m.limit))
+	}
+	return nil
+}
+
+// freeLoop 每隔 every 调一次 debug.FreeOSMemory，直到 stop 关闭
+func (m *memMonitor) freeLoop(every time.Duration, stop <-chan struct{}) {
+	if every <= 0 {
+		return
+	}
+	tick := time.NewTicker(every)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			debug.FreeOSMemory()
+			m.sample()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// readRSS 读 /proc/self/statm；不是 Linux 时退回运行时向系统申请的总量
+func readRSS() int64 {
+	if b, err := os.ReadFile("/proc/self/statm"); err == nil {
+		if f := strings.Fields(string(b)); len(f) > 1 {
+			if pages, err := strconv.ParseInt(f[1], 10, 64); err == nil {
+				return pages * int64(os.Getpagesize())
+			}
+		}
+	}
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return int64(ms.Sys)
+}
+
+// sample 取一次当前内存占用并更新峰值
+func (m *memMonitor) sample() memSummary {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	s := memSummary{RSS: readRSS(), HeapInuse: ms.HeapInuse, NumGC: ms.NumGC}
+	for {
+		peak := m.peakRSS.Load()
+		if s.RSS <= peak || m.peakRSS.CompareAndSwap(peak, s.RSS) {
+			break
+		}
+	}
+	s.PeakRSS = m.peakRSS.Load()
+	if m.limit != math.MaxInt64 {
+		s.Limit = m.limit
+	}
+	return s
+}
+
+// nearLimit RSS 超过软上限的 90%
+func (s memSummary) nearLimit() bool {
+	return s.Limit > 0 && s.RSS > s.Limit/10*9
+}
+
+func (s memSummary) String(workers int) string {
+	str := fmt.Sprintf("rss %s (峰值 %s, 每 worker ~%s) 堆 %s gc %d",
+		fmtBytes(s.RSS), fmtBytes(s.PeakRSS), fmtBytes(s.RSS/int64(max(workers, 1))), fmtBytes(int64(s.HeapInuse)), s.NumGC)
+	if s.Limit > 0 {
+		str += " / 上限 " + fmtBytes(s.Limit)
+	}
+	return str
+}
+
+func fmtBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.2fGiB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMiB", float64(n)/(1<<20))
+	default:
+		return fmt.Sprintf("%dKiB", n>>10)
+	}
+}
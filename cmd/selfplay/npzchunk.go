@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"hexxagon_go/internal/game"
+	"hexxagon_go/internal/npz"
+	"hexxagon_go/internal/samplefmt"
+)
+
+// writeNPZ 把当前分片攒下的样本写成一个压缩 .npz：X (N,planes,9,9) f4、P (N,81) f4、Z (N,) i1、
+// M (N,) 结构化数组（字段同 aux_dtype），开启附加目标时另有 S (N,) f4、O (N,9,9) i1。
+// zip 里的数组要先知道长度，所以不像裸数组那样边生成边写
+func (w *chunkWriter) writeNPZ() error {
+	n := len(w.npzBuf)
+	const cells = game.GridSize * game.GridSize
+	stateLen := len(w.npzBuf[0].state)
+	x := make([]float32, 0, n*stateLen)
+	p := make([]float32, 0, n*cells)
+	z := make([]int8, n)
+	m := make([]samplefmt.AuxRecord, n)
+	var sc []float32
+	var own []int8
+	for i, s := range w.npzBuf {
+		x = append(x, s.state...)
+		p = append(p, s.policy...)
+		z[i] = s.value
+		m[i] = s.aux
+		if w.targets.Score {
+			sc = append(sc, s.score)
+		}
+		if w.targets.Ownership {
+			own = append(own, s.own...)
+		}
+	}
+
+	f, err := os.Create(filepath.Join(w.outDir, w.currentBase+".npz"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	zw := npz.NewWriter(f, true)
+	if err := zw.Add("X", []int{n, stateLen / cells, game.GridSize, game.GridSize}, x); err != nil {
+		return err
+	}
+	if err := zw.Add("P", []int{n, cells}, p); err != nil {
+		return err
+	}
+	if err := zw.Add("Z", []int{n}, z); err != nil {
+		return err
+	}
+	if err := zw.AddStruct("M", samplefmt.AuxDtype, []int{n}, m); err != nil {
+		return err
+	}
+	if w.targets.Score {
+		if err := zw.Add("S", []int{n}, sc); err != nil {
+			return err
+		}
+	}
+	if w.targets.Ownership {
+		if err := zw.Add("O", []int{n, game.GridSize, game.GridSize}, own); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bufio"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"hexxagon_go/internal/game"
+)
+
+// -options 热加载搜索/评估参数（rollout、阶段切换、延伸等，见 game.Options）：
+// 文件改动、kill -HUP 或在控制台输入 reload 时重读，不用重启进程、重做 TensorRT 热身。
+// 参数是无锁读取的包级变量，所以只在两局之间换：每局持 optionsGate 读锁，换参数时拿写锁，
+// 写锁排队期间新局不再开始，已开始的局下完后统一生效。
+
+var optionsGate sync.RWMutex
+
+// startOptionsReload 先加载一次参数文件，再开始监视；path 为空时什么都不做
+func startOptionsReload(path string) (*game.OptionsWatcher, error) {
+	if path == "" {
+		return nil, nil
+	}
+	o, err := game.LoadOptions(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := game.ApplyOptions(o); err != nil {
+		return nil, err
+	}
+	log.Printf("selfplay: options %s %+v", path, o)
+	w := game.WatchOptions(path, 5*time.Second, func(o game.Options) {
+		optionsGate.Lock()
+		err := game.ApplyOptions(o)
+		optionsGate.Unlock()
+		if err != nil {
+			log.Printf("[options] %v", err)
+			return
+		}
+		log.Printf("[options] 已重新加载 %s %+v", path, o)
+	}, func(err error) {
+		log.Printf("[options] 重读失败，沿用当前参数: %v", err)
+	})
+	go consoleReload(w)
+	return w, nil
+}
+
+// consoleReload 标准输入每读到一行 reload 就重读一次；标准输入关闭时安静退出
+func consoleReload(w *game.OptionsWatcher) {
+	sc := bufio.NewScanner(os.Stdin)
+	for sc.Scan() {
+		if strings.TrimSpace(sc.Text()) == "reload" {
+			w.Reload()
+		}
+	}
+}
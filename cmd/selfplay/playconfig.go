@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	"hexxagon_go/internal/game"
+)
+
+// playConfig 影响数据分布的自博弈参数；整份写进 run_meta.json，方便事后追查某批数据怎么来的
+type playConfig struct {
+	Sims      int              `json:"sims"`
+	Temp      float64          `json:"temp"`
+	TempPlies int              `json:"temp_plies"`
+	TempFinal float64          `json:"temp_final"`
+	MCTS      game.MCTSOptions `json:"-"`
+	Augment   bool             `json:"augment"` // 每个样本写出全部 12 个对称像
+
+	Policy  string  `json:"policy"`   // 策略目标来源：mcts / ab，见 abpolicy.go
+	ABDepth int     `json:"ab_depth"` // -policy ab 时根层每个着法的搜索深度
+	ABTemp  float64 `json:"ab_temp"`  // -policy ab 时 softmax 的温度（评估分）
+}
+
+// validate 检查探索参数的取值范围
+func (pc playConfig) validate() error {
+	switch {
+	case pc.Temp < 0 || pc.TempFinal < 0 || pc.TempPlies < 0:
+		return fmt.Errorf("-temp, -temp_final and -temp_plies must be >= 0")
+	case pc.MCTS.DirichletAlpha < 0:
+		return fmt.Errorf("-dirichlet_alpha must be >= 0")
+	case pc.MCTS.NoiseFrac < 0 || pc.MCTS.NoiseFrac > 1:
+		return fmt.Errorf("-noise_frac must be in [0,1]")
+	}
+	return nil
+}
+
+func (pc playConfig) temperature(ply int) float64 {
+	if ply < pc.TempPlies {
+		return pc.Temp
+	}
+	return pc.TempFinal
+}
+
+func (pc playConfig) String() string {
+	if pc.Policy == policyAB {
+		return fmt.Sprintf("policy=ab depth=%d ab_temp=%.1f temp=%.2f(前%d手)→%.2f augment=%v",
+			pc.ABDepth, pc.ABTemp, pc.Temp, pc.TempPlies, pc.TempFinal, pc.Augment)
+	}
+	return fmt.Sprintf("temp=%.2f(前%d手)→%.2f dirichlet=%.2f/%.2f forced_k=%.1f prune=%v augment=%v",
+		pc.Temp, pc.TempPlies, pc.TempFinal, pc.MCTS.DirichletAlpha, pc.MCTS.NoiseFrac, pc.MCTS.ForcedK, pc.MCTS.PruneTarget, pc.Augment)
+}
+
+// writeRunMeta 写 run_meta.json，并返回其内容（proto 分片的 Header 里也带一份）
+func writeRunMeta(outDir string, seed int64, pc playConfig, lg *league) ([]byte, error) {
+	meta := map[string]any{
+		"started":         time.Now().Format(time.RFC3339),
+		"seed":            seed,
+		"sims":            pc.Sims,
+		"temp":            pc.Temp,
+		"temp_plies":      pc.TempPlies,
+		"temp_final":      pc.TempFinal,
+		"dirichlet_alpha": pc.MCTS.DirichletAlpha,
+		"noise_frac":      pc.MCTS.NoiseFrac,
+		"forced_k":        pc.MCTS.ForcedK,
+		"prune_target":    pc.MCTS.PruneTarget,
+		"policy":          pc.Policy,
+		"augment":         pc.Augment,
+	}
+	if pc.Policy == policyAB {
+		meta["ab_depth"] = pc.ABDepth
+		meta["ab_temp"] = pc.ABTemp
+	}
+	if lg != nil {
+		meta["league"] = lg
+	}
+	b, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return b, os.WriteFile(filepath.Join(outDir, "run_meta.json"), b, 0644)
+}
+
+// pickMove 按 访问数^(1/temp) 抽样；temp 很小时直接取访问最多的着法。
+// 抽样权重用剪枝后的 Target，全为 0 时（模拟太少）退回原始访问数。
+func pickMove(rv []game.RootVisit, temp float64, r *rand.Rand) game.Move {
+	if temp < 1e-3 {
+		best := 0
+		for i, v := range rv {
+			if v.Visits > rv[best].Visits {
+				best = i
+			}
+		}
+		return rv[best].Move
+	}
+	count := func(v game.RootVisit) int { return v.Target }
+	total := 0
+	for _, v := range rv {
+		total += v.Target
+	}
+	if total == 0 {
+		count = func(v game.RootVisit) int { return v.Visits }
+	}
+	// 先除以最大访问数再求幂：温度很低时 n^(1/temp) 会溢出成 +Inf，抽样就只剩最后一手
+	maxN := 0
+	for _, v := range rv {
+		maxN = max(maxN, count(v))
+	}
+	w := make([]float64, len(rv))
+	sum := 0.0
+	for i, v := range rv {
+		if n := count(v); n > 0 {
+			w[i] = math.Pow(float64(n)/float64(maxN), 1/temp)
+			sum += w[i]
+		}
+	}
+	if sum == 0 {
+		return rv[r.Intn(len(rv))].Move
+	}
+	x := r.Float64() * sum
+	for i, wi := range w {
+		if x < wi {
+			return rv[i].Move
+		}
+		x -= wi
+	}
+	return rv[len(rv)-1].Move
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"hexxagon_go/internal/replaybuf"
+	"hexxagon_go/internal/samplefmt"
+)
+
+// 在线训练：-push 指向 cmd/replaybuf 时，写分片之外每局样本再推一份给回放缓冲。
+// http:// 走 POST /samples，grpc://host:port 走 gRPC 的 Push。
+// 推送失败只记日志并丢掉这一局：分片文件里照样有，不拖慢自博弈。
+
+const pushTimeout = 30 * time.Second
+
+type pusher struct {
+	url     string
+	client  *http.Client     // http:// 时用
+	conn    *grpc.ClientConn // grpc:// 时用
+	header  samplefmt.Header
+	pending []samplefmt.Sample
+
+	pushed, dropped int // 推成功 / 因失败丢掉的样本数
+}
+
+func newPusher(url string, h samplefmt.Header) (*pusher, error) {
+	p := &pusher{url: url, header: h}
+	if target, ok := strings.CutPrefix(url, "grpc://"); ok {
+		conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, fmt.Errorf("-push %s: %w", url, err)
+		}
+		p.conn = conn
+		return p, nil
+	}
+	p.client = &http.Client{Timeout: pushTimeout}
+	return p, nil
+}
+
+// add 攒一条，flush 时一起推；p 为 nil 时什么都不做
+func (p *pusher) add(s samplefmt.Sample) {
+	if p != nil {
+		p.pending = append(p.pending, s)
+	}
+}
+
+// flush 把攒下的样本推出去
+func (p *pusher) flush() {
+	if p == nil || len(p.pending) == 0 {
+		return
+	}
+	if err := p.push(); err != nil {
+		log.Printf("[writer] push %d samples: %v", len(p.pending), err)
+		p.dropped += len(p.pending)
+	} else {
+		p.pushed += len(p.pending)
+	}
+	p.pending = p.pending[:0]
+}
+
+func (p *pusher) push() error {
+	if p.conn == nil {
+		return replaybuf.Push(p.client, p.url, p.header, p.pending)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+	defer cancel()
+	return replaybuf.PushGRPC(ctx, p.conn, p.header, p.pending)
+}
+
+// close 断开 gRPC 连接；p 为 nil 时什么都不做
+func (p *pusher) close() {
+	if p != nil && p.conn != nil {
+		p.conn.Close()
+	}
+}
+
+func (p *pusher) String() string {
+	return fmt.Sprintf("%s: pushed %d, dropped %d", p.url, p.pushed, p.dropped)
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"hexxagon_go/internal/samplefmt"
+)
+
+// 断点续跑：writer 每收到一局就把进度写进 progress.json（先写临时文件再改名）——
+// 已打完的局数、每个 worker 的随机数状态、当前分片各文件已写到的字节数。
+// -resume 时按进度把当前分片截回最后一个完整对局的末尾接着写，编号更大的残留分片删掉，
+// worker 的随机数快进到中断前的位置，对局编号、分片编号都接着原来的往下数。
+// 中断时正在打的局会重打；NN、全局随机数、热加载参数等不在进度里，重打的局不保证与原来一模一样。
+
+const progressFile = "progress.json"
+
+// countingSource 记下取过多少次随机数：math/rand 的状态取不出来，只能记“种子 + 已取次数”，
+// 续跑时重新播种再空转同样次数。rngSource 的 Int63 和 Uint64 都只推进一步，两者一样计数
+type countingSource struct {
+	src   rand.Source64
+	draws uint64
+}
+
+func newCountingSource(seed int64, draws uint64) *countingSource {
+	s := &countingSource{src: rand.NewSource(seed).(rand.Source64)}
+	for ; s.draws < draws; s.draws++ {
+		s.src.Uint64()
+	}
+	return s
+}
+
+func (s *countingSource) Int63() int64   { s.draws++; return s.src.Int63() }
+func (s *countingSource) Uint64() uint64 { s.draws++; return s.src.Uint64() }
+func (s *countingSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.draws = 0
+}
+
+// workerRNG 一个 worker 的随机数状态
+type workerRNG struct {
+	Seed  int64  `json:"seed"`
+	Draws uint64 `json:"draws"`
+	Games int    `json:"games"` // 该 worker 打完的局数
+}
+
+// progress progress.json 的内容
+type progress struct {
+	Seed      int64       `json:"seed"`
+	ChunkSize int         `json:"chunk_size"`
+	Format    string      `json:"format"`
+	Targets   string      `json:"targets"`
+	Workers   []workerRNG `json:"workers"`
+
+	Done  int    `json:"games_done"`    // 已打完的局数（含太短被丢弃的），下一局从这个编号开始
+	Games uint32 `json:"games_written"` // 写出过样本的局数，样本的 aux.Game 接着它编号
+
+	Chunk        int              `json:"chunk"`         // 当前分片编号，0=还没开过分片
+	ChunkSamples int              `json:"chunk_samples"` // 当前分片已写的样本数
+	ChunkFiles   map[string]int64 `json:"chunk_files"`   // 当前分片各文件（后缀）已写的字节数
+}
+
+func newProgress(seed int64, workers, chunkSize int, format, targets string) *progress {
+	p := &progress{Seed: seed, ChunkSize: chunkSize, Format: format, Targets: targets, Workers: make([]workerRNG, workers)}
+	for i := range p.Workers {
+		p.Workers[i].Seed = seed + int64(i)
+	}
+	return p
+}
+
+func loadProgress(outDir string) (*progress, error) {
+	b, err := os.ReadFile(filepath.Join(outDir, progressFile))
+	if err != nil {
+		return nil, err
+	}
+	var p progress
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("%s: %w", progressFile, err)
+	}
+	return &p, nil
+}
+
+// check 续跑的参数必须与中断的那次一致：worker 数决定随机数流怎么分，分片参数决定文件怎么接
+func (p *progress) check(workers, chunkSize int, format, targets string) error {
+	switch {
+	case workers != len(p.Workers):
+		return fmt.Errorf("-workers %d, interrupted run used %d", workers, len(p.Workers))
+	case chunkSize != p.ChunkSize:
+		return fmt.Errorf("-chunk %d, interrupted run used %d", chunkSize, p.ChunkSize)
+	case format != p.Format:
+		return fmt.Errorf("-format %q, interrupted run used %q", format, p.Format)
+	case targets != p.Targets:
+		return fmt.Errorf("-targets %q, interrupted run used %q", targets, p.Targets)
+	}
+	return nil
+}
+
+func (p *progress) save(outDir string) error {
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(outDir, progressFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// chunkFiles 当前分片打开着的文件，按后缀
+func (w *chunkWriter) chunkFiles() map[string]*os.File {
+	files := map[string]*os.File{}
+	for suffix, f := range map[string]*os.File{
+		"_X.bin": w.fx, "_P.bin": w.fp, "_Z.bin": w.fz, "_M.bin": w.fm,
+		"_S.bin": w.fs, "_O.bin": w.fo, ".pb": w.fpb, ".tfrecord": w.ftf,
+	} {
+		if f != nil {
+			files[suffix] = f
+		}
+	}
+	return files
+}
+
+// checkpoint 一局的样本写完后记进度；.pb/.tfrecord 的缓冲先刷到文件，记下的字节数才对得上
+func (w *chunkWriter) checkpoint() error {
+	p := w.progress
+	p.Games, p.Chunk, p.ChunkSamples = w.games, w.idx, w.count
+	p.ChunkFiles = map[string]int64{}
+	if w.open {
+		if w.pbw != nil {
+			if err := w.pbw.Flush(); err != nil {
+				return err
+			}
+		}
+		if w.tfw != nil {
+			if err := w.tfw.Flush(); err != nil {
+				return err
+			}
+		}
+		for suffix, f := range w.chunkFiles() {
+			st, err := f.Stat()
+			if err != nil {
+				return err
+			}
+			p.ChunkFiles[suffix] = st.Size()
+		}
+	}
+	return p.save(w.outDir)
+}
+
+// resume 按进度接上中断的分片：删掉编号更大的残留分片，当前分片各文件截到进度记下的长度后接着写
+func (w *chunkWriter) resume(p *progress) error {
+	w.progress = p
+	w.games = p.Games
+	w.idx = p.Chunk
+	if err := removeChunksAfter(w.outDir, p.Chunk); err != nil {
+		return err
+	}
+	if p.Chunk == 0 || len(p.ChunkFiles) == 0 {
+		return nil
+	}
+	w.currentBase = fmt.Sprintf("chunk_%05d", w.idx)
+	w.count = p.ChunkSamples
+	w.open = true
+	for suffix, size := range p.ChunkFiles {
+		f, err := os.OpenFile(filepath.Join(w.outDir, w.currentBase+suffix), os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		if err := f.Truncate(size); err != nil {
+			f.Close()
+			return err
+		}
+		if _, err := f.Seek(size, 0); err != nil {
+			f.Close()
+			return err
+		}
+		switch suffix {
+		case "_X.bin":
+			w.fx = f
+		case "_P.bin":
+			w.fp = f
+		case "_Z.bin":
+			w.fz = f
+		case "_M.bin":
+			w.fm = f
+		case "_S.bin":
+			w.fs = f
+		case "_O.bin":
+			w.fo = f
+		case ".pb":
+			w.fpb = f
+			w.pbw = samplefmt.AppendWriter(f, size, p.ChunkSamples)
+		case ".tfrecord":
+			w.ftf = f
+			w.tfw = samplefmt.NewTFRecordWriter(f)
+			w.tfw.Score = w.targets.Score
+		default:
+			f.Close()
+			return fmt.Errorf("%s: unknown chunk file %q", progressFile, suffix)
+		}
+	}
+	return nil
+}
+
+// removeChunksAfter 删掉编号大于 idx 的分片文件（中断前写了、但还没记进进度的）
+func removeChunksAfter(outDir string, idx int) error {
+	matches, err := filepath.Glob(filepath.Join(outDir, "chunk_*"))
+	if err != nil {
+		return err
+	}
+	for _, path := range matches {
+		name := strings.TrimPrefix(filepath.Base(path), "chunk_")
+		if len(name) < 5 {
+			continue
+		}
+		n, err := strconv.Atoi(name[:5])
+		if err != nil || n <= idx {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		log.Printf("resume: removed stale %s", filepath.Base(path))
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// phaseReservoir 按对局阶段分层的蓄水池抽样：每个阶段各有固定容量，
+// 开局局面再多也只占 phase_mix 规定的份额，其余阶段样本不够时按实际数量写出。
+type phaseReservoir struct {
+	cap  [3]int
+	seen [3]int
+	buf  [3][]finishedSample
+	r    *rand.Rand
+}
+
+func newPhaseReservoir(total int, mix string, r *rand.Rand) (*phaseReservoir, error) {
+	parts := strings.Split(mix, ",")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("phase_mix %q: want 3 weights", mix)
+	}
+	var w [3]float64
+	sum := 0.0
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("phase_mix %q: bad weight %q", mix, p)
+		}
+		w[i] = v
+		sum += v
+	}
+	if sum == 0 {
+		return nil, fmt.Errorf("phase_mix %q: all weights are zero", mix)
+	}
+	res := &phaseReservoir{r: r}
+	for i := range w {
+		res.cap[i] = int(float64(total) * w[i] / sum)
+	}
+	return res, nil
+}
+
+// add 经典 Algorithm R：第 k 个样本以 cap/k 的概率替换池中随机一个
+func (p *phaseReservoir) add(s finishedSample) {
+	ph := s.phase
+	p.seen[ph]++
+	if len(p.buf[ph]) < p.cap[ph] {
+		p.buf[ph] = append(p.buf[ph], s)
+		return
+	}
+	if j := p.r.Intn(p.seen[ph]); j < p.cap[ph] {
+		p.buf[ph][j] = s
+	}
+}
+
+// drain 返回全部入选样本并打乱顺序，避免分片内按阶段扎堆
+func (p *phaseReservoir) drain() []finishedSample {
+	var out []finishedSample
+	for i := range p.buf {
+		out = append(out, p.buf[i]...)
+		p.buf[i] = nil
+	}
+	p.r.Shuffle(len(out), func(i, j int) { out[i], out[j] = out[j], out[i] })
+	return out
+}
+
+func (p *phaseReservoir) String() string {
+	return fmt.Sprintf("opening %d/%d, midgame %d/%d, endgame %d/%d (kept/seen)",
+		len(p.buf[0]), p.seen[0], len(p.buf[1]), p.seen[1], len(p.buf[2]), p.seen[2])
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"hexxagon_go/internal/game"
+)
+
+// targetSet 除胜负外额外写出的价值目标
+type targetSet struct {
+	Score     bool // 终局子数差（归一化），写 _S.bin / Sample.score
+	Ownership bool // 终局每格归属，写 _O.bin / Sample.ownership
+}
+
+func parseTargets(s string) (targetSet, error) {
+	var t targetSet
+	for _, name := range strings.Split(s, ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case "score":
+			t.Score = true
+		case "ownership":
+			t.Ownership = true
+		default:
+			return t, fmt.Errorf("unknown target %q (want score, ownership)", name)
+		}
+	}
+	return t, nil
+}
+
+// finalTargets 由终局局面算出的附加目标，下标 0 为 A 方视角、1 为 B 方视角
+type finalTargets struct {
+	score [2]float32
+	own   [2][]int8 // 9×9，与 policy 同索引：+1 我方 / -1 对方 / 0 空或盘外
+}
+
+func computeFinalTargets(b *game.Board) finalTargets {
+	var t finalTargets
+	a, bb := b.CountPieces(game.PlayerA), b.CountPieces(game.PlayerB)
+	// 按可落子格数归一化，障碍格多的布局也落在 [-1,1]
+	playable := game.BoardN - b.CountPieces(game.Blocked)
+	if playable > 0 {
+		t.score[0] = float32(a-bb) / float32(playable)
+		t.score[1] = float32(bb-a) / float32(playable)
+	}
+
+	t.own[0] = make([]int8, game.GridSize*game.GridSize)
+	t.own[1] = make([]int8, game.GridSize*game.GridSize)
+	for i := 0; i < game.BoardN; i++ {
+		g := game.AxialToIndex(game.CoordOf[i])
+		switch b.GetI(i) {
+		case game.PlayerA:
+			t.own[0][g], t.own[1][g] = 1, -1
+		case game.PlayerB:
+			t.own[0][g], t.own[1][g] = -1, 1
+		}
+	}
+	return t
+}
+
+func (t *finalTargets) forSide(side game.CellState) (float32, []int8) {
+	if side == game.PlayerB {
+		return t.score[1], t.own[1]
+	}
+	return t.score[0], t.own[0]
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"hexxagon_go/internal/game"
+)
+
+// throughput 生成速度计数：对局、搜索过的局面（每步一次 MCTS）、产出样本
+type throughput struct {
+	games   atomic.Int64
+	plies   atomic.Int64
+	samples atomic.Int64
+	start   time.Time
+}
+
+var tp = &throughput{start: time.Now()}
+
+// tpSummary 写进 throughput.json，供调 -workers / 批量参数时对比
+type tpSummary struct {
+	Elapsed       float64                        `json:"elapsed_sec"`
+	Workers       int                            `json:"workers"`
+	Sims          int                            `json:"sims"`
+	Games         int64                          `json:"games"`
+	Positions     int64                          `json:"positions"`
+	Samples       int64                          `json:"samples"`
+	GamesPerMin   float64                        `json:"games_per_min"`
+	PositionsPerS float64                        `json:"positions_per_sec"`
+	NN            map[string]game.NNSessionStats `json:"nn"`
+	Models        []game.ModelInfo               `json:"models,omitempty"`
+	Mem           memSummary                     `json:"mem"`
+}
+
+func (t *throughput) summary(workers, sims int) tpSummary {
+	el := time.Since(t.start).Seconds()
+	s := tpSummary{
+		Elapsed:   el,
+		Workers:   workers,
+		Sims:      sims,
+		Games:     t.games.Load(),
+		Positions: t.plies.Load(),
+		Samples:   t.samples.Load(),
+		NN:        game.GetNNStats(),
+		Models:    game.GetModelInfo(),
+		Mem:       mem.sample(),
+	}
+	if el > 0 {
+		s.GamesPerMin = float64(s.Games) / el * 60
+		s.PositionsPerS = float64(s.Positions) / el
+	}
+	return s
+}
+
+func (s tpSummary) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d 局 %.1f 局/分 | %.1f 局面/秒 | %d 样本 | %s", s.Games, s.GamesPerMin, s.PositionsPerS, s.Samples, s.Mem.String(s.Workers))
+	names := make([]string, 0, len(s.NN))
+	for name := range s.NN {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		st := s.NN[name]
+		fmt.Fprintf(&b, " | %s[%s] %d 次 占用 %.0f%% 延迟 %v",
+			name, st.Provider, st.Calls, 100*st.Occupancy(), st.AvgLatency().Round(time.Microsecond))
+	}
+	return b.String()
+}
+
+// report 每隔 every 打一行日志，直到 stop 关闭
+func (t *throughput) report(every time.Duration, workers, sims int, stop <-chan struct{}) {
+	if every <= 0 {
+		return
+	}
+	tick := time.NewTicker(every)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			s := t.summary(workers, sims)
+			log.Printf("[stats] %s", s)
+			if s.Mem.nearLimit() {
+				log.Printf("[mem] 警告：RSS %s 已超过软上限 %s 的 90%%，考虑减少 -workers 或 -reservoir",
+					fmtBytes(s.Mem.RSS), fmtBytes(s.Mem.Limit))
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (t *throughput) writeSummary(outDir string, workers, sims int) error {
+	s := t.summary(workers, sims)
+	log.Printf("[stats] 合计: %s", s)
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outDir, "throughput.json"), b, 0644)
+}
@@ -0,0 +1,139 @@
+// cmd/solve/main.go
+// 小棋盘完全求解（不跳跃，见 internal/game/solver.go）：
+//
+//	go run ./cmd/solve                     # 半径 2 开局的精确值与每个着法的值
+//	go run ./cmd/solve -validate 200       # 随机局面上对照 α-β 搜索和静态评估
+//
+// -validate 的统计：搜索着法是否最优、平均损失（终局子数差），静态评估的符号与精确值是否一致。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"time"
+
+	"hexxagon_go/internal/cli"
+	"hexxagon_go/internal/engine"
+	"hexxagon_go/internal/game"
+)
+
+func main() {
+	radius := flag.Int("radius", 2, "小棋盘半径 1..3（3 只能解空格少的局面）")
+	validate := flag.Int("validate", 0, "随机抽这么多个局面对照搜索与评估；0=只解开局")
+	depth := flag.Int("depth", 3, "-validate 时 α-β 的搜索深度")
+	maxEmpties := flag.Int("max-empties", game.SolverMaxEmpties, "求解的空格上限，0=不限")
+	common := cli.Common{Seed: time.Now().UnixNano()}
+	common.Register(flag.CommandLine, cli.Seed)
+	cli.Parse("solve")
+
+	// 对照用静态评估，免得 NN 的量纲和加载时间掺进来
+	game.UseONNXForPlayerA, game.UseONNXForPlayerB = false, false
+
+	s := game.NewSolver()
+	s.MaxEmpties = *maxEmpties
+	if *validate > 0 {
+		runValidate(s, *radius, *validate, *depth, rand.New(rand.NewSource(common.Seed)))
+		return
+	}
+
+	gs, err := game.SmallBoardState(*radius)
+	if err != nil {
+		log.Fatal(err)
+	}
+	t0 := time.Now()
+	moves, vals, err := s.SolveMoves(gs.Board, game.PlayerA)
+	if err != nil {
+		log.Fatal(err)
+	}
+	best := vals[0]
+	for _, v := range vals {
+		best = max(best, v)
+	}
+	fmt.Printf("半径 %d 开局（不跳跃）先手精确值 %+d，局面 %d 个，用时 %v\n", *radius, best, s.Nodes, time.Since(t0).Round(time.Millisecond))
+	idx := make([]int, len(moves))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return vals[idx[a]] > vals[idx[b]] })
+	for _, i := range idx {
+		fmt.Printf("  %-10s %+d\n", engine.FormatMove(moves[i]), vals[i])
+	}
+}
+
+// runValidate 从小棋盘开局随机走若干手抽局面，对照精确解
+func runValidate(s *game.Solver, radius, n, depth int, rng *rand.Rand) {
+	var positions, searchOK, evalOK, evalCnt, skipped int
+	var loss int
+	for positions < n {
+		gs, err := game.SmallBoardState(radius)
+		if err != nil {
+			log.Fatal(err)
+		}
+		plies := rng.Intn(12)
+		for i := 0; i < plies && !gs.GameOver; i++ {
+			moves := clones(gs.Board, gs.CurrentPlayer)
+			if len(moves) == 0 {
+				break
+			}
+			gs.MakeMove(moves[rng.Intn(len(moves))])
+		}
+		if gs.GameOver || !gs.HasLegalMoves() {
+			continue
+		}
+		b, side := gs.Board, gs.CurrentPlayer
+		exact, err := s.Solve(b, side)
+		if err != nil {
+			skipped++
+			if skipped > 10*n {
+				log.Fatalf("抽不到空格不多于 %d 的局面: %v", s.MaxEmpties, err)
+			}
+			continue
+		}
+		positions++
+
+		if mv, ok := game.FindBestMoveAtDepth(b, side, int64(depth), false); ok {
+			nb := b.Clone()
+			mv.MakeMove(nb, side)
+			v, err := s.Solve(nb, game.Opponent(side))
+			if err != nil {
+				log.Fatal(err)
+			}
+			if -v == exact {
+				searchOK++
+			}
+			loss += exact + v
+		}
+		if exact != 0 {
+			evalCnt++
+			if (game.EvaluateBitBoard(b, side) > 0) == (exact > 0) {
+				evalOK++
+			}
+		}
+	}
+	fmt.Printf("半径 %d，%d 个局面（不跳跃），α-β depth %d：\n", radius, positions, depth)
+	fmt.Printf("  搜索着法最优  %d/%d (%.1f%%)，平均损失 %.2f 子\n",
+		searchOK, positions, 100*float64(searchOK)/float64(positions), float64(loss)/float64(positions))
+	if evalCnt > 0 {
+		fmt.Printf("  静态评估符号  %d/%d (%.1f%%) 与精确值一致（不计和棋）\n",
+			evalOK, evalCnt, 100*float64(evalOK)/float64(evalCnt))
+	}
+	fmt.Printf("  求解局面累计 %d 个\n", s.Nodes)
+}
+
+// clones side 的全部克隆；没有时给全部着法（同搜索的不跳跃规则）
+func clones(b *game.Board, side game.CellState) []game.Move {
+	all := game.GenerateMoves(b, side)
+	var out []game.Move
+	for _, mv := range all {
+		if mv.IsClone() {
+			out = append(out, mv)
+		}
+	}
+	if len(out) == 0 {
+		return all
+	}
+	return out
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	"hexxagon_go/internal/engine"
+	"hexxagon_go/internal/game"
+	"hexxagon_go/internal/matchlog"
+)
+
+// 分歧日志（-disagreements）：两个引擎在同一局面（含执子方）各走过一次、选的着法不同时，
+// 写一行 JSON，带局面和双方的着法与主变。赛程里每对引擎轮流先手、开局相同，
+// 同一条线会在两局里由双方各走一次，攒下来就是两者判断不一的局面，适合拿去逐个分析系统性弱点。
+// 开着时每步多算一条主变（外部引擎用 go ... pv 1，见 internal/engine），会慢一些。
+//
+// 记住的局面最多 maxDisagreeSeen 个，满了先忘最早记下的：配对的两局前后脚下完，
+// 要比的局面早就比过了，长赛程不会因此把内存吃满。
+
+const maxDisagreeSeen = 1 << 18
+
+type disagreeLog struct {
+	mu    sync.Mutex
+	f     *os.File
+	enc   *json.Encoder
+	seen  map[string][]choice // 局面 → 各引擎第一次在这里的选择
+	order []string            // seen 里的局面按记下的先后排成环，next 指向最早的
+	next  int
+	n     int
+}
+
+// choice 一个引擎在某局面下的选择
+type choice struct {
+	Engine string   `json:"engine"`
+	Move   string   `json:"move"`
+	PV     []string `json:"pv"`
+	Depth  int      `json:"depth,omitempty"` // 完成的迭代深度（外部引擎有 info 才有）
+}
+
+// disagreement 日志里的一行
+type disagreement struct {
+	Position string `json:"position"` // GameState.PositionString，可直接喂给引擎协议的 position 命令
+	Side     string `json:"side"`
+	Ply      int    `json:"ply"` // 后一个引擎遇到它时是第几手
+	First    choice `json:"first"`
+	Second   choice `json:"second"`
+}
+
+// openDisagreeLog path 为空时返回 nil（不记）
+func openDisagreeLog(path string) (*disagreeLog, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetEscapeHTML(false) // 着法里的 '>' 原样写出
+	return &disagreeLog{f: f, enc: enc, seen: map[string][]choice{}}, nil
+}
+
+func (d *disagreeLog) enabled() bool { return d != nil }
+
+// observe 记下 name 在 st 下走了 mv（主变 pv），与之前在同一局面走过的其他引擎比较
+func (d *disagreeLog) observe(st *game.GameState, ply int, name string, mv game.Move, pv []game.Move, depth int) error {
+	if d == nil {
+		return nil
+	}
+	pos := st.PositionString()
+	c := choice{Engine: name, Move: engine.FormatMove(mv), Depth: depth}
+	for _, m := range pv {
+		c.PV = append(c.PV, engine.FormatMove(m))
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	prev, ok := d.seen[pos]
+	for _, p := range prev {
+		if p.Engine == name {
+			return nil // 同一引擎再次遇到：只比第一次，所以每对引擎在每个局面最多写一行
+		}
+	}
+	if !ok {
+		d.remember(pos)
+	}
+	d.seen[pos] = append(prev, c)
+	for _, p := range prev {
+		if p.Move == c.Move {
+			continue
+		}
+		d.n++
+		if err := d.enc.Encode(disagreement{Position: pos, Side: matchlog.SideName(st.CurrentPlayer), Ply: ply, First: p, Second: c}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remember 把新局面排进环；满了先忘掉最早的那个。调用方持 mu
+func (d *disagreeLog) remember(pos string) {
+	if len(d.order) < maxDisagreeSeen {
+		d.order = append(d.order, pos)
+		return
+	}
+	delete(d.seen, d.order[d.next])
+	d.order[d.next] = pos
+	d.next = (d.next + 1) % maxDisagreeSeen
+}
+
+// Close 关文件，返回写出的分歧条数
+func (d *disagreeLog) Close() (int, error) {
+	if d == nil {
+		return 0, nil
+	}
+	return d.n, d.f.Close()
+}
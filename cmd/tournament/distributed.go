@@ -0,0 +1,270 @@
+package main
+
+// 分布式模式：协调者（-serve）持有赛程与战绩，通过 HTTP 把单局分给工作节点（-worker）；
+// 工作节点用本机的引擎配置下完整一局再回报结果。引擎按名字对应，各节点的 path/dir 可以不同。
+//
+//	POST /assign  {"worker": "..."}  → {"id": 7, "a": "go-d2", "b": "ref-ai"} | {"wait": true} | {"done": true}
+//	POST /result  {"id": 7, "worker": "...", "score_a": 1, ...}
+//	GET  /status  当前战绩（纯文本）
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"hexxagon_go/internal/matchlog"
+)
+
+type assignRequest struct {
+	Worker string `json:"worker"`
+}
+
+type assignment struct {
+	ID   int    `json:"id,omitempty"`
+	A    string `json:"a,omitempty"` // 执 A（先手）的引擎名
+	B    string `json:"b,omitempty"`
+	Wait bool   `json:"wait,omitempty"` // 暂无可分发的对局，稍后再来
+	Done bool   `json:"done,omitempty"` // 全部结束，工作节点可以退出
+}
+
+type resultReport struct {
+	ID     int    `json:"id"`
+	Worker string `json:"worker"`
+	gameResult
+}
+
+type inflightGame struct {
+	pairing
+	worker   string
+	deadline time.Time
+}
+
+type coordinator struct {
+	mu       sync.Mutex
+	st       *standings
+	queue    []pairing
+	retry    []pairing // 租约过期需要重下的对局
+	inflight map[int]*inflightGame
+	nextID   int
+	lease    time.Duration
+	start    time.Time
+	finished chan struct{}
+	closed   bool
+}
+
+// take 取下一局可分发的对局；SPRT 已有结论的对跳过
+func (c *coordinator) take() (pairing, bool) {
+	for len(c.retry) > 0 {
+		g := c.retry[0]
+		c.retry = c.retry[1:]
+		if !c.st.pairDone(g.a, g.b) {
+			return g, true
+		}
+	}
+	for len(c.queue) > 0 {
+		g := c.queue[0]
+		c.queue = c.queue[1:]
+		if !c.st.pairDone(g.a, g.b) {
+			return g, true
+		}
+	}
+	return pairing{}, false
+}
+
+func (c *coordinator) assign(worker string) assignment {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for id, g := range c.inflight {
+		if now.After(g.deadline) {
+			log.Printf("对局 #%d 租约过期（%s），重新分发", id, g.worker)
+			c.retry = append(c.retry, g.pairing)
+			delete(c.inflight, id)
+		}
+	}
+
+	g, ok := c.take()
+	if !ok {
+		if len(c.inflight) == 0 {
+			return assignment{Done: true}
+		}
+		return assignment{Wait: true}
+	}
+	c.nextID++
+	c.inflight[c.nextID] = &inflightGame{pairing: g, worker: worker, deadline: now.Add(c.lease)}
+	return assignment{ID: c.nextID, A: c.st.names[g.a], B: c.st.names[g.b]}
+}
+
+func (c *coordinator) report(r resultReport) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	g, ok := c.inflight[r.ID]
+	if !ok {
+		return // 重复或租约过期后才到的结果：该局已另行分发
+	}
+	delete(c.inflight, r.ID)
+	c.st.record(g.a, g.b, r.gameResult)
+	log.Printf("对局 #%d %s vs %s = %.1f（%s）", r.ID, c.st.names[g.a], c.st.names[g.b], r.ScoreA, r.Worker)
+
+	if len(c.inflight) == 0 && !c.pending() && !c.closed {
+		c.closed = true
+		close(c.finished)
+	}
+}
+
+// pending 是否还有需要下的对局（不出队）
+func (c *coordinator) pending() bool {
+	for _, q := range [][]pairing{c.retry, c.queue} {
+		for _, g := range q {
+			if !c.st.pairDone(g.a, g.b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func runCoordinator(addr string, st *standings, games []pairing, lease time.Duration) error {
+	c := &coordinator{
+		st:       st,
+		queue:    games,
+		inflight: make(map[int]*inflightGame),
+		lease:    lease,
+		start:    time.Now(),
+		finished: make(chan struct{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /assign", func(w http.ResponseWriter, r *http.Request) {
+		var req assignRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, c.assign(req.Worker))
+	})
+	mux.HandleFunc("POST /result", func(w http.ResponseWriter, r *http.Request) {
+		var rep resultReport
+		if err := json.NewDecoder(r.Body).Decode(&rep); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		c.report(rep)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintf(w, "进行中 %d 局，待分发 %d 局\n", len(c.inflight), len(c.queue)+len(c.retry))
+		c.st.print(w, time.Since(c.start))
+	})
+
+	srv := &http.Server{Addr: addr, Handler: mux}
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.ListenAndServe() }()
+	log.Printf("协调者监听 %s，共 %d 局待分发", addr, len(games))
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-c.finished:
+	}
+	// 留一点时间让还在轮询的工作节点拿到 done
+	time.Sleep(5 * time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	srv.Shutdown(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.st.print(os.Stdout, time.Since(c.start))
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// postJSON 发请求并解码响应；网络抖动时重试几次
+func postJSON(url string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	var lastErr error
+	for attempt := 0; attempt < 5; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * 2 * time.Second)
+		}
+		resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode >= 300 {
+			lastErr = fmt.Errorf("%s: %s", url, resp.Status)
+			resp.Body.Close()
+			continue
+		}
+		if out != nil {
+			err = json.NewDecoder(resp.Body).Decode(out)
+		}
+		resp.Body.Close()
+		return err
+	}
+	return lastErr
+}
+
+func runWorker(base string, cfg config, maxPlies int, timeout time.Duration, ml *matchlog.Writer, dl *disagreeLog) error {
+	players, closeAll, err := startPlayers(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeAll()
+	byName := make(map[string]*player, len(players))
+	for _, p := range players {
+		byName[p.Name] = p
+	}
+
+	host, _ := os.Hostname()
+	name := fmt.Sprintf("%s/%d", host, os.Getpid())
+	for {
+		var a assignment
+		if err := postJSON(base+"/assign", assignRequest{Worker: name}, &a); err != nil {
+			return fmt.Errorf("领取对局失败: %w", err)
+		}
+		switch {
+		case a.Done:
+			log.Printf("协调者通知全部完成，退出")
+			return nil
+		case a.Wait:
+			time.Sleep(2 * time.Second)
+			continue
+		}
+		pa, pb := byName[a.A], byName[a.B]
+		if pa == nil || pb == nil {
+			return fmt.Errorf("本机配置缺少引擎 %q 或 %q", a.A, a.B)
+		}
+		recs := gameRecords(ml)
+		r := playGame(pa, pb, maxPlies, timeout, recs, dl)
+		if err := writeGameLog(ml, a.ID, recs); err != nil {
+			return err
+		}
+		if r.Forfeit != "" {
+			log.Printf("判负: %s", r.Forfeit)
+		}
+		if err := postJSON(base+"/result", resultReport{ID: a.ID, Worker: name, gameResult: r}, nil); err != nil {
+			return fmt.Errorf("回报结果失败: %w", err)
+		}
+	}
+}
@@ -0,0 +1,520 @@
+// cmd/tournament/main.go
+// 引擎循环赛：参赛者可以是任何说 internal/engine 协议的可执行文件（包括非 Go 实现的参考 AI），
+// 也可以是进程内的内置搜索。每对引擎轮流先手下 -games 局，引擎崩溃、超时或走非法着判负并自动重启。
+// 给了 -sprt 时每对引擎在 SPRT 得出结论后提前停止；-serve / -worker 把对局分发到多台机器，见 distributed.go。
+// -disagreements 记下两个引擎在同一局面选了不同着法的地方，见 disagree.go。
+//
+// 配置示例（engines.json）：
+//
+//	{"engines": [
+//	  {"name": "go-d2", "path": "./engine", "args": ["-nn-b=false"], "depth": 2},
+//	  {"name": "ref-ai", "path": "/opt/ref/hexx", "dir": "/opt/ref", "depth": 4},
+//	  {"name": "builtin-d1", "depth": 1},
+//	  {"name": "builtin-nn-d2", "depth": 2, "nn": true},
+//	  {"name": "greedy", "bot": "greedy"}
+//	]}
+//
+// bot 为 random / greedy 时使用内置基线对手（game.BaselineMove），不搜索，depth 无效；
+// perfect 须配 "allow_jump": false。
+// nn 只对内置搜索有效（叶子用 ONNX 评估，缺省静态评估）；外部引擎的评估器在 args 里给（-nn-a/-nn-b）。
+//
+// 评估改动用同一个引擎、不同参数的两个参赛者对比，例如残局奇偶项开关：
+//
+//	{"engines": [
+//	  {"name": "parity", "path": "./engine", "args": ["-nn-b=false", "-parity-w=4"], "depth": 3},
+//	  {"name": "no-parity", "path": "./engine", "args": ["-nn-b=false", "-parity-w=0"], "depth": 3}
+//	]}
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"hexxagon_go/internal/cli"
+	"hexxagon_go/internal/elo"
+	"hexxagon_go/internal/engine"
+	"hexxagon_go/internal/game"
+	"hexxagon_go/internal/matchlog"
+)
+
+type entrant struct {
+	Name string `json:"name"`
+	engine.Config
+	Depth     int    `json:"depth"`
+	AllowJump *bool  `json:"allow_jump,omitempty"` // 缺省 true
+	Bot       string `json:"bot,omitempty"`        // 内置基线对手：random / greedy（此时忽略 path）
+	NN        bool   `json:"nn,omitempty"`         // 内置搜索的叶子用 ONNX 评估
+}
+
+type config struct {
+	Engines []entrant `json:"engines"`
+}
+
+// player 一个参赛者的运行时句柄
+type player struct {
+	entrant
+	client *engine.Client // nil = 进程内搜索
+}
+
+func (e entrant) allowJump() bool { return e.AllowJump == nil || *e.AllowJump }
+
+func (p *player) start() error {
+	if p.Path == "" || p.Bot != "" {
+		return nil
+	}
+	c, err := engine.StartConfig(p.Config)
+	if err != nil {
+		return err
+	}
+	p.client = c
+	return nil
+}
+
+// restart 崩溃或超时后换一个新进程，下一局继续用
+func (p *player) restart() {
+	if p.client == nil {
+		return
+	}
+	p.client.Close()
+	p.client = nil
+	if err := p.start(); err != nil {
+		log.Printf("[%s] 重启失败: %v", p.Name, err)
+	}
+}
+
+var errTimeout = errors.New("move timeout")
+
+// search 走一步；onProgress 在每完成一层时回调（基线对手不回调）。
+// wantPV 时另给出以这一手开头的主变（基线对手只有这一手）
+func (p *player) search(st *game.GameState, timeout time.Duration, wantPV bool, onProgress func(game.SearchProgress)) (game.Move, []game.Move, bool, error) {
+	if p.Bot != "" {
+		mv, ok, err := game.BaselineMove(p.Bot, st.Board, st.CurrentPlayer, p.allowJump(), nil)
+		return mv, []game.Move{mv}, ok, err
+	}
+	if p.Path == "" {
+		// ONNX 开关和置换表都是全局的：每手按本参赛者的设置重设开关（否则执白时会沿用缺省的
+		// UseONNXForPlayerB=true），并清掉上一手另一位内置参赛者存下的分数
+		game.UseONNXForPlayerA, game.UseONNXForPlayerB = p.NN, p.NN
+		game.ClearTT()
+		// 内置搜索无法中途打断，不受 timeout 约束
+		mv, _, ok := game.IterativeDeepeningProgress(st.Board, st.CurrentPlayer, p.Depth, p.allowJump(), onProgress)
+		var pv []game.Move
+		if ok && wantPV {
+			pv = game.PrincipalVariation(st.Board, st.CurrentPlayer, mv, p.Depth, p.allowJump())
+		}
+		return mv, pv, ok, nil
+	}
+	if p.client == nil {
+		return game.Move{}, nil, false, engine.ErrEngineDead
+	}
+
+	type result struct {
+		mv  game.Move
+		pv  []game.Move
+		ok  bool
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		if wantPV {
+			mv, pv, ok, err := p.client.SearchPV(st, p.Depth, p.allowJump(), onProgress)
+			done <- result{mv, pv, ok, err}
+			return
+		}
+		mv, ok, err := p.client.Search(st, p.Depth, p.allowJump(), onProgress)
+		done <- result{mv, nil, ok, err}
+	}()
+	var timer <-chan time.Time
+	if timeout > 0 {
+		timer = time.After(timeout)
+	}
+	select {
+	case r := <-done:
+		return r.mv, r.pv, r.ok, r.err
+	case <-timer:
+		p.client.Kill()
+		<-done
+		return game.Move{}, nil, false, errTimeout
+	}
+}
+
+// gameResult 一局的结果；Fault 为因崩溃/超时/非法着判负的一方名字
+type gameResult struct {
+	ScoreA  float64 `json:"score_a"` // A 方得分 1/0.5/0
+	Fault   string  `json:"fault,omitempty"`
+	Forfeit string  `json:"forfeit,omitempty"` // 判负原因，供日志
+}
+
+// playGame 下一局；recs 非 nil 时逐手追加 matchlog 记录（含结束行），dl 非 nil 时每手交给分歧日志比较
+func playGame(a, b *player, maxPlies int, timeout time.Duration, recs *[]matchlog.Record, dl *disagreeLog) gameResult {
+	r := playMoves(a, b, maxPlies, timeout, recs, dl)
+	if recs != nil {
+		winner := map[float64]game.CellState{1: game.PlayerA, 0: game.PlayerB, 0.5: game.Empty}[r.ScoreA]
+		*recs = append(*recs, matchlog.EndRecord(len(*recs), winner, r.Forfeit)) // 此时 recs 里只有 move 行
+	}
+	return r
+}
+
+// playMoves 实际下棋；判负时 recs 里没有犯规那一手
+func playMoves(a, b *player, maxPlies int, timeout time.Duration, recs *[]matchlog.Record, dl *disagreeLog) gameResult {
+	st := game.NewGameState(4)
+	players := map[game.CellState]*player{game.PlayerA: a, game.PlayerB: b}
+
+	for ply := 0; ply < maxPlies && !st.GameOver; ply++ {
+		if st.AdjudicateIfBlocked() {
+			break
+		}
+		side := st.CurrentPlayer
+		p := players[side]
+		depth := 0
+		nodes0 := atomic.LoadInt64(&game.NodesSearched)
+		t0 := time.Now()
+		mv, pv, ok, err := p.search(st, timeout, dl.enabled(), func(sp game.SearchProgress) { depth = sp.Depth })
+		took := time.Since(t0)
+		if err == nil && !ok {
+			err = errors.New("claimed no move but legal moves exist")
+		}
+		rec := matchlog.MoveRecord(st.Board, side, mv, ply, took)
+		rec.Player, rec.Depth = p.Name, depth
+		if p.client == nil && p.Bot == "" {
+			rec.Nodes = atomic.LoadInt64(&game.NodesSearched) - nodes0 // 外部引擎不报告节点数
+		}
+		if err == nil {
+			if derr := dl.observe(st, ply, p.Name, mv, pv, depth); derr != nil {
+				log.Fatalf("写分歧日志失败: %v", derr)
+			}
+			_, _, err = st.MakeMove(mv)
+		}
+		if err == nil && recs != nil {
+			*recs = append(*recs, rec)
+		}
+		if err != nil {
+			p.restart()
+			lost := 0.0
+			if side == game.PlayerB {
+				lost = 1
+			}
+			return gameResult{ScoreA: lost, Fault: p.Name, Forfeit: fmt.Sprintf("%s ply %d: %v", p.Name, ply, err)}
+		}
+	}
+
+	winner := st.Winner
+	if !st.GameOver {
+		switch {
+		case st.ScoreA > st.ScoreB:
+			winner = game.PlayerA
+		case st.ScoreB > st.ScoreA:
+			winner = game.PlayerB
+		default:
+			winner = game.Empty
+		}
+	}
+	switch winner {
+	case game.PlayerA:
+		return gameResult{ScoreA: 1}
+	case game.PlayerB:
+		return gameResult{ScoreA: 0}
+	}
+	return gameResult{ScoreA: 0.5}
+}
+
+// standings 累计战绩；本地与分布式模式共用
+type standings struct {
+	names   []string
+	points  [][]float64 // points[i][j]：i 对 j 的累计得分
+	wins    [][]int
+	draws   [][]int
+	crashes []int
+	sprt    *elo.SPRT
+	decided [][]int // SPRT 结论（i<j 时以 i 的视角）：+1 接受 H1，-1 接受 H0
+}
+
+func newStandings(names []string, sprt *elo.SPRT) *standings {
+	n := len(names)
+	s := &standings{names: names, crashes: make([]int, n), sprt: sprt}
+	for i := 0; i < n; i++ {
+		s.points = append(s.points, make([]float64, n))
+		s.wins = append(s.wins, make([]int, n))
+		s.draws = append(s.draws, make([]int, n))
+		s.decided = append(s.decided, make([]int, n))
+	}
+	return s
+}
+
+func (s *standings) index(name string) int {
+	for i, n := range s.names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// record 记一局：ai 执 A、bi 执 B
+func (s *standings) record(ai, bi int, r gameResult) {
+	if r.Forfeit != "" {
+		log.Printf("判负: %s", r.Forfeit)
+	}
+	if f := s.index(r.Fault); f >= 0 {
+		s.crashes[f]++
+	}
+	s.points[ai][bi] += r.ScoreA
+	s.points[bi][ai] += 1 - r.ScoreA
+	switch r.ScoreA {
+	case 1:
+		s.wins[ai][bi]++
+	case 0:
+		s.wins[bi][ai]++
+	default:
+		s.draws[ai][bi]++
+		s.draws[bi][ai]++
+	}
+	if s.sprt != nil {
+		i, j := min(ai, bi), max(ai, bi)
+		if s.decided[i][j] == 0 {
+			if d, llr := s.sprt.Decide(s.wins[i][j], s.draws[i][j], s.wins[j][i]); d != 0 {
+				s.decided[i][j] = d
+				log.Printf("SPRT %s vs %s 结束: LLR %.2f，接受 %s", s.names[i], s.names[j], llr, map[int]string{1: "H1", -1: "H0"}[d])
+			}
+		}
+	}
+}
+
+func (s *standings) pairDone(i, j int) bool {
+	return s.decided[min(i, j)][max(i, j)] != 0
+}
+
+func (s *standings) played(i, j int) int {
+	return s.wins[i][j] + s.wins[j][i] + s.draws[i][j]
+}
+
+func (s *standings) print(w io.Writer, elapsed time.Duration) {
+	fmt.Fprintf(w, "\n===== 循环赛结果（用时 %v）=====\n", elapsed.Round(time.Second))
+	for i, name := range s.names {
+		total, cnt := 0.0, 0
+		for j := range s.names {
+			if i != j {
+				total += s.points[i][j]
+				cnt += s.played(i, j)
+			}
+		}
+		if cnt == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%-16s 得分 %5.1f/%-4d (%.1f%%)  Elo(相对全场) %+5.0f  崩溃/超时/非法 %d\n",
+			name, total, cnt, 100*total/float64(cnt), elo.FromScore(total/float64(cnt)), s.crashes[i])
+	}
+	fmt.Fprintln(w, "\n对阵表（行对列的得分 / 局数）:")
+	fmt.Fprintf(w, "%-16s", "")
+	for _, name := range s.names {
+		fmt.Fprintf(w, " %12.12s", name)
+	}
+	fmt.Fprintln(w)
+	for i, name := range s.names {
+		fmt.Fprintf(w, "%-16s", name)
+		for j := range s.names {
+			if i == j {
+				fmt.Fprintf(w, " %12s", "-")
+				continue
+			}
+			fmt.Fprintf(w, " %12s", fmt.Sprintf("%.1f/%d", s.points[i][j], s.played(i, j)))
+		}
+		fmt.Fprintln(w)
+	}
+	if s.sprt != nil {
+		fmt.Fprintf(w, "\nSPRT [%.1f, %.1f] alpha=%.2f beta=%.2f:\n", s.sprt.Elo0, s.sprt.Elo1, s.sprt.Alpha, s.sprt.Beta)
+		for i := range s.names {
+			for j := i + 1; j < len(s.names); j++ {
+				_, llr := s.sprt.Decide(s.wins[i][j], s.draws[i][j], s.wins[j][i])
+				verdict := map[int]string{1: "H1", -1: "H0", 0: "未决"}[s.decided[i][j]]
+				fmt.Fprintf(w, "  %s vs %s: LLR %+.2f  %s\n", s.names[i], s.names[j], llr, verdict)
+			}
+		}
+	}
+}
+
+// pairing 赛程里的一局：A 执先
+type pairing struct{ a, b int }
+
+// schedule 每对引擎轮流先手下 games 局
+func schedule(n, games int) []pairing {
+	var out []pairing
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			for g := 0; g < games; g++ {
+				if g%2 == 0 {
+					out = append(out, pairing{i, j})
+				} else {
+					out = append(out, pairing{j, i})
+				}
+			}
+		}
+	}
+	return out
+}
+
+func loadConfig(path string) (config, error) {
+	var cfg config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("解析 %s 失败: %w", path, err)
+	}
+	if len(cfg.Engines) < 2 {
+		return cfg, fmt.Errorf("至少需要两个引擎，配置里有 %d 个", len(cfg.Engines))
+	}
+	for i := range cfg.Engines {
+		if b := cfg.Engines[i].Bot; b != "" {
+			if err := game.CheckBaselineBot(b, cfg.Engines[i].allowJump()); err != nil {
+				return cfg, fmt.Errorf("%s: %w", cfg.Engines[i].Name, err)
+			}
+		}
+		if cfg.Engines[i].Depth < 1 {
+			cfg.Engines[i].Depth = 1
+		}
+	}
+	return cfg, nil
+}
+
+func (c config) names() []string {
+	out := make([]string, len(c.Engines))
+	for i, e := range c.Engines {
+		out[i] = e.Name
+	}
+	return out
+}
+
+// startPlayers 启动全部参赛者；返回的 closeAll 负责收尾
+func startPlayers(cfg config) (players []*player, closeAll func(), err error) {
+	closeAll = func() {
+		for _, p := range players {
+			if p.client != nil {
+				p.client.Close()
+			}
+		}
+	}
+	for _, e := range cfg.Engines {
+		p := &player{entrant: e}
+		if err := p.start(); err != nil {
+			closeAll()
+			return nil, nil, fmt.Errorf("[%s] 启动失败: %w", e.Name, err)
+		}
+		if p.client != nil {
+			log.Printf("[%s] 已启动: %s", e.Name, p.client.Name)
+		}
+		players = append(players, p)
+	}
+	return players, closeAll, nil
+}
+
+func main() {
+	var (
+		cfgPath       = flag.String("config", "engines.json", "参赛引擎配置（JSON）")
+		games         = flag.Int("games", 20, "每对引擎的对局数上限（轮流先手）")
+		maxPlies      = flag.Int("max_plies", 400, "单局最大手数，超过按子数判")
+		timeout       = flag.Duration("move_timeout", 30*time.Second, "外部引擎单步时限，超时判负（0=不限）")
+		sprtFlag      = flag.String("sprt", "", "启用 SPRT：elo0,elo1[,alpha,beta]，每对引擎得出结论即停")
+		serve         = flag.String("serve", "", "分布式协调者：在该地址上分发对局（如 :8090），本机不下棋")
+		worker        = flag.String("worker", "", "分布式工作节点：协调者地址（如 http://host:8090）")
+		lease         = flag.Duration("lease", 10*time.Minute, "协调者：一局分发后多久没回结果就重新分发")
+		matchLog      = flag.String("matchlog", "", "逐手对局日志（JSON Lines，格式见 internal/matchlog）；分布式时由各工作节点各自写；空=不写")
+		disagree      = flag.String("disagreements", "", "分歧日志（JSON Lines）：两个引擎在同一局面选了不同着法时记下局面与双方主变；空=不记")
+		backendReport = flag.Bool("backend-report", false, "初始化 NN 后端并打印能力报告（EP、FP16、批量会话、建会话与热身耗时）")
+	)
+	cli.Parse("tournament")
+	if *backendReport {
+		game.WriteBackendReport(os.Stderr)
+	}
+
+	cfg, err := loadConfig(*cfgPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	sprt, err := elo.ParseSPRT(*sprtFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var ml *matchlog.Writer
+	if *serve == "" {
+		if ml, err = matchlog.Create(*matchLog, "tournament"); err != nil {
+			log.Fatalf("创建对局日志失败: %v", err)
+		}
+		defer ml.Close()
+	}
+
+	dl, err := openDisagreeLog(*disagree)
+	if err != nil {
+		log.Fatalf("创建分歧日志失败: %v", err)
+	}
+	defer func() {
+		if n, err := dl.Close(); err != nil {
+			log.Printf("关闭分歧日志失败: %v", err)
+		} else if dl.enabled() {
+			log.Printf("分歧日志 %s: %d 个局面", *disagree, n)
+		}
+	}()
+
+	switch {
+	case *serve != "":
+		st := newStandings(cfg.names(), sprt)
+		if err := runCoordinator(*serve, st, schedule(len(cfg.Engines), *games), *lease); err != nil {
+			log.Fatal(err)
+		}
+	case *worker != "":
+		if err := runWorker(*worker, cfg, *maxPlies, *timeout, ml, dl); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		runLocal(cfg, sprt, *games, *maxPlies, *timeout, ml, dl)
+	}
+}
+
+func runLocal(cfg config, sprt *elo.SPRT, games, maxPlies int, timeout time.Duration, ml *matchlog.Writer, dl *disagreeLog) {
+	players, closeAll, err := startPlayers(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer closeAll()
+
+	st := newStandings(cfg.names(), sprt)
+	start := time.Now()
+	for i, g := range schedule(len(players), games) {
+		if st.pairDone(g.a, g.b) {
+			continue
+		}
+		recs := gameRecords(ml)
+		st.record(g.a, g.b, playGame(players[g.a], players[g.b], maxPlies, timeout, recs, dl))
+		if err := writeGameLog(ml, i, recs); err != nil {
+			log.Fatal(err)
+		}
+	}
+	st.print(os.Stdout, time.Since(start))
+}
+
+// gameRecords 开了对局日志时返回一局的记录缓冲，否则 nil
+func gameRecords(ml *matchlog.Writer) *[]matchlog.Record {
+	if ml == nil {
+		return nil
+	}
+	return new([]matchlog.Record)
+}
+
+func writeGameLog(ml *matchlog.Writer, id int, recs *[]matchlog.Record) error {
+	if recs == nil {
+		return nil
+	}
+	if err := ml.WriteGame(id, *recs); err != nil {
+		return fmt.Errorf("写对局日志失败: %w", err)
+	}
+	return nil
+}
@@ -4,6 +4,12 @@ import (
 	"bytes"
 	"embed"
 	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,18 +20,32 @@ import (
 //go:embed audio/*.mp3
 var soundsFS embed.FS
 
+// 音效登记表：audio/<key>.mp3 是 key 的第一版录音，audio/<key>.<n>.mp3 是同一音效的其他版本。
+// 每次播放从中随机挑一版（不连续重复同一版），长局听着不那么单调；加新版本只要放文件，不用改代码。
+// 所有版本在创建时一次解码成 PCM 并记下真实时长，播放时不再解码，连播也按时长排好时间表。
 type AudioManager struct {
-	ctx     *audio.Context
-	buffers map[string][]byte
+	ctx   *audio.Context
+	takes map[string][]clip // key -> 各版本
 
 	mu         sync.Mutex
+	rng        *rand.Rand
+	lastTake   map[string]int // key -> 上次播的版本下标
 	players    []*audio.Player
 	lastPlayer *audio.Player // 保留最近一次播放的 player，防止被 GC
+	busyUntil  time.Time     // 已排好的连播最晚结束时刻
+	paused     bool          // 窗口失焦时暂停，见 Pause
+	held       []*audio.Player
+}
+
+// clip 解码好的一版录音：16 位小端立体声 PCM，采样率同 ctx
+type clip struct {
+	pcm []byte
+	dur time.Duration
 }
 
 // NewAudioManager 接收 main 创建好的 *audio.Context，不再 NewContext
 func NewAudioManager(ctx *audio.Context) (*AudioManager, error) {
-	names := []string{
+	required := []string{
 		"cancel_select_piece",
 		"game_over",
 		"red_capture_white_after",
@@ -38,38 +58,171 @@ func NewAudioManager(ctx *audio.Context) (*AudioManager, error) {
 		"white_split",
 		"all_capture_after",
 	}
-	buf := make(map[string][]byte, len(names))
-	for _, name := range names {
-		data, err := soundsFS.ReadFile("audio/" + name + ".mp3")
+	raw, err := loadTakes(soundsFS, "audio")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range required {
+		if len(raw[name]) == 0 {
+			return nil, fmt.Errorf("加载音频 %s 失败: 缺少 audio/%s.mp3", name, name)
+		}
+	}
+	m := &AudioManager{
+		ctx:      ctx,
+		takes:    make(map[string][]clip, len(raw)),
+		rng:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		lastTake: make(map[string]int),
+	}
+	if ctx == nil {
+		return m, nil // 无音频设备（无头测试）：不解码，播放全是空操作
+	}
+	for key, list := range raw {
+		for i, data := range list {
+			c, err := decodeClip(ctx.SampleRate(), data)
+			if err != nil {
+				return nil, fmt.Errorf("解码音频 %s（第 %d 版）失败: %w", key, i+1, err)
+			}
+			m.takes[key] = append(m.takes[key], c)
+		}
+	}
+	return m, nil
+}
+
+// decodeClip 把 mp3 整段解码成 rate 采样率的 PCM，时长按字节数折算（每帧 4 字节）
+func decodeClip(rate int, data []byte) (clip, error) {
+	s, err := mp3.DecodeWithSampleRate(rate, bytes.NewReader(data))
+	if err != nil {
+		return clip{}, err
+	}
+	pcm, err := io.ReadAll(s)
+	if err != nil {
+		return clip{}, err
+	}
+	return clip{
+		pcm: pcm,
+		dur: time.Duration(len(pcm)/4) * time.Second / time.Duration(rate),
+	}, nil
+}
+
+// loadTakes 扫描 dir 下的 mp3，按文件名第一个 '.' 之前的部分归到同一 key；
+// 版本按文件名排序，<key>.mp3 排在最前
+func loadTakes(fsys fs.FS, dir string) (map[string][][]byte, error) {
+	files, err := fs.Glob(fsys, dir+"/*.mp3")
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool {
+		a, b := strings.TrimSuffix(files[i], ".mp3"), strings.TrimSuffix(files[j], ".mp3")
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return a < b
+	})
+	takes := make(map[string][][]byte)
+	for _, f := range files {
+		key, _, _ := strings.Cut(strings.TrimPrefix(f, dir+"/"), ".")
+		data, err := fs.ReadFile(fsys, f)
 		if err != nil {
-			return nil, fmt.Errorf("加载音频 %s 失败: %w", name, err)
+			return nil, fmt.Errorf("加载音频 %s 失败: %w", f, err)
 		}
-		buf[name] = data
+		takes[key] = append(takes[key], data)
 	}
-	return &AudioManager{ctx: ctx, buffers: buf}, nil
+	return takes, nil
 }
 
-// Play 播放 key 对应音效，并保存引用，防止被 GC
-func (m *AudioManager) Play(key string) {
-	data, ok := m.buffers[key]
+// pickTake 随机挑 key 的一个版本，有多个版本时避开上一次播的那版
+func (m *AudioManager) pickTake(key string) (clip, bool) {
+	list := m.takes[key]
+	if len(list) == 0 {
+		return clip{}, false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := 0
+	if len(list) > 1 {
+		last, played := m.lastTake[key]
+		i = m.rng.Intn(len(list))
+		if played && i == last {
+			i = (i + 1 + m.rng.Intn(len(list)-1)) % len(list)
+		}
+	}
+	m.lastTake[key] = i
+	return list[i], true
+}
+
+// Play 播放 key 对应音效（居中），并保存引用，防止被 GC
+func (m *AudioManager) Play(key string) { m.PlayAt(key, 0) }
+
+// PlayAt 按声像 pan 播放 key：-1 全左，0 居中，+1 全右
+func (m *AudioManager) PlayAt(key string, pan float64) {
+	if m == nil || m.ctx == nil {
+		return // 无音频设备（无头测试）
+	}
+	c, ok := m.pickTake(key)
 	if !ok {
 		fmt.Println("AudioManager.Play：未找到音效", key)
 		return
 	}
-	r := bytes.NewReader(data)
-	s, err := mp3.Decode(m.ctx, r)
-	if err != nil {
-		fmt.Println("AudioManager.Play：解码失败", err)
-		return
+	m.start(c, pan)
+}
+
+// start 用预解码的 PCM 起一个 player
+func (m *AudioManager) start(c clip, pan float64) {
+	m.mu.Lock()
+	paused := m.paused
+	m.mu.Unlock()
+	if paused {
+		return // 暂停期间（含连播里排在后面的段）不出声
 	}
-	p, err := m.ctx.NewPlayer(s)
+	p, err := m.ctx.NewPlayer(withPan(bytes.NewReader(c.pcm), pan))
 	if err != nil {
 		fmt.Println("AudioManager.Play：创建 Player 失败", err)
 		return
 	}
 	p.Play()
+	m.mu.Lock()
 	// **关键**：保留引用，防止 GC
 	m.lastPlayer = p
+	m.players = append(m.players, p)
+	m.mu.Unlock()
+}
+
+// Pause 暂停正在播的音效，暂停期间新的音效直接丢弃；Resume 从暂停处接着播
+func (m *AudioManager) Pause() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.paused {
+		return
+	}
+	m.paused = true
+	for _, p := range m.players {
+		if p.IsPlaying() {
+			p.Pause()
+			m.held = append(m.held, p)
+		}
+	}
+	m.players = nil
+}
+
+// Resume 撤销 Pause
+func (m *AudioManager) Resume() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.paused {
+		return
+	}
+	m.paused = false
+	for _, p := range m.held {
+		p.Play()
+	}
+	m.players = append(m.players, m.held...)
+	m.held = nil
 }
 
 // Update 应每帧调用一次，清理已停止的播放器
@@ -85,35 +238,96 @@ func (m *AudioManager) Update() {
 	m.players = alive
 }
 
-func (m *AudioManager) PlaySequential(keys ...string) {
-	go func() {
-		for _, key := range keys {
-			data, ok := m.buffers[key]
-			if !ok {
-				continue
-			}
-			r := bytes.NewReader(data)
-			s, err := mp3.DecodeWithSampleRate(44100, r)
-			if err != nil {
-				continue
-			}
-			p, err := m.ctx.NewPlayer(s)
-			if err != nil {
-				continue
-			}
-			p.Play()
-			// 等待这个 player 播放完毕
-			for p.IsPlaying() {
-				time.Sleep(10 * time.Millisecond)
-			}
-			// 这里循环结束，自动进入下一个 key
+func (m *AudioManager) PlaySequential(keys ...string) time.Duration {
+	return m.PlaySequentialAt(0, keys...)
+}
+
+// PlaySequentialAt 依次播放 keys，整串用同一个声像。
+// 调用时就选好各段版本，按解码出的真实时长排定起播时刻，首段立即播放；返回整串时长
+func (m *AudioManager) PlaySequentialAt(pan float64, keys ...string) time.Duration {
+	if m == nil || m.ctx == nil {
+		return 0
+	}
+	var at time.Duration
+	for _, key := range keys {
+		c, ok := m.pickTake(key)
+		if !ok {
+			continue
+		}
+		if at == 0 {
+			m.start(c, pan)
+		} else {
+			time.AfterFunc(at, func() { m.start(c, pan) })
 		}
-	}()
+		at += c.dur
+	}
+	m.mu.Lock()
+	if end := time.Now().Add(at); end.After(m.busyUntil) {
+		m.busyUntil = end
+	}
+	m.mu.Unlock()
+	return at
 }
 
+// Busy 最近的音效还在播，或排好的连播还没放完
 func (m *AudioManager) Busy() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if time.Now().Before(m.busyUntil) {
+		return true
+	}
 	if m.lastPlayer == nil {
 		return false
 	}
 	return m.lastPlayer.IsPlaying()
 }
+
+// withPan 给解码出的流加声像；pan 为 0 时原样返回
+func withPan(src io.ReadSeeker, pan float64) io.ReadSeeker {
+	if pan == 0 {
+		return src
+	}
+	l, r := panGains(pan)
+	return &panStream{src: src, gainL: l, gainR: r}
+}
+
+// panGains 等功率声像：居中时两声道都是 1，偏到一侧时远侧衰减、近侧不超过 1（不削波）
+func panGains(pan float64) (left, right float64) {
+	pan = math.Max(-1, math.Min(1, pan))
+	a := (pan + 1) * math.Pi / 4
+	return math.Min(1, math.Sqrt2*math.Cos(a)), math.Min(1, math.Sqrt2*math.Sin(a))
+}
+
+// panStream 按左右增益缩放 16 位小端立体声 PCM（ebiten 解码器的输出格式）
+type panStream struct {
+	src          io.ReadSeeker
+	gainL, gainR float64
+}
+
+func (s *panStream) Read(p []byte) (int, error) {
+	// 只按整帧（4 字节）读，保证每次处理的样本不会被拆开
+	if len(p) < 4 {
+		return 0, io.ErrShortBuffer
+	}
+	n, err := s.src.Read(p[:len(p)&^3])
+	for n%4 != 0 && err == nil {
+		var m int
+		m, err = s.src.Read(p[n : n+4-n%4])
+		n += m
+	}
+	for i := 0; i+3 < n; i += 4 {
+		scaleSample(p[i:i+2], s.gainL)
+		scaleSample(p[i+2:i+4], s.gainR)
+	}
+	return n, err
+}
+
+func (s *panStream) Seek(offset int64, whence int) (int64, error) {
+	return s.src.Seek(offset, whence)
+}
+
+func scaleSample(b []byte, g float64) {
+	v := float64(int16(uint16(b[0]) | uint16(b[1])<<8))
+	out := int16(v * g)
+	b[0], b[1] = byte(out), byte(uint16(out)>>8)
+}
@@ -0,0 +1,218 @@
+// Package botscript 可在运行时加载的脚本对手：不用 Go 工具链，写一个 Starlark（Python 方言，
+// 见 https://github.com/bazelbuild/starlark）文件就能定制 AI 怎么挑着法。
+//
+// 脚本定义下面两个函数之一：
+//
+//	def score(board, move):   # 对每个候选着法调用一次，返回数，最高的被选中，同分随机
+//	    return move.gain + (1 if move.clone else 0)
+//
+//	def choose(board, moves): # 调用一次，从候选里直接挑一个返回
+//	    return max(moves, key = lambda m: m.gain)
+//
+// board 是轮到的一方视角的局面（只读，见 boardValue）：
+//
+//	board.me / board.opponent    "red" 或 "white"
+//	board.radius                 实际可下的棋盘半径
+//	board.get(q, r)              "mine"、"theirs"、"empty" 或 "blocked"（盘外也算 blocked）
+//	board.count(kind)            该类格子数，kind 同上
+//	board.cells(kind)            该类格子的 (q, r) 列表
+//	board.moves(side = "mine")   该方的全部合法着法；"theirs" 为对方的
+//	board.play(move)             走完这一手的新局面，视角不变
+//
+// move 的属性（见 moveValue）：src、dst 为 (q, r)，clone、jump，gain（这一手感染的对方子数），
+// dist（落点到中心的距离），side（走子方）；str(move) 为 HGN 记法 "q,r>q,r"。
+// 另外可用 rand()（[0,1) 均匀随机）和 math 模块。脚本顶层只在加载时执行一次，之后全局变量冻结；
+// 每步棋的执行步数有上限，死循环会报错而不是卡住界面。
+package botscript
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.starlark.net/lib/math"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+
+	"hexxagon_go/internal/game"
+)
+
+// MaxSteps 每次挑着法（一次 Move 调用）脚本最多执行的步数
+const MaxSteps = 50_000_000
+
+// Script 加载好的脚本对手；全局变量已冻结，可以在多个协程里同时用
+type Script struct {
+	Name   string // 文件名（不含目录和扩展名），用于日志和界面
+	score  starlark.Callable
+	choose starlark.Callable
+}
+
+var fileOptions = &syntax.FileOptions{Set: true, While: true, TopLevelControl: true, GlobalReassign: true}
+
+var predeclared = starlark.StringDict{
+	"rand": starlark.NewBuiltin("rand", builtinRand),
+	"math": math.Module,
+}
+
+// Parse 执行脚本源码的顶层，取出 score 或 choose；语法错误带行号和列号
+func Parse(name, src string) (*Script, error) {
+	thread := newThread(name, nil)
+	globals, err := starlark.ExecFileOptions(fileOptions, thread, name, src, predeclared)
+	if err != nil {
+		return nil, scriptError(name, err)
+	}
+	globals.Freeze()
+	s := &Script{Name: name}
+	for fn, dst := range map[string]*starlark.Callable{"score": &s.score, "choose": &s.choose} {
+		if v, ok := globals[fn]; ok {
+			c, ok := v.(starlark.Callable)
+			if !ok {
+				return nil, fmt.Errorf("%s: %s 应是函数，实为 %s", name, fn, v.Type())
+			}
+			*dst = c
+		}
+	}
+	switch {
+	case s.score == nil && s.choose == nil:
+		return nil, fmt.Errorf("%s: 须定义 score(board, move) 或 choose(board, moves)", name)
+	case s.score != nil && s.choose != nil:
+		return nil, fmt.Errorf("%s: score 与 choose 只能定义一个", name)
+	}
+	return s, nil
+}
+
+// Load 读入并加载脚本文件
+func Load(path string) (*Script, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := Parse(path, string(src))
+	if err != nil {
+		return nil, err
+	}
+	s.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return s, nil
+}
+
+func newThread(name string, r *rand.Rand) *starlark.Thread {
+	thread := &starlark.Thread{Name: name, Print: func(_ *starlark.Thread, msg string) {}}
+	thread.SetMaxExecutionSteps(MaxSteps)
+	thread.SetLocal(randKey, r)
+	return thread
+}
+
+// scriptError 运行期错误带上 Starlark 的调用栈
+func scriptError(name string, err error) error {
+	var evalErr *starlark.EvalError
+	if errors.As(err, &evalErr) {
+		return fmt.Errorf("%s: %s", name, evalErr.Backtrace())
+	}
+	return fmt.Errorf("%s: %w", name, err)
+}
+
+const randKey = "botscript.rand"
+
+func builtinRand(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	if r, _ := thread.Local(randKey).(*rand.Rand); r != nil {
+		return starlark.Float(r.Float64()), nil
+	}
+	return starlark.Float(rand.Float64()), nil
+}
+
+// Score 脚本给 mv 打的分；mv 须是 player 的合法着法，脚本须定义 score
+func (s *Script) Score(b *game.Board, player game.CellState, mv game.Move, r *rand.Rand) (float64, error) {
+	if s.score == nil {
+		return 0, fmt.Errorf("%s: 没有定义 score", s.Name)
+	}
+	thread := newThread(s.Name, r)
+	bv := newBoardValue(b, player)
+	return s.callScore(thread, bv, newMoveValue(b, player, mv))
+}
+
+func (s *Script) callScore(thread *starlark.Thread, bv *boardValue, mv *moveValue) (float64, error) {
+	v, err := starlark.Call(thread, s.score, starlark.Tuple{bv, mv}, nil)
+	if err != nil {
+		return 0, scriptError(s.Name, err)
+	}
+	f, ok := starlark.AsFloat(v)
+	if !ok {
+		return 0, fmt.Errorf("%s: score(%s) 返回了 %s，应返回数", s.Name, mv, v.Type())
+	}
+	return f, nil
+}
+
+// Move 按脚本挑一个着法：score 对每个候选打分取最高、同分随机，choose 直接从候选里挑；r 为 nil 时用全局随机源。
+// allowJump 为 false 时和内置对手一样只在克隆里挑（没有克隆可走时才考虑跳跃）。无合法着返回 ok=false；
+// 脚本出错（抛异常、超步数、返回值不对）返回 err
+func (s *Script) Move(b *game.Board, player game.CellState, allowJump bool, r *rand.Rand) (game.Move, bool, error) {
+	moves := game.GenerateMoves(b, player)
+	if !allowJump {
+		clones := moves[:0:0]
+		for _, mv := range moves {
+			if mv.IsClone() {
+				clones = append(clones, mv)
+			}
+		}
+		if len(clones) > 0 {
+			moves = clones
+		}
+	}
+	if len(moves) == 0 {
+		return game.Move{}, false, nil
+	}
+	thread := newThread(s.Name, r)
+	bv := newBoardValue(b, player)
+	cands := make([]starlark.Value, len(moves))
+	for i, mv := range moves {
+		cands[i] = newMoveValue(b, player, mv)
+	}
+
+	if s.choose != nil {
+		list := starlark.NewList(cands)
+		list.Freeze()
+		v, err := starlark.Call(thread, s.choose, starlark.Tuple{bv, list}, nil)
+		if err != nil {
+			return game.Move{}, false, scriptError(s.Name, err)
+		}
+		if mv, ok := v.(*moveValue); ok && mv.side == player {
+			for _, c := range moves {
+				if c == mv.mv {
+					return c, true, nil
+				}
+			}
+		}
+		return game.Move{}, false, fmt.Errorf("%s: choose 返回了 %s，不是候选着法之一", s.Name, v)
+	}
+
+	intn := rand.Intn
+	if r != nil {
+		intn = r.Intn
+	}
+	var best float64
+	var pick game.Move
+	n := 0
+	for i, c := range cands {
+		v, err := s.callScore(thread, bv, c.(*moveValue))
+		if err != nil {
+			return game.Move{}, false, err
+		}
+		switch {
+		case n == 0 || v > best:
+			best, n, pick = v, 1, moves[i]
+		case v == best:
+			// 蓄水池抽样：同分着法等概率
+			n++
+			if intn(n) == 0 {
+				pick = moves[i]
+			}
+		}
+	}
+	return pick, true, nil
+}
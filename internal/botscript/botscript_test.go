@@ -0,0 +1,197 @@
+package botscript
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"hexxagon_go/internal/game"
+)
+
+func TestBoardAPI(t *testing.T) {
+	src := `
+def choose(board, moves):
+    checks = [
+        board.me == "white",
+        board.opponent == "red",
+        board.radius == 4,
+        board.get(-4, 0) == "mine",
+        board.get(4, 0) == "theirs",
+        board.get(0, 0) == "empty",
+        board.get(9, 9) == "blocked",
+        board.count("mine") == 3 and board.count("theirs") == 3,
+        [board.get(q, r) for q, r in board.cells("mine")] == ["mine"] * 3,
+        len(moves) == len(board.moves()),
+        all([m.side == "white" for m in moves]),
+        all([m.side == "red" for m in board.moves("theirs")]),
+    ]
+    for i, ok in enumerate(checks):
+        if not ok:
+            fail("check %d" % i)
+    mv = [m for m in moves if m.clone][0]
+    after = board.play(mv)
+    if after.count("mine") != 4 or board.count("mine") != 3 or after.get(*mv.dst) != "mine":
+        fail("play")
+    if str(mv) != "%d,%d>%d,%d" % (mv.src + mv.dst) or mv not in moves:
+        fail("move")
+    return mv
+`
+	s, err := Parse("t", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := game.NewGameState(4)
+	before := gs.Board.Hash()
+	if _, ok, err := s.Move(gs.Board, game.PlayerB, true, nil); err != nil || !ok {
+		t.Fatal(ok, err)
+	}
+	if gs.Board.Hash() != before {
+		t.Fatal("脚本改动了棋盘")
+	}
+}
+
+func TestScriptErrors(t *testing.T) {
+	cases := []struct{ src, want string }{
+		{"x = 1", "score(board, move) 或 choose"},
+		{"def score(b, m): return 1\ndef choose(b, ms): return ms[0]", "只能定义一个"},
+		{"score = 1", "应是函数"},
+		{"def score(b, m):\n  return (1", "t:2:"},
+		{"def score(b, m): return \"x\"", "应返回数"},
+		{"def score(b, m): return b.get(0)", "get"},
+		{"def score(b, m): return b.count(\"red\")", "不认识的类别"},
+		{"def score(b, m):\n  while True:\n    pass", "too many steps"},
+		{"def choose(b, ms): return b.moves(\"theirs\")[0]", "不是候选着法"},
+		{"def score(b, m): fail(\"boom\")", "boom"},
+	}
+	gs := game.NewGameState(4)
+	for _, c := range cases {
+		s, err := Parse("t", c.src)
+		if err == nil {
+			_, _, err = s.Move(gs.Board, game.PlayerA, true, nil)
+		}
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%q: 错误 %v，应含 %q", c.src, err, c.want)
+		}
+	}
+}
+
+// 脚本的全局变量加载后冻结：打分时改不了，多个协程共用一个 Script 也安全
+func TestGlobalsFrozen(t *testing.T) {
+	s, err := Parse("t", "seen = []\ndef score(b, m):\n  seen.append(m)\n  return 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = s.Move(game.NewGameState(4).Board, game.PlayerA, true, nil)
+	if err == nil || !strings.Contains(err.Error(), "frozen") {
+		t.Fatalf("错误 %v，应提示已冻结", err)
+	}
+}
+
+// examples/greedy.star 与内置 greedy 同口径：每步净增子数都应和 greedy 挑的一样多
+func TestGreedyScriptMatchesBaseline(t *testing.T) {
+	s, err := Load("examples/greedy.star")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rand.New(rand.NewSource(1))
+	gs := game.NewGameState(4)
+	gain := func(mv game.Move) int {
+		n, _ := mv.ApplyPreview(gs.Board, gs.CurrentPlayer)
+		if mv.IsClone() {
+			n++
+		}
+		return n
+	}
+	for ply := 0; ply < 60 && !gs.GameOver; ply++ {
+		mv, ok, err := s.Move(gs.Board, gs.CurrentPlayer, true, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			break
+		}
+		want, _, _ := game.BaselineMove(game.BotGreedy, gs.Board, gs.CurrentPlayer, true, r)
+		if gain(mv) != gain(want) {
+			t.Fatalf("第 %d 手: 脚本 %v 净增 %d，greedy %v 净增 %d", ply, mv, gain(mv), want, gain(want))
+		}
+		if _, _, err := gs.MakeMove(mv); err != nil {
+			t.Fatalf("第 %d 手 %v 非法: %v", ply, mv, err)
+		}
+	}
+}
+
+// move 的属性与着法一致，打分不改棋盘
+func TestMoveAttrs(t *testing.T) {
+	s, err := Parse("t", "def score(b, m): return m.gain*1000 + (100 if m.clone else 0) + (10 if m.jump else 0) + m.dist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := game.NewGameState(4)
+	before := gs.Board.Hash()
+	for _, mv := range game.GenerateMoves(gs.Board, game.PlayerA) {
+		got, err := s.Score(gs.Board, game.PlayerA, mv, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q, r := mv.To.Q, mv.To.R
+		dist := max(abs(q), abs(r), abs(q+r))
+		want := float64(b2i(mv.IsClone())*100 + b2i(mv.IsJump())*10 + dist)
+		if got != want {
+			t.Fatalf("%v 得分 %v，应为 %v", mv, got, want)
+		}
+	}
+	if gs.Board.Hash() != before {
+		t.Fatal("打分改动了棋盘")
+	}
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// examples/cautious.star 应稳定胜过随机对手
+func TestCautiousBeatsRandom(t *testing.T) {
+	s, err := Load("examples/cautious.star")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rand.New(rand.NewSource(7))
+	wins := 0
+	const games = 6
+	for g := 0; g < games; g++ {
+		scripted := game.PlayerA
+		if g%2 == 1 {
+			scripted = game.PlayerB
+		}
+		gs := game.NewGameState(4)
+		for ply := 0; ply < 300 && !gs.GameOver; ply++ {
+			var mv game.Move
+			var ok bool
+			if gs.CurrentPlayer == scripted {
+				var err error
+				if mv, ok, err = s.Move(gs.Board, gs.CurrentPlayer, true, r); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				mv, ok, _ = game.BaselineMove(game.BotRandom, gs.Board, gs.CurrentPlayer, true, r)
+			}
+			if !ok {
+				gs.AdjudicateIfBlocked()
+				break
+			}
+			if _, _, err := gs.MakeMove(mv); err != nil {
+				t.Fatal(err)
+			}
+		}
+		a, b := gs.GetScores()
+		if (scripted == game.PlayerA) == (a > b) {
+			wins++
+		}
+	}
+	if wins < games-1 {
+		t.Fatalf("脚本对手 %d 局只赢了 %d 局随机对手", games, wins)
+	}
+}
@@ -0,0 +1,249 @@
+package botscript
+
+import (
+	"fmt"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+
+	"hexxagon_go/internal/game"
+)
+
+// 格子类别，board.get / count / cells 用
+const (
+	kindMine    = "mine"
+	kindTheirs  = "theirs"
+	kindEmpty   = "empty"
+	kindBlocked = "blocked"
+)
+
+func sideName(s game.CellState) string {
+	if s == game.PlayerB {
+		return "white"
+	}
+	return "red"
+}
+
+// boardValue 脚本里的 board：me 一方视角的局面。b 不会被改（play 在副本上走），所以不用管冻结
+type boardValue struct {
+	b  *game.Board
+	me game.CellState
+}
+
+var (
+	_ starlark.HasAttrs   = (*boardValue)(nil)
+	_ starlark.HasAttrs   = (*moveValue)(nil)
+	_ starlark.Comparable = (*moveValue)(nil)
+)
+
+func newBoardValue(b *game.Board, me game.CellState) *boardValue {
+	return &boardValue{b: b, me: me}
+}
+
+func (bv *boardValue) String() string {
+	return fmt.Sprintf("<board %s radius=%d>", sideName(bv.me), game.ActiveRadius(bv.b))
+}
+func (bv *boardValue) Type() string          { return "board" }
+func (bv *boardValue) Freeze()               {}
+func (bv *boardValue) Truth() starlark.Bool  { return starlark.True }
+func (bv *boardValue) Hash() (uint32, error) { return 0, fmt.Errorf("unhashable: board") }
+
+var boardAttrs = []string{"cells", "count", "get", "me", "moves", "opponent", "play", "radius"}
+
+func (bv *boardValue) AttrNames() []string { return boardAttrs }
+
+func (bv *boardValue) Attr(name string) (starlark.Value, error) {
+	switch name {
+	case "me":
+		return starlark.String(sideName(bv.me)), nil
+	case "opponent":
+		return starlark.String(sideName(game.Opponent(bv.me))), nil
+	case "radius":
+		return starlark.MakeInt(game.ActiveRadius(bv.b)), nil
+	case "get":
+		return starlark.NewBuiltin("get", bv.get), nil
+	case "count":
+		return starlark.NewBuiltin("count", bv.count), nil
+	case "cells":
+		return starlark.NewBuiltin("cells", bv.cells), nil
+	case "moves":
+		return starlark.NewBuiltin("moves", bv.moves), nil
+	case "play":
+		return starlark.NewBuiltin("play", bv.play), nil
+	}
+	return nil, nil
+}
+
+// kindOf 格子 s 从 me 看属于哪一类
+func (bv *boardValue) kindOf(s game.CellState) string {
+	switch s {
+	case bv.me:
+		return kindMine
+	case game.Opponent(bv.me):
+		return kindTheirs
+	case game.Empty:
+		return kindEmpty
+	}
+	return kindBlocked
+}
+
+// stateOf kind 对应的格子状态
+func (bv *boardValue) stateOf(fn, kind string) (game.CellState, error) {
+	switch kind {
+	case kindMine:
+		return bv.me, nil
+	case kindTheirs:
+		return game.Opponent(bv.me), nil
+	case kindEmpty:
+		return game.Empty, nil
+	case kindBlocked:
+		return game.Blocked, nil
+	}
+	return 0, fmt.Errorf("%s: 不认识的类别 %q（应为 mine/theirs/empty/blocked）", fn, kind)
+}
+
+func (bv *boardValue) get(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var q, r int
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 2, &q, &r); err != nil {
+		return nil, err
+	}
+	i, ok := game.IndexOf[game.HexCoord{Q: q, R: r}]
+	if !ok {
+		return starlark.String(kindBlocked), nil
+	}
+	return starlark.String(bv.kindOf(bv.b.GetI(i))), nil
+}
+
+func (bv *boardValue) count(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var kind string
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &kind); err != nil {
+		return nil, err
+	}
+	s, err := bv.stateOf(fn.Name(), kind)
+	if err != nil {
+		return nil, err
+	}
+	return starlark.MakeInt(bv.b.CountPieces(s)), nil
+}
+
+func (bv *boardValue) cells(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var kind string
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &kind); err != nil {
+		return nil, err
+	}
+	s, err := bv.stateOf(fn.Name(), kind)
+	if err != nil {
+		return nil, err
+	}
+	var out []starlark.Value
+	for i, c := range game.CoordOf {
+		if bv.b.GetI(i) == s {
+			out = append(out, coordTuple(c))
+		}
+	}
+	return starlark.NewList(out), nil
+}
+
+func (bv *boardValue) moves(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	side := kindMine
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "side?", &side); err != nil {
+		return nil, err
+	}
+	player := bv.me
+	switch side {
+	case kindMine:
+	case kindTheirs:
+		player = game.Opponent(bv.me)
+	default:
+		return nil, fmt.Errorf("%s: side 应为 mine 或 theirs，实为 %q", fn.Name(), side)
+	}
+	var out []starlark.Value
+	for _, mv := range game.GenerateMoves(bv.b, player) {
+		out = append(out, newMoveValue(bv.b, player, mv))
+	}
+	return starlark.NewList(out), nil
+}
+
+func (bv *boardValue) play(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var mv *moveValue
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &mv); err != nil {
+		return nil, err
+	}
+	nb := bv.b.Clone()
+	if _, err := mv.mv.Apply(nb, mv.side); err != nil {
+		return nil, fmt.Errorf("%s(%s): %v", fn.Name(), mv, err)
+	}
+	return newBoardValue(nb, bv.me), nil
+}
+
+func coordTuple(c game.HexCoord) starlark.Tuple {
+	return starlark.Tuple{starlark.MakeInt(c.Q), starlark.MakeInt(c.R)}
+}
+
+// moveValue 脚本里的 move；gain 在生成时按当时的局面算好
+type moveValue struct {
+	mv   game.Move
+	side game.CellState
+	gain int
+}
+
+func newMoveValue(b *game.Board, side game.CellState, mv game.Move) *moveValue {
+	gain, _ := mv.ApplyPreview(b, side)
+	return &moveValue{mv: mv, side: side, gain: gain}
+}
+
+func (m *moveValue) String() string {
+	return game.FormatCoord(m.mv.From, game.CoordAxial) + ">" + game.FormatCoord(m.mv.To, game.CoordAxial)
+}
+func (m *moveValue) Type() string         { return "move" }
+func (m *moveValue) Freeze()              {}
+func (m *moveValue) Truth() starlark.Bool { return starlark.True }
+
+func (m *moveValue) Hash() (uint32, error) {
+	f, t := m.mv.From, m.mv.To
+	return uint32(f.Q+8)<<24 | uint32(f.R+8)<<16 | uint32(t.Q+8)<<8 | uint32(t.R+8) ^ uint32(m.side)<<30, nil
+}
+
+func (m *moveValue) CompareSameType(op syntax.Token, y starlark.Value, depth int) (bool, error) {
+	o := y.(*moveValue)
+	eq := m.mv == o.mv && m.side == o.side
+	switch op {
+	case syntax.EQL:
+		return eq, nil
+	case syntax.NEQ:
+		return !eq, nil
+	}
+	return false, fmt.Errorf("%s %s %s not implemented", m.Type(), op, y.Type())
+}
+
+var moveAttrs = []string{"clone", "dist", "dst", "gain", "jump", "side", "src"}
+
+func (m *moveValue) AttrNames() []string { return moveAttrs }
+
+func (m *moveValue) Attr(name string) (starlark.Value, error) {
+	switch name {
+	case "src":
+		return coordTuple(m.mv.From), nil
+	case "dst":
+		return coordTuple(m.mv.To), nil
+	case "clone":
+		return starlark.Bool(m.mv.IsClone()), nil
+	case "jump":
+		return starlark.Bool(m.mv.IsJump()), nil
+	case "gain":
+		return starlark.MakeInt(m.gain), nil
+	case "dist":
+		t := m.mv.To
+		return starlark.MakeInt(max(abs(t.Q), abs(t.R), abs(t.Q+t.R))), nil
+	case "side":
+		return starlark.String(sideName(m.side)), nil
+	}
+	return nil, nil
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
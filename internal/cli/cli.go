@@ -0,0 +1,179 @@
+// Package cli 各个 cmd 工具共用的命令行参数与 YAML 参数文件。
+//
+// 棋盘半径、搜索深度、随机种子、输出路径、规则变体、并发数这几项几乎每个工具都有，
+// 统一由 Common 按同一个名字（-radius、-depth、-seed、-out、-rules、-workers）注册，
+// 各工具只给自己的默认值。所有工具都用 Parse 代替 flag.Parse，多出一个 -flagfile：
+//
+//	# exp1.yaml：顶层对所有工具生效，工具名下的只对该工具生效（覆盖顶层）
+//	radius: 4
+//	depth: 3
+//	seed: 42
+//	selfplay:
+//	  workers: 8
+//	  out: runs/exp1/selfplay
+//	analyze:
+//	  out: runs/exp1/analysis
+//
+//	selfplay -flagfile exp1.yaml -games 200
+//
+// 同一份文件可以原样交给不同工具，实验配置不用逐个工具改写。优先级：命令行 > 工具名小节 > 顶层 > 默认值。
+// 顶层里本工具没有的参数直接跳过；工具名小节里写了本工具没有的参数则报错（多半是拼错了）。
+// 键名就是参数名，'-' 与 '_' 可以互换（历史上两种写法都有）。
+// 没给 -flagfile 时读环境变量 HEXXAGON_CONFIG；文件语法只支持 YAML 的子集，见 ParseConfig。
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"hexxagon_go/internal/game"
+)
+
+// FileFlag 指定参数文件的命令行参数
+const FileFlag = "flagfile"
+
+// EnvFile 没给 -flagfile 时读这个环境变量
+const EnvFile = "HEXXAGON_CONFIG"
+
+// Flag Common 里的一项，可以按位或组合
+type Flag uint
+
+const (
+	Radius Flag = 1 << iota
+	Depth
+	Seed
+	Out
+	Rules
+	Workers
+)
+
+// Common 各工具共用的参数；注册前先把字段设成本工具的默认值
+type Common struct {
+	Radius  int
+	Depth   int
+	Seed    int64
+	Out     string // 输出文件或目录，含义由工具定
+	Rules   string // 规则变体，见 game.ParseRules
+	Workers int    // 并发数；0 = 工具自己定（通常按 CPU 数）
+}
+
+// Register 把 which 里的各项注册到 fs，默认值取 c 当前的字段值
+func (c *Common) Register(fs *flag.FlagSet, which Flag) {
+	if which&Radius != 0 {
+		fs.IntVar(&c.Radius, "radius", c.Radius, fmt.Sprintf("棋盘半径 1..%d（大于 %d 时神经网络输入装不下，改用静态评估）", game.MaxBoardRadius, game.DefaultBoardRadius))
+	}
+	if which&Depth != 0 {
+		fs.IntVar(&c.Depth, "depth", c.Depth, "α-β 搜索深度")
+	}
+	if which&Seed != 0 {
+		fs.Int64Var(&c.Seed, "seed", c.Seed, "随机种子")
+	}
+	if which&Out != 0 {
+		fs.StringVar(&c.Out, "out", c.Out, "输出路径")
+	}
+	if which&Rules != 0 {
+		if c.Rules == "" {
+			c.Rules = "standard"
+		}
+		fs.StringVar(&c.Rules, "rules", c.Rules, fmt.Sprintf("规则变体（%s）", strings.Join(game.RuleNames, "/")))
+	}
+	if which&Workers != 0 {
+		fs.IntVar(&c.Workers, "workers", c.Workers, "并发数（0=按 CPU 数自动）")
+	}
+}
+
+// ApplyRules 解析 -rules 并设为全局规则
+func (c *Common) ApplyRules() error {
+	rules, err := game.ParseRules(c.Rules)
+	if err != nil {
+		return err
+	}
+	game.SetRules(rules)
+	return nil
+}
+
+// Parse 代替 flag.Parse：解析命令行，再按参数文件补上命令行没给的参数；tool 是参数文件里本工具的小节名。
+// 出错时打印并以状态 2 退出，与 flag.ExitOnError 一致
+func Parse(tool string) {
+	if err := ParseFlags(flag.CommandLine, tool, os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+}
+
+// ParseFlags 在 fs 上注册 -flagfile 并解析 args，然后应用参数文件
+func ParseFlags(fs *flag.FlagSet, tool string, args []string) error {
+	path := fs.String(FileFlag, os.Getenv(EnvFile), "YAML 参数文件：顶层对所有工具生效，工具名小节只对该工具；命令行优先（默认取环境变量 "+EnvFile+"）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(*path)
+	if err != nil {
+		return err
+	}
+	cfg, err := ParseConfig(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *path, err)
+	}
+	if err := cfg.Apply(fs, tool); err != nil {
+		return fmt.Errorf("%s: %w", *path, err)
+	}
+	return nil
+}
+
+// Apply 把 cfg 里对 tool 生效的值设到 fs 上；命令行上已显式给过的参数不动
+func (cfg *Config) Apply(fs *flag.FlagSet, tool string) error {
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	type pending struct {
+		e     Entry
+		local bool
+	}
+	vals := map[string]pending{}
+	for _, e := range cfg.Global {
+		if f := lookup(fs, e.Key); f != nil {
+			vals[f.Name] = pending{e, false}
+		}
+	}
+	for _, e := range cfg.Sections[tool] {
+		f := lookup(fs, e.Key)
+		if f == nil {
+			return fmt.Errorf("line %d: %s has no flag -%s", e.Line, tool, e.Key)
+		}
+		vals[f.Name] = pending{e, true}
+	}
+
+	names := make([]string, 0, len(vals))
+	for name := range vals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if explicit[name] || name == FileFlag {
+			continue
+		}
+		p := vals[name]
+		if err := fs.Set(name, p.e.Value); err != nil {
+			return fmt.Errorf("line %d: -%s: %w", p.e.Line, name, err)
+		}
+	}
+	return nil
+}
+
+// lookup 按参数名找 flag，'-' 与 '_' 互换后再找一次
+func lookup(fs *flag.FlagSet, key string) *flag.Flag {
+	if f := fs.Lookup(key); f != nil {
+		return f
+	}
+	if f := fs.Lookup(strings.ReplaceAll(key, "_", "-")); f != nil {
+		return f
+	}
+	return fs.Lookup(strings.ReplaceAll(key, "-", "_"))
+}
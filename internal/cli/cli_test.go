@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sample = `# 实验 1
+radius: 4
+depth: 3   # 顶层对所有工具
+seed: 42
+budget: 500ms
+selfplay:
+  workers: 8
+  out: "runs/exp1 selfplay"  # 引号里的空格保留
+analyze:
+  out: 'runs/it''s'
+`
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig([]byte(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(cfg.Global); got != 4 {
+		t.Fatalf("顶层 %d 项", got)
+	}
+	if e := cfg.Global[1]; e.Key != "depth" || e.Value != "3" || e.Line != 3 {
+		t.Fatalf("depth = %+v", e)
+	}
+	if e := cfg.Sections["selfplay"][1]; e.Value != "runs/exp1 selfplay" {
+		t.Fatalf("双引号: %+v", e)
+	}
+	if e := cfg.Sections["analyze"][0]; e.Value != "runs/it's" {
+		t.Fatalf("单引号: %+v", e)
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	for _, src := range []string{
+		"radius 4",
+		"radius:4",
+		"radius: 4\nradius: 5",
+		"  radius: 4",
+		"selfplay:\n\tworkers: 8",
+		"selfplay:\n  workers: 8\n    out: x",
+		"selfplay:\n  mcts:\n    sims: 100",
+		"engines: [a, b]",
+		"engines:\n  - a",
+		`out: "abc`,
+		`out: "abc" def`,
+	} {
+		if _, err := ParseConfig([]byte(src)); err == nil {
+			t.Errorf("%q 应报错", src)
+		}
+	}
+}
+
+// 命令行 > 工具小节 > 顶层 > 默认值；顶层里本工具没有的参数跳过
+func TestApplyPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "exp.yaml")
+	if err := os.WriteFile(path, []byte(sample), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fs := flag.NewFlagSet("selfplay", flag.ContinueOnError)
+	c := Common{Radius: 3, Depth: 1, Seed: 7, Out: "selfplay_out"}
+	c.Register(fs, Radius|Depth|Seed|Out|Workers)
+	games := fs.Int("games", 10, "")
+	if err := ParseFlags(fs, "selfplay", []string{"-flagfile", path, "-seed", "9"}); err != nil {
+		t.Fatal(err)
+	}
+	want := Common{Radius: 4, Depth: 3, Seed: 9, Out: "runs/exp1 selfplay", Workers: 8}
+	if c != want || *games != 10 {
+		t.Fatalf("got %+v games=%d, want %+v", c, *games, want)
+	}
+}
+
+// 小节里写了本工具没有的参数报错；'-' 与 '_' 互换；值不合法时报出行号
+func TestApplySection(t *testing.T) {
+	cfg, err := ParseConfig([]byte("tool:\n  move_timeout: 2s\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := flag.NewFlagSet("tool", flag.ContinueOnError)
+	d := fs.Duration("move-timeout", time.Second, "")
+	if err := cfg.Apply(fs, "tool"); err != nil || *d != 2*time.Second {
+		t.Fatalf("move_timeout → -move-timeout: %v %v", *d, err)
+	}
+	if err := cfg.Apply(flag.NewFlagSet("tool", flag.ContinueOnError), "tool"); err == nil {
+		t.Fatal("小节里没有对应参数应报错")
+	}
+
+	cfg, _ = ParseConfig([]byte("depth: deep\n"))
+	fs = flag.NewFlagSet("x", flag.ContinueOnError)
+	new(Common).Register(fs, Depth)
+	if err := cfg.Apply(fs, "x"); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Fatalf("非法值应带行号报错: %v", err)
+	}
+}
+
+func TestEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "exp.yaml")
+	os.WriteFile(path, []byte("rules: cascade\n"), 0644)
+	t.Setenv(EnvFile, path)
+	fs := flag.NewFlagSet("engine", flag.ContinueOnError)
+	var c Common
+	c.Register(fs, Rules)
+	if err := ParseFlags(fs, "engine", nil); err != nil || c.Rules != "cascade" {
+		t.Fatalf("rules=%q err=%v", c.Rules, err)
+	}
+}
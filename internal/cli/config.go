@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Config 解析后的参数文件
+type Config struct {
+	Global   []Entry            // 顶层的键值，对所有工具生效
+	Sections map[string][]Entry // 工具名 → 只对该工具生效的键值
+}
+
+// Entry 一个键值；Line 是它在文件里的行号，报错用
+type Entry struct {
+	Key   string
+	Value string
+	Line  int
+}
+
+// ParseConfig 解析参数文件。只支持 YAML 的一个子集，够写命令行参数用：
+//
+//	key: value          标量：数字、布尔、时长（500ms）、裸字符串，或 "双引号"、'单引号' 字符串
+//	tool:               后面缩进的各行属于名为 tool 的小节（只有一层）
+//	  key: value
+//	# 注释              整行注释；值后面空格加 # 也是注释（引号里的除外）
+//
+// 列表、多行字符串、锚点等不支持，遇到直接报错而不是猜。缩进只能用空格。
+func ParseConfig(data []byte) (*Config, error) {
+	cfg := &Config{Sections: map[string][]Entry{}}
+	section := "" // 当前小节；"" = 顶层
+	indent := -1  // 当前小节的缩进宽度，-1 = 还没见到小节里的第一行
+	for i, raw := range strings.Split(string(data), "\n") {
+		line := i + 1
+		text := strings.TrimRight(raw, " \r")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || (trimmed == "---" && len(trimmed) == len(text)) {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("line %d: indent with spaces, not tabs", line)
+		}
+		depth := len(text) - len(trimmed)
+
+		key, value, hasValue, err := splitEntry(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		switch {
+		case depth == 0:
+			section, indent = "", -1
+			if !hasValue {
+				if _, dup := cfg.Sections[key]; dup {
+					return nil, fmt.Errorf("line %d: section %s repeated", line, key)
+				}
+				cfg.Sections[key] = nil
+				section = key
+				continue
+			}
+			if err := checkDup(cfg.Global, key, line); err != nil {
+				return nil, err
+			}
+			cfg.Global = append(cfg.Global, Entry{key, value, line})
+		case section == "":
+			return nil, fmt.Errorf("line %d: unexpected indent", line)
+		default:
+			if indent < 0 {
+				indent = depth
+			}
+			if depth != indent {
+				return nil, fmt.Errorf("line %d: inconsistent indent in section %s", line, section)
+			}
+			if !hasValue {
+				return nil, fmt.Errorf("line %d: nested sections are not supported", line)
+			}
+			if err := checkDup(cfg.Sections[section], key, line); err != nil {
+				return nil, err
+			}
+			cfg.Sections[section] = append(cfg.Sections[section], Entry{key, value, line})
+		}
+	}
+	return cfg, nil
+}
+
+// splitEntry 拆 "key: value"；hasValue=false 表示 "key:" 后面什么都没有（小节开头）
+func splitEntry(s string) (key, value string, hasValue bool, err error) {
+	if strings.HasPrefix(s, "- ") || s == "-" {
+		return "", "", false, fmt.Errorf("lists are not supported")
+	}
+	colon := strings.Index(s, ":")
+	if colon <= 0 {
+		return "", "", false, fmt.Errorf("expected key: value, got %q", s)
+	}
+	key = strings.TrimSpace(s[:colon])
+	if strings.ContainsAny(key, " \"'#") {
+		return "", "", false, fmt.Errorf("bad key %q", key)
+	}
+	rest := s[colon+1:]
+	if rest != "" && rest[0] != ' ' {
+		return "", "", false, fmt.Errorf("expected a space after %s:", key)
+	}
+	value, err = parseScalar(strings.TrimSpace(rest))
+	if err != nil {
+		return "", "", false, fmt.Errorf("%s: %w", key, err)
+	}
+	hasValue = strings.TrimSpace(stripComment(rest)) != ""
+	return key, value, hasValue, nil
+}
+
+// parseScalar 解析一个标量值（已去掉首尾空格）
+func parseScalar(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		end := closingQuote(s)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string")
+		}
+		if err := onlyComment(s[end+1:]); err != nil {
+			return "", err
+		}
+		return strconv.Unquote(s[:end+1])
+	case strings.HasPrefix(s, "'"):
+		var b strings.Builder
+		for i := 1; i < len(s); i++ {
+			if s[i] != '\'' {
+				b.WriteByte(s[i])
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '\'' { // '' 是单引号本身
+				b.WriteByte('\'')
+				i++
+				continue
+			}
+			if err := onlyComment(s[i+1:]); err != nil {
+				return "", err
+			}
+			return b.String(), nil
+		}
+		return "", fmt.Errorf("unterminated string")
+	case strings.HasPrefix(s, "[") || strings.HasPrefix(s, "{") || strings.HasPrefix(s, "|") ||
+		strings.HasPrefix(s, ">") || strings.HasPrefix(s, "&") || strings.HasPrefix(s, "*"):
+		return "", fmt.Errorf("unsupported YAML syntax %q (quote it to use as a string)", s)
+	}
+	return strings.TrimSpace(stripComment(s)), nil
+}
+
+// closingQuote 双引号字符串结尾的下标（跳过转义）；没有结尾返回 -1
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// onlyComment 引号字符串后面只允许空白和注释
+func onlyComment(rest string) error {
+	if strings.TrimSpace(stripComment(rest)) != "" {
+		return fmt.Errorf("unexpected %q after string", strings.TrimSpace(rest))
+	}
+	return nil
+}
+
+// stripComment 去掉裸值后面的 " #..." 注释
+func stripComment(s string) string {
+	if strings.HasPrefix(s, "#") {
+		return ""
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// checkDup 同一层里一个键只能出现一次
+func checkDup(entries []Entry, key string, line int) error {
+	for _, e := range entries {
+		if e.Key == key {
+			return fmt.Errorf("line %d: %s already set on line %d", line, key, e.Line)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+// Package elo 对局结果的统计：由得分率换算 Elo 差、给出置信区间、多人 Bradley-Terry 拟合，以及 SPRT 提前停止。
+// tournament、arena 等对战工具共用。
+package elo
+
+import "math"
+
+// FromScore 由得分率换算 Elo 差（得分率贴边时截断）
+func FromScore(p float64) float64 {
+	p = math.Min(math.Max(p, 0.001), 0.999)
+	return -400 * math.Log10(1/p-1)
+}
+
+// Estimate 由胜/和/负估计 Elo 差及其 95% 置信区间（按每局得分的样本方差，正态近似）
+func Estimate(w, d, l int) (diff, lo, hi float64) {
+	n := float64(w + d + l)
+	if n == 0 {
+		return 0, math.Inf(-1), math.Inf(1)
+	}
+	x := (float64(w) + 0.5*float64(d)) / n
+	v := (float64(w)*(1-x)*(1-x) + float64(d)*(0.5-x)*(0.5-x) + float64(l)*x*x) / n
+	se := math.Sqrt(v / n)
+	return FromScore(x), FromScore(x - 1.96*se), FromScore(x + 1.96*se)
+}
+
+// BradleyTerry 由两两得分拟合等级分：points[i][j] 为 i 对 j 的总得分，games[i][j] 为局数。
+// 每对下过的参赛者先加一局虚拟和棋，免得全胜/全负时发散；没下过的对不参与。
+// anchor >= 0 时平移到 anchor 为 0，否则平移到平均为 0
+func BradleyTerry(points, games [][]float64, anchor int) []float64 {
+	n := len(points)
+	r := make([]float64, n)
+	for iter := 0; iter < 5000; iter++ {
+		moved := 0.0
+		for i := 0; i < n; i++ {
+			var s, e, g float64
+			for j := 0; j < n; j++ {
+				if i == j || games[i][j] == 0 {
+					continue
+				}
+				gij := games[i][j] + 1
+				s += points[i][j] + 0.5
+				e += gij / (1 + math.Pow(10, (r[j]-r[i])/400))
+				g += gij
+			}
+			if g > 0 {
+				step := 400 * (s - e) / g
+				r[i] += step
+				moved = math.Max(moved, math.Abs(step))
+			}
+		}
+		if moved < 1e-6 {
+			break
+		}
+	}
+	shift := 0.0
+	if anchor >= 0 {
+		shift = r[anchor]
+	} else if n > 0 {
+		for _, v := range r {
+			shift += v
+		}
+		shift /= float64(n)
+	}
+	for i := range r {
+		r[i] -= shift
+	}
+	return r
+}
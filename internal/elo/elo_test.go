@@ -0,0 +1,71 @@
+package elo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFromScore(t *testing.T) {
+	if e := FromScore(0.5); e != 0 {
+		t.Fatalf("50%% 得分应为 0 Elo，得到 %v", e)
+	}
+	if e := FromScore(0.75); math.Abs(e-190.8) > 0.1 {
+		t.Fatalf("75%% 得分约 +191 Elo，得到 %v", e)
+	}
+	if FromScore(1) != FromScore(0.999) {
+		t.Fatal("全胜应截断")
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	diff, lo, hi := Estimate(60, 20, 20)
+	if !(lo < diff && diff < hi) || diff <= 0 {
+		t.Fatalf("diff=%v 区间 [%v, %v]", diff, lo, hi)
+	}
+	// 局数翻四倍，区间约缩到一半
+	_, lo4, hi4 := Estimate(240, 80, 80)
+	if r := (hi4 - lo4) / (hi - lo); r < 0.45 || r > 0.55 {
+		t.Fatalf("区间缩放比 %v", r)
+	}
+}
+
+func TestBradleyTerry(t *testing.T) {
+	// 0 对 1 得 3/4，1 对 2 得 3/4：相邻两人差约 +191 × 虚拟和棋的收缩
+	points := [][]float64{{0, 30, 0}, {10, 0, 30}, {0, 10, 0}}
+	games := [][]float64{{0, 40, 0}, {40, 0, 40}, {0, 40, 0}}
+	r := BradleyTerry(points, games, 2)
+	if r[2] != 0 || !(r[0] > r[1] && r[1] > 0) {
+		t.Fatalf("锚定 2：%v", r)
+	}
+	if math.Abs(r[0]-r[1]-(r[1]-r[2])) > 1 || math.Abs(r[1]-r[2]-185) > 10 {
+		t.Fatalf("相邻差应对称且约 185：%v", r)
+	}
+	m := BradleyTerry(points, games, -1)
+	if sum := m[0] + m[1] + m[2]; math.Abs(sum) > 1e-6 || math.Abs(m[0]-m[2]-(r[0]-r[2])) > 1e-6 {
+		t.Fatalf("不锚定应平均为 0、差不变：%v", m)
+	}
+}
+
+func TestSPRT(t *testing.T) {
+	if s, err := ParseSPRT(""); s != nil || err != nil {
+		t.Fatal("空串应不启用")
+	}
+	for _, bad := range []string{"5", "10,0", "0,x", "0,10,0,0.05", "0,10,0.05,0.5", "0,10,-0.1,0.05", "0,10,0.05,NaN"} {
+		if _, err := ParseSPRT(bad); err == nil {
+			t.Errorf("%q 应报错", bad)
+		}
+	}
+	s, err := ParseSPRT("0,20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, _ := s.Decide(300, 100, 100); d != +1 {
+		t.Fatal("明显更强应接受 H1")
+	}
+	if d, _ := s.Decide(100, 100, 300); d != -1 {
+		t.Fatal("明显更弱应接受 H0")
+	}
+	if d, _ := s.Decide(3, 2, 3); d != 0 {
+		t.Fatal("样本太少应继续")
+	}
+}
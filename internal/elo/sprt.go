@@ -0,0 +1,76 @@
+package elo
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// SPRT 序贯概率比检验：H0 = Elo 差为 Elo0，H1 = Elo 差为 Elo1
+type SPRT struct {
+	Elo0, Elo1  float64
+	Alpha, Beta float64
+}
+
+// ParseSPRT 解析 "elo0,elo1[,alpha,beta]"；空串表示不启用。alpha、beta 须在 (0, 0.5) 内，否则边界为 ±Inf 或倒过来
+func ParseSPRT(s string) (*SPRT, error) {
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 && len(parts) != 4 {
+		return nil, fmt.Errorf("sprt %q: want elo0,elo1[,alpha,beta]", s)
+	}
+	v := make([]float64, len(parts))
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, fmt.Errorf("sprt %q: %w", s, err)
+		}
+		v[i] = f
+	}
+	cfg := &SPRT{Elo0: v[0], Elo1: v[1], Alpha: 0.05, Beta: 0.05}
+	if len(v) == 4 {
+		cfg.Alpha, cfg.Beta = v[2], v[3]
+	}
+	if cfg.Elo1 <= cfg.Elo0 {
+		return nil, fmt.Errorf("sprt %q: elo1 must be greater than elo0", s)
+	}
+	if !(cfg.Alpha > 0 && cfg.Alpha < 0.5) || !(cfg.Beta > 0 && cfg.Beta < 0.5) {
+		return nil, fmt.Errorf("sprt %q: alpha and beta must be in (0, 0.5)", s)
+	}
+	return cfg, nil
+}
+
+// LLR 用正态近似（GSPRT）由胜/和/负计算对数似然比
+func (c *SPRT) LLR(w, d, l int) float64 {
+	if w+d+l == 0 {
+		return 0
+	}
+	wf, df, lf := float64(w), float64(d), float64(l)
+	if w == 0 || l == 0 {
+		// 一边倒（引擎确定性、开局固定时很常见）方差会退化为 0：各补半局做正则化
+		wf, df, lf = wf+0.5, df+0.5, lf+0.5
+	}
+	n := wf + df + lf
+	x := (wf + 0.5*df) / n
+	v := (wf*(1-x)*(1-x) + df*(0.5-x)*(0.5-x) + lf*x*x) / n
+	s0 := 1 / (1 + math.Pow(10, -c.Elo0/400))
+	s1 := 1 / (1 + math.Pow(10, -c.Elo1/400))
+	return n * (s1 - s0) * (2*x - s0 - s1) / (2 * v)
+}
+
+// Decide 返回 +1（接受 H1）、-1（接受 H0）或 0（继续），以及当前的 LLR
+func (c *SPRT) Decide(w, d, l int) (int, float64) {
+	llr := c.LLR(w, d, l)
+	lower := math.Log(c.Beta / (1 - c.Alpha))
+	upper := math.Log((1 - c.Beta) / c.Alpha)
+	switch {
+	case llr >= upper:
+		return +1, llr
+	case llr <= lower:
+		return -1, llr
+	}
+	return 0, llr
+}
@@ -0,0 +1,352 @@
+package engine
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"hexxagon_go/internal/game"
+)
+
+// 原生库（ORT/TensorRT）可能绕过 Go 直接往 stdout 写颜色码，读行时剔除
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// ErrEngineDead：引擎进程已退出（崩溃或被关闭），需要重新 Start
+var ErrEngineDead = errors.New("engine process is not running")
+
+// Client 驱动一个说本协议的子进程。方法可并发调用，内部串行化。
+type Client struct {
+	Name string // 握手时引擎报告的名字
+	// HashFull 为 true 时 go 命令带 hashfull 1，进度回调里的 HashFull 才有值；不认该键的引擎会回 error
+	HashFull bool
+
+	mu     sync.Mutex
+	cfg    Config // Restart 按它重新启动
+	hashMB int    // SetHash 设过的大小，Restart 后重新设；0=引擎默认
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	lines  *bufio.Scanner
+	dead   bool // 管道已断，不能再通信
+	closed bool // 已回收进程
+}
+
+// Config 描述如何启动一个引擎进程
+type Config struct {
+	Path string   `json:"path"`
+	Args []string `json:"args,omitempty"`
+	Dir  string   `json:"dir,omitempty"` // 工作目录（模型、缓存等相对路径以此为准）；空=当前目录
+}
+
+// Start 启动引擎可执行文件并完成握手；引擎的 stderr 透传到本进程 stderr
+func Start(path string, args ...string) (*Client, error) {
+	return StartConfig(Config{Path: path, Args: args})
+}
+
+// StartConfig 同 Start，可指定工作目录
+func StartConfig(cfg Config) (*Client, error) {
+	c := &Client{cfg: cfg}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.start(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// start 起进程并握手；调用方持有 mu
+func (c *Client) start() error {
+	path := c.cfg.Path
+	cmd := exec.Command(path, c.cfg.Args...)
+	cmd.Dir = c.cfg.Dir
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start engine %s: %w", path, err)
+	}
+	c.cmd, c.stdin, c.lines = cmd, stdin, bufio.NewScanner(stdout)
+	c.dead, c.closed = false, false
+
+	if err := c.send("hexx"); err != nil {
+		c.kill()
+		return err
+	}
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			c.kill()
+			return fmt.Errorf("engine %s handshake: %w", path, err)
+		}
+		if name, ok := strings.CutPrefix(line, "id name "); ok {
+			c.Name = name
+		}
+		if line == "hexxok" {
+			return nil
+		}
+	}
+}
+
+// Restart 杀掉当前进程（不管它是否还活着），按原配置重新启动并握手，SetHash 设过的大小一并恢复。
+// 用于引擎崩溃或回错之后：重启失败时 Client 保持 dead，之后的调用返回 ErrEngineDead
+func (c *Client) Restart() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		c.kill()
+	}
+	if err := c.start(); err != nil {
+		return err
+	}
+	if c.hashMB > 0 {
+		return c.setHash(c.hashMB)
+	}
+	return nil
+}
+
+// Search 让引擎为 gs 的执子方搜索到 depth 层；onProgress 可为 nil。
+// ok=false 表示引擎认为无着可走；引擎给出的走法会先经过 game.ValidateMove。
+func (c *Client) Search(gs *game.GameState, depth int, allowJump bool, onProgress func(game.SearchProgress)) (mv game.Move, ok bool, err error) {
+	mv, _, ok, err = c.search(gs, depth, 0, allowJump, false, onProgress)
+	return mv, ok, err
+}
+
+// SearchBudget 同 Search，budget 非 0 时 go 命令带 movetime：迭代加深到 depth 层或到点为止。
+// 不认 movetime 的引擎会回 error
+func (c *Client) SearchBudget(gs *game.GameState, depth int, budget time.Duration, allowJump bool, onProgress func(game.SearchProgress)) (mv game.Move, ok bool, err error) {
+	mv, _, ok, err = c.search(gs, depth, budget, allowJump, false, onProgress)
+	return mv, ok, err
+}
+
+// SearchPV 同 Search，另外要引擎回报主变（go ... pv 1）。不认 pv 的引擎会回 error；
+// 认 pv 但没回 info pv 的，pv 退回只有 bestmove 一手
+func (c *Client) SearchPV(gs *game.GameState, depth int, allowJump bool, onProgress func(game.SearchProgress)) (mv game.Move, pv []game.Move, ok bool, err error) {
+	mv, pv, ok, err = c.search(gs, depth, 0, allowJump, true, onProgress)
+	if ok && len(pv) == 0 {
+		pv = []game.Move{mv}
+	}
+	return mv, pv, ok, err
+}
+
+func (c *Client) search(gs *game.GameState, depth int, budget time.Duration, allowJump, wantPV bool, onProgress func(game.SearchProgress)) (mv game.Move, pv []game.Move, ok bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.dead {
+		return game.Move{}, nil, false, ErrEngineDead
+	}
+
+	jump := 0
+	if allowJump {
+		jump = 1
+	}
+	if err := c.send("position " + gs.PositionString()); err != nil {
+		return game.Move{}, nil, false, err
+	}
+	goCmd := fmt.Sprintf("go depth %d jump %d", depth, jump)
+	if wantPV {
+		goCmd += " pv 1"
+	}
+	if c.HashFull {
+		goCmd += " hashfull 1"
+	}
+	if budget > 0 {
+		goCmd += fmt.Sprintf(" movetime %d", max(1, budget.Milliseconds()))
+	}
+	if err := c.send(goCmd); err != nil {
+		return game.Move{}, nil, false, err
+	}
+
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return game.Move{}, nil, false, err
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "info":
+			if len(fields) > 1 && fields[1] == "pv" {
+				if pv, err = parsePV(fields[2:]); err != nil {
+					return game.Move{}, nil, false, fmt.Errorf("engine: %w", err)
+				}
+				continue
+			}
+			if p, perr := parseInfo(fields[1:]); perr == nil && onProgress != nil {
+				onProgress(p)
+			}
+		case "bestmove":
+			if len(fields) != 2 {
+				return game.Move{}, nil, false, fmt.Errorf("engine: malformed %q", line)
+			}
+			if fields[1] == "none" {
+				return game.Move{}, nil, false, nil
+			}
+			mv, err := ParseMove(fields[1])
+			if err != nil {
+				return game.Move{}, nil, false, fmt.Errorf("engine: %w", err)
+			}
+			if err := game.ValidateMove(gs, mv); err != nil {
+				return game.Move{}, nil, false, fmt.Errorf("engine returned illegal move %s: %w", fields[1], err)
+			}
+			return mv, pv, true, nil
+		case "error":
+			return game.Move{}, nil, false, fmt.Errorf("engine: %s", strings.TrimPrefix(line, "error "))
+		}
+	}
+}
+
+// SetHash 让引擎把置换表换成 mb MB（hash 命令），再用 isready 确认引擎接受了
+func (c *Client) SetHash(mb int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.setHash(mb); err != nil {
+		return err
+	}
+	c.hashMB = mb
+	return nil
+}
+
+func (c *Client) setHash(mb int) error {
+	if c.dead {
+		return ErrEngineDead
+	}
+	if err := c.send(fmt.Sprintf("hash %d", mb)); err != nil {
+		return err
+	}
+	if err := c.send("isready"); err != nil {
+		return err
+	}
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return err
+		}
+		if msg, ok := strings.CutPrefix(line, "error "); ok {
+			return fmt.Errorf("engine: %s", msg)
+		}
+		if line == "readyok" {
+			return nil
+		}
+	}
+}
+
+// parsePV 解析 info pv 后面的走法；只查格式，主变后面几手的合法性调用方需要时自己验
+func parsePV(args []string) ([]game.Move, error) {
+	pv := make([]game.Move, len(args))
+	for i, s := range args {
+		mv, err := ParseMove(s)
+		if err != nil {
+			return nil, fmt.Errorf("pv: %w", err)
+		}
+		pv[i] = mv
+	}
+	return pv, nil
+}
+
+// Close 请求引擎退出，超时则强杀；引擎已崩溃时只负责回收进程
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	if !c.dead {
+		_ = c.send("quit")
+	}
+	c.dead = true
+	c.stdin.Close()
+
+	done := make(chan error, 1)
+	go func() { done <- c.cmd.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(2 * time.Second):
+		c.cmd.Process.Kill()
+		return <-done
+	}
+}
+
+// Kill 立即杀掉引擎进程，不等待锁：用于超时时打断正在进行的 Search（它会返回 ErrEngineDead）
+func (c *Client) Kill() {
+	c.cmd.Process.Kill()
+}
+
+func (c *Client) send(line string) error {
+	if _, err := io.WriteString(c.stdin, line+"\n"); err != nil {
+		c.dead = true
+		return fmt.Errorf("%w: %v", ErrEngineDead, err)
+	}
+	return nil
+}
+
+func (c *Client) readLine() (string, error) {
+	if c.lines.Scan() {
+		return strings.TrimSpace(ansiEscape.ReplaceAllString(c.lines.Text(), "")), nil
+	}
+	c.dead = true
+	if err := c.lines.Err(); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrEngineDead, err)
+	}
+	return "", ErrEngineDead
+}
+
+func (c *Client) kill() {
+	c.dead = true
+	c.closed = true
+	c.cmd.Process.Kill()
+	c.cmd.Wait()
+}
+
+// parseInfo 解析 "depth <d> move <走法> time <毫秒> [hashfull <千分比>]"
+func parseInfo(args []string) (game.SearchProgress, error) {
+	var p game.SearchProgress
+	if len(args) == 0 || args[0] != "depth" {
+		return p, fmt.Errorf("not a depth info") // info verify 等统计行
+	}
+	for i := 0; i+1 < len(args); i += 2 {
+		switch args[i] {
+		case "depth":
+			d, err := strconv.Atoi(args[i+1])
+			if err != nil {
+				return p, err
+			}
+			p.Depth = d
+		case "move":
+			mv, err := ParseMove(args[i+1])
+			if err != nil {
+				return p, err
+			}
+			p.Best = mv
+		case "time":
+			ms, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil {
+				return p, err
+			}
+			p.Elapsed = time.Duration(ms) * time.Millisecond
+		case "hashfull":
+			h, err := strconv.Atoi(args[i+1])
+			if err != nil {
+				return p, err
+			}
+			p.HashFull = h
+		}
+	}
+	return p, nil
+}
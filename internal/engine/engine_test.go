@@ -0,0 +1,202 @@
+package engine
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"hexxagon_go/internal/game"
+)
+
+func TestMoveRoundTrip(t *testing.T) {
+	m := game.Move{From: game.HexCoord{Q: -4, R: 0}, To: game.HexCoord{Q: -2, R: 1}}
+	got, err := ParseMove(FormatMove(m))
+	if err != nil || got != m {
+		t.Fatalf("往返失败: %v %v", got, err)
+	}
+	for _, bad := range []string{"", "1,2", "1,2>3", "a,b>1,2"} {
+		if _, err := ParseMove(bad); err == nil {
+			t.Fatalf("%q 应解析失败", bad)
+		}
+	}
+}
+
+func TestMoveCoordSystems(t *testing.T) {
+	m := game.Move{From: game.HexCoord{Q: -3, R: 1}, To: game.HexCoord{Q: -1, R: 0}}
+	for _, sys := range []game.CoordSystem{game.CoordAxial, game.CoordCube, game.CoordOffset} {
+		got, err := ParseMoveIn(FormatMoveIn(m, sys), sys)
+		if err != nil || got != m {
+			t.Fatalf("%v 往返失败: %v %v", sys, got, err)
+		}
+	}
+	// 立方坐标不用切换坐标系也能读
+	if got, err := ParseMove(FormatMoveIn(m, game.CoordCube)); err != nil || got != m {
+		t.Fatalf("立方坐标: %v %v", got, err)
+	}
+}
+
+func TestServeCoordsAndPositionMoves(t *testing.T) {
+	st := game.NewGameState(4)
+	mv := game.GenerateMoves(st.Board, game.PlayerA)[0]
+	in := strings.NewReader("coords offset\nposition " + st.PositionString() + " moves " + FormatMoveIn(mv, game.CoordOffset) +
+		"\ngo depth 1 jump 0\ncoords hex\nposition " + st.PositionString() + " moves 9,9>9,8\nquit\n")
+	var out strings.Builder
+	if err := ServeBot(in, &out, game.BotGreedy); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "bestmove ") ||
+		!strings.HasPrefix(lines[1], "error coords") || !strings.HasPrefix(lines[2], "error position") {
+		t.Fatalf("输出不对: %q", lines)
+	}
+	// 应着是白方的，按 offset 输出
+	if _, _, err := st.MakeMove(mv); err != nil {
+		t.Fatal(err)
+	}
+	best, err := ParseMoveIn(strings.TrimPrefix(lines[0], "bestmove "), game.CoordOffset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := game.ValidateMove(st, best); err != nil {
+		t.Fatalf("bestmove %v 对白方非法: %v", best, err)
+	}
+}
+
+func TestServeSearch(t *testing.T) {
+	game.UseONNXForPlayerA = false
+	st := game.NewGameState(4)
+	in := strings.NewReader("hexx\nposition " + st.PositionString() + "\ngo depth 2 jump 0\nbogus\nquit\n")
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(Serve(in, pw))
+	}()
+
+	var lines []string
+	sc := bufio.NewScanner(pr)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	if len(lines) < 5 || lines[0] != "id name "+Name || lines[1] != "hexxok" {
+		t.Fatalf("握手输出不对: %q", lines)
+	}
+	var best string
+	infos := 0
+	for _, l := range lines[2:] {
+		switch {
+		case strings.HasPrefix(l, "info "):
+			infos++
+		case strings.HasPrefix(l, "bestmove "):
+			best = strings.TrimPrefix(l, "bestmove ")
+		}
+	}
+	if infos != 2 {
+		t.Fatalf("depth 2 应有 2 条 info，得到 %d: %q", infos, lines)
+	}
+	mv, err := ParseMove(best)
+	if err != nil {
+		t.Fatalf("bestmove 无法解析: %q", lines)
+	}
+	if err := game.ValidateMove(st, mv); err != nil {
+		t.Fatalf("bestmove 非法: %v", err)
+	}
+	if !strings.HasPrefix(lines[len(lines)-1], "error unknown command") {
+		t.Fatalf("未知命令应回 error: %q", lines[len(lines)-1])
+	}
+}
+
+func TestServeSearchVerifyStats(t *testing.T) {
+	game.UseONNXForPlayerA = false
+	prev := game.CurrentOptions()
+	o := prev
+	o.Search.Verify.Mode = game.VerifyABByMCTS
+	o.Search.Verify.MCTSSims = 50
+	if err := game.ApplyOptions(o); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { game.ApplyOptions(prev) })
+
+	st := game.NewGameState(4)
+	var out strings.Builder
+	in := strings.NewReader("position " + st.PositionString() + "\ngo depth 1 jump 0\nquit\n")
+	if err := Serve(in, &out); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "info verify calls 1 ") || !strings.HasPrefix(lines[2], "bestmove ") {
+		t.Fatalf("开着复核应先回 info depth、再回 info verify、最后 bestmove: %q", lines)
+	}
+	if _, err := parseInfo(strings.Fields(lines[1])[1:]); err == nil {
+		t.Fatal("info verify 不应被当成搜索进度")
+	}
+}
+
+func TestServeSearchPV(t *testing.T) {
+	game.UseONNXForPlayerA = false
+	st := game.NewGameState(4)
+	var out strings.Builder
+	in := strings.NewReader("position " + st.PositionString() + "\ngo depth 2 jump 0 pv 1\nquit\n")
+	if err := Serve(in, &out); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[2], "info pv ") || !strings.HasPrefix(lines[3], "bestmove ") {
+		t.Fatalf("pv 1 时 bestmove 之前应有一行 info pv: %q", lines)
+	}
+	pv, err := parsePV(strings.Fields(lines[2])[2:])
+	if err != nil || len(pv) != 2 || FormatMove(pv[0]) != strings.TrimPrefix(lines[3], "bestmove ") {
+		t.Fatalf("主变 %v（%v）应有 2 手且第一手是 bestmove: %q", pv, err, lines)
+	}
+}
+
+func TestServeHashAndHashFull(t *testing.T) {
+	game.UseONNXForPlayerA = false
+	prev := game.TTSizeMB()
+	t.Cleanup(func() { game.ResizeTT(prev) })
+
+	st := game.NewGameState(4)
+	var out strings.Builder
+	in := strings.NewReader("hash 0\nhash 2\nposition " + st.PositionString() + "\ngo depth 2 jump 0 hashfull 1\nquit\n")
+	if err := Serve(in, &out); err != nil {
+		t.Fatal(err)
+	}
+	if game.TTSizeMB() != 2 {
+		t.Fatalf("hash 2 之后置换表 %dMB", game.TTSizeMB())
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "error hash") {
+		t.Fatalf("hash 0 应回 error，其余 hash 不回复: %q", lines)
+	}
+	p, err := parseInfo(strings.Fields(lines[2])[1:])
+	if err != nil || p.Depth != 2 || p.HashFull != game.HashFull() || !strings.Contains(lines[2], " hashfull ") {
+		t.Fatalf("hashfull 1 时 info depth 行应带占用率: %q (%+v, %v)", lines[2], p, err)
+	}
+}
+
+func TestServeSearchMoveTime(t *testing.T) {
+	game.UseONNXForPlayerA = false
+	st := game.NewGameState(4)
+	var out strings.Builder
+	in := strings.NewReader("position " + st.PositionString() + "\ngo depth 60 jump 0 movetime -1\ngo depth 60 jump 0 pv 1 movetime 200\nquit\n")
+	start := time.Now()
+	if err := Serve(in, &out); err != nil {
+		t.Fatal(err)
+	}
+	if took := time.Since(start); took > 10*time.Second {
+		t.Fatalf("movetime 200 搜了 %v", took)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	n := len(lines)
+	if n < 4 || !strings.HasPrefix(lines[0], "error go") || !strings.HasPrefix(lines[n-1], "bestmove ") || !strings.HasPrefix(lines[n-2], "info pv ") {
+		t.Fatalf("输出不对: %q", lines)
+	}
+	last, err := parseInfo(strings.Fields(lines[n-3])[1:])
+	if err != nil || last.Depth >= 60 {
+		t.Fatalf("到点应停在 60 层之前: %q (%v)", lines[n-3], err)
+	}
+	// 主变只到最后搜完的那一层
+	if pv, err := parsePV(strings.Fields(lines[n-2])[2:]); err != nil || len(pv) > last.Depth {
+		t.Fatalf("主变 %v（%v）超过了搜完的 %d 层", pv, err, last.Depth)
+	}
+}
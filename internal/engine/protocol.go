@@ -0,0 +1,62 @@
+// Package engine 定义 UI / 对战工具与独立引擎进程之间的文本协议。
+//
+// 每行一条命令，字段以空格分隔，引擎从 stdin 读、往 stdout 写：
+//
+//	hexx                          握手；引擎回 "id name <名字>"，最后回 "hexxok"
+//	position <pos> [moves <走法>...]
+//	                              设置局面，<pos> 为 GameState.PositionString（含执子方）；
+//	                              带 moves 时再按顺序走这些着法（按当前 coords 读，逐步校验）
+//	go depth <n> jump <0|1> [pv <0|1>] [hashfull <0|1>] [movetime <毫秒>]
+//	                              迭代加深到 n 层；带 movetime 时到点也停，用最后一层搜完的结果
+//	                              （见 game.FindBestMoveWithBudgetDepth）；每完成一层回
+//	                                "info depth <d> move <走法> time <毫秒>"
+//	                              hashfull 1 时该行末尾再加 "hashfull <千分比>"（置换表占用率，见 game.HashFull）
+//	                              开着 α-β/MCTS 复核时再回一行累计分歧统计
+//	                                "info verify calls <n> agree <n> disagree <n> ..."（见 game.VerifyStats）
+//	                              pv 1 时在 bestmove 之前回一行主变（第一手即 bestmove，见 game.PrincipalVariation）
+//	                                "info pv <走法> <走法>..."
+//	                              结束时回 "bestmove <走法>" 或 "bestmove none"
+//	coords <axial|cube|offset>    之后的走法（info / bestmove）按该坐标系输出，默认 axial
+//	hash <MB>                     把置换表换成 MB 大小（按 2 的幂向下取整，见 game.ResizeTT），清空原有内容；不回复
+//	isready                       回 "readyok"
+//	quit                          退出
+//
+// 走法写作 "q,r>q,r"（起点>终点，轴坐标）；coords 切换后按 game.FormatCoord 的格式，
+// 三个分量的立方坐标 "x,y,z>x,y,z" 在任何坐标系下都能读。无法识别的命令回 "error <原因>"，不中断会话。
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"hexxagon_go/internal/game"
+)
+
+// FormatMove 把走法写成协议格式（轴坐标）
+func FormatMove(m game.Move) string { return FormatMoveIn(m, game.CoordAxial) }
+
+// FormatMoveIn 按坐标系 sys 写出走法
+func FormatMoveIn(m game.Move, sys game.CoordSystem) string {
+	return game.FormatCoord(m.From, sys) + ">" + game.FormatCoord(m.To, sys)
+}
+
+// ParseMove 解析 FormatMove 的输出；只检查格式，合法性由调用方用 game.ValidateMove 判断。
+// 三个分量的坐标按立方坐标解释，所以 FormatMoveIn(m, game.CoordCube) 的输出也能直接解析
+func ParseMove(s string) (game.Move, error) { return ParseMoveIn(s, game.CoordAxial) }
+
+// ParseMoveIn 按坐标系 sys 解析走法，换成轴坐标
+func ParseMoveIn(s string, sys game.CoordSystem) (game.Move, error) {
+	from, to, ok := strings.Cut(s, ">")
+	if !ok {
+		return game.Move{}, fmt.Errorf("move %q: missing '>'", s)
+	}
+	f, err := game.ParseCoord(from, sys)
+	if err != nil {
+		return game.Move{}, fmt.Errorf("move %q: %w", s, err)
+	}
+	t, err := game.ParseCoord(to, sys)
+	if err != nil {
+		return game.Move{}, fmt.Errorf("move %q: %w", s, err)
+	}
+	return game.Move{From: f, To: t}, nil
+}
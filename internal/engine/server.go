@@ -0,0 +1,255 @@
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"hexxagon_go/internal/game"
+)
+
+// Name 握手时报告的引擎名
+const Name = "hexxagon_go"
+
+// Serve 在 r/w 上运行协议循环，直到读到 quit 或 r 结束。搜索同步执行，期间不读新命令。
+func Serve(r io.Reader, w io.Writer) error {
+	return ServeBot(r, w, "")
+}
+
+// ServeBot 与 Serve 相同，但 bot 非空时用内置基线对手（game.BaselineMove）应着，go 的 depth 被忽略
+func ServeBot(r io.Reader, w io.Writer, bot string) error {
+	if bot != "" && !game.IsBaselineBot(bot) {
+		return fmt.Errorf("unknown bot %q", bot)
+	}
+	out := bufio.NewWriter(w)
+	reply := func(format string, args ...any) error {
+		if _, err := fmt.Fprintf(out, format+"\n", args...); err != nil {
+			return err
+		}
+		return out.Flush()
+	}
+
+	st := game.NewGameState(4)
+	sys := game.CoordAxial
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		var err error
+		switch fields[0] {
+		case "hexx":
+			if err = reply("id name %s", Name); err == nil {
+				err = reply("hexxok")
+			}
+		case "coords":
+			if len(fields) != 2 {
+				err = reply("error coords: want 1 argument")
+				break
+			}
+			s, perr := game.ParseCoordSystem(fields[1])
+			if perr != nil {
+				err = reply("error coords: %v", perr)
+				break
+			}
+			sys = s
+		case "hash":
+			mb, perr := parseHash(fields[1:])
+			if perr != nil {
+				err = reply("error hash: %v", perr)
+				break
+			}
+			game.ResizeTT(mb)
+		case "isready":
+			err = reply("readyok")
+		case "position":
+			gs, perr := parsePosition(fields[1:], sys)
+			if perr != nil {
+				err = reply("error position: %v", perr)
+				break
+			}
+			st = gs
+		case "go":
+			g, perr := parseGo(fields[1:])
+			if perr != nil {
+				err = reply("error go: %v", perr)
+				break
+			}
+			if bot != "" {
+				err = serveBot(st, bot, g.allowJump, g.pv, sys, reply)
+				break
+			}
+			err = serveSearch(st, g, sys, reply)
+		case "quit":
+			return nil
+		default:
+			err = reply("error unknown command %q", fields[0])
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+func serveSearch(st *game.GameState, g goArgs, sys game.CoordSystem, reply func(string, ...any) error) error {
+	var werr error
+	onDepth := func(p game.SearchProgress) {
+		if werr != nil {
+			return
+		}
+		line := fmt.Sprintf("info depth %d move %s time %d", p.Depth, FormatMoveIn(p.Best, sys), p.Elapsed.Milliseconds())
+		if g.hashFull {
+			line += fmt.Sprintf(" hashfull %d", p.HashFull)
+		}
+		werr = reply("%s", line)
+	}
+	var mv game.Move
+	var ok bool
+	pvDepth := g.depth
+	if g.moveTime > 0 {
+		// 按时间停的话主变只取到搜完的那一层
+		pvDepth = 1
+		timed := onDepth
+		onDepth = func(p game.SearchProgress) {
+			pvDepth = p.Depth
+			timed(p)
+		}
+		mv, ok = game.FindBestMoveWithBudgetDepth(st.Board, st.CurrentPlayer, g.depth, g.moveTime, g.allowJump, onDepth)
+	} else {
+		mv, _, ok = game.IterativeDeepeningProgress(st.Board, st.CurrentPlayer, g.depth, g.allowJump, onDepth)
+	}
+	if werr != nil {
+		return werr
+	}
+	if game.VerifyEnabled() {
+		if err := reply("info verify %s", game.GetVerifyStats()); err != nil {
+			return err
+		}
+	}
+	if !ok || st.GameOver {
+		return reply("bestmove none")
+	}
+	if g.pv {
+		if err := replyPV(game.PrincipalVariation(st.Board, st.CurrentPlayer, mv, pvDepth, g.allowJump), sys, reply); err != nil {
+			return err
+		}
+	}
+	return reply("bestmove %s", FormatMoveIn(mv, sys))
+}
+
+func serveBot(st *game.GameState, bot string, allowJump, pv bool, sys game.CoordSystem, reply func(string, ...any) error) error {
+	mv, ok, err := game.BaselineMove(bot, st.Board, st.CurrentPlayer, allowJump, nil)
+	if err != nil {
+		return reply("error go: %v", err)
+	}
+	if !ok || st.GameOver {
+		return reply("bestmove none")
+	}
+	if pv {
+		// 基线对手不往下看，主变只有这一手
+		if err := replyPV([]game.Move{mv}, sys, reply); err != nil {
+			return err
+		}
+	}
+	return reply("bestmove %s", FormatMoveIn(mv, sys))
+}
+
+// replyPV 回 "info pv <走法>..."
+func replyPV(pv []game.Move, sys game.CoordSystem, reply func(string, ...any) error) error {
+	moves := make([]string, len(pv))
+	for i, m := range pv {
+		moves[i] = FormatMoveIn(m, sys)
+	}
+	return reply("info pv %s", strings.Join(moves, " "))
+}
+
+// parsePosition 解析 "<pos> [moves <走法>...]"；走法按当前坐标系读，逐步校验后走在 pos 上
+func parsePosition(args []string, sys game.CoordSystem) (*game.GameState, error) {
+	if len(args) == 0 || len(args) == 2 || len(args) > 2 && args[1] != "moves" {
+		return nil, fmt.Errorf("want <pos> [moves <move>...]")
+	}
+	gs, err := game.ParsePosition(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(args) > 2 {
+		for i, s := range args[2:] {
+			mv, err := ParseMoveIn(s, sys)
+			if err != nil {
+				return nil, err
+			}
+			if err := game.ValidateMove(gs, mv); err != nil {
+				return nil, fmt.Errorf("move %d %s: %w", i, s, err)
+			}
+			if _, _, err := gs.MakeMove(mv); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return gs, nil
+}
+
+// parseHash 解析 "hash <MB>"
+func parseHash(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("want 1 argument")
+	}
+	mb, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, err
+	}
+	if mb < 1 {
+		return 0, fmt.Errorf("%d MB < 1", mb)
+	}
+	return mb, nil
+}
+
+// goArgs go 命令的参数
+type goArgs struct {
+	depth     int
+	allowJump bool
+	pv        bool          // 回 bestmove 前先回一行 info pv
+	hashFull  bool          // info depth 行末尾带 hashfull <千分比>
+	moveTime  time.Duration // 非 0 时到点即停
+}
+
+// parseGo 解析 "depth <n> jump <0|1> [pv <0|1>] [hashfull <0|1>] [movetime <毫秒>]"，键值对顺序不限，
+// 缺省 depth=1、jump=1、pv=0、hashfull=0、movetime=0（不限时）
+func parseGo(args []string) (goArgs, error) {
+	g := goArgs{depth: 1, allowJump: true}
+	if len(args)%2 != 0 {
+		return goArgs{}, fmt.Errorf("odd number of arguments")
+	}
+	for i := 0; i < len(args); i += 2 {
+		v, err := strconv.Atoi(args[i+1])
+		if err != nil {
+			return goArgs{}, fmt.Errorf("%s: %w", args[i], err)
+		}
+		switch args[i] {
+		case "depth":
+			if v < 1 {
+				return goArgs{}, fmt.Errorf("depth %d < 1", v)
+			}
+			g.depth = v
+		case "jump":
+			g.allowJump = v != 0
+		case "pv":
+			g.pv = v != 0
+		case "hashfull":
+			g.hashFull = v != 0
+		case "movetime":
+			if v < 0 {
+				return goArgs{}, fmt.Errorf("movetime %d < 0", v)
+			}
+			g.moveTime = time.Duration(v) * time.Millisecond
+		default:
+			return goArgs{}, fmt.Errorf("unknown key %q", args[i])
+		}
+	}
+	return g, nil
+}
@@ -8,6 +8,7 @@ import (
 	"runtime"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -28,12 +29,17 @@ const jumpMovePenalty = 25
 // 用对象池拿一块 Board，然后把当前盘面“整块拷贝”过去。
 // 注意：array 赋值是深拷贝，O(37)；比逐个 map 复制快多了。
 func cloneBoardPool(b *Board) *Board {
-	nb := acquireBoard(b.radius) // 已清空并重置 hash/标记
+	nb := acquireBoard() // 已清空并重置 hash/标记
 	// 直接结构字段拷贝（array 是值拷贝）
 	nb.Cells = b.Cells
+	nb.cut = b.cut
 	nb.hash = b.hash
 	nb.bitA = b.bitA
 	nb.bitB = b.bitB
+	nb.reach = b.reach
+	nb.eval = b.eval
+	nb.lin = b.lin
+	nb.ids = b.ids
 
 	nb.LastMove = b.LastMove
 	nb.LastMover = b.LastMover
@@ -45,11 +51,15 @@ func cloneBoardPool(b *Board) *Board {
 // 若你在根并行的 worker 内部“只克隆一次后复用”，也可以用这个。
 func cloneBoard(b *Board) *Board {
 	nb := &Board{
-		radius:     b.radius,
 		Cells:      b.Cells, // 数组值拷贝
+		cut:        b.cut,
 		hash:       b.hash,
 		bitA:       b.bitA,
 		bitB:       b.bitB,
+		reach:      b.reach,
+		eval:       b.eval,
+		lin:        b.lin,
+		ids:        b.ids,
 		LastMove:   b.LastMove,
 		LastMover:  b.LastMover,
 		LastInfect: b.LastInfect,
@@ -57,14 +67,28 @@ func cloneBoard(b *Board) *Board {
 	return nb
 }
 
+// FindBestMoveAtDepth 根并行 α-β 搜索。过滤器都有兜底，只有 player 完全无合法走法时才返回 ok=false，
+// 此时调用方应通过 GameState.AdjudicateIfBlocked 结束对局。
 func FindBestMoveAtDepth(b *Board, player CellState, depth int64, allowJump bool) (Move, bool) {
+	mv, ok, _ := findBestMoveAtDepth(b, player, depth, allowJump, time.Time{})
+	return mv, ok
+}
+
+// findBestMoveAtDepth deadline 非零时到点即停，complete=false 表示这一层没搜完、结果不可用（ok 也为 false），见 search_budget.go
+func findBestMoveAtDepth(b *Board, player CellState, depth int64, allowJump bool, deadline time.Time) (best Move, ok, complete bool) {
+	syncTTEvaluator()
+	abortGen := searchAbortGen.Load()
+
 	moves := GenerateMoves(b, player)
 	moves = applyMoveFilters(b, player, moves, allowJump)
 	if len(moves) == 0 {
-		return Move{}, false
+		return Move{}, false, true
 	}
+	moves = filterNegBook(b, player, moves)
+	// 开局对称局面：等价着法只搜一个
+	moves = dedupSymmetricMoves(b, moves)
 
-	useNN := (player == PlayerA && UseONNXForPlayerA) || (player == PlayerB && UseONNXForPlayerB)
+	useNN := useNNFor(b, player)
 
 	// 计算并行度：核心数/8，向上取偶数，范围 [2, 8]
 	numWorkers := (runtime.NumCPU() + 7) / 8
@@ -92,10 +116,7 @@ func FindBestMoveAtDepth(b *Board, player CellState, depth int64, allowJump bool
 		
 		for i, mv := range moves {
 			// 从池中获取或临时克隆一个，但尽量复用
-			nb := acquireBoard(b.radius)
-			nb.Cells = b.Cells
-			nb.bitA = b.bitA
-			nb.bitB = b.bitB
+			nb := cloneBoardPool(b)
 			nb.ApplyMove(mv, player)
 			batchBoards[i] = nb
 		}
@@ -112,7 +133,7 @@ func FindBestMoveAtDepth(b *Board, player CellState, depth int64, allowJump bool
 				results[i] = scored{mv: moves[i], score: -s}
 			}
 			sort.Slice(results, func(i, j int) bool { return results[i].score > results[j].score })
-			return results[0].mv, true
+			return results[0].mv, true, true
 		}
 	}
 
@@ -151,9 +172,12 @@ func FindBestMoveAtDepth(b *Board, player CellState, depth int64, allowJump bool
 			}
 		}
 	} else {
-		sort.Slice(moves, func(i, j int) bool {
-			return previewInfectedCount(b, moves[i], player) > previewInfectedCount(b, moves[j], player)
-		})
+		// 按局部交换收益排序：只看吃子数会把“吃一送二”的着法排在前面
+		see := make(map[Move]int, len(moves))
+		for _, mv := range moves {
+			see[mv] = seeMove(b, player, mv)
+		}
+		sort.SliceStable(moves, func(i, j int) bool { return see[moves[i]] > see[moves[j]] })
 		for i, mv := range moves {
 			taskChan <- task{i, mv}
 		}
@@ -161,42 +185,51 @@ func FindBestMoveAtDepth(b *Board, player CellState, depth int64, allowJump bool
 	close(taskChan)
 
 	var wg sync.WaitGroup
+	var expired atomic.Bool
 	for i := 0; i < numWorkers; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			localBoard := b.Clone() // 每个线程私有 Board
-			var localNodes int64
+			localNodes := nodeCounter{deadline: deadline, abortGen: abortGen}
 			for t := range taskChan {
+				if localNodes.timeUp() {
+					continue // 到点了：剩下的根着法不再搜，只把通道取空
+				}
 				undo := mMakeMoveWithUndo(localBoard, t.mv, player)
+				d, ext := childDepth(localBoard, player, depth, searchExtMax, allowJump)
 				// 初始 alpha/beta 窗口
-				score := hybridAlphaBeta(localBoard, 0, Opponent(player), player, depth-1, -1000000, 1000000, allowJump, &localNodes)
+				score := hybridAlphaBeta(localBoard, 0, Opponent(player), player, d, -1000000, 1000000, allowJump, &localNodes, ext)
 				localBoard.UnmakeMove(undo)
 				results[t.idx] = scored{mv: t.mv, score: score}
 			}
 			// 同步剩余节点
-			if localNodes > 0 {
-				AddNodes(localNodes)
+			localNodes.flush()
+			if localNodes.expired {
+				expired.Store(true)
 			}
 		}()
 	}
 	wg.Wait()
+	if expired.Load() {
+		return Move{}, false, false
+	}
 
 	sort.Slice(results, func(i, j int) bool { return results[i].score > results[j].score })
 
 	if useNN {
-		return results[0].mv, true
+		return results[0].mv, true, true
 	}
 
 	if len(results) >= 2 && results[0].score > results[1].score+200 {
-		return results[0].mv, true
+		return results[0].mv, true, true
 	}
 	topK := 2
 	if len(results) < topK {
 		topK = len(results)
 	}
 	pick := rand.Intn(topK)
-	return results[pick].mv, true
+	return results[pick].mv, true, true
 }
 
 
@@ -207,9 +240,10 @@ func hybridAlphaBeta(
 	depth int64,
 	alpha, beta int,
 	allowJump bool,
-	localNodes *int64, // 新增：局部计数器
+	localNodes *nodeCounter, // 本协程的节点计数与截止时间，nil = 直接记全局、不限时
+	extLeft int, // 本路径剩余的强制线延伸层数，见 search_ext.go
 ) int {
-	useNN := (original == PlayerA && UseONNXForPlayerA) || (original == PlayerB && UseONNXForPlayerB)
+	useNN := useNNFor(b, original)
 
 	if depth <= 0 {
 		if useNN {
@@ -220,7 +254,7 @@ func hybridAlphaBeta(
 			}
 			return v
 		}
-		return EvaluateBitBoard(b, original)
+		return leafEval(b, original, current)
 	}
 
 	ttKey := ttKeyFor(b, current)
@@ -231,6 +265,7 @@ func hybridAlphaBeta(
 		}
 		switch flag {
 		case ttExact:
+			countTTCutoff()
 			return val
 		case ttLower:
 			if val > alpha {
@@ -242,21 +277,22 @@ func hybridAlphaBeta(
 			}
 		}
 		if alpha >= beta {
+			countTTCutoff()
 			return val
 		}
 	}
 
 	if localNodes != nil {
-		*localNodes++
-		if *localNodes >= 1024 {
-			AddNodes(*localNodes)
-			*localNodes = 0
+		if localNodes.tick() {
+			return 0 // 超时，调用方会整层作废
 		}
 	} else {
 		incNodes()
 	}
 
-	moves := GenerateMoves(b, current)
+	mbuf := acquireMoves()
+	moves := GenerateMovesInto(b, current, *mbuf)
+	defer releaseMoves(mbuf, moves)
 	moves = applyMoveFilters(b, current, moves, allowJump)
 
 	if len(moves) == 0 {
@@ -267,17 +303,14 @@ func hybridAlphaBeta(
 			}
 			return v
 		}
-		return EvaluateBitBoard(b, original)
+		return leafEval(b, original, current)
 	}
 
 	// 深度 2 优化：在叶子节点上一层进行批量评估
 	if depth == 1 && useNN {
 		batchBoards := make([]*Board, len(moves))
 		for i, mv := range moves {
-			nb := acquireBoard(b.radius)
-			nb.Cells = b.Cells
-			nb.bitA = b.bitA
-			nb.bitB = b.bitB
+			nb := cloneBoardPool(b)
 			nb.ApplyMove(mv, current)
 			batchBoards[i] = nb
 		}
@@ -291,6 +324,7 @@ func hybridAlphaBeta(
 		}
 		
 		if err == nil {
+			countNNBatchLeaf()
 			best := 0
 			if current == original { // MAX 节点
 				best = -1000000
@@ -332,8 +366,12 @@ func hybridAlphaBeta(
 		bestScore = math.MinInt32
 		for i, mv := range moves {
 			undo := mMakeMoveWithUndo(b, mv, current)
-			score := hybridAlphaBeta(b, 0, Opponent(current), original, depth-1, alpha, beta, allowJump, localNodes)
+			d, ext := childDepth(b, current, depth, extLeft, allowJump)
+			score := hybridAlphaBeta(b, 0, Opponent(current), original, d, alpha, beta, allowJump, localNodes, ext)
 			b.UnmakeMove(undo)
+			if localNodes.stopped() {
+				return 0 // 子树没搜完，不能当真，也不写置换表
+			}
 			if score > bestScore {
 				bestScore = score
 				bestIdx = uint8(i)
@@ -341,6 +379,7 @@ func hybridAlphaBeta(
 			if score > alpha {
 				alpha = score
 				if alpha >= beta {
+					countCutoff(true, i)
 					break
 				}
 			}
@@ -349,8 +388,12 @@ func hybridAlphaBeta(
 		bestScore = math.MaxInt32
 		for i, mv := range moves {
 			undo := mMakeMoveWithUndo(b, mv, current)
-			score := hybridAlphaBeta(b, 0, Opponent(current), original, depth-1, alpha, beta, allowJump, localNodes)
+			d, ext := childDepth(b, current, depth, extLeft, allowJump)
+			score := hybridAlphaBeta(b, 0, Opponent(current), original, d, alpha, beta, allowJump, localNodes, ext)
 			b.UnmakeMove(undo)
+			if localNodes.stopped() {
+				return 0
+			}
 			if score < bestScore {
 				bestScore = score
 				bestIdx = uint8(i)
@@ -358,6 +401,7 @@ func hybridAlphaBeta(
 			if score < beta {
 				beta = score
 				if beta <= alpha {
+					countCutoff(false, i)
 					break
 				}
 			}
@@ -387,16 +431,9 @@ func hybridAlphaBeta(
 // α-β + 置换表
 // ------------------------------------------------------------
 func mMakeMoveWithUndo(b *Board, mv Move, player CellState) undoInfo {
-	u := undoInfo{
-		prevLastMove:   b.LastMove,
-		prevLastMover:  b.LastMover,
-		prevLastInfect: b.LastInfect,
-	}
-	b.LastMove = mv
-	infected, inner := mv.MakeMove(b, player) // 这里会改 cells/hash
+	u := b.makeMove(mv, player) // 这里会改 cells/hash，不分配
 	b.LastMover = player
-	b.LastInfect = len(infected)
-	u.changed = inner.changed
+	b.LastInfect = u.infected.Count()
 	return u
 }
 
@@ -409,10 +446,11 @@ func alphaBeta(
 	depth int64,
 	alpha, beta int,
 	allowJump bool,
-	localNodes *int64, // 新增：局部计数器
+	localNodes *nodeCounter, // 本协程的节点计数与截止时间，nil = 直接记全局、不限时
+	extLeft int, // 本路径剩余的强制线延伸层数，见 search_ext.go
 ) int {
 	if depth <= 0 {
-		return Evaluate(b, original)
+		return evaluateAt(b, original, current)
 	}
 
 	ttKey := ttKeyFor(b, current)
@@ -424,6 +462,7 @@ func alphaBeta(
 		}
 		switch flag {
 		case ttExact:
+			countTTCutoff()
 			return val
 		case ttLower:
 			if val > alpha {
@@ -435,26 +474,27 @@ func alphaBeta(
 			}
 		}
 		if alpha >= beta {
+			countTTCutoff()
 			return val
 		}
 	}
 
 	if localNodes != nil {
-		*localNodes++
-		if *localNodes >= 1024 {
-			AddNodes(*localNodes)
-			*localNodes = 0
+		if localNodes.tick() {
+			return 0 // 超时，调用方会整层作废
 		}
 	} else {
 		incNodes()
 	}
 
-	// 1) 走法生成（含 UI 禁跳）
-	moves := GenerateMoves(b, current)
+	// 1) 走法生成（含 UI 禁跳），缓冲取自池、返回时放回
+	mbuf := acquireMoves()
+	moves := GenerateMovesInto(b, current, *mbuf)
+	defer releaseMoves(mbuf, moves)
 	moves = applyMoveFilters(b, current, moves, allowJump)
 
 	if len(moves) == 0 {
-		return Evaluate(b, original)
+		return evaluateAt(b, original, current)
 	}
 
 	alphaOrig, betaOrig := alpha, beta
@@ -477,10 +517,14 @@ func alphaBeta(
 
 		for i, mv := range moves {
 			undo := mMakeMoveWithUndo(b, mv, current)
+			d, ext := childDepth(b, current, depth, extLeft, allowJump)
 
-			score := alphaBeta(b, 0, Opponent(current), original, depth-1, alpha, beta, allowJump, localNodes)
+			score := alphaBeta(b, 0, Opponent(current), original, d, alpha, beta, allowJump, localNodes, ext)
 
 			b.UnmakeMove(undo)
+			if localNodes.stopped() {
+				return 0
+			}
 
 			if score > bestScore {
 				bestScore = score
@@ -489,6 +533,7 @@ func alphaBeta(
 			if score > alpha {
 				alpha = score
 				if alpha >= beta {
+					countCutoff(true, i)
 					break
 				}
 			}
@@ -499,10 +544,14 @@ func alphaBeta(
 
 		for i, mv := range moves {
 			undo := mMakeMoveWithUndo(b, mv, current)
+			d, ext := childDepth(b, current, depth, extLeft, allowJump)
 
-			score := alphaBeta(b, 0, Opponent(current), original, depth-1, alpha, beta, allowJump, localNodes)
+			score := alphaBeta(b, 0, Opponent(current), original, d, alpha, beta, allowJump, localNodes, ext)
 
 			b.UnmakeMove(undo)
+			if localNodes.stopped() {
+				return 0
+			}
 
 			if score < bestScore {
 				bestScore = score
@@ -511,6 +560,7 @@ func alphaBeta(
 			if score < beta {
 				beta = score
 				if beta <= alpha {
+					countCutoff(false, i)
 					break
 				}
 			}
@@ -556,12 +606,7 @@ func min(a, b int) int {
 
 func chooseEndgameDepth(b *Board, base int) int {
 	// 统计空格
-	empties := 0
-	for i := 0; i < BoardN; i++ {
-		if b.Cells[i] == Empty {
-			empties++
-		}
-	}
+	empties := b.EmptyMask().Count()
 	switch {
 	case empties <= 6:
 		// 残局很小，基本可以搜到底（每回合至少占/改变1格，给点冗余）
@@ -580,13 +625,8 @@ func findImmediateWinOnly(b *Board, p CellState) (Move, bool) {
 	for _, mv := range GenerateMoves(b, p) {
 		undo := mMakeMoveWithUndo(b, mv, p)
 
-		empties := 0
-		for i := 0; i < BoardN; i++ {
-			if b.Cells[i] == Empty {
-				empties++
-			}
-		}
-		noOpp := len(GenerateMoves(b, op)) == 0
+		empties := b.EmptyMask().Count()
+		noOpp := !b.HasMoves(op)
 		b.UnmakeMove(undo)
 
 		if noOpp || empties == 0 {
@@ -598,7 +638,15 @@ func findImmediateWinOnly(b *Board, p CellState) (Move, bool) {
 
 func DeepSearch(b *Board, hash uint64, side CellState, depth int) int {
 
-	return alphaBeta(b, hash, side, side, int64(depth), -32000, 32000, true, nil)
+	return alphaBeta(b, hash, side, side, int64(depth), -32000, 32000, true, nil, searchExtMax)
+}
+
+// SearchProgress 迭代加深每完成一层回报一次
+type SearchProgress struct {
+	Depth    int           // 已完成的深度
+	Best     Move          // 该深度下的最佳着法
+	Elapsed  time.Duration // 自搜索开始
+	HashFull int           // 该层结束时的置换表占用率（千分比），见 HashFull
 }
 
 func IterativeDeepening(
@@ -607,17 +655,36 @@ func IterativeDeepening(
 	maxDepth int,
 	allowJump bool,
 ) (best Move, bestScore int, ok bool) {
+	return IterativeDeepeningProgress(root, player, maxDepth, allowJump, nil)
+}
 
+// IterativeDeepeningProgress 同 IterativeDeepening，每完成一层在搜索协程里同步调用 onDepth（可为 nil），
+// 回调应尽快返回。开着复核（SetVerifyConfig）时最后一层交给 FindBestMoveVerified。
+func IterativeDeepeningProgress(
+	root *Board,
+	player CellState,
+	maxDepth int,
+	allowJump bool,
+	onDepth func(SearchProgress),
+) (best Move, bestScore int, ok bool) {
+	start := time.Now()
 	for depth := 1; depth <= maxDepth; depth++ {
 		// 暂时关闭残局加深，确保混合搜索时间稳定
 		fullDepth := depth
 
 		// 根搜索
-		mv, hit := FindBestMoveAtDepth(root, player, int64(fullDepth), allowJump)
+		search := FindBestMoveAtDepth
+		if depth == maxDepth {
+			search = FindBestMoveVerified
+		}
+		mv, hit := search(root, player, int64(fullDepth), allowJump)
 		if !hit {
 			break
 		}
 		best, bestScore, ok = mv, 0, true
+		if onDepth != nil {
+			onDepth(SearchProgress{Depth: depth, Best: mv, Elapsed: time.Since(start), HashFull: HashFull()})
+		}
 	}
 	return
 }
@@ -635,7 +702,8 @@ func AlphaBeta(b *Board, player CellState, depth int) int {
 		math.MinInt, // 初始 α
 		math.MaxInt, // 初始 β
 		true,
-		nil)
+		nil,
+		searchExtMax)
 }
 
 // alphaBetaNoTT 在 b 上执行一次不带置换表的 α–β 搜索。
@@ -668,7 +736,7 @@ func alphaBetaNoTT(
 ) int {
 	// 递归终止：深度到 0 或无空位
 	if depth == 0 || b.CountPieces(PlayerA)+b.CountPieces(PlayerB) == len(b.AllCoords()) {
-		return Evaluate(b, original)
+		return evaluateAt(b, original, current)
 	}
 
 	moves := GenerateMoves(b, current)
@@ -715,38 +783,73 @@ func alphaBetaNoTT(
 }
 
 func applyMoveFilters(b *Board, side CellState, moves []Move, allowJump bool) []Move {
+	return applyMoveFiltersTraced(b, side, moves, allowJump, nil)
+}
+
+// applyMoveFiltersTraced 同 applyMoveFilters；dropped 非 nil 时对每个被剔除的着法回调一次，reason 为过滤器名。
+// 过滤器原地改写 moves，调用方还要用原切片时先复制
+func applyMoveFiltersTraced(b *Board, side CellState, moves []Move, allowJump bool, dropped func(mv Move, reason string)) []Move {
 	// 如果当前执子方使用的是 NN 评估，我们只保留最关键的过滤器。
-	useNN := false
-	if side == PlayerA && UseONNXForPlayerA {
-		useNN = true
-	} else if side == PlayerB && UseONNXForPlayerB {
-		useNN = true
-	}
-	
+	useNN := useNNFor(b, side)
+
 	// 这里必须小心：如果 GenerateMoves 返回的是预分配缓冲区的切片，或者我们连续调用多个原地过滤器，
 	// 逻辑必须闭环。
+	before := snapshotMoves(moves, dropped)
 	out := filterJumpsByFlag(b, side, moves, allowJump)
-	
-	if useNN {
-		// NN 玩家仍然应用这些核心的防御性过滤，防止 1 层搜索时的低级错误
-		out = filterZeroInfectJumpsOrFallback(b, side, out)
-		if allowJump {
-			out = filterDangerousRecaptureJumps(b, side, out)
-		}
-		out = filterVulnerableZeroInfClones(b, side, out)
-		return out
-	}
+	endFilter(filterJumpGate, before, out, dropped)
 
-	out = filterOpeningEdgeOnly(b, side, out)
+	if !useNN {
+		before = snapshotMoves(out, dropped)
+		out = filterOpeningEdgeOnly(b, side, out)
+		endFilter(filterOpeningEdge, before, out, dropped)
+	}
+	// NN 玩家仍然应用以下核心的防御性过滤，防止 1 层搜索时的低级错误
+	before = snapshotMoves(out, dropped)
 	out = filterZeroInfectJumpsOrFallback(b, side, out)
+	endFilter(filterZeroInfectJump, before, out, dropped)
 	if allowJump {
+		before = snapshotMoves(out, dropped)
 		out = filterDangerousRecaptureJumps(b, side, out)
+		endFilter(filterRecaptureJump, before, out, dropped)
 	}
+	before = snapshotMoves(out, dropped)
 	out = filterVulnerableZeroInfClones(b, side, out)
+	endFilter(filterVulnerableClone, before, out, dropped)
+	if useNN {
+		return out
+	}
+
+	before = snapshotMoves(out, dropped)
 	out = filterDangerousIsolatedClones(b, side, out)
+	endFilter(filterIsolatedClone, before, out, dropped)
 	return out
 }
 
+// snapshotMoves 过滤前的着法：不回调时只用到长度，直接返回；要回调时复制一份（过滤器会原地改写）
+func snapshotMoves(moves []Move, dropped func(Move, string)) []Move {
+	if dropped == nil {
+		return moves
+	}
+	return append([]Move(nil), moves...)
+}
+
+// endFilter 记一个过滤器的统计，dropped 非 nil 时报告 before 里没留在 kept 里的着法
+func endFilter(idx int, before, kept []Move, dropped func(Move, string)) {
+	countFilter(idx, len(before), len(kept))
+	if dropped == nil || len(before) == len(kept) {
+		return
+	}
+	keep := make(map[Move]bool, len(kept))
+	for _, mv := range kept {
+		keep[mv] = true
+	}
+	for _, mv := range before {
+		if !keep[mv] {
+			dropped(mv, moveFilterNames[idx])
+		}
+	}
+}
+
 // 根节点/任意节点可复用的过滤器：尽量剔除“0 感染跳跃”，但保证不至于空集合
 func filterZeroInfectJumpsOrFallback(b *Board, side CellState, moves []Move) []Move {
 	n := 0
@@ -774,73 +877,19 @@ func filterZeroInfectJumpsOrFallback(b *Board, side CellState, moves []Move) []M
 	return moves
 }
 
-// 过滤“跳跃且只感染1子，但对手可一手同时反吃落点+该子”的招法。
+// 过滤“跳跃后对手一手反吃就明显赚回”的招法（局部交换评估见 see.go）。
 // 保守起见：若全被删光，则回退原 moves。
 func filterDangerousRecaptureJumps(b *Board, me CellState, moves []Move) []Move {
-	op := Opponent(me)
 	n := 0
 	fullCount := len(moves)
-
 	for i := 0; i < fullCount; i++ {
 		mv := moves[i]
-		// 只针对跳跃
-		if !mv.IsJump() {
-			moves[n] = mv
-			n++
+		if mv.IsJump() && seeDangerous(b, me, mv) {
 			continue
 		}
-		toIdx, ok := IndexOf[mv.To]
-		if !ok {
-			moves[n] = mv
-			n++
-			continue
-		}
-
-		// 统计“即时被你感染”的邻格（这里只关心 == 1 的情形）
-		inf := -1
-		for _, nb := range NeighI[toIdx] {
-			if b.Cells[nb] == op {
-				if inf == -1 {
-					inf = nb
-				} else {
-					inf = -2 // 多于1个
-					break
-				}
-			}
-		}
-		if inf != -1 && inf != -2 {
-			// inf == 单一被感染格的下标
-		} else {
-			// 0 或 >=2，不做这个危险判定（按你描述只针对“感染1子”）
-			moves[n] = mv
-			n++
-			continue
-		}
-
-		// 找“同时邻接 落点(toIdx) 和 被感染(inf) 的空位 x”
-		// 也就是 x ∈ Neigh(toIdx) ∩ Neigh(inf)
-		danger := false
-		for _, x := range NeighI[toIdx] {
-			if b.Cells[x] != Empty {
-				continue
-			}
-			// x 也必须邻接 inf
-			if !isNeighborI(inf, x) {
-				continue
-			}
-			// 对手下一手能到 x（克隆/跳），则这步判危险
-			if opponentCanReachNextI(b, op, x) {
-				danger = true
-				break
-			}
-		}
-
-		if !danger {
-			moves[n] = mv
-			n++
-		}
+		moves[n] = mv
+		n++
 	}
-
 	if n == 0 {
 		return moves[:fullCount]
 	}
@@ -857,7 +906,7 @@ func filterOpeningEdgeOnly(b *Board, side CellState, moves []Move) []Move {
 	fullCount := len(moves)
 	for i := 0; i < fullCount; i++ {
 		mv := moves[i]
-		if mv.IsClone() && isOuterI[IndexOf[mv.From]] && isOuterI[IndexOf[mv.To]] {
+		if mv.IsClone() && b.onRim(IndexOf[mv.From]) && b.onRim(IndexOf[mv.To]) {
 			moves[n] = mv
 			n++
 		}
@@ -888,25 +937,6 @@ func infectionUnlocked(b *Board) bool {
 	}
 	return false
 }
-func opponentCanReachNextI(b *Board, op CellState, dst int) bool {
-	if b.Cells[dst] != Empty {
-		return false
-	}
-	// 克隆一步（邻接）
-	for _, from := range NeighI[dst] {
-		if b.Cells[from] == op {
-			return true
-		}
-	}
-	// 跳两步（距离2）
-	for _, from := range JumpI[dst] {
-		if b.Cells[from] == op {
-			return true
-		}
-	}
-	return false
-}
-
 // 删掉“感染数 < minInf”的跳越。例：minInf=2 => 删掉0和1感染跳越。
 // 若全删光，则至少保留所有克隆；再不行就原样返回，保证不至于无解。
 func filterLowInfectJumpsOrFallback(b *Board, side CellState, moves []Move, minInf int) []Move {
@@ -949,48 +979,16 @@ func isIsolated(b *Board, who CellState, at HexCoord) bool {
 	return true
 }
 
-func sharedNeighbors(a, b HexCoord) []HexCoord {
-	m := make(map[HexCoord]bool, 6)
-	for _, d := range Directions {
-		m[HexCoord{a.Q + d.Q, a.R + d.R}] = true
-	}
-	out := make([]HexCoord, 0, 2)
-	for _, d := range Directions {
-		c := HexCoord{b.Q + d.Q, b.R + d.R}
-		if m[c] {
-			out = append(out, c)
-		}
-	}
-	return out
-}
-
+// 孤立子克隆出去后 from/to 两子挨在一起，对手一手落到共同邻居就能同时翻回
 func isDangerousIsolatedClone(b *Board, me CellState, mv Move) bool {
-	if !mv.IsClone() {
-		return false
-	}
-	if !isIsolated(b, me, mv.From) {
-		return false
-	}
-	op := Opponent(me)
-	// from/to 的共同邻居作为“对手一跳双吃”的落点候选
-	for _, x := range sharedNeighbors(mv.From, mv.To) {
-		if opponentCanReachNext(b, op, x) {
-			return true
-		}
-	}
-	return false
+	return mv.IsClone() && isIsolated(b, me, mv.From) && seeDangerous(b, me, mv)
 }
 
 // 删掉“危险孤立克隆”。若删光了，就回退为原 moves（避免无解）；
 func filterDangerousIsolatedClones(b *Board, me CellState, moves []Move) []Move {
 	// 只在开局/前中期更有意义，降低误杀：空位比例大时才启用
 	total := len(b.AllCoords())
-	empties := 0
-	for i := 0; i < BoardN; i++ {
-		if b.Cells[i] == Empty {
-			empties++
-		}
-	}
+	empties := b.EmptyMask().Count()
 	r := float64(empties) / float64(total)
 	if r < 0.65 { // 阈值可调：开局/前中期才启用
 		return moves
@@ -1012,85 +1010,21 @@ func filterDangerousIsolatedClones(b *Board, me CellState, moves []Move) []Move
 	return moves[:originalCount] // 全被删光就回退
 }
 
-// 过滤“克隆且不吃子，但对手下一步可以到达 from/to 的共同邻居并感染这两个子”的招法。
+// 过滤“克隆且不吃子，但对手下一手反吃明显赚回（典型是同时翻回 from/to 两子）”的招法。
 // 若全删光则回退原 moves。
 func filterVulnerableZeroInfClones(b *Board, me CellState, moves []Move) []Move {
-	op := Opponent(me)
 	n := 0
 	originalCount := len(moves)
 	for i := 0; i < originalCount; i++ {
 		mv := moves[i]
-		if !mv.IsClone() {
-			moves[n] = mv
-			n++
-			continue
-		}
-		// 仅关注“未吃子”的克隆
-		if previewInfectedCount(b, mv, me) != 0 {
-			moves[n] = mv
-			n++
+		if mv.IsClone() && previewInfectedCount(b, mv, me) == 0 && seeDangerous(b, me, mv) {
 			continue
 		}
-		// 寻找 from/to 的共同邻居空位，若对手可一手到达则视为危险
-		danger := false
-		for _, x := range sharedNeighbors(mv.From, mv.To) {
-			if idx, ok := IndexOf[x]; ok {
-				if b.Cells[idx] != Empty {
-					continue
-				}
-				if opponentCanReachNext(b, op, x) {
-					danger = true
-					break
-				}
-			}
-		}
-		if !danger {
-			moves[n] = mv
-			n++
-		}
+		moves[n] = mv
+		n++
 	}
 	if n == 0 {
 		return moves[:originalCount]
 	}
 	return moves[:n]
 }
-func opponentCanJumpTo(b *Board, op CellState, dst HexCoord) bool {
-	// 坐标 -> 下标
-	to, ok := IndexOf[dst]
-	if !ok {
-		return false
-	}
-	// 目标必须是空
-	if b.Cells[to] != Empty {
-		return false
-	}
-	// 任何一个“能跳到 to 的起点(from)”上有对手棋子即可
-	for _, from := range JumpI[to] {
-		if b.Cells[from] == op {
-			return true
-		}
-	}
-	return false
-}
-
-func opponentCanCloneTo(b *Board, op CellState, dst HexCoord) bool {
-	to, ok := IndexOf[dst]
-	if !ok {
-		return false
-	}
-	if b.Cells[to] != Empty {
-		return false
-	}
-	// 任何一个相邻格有对手棋子即可克隆到 to
-	for _, from := range NeighI[to] {
-		if b.Cells[from] == op {
-			return true
-		}
-	}
-	return false
-}
-
-// 对手下一手“能占到 dst 吗”（克隆或跳越任一成立）
-func opponentCanReachNext(b *Board, op CellState, dst HexCoord) bool {
-	return opponentCanCloneTo(b, op, dst) || opponentCanJumpTo(b, op, dst)
-}
@@ -375,9 +375,9 @@ func movesFromSelected(b *Board, player CellState, fromIdx int, allowJump bool)
 	moves := make([]Move, 0, len(NeighI[fromIdx])+len(JumpI[fromIdx]))
 	fromCoord := CoordOf[fromIdx]
 
-	// 克隆
+	// 克隆（克隆上限规则下要有剩余次数）
 	for _, to := range NeighI[fromIdx] {
-		if b.Cells[to] == Empty {
+		if b.Cells[to] == Empty && b.canCloneI(fromIdx) {
 			moves = append(moves, Move{From: fromCoord, To: CoordOf[to]})
 		}
 	}
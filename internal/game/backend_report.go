@@ -0,0 +1,218 @@
+// internal/game/backend_report.go
+package game
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	ort "github.com/yalue/onnxruntime_go"
+)
+
+// NN 后端能力报告：每类会话最终落在哪个 Execution Provider、是否跑 FP16、批量多大、
+// 建会话和热身各花了多久，以及前面哪些 EP 试过失败了。
+// 只有一句 "Successfully initialized with X" 时，用户机器上 CPU 回退、DirectML 首轮编译慢之类的问题看不出来。
+
+// BackendSession 一类 NN 会话的初始化结果
+type BackendSession struct {
+	Name      string        `json:"name"`
+	Provider  string        `json:"provider,omitempty"`
+	FP16      bool          `json:"fp16"`
+	BatchSize int           `json:"batch_size,omitempty"`
+	InitTime  time.Duration `json:"init_ns,omitempty"`   // 建会话（含 TensorRT 引擎编译/加载缓存）
+	WarmUp    time.Duration `json:"warmup_ns,omitempty"` // 全部热身 Run 合计
+	FirstRun  time.Duration `json:"first_run_ns,omitempty"`
+	SteadyRun time.Duration `json:"steady_run_ns,omitempty"` // 热身后一次 Run，接近实际对局延迟
+	Failed    []string      `json:"failed,omitempty"`        // "EP: 原因"，按尝试顺序
+}
+
+func (s BackendSession) String() string {
+	if s.Provider == "" {
+		return s.Name + ": 未初始化"
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %s", s.Name, s.Provider)
+	if s.FP16 {
+		sb.WriteString(" fp16")
+	} else {
+		sb.WriteString(" fp32")
+	}
+	if s.BatchSize > 0 {
+		fmt.Fprintf(&sb, " batch=%d", s.BatchSize)
+	}
+	if s.InitTime > 0 {
+		fmt.Fprintf(&sb, " init=%s", s.InitTime.Round(time.Millisecond))
+	}
+	if s.WarmUp > 0 {
+		fmt.Fprintf(&sb, " warm-up=%s (first %s, steady %s)",
+			s.WarmUp.Round(time.Millisecond), s.FirstRun.Round(time.Microsecond), s.SteadyRun.Round(time.Microsecond))
+	}
+	for _, f := range s.Failed {
+		fmt.Fprintf(&sb, "\n    skipped %s", f)
+	}
+	return sb.String()
+}
+
+// BackendReport 进程内所有 NN 后端的快照
+type BackendReport struct {
+	ORTVersion string           `json:"ort_version,omitempty"`
+	Library    string           `json:"library,omitempty"`
+	Config     ORTConfig        `json:"config"`
+	Sessions   []BackendSession `json:"sessions"`
+}
+
+func (r BackendReport) String() string {
+	var sb strings.Builder
+	ver := r.ORTVersion
+	if ver == "" {
+		ver = "未加载"
+	}
+	fmt.Fprintf(&sb, "NN backend report: ORT %s", ver)
+	if r.Library != "" {
+		fmt.Fprintf(&sb, " (%s)", r.Library)
+	}
+	fmt.Fprintf(&sb, "\n  options: %s", r.Config)
+	if len(r.Sessions) == 0 {
+		sb.WriteString("\n  (no NN session initialized)")
+	}
+	for _, s := range r.Sessions {
+		sb.WriteString("\n  ")
+		sb.WriteString(s.String())
+	}
+	return sb.String()
+}
+
+var (
+	ortLibMu   sync.Mutex
+	ortLibUsed string
+)
+
+// noteORTLib 记录实际交给 SetSharedLibraryPath 的库路径
+func noteORTLib(path string) {
+	ortLibMu.Lock()
+	ortLibUsed = path
+	ortLibMu.Unlock()
+}
+
+// noteNNFailure 记录某个 EP 尝试失败的原因
+func noteNNFailure(slot int, provider string, err error) {
+	nnBackendMu.Lock()
+	nnBackends[slot].Failed = append(nnBackends[slot].Failed, fmt.Sprintf("%s: %v", provider, err))
+	nnBackendMu.Unlock()
+}
+
+// setNNBackend 记录会话初始化成功后的信息；之前的失败记录保留
+func setNNBackend(slot int, s BackendSession) {
+	nnBackendMu.Lock()
+	s.Name = nnSessionNames[slot]
+	s.Failed = nnBackends[slot].Failed
+	nnBackends[slot] = s
+	nnBackendMu.Unlock()
+}
+
+// providerFP16 该 EP 在本项目的配置下是否以半精度推理：
+// TensorRT 开了 trt_fp16_enable；CoreML 走 ANE 时只支持 FP16。其余按模型原样 FP32。
+func providerFP16(provider string) bool {
+	return provider == "TensorRT" || provider == "CoreML"
+}
+
+// warmUpRuns 首轮 Run 要编译/特化图的 EP 多热几轮，避免把慢的前几步留到对局里
+func warmUpRuns(provider string) int {
+	switch provider {
+	case "CoreML", "DirectML":
+		return 3
+	}
+	return 1
+}
+
+// warmUpSession 跑 n 轮热身再测一轮稳态；返回首轮、合计、稳态耗时
+func warmUpSession(run func() error, n int) (first, total, steady time.Duration, err error) {
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		t := time.Now()
+		if err = run(); err != nil {
+			return
+		}
+		if i == 0 {
+			first = time.Since(t)
+		}
+	}
+	total = time.Since(start)
+	t := time.Now()
+	if err = run(); err != nil {
+		return
+	}
+	steady = time.Since(t)
+	return
+}
+
+// GetBackendReport 返回当前已初始化（或尝试过）的 NN 会话信息
+func GetBackendReport() BackendReport {
+	r := BackendReport{Config: ortCfg}
+	if ort.IsInitialized() {
+		r.ORTVersion = ort.GetVersion()
+	}
+	ortLibMu.Lock()
+	r.Library = ortLibUsed
+	ortLibMu.Unlock()
+
+	nnBackendMu.Lock()
+	defer nnBackendMu.Unlock()
+	for i, s := range nnBackends {
+		if s.Provider == "" && len(s.Failed) == 0 {
+			continue
+		}
+		s.Name = nnSessionNames[i]
+		s.Failed = append([]string(nil), s.Failed...)
+		r.Sessions = append(r.Sessions, s)
+	}
+	return r
+}
+
+// EnsureNNBackend 同步初始化主力 KataGo 后端（已初始化则立即返回）
+func EnsureNNBackend() error { return ensureKataONNX() }
+
+// CPUFallback 报告主力后端是否在 GPU EP 全部失败后落到了 CPU。
+// settled=false 表示初始化还没结束（UI 每帧轮询时据此等待）；reason 是最先尝试的 EP 的失败原因，压成一行。
+func CPUFallback() (reason string, fellBack, settled bool) {
+	if !katagoSettled.Load() {
+		return "", false, false
+	}
+	nnBackendMu.Lock()
+	provider := nnBackends[nnKata].Provider
+	nnBackendMu.Unlock()
+	if provider != "CPU" {
+		return "", false, true
+	}
+	reason, fellBack = cpuFallbackReason()
+	return reason, fellBack, true
+}
+
+func cpuFallbackReason() (string, bool) {
+	nnBackendMu.Lock()
+	defer nnBackendMu.Unlock()
+	failed := nnBackends[nnKata].Failed
+	if len(failed) == 0 {
+		return "", false // 本来就只配置了 CPU
+	}
+	reason := failed[0]
+	if i := strings.IndexAny(reason, "\r\n"); i >= 0 {
+		reason = reason[:i]
+	}
+	const maxLen = 80
+	if r := []rune(reason); len(r) > maxLen {
+		reason = string(r[:maxLen]) + "..."
+	}
+	return reason, true
+}
+
+// WriteBackendReport 初始化主力 KataGo 后端（已初始化则直接返回）后把报告写到 w；
+// 各命令的 -backend-report 开关调用它
+func WriteBackendReport(w io.Writer) {
+	if err := ensureKataONNX(); err != nil {
+		fmt.Fprintf(w, "NN backend init failed: %v\n", err)
+	}
+	fmt.Fprintln(w, GetBackendReport())
+}
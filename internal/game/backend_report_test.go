@@ -0,0 +1,113 @@
+package game
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWarmUpSessionCounts(t *testing.T) {
+	calls := 0
+	run := func() error { calls++; time.Sleep(time.Millisecond); return nil }
+	first, total, steady, err := warmUpSession(run, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 4 {
+		t.Fatalf("3 轮热身 + 1 轮稳态应调用 4 次，实际 %d", calls)
+	}
+	if first <= 0 || steady <= 0 || total < first {
+		t.Fatalf("耗时不合理: first=%v total=%v steady=%v", first, total, steady)
+	}
+
+	boom := errors.New("boom")
+	calls = 0
+	if _, _, _, err := warmUpSession(func() error { calls++; return boom }, 3); !errors.Is(err, boom) || calls != 1 {
+		t.Fatalf("首轮失败应立即返回: err=%v calls=%d", err, calls)
+	}
+}
+
+func TestBackendReportRecordsFailuresAndSuccess(t *testing.T) {
+	nnBackendMu.Lock()
+	saved := nnBackends
+	nnBackendMu.Unlock()
+	defer func() {
+		nnBackendMu.Lock()
+		nnBackends = saved
+		nnBackendMu.Unlock()
+	}()
+
+	noteNNFailure(nnSnapshot, "TensorRT", errors.New("no libnvinfer"))
+	setNNBackend(nnSnapshot, BackendSession{Provider: "DirectML", BatchSize: 8, WarmUp: 30 * time.Millisecond})
+
+	var got *BackendSession
+	for _, s := range GetBackendReport().Sessions {
+		if s.Name == nnSessionNames[nnSnapshot] {
+			s := s
+			got = &s
+		}
+	}
+	if got == nil {
+		t.Fatal("报告里缺少 snapshot 会话")
+	}
+	if got.Provider != "DirectML" || got.BatchSize != 8 || len(got.Failed) != 1 {
+		t.Fatalf("记录不符: %+v", *got)
+	}
+	out := got.String()
+	for _, want := range []string{"DirectML", "fp32", "batch=8", "skipped TensorRT: no libnvinfer"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("输出缺少 %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestProviderFP16(t *testing.T) {
+	for p, want := range map[string]bool{"TensorRT": true, "CoreML": true, "CUDA": false, "DirectML": false, "CPU": false} {
+		if providerFP16(p) != want {
+			t.Errorf("%s: fp16=%v, want %v", p, !want, want)
+		}
+	}
+}
+
+func TestCPUFallbackReason(t *testing.T) {
+	nnBackendMu.Lock()
+	saved := nnBackends
+	nnBackendMu.Unlock()
+	wasSettled := katagoSettled.Load()
+	defer func() {
+		nnBackendMu.Lock()
+		nnBackends = saved
+		nnBackendMu.Unlock()
+		katagoSettled.Store(wasSettled)
+	}()
+
+	katagoSettled.Store(false)
+	if _, _, settled := CPUFallback(); settled {
+		t.Fatal("初始化未结束时 settled 应为 false")
+	}
+
+	katagoSettled.Store(true)
+	nnBackendMu.Lock()
+	nnBackends[nnKata] = BackendSession{}
+	nnBackendMu.Unlock()
+	noteNNFailure(nnKata, "TensorRT", errors.New("LoadLibrary failed\nstack: "+strings.Repeat("x", 200)))
+	noteNNFailure(nnKata, "CUDA", errors.New("cudnn missing"))
+	setNNBackend(nnKata, BackendSession{Provider: "CPU"})
+
+	reason, fellBack, settled := CPUFallback()
+	if !settled || !fellBack {
+		t.Fatalf("应报告回退: fellBack=%v settled=%v", fellBack, settled)
+	}
+	if reason != "TensorRT: LoadLibrary failed" {
+		t.Fatalf("原因应取首个失败的第一行: %q", reason)
+	}
+
+	// 本来就只有 CPU（没有失败记录）不算回退
+	nnBackendMu.Lock()
+	nnBackends[nnKata] = BackendSession{Provider: "CPU"}
+	nnBackendMu.Unlock()
+	if _, fellBack, _ := CPUFallback(); fellBack {
+		t.Fatal("没有 GPU 失败记录时不应提示回退")
+	}
+}
@@ -0,0 +1,95 @@
+// internal/game/baseline.go
+package game
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+// 内置基线对手：不搜索、不用 NN，只用来做下限参照（锦标赛、测试、最低难度）。
+// 任何正常的搜索引擎都应稳定胜过它们；输给 greedy 说明评估或搜索出了问题。
+
+const (
+	BotRandom = "random" // 在合法着里均匀随机
+	BotGreedy = "greedy" // 取本步净增子最多的着法（感染数 + 克隆的 1 子），同分随机
+	// BotPerfect 按不跳跃的完全解走（见 solver.go），只用于 allowJump=false 的对局（见 CheckBaselineBot）；
+	// 空格多于 SolverMaxEmpties 时解不动，退回 greedy
+	BotPerfect = "perfect"
+)
+
+// BaselineBots 可选的基线名字，供命令行帮助使用
+var BaselineBots = []string{BotRandom, BotGreedy, BotPerfect}
+
+// IsBaselineBot name 是否是内置基线（空串不是）
+func IsBaselineBot(name string) bool {
+	return name == BotRandom || name == BotGreedy || name == BotPerfect
+}
+
+// CheckBaselineBot bot 是否可用于 allowJump 的对局：名字须是内置基线，perfect 只解不跳跃的对局
+func CheckBaselineBot(bot string, allowJump bool) error {
+	if !IsBaselineBot(bot) {
+		return fmt.Errorf("unknown baseline bot %q (want %v)", bot, BaselineBots)
+	}
+	if bot == BotPerfect && allowJump {
+		return fmt.Errorf("baseline bot %s solves only jump-free games; disable jumps", BotPerfect)
+	}
+	return nil
+}
+
+var (
+	perfectMu     sync.Mutex
+	perfectSolver = NewSolver() // 跨着法保留记忆表，同一盘棋后面几手几乎不用再算
+)
+
+// BaselineMove 按 bot 选一手；r 为 nil 时用全局随机源。无合法着返回 ok=false。
+// perfect 遇到 allowJump 报错：它的解不考虑对方随时可跳，不能当成最优着法
+func BaselineMove(bot string, b *Board, player CellState, allowJump bool, r *rand.Rand) (Move, bool, error) {
+	if err := CheckBaselineBot(bot, allowJump); err != nil {
+		return Move{}, false, err
+	}
+	moves := filterJumpsByFlag(b, player, GenerateMoves(b, player), allowJump)
+	if len(moves) == 0 {
+		return Move{}, false, nil
+	}
+	intn := rand.Intn
+	if r != nil {
+		intn = r.Intn
+	}
+
+	if bot == BotRandom {
+		return moves[intn(len(moves))], true, nil
+	}
+	if bot == BotPerfect {
+		perfectMu.Lock()
+		mv, _, ok, err := perfectSolver.BestMove(b, player)
+		perfectMu.Unlock()
+		if err == nil && ok {
+			return mv, true, nil
+		}
+		if !errors.Is(err, ErrTooManyEmpties) {
+			return Move{}, false, err
+		}
+	}
+
+	best, n := -1, 0
+	var pick Move
+	for _, mv := range moves {
+		gain := previewInfectedCount(b, mv, player)
+		if mv.IsClone() {
+			gain++
+		}
+		switch {
+		case gain > best:
+			best, n, pick = gain, 1, mv
+		case gain == best:
+			// 蓄水池抽样：同分着法等概率
+			n++
+			if intn(n) == 0 {
+				pick = mv
+			}
+		}
+	}
+	return pick, true, nil
+}
@@ -0,0 +1,55 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestBaselineBotsPlayLegalMoves(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, bot := range BaselineBots {
+		gs, allowJump := NewGameState(boardRadius), true
+		if bot == BotPerfect {
+			// perfect 只解不跳跃的对局，整盘的残局也解得很慢：放到小棋盘上
+			gs, _ = SmallBoardState(2)
+			allowJump = false
+		}
+		for ply := 0; ply < 200 && !gs.GameOver; ply++ {
+			mv, ok, err := BaselineMove(bot, gs.Board, gs.CurrentPlayer, allowJump, r)
+			if err != nil || !ok {
+				break
+			}
+			if _, _, err := gs.MakeMove(mv); err != nil {
+				t.Fatalf("%s 第 %d 手 %v 非法: %v", bot, ply, mv, err)
+			}
+		}
+	}
+	if _, _, err := BaselineMove("minimax", NewGameState(boardRadius).Board, PlayerA, true, r); err == nil {
+		t.Fatalf("未知 bot 应报错")
+	}
+	if _, _, err := BaselineMove(BotPerfect, NewGameState(boardRadius).Board, PlayerA, true, r); err == nil {
+		t.Fatalf("perfect 只解不跳跃的对局，allowJump 时应报错")
+	}
+}
+
+func TestGreedyTakesMostPieces(t *testing.T) {
+	// B 三子围着空格 (2,-2)，A 的 (1,-2) 克隆过去可吃 3 子，其余着法至多吃 1 子
+	cells := make([]CellState, CellCount(boardRadius))
+	set := func(c HexCoord, s CellState) { cells[IndexOf[c]] = s }
+	set(HexCoord{Q: 1, R: -2}, PlayerA)
+	set(HexCoord{Q: 3, R: -2}, PlayerB)
+	set(HexCoord{Q: 3, R: -3}, PlayerB)
+	set(HexCoord{Q: 2, R: -1}, PlayerB)
+	set(HexCoord{Q: -3, R: 3}, PlayerB)
+	b, err := BoardFromCells(cells)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mv, ok, err := BaselineMove(BotGreedy, b, PlayerA, true, rand.New(rand.NewSource(1)))
+	if err != nil || !ok {
+		t.Fatalf("greedy 没走出着法: %v", err)
+	}
+	if want := (HexCoord{Q: 2, R: -2}); mv.To != want || !mv.IsClone() {
+		t.Fatalf("greedy 走了 %v，期望克隆到 %v", mv, want)
+	}
+}
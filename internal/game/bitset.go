@@ -0,0 +1,67 @@
+// game/bitset.go
+package game
+
+import "math/bits"
+
+// CellMask 按格子下标置位的位集。BoardN 最多 127 格（半径 6），两个字装得下：
+// 下标 0..63 在第 0 个字，64 起在第 1 个字。半径 4 及以下的盘只用到下标 0..60，第 1 个字恒为 0。
+// 是值类型，可以直接比较、当 map 的 key。
+type CellMask [2]uint64
+
+// boardMask 全部 BoardN 格
+var boardMask = CellMask{^uint64(0), 1<<(BoardN-64) - 1}
+
+// cellBit 只含格子 i 的掩码
+func cellBit(i int) CellMask {
+	var m CellMask
+	m[i>>6] = 1 << uint(i&63)
+	return m
+}
+
+func (m CellMask) And(o CellMask) CellMask    { return CellMask{m[0] & o[0], m[1] & o[1]} }
+func (m CellMask) Or(o CellMask) CellMask     { return CellMask{m[0] | o[0], m[1] | o[1]} }
+func (m CellMask) AndNot(o CellMask) CellMask { return CellMask{m[0] &^ o[0], m[1] &^ o[1]} }
+
+// Not 补集（只在 BoardN 格以内）
+func (m CellMask) Not() CellMask { return boardMask.AndNot(m) }
+
+func (m CellMask) IsZero() bool { return m[0]|m[1] == 0 }
+
+// Count 置位的格子数
+func (m CellMask) Count() int { return bits.OnesCount64(m[0]) + bits.OnesCount64(m[1]) }
+
+// Has 是否含格子 i
+func (m CellMask) Has(i int) bool { return m[i>>6]&(1<<uint(i&63)) != 0 }
+
+// Cells 置位的下标，从小到大
+func (m CellMask) Cells() []int {
+	out := make([]int, 0, m.Count())
+	for !m.IsZero() {
+		out = append(out, m.pop())
+	}
+	return out
+}
+
+func (m *CellMask) set(i int)   { m[i>>6] |= 1 << uint(i&63) }
+func (m *CellMask) clear(i int) { m[i>>6] &^= 1 << uint(i&63) }
+
+// first 最低置位的下标；调用方保证非空
+func (m CellMask) first() int {
+	if m[0] != 0 {
+		return bits.TrailingZeros64(m[0])
+	}
+	return 64 + bits.TrailingZeros64(m[1])
+}
+
+// pop 清掉最低置位并返回它的下标；调用方保证非空。
+// 遍历写成 for m := x; !m.IsZero(); { i := m.pop() ... }，与单字的 m &= m-1 一样按下标从小到大
+func (m *CellMask) pop() int {
+	if m[0] != 0 {
+		i := bits.TrailingZeros64(m[0])
+		m[0] &= m[0] - 1
+		return i
+	}
+	i := bits.TrailingZeros64(m[1])
+	m[1] &= m[1] - 1
+	return 64 + i
+}
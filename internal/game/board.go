@@ -2,6 +2,7 @@
 package game
 
 import (
+	"fmt"
 	"sync"
 )
 
@@ -27,15 +28,74 @@ var Directions = []HexCoord{
 	{-1, 0}, {-1, 1}, {0, 1},
 }
 
+// 棋盘按最大半径 MaxBoardRadius 存：下标 0..60 是标准盘（半径 4）的 61 格，顺序与只有半径 4 时完全相同，
+// 模型输入、局面编码、zobrist 键都靠这个顺序；第 5 圈接在后面（61..90），第 6 圈再接在后面（91..126）。
+// 半径小于 MaxBoardRadius 的盘，盘外的格子一律是 Blocked，所以走法生成、评估、哈希都不用区分半径。
 const boardRadius = 4
-const BoardN = 1 + 3*boardRadius*(boardRadius+1) // 预先按 AllCoords(3) 的顺序编号
-// Board represents a hexagonal board of a given radius.
-// Coordinates satisfying |q| <= radius, |r| <= radius, |q+r| <= radius are valid.
+const BoardN = 1 + 3*MaxBoardRadius*(MaxBoardRadius+1)
+
+// DefaultBoardRadius 标准盘的半径
+const DefaultBoardRadius = boardRadius
+
+// MaxBoardRadius 可开的最大半径（127 格，位板见 CellMask）。
+// 神经网络的输入是 9×9 网格，只装得下半径 4；更大的盘一律走静态评估，见 FitsNN
+const MaxBoardRadius = 6
+
+// CellCount 半径 radius 的盘有多少格
+func CellCount(radius int) int { return 1 + 3*radius*(radius+1) }
+
+// CheckBoardRadius 检查 radius 能否开盘
+func CheckBoardRadius(radius int) error {
+	if radius < 1 || radius > MaxBoardRadius {
+		return fmt.Errorf("board radius %d unsupported: need 1..%d", radius, MaxBoardRadius)
+	}
+	return nil
+}
+
+// ActiveRadius 实际可下的半径：非障碍格离中心最远的距离（全是障碍时为 0）
+func ActiveRadius(b *Board) int { return MaxBoardRadius - int(b.cut) }
+
+// scanActiveRadius 逐格重算 ActiveRadius；只在障碍格变化时调用（setI）
+func (b *Board) scanActiveRadius() int {
+	r := 0
+	for i, s := range b.Cells {
+		if s != Blocked {
+			r = max(r, ringOf(CoordOf[i]))
+		}
+	}
+	return r
+}
+
+// spanRadius 遍历格子要覆盖的半径：不小于标准盘，可下的格子都在它以内
+func (b *Board) spanRadius() int { return max(boardRadius, ActiveRadius(b)) }
+
+// span 格子下标的上界：Cells[span:] 全是障碍，只看格子的循环可以到此为止
+func (b *Board) span() int { return CellCount(b.spanRadius()) }
+
+// onRim 格子 i 是否在实际可下的最外圈上（小半径的盘，外圈是 ActiveRadius 那一圈，不是存储的最外圈）
+func (b *Board) onRim(i int) bool { return ringOf(CoordOf[i]) == ActiveRadius(b) }
+
+// edgeMask 实际可下的最外圈的位掩码
+func (b *Board) edgeMask() CellMask {
+	ensurePrecomp()
+	return bbCache.rimMask[ActiveRadius(b)]
+}
+
+// ringOf c 离中心的距离
+func ringOf(c HexCoord) int { return max3(abs(c.Q), abs(c.R), abs(c.Q+c.R)) }
+
+// Board represents a hexagonal board stored at radius MaxBoardRadius.
+// Coordinates satisfying |q| <= radius, |r| <= radius, |q+r| <= radius are valid;
+// cells outside a smaller requested radius are Blocked (see NewBoard, ActiveRadius).
 type Board struct {
-	radius     int
+	cut        int8              // 盘外封掉的圈数：ActiveRadius = MaxBoardRadius - cut；零值即整盘，障碍格变化时由 setI 重算
 	Cells      [BoardN]CellState // 定长数组
 	hash       uint64
-	bitA, bitB uint64 // 新增：位掩码，加速评估
+	bitA, bitB CellMask     // 新增：位掩码，加速评估
+	reach      reachState   // 各方可落子范围，见 reach.go
+	eval       evalAcc      // 静态评估的增量项，见 eval_acc.go
+	lin        lineageState // 克隆上限规则下每颗子所属的脉，见 lineage.go
+	ids        pieceIDs     // 棋子编号，见 piece_id.go
 	LastMove   Move
 	LastMover  CellState
 	LastInfect int
@@ -45,7 +105,7 @@ var (
 	CoordOf   [BoardN]HexCoord // index -> 坐标
 	IndexOf   map[HexCoord]int // 坐标 -> index（仅入口/出口处用）
 	NeighI    [BoardN][]int    // 每个格子的 6 邻居下标
-	NeighMask [BoardN]uint64   // 每个格子的 6 邻居位掩码
+	NeighMask [BoardN]CellMask // 每个格子的 6 邻居位掩码
 	JumpI     [BoardN][]int    // 每个格子的跳跃可达下标（两格）
 	Coords    [BoardN]HexCoord
 )
@@ -56,28 +116,38 @@ var boardPool = sync.Pool{
 	},
 }
 
-var coordsCache = map[int][]HexCoord{} // 支持多半径
-var isOuterI [BoardN]bool
+var coordsCache = map[int][]HexCoord{} // 半径 < boardRadius 的子集，init 时填好，之后只读
 
 func init() {
 	IndexOf = make(map[HexCoord]int, BoardN)
 	i := 0
-	for q := -boardRadius; q <= boardRadius; q++ {
-		for r := -boardRadius; r <= boardRadius; r++ {
-			if abs(q)+abs(r)+abs(-q-r) <= 2*boardRadius {
+	// 先按老顺序排半径 4 以内的格子，再一圈一圈往外接，见 BoardN
+	for ring := boardRadius; ring <= MaxBoardRadius; ring++ {
+		for q := -ring; q <= ring; q++ {
+			for r := -ring; r <= ring; r++ {
 				c := HexCoord{q, r}
+				if ringOf(c) > ring || ring > boardRadius && ringOf(c) != ring {
+					continue
+				}
 				Coords[i] = c
 				IndexOf[c] = i
 				i++
 			}
 		}
 	}
+	for r := 0; r < boardRadius; r++ {
+		for _, c := range Coords {
+			if ringOf(c) <= r {
+				coordsCache[r] = append(coordsCache[r], c)
+			}
+		}
+	}
 }
 func initBoardTables() {
-	coords := AllCoords(boardRadius)
+	coords := AllCoords(MaxBoardRadius)
 	if len(coords) != BoardN {
 		// 保险：避免坐标枚举顺序变化导致 out-of-range
-		panic("AllCoords(boardRadius) size mismatch")
+		panic("AllCoords(MaxBoardRadius) size mismatch")
 	}
 	IndexOf = make(map[HexCoord]int, BoardN)
 	for i, c := range coords {
@@ -89,16 +159,11 @@ func initBoardTables() {
 
 		CoordOf[i] = c
 		IndexOf[c] = i
-		// 半径边界上的点就是外圈
-		if abs(c.Q) == boardRadius || abs(c.R) == boardRadius || abs(-c.Q-c.R) == boardRadius {
-			isOuterI[i] = true
-		}
-
 		for _, d := range Directions {
 			n := HexCoord{c.Q + d.Q, c.R + d.R}
 			if j, ok := IndexOf[n]; ok {
 				NeighI[i] = append(NeighI[i], j)
-				NeighMask[i] |= uint64(1) << uint(j)
+				NeighMask[i].set(j)
 			}
 		}
 		// 预计算跳跃：12 个方向（= 两步）
@@ -110,26 +175,35 @@ func initBoardTables() {
 		}
 	}
 }
+
+// AllCoords 半径 radius 以内的格子（按下标顺序）；radius >= boardRadius 时就是 Coords 的前 CellCount(radius) 个
 func AllCoords(radius int) []HexCoord {
-	if radius != boardRadius {
-		panic("unsupported radius")
+	if radius >= boardRadius && radius <= MaxBoardRadius {
+		return Coords[:CellCount(radius)]
+	}
+	if cs, ok := coordsCache[radius]; ok {
+		return cs
 	}
-	return Coords[:]
+	panic(fmt.Sprintf("AllCoords: unsupported radius %d", radius))
 }
 
-func acquireBoard(radius int) *Board {
+func acquireBoard() *Board {
 	b := boardPool.Get().(*Board)
-	b.radius = radius
 	// 清空棋盘 & hash & bitmask
 	for i := 0; i < BoardN; i++ {
 		b.Cells[i] = Empty
 	}
-	b.hash = 0
-	b.bitA = 0
-	b.bitB = 0
+	b.cut = 0
+	b.hash = zobristBlank
+	b.bitA = CellMask{}
+	b.bitB = CellMask{}
 	b.LastMove = Move{}
 	b.LastMover = Empty
 	b.LastInfect = 0
+	b.reach = reachState{}
+	b.eval = evalAcc{}
+	b.lin = lineageState{}
+	b.ids = pieceIDs{}
 	return b
 }
 func releaseBoard(b *Board) {
@@ -145,23 +219,25 @@ func (b *Board) set(c HexCoord, s CellState) {
 }
 
 // NewBoard creates and initializes a new board with the given radius.
+// NewBoard 空棋盘；radius < MaxBoardRadius 时盘外的格子置为 Blocked（见 BoardN）
 func NewBoard(radius int) *Board {
-	if radius != boardRadius {
-		panic("NewBoard: radius must match boardRadius (4)")
+	if err := CheckBoardRadius(radius); err != nil {
+		panic("NewBoard: " + err.Error())
 	}
-	b := &Board{radius: radius}
+	b := &Board{hash: zobristBlank} // 存储总是整张 BoardN 格，radius 只决定哪些格子封成 Blocked
 	for i := 0; i < BoardN; i++ {
 		b.Cells[i] = Empty
+		if ringOf(CoordOf[i]) > radius {
+			b.setI(i, Blocked)
+		}
 	}
 	return b
 }
 
 // InBounds returns true if coord c is within the board's radius.
+// 半径 4 及以下的盘按标准盘的范围算（盘外的格子是障碍，但仍在界内），见 spanRadius
 func (b *Board) InBounds(c HexCoord) bool {
-	if abs(c.Q) > b.radius || abs(c.R) > b.radius || abs(-c.Q-c.R) > b.radius {
-		return false
-	}
-	return true
+	return ringOf(c) <= b.spanRadius()
 }
 
 // Get returns the cell state at coord c. If out of bounds, returns Blocked.
@@ -175,19 +251,22 @@ func (b *Board) setI(i int, s CellState) {
 	// 增量维护 zobrist
 	b.hash ^= zobKeyI(i, prev)
 	// 增量维护 bitmask
-	mask := uint64(1) << uint(i)
 	if prev == PlayerA {
-		b.bitA &= ^mask
+		b.bitA.clear(i)
 	} else if prev == PlayerB {
-		b.bitB &= ^mask
+		b.bitB.clear(i)
 	}
 
 	b.Cells[i] = s
 	b.hash ^= zobKeyI(i, s)
 	if s == PlayerA {
-		b.bitA |= mask
+		b.bitA.set(i)
 	} else if s == PlayerB {
-		b.bitB |= mask
+		b.bitB.set(i)
+	}
+	b.trackCell(i, prev, s)
+	if prev == Blocked || s == Blocked {
+		b.cut = int8(MaxBoardRadius - b.scanActiveRadius())
 	}
 }
 
@@ -203,8 +282,9 @@ func (b *Board) Neighbors(c HexCoord) []HexCoord {
 	return result
 }
 
+// AllCoords 下标在 span 以内的格子（含盘内外的障碍）
 func (b *Board) AllCoords() []HexCoord {
-	return AllCoords(b.radius)
+	return AllCoords(b.spanRadius())
 }
 
 // AllCoords returns a slice of all coordinates on the board.
@@ -225,11 +305,16 @@ func abs(x int) int {
 }
 
 func (b *Board) Clone() *Board {
-	nb := acquireBoard(b.radius)
+	nb := acquireBoard()
 	nb.Cells = b.Cells // Direct array copy
+	nb.cut = b.cut
 	nb.hash = b.hash
 	nb.bitA = b.bitA
 	nb.bitB = b.bitB
+	nb.reach = b.reach
+	nb.eval = b.eval
+	nb.lin = b.lin
+	nb.ids = b.ids
 	nb.LastMove = b.LastMove
 
 	nb.LastMover = b.LastMover
@@ -243,8 +328,9 @@ func (b *Board) ApplyMoveWithUndo(m Move, player CellState) (infected int, undo
 	// 将坐标映射为下标（board 初始化时已填好 indexOf）
 	from, okFrom := IndexOf[m.From]
 	to, okTo := IndexOf[m.To]
-	if !okTo {
-		// 非法坐标；对于已合法化的走法生成器，这里理论上不会触发
+	if !okTo || !okFrom {
+		// 非法坐标；对于已合法化的走法生成器，这里理论上不会触发。
+		// 起点不在盘上时也不能落子，否则跳跃会凭空多出一个子
 		return 0, func() {}
 	}
 
@@ -264,20 +350,20 @@ func (b *Board) ApplyMoveWithUndo(m Move, player CellState) (infected int, undo
 		// 增量维护 zobrist
 		b.hash ^= zobKeyI(i, prev)
 		// 增量维护 bitmask
-		mask := uint64(1) << uint(i)
 		if prev == PlayerA {
-			b.bitA &= ^mask
+			b.bitA.clear(i)
 		} else if prev == PlayerB {
-			b.bitB &= ^mask
+			b.bitB.clear(i)
 		}
 
 		b.Cells[i] = s
 		b.hash ^= zobKeyI(i, s)
 		if s == PlayerA {
-			b.bitA |= mask
+			b.bitA.set(i)
 		} else if s == PlayerB {
-			b.bitB |= mask
+			b.bitB.set(i)
 		}
+		b.trackCell(i, prev, s)
 
 		changed = append(changed, change{i: i, prev: prev})
 	}
@@ -294,13 +380,15 @@ func (b *Board) ApplyMoveWithUndo(m Move, player CellState) (infected int, undo
 		setI(to, player)
 	}
 
-	// —— 邻居感染：把 to 的 6 邻居中属于对手的翻为我方 —— //
-	for _, j := range NeighI[to] {
-		if b.Cells[j] == opp {
-			setI(j, player)
-			infected++
-		}
+	// —— 邻居感染：把 to 的 6 邻居中属于对手的翻为我方（连锁规则下继续向外传） —— //
+	opBit := b.bitB
+	if opp == PlayerA {
+		opBit = b.bitA
 	}
+	forEachInfection(to, opBit, func(_, j, _ int) {
+		setI(j, player)
+		infected++
+	})
 
 	// 撤销函数：按相反顺序恢复所有被改格
 	undo = func() {
@@ -311,20 +399,20 @@ func (b *Board) ApplyMoveWithUndo(m Move, player CellState) (infected int, undo
 				// 增量维护 zobrist
 				b.hash ^= zobKeyI(c.i, cur)
 				// 增量维护 bitmask
-				mask := uint64(1) << uint(c.i)
 				if cur == PlayerA {
-					b.bitA &= ^mask
+					b.bitA.clear(c.i)
 				} else if cur == PlayerB {
-					b.bitB &= ^mask
+					b.bitB.clear(c.i)
 				}
 
 				b.Cells[c.i] = c.prev
 				b.hash ^= zobKeyI(c.i, c.prev)
 				if c.prev == PlayerA {
-					b.bitA |= mask
+					b.bitA.set(c.i)
 				} else if c.prev == PlayerB {
-					b.bitB |= mask
+					b.bitB.set(c.i)
 				}
+				b.trackCell(c.i, cur, c.prev)
 			}
 		}
 	}
@@ -337,15 +425,19 @@ func (b *Board) Hash() uint64 {
 	return b.hash
 }
 
-// CountPieces 统计棋盘上 pl 方棋子数量
+// CountPieces 统计棋盘上 pl 方棋子数量（也可以数 Empty、Blocked；Blocked 含盘外的格子）
 func (b *Board) CountPieces(pl CellState) int {
-	n := 0
-	for i := 0; i < BoardN; i++ { 
-		if b.Cells[i] == pl {
-			n++
-		}
+	switch pl {
+	case PlayerA:
+		return b.bitA.Count()
+	case PlayerB:
+		return b.bitB.Count()
+	case Blocked:
+		return b.reach.blocked.Count()
+	case Empty:
+		return b.EmptyMask().Count()
 	}
-	return n
+	return 0
 }
 
 func (b *Board) ToFeatureInto(side CellState, dst []float32) []float32 {
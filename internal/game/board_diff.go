@@ -0,0 +1,21 @@
+// game/board_diff.go
+package game
+
+// CellChange 单个格子的前后状态
+type CellChange struct {
+	Coord HexCoord
+	Old   CellState
+	New   CellState
+}
+
+// Diff 返回从 b 变到 other 时状态有变化的格子，按格子下标顺序。
+// 联机用它核对双方局面（见 internal/net 的 state 消息），界面用它从提交前后的局面统一驱动动画。
+func (b *Board) Diff(other *Board) []CellChange {
+	var out []CellChange
+	for i := 0; i < BoardN; i++ {
+		if b.Cells[i] != other.Cells[i] {
+			out = append(out, CellChange{Coord: CoordOf[i], Old: b.Cells[i], New: other.Cells[i]})
+		}
+	}
+	return out
+}
@@ -0,0 +1,37 @@
+package game
+
+import "testing"
+
+func TestBoardDiffRoundTrip(t *testing.T) {
+	gs := NewGameState(boardRadius)
+	before := gs.Board.Clone()
+
+	// 走几步，制造克隆、跳跃与感染
+	for i := 0; i < 6 && !gs.GameOver; i++ {
+		mvs := GenerateMoves(gs.Board, gs.CurrentPlayer)
+		gs.MakeMove(mvs[len(mvs)-1])
+	}
+
+	d := before.Diff(gs.Board)
+	if len(d) == 0 {
+		t.Fatalf("走子后 Diff 不应为空")
+	}
+	for _, c := range d {
+		if c.Old == c.New {
+			t.Fatalf("Diff 含未变化的格子: %+v", c)
+		}
+	}
+
+	changed := 0
+	for i := range before.Cells {
+		if before.Cells[i] != gs.Board.Cells[i] {
+			changed++
+		}
+	}
+	if len(d) != changed {
+		t.Fatalf("Diff 给出 %d 格，实际变化 %d 格", len(d), changed)
+	}
+	if len(gs.Board.Diff(gs.Board.Clone())) != 0 {
+		t.Fatalf("相同局面 Diff 应为空")
+	}
+}
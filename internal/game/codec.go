@@ -0,0 +1,141 @@
+// game/codec.go
+package game
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// 紧凑二进制格式：每格 2 bit（CellState 本身就是 0..3）。标准盘（可下的格子都在半径 4 以内）用 v1，
+// 只存前 61 格 = 16 字节，之后的格子读回来一律是 Blocked；半径 5、6 的盘用 v2，多一个字节记半径：
+//   Board:     v1 [1][16B cells] = 17 字节；v2 [2][r][cells]，r=5 共 2+23 = 25 字节，r=6 共 2+32 = 34 字节
+//   GameState: Board 之后再加 [side|winner|blocked|over] 一个字节（18 / 26 / 35 字节）
+// PositionString 是它的 base64 形式：联机（internal/net 的 game/state 消息）、引擎协议的 position、
+// 录像的开局与逐手局面、analyze/curriculum 导出的习题都用它。置换表落盘只存哈希，不存局面。
+
+const (
+	codecVersion     = 1 // 标准盘，61 格
+	codecVersionWide = 2 // 半径 5、6，头里多一个半径字节
+)
+
+// cellBytes n 格打包后的字节数
+func cellBytes(n int) int { return (n*2 + 7) / 8 }
+
+// packBoard 版本头 + 格子，末尾再留 extra 个字节给调用方
+func packBoard(b *Board, extra int) []byte {
+	r := b.spanRadius()
+	var out []byte
+	if r <= boardRadius {
+		out = []byte{codecVersion}
+	} else {
+		out = []byte{codecVersionWide, byte(r)}
+	}
+	n, off := CellCount(r), len(out)
+	out = append(out, make([]byte, cellBytes(n)+extra)...)
+	for i := 0; i < n; i++ {
+		out[off+(i>>2)] |= byte(b.Cells[i]&3) << uint((i&3)*2)
+	}
+	return out
+}
+
+// unpackBoard 解出 data 里的棋盘，data 末尾应恰好多 extra 个字节；返回这些字节的起点
+func unpackBoard(data []byte, extra int, b *Board) (int, error) {
+	if len(data) == 0 {
+		return 0, errors.New("codec: empty data")
+	}
+	var n, off int
+	switch data[0] {
+	case codecVersion:
+		n, off = CellCount(boardRadius), 1
+	case codecVersionWide:
+		if len(data) < 2 || data[1] <= boardRadius || data[1] > MaxBoardRadius {
+			return 0, errors.New("codec: invalid board radius")
+		}
+		n, off = CellCount(int(data[1])), 2
+	default:
+		return 0, fmt.Errorf("codec: unsupported version %d", data[0])
+	}
+	if want := off + cellBytes(n) + extra; len(data) != want {
+		return 0, fmt.Errorf("codec: got %d bytes, want %d", len(data), want)
+	}
+	*b = Board{hash: zobristBlank}
+	for i := 0; i < BoardN; i++ {
+		s := Blocked
+		if i < n {
+			s = CellState(data[off+(i>>2)]>>uint((i&3)*2)) & 3
+		}
+		b.setI(i, s)
+	}
+	b.resetLineage() // 脉号、棋子编号都不进编码，载入后重新分配
+	b.resetPieceIDs()
+	return off + cellBytes(n), nil
+}
+
+// MarshalBinary 实现 encoding.BinaryMarshaler（只含格子状态）
+func (b *Board) MarshalBinary() ([]byte, error) {
+	return packBoard(b, 0), nil
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler；hash/位板重新计算，LastMove 等清零
+func (b *Board) UnmarshalBinary(data []byte) error {
+	_, err := unpackBoard(data, 0, b)
+	return err
+}
+
+func (gs *GameState) MarshalBinary() ([]byte, error) {
+	if gs.Board == nil {
+		return nil, errors.New("codec: nil board")
+	}
+	out := packBoard(gs.Board, 1)
+	meta := byte(gs.CurrentPlayer&3) | byte(gs.Winner&3)<<2 | byte(gs.BlockedPlayer&3)<<4
+	if gs.GameOver {
+		meta |= 1 << 6
+	}
+	out[len(out)-1] = meta
+	return out, nil
+}
+
+func (gs *GameState) UnmarshalBinary(data []byte) error {
+	b := &Board{}
+	end, err := unpackBoard(data, 1, b)
+	if err != nil {
+		return err
+	}
+	meta := data[end]
+	cur := CellState(meta & 3)
+	if cur != PlayerA && cur != PlayerB {
+		return fmt.Errorf("codec: invalid side to move %d", cur)
+	}
+	// 与 NewGameState 一致：开局时 XOR 进去的行棋方键之后不再随换手变化
+	b.hash ^= zobristSide[sideIdx(PlayerA)]
+
+	*gs = GameState{
+		Board:         b,
+		CurrentPlayer: cur,
+		Winner:        CellState(meta>>2) & 3,
+		BlockedPlayer: CellState(meta>>4) & 3,
+		GameOver:      meta&(1<<6) != 0,
+	}
+	gs.updateScores()
+	return nil
+}
+
+// PositionString 把局面编码成可分享的 base64 串（URL 安全、无填充）
+func (gs *GameState) PositionString() string {
+	data, _ := gs.MarshalBinary()
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParsePosition 解析 PositionString 的结果
+func ParsePosition(s string) (*GameState, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("codec: %w", err)
+	}
+	gs := &GameState{}
+	if err := gs.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return gs, nil
+}
@@ -0,0 +1,47 @@
+package game
+
+import "testing"
+
+func TestGameStateBinaryRoundTrip(t *testing.T) {
+	for _, b := range RandomBoards(50, boardRadius) {
+		gs := &GameState{Board: b, CurrentPlayer: PlayerB}
+		gs.updateScores()
+
+		data, err := gs.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary: %v", err)
+		}
+		if len(data) > 20 {
+			t.Fatalf("编码过长: %d 字节", len(data))
+		}
+
+		got, err := ParsePosition(gs.PositionString())
+		if err != nil {
+			t.Fatalf("ParsePosition: %v", err)
+		}
+		if got.Board.Cells != b.Cells || got.CurrentPlayer != PlayerB ||
+			got.ScoreA != gs.ScoreA || got.ScoreB != gs.ScoreB {
+			t.Fatalf("往返不一致")
+		}
+		if got.Board.Hash() != b.Hash() {
+			t.Fatalf("哈希不一致: %x vs %x", got.Board.Hash(), b.Hash())
+		}
+	}
+}
+
+func TestBoardBinaryRejectsBadInput(t *testing.T) {
+	b := NewGameState(boardRadius).Board
+	data, _ := b.MarshalBinary()
+
+	var nb Board
+	if err := nb.UnmarshalBinary(data); err != nil || nb.Cells != b.Cells {
+		t.Fatalf("Board 往返失败: %v", err)
+	}
+	if err := nb.UnmarshalBinary(data[:5]); err == nil {
+		t.Fatalf("长度错误应报错")
+	}
+	data[0] = 99
+	if err := nb.UnmarshalBinary(data); err == nil {
+		t.Fatalf("版本错误应报错")
+	}
+}
@@ -0,0 +1,46 @@
+// game/commentary.go
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 给观众看的逐手解说：把 MoveEvents 的事件流翻成一句话，例如
+//
+//	White jumps (2,0) -> (0,1), converting 3 red pieces -- evaluation swings +34
+//
+// 评估用 EvaluateStatic（走子方视角，走前走后之差）：不碰 NN，回放、录像、对战工具里现算也不卡，
+// 同一局面每次结果一样，导出的解说和回放时重新生成的一致。basicfont 只有 ASCII，所以不用破折号。
+
+var commentSide = map[CellState]string{PlayerA: "Red", PlayerB: "White"}
+
+// CommentMove player 在 b 上走 mv 的一行解说（不改 b）；走不了的着法返回空串
+func CommentMove(b *Board, player CellState, mv Move) string {
+	after := b.Clone()
+	defer releaseBoard(after)
+	if _, err := mv.Apply(after, player); err != nil {
+		return ""
+	}
+	events := MoveEvents(b, player, mv)
+	var sb strings.Builder
+	verb := "clones"
+	if events[0].Kind == PieceJump {
+		verb = "jumps"
+	}
+	fmt.Fprintf(&sb, "%s %s (%d,%d) -> (%d,%d)", commentSide[player], verb, mv.From.Q, mv.From.R, mv.To.Q, mv.To.R)
+
+	if flips := events[1:]; len(flips) > 0 {
+		noun := "pieces"
+		if len(flips) == 1 {
+			noun = "piece"
+		}
+		fmt.Fprintf(&sb, ", converting %d %s %s", len(flips), strings.ToLower(commentSide[Opponent(player)]), noun)
+		if waves := flips[len(flips)-1].Wave + 1; waves > 1 {
+			fmt.Fprintf(&sb, " in %d waves", waves)
+		}
+	}
+
+	fmt.Fprintf(&sb, " -- evaluation swings %+d", EvaluateStatic(after, player)-EvaluateStatic(b, player))
+	return sb.String()
+}
@@ -0,0 +1,35 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommentMove(t *testing.T) {
+	withRules(t, Rules{})
+	b, mv := chainBoard(t)
+	hash := b.Hash()
+	got := CommentMove(b, PlayerA, mv)
+	if !strings.HasPrefix(got, "Red clones (0,0) -> (1,0), converting 1 white piece -- evaluation swings +") {
+		t.Fatalf("解说: %q", got)
+	}
+	if b.Hash() != hash {
+		t.Fatal("CommentMove 不应改盘")
+	}
+
+	jump := Move{From: HexCoord{Q: 3, R: 0}, To: HexCoord{Q: 1, R: 0}}
+	if got := CommentMove(b, PlayerB, jump); !strings.HasPrefix(got, "White jumps (3,0) -> (1,0), converting 1 red piece -- evaluation swings ") {
+		t.Fatalf("解说: %q", got)
+	}
+	if got := CommentMove(b, PlayerA, Move{From: HexCoord{Q: 9, R: 9}, To: mv.To}); got != "" {
+		t.Fatalf("盘外走法应为空串: %q", got)
+	}
+	if got := CommentMove(b, PlayerA, Move{From: HexCoord{Q: 2, R: 0}, To: mv.To}); got != "" {
+		t.Fatalf("不是自己的子应为空串: %q", got)
+	}
+
+	withRules(t, Rules{Cascade: true})
+	if got := CommentMove(b, PlayerA, mv); !strings.Contains(got, "converting 3 white pieces in 3 waves -- evaluation swings +") {
+		t.Fatalf("连锁解说: %q", got)
+	}
+}
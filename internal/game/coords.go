@@ -0,0 +1,122 @@
+// game/coords.go
+package game
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 坐标系。内部一律用轴坐标 HexCoord{Q, R}（中心为原点），其余坐标系只在输入输出时换算：
+//
+//	axial   "q,r"      轴坐标
+//	cube    "x,y,z"    立方坐标，x=q、z=r、y=-q-r，三者之和为 0
+//	offset  "col,row"  平顶六边形的 odd-q 偏移坐标（列 = q，奇数列下沉半格），同样以中心为原点，
+//	                   与棋盘画面的行列一致：同一行的格子 row 相同
+//
+// 立方坐标有三个分量，任何坐标系下都能直接认出来；两个分量的按调用方指定的坐标系解释。
+
+// CoordSystem 坐标系
+type CoordSystem int
+
+const (
+	CoordAxial CoordSystem = iota
+	CoordCube
+	CoordOffset
+)
+
+// CoordSystemNames 可选的坐标系名，供命令行帮助使用
+var CoordSystemNames = []string{"axial", "cube", "offset"}
+
+func (s CoordSystem) String() string {
+	if s >= 0 && int(s) < len(CoordSystemNames) {
+		return CoordSystemNames[s]
+	}
+	return fmt.Sprintf("CoordSystem(%d)", int(s))
+}
+
+// ParseCoordSystem 解析坐标系名；空串视为 axial
+func ParseCoordSystem(name string) (CoordSystem, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "axial":
+		return CoordAxial, nil
+	case "cube":
+		return CoordCube, nil
+	case "offset", "odd-q":
+		return CoordOffset, nil
+	}
+	return CoordAxial, fmt.Errorf("未知坐标系 %q（可选 %v）", name, CoordSystemNames)
+}
+
+// Cube 立方坐标
+type Cube struct {
+	X, Y, Z int
+}
+
+// Offset odd-q 偏移坐标
+type Offset struct {
+	Col, Row int
+}
+
+// Cube 轴坐标 → 立方坐标
+func (c HexCoord) Cube() Cube { return Cube{X: c.Q, Y: -c.Q - c.R, Z: c.R} }
+
+// Offset 轴坐标 → odd-q 偏移坐标
+func (c HexCoord) Offset() Offset {
+	return Offset{Col: c.Q, Row: c.R + (c.Q-c.Q&1)/2}
+}
+
+// Axial 立方坐标 → 轴坐标；三个分量之和不为 0 时报错
+func (c Cube) Axial() (HexCoord, error) {
+	if c.X+c.Y+c.Z != 0 {
+		return HexCoord{}, fmt.Errorf("cube %d,%d,%d: x+y+z must be 0", c.X, c.Y, c.Z)
+	}
+	return HexCoord{Q: c.X, R: c.Z}, nil
+}
+
+// Axial odd-q 偏移坐标 → 轴坐标
+func (o Offset) Axial() HexCoord {
+	return HexCoord{Q: o.Col, R: o.Row - (o.Col-o.Col&1)/2}
+}
+
+// FormatCoord 按坐标系 sys 写出 c
+func FormatCoord(c HexCoord, sys CoordSystem) string {
+	switch sys {
+	case CoordCube:
+		k := c.Cube()
+		return fmt.Sprintf("%d,%d,%d", k.X, k.Y, k.Z)
+	case CoordOffset:
+		o := c.Offset()
+		return fmt.Sprintf("%d,%d", o.Col, o.Row)
+	}
+	return fmt.Sprintf("%d,%d", c.Q, c.R)
+}
+
+// ParseCoord 解析 FormatCoord 的输出并换成轴坐标。三个分量的总按立方坐标解释，
+// 两个分量的按 sys（CoordCube 时要求三个分量）。只检查格式，不检查是否在盘上。
+func ParseCoord(s string, sys CoordSystem) (HexCoord, error) {
+	parts := strings.Split(s, ",")
+	v := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return HexCoord{}, fmt.Errorf("coord %q: %w", s, err)
+		}
+		v[i] = n
+	}
+	switch {
+	case len(v) == 3:
+		c, err := Cube{X: v[0], Y: v[1], Z: v[2]}.Axial()
+		if err != nil {
+			return HexCoord{}, fmt.Errorf("coord %q: %w", s, err)
+		}
+		return c, nil
+	case len(v) != 2:
+		return HexCoord{}, fmt.Errorf("coord %q: want 2 or 3 components", s)
+	case sys == CoordCube:
+		return HexCoord{}, fmt.Errorf("coord %q: cube coordinates need 3 components", s)
+	case sys == CoordOffset:
+		return Offset{Col: v[0], Row: v[1]}.Axial(), nil
+	}
+	return HexCoord{Q: v[0], R: v[1]}, nil
+}
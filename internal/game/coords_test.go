@@ -0,0 +1,62 @@
+package game
+
+import "testing"
+
+func TestCoordSystemsRoundTrip(t *testing.T) {
+	for _, sys := range []CoordSystem{CoordAxial, CoordCube, CoordOffset} {
+		seen := map[string]bool{}
+		for i := 0; i < BoardN; i++ {
+			c := CoordOf[i]
+			s := FormatCoord(c, sys)
+			if seen[s] {
+				t.Fatalf("%v: %q 重复", sys, s)
+			}
+			seen[s] = true
+			got, err := ParseCoord(s, sys)
+			if err != nil || got != c {
+				t.Fatalf("%v: %v → %q → %v %v", sys, c, s, got, err)
+			}
+		}
+	}
+}
+
+func TestCubeAndOffset(t *testing.T) {
+	c := HexCoord{Q: 3, R: -1}
+	if k := c.Cube(); k != (Cube{X: 3, Y: -2, Z: -1}) {
+		t.Fatalf("立方坐标 %+v", k)
+	}
+	// 立方坐标三个分量，axial 下也认得
+	if got, err := ParseCoord("3,-2,-1", CoordAxial); err != nil || got != c {
+		t.Fatalf("立方坐标解析: %v %v", got, err)
+	}
+	if _, err := ParseCoord("1,1,1", CoordAxial); err == nil {
+		t.Fatal("x+y+z != 0 应报错")
+	}
+	if _, err := ParseCoord("1,1", CoordCube); err == nil {
+		t.Fatal("cube 坐标系下两个分量应报错")
+	}
+	// odd-q：奇数列下沉半格，(1,0) 与 (0,0) 同一行，(-1,0) 的行号比 (0,0) 小 1
+	for _, tc := range []struct {
+		c HexCoord
+		o Offset
+	}{
+		{HexCoord{Q: 0, R: 0}, Offset{0, 0}},
+		{HexCoord{Q: 1, R: 0}, Offset{1, 0}},
+		{HexCoord{Q: -1, R: 0}, Offset{-1, -1}},
+		{HexCoord{Q: 2, R: -1}, Offset{2, 0}},
+		{HexCoord{Q: -4, R: 4}, Offset{-4, 2}},
+	} {
+		if o := tc.c.Offset(); o != tc.o || o.Axial() != tc.c {
+			t.Fatalf("%v: 偏移坐标 %+v，应为 %+v", tc.c, o, tc.o)
+		}
+	}
+	for _, name := range CoordSystemNames {
+		sys, err := ParseCoordSystem(name)
+		if err != nil || sys.String() != name {
+			t.Fatalf("%q: %v %v", name, sys, err)
+		}
+	}
+	if _, err := ParseCoordSystem("hex"); err == nil {
+		t.Fatal("未知坐标系应报错")
+	}
+}
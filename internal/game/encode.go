@@ -1,18 +1,40 @@
 // internal/game/encode.go
 package game
 
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// 所有神经网络的输入特征都在这里编码。不同模型吃的平面含义不同，按 FeatureSet 区分版本：
+// 训练数据与模型必须用同一版本，新增编码只追加新版本，不要改已有版本的平面含义。
+
 const (
 	GridSize  = 9 // 把 (-4..4, -4..4) 映射到 9×9
-	PlaneCnt  = 3 // [我方, 对方, Blocked]
+	PlaneCnt  = 3 // FeaturesGrid3: [我方, 对方, Blocked]
 	TensorLen = PlaneCnt * GridSize * GridSize
+
+	gridArea = GridSize * GridSize
+
+	katagoPlanes  = 22
+	katagoGlobals = 19
+
+	nnCells = 1 + 3*boardRadius*(boardRadius+1) // 网格装得下的格子：棋盘下标 0..60
 )
 
+// ErrBoardTooLargeForNN 盘开到半径 5、6，9×9 的网络输入装不下
+var ErrBoardTooLargeForNN = errors.New("board radius too large for NN input")
+
+// FitsNN b 能否喂给神经网络：可下的格子都在半径 4 以内。
+// 装不下时所有网络入口返回 ErrBoardTooLargeForNN，搜索、提示改用静态评估（见 useNNFor）
+func FitsNN(b *Board) bool { return ActiveRadius(b) <= boardRadius }
+
 var (
-	// 预计算表
-	boardIndexToGrid [BoardN]int               // 37 -> 0..80
-	gridInBoard      [GridSize * GridSize]bool // 81 -> in radius-3?
-	gridAxial        [GridSize * GridSize]HexCoord
-	encodeTablesInit bool
+	// 预计算表（init 中 initBoardTables 之后生成，此后只读）
+	boardIndexToGrid [BoardN]int        // 61 -> 0..80；半径 5、6 的格子网格里没有，为 -1
+	gridInBoard      [gridArea]bool     // 81 -> 是否在半径 4 棋盘内
+	gridAxial        [gridArea]HexCoord // 81 -> 轴坐标
 )
 
 // 在 initBoardTables() 之后调用一次
@@ -26,68 +48,247 @@ func initEncodeTables() {
 			c := HexCoord{Q: q, R: r}
 			gridAxial[idx] = c
 			// 边长为5，意味着半径为4。判断标准：|q|<=4, |r|<=4, |q+r|<=4
-			in := abs(q) <= 4 && abs(r) <= 4 && abs(-q-r) <= 4
-			gridInBoard[idx] = in
+			gridInBoard[idx] = abs(q) <= 4 && abs(r) <= 4 && abs(-q-r) <= 4
 			idx++
 		}
 	}
 	// 2) 棋盘下标 -> 网格下标
 	for i := 0; i < BoardN; i++ {
-		c := CoordOf[i] 
-		x := c.Q + 4
-		r := c.R + 4
-		g := r*GridSize + x
-		boardIndexToGrid[i] = g
+		boardIndexToGrid[i] = -1
+		if i < nnCells {
+			boardIndexToGrid[i] = AxialToIndex(CoordOf[i])
+		}
 	}
-	encodeTablesInit = true
 }
 
-// EncodeBoardTensor 把棋盘即时编码成 [243]float32 张量
-// plane 0: 我方, plane 1: 对方, plane 2: Blocked(非棋盘区域)
-func EncodeBoardTensor(b *Board, me CellState) [TensorLen]float32 {
-	if !encodeTablesInit {
-		// 防御：确保预表已初始化（正常应在程序启动时就调用 initEncodeTables）
-		initEncodeTables()
+// AxialToIndex 把落子坐标映射到 0..80 的 move 索引；9×9 网格以外的坐标（半径 5、6 的盘才有）返回 -1
+func AxialToIndex(c HexCoord) int {
+	if abs(c.Q) > 4 || abs(c.R) > 4 {
+		return -1
 	}
+	return (c.R+4)*GridSize + (c.Q + 4)
+}
+
+// FeatureSet 特征编码版本
+type FeatureSet int
+
+const (
+	// FeaturesGrid3 自博弈训练数据（X.bin / samplefmt）：我方 / 对方 / Blocked（棋盘外与盘内障碍）
+	FeaturesGrid3 FeatureSet = iota + 1
+	// FeaturesCNN3 onnx_infer 的 3 平面 CNN 与 PVModel：我方 / 对方 / 棋盘内掩码
+	FeaturesCNN3
+	// FeaturesKataV7 KataGo 网络：22 个空间平面 + 19 个全局特征，支持“已选中起点”的两段式走子
+	FeaturesKataV7
+	// FeaturesCNN3Grid 同 FeaturesCNN3，但我方/对方平面也按 9×9 网格摆放（与掩码平面对齐）；给新训练的 3 平面模型用
+	FeaturesCNN3Grid
+)
+
+// FeatureEncoder 描述一种编码的形状并执行编码
+type FeatureEncoder struct {
+	Set        FeatureSet
+	Name       string
+	Planes     int
+	Globals    int      // 全局特征个数；0 = 无
+	PlaneNames []string // 只列出有含义的平面，KataGo 保留的空平面不列
+
+	encode func(b *Board, me CellState, spatial, global []float32, selectedIdx int)
+}
 
+var featureEncoders = map[FeatureSet]*FeatureEncoder{
+	FeaturesGrid3: {
+		Set: FeaturesGrid3, Name: "grid3", Planes: PlaneCnt,
+		PlaneNames: []string{"mine", "opponent", "off_board"},
+		encode: func(b *Board, me CellState, spatial, _ []float32, _ int) {
+			encodeGrid3(b, me, spatial)
+		},
+	},
+	FeaturesCNN3: {
+		Set: FeaturesCNN3, Name: "cnn3", Planes: 3,
+		PlaneNames: []string{"mine", "opponent", "in_board"},
+		encode: func(b *Board, me CellState, spatial, _ []float32, _ int) {
+			encodeBoard(b, me, spatial)
+		},
+	},
+	FeaturesKataV7: {
+		Set: FeaturesKataV7, Name: "katago_v7", Planes: katagoPlanes, Globals: katagoGlobals,
+		PlaneNames: []string{"ones", "mine", "opponent", "blocked", "selected"},
+		encode:     encodeKataInputs,
+	},
+	FeaturesCNN3Grid: {
+		Set: FeaturesCNN3Grid, Name: "cnn3_grid", Planes: 3,
+		PlaneNames: []string{"mine", "opponent", "in_board"},
+		encode: func(b *Board, me CellState, spatial, _ []float32, _ int) {
+			encodeCNN3Grid(b, me, spatial)
+		},
+	},
+}
+
+// Encoder 按版本取编码器
+func Encoder(set FeatureSet) (*FeatureEncoder, error) {
+	if e := featureEncoders[set]; e != nil {
+		return e, nil
+	}
+	return nil, fmt.Errorf("unknown feature set %d", set)
+}
+
+// SpatialLen 空间输入长度 Planes×9×9
+func (e *FeatureEncoder) SpatialLen() int { return e.Planes * gridArea }
+
+// Encode 把 b 以 me 视角写入 spatial（长度 SpatialLen）与 global（长度 Globals，无全局特征时可传 nil）。
+// selectedIdx 为已选中起点的 9×9 索引，-1 表示未选中；只有 KataGo 编码使用。
+func (e *FeatureEncoder) Encode(b *Board, me CellState, spatial, global []float32, selectedIdx int) error {
+	if len(spatial) != e.SpatialLen() || len(global) != e.Globals {
+		return fmt.Errorf("%s: buffers %d/%d, want %d/%d", e.Name, len(spatial), len(global), e.SpatialLen(), e.Globals)
+	}
+	if !FitsNN(b) {
+		return fmt.Errorf("%s: %w", e.Name, ErrBoardTooLargeForNN)
+	}
+	e.encode(b, me, spatial, global, selectedIdx)
+	return nil
+}
+
+// EncodeBoardTensor 把棋盘即时编码成 [243]float32 张量（FeaturesGrid3）；调用方保证 FitsNN，盘外的格子不编码
+func EncodeBoardTensor(b *Board, me CellState) [TensorLen]float32 {
 	var t [TensorLen]float32
-	const plane = GridSize * GridSize
+	encodeGrid3(b, me, t[:])
+	return t
+}
 
-	// 先把非棋盘区域标记到 Blocked 平面
-	for g := 0; g < GridSize*GridSize; g++ {
-		if !gridInBoard[g] {
-			t[2*plane+g] = 1
+// encodeGrid3 plane 0: 我方, plane 1: 对方, plane 2: Blocked(非棋盘区域与盘内障碍)
+func encodeGrid3(b *Board, me CellState, dst []float32) {
+	clear(dst)
+	for g, in := range gridInBoard {
+		if !in {
+			dst[2*gridArea+g] = 1
 		}
 	}
+	opp := Opponent(me)
+	for i := 0; i < nnCells; i++ {
+		g := boardIndexToGrid[i]
+		switch b.Cells[i] {
+		case me:
+			dst[g] = 1
+		case opp:
+			dst[gridArea+g] = 1
+		case Blocked:
+			dst[2*gridArea+g] = 1
+		}
+	}
+}
 
+// encodeBoard 把 Board 编成 3×9×9：my=1 / opp=1 / mask=1（FeaturesCNN3）。
+// 注意：我方/对方平面按棋盘下标 0..nnCells-1 摆放，只有掩码平面按 9×9 网格；现有 3 平面模型就是这样训练的，不能改
+func encodeBoard(b *Board, me CellState, dst []float32) {
+	clear(dst)
+	offMy, offOpp, offMask := 0, gridArea, 2*gridArea
+	for g, in := range gridInBoard {
+		if in {
+			dst[offMask+g] = 1
+		}
+	}
 	opp := Opponent(me)
+	for i := 0; i < nnCells; i++ {
+		switch b.Cells[i] {
+		case me:
+			dst[offMy+i] = 1
+		case opp:
+			dst[offOpp+i] = 1
+		}
+	}
+}
 
-	// 遍历棋盘 37 格：把棋子映射到对应网格位
-	for i := 0; i < BoardN; i++ {
-		s := b.Cells[i]
-		if s == Empty {
-			continue // 空格子：三平面都 0
+// encodeCNN3Grid FeaturesCNN3Grid：三个平面都按 9×9 网格
+func encodeCNN3Grid(b *Board, me CellState, dst []float32) {
+	clear(dst)
+	offMy, offOpp, offMask := 0, gridArea, 2*gridArea
+	for g, in := range gridInBoard {
+		if in {
+			dst[offMask+g] = 1
 		}
+	}
+	opp := Opponent(me)
+	for i := 0; i < nnCells; i++ {
 		g := boardIndexToGrid[i]
-
-		// 在棋盘内的格子不是 Blocked
-		// 非棋盘的 Blocked 已在上面设置；棋盘内默认是 0
-		// 只需根据棋子设置我方/对方平面：
-		switch s {
+		switch b.Cells[i] {
 		case me:
-			t[g] = 1 // plane 0
+			dst[offMy+g] = 1
 		case opp:
-			t[plane+g] = 1 // plane 1
-		case Blocked:
-			// 如果你的棋盘内部不会出现 Blocked，可忽略
-			t[2*plane+g] = 1
+			dst[offOpp+g] = 1
 		}
 	}
-	return t
 }
 
-// AxialToIndex 把落子坐标映射到 0..80 的 move 索引
-// 仍然保留直接计算，或用 gridAxial 反查也行
-func AxialToIndex(c HexCoord) int {
-	return (c.R+4)*GridSize + (c.Q + 4)
+// KataGo 编码的固定部分：Plane 0 全 1，Plane 3 为棋盘外 + 开局固定障碍
+var (
+	staticSpatialOnce sync.Once
+	staticSpatial     []float32
+)
+
+// kataFixedBlocks 训练 KataGo 模型时盘内固定障碍物 (来自 state.go)；Plane 3 只认这三格，不读棋盘上的 Blocked
+var kataFixedBlocks = []HexCoord{{1, 0}, {-1, 1}, {0, -1}}
+
+func ensureStaticSpatial() {
+	staticSpatialOnce.Do(func() {
+		staticSpatial = make([]float32, katagoPlanes*gridArea)
+		for g := 0; g < gridArea; g++ {
+			staticSpatial[g] = 1.0
+			if !gridInBoard[g] {
+				staticSpatial[3*gridArea+g] = 1.0
+			}
+		}
+		for _, c := range kataFixedBlocks {
+			if idx, ok := IndexOf[c]; ok {
+				staticSpatial[3*gridArea+boardIndexToGrid[idx]] = 1.0
+			}
+		}
+	})
+}
+
+// encodeKataInputs FeaturesKataV7。
+// Plane 1/2: 我方/对方，Plane 4: 已选中起点；Global 0: 处于选子后的第二阶段，Global 9: 恒为 1
+func encodeKataInputs(b *Board, me CellState, spatial []float32, global []float32, selectedIdx int) {
+	ensureStaticSpatial()
+	copy(spatial, staticSpatial)
+	clear(global)
+
+	// 使用位掩码加速特征提取
+	myBit, opBit := b.bitA, b.bitB
+	if me != PlayerA {
+		myBit, opBit = b.bitB, b.bitA
+	}
+	for m := myBit; !m.IsZero(); {
+		spatial[gridArea+boardIndexToGrid[m.pop()]] = 1.0
+	}
+	for m := opBit; !m.IsZero(); {
+		spatial[2*gridArea+boardIndexToGrid[m.pop()]] = 1.0
+	}
+
+	if selectedIdx >= 0 {
+		if selectedIdx < gridArea {
+			spatial[4*gridArea+selectedIdx] = 1.0
+		}
+		global[0] = 1.0
+	}
+	global[9] = 1.0
+}
+
+// DecodeBoardTensor 是 EncodeBoardTensor 的逆：我方记为 PlayerA、对方记为 PlayerB。
+// 用于离线分析训练数据；张量来自外部文件，格式不对时报错。
+func DecodeBoardTensor(t []float32) (*Board, error) {
+	if len(t) != TensorLen {
+		return nil, fmt.Errorf("tensor has %d values, want %d", len(t), TensorLen)
+	}
+	cells := make([]CellState, nnCells)
+	for i := 0; i < nnCells; i++ {
+		g := boardIndexToGrid[i]
+		switch {
+		case t[g] > 0.5:
+			cells[i] = PlayerA
+		case t[gridArea+g] > 0.5:
+			cells[i] = PlayerB
+		case t[2*gridArea+g] > 0.5:
+			cells[i] = Blocked
+		}
+	}
+	return BoardFromCells(cells)
 }
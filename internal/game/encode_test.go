@@ -0,0 +1,150 @@
+package game
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDecodeBoardTensorRoundTrip(t *testing.T) {
+	gs := NewGameState(boardRadius)
+	gs.MakeMove(GenerateMoves(gs.Board, PlayerA)[0])
+
+	// 以 B 视角编码：解码后 B 的子应出现在 PlayerA 位置
+	tensor := EncodeBoardTensor(gs.Board, PlayerB)
+	b, err := DecodeBoardTensor(tensor[:])
+	if err != nil {
+		t.Fatalf("DecodeBoardTensor: %v", err)
+	}
+	for i := 0; i < nnCells; i++ {
+		want := gs.Board.Cells[i]
+		switch want {
+		case PlayerA:
+			want = PlayerB
+		case PlayerB:
+			want = PlayerA
+		}
+		if b.Cells[i] != want {
+			t.Fatalf("格子 %v: 得到 %v，期望 %v", CoordOf[i], b.Cells[i], want)
+		}
+	}
+	if _, err := DecodeBoardTensor(tensor[:10]); err == nil {
+		t.Fatalf("长度不对时应报错")
+	}
+}
+
+// planeAt 取 9×9 平面上坐标 c 处的值
+func planeAt(t []float32, plane int, c HexCoord) float32 {
+	return t[plane*gridArea+AxialToIndex(c)]
+}
+
+func planeSum(t []float32, plane int) int {
+	n := 0
+	for _, v := range t[plane*gridArea : (plane+1)*gridArea] {
+		n += int(v)
+	}
+	return n
+}
+
+func TestEncodersOnInitialPosition(t *testing.T) {
+	b := NewGameState(boardRadius).Board
+	blocks := b.CountPieces(Blocked) - (BoardN - nnCells) // 盘内障碍：半径 5、6 的格子都是障碍，不进网格
+	a1, b1 := HexCoord{Q: 4, R: 0}, HexCoord{Q: -4, R: 0} // A/B 初始角
+	center := HexCoord{Q: 1, R: 0}                        // 盘内障碍
+
+	t.Run("grid3", func(t *testing.T) {
+		x := EncodeBoardTensor(b, PlayerB)
+		if planeAt(x[:], 0, b1) != 1 || planeAt(x[:], 1, a1) != 1 || planeAt(x[:], 0, a1) != 0 {
+			t.Fatalf("B 视角下我方/对方平面不对")
+		}
+		if planeSum(x[:], 0) != 3 || planeSum(x[:], 1) != 3 {
+			t.Fatalf("棋子数: 我方 %d 对方 %d，期望 3/3", planeSum(x[:], 0), planeSum(x[:], 1))
+		}
+		if got, want := planeSum(x[:], 2), gridArea-nnCells+blocks; got != want {
+			t.Fatalf("Blocked 平面 %d 格，期望 %d", got, want)
+		}
+		if planeAt(x[:], 2, center) != 1 || planeAt(x[:], 2, HexCoord{Q: 4, R: 4}) != 1 {
+			t.Fatalf("盘内障碍 (1,0) 与棋盘外 (4,4) 都应标 Blocked")
+		}
+	})
+
+	// cnn3 的我方/对方平面按棋盘下标摆放（现有 3 平面模型就是这样训练的），这里把下标写死，改了布局会立刻发现
+	t.Run("cnn3", func(t *testing.T) {
+		e, _ := Encoder(FeaturesCNN3)
+		x := make([]float32, e.SpatialLen())
+		if err := e.Encode(b, PlayerA, x, nil, -1); err != nil {
+			t.Fatal(err)
+		}
+		for plane, want := range [][]int{{4, 26, 60}, {0, 34, 56}} {
+			var got []int
+			for i, v := range x[plane*gridArea : (plane+1)*gridArea] {
+				if v != 0 {
+					got = append(got, i)
+				}
+			}
+			if !slices.Equal(got, want) {
+				t.Fatalf("平面 %d 非零位置 %v，期望 %v", plane, got, want)
+			}
+		}
+		if planeSum(x, 2) != nnCells || planeAt(x, 2, center) != 1 || planeAt(x, 2, HexCoord{Q: 4, R: 4}) != 0 {
+			t.Fatalf("掩码平面应恰好覆盖 %d 个盘内格（含障碍）", nnCells)
+		}
+	})
+
+	t.Run("cnn3_grid", func(t *testing.T) {
+		e, _ := Encoder(FeaturesCNN3Grid)
+		x := make([]float32, e.SpatialLen())
+		if err := e.Encode(b, PlayerA, x, nil, -1); err != nil {
+			t.Fatal(err)
+		}
+		if planeAt(x, 0, a1) != 1 || planeAt(x, 1, b1) != 1 || planeSum(x, 0) != 3 || planeSum(x, 1) != 3 {
+			t.Fatalf("我方/对方平面不对")
+		}
+		if planeSum(x, 2) != nnCells || planeAt(x, 2, center) != 1 || planeAt(x, 2, HexCoord{Q: 4, R: 4}) != 0 {
+			t.Fatalf("掩码平面应恰好覆盖 %d 个盘内格（含障碍）", nnCells)
+		}
+	})
+
+	t.Run("katago_v7", func(t *testing.T) {
+		e, _ := Encoder(FeaturesKataV7)
+		x := make([]float32, e.SpatialLen())
+		g := make([]float32, e.Globals)
+		sel := AxialToIndex(a1)
+		if err := e.Encode(b, PlayerA, x, g, sel); err != nil {
+			t.Fatal(err)
+		}
+		if planeSum(x, 0) != gridArea {
+			t.Fatalf("Plane 0 应全 1")
+		}
+		if planeAt(x, 1, a1) != 1 || planeAt(x, 2, b1) != 1 || planeSum(x, 1) != 3 || planeSum(x, 2) != 3 {
+			t.Fatalf("我方/对方平面不对")
+		}
+		if got, want := planeSum(x, 3), gridArea-nnCells+len(kataFixedBlocks); got != want {
+			t.Fatalf("Plane 3 %d 格，期望 %d", got, want)
+		}
+		if planeSum(x, 4) != 1 || x[4*gridArea+sel] != 1 || g[0] != 1 || g[9] != 1 {
+			t.Fatalf("选中起点应写入 Plane 4 与 Global 0")
+		}
+
+		// 复用缓冲区再编码未选中的局面，不应残留上一次的选中信息
+		if err := e.Encode(b, PlayerA, x, g, -1); err != nil {
+			t.Fatal(err)
+		}
+		if planeSum(x, 4) != 0 || g[0] != 0 || g[9] != 1 {
+			t.Fatalf("未选中时 Plane 4 与 Global 0 应清零")
+		}
+	})
+}
+
+func TestEncoderBufferCheck(t *testing.T) {
+	e, err := Encoder(FeaturesKataV7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := NewGameState(boardRadius).Board
+	if err := e.Encode(b, PlayerA, make([]float32, TensorLen), make([]float32, e.Globals), -1); err == nil {
+		t.Fatalf("空间缓冲区长度不对时应报错")
+	}
+	if _, err := Encoder(FeatureSet(99)); err == nil {
+		t.Fatalf("未知版本应报错")
+	}
+}
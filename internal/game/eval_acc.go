@@ -0,0 +1,70 @@
+// internal/game/eval_acc.go
+package game
+
+// 静态评估的增量累加器：子数差随每次改格（trackCell）加减，叶子上不用再扫 61 格；
+// 外圈子数差随盘的实际半径而变（见 Board.edgeMask），评估时用位板两次 popcount 求出。
+// 紧三角块数取决于连通分量，改一格可能合并或拆开分量，不做增量：只记哪一方的子变过，
+// 评估时才对变过的一方重算（triangleBlocks）。makeMove 把走子前的累加器存进 undoInfo，
+// UnmakeMove 原样还原，退回父节点时缓存的三角数仍然有效。
+//
+// 零值就是空棋盘的值；拷贝棋盘时要一并拷贝（与 reachState 相同）。
+// 重算会写缓存，同一块棋盘不能在多个协程里同时评估（搜索各自克隆，本来也不共享）。
+
+const (
+	triDirtyA = 1 << iota // A 方的子变过，triA 待重算
+	triDirtyB
+)
+
+// evalAcc 以 A 方视角记的差值
+type evalAcc struct {
+	pieceDiff  int // A 子数 - B 子数
+	triA, triB int // 缓存的紧三角块数
+	triDirty   uint8
+}
+
+// update 格子 i 从 prev 变成 s
+func (a *evalAcc) update(i int, prev, s CellState) {
+	a.add(i, prev, -1)
+	a.add(i, s, +1)
+}
+
+func (a *evalAcc) add(i int, c CellState, sign int) {
+	switch c {
+	case PlayerA:
+		a.triDirty |= triDirtyA
+	case PlayerB:
+		a.triDirty |= triDirtyB
+		sign = -sign
+	default:
+		return
+	}
+	a.pieceDiff += sign
+}
+
+// triangleBlocks 双方含紧三角的连通块数，按需重算变过的一方
+func (b *Board) triangleBlocks() (triA, triB int) {
+	a := &b.eval
+	if a.triDirty != 0 {
+		ensurePrecomp()
+		if a.triDirty&triDirtyA != 0 {
+			a.triA = countTriangleBlocksBB(b.bitA)
+		}
+		if a.triDirty&triDirtyB != 0 {
+			a.triB = countTriangleBlocksBB(b.bitB)
+		}
+		a.triDirty = 0
+	}
+	return a.triA, a.triB
+}
+
+// staticTerms player 视角的子数差、外圈差、紧三角差（未乘权重）
+func (b *Board) staticTerms(player CellState) (piece, edge, tri int) {
+	triA, triB := b.triangleBlocks()
+	rim := b.edgeMask()
+	edge = b.bitA.And(rim).Count() - b.bitB.And(rim).Count()
+	piece, tri = b.eval.pieceDiff, triA-triB
+	if player == PlayerB {
+		return -piece, -edge, -tri
+	}
+	return piece, edge, tri
+}
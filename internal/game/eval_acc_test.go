@@ -0,0 +1,121 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// evaluateStaticFull 逐格扫描的静态评估（增量累加器之前的写法），用来核对增量版
+func evaluateStaticFull(b *Board, player CellState) int {
+	op := Opponent(player)
+	myCnt, opCnt, myEdge, opEdge := 0, 0, 0, 0
+	for i := 0; i < BoardN; i++ {
+		switch b.Cells[i] {
+		case player:
+			myCnt++
+			if b.onRim(i) {
+				myEdge++
+			}
+		case op:
+			opCnt++
+			if b.onRim(i) {
+				opEdge++
+			}
+		}
+	}
+	tri := countTriangleBlocks(b, player) - countTriangleBlocks(b, op)
+	return (myCnt-opCnt)*pieceW + (myEdge-opEdge)*edgeW + tri*triW + securedScore(b, player)
+}
+
+func checkEvalAcc(t *testing.T, b *Board, where string) {
+	t.Helper()
+	for _, side := range []CellState{PlayerA, PlayerB} {
+		want := evaluateStaticFull(b, side)
+		if got := EvaluateStatic(b, side); got != want {
+			t.Fatalf("%s: EvaluateStatic(%v) = %d，逐格扫描 %d", where, side, got, want)
+		}
+		if got := EvaluateBitBoard(b, side); got != want {
+			t.Fatalf("%s: EvaluateBitBoard(%v) = %d，逐格扫描 %d", where, side, got, want)
+		}
+	}
+}
+
+// 随机对局里逐手走子：每手之后、每个候选着法的 make/unmake 前后，增量评估都与逐格扫描一致。
+// 标准盘与半径 6 的盘（格子下标跨过位板的第一个字）轮流下
+func testEvalAccRandomGames(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	for g := 0; g < 6; g++ {
+		st := NewGameState([]int{boardRadius, MaxBoardRadius}[g%2])
+		checkEvalAcc(t, st.Board, "开局")
+		for ply := 0; ply < 80 && !st.GameOver; ply++ {
+			moves := GenerateMoves(st.Board, st.CurrentPlayer)
+			if len(moves) == 0 {
+				break
+			}
+			for _, mv := range moves {
+				u := st.Board.makeMove(mv, st.CurrentPlayer)
+				checkEvalAcc(t, st.Board, "makeMove 后")
+				// 子节点上再走一层，撤销后缓存的三角数要换回子节点的
+				if replies := GenerateMoves(st.Board, Opponent(st.CurrentPlayer)); len(replies) > 0 {
+					u2 := st.Board.makeMove(replies[rng.Intn(len(replies))], Opponent(st.CurrentPlayer))
+					st.Board.UnmakeMove(u2)
+					checkEvalAcc(t, st.Board, "孙节点撤销后")
+				}
+				st.Board.UnmakeMove(u)
+				checkEvalAcc(t, st.Board, "UnmakeMove 后")
+			}
+			mv := moves[rng.Intn(len(moves))]
+			_, undo := st.Board.ApplyMoveWithUndo(mv, st.CurrentPlayer)
+			checkEvalAcc(t, st.Board, "ApplyMoveWithUndo 后")
+			undo()
+			checkEvalAcc(t, st.Board, "ApplyMoveWithUndo 撤销后")
+
+			if _, _, err := st.MakeMove(mv); err != nil {
+				t.Fatal(err)
+			}
+			checkEvalAcc(t, st.Board, "GameState.MakeMove 后")
+			cp := st.Board.Clone()
+			checkEvalAcc(t, cp, "Clone")
+			releaseBoard(cp)
+			parsed, err := ParsePosition(st.PositionString())
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkEvalAcc(t, parsed.Board, "ParsePosition")
+		}
+	}
+}
+
+func TestEvalAccMatchesFullScan(t *testing.T) {
+	t.Run("standard", func(t *testing.T) {
+		withRules(t, Rules{})
+		testEvalAccRandomGames(t)
+	})
+	t.Run("cascade", func(t *testing.T) {
+		withRules(t, Rules{Cascade: true})
+		testEvalAccRandomGames(t)
+	})
+}
+
+// 各半径盘外的障碍格不计入累加器
+func TestEvalAccSmallBoard(t *testing.T) {
+	for r := 1; r <= MaxBoardRadius; r++ {
+		checkEvalAcc(t, NewGameState(r).Board, "各半径开局")
+	}
+}
+
+func BenchmarkEvaluateStatic(b *testing.B) {
+	st := NewGameState(boardRadius)
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 12; i++ {
+		moves := GenerateMoves(st.Board, st.CurrentPlayer)
+		st.MakeMove(moves[rng.Intn(len(moves))])
+	}
+	moves := GenerateMoves(st.Board, st.CurrentPlayer)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		u := st.Board.makeMove(moves[i%len(moves)], st.CurrentPlayer)
+		EvaluateStatic(st.Board, PlayerA)
+		st.Board.UnmakeMove(u)
+	}
+}
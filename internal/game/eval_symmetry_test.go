@@ -0,0 +1,127 @@
+package game
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// 评估函数的对称性：交换颜色后换边评估应得到同一分数，自身视角与对手视角互为相反数；
+// 盘面经旋转/镜像后分数不变。任何一条不成立都会让自博弈数据偏向某一方或某个方向。
+
+// symTestBoards 从开局随机走若干步得到的一组局面（含开局本身）
+func symTestBoards(t *testing.T) []*Board {
+	t.Helper()
+	r := rand.New(rand.NewSource(4215))
+	out := []*Board{NewGameState(boardRadius).Board}
+	for g := 0; g < 8; g++ {
+		b := NewGameState(boardRadius).Board
+		side := PlayerA
+		for ply := 0; ply < 6+4*g; ply++ {
+			moves := GenerateMoves(b, side)
+			if len(moves) == 0 {
+				break
+			}
+			mMakeMoveWithUndo(b, moves[r.Intn(len(moves))], side)
+			side = Opponent(side)
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+// swapColors 返回 A、B 对调后的新盘
+func swapColors(t *testing.T, b *Board) *Board {
+	t.Helper()
+	cells := make([]CellState, BoardN)
+	for i, c := range b.Cells[:BoardN] {
+		switch c {
+		case PlayerA:
+			c = PlayerB
+		case PlayerB:
+			c = PlayerA
+		}
+		cells[i] = c
+	}
+	nb, err := BoardFromCells(cells)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return nb
+}
+
+// checkEvalSymmetry geometric=false 时只检查颜色对称
+func checkEvalSymmetry(t *testing.T, name string, eval func(*Board, CellState) int, geometric bool) {
+	t.Helper()
+	for n, b := range symTestBoards(t) {
+		a := eval(b, PlayerA)
+		if got := eval(b, PlayerB); got != -a {
+			t.Errorf("%s 局面 %d: A 视角 %d，B 视角 %d，应互为相反数", name, n, a, got)
+		}
+		if got := eval(swapColors(t, b), PlayerB); got != a {
+			t.Errorf("%s 局面 %d: 换色后 B 视角 %d，原 A 视角 %d", name, n, got, a)
+		}
+		if !geometric {
+			continue
+		}
+		for s := 1; s < numSymmetries; s++ {
+			if got := eval(TransformBoard(b, s), PlayerA); got != a {
+				t.Errorf("%s 局面 %d: 对称变换 %d 后 %d，原 %d", name, n, s, got, a)
+			}
+		}
+	}
+}
+
+func TestEvaluateStaticSymmetric(t *testing.T) {
+	checkEvalSymmetry(t, "EvaluateStatic", EvaluateStatic, true)
+}
+
+func TestEvaluateBitBoardSymmetric(t *testing.T) {
+	checkEvalSymmetry(t, "EvaluateBitBoard", EvaluateBitBoard, true)
+	for n, b := range symTestBoards(t) {
+		if s, bb := EvaluateStatic(b, PlayerA), EvaluateBitBoard(b, PlayerA); s != bb {
+			t.Errorf("局面 %d: EvaluateStatic %d 与 EvaluateBitBoard %d 不一致", n, s, bb)
+		}
+	}
+}
+
+func TestEvaluateNNSymmetric(t *testing.T) {
+	// 网络本身不具备旋转等变性，只在回退到位板评估（无模型/无 ORT）时检查几何对称。
+	// A/B 视角互为相反数对网络同样不严格成立（value 头独立输出胜负），只查换色。
+	b0 := NewGameState(boardRadius).Board
+	if _, err := KataValueScore(b0, PlayerA); err != nil {
+		t.Logf("KataGo 模型不可用，EvaluateNN 回退位板评估: %v", err)
+		checkEvalSymmetry(t, "EvaluateNN", EvaluateNN, true)
+		return
+	}
+	for n, b := range symTestBoards(t) {
+		if a, got := EvaluateNN(b, PlayerA), EvaluateNN(swapColors(t, b), PlayerB); a != got {
+			t.Errorf("EvaluateNN 局面 %d: 换色后 %d，原 %d", n, got, a)
+		}
+	}
+}
+
+// 同一局面换色后以对方视角编码，网络输入必须逐位相同
+func TestEncodersColorSymmetric(t *testing.T) {
+	for _, set := range []FeatureSet{FeaturesGrid3, FeaturesCNN3, FeaturesKataV7, FeaturesCNN3Grid} {
+		enc, err := Encoder(set)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encode := func(b *Board, me CellState) ([]float32, []float32) {
+			spatial := make([]float32, enc.SpatialLen())
+			global := make([]float32, enc.Globals)
+			if err := enc.Encode(b, me, spatial, global, -1); err != nil {
+				t.Fatal(err)
+			}
+			return spatial, global
+		}
+		for n, b := range symTestBoards(t) {
+			s1, g1 := encode(b, PlayerA)
+			s2, g2 := encode(swapColors(t, b), PlayerB)
+			if !slices.Equal(s1, s2) || !slices.Equal(g1, g2) {
+				t.Errorf("%s 局面 %d: 换色后编码不同", enc.Name, n)
+			}
+		}
+	}
+}
@@ -1,8 +1,6 @@
 // file: internal/game/evaluate.go
 package game
 
-import "math/bits"
-
 // 可调参数
 var (
 	cloneThresh = 0.25      // 克隆/跳跃阈值
@@ -45,9 +43,9 @@ func (h HexCoord) Add(o HexCoord) HexCoord {
 
 // ApplyPreview：在不修改棋盘的情况下预览感染数
 func (m Move) ApplyPreview(b *Board, player CellState) (infected int, ok bool) {
-	coords, undo := m.MakeMove(b, player)
-	b.UnmakeMove(undo)
-	return len(coords), true
+	u := b.makeMove(m, player)
+	b.UnmakeMove(u)
+	return u.infected.Count(), u.moved
 }
 
 // 对外导出
@@ -55,14 +53,6 @@ func (m Move) ApplyPreview(b *Board, player CellState) (infected int, ok bool) {
 //	return evaluateStatic(b, player)
 //}
 
-func isOuter(c HexCoord, _ int) bool {
-	idx, ok := IndexOf[c] // 你已有的“坐标 -> 下标”映射
-	if !ok {
-		return false
-	}
-	return isOuterI[idx]
-}
-
 //func outerRingCoords(b *Board) []HexCoord {
 //	var ring []HexCoord
 //	for _, c := range b.AllCoords() {
@@ -192,8 +182,21 @@ const (
 	triW      = 15 // “紧三角”差
 	mobilityW = 1  // 机动性（去重后的可走空位数）差
 	supportW  = 2  // 弱支撑惩罚（同色邻居≤1 的子数）差
+	securedW  = 8  // 已锁定空格差（己方单独封住或对手够不到的区域，见 regions.go），终局时这些格都会变成己方子
 )
 
+// securedMaxEmpties 空格多于此数时不算已锁定地盘：开局、中局几乎没有封住的区域，
+// 不值得在每个叶子上做一遍区域洪泛
+const securedMaxEmpties = 20
+
+// securedScore 已锁定地盘差 × securedW，只在残局计算；EvaluateStatic 与位板版共用
+func securedScore(b *Board, player CellState) int {
+	if b.EmptyMask().Count() > securedMaxEmpties {
+		return 0
+	}
+	return SecuredTerritory(b, player) * securedW
+}
+
 func mobilityCount(b *Board, side CellState) int {
 	vis := make([]bool, BoardN)
 	cnt := 0
@@ -236,47 +239,17 @@ func weakSupportCount(b *Board, side CellState) int {
 	return bad
 }
 
+// EvaluateStatic 静态评估：子数差、外圈差、紧三角差取自棋盘上的增量累加器（见 eval_acc.go），
+// 残局再加已锁定地盘差
 func EvaluateStatic(b *Board, player CellState) int {
-	op := Opponent(player)
-
-	// 子数差
-	myCnt, opCnt := 0, 0
-	for i := 0; i < BoardN; i++ {
-		if b.Cells[i] == player {
-			myCnt++
-		}
-		if b.Cells[i] == op {
-			opCnt++
-		}
-	}
-	pieceScore := (myCnt - opCnt) * pieceW
-
-	// 外圈差（差值！而不是只加我方）
-	myEdge, opEdge := 0, 0
-	for i := 0; i < BoardN; i++ {
-		if !isOuterI[i] {
-			continue
-		}
-		if b.Cells[i] == player {
-			myEdge++
-		}
-		if b.Cells[i] == op {
-			opEdge++
-		}
-	}
-	edgeScore := (myEdge - opEdge) * edgeW
-
-	// 紧三角差（你已有的 countTriangleBlocks）
-	myTri := countTriangleBlocks(b, player)
-	opTri := countTriangleBlocks(b, op)
-	triangleScore := (myTri - opTri) * triW
+	piece, edge, tri := b.staticTerms(player)
 
 	// 弱支撑差：我方“同色邻居≤1”的子越多越糟
 	//myWeak := weakSupportCount(b, player)
 	//opWeak := weakSupportCount(b, op)
 	//supportScore := (opWeak - myWeak) * supportW // 惩我方=负，惩对手=正
 
-	return pieceScore + edgeScore + triangleScore
+	return piece*pieceW + edge*edgeW + tri*triW + securedScore(b, player)
 }
 
 // “预览”一次感染数，而不实际修改棋盘
@@ -286,15 +259,15 @@ func previewInfectedCount(b *Board, mv Move, player CellState) int {
 		return 0
 	}
 	// 获取对手位掩码
-	var opBit uint64
+	var opBit CellMask
 	if player == PlayerA {
 		opBit = b.bitB
 	} else {
 		opBit = b.bitA
 	}
 
-	// 位运算：邻居掩码 & 对手掩码，然后计算 1 的个数
-	return bits.OnesCount64(NeighMask[to] & opBit)
+	// 位运算：邻居掩码 & 对手掩码（连锁规则下再向外扩散），然后计算 1 的个数
+	return infectMask(to, opBit).Count()
 }
 func addHex(a, b HexCoord) HexCoord { return HexCoord{Q: a.Q + b.Q, R: a.R + b.R} }
 
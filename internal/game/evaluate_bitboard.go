@@ -2,17 +2,18 @@
 package game
 
 import (
-	"math/bits"
+	"sort"
 	"sync"
 )
 
 // ---- 位板预计算缓存 ----
 
 type BitBoardCache struct {
-	edgeMask      uint64         // 外圈位掩码
-	neighMask     [BoardN]uint64 // 每格 6 邻居的汇总掩码
-	indexBit      [BoardN]uint64 // 1<<i 快速表
-	tightTriMasks []uint64       // 所有“紧三角”三元组的掩码（去重后）
+	rimMask       [MaxBoardRadius + 1]CellMask // rimMask[r]：离中心恰为 r 的一圈；盘的外圈取 ActiveRadius 那一圈
+	neighMask     [BoardN]CellMask             // 每格 6 邻居的汇总掩码
+	indexBit      [BoardN]CellMask             // 1<<i 快速表
+	tightTriMasks []CellMask                   // 所有“紧三角”三元组的掩码（去重后），只占低字的排在前面
+	tightTriLo    int                          // tightTriMasks 前多少个只占低字（下标 < 64）
 }
 
 var (
@@ -22,33 +23,28 @@ var (
 
 func ensurePrecomp() {
 	initOnce.Do(func() {
-		if BoardN > 64 {
-			panic("bitboard impl assumes BoardN <= 64 (R=4 -> 61)")
-		}
-
 		// indexBit
 		for i := 0; i < BoardN; i++ {
-			bbCache.indexBit[i] = 1 << uint(i)
+			bbCache.indexBit[i] = cellBit(i)
 		}
 
-		// 外圈掩码
+		// 各圈掩码
 		for i := 0; i < BoardN; i++ {
-			if isOuterI[i] {
-				bbCache.edgeMask |= bbCache.indexBit[i]
-			}
+			r := ringOf(CoordOf[i])
+			bbCache.rimMask[r].set(i)
 		}
 
 		// 邻居掩码
 		for i := 0; i < BoardN; i++ {
-			var m uint64
+			var m CellMask
 			for _, nb := range NeighI[i] {
-				m |= bbCache.indexBit[nb]
+				m.set(nb)
 			}
 			bbCache.neighMask[i] = m
 		}
 
 		// 紧三角：任意三点两两相邻（去重）
-		seen := make(map[uint64]struct{}, 256)
+		seen := make(map[CellMask]struct{}, 256)
 		for a := 0; a < BoardN; a++ {
 			for _, b := range NeighI[a] {
 				if b <= a {
@@ -59,7 +55,7 @@ func ensurePrecomp() {
 						continue
 					}
 					if isNeighborI(b, c) {
-						mask := bbCache.indexBit[a] | bbCache.indexBit[b] | bbCache.indexBit[c]
+						mask := bbCache.indexBit[a].Or(bbCache.indexBit[b]).Or(bbCache.indexBit[c])
 						if _, ok := seen[mask]; !ok {
 							seen[mask] = struct{}{}
 							bbCache.tightTriMasks = append(bbCache.tightTriMasks, mask)
@@ -68,66 +64,75 @@ func ensurePrecomp() {
 				}
 			}
 		}
+		// 半径 4 及以下的盘，子都在低字里：只占低字的三角排前面，那样的分量只查这一段
+		sort.SliceStable(bbCache.tightTriMasks, func(i, j int) bool {
+			return bbCache.tightTriMasks[i][1] == 0 && bbCache.tightTriMasks[j][1] != 0
+		})
+		for _, tri := range bbCache.tightTriMasks {
+			if tri[1] == 0 {
+				bbCache.tightTriLo++
+			}
+		}
 	})
 }
 
 // ---- 位板工具 ----
 
-func boardMasks(b *Board, player CellState) (my, op uint64) {
+func boardMasks(b *Board, player CellState) (my, op CellMask) {
 	if player == PlayerA {
 		return b.bitA, b.bitB
 	}
 	return b.bitB, b.bitA
 }
 
-func floodComponent(seed, mask uint64) uint64 {
+func floodComponent(seed, mask CellMask) CellMask {
 	comp := seed
 	frontier := seed
 
-	for frontier != 0 {
+	for !frontier.IsZero() {
 		// 批处理 frontier 的所有邻居
-		var nbAll uint64
-		f := frontier
-		for f != 0 {
-			lsb := f & -f
-			idx := bits.TrailingZeros64(lsb)
-			nbAll |= bbCache.neighMask[idx]
-			f &= f - 1 // 清最低位
+		var nbAll CellMask
+		for f := frontier; !f.IsZero(); {
+			nbAll = nbAll.Or(bbCache.neighMask[f.pop()])
 		}
 
-		next := (nbAll & mask) &^ comp
-		if next == 0 {
+		next := nbAll.And(mask).AndNot(comp)
+		if next.IsZero() {
 			break
 		}
-		comp |= next
+		comp = comp.Or(next)
 		frontier = next
 	}
 	return comp
 }
 
-func componentHasTightTriangle(comp uint64) bool {
+func componentHasTightTriangle(comp CellMask) bool {
 	// 快速剪枝：少于 3 个子不可能
-	if bits.OnesCount64(comp) < 3 {
+	if comp.Count() < 3 {
 		return false
 	}
-	for _, tri := range bbCache.tightTriMasks {
-		if comp&tri == tri {
+	tris := bbCache.tightTriMasks
+	if comp[1] == 0 {
+		tris = tris[:bbCache.tightTriLo]
+	}
+	for _, tri := range tris {
+		if comp.And(tri) == tri {
 			return true
 		}
 	}
 	return false
 }
 
-func countTriangleBlocksBB(mask uint64) int {
+func countTriangleBlocksBB(mask CellMask) int {
 	count := 0
 	remain := mask
-	for remain != 0 {
-		seed := remain & -remain
+	for !remain.IsZero() {
+		seed := bbCache.indexBit[remain.first()]
 		comp := floodComponent(seed, mask)
 		if componentHasTightTriangle(comp) {
 			count++
 		}
-		remain &^= comp // 去掉已处理分量
+		remain = remain.AndNot(comp) // 去掉已处理分量
 	}
 	return count
 }
@@ -139,14 +144,14 @@ func EvaluateBitBoard(b *Board, player CellState) int {
 
 	my, op := boardMasks(b, player)
 
-	pieceScore := (bits.OnesCount64(my) - bits.OnesCount64(op)) * pieceW
-	edgeScore := (bits.OnesCount64(my&bbCache.edgeMask) - bits.OnesCount64(op&bbCache.edgeMask)) * edgeW
+	pieceScore := (my.Count() - op.Count()) * pieceW
+	edge := b.edgeMask()
+	edgeScore := (my.And(edge).Count() - op.And(edge).Count()) * edgeW
 
-	myTri := countTriangleBlocksBB(my)
-	opTri := countTriangleBlocksBB(op)
-	triangleScore := (myTri - opTri) * triW
+	_, _, tri := b.staticTerms(player) // 紧三角数走累加器的缓存，见 eval_acc.go
+	triangleScore := tri * triW
 
-	return pieceScore + edgeScore + triangleScore
+	return pieceScore + edgeScore + triangleScore + securedScore(b, player)
 }
 
 // 控制每个执子方是否使用 ONNX 评估（默认开启 PlayerB 以供人机模式使用）。
@@ -155,8 +160,13 @@ var (
 	UseONNXForPlayerB = true
 )
 
+// useNNFor side 这一方在 b 上是否走神经网络：开关打开，且盘装得进网络输入（FitsNN）
+func useNNFor(b *Board, side CellState) bool {
+	return (side == PlayerA && UseONNXForPlayerA || side == PlayerB && UseONNXForPlayerB) && FitsNN(b)
+}
+
 func Evaluate(b *Board, player CellState) int {
-	if (player == PlayerA && UseONNXForPlayerA) || (player == PlayerB && UseONNXForPlayerB) {
+	if useNNFor(b, player) {
 		return EvaluateNN(b, player)
 	}
 	return EvaluateBitBoard(b, player)
@@ -0,0 +1,38 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestFlipsCountInfections(t *testing.T) {
+	r := rand.New(rand.NewSource(4252))
+	gs := NewGameState(boardRadius)
+	var want [BoardN]uint16
+	for ply := 0; ply < 60 && !gs.GameOver; ply++ {
+		moves := GenerateMoves(gs.Board, gs.CurrentPlayer)
+		infected, _, err := gs.MakeMove(moves[r.Intn(len(moves))])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range infected {
+			want[IndexOf[c]]++
+		}
+	}
+	if gs.Flips != want {
+		t.Fatalf("易手计数不对:\n got %v\nwant %v", gs.Flips, want)
+	}
+	top := 0
+	for i, n := range want {
+		if gs.FlipCount(CoordOf[i]) != int(n) {
+			t.Fatalf("FlipCount(%v) = %d，应为 %d", CoordOf[i], gs.FlipCount(CoordOf[i]), n)
+		}
+		top = max(top, int(n))
+	}
+	if top == 0 || gs.MaxFlips() != top {
+		t.Fatalf("MaxFlips = %d，应为 %d", gs.MaxFlips(), top)
+	}
+	if gs.FlipCount(HexCoord{Q: 9, R: 9}) != 0 {
+		t.Fatal("盘外格子应为 0")
+	}
+}
@@ -0,0 +1,84 @@
+// game/fog.go
+package game
+
+import "math/rand"
+
+// 迷雾变体：每方只看得见自己棋子距离 2 以内的格子（正好是自己能落子的范围）。
+// 障碍格和双方子数是公开信息；看不见的格子里对方子的位置未知。
+// 走子规则不变，所以这里只提供可见性与信息集（确定化采样）工具，由调用方决定何时遮挡。
+
+// VisibleMask side 看得见的格子：己方子、距离 2 以内的格子和障碍
+func VisibleMask(b *Board, side CellState) CellMask {
+	slot := reachSlot(side)
+	if slot < 0 {
+		return boardMask
+	}
+	own := b.bitA
+	if side == PlayerB {
+		own = b.bitB
+	}
+	return own.Or(b.reach.mask[slot]).Or(b.reach.blocked)
+}
+
+// Determinize 按 side 的视角抽一个与其所见一致的完整局面：
+// 可见格照抄，对方藏在迷雾里的子（总数已知）均匀随机放进看不见的格子。
+func Determinize(b *Board, side CellState, rng *rand.Rand) *Board {
+	visible := VisibleMask(b, side)
+	opBit := b.bitB
+	if side == PlayerB {
+		opBit = b.bitA
+	}
+	hiddenOpp := opBit.AndNot(visible).Count()
+
+	cells := make([]CellState, BoardN)
+	var hidden []int
+	for i := 0; i < BoardN; i++ {
+		if visible.Has(i) {
+			cells[i] = b.Cells[i]
+			continue
+		}
+		hidden = append(hidden, i)
+	}
+	opp := Opponent(side)
+	rng.Shuffle(len(hidden), func(i, j int) { hidden[i], hidden[j] = hidden[j], hidden[i] })
+	for _, i := range hidden[:hiddenOpp] {
+		cells[i] = opp
+	}
+	nb, err := BoardFromCells(cells)
+	if err != nil {
+		// 可见部分照抄自合法局面，不会走到这里；万一走到就退回完整局面
+		return b.Clone()
+	}
+	nb.LastMove, nb.LastMover, nb.LastInfect = b.LastMove, b.LastMover, b.LastInfect
+	return nb
+}
+
+// FogSearch 信息集搜索：对 samples 个确定化局面各调用一次 search，选得票最多的着法（平票取先出现的）。
+// side 的合法着法只落在可见范围内，所以每个样本给出的着法在真实局面上同样合法。
+func FogSearch(b *Board, side CellState, samples int, rng *rand.Rand, search func(*Board) (Move, bool)) (Move, bool) {
+	if samples < 1 {
+		samples = 1
+	}
+	votes := make(map[Move]int, samples)
+	var order []Move
+	for s := 0; s < samples; s++ {
+		mv, ok := search(Determinize(b, side, rng))
+		if !ok {
+			continue
+		}
+		if votes[mv] == 0 {
+			order = append(order, mv)
+		}
+		votes[mv]++
+	}
+	if len(order) == 0 {
+		return Move{}, false
+	}
+	best := order[0]
+	for _, mv := range order[1:] {
+		if votes[mv] > votes[best] {
+			best = mv
+		}
+	}
+	return best, true
+}
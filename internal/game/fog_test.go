@@ -0,0 +1,67 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestVisibleMask(t *testing.T) {
+	b := seeBoard(t, []HexCoord{{Q: -4, R: 0}}, []HexCoord{{Q: -3, R: 0}, {Q: 4, R: 0}})
+	vis := VisibleMask(b, PlayerA)
+	seen := func(c HexCoord) bool { return vis.Has(IndexOf[c]) }
+	if !seen(HexCoord{Q: -4, R: 0}) || !seen(HexCoord{Q: -3, R: 0}) || !seen(HexCoord{Q: -2, R: 0}) {
+		t.Fatal("己方子及距离 2 以内应可见")
+	}
+	if seen(HexCoord{Q: 4, R: 0}) || seen(HexCoord{Q: -1, R: 0}) {
+		t.Fatal("距离 3 以外不应可见")
+	}
+}
+
+// 确定化局面：可见部分不变，对方子总数不变，己方着法与真实局面一致
+func TestDeterminizeConsistent(t *testing.T) {
+	rng := rand.New(rand.NewSource(4233))
+	for n, b := range symTestBoards(t) {
+		for _, side := range []CellState{PlayerA, PlayerB} {
+			vis := VisibleMask(b, side)
+			want := GenerateMoves(b, side)
+			for k := 0; k < 4; k++ {
+				d := Determinize(b, side, rng)
+				for i := 0; i < BoardN; i++ {
+					if vis.Has(i) && d.Cells[i] != b.Cells[i] {
+						t.Fatalf("局面 %d: 可见格 %v 被改动", n, CoordOf[i])
+					}
+				}
+				opp := Opponent(side)
+				if d.CountPieces(opp) != b.CountPieces(opp) || d.CountPieces(side) != b.CountPieces(side) {
+					t.Fatalf("局面 %d: 子数不一致", n)
+				}
+				got := GenerateMoves(d, side)
+				if len(got) != len(want) {
+					t.Fatalf("局面 %d: 着法数 %d，期望 %d", n, len(got), len(want))
+				}
+			}
+		}
+	}
+}
+
+func TestFogSearchVotes(t *testing.T) {
+	b := NewGameState(boardRadius).Board
+	mvs := GenerateMoves(b, PlayerA)
+	calls := 0
+	mv, ok := FogSearch(b, PlayerA, 5, rand.New(rand.NewSource(1)), func(s *Board) (Move, bool) {
+		calls++
+		if VisibleMask(s, PlayerA).Count() != VisibleMask(b, PlayerA).Count() {
+			t.Fatal("样本的己方视野应与真实局面相同")
+		}
+		if calls%2 == 0 {
+			return mvs[0], true
+		}
+		return mvs[1], true
+	})
+	if !ok || calls != 5 || mv != mvs[1] {
+		t.Fatalf("应选得票最多的着法: %v ok=%v calls=%d", mv, ok, calls)
+	}
+	if _, ok := FogSearch(b, PlayerA, 3, rand.New(rand.NewSource(1)), func(*Board) (Move, bool) { return Move{}, false }); ok {
+		t.Fatal("所有样本都无着时应返回 ok=false")
+	}
+}
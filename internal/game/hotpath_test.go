@@ -0,0 +1,154 @@
+package game
+
+import (
+	"testing"
+)
+
+// 搜索热路径不分配、公开 API 遇到非法输入不 panic。
+// 作为库被别人调用时，这两条一旦回退就很难从外面看出来，所以单独钉住。
+
+func midgameBoard(t *testing.T) *Board {
+	t.Helper()
+	boards := symTestBoards(t)
+	return boards[len(boards)/2]
+}
+
+func TestHotPathNoAllocs(t *testing.T) {
+	b := midgameBoard(t)
+	buf := make([]Move, 0, 256)
+	mv := GenerateMoves(b, PlayerA)[0]
+	cases := []struct {
+		name string
+		fn   func()
+	}{
+		{"GenerateMovesInto", func() { buf = GenerateMovesInto(b, PlayerA, buf[:0]) }},
+		{"ForEachMove", func() { ForEachMove(b, PlayerB, func(Move) bool { return true }) }},
+		{"HasMoves", func() { b.HasMoves(PlayerB) }},
+		{"EvaluateBitBoard", func() { EvaluateBitBoard(b, PlayerA) }},
+		{"SecuredTerritory", func() { SecuredTerritory(b, PlayerB) }},
+		{"leafEval", func() { leafEval(b, PlayerA, PlayerB) }},
+		{"make/unmake", func() { b.UnmakeMove(mMakeMoveWithUndo(b, mv, PlayerA)) }},
+		{"ApplyPreview", func() { mv.ApplyPreview(b, PlayerA) }},
+	}
+	for _, c := range cases {
+		if n := testing.AllocsPerRun(50, c.fn); n != 0 {
+			t.Errorf("%s: 每次分配 %v 次，应为 0", c.name, n)
+		}
+	}
+}
+
+func TestSearchNoAllocs(t *testing.T) {
+	b := midgameBoard(t)
+	h := b.Hash()
+	// 清空置换表，保证每轮都真的搜完整棵树
+	n := testing.AllocsPerRun(3, func() {
+		ClearTT()
+		AlphaBeta(b, PlayerA, 3)
+	})
+	if n != 0 {
+		t.Errorf("AlphaBeta 深度 3: 每次分配 %v 次，应为 0", n)
+	}
+	if b.Hash() != h {
+		t.Fatal("搜索后棋盘被改动")
+	}
+}
+
+// 根搜索有固定开销（并行 worker、根着法切片），但不能随深度增长
+func TestRootSearchAllocsBounded(t *testing.T) {
+	b := midgameBoard(t)
+	allocs := func(depth int64) float64 {
+		return testing.AllocsPerRun(3, func() {
+			ClearTT()
+			FindBestMoveAtDepth(b, PlayerA, depth, false)
+		})
+	}
+	shallow, deep := allocs(1), allocs(3)
+	if deep > shallow+8 {
+		t.Errorf("根搜索分配随深度增长: 深度 1 = %v, 深度 3 = %v", shallow, deep)
+	}
+}
+
+// 连锁规则下 Move.MakeMove 返回的格子顺序与 InfectionChain 一致（UI 按这个顺序播动画）
+func TestMakeMoveInfectedOrder(t *testing.T) {
+	withRules(t, Rules{Cascade: true})
+	b, mv := chainBoard(t)
+	chain := InfectionChain(b, PlayerA, mv)
+	got, u := mv.MakeMove(b, PlayerA)
+	b.UnmakeMove(u)
+	if len(got) != len(chain) {
+		t.Fatalf("感染 %v，InfectionChain %+v", got, chain)
+	}
+	for i := range chain {
+		if got[i] != chain[i].To {
+			t.Fatalf("第 %d 个感染格 %v，应为 %v", i, got[i], chain[i].To)
+		}
+	}
+}
+
+// 盘外坐标、空起点等非法输入：不 panic，返回错误或什么都不改
+func TestMalformedInputNoPanic(t *testing.T) {
+	off := HexCoord{Q: 9, R: 9}
+	on := HexCoord{Q: 4, R: 0} // A 的开局角
+	bad := []Move{
+		{From: off, To: HexCoord{Q: 3, R: 0}},
+		{From: on, To: off},
+		{From: off, To: off},
+		{From: HexCoord{Q: 0, R: 0}, To: HexCoord{Q: 1, R: 0}}, // 起点不是己方子
+		{From: on, To: HexCoord{Q: 0, R: 0}},                   // 距离 4
+	}
+	for _, mv := range bad {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Fatalf("%v: panic %v", mv, r)
+				}
+			}()
+
+			gs := NewGameState(boardRadius)
+			cells, h := gs.Board.Cells, gs.Board.Hash()
+			unchanged := func(api string) {
+				t.Helper()
+				if gs.Board.Cells != cells || gs.Board.Hash() != h {
+					t.Fatalf("%s %v: 非法走法改动了棋盘", api, mv)
+				}
+			}
+
+			if _, _, err := gs.MakeMove(mv); err == nil {
+				t.Fatalf("GameState.MakeMove %v: 应返回错误", mv)
+			}
+			unchanged("GameState.MakeMove")
+			if gs.CurrentPlayer != PlayerA {
+				t.Fatalf("GameState.MakeMove %v: 非法走法不应交出回合", mv)
+			}
+			if err := ValidateMove(gs, mv); err == nil {
+				t.Fatalf("ValidateMove %v: 应返回错误", mv)
+			}
+			DescribeMove(gs.Board, PlayerA, mv)
+			InfectionChain(gs.Board, PlayerA, mv)
+
+			if gs.Board.InBounds(mv.From) && gs.Board.InBounds(mv.To) {
+				return // 以下只针对盘外坐标
+			}
+			if _, err := mv.Apply(gs.Board, PlayerA); err == nil {
+				t.Fatalf("Move.Apply %v: 盘外坐标应返回错误", mv)
+			}
+			unchanged("Move.Apply")
+			_, u := mv.MakeMove(gs.Board, PlayerA)
+			unchanged("Move.MakeMove")
+			gs.Board.UnmakeMove(u)
+			unchanged("UnmakeMove")
+			if n, undo := gs.Board.ApplyMoveWithUndo(mv, PlayerA); n != 0 {
+				t.Fatalf("ApplyMoveWithUndo %v: 感染 %d", mv, n)
+			} else {
+				undo()
+			}
+			unchanged("ApplyMoveWithUndo")
+			if _, ok := mv.ApplyPreview(gs.Board, PlayerA); ok {
+				t.Fatalf("ApplyPreview %v: 盘外坐标应 ok=false", mv)
+			}
+			if err := CheckFairLayout([]HexCoord{off}); err == nil {
+				t.Fatal("CheckFairLayout: 盘外障碍应返回错误")
+			}
+		}()
+	}
+}
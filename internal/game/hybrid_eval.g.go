@@ -51,6 +51,18 @@ func AddNodes(n int64) { atomic.AddInt64(&NodesSearched, n) }
 
 func SetPhaseSwitch(ps PhaseSwitch) { phaseSwitch = ps }
 
+// GamePhase 按 phaseSwitch 的空位比例阈值分阶段：0=开局 1=中局 2=残局
+func GamePhase(b *Board) int {
+	r := emptyRatio(b)
+	switch {
+	case r >= phaseSwitch.ROpen:
+		return 0
+	case r <= phaseSwitch.REnd:
+		return 2
+	}
+	return 1
+}
+
 // 只在一个阶段里用 CNN；其余阶段一律用“你的静态评估”
 // 不做混合，便于看清谁强谁弱
 func PhaseSelectEval(b *Board, me CellState) int {
@@ -74,12 +86,7 @@ func PhaseSelectEval(b *Board, me CellState) int {
 // 统计空位比例
 func emptyRatio(b *Board) float64 {
 	total := len(b.AllCoords())
-	empties := 0
-	for i := 0; i < BoardN; i++ {
-		if b.Cells[i] == Empty {
-			empties++
-		}
-	}
+	empties := b.EmptyMask().Count()
 	return float64(empties) / float64(total)
 }
 
@@ -155,12 +162,7 @@ func FindBestMoveAtDepthHybrid(b *Board, player CellState, depth int64, allowJum
 
 	// 2) 根层一次性计算空位比例 r
 	total := len(b.AllCoords())
-	empties := 0
-	for i := 0; i < BoardN; i++ {
-		if b.Cells[i] == Empty {
-			empties++
-		}
-	}
+	empties := b.EmptyMask().Count()
 	r := float64(empties) / float64(total)
 
 	// 3) 开局极早期：只保留“外圈克隆”
@@ -171,7 +173,7 @@ func FindBestMoveAtDepthHybrid(b *Board, player CellState, depth int64, allowJum
 			if !m.IsClone() {
 				continue
 			}
-			if idx, ok := IndexOf[m.To]; ok && isOuterI[idx] {
+			if idx, ok := IndexOf[m.To]; ok && b.onRim(idx) {
 				edgeClones = append(edgeClones, m)
 			}
 		}
@@ -190,9 +192,11 @@ func FindBestMoveAtDepthHybrid(b *Board, player CellState, depth int64, allowJum
 	if len(moves) == 0 {
 		return Move{}, false
 	}
+	moves = dedupSymmetricMoves(b, moves)
 
 	// 6) policy 先验修剪（可选）
 	if pruned := policyPruneRoot(b, player, moves); len(pruned) > 0 {
+		countPolicyPrune(len(moves), len(pruned))
 		moves = pruned
 	}
 
@@ -269,16 +273,15 @@ func FindBestMoveAtDepthHybrid(b *Board, player CellState, depth int64, allowJum
 				_ = nb
 			}()
 
-			var localNodes int64
+			var localNodes nodeCounter
 			for mv := range jobs {
 				undo := mMakeMoveWithUndo(nb, mv, player)
-				score := alphaBeta(nb, 0, Opponent(player), player, depth-1, alphaRoot, betaRoot, true, &localNodes)
+				d, ext := childDepth(nb, player, depth, searchExtMax, allowJump)
+				score := alphaBeta(nb, 0, Opponent(player), player, d, alphaRoot, betaRoot, true, &localNodes, ext)
 				nb.UnmakeMove(undo)
 				results <- result{mv: mv, score: score}
 			}
-			if localNodes > 0 {
-				AddNodes(localNodes)
-			}
+			localNodes.flush()
 		}()
 	}
 
@@ -0,0 +1,143 @@
+// internal/game/kata_ownership.go
+package game
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	ort "github.com/yalue/onnxruntime_go"
+)
+
+// 部分 KataGo 模型带 ownership 头：(N,1,9,9) 的原始输出，tanh 后为各格最终归属 [-1,1]（+1 = 执子方）。
+// 现在内置的模型没有这个头，ensureKataONNX 成功后再探测一次，有才额外建一个单样本会话；
+// 常规搜索仍走 katagoSess，不为用不到的输出多付推理开销。
+
+// ErrNoOwnershipHead 当前 KataGo 模型没有 ownership 输出
+var ErrNoOwnershipHead = errors.New("katago model has no ownership head")
+
+var (
+	katagoOwnershipName string // 探测到的输出名；空 = 无此头
+	katagoSessOwn       *ort.AdvancedSession
+	katagoOutOwnership  *ort.Tensor[float32]
+)
+
+// findOwnershipOutput 在模型输出里找 ownership 头（KataGo 导出的名字是 out_ownership，兼容不带前缀的）
+func findOwnershipOutput(modelData []byte) string {
+	_, outputs, err := ort.GetInputOutputInfoWithONNXData(modelData)
+	if err != nil {
+		return ""
+	}
+	for _, o := range outputs {
+		if strings.Contains(strings.ToLower(o.Name), "ownership") {
+			return o.Name
+		}
+	}
+	return ""
+}
+
+// initKataOwnership 用与主会话相同的 SessionOptions 建带 ownership 输出的会话。
+// 失败只记日志：没有 ownership 不影响对弈和搜索。
+func initKataOwnership(modelData []byte, so *ort.SessionOptions) {
+	name := findOwnershipOutput(modelData)
+	if name == "" {
+		return
+	}
+	out, err := ort.NewEmptyTensor[float32](ort.NewShape(1, 1, katagoGrid, katagoGrid))
+	if err != nil {
+		log.Printf("[katago] ownership tensor: %v%s", err, ansiReset)
+		return
+	}
+	sess, err := ort.NewAdvancedSessionWithONNXData(
+		modelData,
+		[]string{katagoInputSpatial, katagoInputGlobal},
+		[]string{katagoOutputPolicy, katagoOutputValue, name},
+		[]ort.Value{katagoInSpatial, katagoInGlobal},
+		[]ort.Value{katagoOutPolicy, katagoOutValue, out},
+		so,
+	)
+	if err != nil {
+		log.Printf("[katago] ownership session creation failed: %v%s", err, ansiReset)
+		out.Destroy()
+		return
+	}
+	katagoOwnershipName = name
+	katagoSessOwn = sess
+	katagoOutOwnership = out
+	log.Printf("[katago] ownership head found: %s%s", name, ansiReset)
+}
+
+// KataHasOwnership 当前模型是否带 ownership 头（会触发模型加载）
+func KataHasOwnership() bool {
+	return ensureKataONNX() == nil && katagoSessOwn != nil
+}
+
+// KataOwnership 返回 9×9 网格上每格的预测归属（按 AxialToIndex 排列，共 81 个），
+// 取值 [-1,1]，正数表示最终归 me；棋盘外的格子为 0。模型没有该头时返回 ErrNoOwnershipHead。
+func KataOwnership(b *Board, me CellState) ([]float32, error) {
+	_, _, own, err := KataPolicyValueOwnership(b, me, -1)
+	return own, err
+}
+
+// KataPolicyValueOwnership 与 KataPolicyValueWithSelection 相同，另外返回 ownership（见 KataOwnership）
+func KataPolicyValueOwnership(b *Board, me CellState, selectedIdx int) ([]float32, float32, []float32, error) {
+	if err := ensureKataONNX(); err != nil {
+		return nil, 0, nil, err
+	}
+	if katagoSessOwn == nil {
+		return nil, 0, nil, ErrNoOwnershipHead
+	}
+	if !FitsNN(b) {
+		return nil, 0, nil, ErrBoardTooLargeForNN
+	}
+
+	katagoMu.Lock()
+	defer katagoMu.Unlock()
+
+	encodeKataInputs(b, me, katagoInSpatial.GetData(), katagoInGlobal.GetData(), selectedIdx)
+	t0 := time.Now()
+	if err := katagoSessOwn.Run(); err != nil {
+		return nil, 0, nil, fmt.Errorf("katago ownership run: %w", err)
+	}
+	countNNRun(nnKata, 1, 1, t0)
+
+	policy := make([]float32, katagoGrid*katagoGrid+1)
+	copy(policy, katagoOutPolicy.GetData()[:len(policy)])
+	softmaxInPlace(policy)
+
+	v := katagoOutValue.GetData()
+	wdl := []float32{v[0], v[1], v[2]}
+	softmaxInPlace(wdl)
+	score := wdl[0] - wdl[1]
+
+	raw := katagoOutOwnership.GetData()
+	own := make([]float32, katagoGrid*katagoGrid)
+	for i := 0; i < nnCells; i++ {
+		idx := AxialToIndex(CoordOf[i])
+		if idx >= 0 && idx < len(own) {
+			own[idx] = float32(math.Tanh(float64(raw[idx])))
+		}
+	}
+	return policy, score, own, nil
+}
+
+func softmaxInPlace(xs []float32) {
+	maxV := float32(math.Inf(-1))
+	for _, x := range xs {
+		if x > maxV {
+			maxV = x
+		}
+	}
+	var sum float64
+	for i, x := range xs {
+		e := math.Exp(float64(x - maxV))
+		xs[i] = float32(e)
+		sum += e
+	}
+	for i := range xs {
+		xs[i] = float32(float64(xs[i]) / sum)
+	}
+}
@@ -9,12 +9,13 @@ import (
 	"io"
 	"log"
 	"math"
-	"math/bits"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	ort "github.com/yalue/onnxruntime_go"
 )
@@ -37,9 +38,7 @@ const (
 	katagoInputGlobal  = "input_global"
 	katagoOutputPolicy = "out_policy"
 	katagoOutputValue  = "out_value"
-	katagoGrid         = 9
-	katagoPlanes       = 22
-	katagoGlobals      = 19
+	katagoGrid         = GridSize
 	maxBatchSize       = 64 // 固定 Batch 大小用于加速
 )
 
@@ -49,6 +48,7 @@ var (
 	katagoSess      *ort.AdvancedSession
 	katagoSessBatch *ort.AdvancedSession
 	katagoMu        sync.Mutex
+	katagoSettled   atomic.Bool // 初始化已结束（成功或全部失败），见 CPUFallback
 
 	// 单步推理张量
 	katagoInSpatial *ort.Tensor[float32]
@@ -62,12 +62,10 @@ var (
 	katagoOutPolicyB *ort.Tensor[float32]
 	katagoOutValueB  *ort.Tensor[float32]
 
-	katagoModelBytes  []byte
+	katagoModelBytes  []byte               // 初始化成功的模型，闲置后重建批量会话用
+	katagoSessOpts    *ort.SessionOptions // 初始化成功的会话选项（执行提供者），同上
+	katagoModelSum    uint32 // 模型 CRC32，用作评估器身份（TT 持久化等）
 	katagoPolicyHeads = 4
-
-	// 预计算静态平面
-	staticSpatialOnce sync.Once
-	staticSpatial     []float32 // 包含 Plane 0 (all 1) 和 Plane 3 (Blocked)
 )
 
 const ansiReset = "\033[0m"
@@ -77,41 +75,9 @@ func init() {
 	log.SetOutput(os.Stdout)
 }
 
-func ensureStaticSpatial() {
-	staticSpatialOnce.Do(func() {
-		staticSpatial = make([]float32, katagoPlanes*katagoGrid*katagoGrid)
-		planeSize := katagoGrid * katagoGrid
-		// Plane 0: All 1s
-		for i := 0; i < planeSize; i++ {
-			staticSpatial[i] = 1.0
-		}
-		// Plane 3: Blocked (Out of board AND Internal Blocks)
-		if !encodeTablesInit {
-			initEncodeTables()
-		}
-		for g := 0; g < planeSize; g++ {
-			// 棋盘外
-			if !gridInBoard[g] {
-				staticSpatial[3*planeSize+g] = 1.0
-			}
-		}
-		// 棋盘内固定障碍物 (来自 state.go: {1, 0}, {-1, 1}, {0, -1})
-		internalBlocks := []HexCoord{{1, 0}, {-1, 1}, {0, -1}}
-		for _, c := range internalBlocks {
-			if idx, ok := IndexOf[c]; ok {
-				g := boardIndexToGrid[idx]
-				if g >= 0 && g < planeSize {
-					staticSpatial[3*planeSize+g] = 1.0
-				}
-			}
-		}
-	})
-}
-
 func ensureKataONNX() error {
 	katagoOnce.Do(func() {
-		ensureStaticSpatial()
-
+		defer katagoSettled.Store(true)
 		// 1. 路径标准化
 		exePath, _ := os.Executable()
 		baseDir := filepath.Dir(exePath)
@@ -136,15 +102,22 @@ func ensureKataONNX() error {
 		log.Printf("[katago] TRT Debug: Syncing Cache to %s%s", absCachePath, ansiReset)
 
 		// 3. 初始化环境（环境变量设置必须在此之前）
-		libPath, _ := prepareORTSharedLib()
+		libPath, err := prepareORTSharedLib()
+		if err != nil {
+			log.Printf("[katago] prepare ORT lib: %v%s", err, ansiReset)
+		}
 		ort.SetSharedLibraryPath(libPath)
+		noteORTLib(libPath)
 		ort.InitializeEnvironment()
 		fmt.Print(ansiReset) // 强行重置可能由 ORT 产生的颜色码
+		log.Printf("[katago] ORT %s (%s)%s", ort.GetVersion(), libPath, ansiReset)
 
 		// 4. 模型加载 (直接加载到内存)
 		var modelData []byte
+		var modelSource string
 		if path := os.Getenv("KATAGO_ONNX_PATH"); path != "" {
 			modelData, _ = os.ReadFile(path)
+			modelSource = path
 		} else {
 			entries, _ := katagoFS.ReadDir("assets")
 			for _, e := range entries {
@@ -162,6 +135,7 @@ func ensureKataONNX() error {
 					} else {
 						modelData = b
 					}
+					modelSource = "assets/" + e.Name()
 					break
 				}
 			}
@@ -171,6 +145,13 @@ func ensureKataONNX() error {
 			katagoErr = fmt.Errorf("no KataGo ONNX model found")
 			return
 		}
+		info, cerr := inspectModel("katago", modelSource, modelData, kataModelSpec)
+		if cerr != nil {
+			katagoErr = cerr
+			log.Printf("[katago] %v%s", cerr, ansiReset)
+			return
+		}
+		katagoModelSum = info.Checksum
 
 		// 4. 初始化推理张量 (这些可以复用)
 		katagoInSpatial, _ = ort.NewTensor(ort.NewShape(1, katagoPlanes, katagoGrid, katagoGrid), make([]float32, katagoPlanes*katagoGrid*katagoGrid))
@@ -178,10 +159,7 @@ func ensureKataONNX() error {
 		katagoOutPolicy, _ = ort.NewEmptyTensor[float32](ort.NewShape(1, int64(katagoPolicyHeads), katagoGrid*katagoGrid+1))
 		katagoOutValue, _ = ort.NewEmptyTensor[float32](ort.NewShape(1, 3))
 
-		katagoInSpatialB, _ = ort.NewTensor(ort.NewShape(maxBatchSize, katagoPlanes, katagoGrid, katagoGrid), make([]float32, maxBatchSize*katagoPlanes*katagoGrid*katagoGrid))
-		katagoInGlobalB, _ = ort.NewTensor(ort.NewShape(maxBatchSize, katagoGlobals), make([]float32, maxBatchSize*katagoGlobals))
-		katagoOutPolicyB, _ = ort.NewEmptyTensor[float32](ort.NewShape(maxBatchSize, int64(katagoPolicyHeads), katagoGrid*katagoGrid+1))
-		katagoOutValueB, _ = ort.NewEmptyTensor[float32](ort.NewShape(maxBatchSize, 3))
+		newKataBatchTensors()
 
 		// 5. 定义并尝试多种策略
 		type strategy struct {
@@ -270,14 +248,17 @@ func ensureKataONNX() error {
 			}
 			// 设置日志级别为 Error (3)，避免输出警告和信息，防止变红
 			_ = so.SetLogSeverityLevel(3)
+			applyORTConfigLogged("katago", so)
 
 			if err := st.setup(so); err != nil {
 				log.Printf("[katago] %s setup failed: %v%s", st.name, err, ansiReset)
+				noteNNFailure(nnKata, st.name, err)
 				so.Destroy()
 				continue
 			}
 
 			// 尝试创建会话
+			initStart := time.Now()
 			s1, err1 := ort.NewAdvancedSessionWithONNXData(
 				modelData,
 				[]string{katagoInputSpatial, katagoInputGlobal},
@@ -288,6 +269,7 @@ func ensureKataONNX() error {
 			)
 			if err1 != nil {
 				log.Printf("[katago] %s session creation failed: %v%s", st.name, err1, ansiReset)
+				noteNNFailure(nnKata, st.name, err1)
 				so.Destroy()
 				continue
 			}
@@ -302,22 +284,30 @@ func ensureKataONNX() error {
 			)
 			if err2 != nil {
 				log.Printf("[katago] %s batch session creation failed: %v%s", st.name, err2, ansiReset)
+				noteNNFailure(nnKata, st.name, err2)
 				s1.Destroy()
 				so.Destroy()
 				continue
 			}
 
-			// 热身
+			initTime := time.Since(initStart)
+
+			// 热身（CoreML/DirectML 首轮要编译，多跑几轮）
 			log.Printf("[katago] Warming up %s...%s", st.name, ansiReset)
-			if errR1 := s1.Run(); errR1 != nil {
+			runs := warmUpRuns(st.name)
+			first1, warm1, steady1, errR1 := warmUpSession(s1.Run, runs)
+			if errR1 != nil {
 				log.Printf("[katago] %s warm-up 1 failed: %v%s", st.name, errR1, ansiReset)
+				noteNNFailure(nnKata, st.name, errR1)
 				s1.Destroy()
 				s2.Destroy()
 				so.Destroy()
 				continue
 			}
-			if errR2 := s2.Run(); errR2 != nil {
+			first2, warm2, steady2, errR2 := warmUpSession(s2.Run, runs)
+			if errR2 != nil {
 				log.Printf("[katago] %s warm-up 2 failed: %v%s", st.name, errR2, ansiReset)
+				noteNNFailure(nnKata, st.name, errR2)
 				s1.Destroy()
 				s2.Destroy()
 				so.Destroy()
@@ -327,10 +317,21 @@ func ensureKataONNX() error {
 			// 成功！
 			katagoSess = s1
 			katagoSessBatch = s2
+			fp16 := providerFP16(st.name)
+			setNNBackend(nnKata, BackendSession{Provider: st.name, FP16: fp16, BatchSize: 1,
+				InitTime: initTime, WarmUp: warm1, FirstRun: first1, SteadyRun: steady1})
+			setNNBackend(nnKataBatch, BackendSession{Provider: st.name, FP16: fp16, BatchSize: maxBatchSize,
+				InitTime: initTime, WarmUp: warm2, FirstRun: first2, SteadyRun: steady2})
+			if reason, ok := cpuFallbackReason(); ok && st.name == "CPU" {
+				log.Printf("[katago] GPU acceleration unavailable, running on CPU: %s%s", reason, ansiReset)
+			}
+			initKataOwnership(modelData, so)
+			katagoModelBytes, katagoSessOpts = modelData, so // 留着，闲置释放批量会话后重建用（见 nn_idle.go）
 			katagoErr = nil
 			success = true
-			log.Printf("[katago] Successfully initialized with %s.%s", st.name, ansiReset)
-			so.Destroy()
+			log.Printf("[katago] Successfully initialized with %s (fp16=%v, init %s, warm-up %s, steady %s / batch %s)%s",
+				st.name, fp16, initTime.Round(time.Millisecond), (warm1 + warm2).Round(time.Millisecond),
+				steady1.Round(time.Microsecond), steady2.Round(time.Microsecond), ansiReset)
 			break
 		}
 
@@ -341,61 +342,6 @@ func ensureKataONNX() error {
 	return katagoErr
 }
 
-func encodeKataInputs(b *Board, me CellState, spatial []float32, global []float32, selectedIdx int) {
-	if !encodeTablesInit {
-		initEncodeTables()
-	}
-	// 拷贝静态平面 (Plane 0 和 Plane 3) - 现在 Plane 3 已包含所有障碍物
-	copy(spatial, staticSpatial)
-	// 清空 Global
-	for i := range global {
-		global[i] = 0
-	}
-
-	planeSize := katagoGrid * katagoGrid
-	
-	// 使用位掩码加速特征提取
-	var myBit, opBit uint64
-	if me == PlayerA {
-		myBit, opBit = b.bitA, b.bitB
-	} else {
-		myBit, opBit = b.bitB, b.bitA
-	}
-
-	// Plane 1: Me
-	tempMy := myBit
-	for tempMy != 0 {
-		i := bits.TrailingZeros64(tempMy)
-		tempMy &= ^(uint64(1) << uint(i))
-		g := boardIndexToGrid[i]
-		if g >= 0 && g < planeSize {
-			spatial[planeSize+g] = 1.0
-		}
-	}
-
-	// Plane 2: Opponent
-	tempOp := opBit
-	for tempOp != 0 {
-		i := bits.TrailingZeros64(tempOp)
-		tempOp &= ^(uint64(1) << uint(i))
-		g := boardIndexToGrid[i]
-		if g >= 0 && g < planeSize {
-			spatial[2*planeSize+g] = 1.0
-		}
-	}
-
-	// Plane 3: Blocked 已在 copy(spatial, staticSpatial) 中处理，无需再遍历
-
-	stageOne := selectedIdx >= 0
-	if stageOne && selectedIdx < planeSize {
-		spatial[4*planeSize+selectedIdx] = 1.0 // Plane 4
-	}
-	if stageOne {
-		global[0] = 1.0
-	}
-	global[9] = 1.0
-}
-
 func KataBatchValueScore(boards []*Board, me CellState) ([]int, error) {
 	return KataBatchValueScoreWithSelection(boards, me, nil)
 }
@@ -404,6 +350,11 @@ func KataBatchValueScoreWithSelection(boards []*Board, me CellState, selectedInd
 	if err := ensureKataONNX(); err != nil {
 		return nil, err
 	}
+	for _, b := range boards {
+		if !FitsNN(b) {
+			return nil, ErrBoardTooLargeForNN
+		}
+	}
 	n := len(boards)
 	if n == 0 {
 		return nil, nil
@@ -438,6 +389,10 @@ func KataBatchValueScoreWithSelection(boards []*Board, me CellState, selectedInd
 
 	// 2. 拷贝数据到张量并执行推理 (持锁)
 	katagoMu.Lock()
+	if err := ensureKataBatch(); err != nil {
+		katagoMu.Unlock()
+		return nil, err
+	}
 	copy(katagoInSpatialB.GetData(), localSpatial)
 	copy(katagoInGlobalB.GetData(), localGlobal)
 
@@ -458,10 +413,12 @@ func KataBatchValueScoreWithSelection(boards []*Board, me CellState, selectedInd
 		}
 	}
 
+	t0 := time.Now()
 	if err := katagoSessBatch.Run(); err != nil {
 		katagoMu.Unlock()
 		return nil, err
 	}
+	countNNRun(nnKataBatch, n, maxBatchSize, t0)
 
 	// 3. 拷贝结果 (尽快解锁)
 	valsRaw := katagoOutValueB.GetData()
@@ -495,14 +452,19 @@ func KataPolicyValueWithSelection(b *Board, me CellState, selectedIdx int) ([]fl
 	if err := ensureKataONNX(); err != nil {
 		return nil, 0, err
 	}
+	if !FitsNN(b) {
+		return nil, 0, ErrBoardTooLargeForNN
+	}
 
 	katagoMu.Lock()
 	defer katagoMu.Unlock()
 
 	encodeKataInputs(b, me, katagoInSpatial.GetData(), katagoInGlobal.GetData(), selectedIdx)
+	t0 := time.Now()
 	if err := katagoSess.Run(); err != nil {
 		return nil, 0, err
 	}
+	countNNRun(nnKata, 1, 1, t0)
 
 	logits := make([]float32, katagoGrid*katagoGrid+1)
 	copy(logits, katagoOutPolicy.GetData()[:len(logits)])
@@ -546,14 +508,19 @@ func KataWinProb(b *Board, me CellState) (float32, error) {
 	if err := ensureKataONNX(); err != nil {
 		return 0, err
 	}
+	if !FitsNN(b) {
+		return 0, ErrBoardTooLargeForNN
+	}
 
 	katagoMu.Lock()
 	defer katagoMu.Unlock()
 
 	encodeKataInputs(b, me, katagoInSpatial.GetData(), katagoInGlobal.GetData(), -1)
+	t0 := time.Now()
 	if err := katagoSess.Run(); err != nil {
 		return 0, err
 	}
+	countNNRun(nnKata, 1, 1, t0)
 
 	vals := katagoOutValue.GetData()
 	maxVal := vals[0]
@@ -0,0 +1,122 @@
+// game/ladder.go
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// 难度阶梯：每档难度是一组 AI 设置（基线对手，或搜索深度 + 评估函数）加一个近似 Elo。
+// Elo 以随机基线对手为 0 分锚点，由 cmd/ladder 实战校准后写进配置文件。
+// 引擎变强后重跑校准，各档会换成离目标 Elo 最近的设置，所以“中等”在各版本间强度大致不变。
+
+// 难度预设的评估函数名，与 ui.EvalNN / ui.EvalStatic 相同
+const (
+	PresetEvalNN     = "nn"
+	PresetEvalStatic = "static"
+)
+
+// Preset 一档难度
+type Preset struct {
+	Name      string  `json:"name"`
+	TargetElo float64 `json:"target_elo"`          // 这一档应有的强度（相对随机基线）
+	Elo       float64 `json:"elo"`                 // 校准实测值
+	Bot       string  `json:"bot,omitempty"`       // 非空时用内置基线对手，忽略 Depth/Evaluator
+	Depth     int     `json:"depth,omitempty"`     // 搜索深度
+	Evaluator string  `json:"evaluator,omitempty"` // PresetEvalNN 或 PresetEvalStatic
+}
+
+// Setting 这一档的 AI 设置，如 "greedy"、"static d2"
+func (p Preset) Setting() string {
+	if p.Bot != "" {
+		return p.Bot
+	}
+	return fmt.Sprintf("%s d%d", p.Evaluator, p.Depth)
+}
+
+func (p Preset) validate() error {
+	switch {
+	case p.Name == "":
+		return fmt.Errorf("难度预设缺少名字")
+	case p.Bot != "":
+		// 难度档位用在界面对局里，允许跳跃
+		if err := CheckBaselineBot(p.Bot, true); err != nil {
+			return fmt.Errorf("难度 %s: %w", p.Name, err)
+		}
+	case p.Depth < 1:
+		return fmt.Errorf("难度 %s: 搜索深度须 >= 1: %d", p.Name, p.Depth)
+	case p.Evaluator != PresetEvalNN && p.Evaluator != PresetEvalStatic:
+		return fmt.Errorf("难度 %s: 未知评估函数 %q", p.Name, p.Evaluator)
+	}
+	return nil
+}
+
+// Ladder 整条难度阶梯，按从弱到强排列
+type Ladder struct {
+	Engine     string   `json:"engine,omitempty"`         // 校准时的 EvaluatorID
+	Calibrated string   `json:"calibrated,omitempty"`     // 校准日期；空 = 用的是未校准的默认值
+	Games      int      `json:"games_per_pair,omitempty"` // 校准时每对设置下了几局
+	Presets    []Preset `json:"presets"`
+}
+
+// DefaultLadder 未校准时的默认阶梯：设置按经验排好，Elo 只是目标值
+func DefaultLadder() *Ladder {
+	return &Ladder{Presets: []Preset{
+		{Name: "beginner", TargetElo: 0, Bot: BotRandom},
+		{Name: "easy", TargetElo: 200, Bot: BotGreedy},
+		{Name: "medium", TargetElo: 400, Depth: 1, Evaluator: PresetEvalStatic},
+		{Name: "hard", TargetElo: 600, Depth: 2, Evaluator: PresetEvalStatic},
+		{Name: "expert", TargetElo: 800, Depth: 2, Evaluator: PresetEvalNN},
+	}}
+}
+
+// LoadLadder 读取校准结果
+func LoadLadder(path string) (*Ladder, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var l Ladder
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(l.Presets) == 0 {
+		return nil, fmt.Errorf("%s: 没有难度预设", path)
+	}
+	for _, p := range l.Presets {
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return &l, nil
+}
+
+// Save 写出阶梯（缩进 JSON，方便人工查看与提交进仓库）
+func (l *Ladder) Save(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Preset 按名字取一档（不区分大小写）
+func (l *Ladder) Preset(name string) (Preset, error) {
+	for _, p := range l.Presets {
+		if strings.EqualFold(p.Name, name) {
+			return p, nil
+		}
+	}
+	return Preset{}, fmt.Errorf("未知难度 %q（可选 %s）", name, strings.Join(l.Names(), "/"))
+}
+
+// Names 全部难度名，从弱到强
+func (l *Ladder) Names() []string {
+	names := make([]string, len(l.Presets))
+	for i, p := range l.Presets {
+		names[i] = p.Name
+	}
+	return names
+}
@@ -0,0 +1,49 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultLadderValid(t *testing.T) {
+	l := DefaultLadder()
+	for i, p := range l.Presets {
+		if err := p.validate(); err != nil {
+			t.Fatal(err)
+		}
+		if i > 0 && p.TargetElo <= l.Presets[i-1].TargetElo {
+			t.Fatalf("%s 的目标 Elo 应高于 %s", p.Name, l.Presets[i-1].Name)
+		}
+	}
+	if p, err := l.Preset("Medium"); err != nil || p.Name != "medium" {
+		t.Fatalf("按名字取预设应不区分大小写: %+v %v", p, err)
+	}
+	if _, err := l.Preset("nightmare"); err == nil {
+		t.Fatal("未知难度应报错")
+	}
+}
+
+func TestLadderSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ladder.json")
+	l := DefaultLadder()
+	l.Calibrated, l.Games = "2026-01-01", 20
+	l.Presets[2].Elo = 412
+	if err := l.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadLadder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Games != 20 || len(got.Presets) != len(l.Presets) || got.Presets[2] != l.Presets[2] {
+		t.Fatalf("读回不一致: %+v", got)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"presets":[{"name":"x","depth":0,"evaluator":"static"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadLadder(path); err == nil {
+		t.Fatal("非法预设应在读取时报错")
+	}
+}
@@ -0,0 +1,141 @@
+// game/layout.go
+package game
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+// 随机障碍布局。双方初始角互为中心对称（A 的三个角取负就是 B 的三个角），
+// 所以障碍也成对按中心对称放置：交换颜色后局面完全相同，谁也不占地形便宜。
+// 生成后再实际核对一遍，不满足就换一组重抽；同一 (n, seed) 总得到同一布局，种子可以分享给别人复现。
+
+// colorSwaps 把 A 的初始角整体变到 B 的初始角的全部对称变换（3 个旋转 + 3 条镜像轴）。
+// 布局在其中任一变换下不变，就等于双方互换颜色后地形一样
+var colorSwaps = []func(HexCoord) HexCoord{
+	func(c HexCoord) HexCoord { return HexCoord{Q: -c.Q, R: -c.R} },      // 中心对称（转 180°）
+	func(c HexCoord) HexCoord { return HexCoord{Q: -c.R, R: c.Q + c.R} }, // 转 60°
+	func(c HexCoord) HexCoord { return HexCoord{Q: c.Q + c.R, R: -c.Q} }, // 转 300°
+	func(c HexCoord) HexCoord { return HexCoord{Q: c.R, R: c.Q} },        // 镜像
+	func(c HexCoord) HexCoord { return HexCoord{Q: -c.Q - c.R, R: c.R} }, // 镜像
+	func(c HexCoord) HexCoord { return HexCoord{Q: c.Q, R: -c.Q - c.R} }, // 镜像
+}
+
+// MaxRandomBlocks 随机障碍数上限；再多空地太少，开局几手就被堵死
+const MaxRandomBlocks = 16
+
+// randomLayoutTries 重抽次数上限；正常几次内就能抽到
+const randomLayoutTries = 1000
+
+// RandomBlocks 生成 n 个随机障碍格（n 为奇数时中心格必为障碍），满足 CheckFairLayout
+func RandomBlocks(n int, seed int64) ([]HexCoord, error) {
+	if n < 0 || n > MaxRandomBlocks {
+		return nil, fmt.Errorf("障碍数 %d 超出范围 0..%d", n, MaxRandomBlocks)
+	}
+	// 候选：每对 {c, -c} 只记一个代表，避开初始角
+	start := NewGameStateWithBlocks(boardRadius, nil).Board
+	var pairs []HexCoord
+	for _, c := range CoordOf {
+		if c.Q < 0 || (c.Q == 0 && c.R <= 0) || start.Cells[IndexOf[c]] != Empty {
+			continue
+		}
+		pairs = append(pairs, c)
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	for try := 0; try < randomLayoutTries; try++ {
+		blocks := make([]HexCoord, 0, n)
+		if n%2 == 1 {
+			blocks = append(blocks, HexCoord{})
+		}
+		for _, k := range rng.Perm(len(pairs))[:n/2] {
+			c := pairs[k]
+			blocks = append(blocks, c, HexCoord{Q: -c.Q, R: -c.R})
+		}
+		if CheckFairLayout(blocks) == nil {
+			return blocks, nil
+		}
+	}
+	return nil, fmt.Errorf("seed %d: %d 次内没抽到公平的 %d 障碍布局", seed, randomLayoutTries, n)
+}
+
+// CheckFairLayout 校验障碍布局：在某个互换颜色的对称变换下不变、不压初始子、空地连成一片、
+// 双方开局可走步数相同且都有棋可走。
+// 原版三障碍只有 120° 旋转对称（不换颜色），不满足这里的要求
+func CheckFairLayout(blocks []HexCoord) error {
+	set := make(map[HexCoord]bool, len(blocks))
+	for _, c := range blocks {
+		if _, ok := IndexOf[c]; !ok {
+			return fmt.Errorf("障碍 %v 不在盘上", c)
+		}
+		set[c] = true
+	}
+	if !swapSymmetric(set) {
+		return errors.New("障碍布局交换颜色后不对称")
+	}
+
+	st := NewGameStateWithBlocks(boardRadius, blocks)
+	b := st.Board
+	for c := range set {
+		if b.Cells[IndexOf[c]] != Blocked {
+			return fmt.Errorf("障碍 %v 压在初始棋子上", c)
+		}
+	}
+	if !openConnected(b) {
+		return errors.New("障碍把棋盘隔成了几块")
+	}
+	ma, mb := len(GenerateMoves(b, PlayerA)), len(GenerateMoves(b, PlayerB))
+	if ma == 0 || ma != mb {
+		return fmt.Errorf("开局可走步数不等: A=%d B=%d", ma, mb)
+	}
+	return nil
+}
+
+func swapSymmetric(set map[HexCoord]bool) bool {
+	for _, f := range colorSwaps {
+		ok := true
+		for c := range set {
+			if !set[f(c)] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// openConnected 非障碍格是否经相邻关系连成一片
+func openConnected(b *Board) bool {
+	first, total := -1, 0
+	for i := 0; i < BoardN; i++ {
+		if b.Cells[i] != Blocked {
+			if first < 0 {
+				first = i
+			}
+			total++
+		}
+	}
+	if first < 0 {
+		return false
+	}
+	seen := make([]bool, BoardN)
+	seen[first] = true
+	queue := []int{first}
+	reached := 1
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, nb := range NeighI[cur] {
+			if !seen[nb] && b.Cells[nb] != Blocked {
+				seen[nb] = true
+				reached++
+				queue = append(queue, nb)
+			}
+		}
+	}
+	return reached == total
+}
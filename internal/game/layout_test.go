@@ -0,0 +1,56 @@
+package game
+
+import "testing"
+
+func TestColorSwapsMapCorners(t *testing.T) {
+	b := NewGameStateWithBlocks(boardRadius, nil).Board
+	for k, f := range colorSwaps {
+		for i := 0; i < BoardN; i++ {
+			if b.Cells[i] != PlayerA {
+				continue
+			}
+			j, ok := IndexOf[f(CoordOf[i])]
+			if !ok || b.Cells[j] != PlayerB {
+				t.Fatalf("变换 %d 把 A 角 %v 变到 %v，不是 B 角", k, CoordOf[i], f(CoordOf[i]))
+			}
+		}
+	}
+}
+
+func TestRandomBlocksFairAndReproducible(t *testing.T) {
+	for n := 0; n <= MaxRandomBlocks; n++ {
+		for seed := int64(1); seed <= 5; seed++ {
+			blocks, err := RandomBlocks(n, seed)
+			if err != nil {
+				t.Fatalf("n=%d seed=%d: %v", n, seed, err)
+			}
+			if len(blocks) != n {
+				t.Fatalf("n=%d seed=%d: 得到 %d 个障碍", n, seed, len(blocks))
+			}
+			if err := CheckFairLayout(blocks); err != nil {
+				t.Fatalf("n=%d seed=%d: %v", n, seed, err)
+			}
+			again, _ := RandomBlocks(n, seed)
+			for i := range blocks {
+				if blocks[i] != again[i] {
+					t.Fatalf("n=%d seed=%d: 同一种子布局不同", n, seed)
+				}
+			}
+		}
+	}
+	if _, err := RandomBlocks(MaxRandomBlocks+1, 1); err == nil {
+		t.Fatal("超过上限应报错")
+	}
+}
+
+func TestCheckFairLayoutRejects(t *testing.T) {
+	if err := CheckFairLayout([]HexCoord{{Q: 1, R: 0}}); err == nil {
+		t.Fatal("单边障碍应判为不对称")
+	}
+	if err := CheckFairLayout([]HexCoord{{Q: 4, R: 0}, {Q: -4, R: 0}}); err == nil {
+		t.Fatal("压在初始子上的障碍应报错")
+	}
+	if err := CheckFairLayout(DefaultBlocks); err == nil {
+		t.Fatal("原版布局不满足换色对称")
+	}
+}
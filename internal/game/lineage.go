@@ -0,0 +1,121 @@
+// game/lineage.go
+package game
+
+// 克隆次数上限（Rules.CloneLimit）按“脉”计：开局或载入局面时盘上每颗子自成一脉，
+// 脉号 = 它当时的下标 + 1。克隆出的子归入母子的一脉并占用一次次数；跳跃时脉号跟着子走；
+// 被感染的子改投落子那一脉。同一脉的子共享次数，用完后只能跳跃。
+// 规则关闭时走子不碰这里，标准规则下搜索没有额外开销。
+//
+// 脉号不进 PositionString：按局面串载入时每颗子重新自成一脉（和开局一样）。
+
+// lineageState 每格所属的脉与每脉已用的克隆次数。
+// 脉号 0 = 空格/障碍，或由终局判地之类的旁路放上来的子，不受次数限制
+type lineageState struct {
+	of   [BoardN]uint8
+	used [BoardN + 1]uint8
+	hash uint64 // of/used 的 zobrist；规则开启时已 XOR 进 Board.hash
+}
+
+// 脉的 zobrist 键不占 tt.go 里的随机数序列（那边改顺序会让已存的 key 作废），用 splitmix64 现算
+func lineageKey(tag, a, b uint64) uint64 {
+	x := tag<<32 | a<<8 | b
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+func lineCellKey(i int, id uint8) uint64 {
+	if id == 0 {
+		return 0
+	}
+	return lineageKey(0x4c494e45, uint64(i), uint64(id)) // "LINE"
+}
+
+func lineUsedKey(id, n uint8) uint64 {
+	if n == 0 {
+		return 0
+	}
+	return lineageKey(0x55534544, uint64(id), uint64(n)) // "USED"
+}
+
+// resetLineage 盘上每颗子各起一脉、次数清零；开局和载入局面时调用
+func (b *Board) resetLineage() {
+	on := rules.CloneLimit > 0
+	if on {
+		b.hash ^= b.lin.hash
+	}
+	b.lin = lineageState{}
+	for m := b.bitA.Or(b.bitB); !m.IsZero(); {
+		i := m.pop()
+		b.lin.of[i] = uint8(i + 1)
+		b.lin.hash ^= lineCellKey(i, b.lin.of[i])
+	}
+	if on {
+		b.hash ^= b.lin.hash
+	}
+}
+
+// canCloneI 下标 i 上的子还能不能克隆
+func (b *Board) canCloneI(i int) bool {
+	lim := rules.CloneLimit
+	return lim == 0 || int(b.lin.used[b.lin.of[i]]) < lim
+}
+
+// moveLineage 走子（已落在盘上）后更新脉号：to 接过 from 的脉，克隆时这一脉计一次；infected 改投这一脉
+func (b *Board) moveLineage(from, to int, jump bool, infected CellMask) {
+	l := &b.lin
+	h := l.hash
+	id := l.of[from]
+	if jump {
+		h ^= lineCellKey(from, id)
+		l.of[from] = 0
+	} else if id != 0 {
+		h ^= lineUsedKey(id, l.used[id])
+		l.used[id]++
+		h ^= lineUsedKey(id, l.used[id])
+	}
+	h ^= lineCellKey(to, l.of[to]) ^ lineCellKey(to, id)
+	l.of[to] = id
+	for x := infected; !x.IsZero(); {
+		i := x.pop()
+		h ^= lineCellKey(i, l.of[i]) ^ lineCellKey(i, id)
+		l.of[i] = id
+	}
+	b.hash ^= l.hash ^ h
+	l.hash = h
+}
+
+// restoreLineage 悔棋时整块换回走子前的脉号
+func (b *Board) restoreLineage(prev *lineageState) {
+	b.hash ^= b.lin.hash ^ prev.hash
+	b.lin = *prev
+}
+
+// limitedReach 克隆上限规则下 side 的可达格（未与空位求交）：次数用完的子只算跳跃
+func (b *Board) limitedReach(side CellState) CellMask {
+	pieces := b.bitA
+	if side == PlayerB {
+		pieces = b.bitB
+	}
+	var m CellMask
+	for !pieces.IsZero() {
+		i := pieces.pop()
+		if b.canCloneI(i) {
+			m = m.Or(NeighMask[i])
+		}
+		for _, j := range JumpI[i] {
+			m.set(j)
+		}
+	}
+	return m
+}
+
+// ClonesLeft c 上的子所在一脉还能克隆几次；规则未开启、c 上没有子或该子不受限时 limited=false
+func (b *Board) ClonesLeft(c HexCoord) (n int, limited bool) {
+	i, ok := IndexOf[c]
+	if !ok || rules.CloneLimit == 0 || (b.Cells[i] != PlayerA && b.Cells[i] != PlayerB) || b.lin.of[i] == 0 {
+		return 0, false
+	}
+	return max(rules.CloneLimit-int(b.lin.used[b.lin.of[i]]), 0), true
+}
@@ -0,0 +1,144 @@
+package game
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// 克隆上限 1：角上那颗子克隆一次后，它和它的克隆都只能跳跃，其余原始子不受影响
+func TestCloneLimitPerLineage(t *testing.T) {
+	withRules(t, Rules{CloneLimit: 1})
+	gs := NewGameState(boardRadius)
+	root := HexCoord{Q: 4, R: 0}
+	child := HexCoord{Q: 3, R: 0}
+	if n, limited := gs.Board.ClonesLeft(root); !limited || n != 1 {
+		t.Fatalf("开局应剩 1 次克隆: %d %v", n, limited)
+	}
+	if _, _, err := gs.MakeMove(Move{From: root, To: child}); err != nil {
+		t.Fatal(err)
+	}
+	gs.MakeMove(GenerateMoves(gs.Board, PlayerB)[0])
+
+	for _, c := range []HexCoord{root, child} {
+		if n, _ := gs.Board.ClonesLeft(c); n != 0 {
+			t.Fatalf("%v 所在一脉应已用完: 剩 %d", c, n)
+		}
+	}
+	if n, _ := gs.Board.ClonesLeft(HexCoord{Q: 0, R: -4}); n != 1 {
+		t.Fatalf("另一颗原始子不应受影响: 剩 %d", n)
+	}
+	jumps := 0
+	for _, mv := range GenerateMoves(gs.Board, PlayerA) {
+		if mv.From != root && mv.From != child {
+			continue
+		}
+		if mv.IsClone() {
+			t.Fatalf("用完次数后不应生成克隆 %v", mv)
+		}
+		jumps++
+	}
+	if jumps == 0 {
+		t.Fatal("用完次数后仍应能跳跃")
+	}
+	err := ValidateMove(gs, Move{From: child, To: HexCoord{Q: 2, R: 0}})
+	if err == nil || !strings.Contains(err.Error(), "clones") {
+		t.Fatalf("应拒绝克隆并说明原因: %v", err)
+	}
+	if _, _, err := gs.MakeMove(Move{From: child, To: HexCoord{Q: 2, R: 0}}); err == nil {
+		t.Fatal("MakeMove 也应拒绝")
+	}
+}
+
+// 被感染的子改投落子那一脉，跳跃时脉跟着子走
+func TestCloneLimitInfectionJoinsLineage(t *testing.T) {
+	withRules(t, Rules{CloneLimit: 2})
+	b := seeBoard(t, []HexCoord{{Q: 0, R: 0}}, []HexCoord{{Q: 2, R: 0}, {Q: -2, R: 2}})
+	mv := Move{From: HexCoord{Q: 0, R: 0}, To: HexCoord{Q: 1, R: 0}}
+	u := b.makeMove(mv, PlayerA)
+	if n, _ := b.ClonesLeft(HexCoord{Q: 2, R: 0}); n != 1 {
+		t.Fatalf("被感染的子应归入 A 那一脉（剩 1 次）: %d", n)
+	}
+	b.UnmakeMove(u)
+	if n, _ := b.ClonesLeft(HexCoord{Q: 2, R: 0}); n != 2 {
+		t.Fatalf("悔棋后应回到 B 自己那一脉: %d", n)
+	}
+
+	jump := Move{From: HexCoord{Q: 0, R: 0}, To: HexCoord{Q: 2, R: -2}}
+	b.makeMove(mv, PlayerA)
+	b.makeMove(jump, PlayerA)
+	if n, limited := b.ClonesLeft(jump.To); !limited || n != 1 {
+		t.Fatalf("跳跃后次数应跟着子走: %d %v", n, limited)
+	}
+	if _, limited := b.ClonesLeft(jump.From); limited {
+		t.Fatal("跳走后原格不应再有脉号")
+	}
+}
+
+// 随机对局：走子/悔棋还原脉号与哈希，HasMoves/Mobility 与 GenerateMoves 一致，每脉次数不超上限
+func TestCloneLimitPlayoutsConsistent(t *testing.T) {
+	for _, r := range []Rules{{CloneLimit: 2}, {Cascade: true, CloneLimit: 1}} {
+		withRules(t, r)
+		rng := rand.New(rand.NewSource(4254))
+		for g := 0; g < 6; g++ {
+			gs := NewGameState(boardRadius)
+			for ply := 0; ply < 200 && !gs.GameOver; ply++ {
+				b := gs.Board
+				for _, side := range []CellState{PlayerA, PlayerB} {
+					moves := GenerateMoves(b, side)
+					targets := map[HexCoord]bool{}
+					for _, mv := range moves {
+						targets[mv.To] = true
+					}
+					if b.HasMoves(side) != (len(moves) > 0) || b.Mobility(side) != len(targets) {
+						t.Fatalf("%v 第 %d 手: HasMoves/Mobility 与着法表不一致", r, ply)
+					}
+				}
+				moves := GenerateMoves(b, gs.CurrentPlayer)
+				if len(moves) == 0 {
+					break
+				}
+				h, lin := b.Hash(), b.lin
+				for _, mv := range moves {
+					u := b.makeMove(mv, gs.CurrentPlayer)
+					b.UnmakeMove(u)
+					if b.Hash() != h || b.lin != lin {
+						t.Fatalf("%v %v: 悔棋后脉号或哈希没还原", r, mv)
+					}
+				}
+				if _, _, err := gs.MakeMove(moves[rng.Intn(len(moves))]); err != nil {
+					t.Fatal(err)
+				}
+				for id, n := range gs.Board.lin.used {
+					if int(n) > r.CloneLimit {
+						t.Fatalf("%v: 脉 %d 克隆了 %d 次", r, id, n)
+					}
+				}
+			}
+		}
+	}
+}
+
+// 盘面相同但脉号不同是不同的局面，置换表不能混用；走子顺序不同而脉号相同则哈希一致
+func TestCloneLimitHashDistinguishesLineage(t *testing.T) {
+	withRules(t, Rules{CloneLimit: 3})
+	start := seeBoard(t, []HexCoord{{Q: 0, R: 0}, {Q: 2, R: 0}}, []HexCoord{{Q: -4, R: 4}})
+	play := func(moves ...Move) *Board {
+		b := start.Clone()
+		for _, mv := range moves {
+			b.makeMove(mv, PlayerA)
+		}
+		return b
+	}
+	left := Move{From: HexCoord{Q: 0, R: 0}, To: HexCoord{Q: 1, R: 0}}
+	right := Move{From: HexCoord{Q: 2, R: 0}, To: HexCoord{Q: 1, R: 0}}
+	jump := Move{From: HexCoord{Q: 2, R: 0}, To: HexCoord{Q: 4, R: 0}}
+
+	x, y := play(left), play(right)
+	if x.Cells != y.Cells || x.Hash() == y.Hash() {
+		t.Fatal("两颗不同的子克隆到同一格：盘面相同，哈希应不同")
+	}
+	if play(left, jump).Hash() != play(jump, left).Hash() {
+		t.Fatal("换序到达的同一局面哈希应相同")
+	}
+}
@@ -0,0 +1,56 @@
+// internal/game/mc_winprob.go
+package game
+
+import "time"
+
+// 没有 ONNX 运行时（或模型加载失败）时给界面用的胜率估计：从当前局面做若干次快速随机对局，
+// 统计胜负。只图“大致方向”，精度远不如网络；同一局面两次调用结果会有随机波动。
+
+// mcRolloutCfg 估计胜率用的 rollout 策略：不截断、不碰 NN，保证无 ORT 也能跑
+var mcRolloutCfg = RolloutConfig{Epsilon: 0.25, MaxPlies: 64}
+
+// MCWinProb 从 toMove 走子开始做至多 n 次快速对局，返回 me 的胜率（和棋记 0.5）与实际完成的局数。
+// 到 deadline（零值 = 不限）就提前停下；一局都没完成时返回 0.5。
+func MCWinProb(b *Board, toMove, me CellState, n int, deadline time.Time) (float64, int) {
+	work := b.Clone()
+	sum, done := 0.0, 0
+	for done < n {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			break
+		}
+		sum += rollout(work, toMove, me, true, mcRolloutCfg)
+		done++
+	}
+	if done == 0 {
+		return 0.5, 0
+	}
+	return (sum/float64(done) + 1) / 2, done
+}
+
+// MCMoveWinProbs 对 moves 中每一步各走一次后估计 player 的胜率，每步至多 perMove 局。
+// 到 deadline 前的时间按剩下的着法平分（前面的着法没用完的留给后面），不会让前几步把预算吃光；
+// 仍未来得及估计的着法为 -1。
+func MCMoveWinProbs(b *Board, player CellState, moves []Move, perMove int, deadline time.Time) []float64 {
+	out := make([]float64, len(moves))
+	for i := range out {
+		out[i] = -1
+	}
+	work := b.Clone()
+	for i, mv := range moves {
+		moveDeadline := deadline
+		if !deadline.IsZero() {
+			left := time.Until(deadline)
+			if left <= 0 {
+				break
+			}
+			moveDeadline = time.Now().Add(left / time.Duration(len(moves)-i))
+		}
+		u := mMakeMoveWithUndo(work, mv, player)
+		p, done := MCWinProb(work, Opponent(player), player, perMove, moveDeadline)
+		work.UnmakeMove(u)
+		if done > 0 {
+			out[i] = p
+		}
+	}
+	return out
+}
@@ -0,0 +1,48 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMCWinProb(t *testing.T) {
+	// B 只剩一子且无路可走：A 必胜
+	cells := make([]CellState, CellCount(boardRadius))
+	cells[IndexOf[HexCoord{Q: 0, R: 0}]] = PlayerA
+	cells[IndexOf[HexCoord{Q: -4, R: 4}]] = PlayerA
+	b, err := BoardFromCells(cells)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, n := MCWinProb(b, PlayerB, PlayerA, 20, time.Time{}); n != 20 || p != 1 {
+		t.Fatalf("必胜局面: p=%v n=%d", p, n)
+	}
+	if p, n := MCWinProb(b, PlayerB, PlayerB, 20, time.Time{}); p != 0 || n != 20 {
+		t.Fatalf("必败局面: p=%v n=%d", p, n)
+	}
+
+	// 截止时间已过：一局都不跑，返回 0.5
+	start := NewGameState(boardRadius).Board
+	if p, n := MCWinProb(start, PlayerA, PlayerA, 100, time.Now().Add(-time.Second)); n != 0 || p != 0.5 {
+		t.Fatalf("超时: p=%v n=%d", p, n)
+	}
+
+	moves := GenerateMoves(start, PlayerA)
+	probs := MCMoveWinProbs(start, PlayerA, moves, 5, time.Time{})
+	for i, p := range probs {
+		if p < 0 || p > 1 {
+			t.Fatalf("着法 %v 胜率 %v 越界", moves[i], p)
+		}
+	}
+	if start.CountPieces(PlayerA) != 3 || start.CountPieces(PlayerB) != 3 {
+		t.Fatalf("估计后棋盘应保持原样")
+	}
+
+	// 每步局数远超预算：时间按着法平分，每一步都有估计，不会让第一步独占
+	probs = MCMoveWinProbs(start, PlayerA, moves, 1<<30, time.Now().Add(200*time.Millisecond))
+	for i, p := range probs {
+		if p < 0 {
+			t.Fatalf("着法 %v 没分到时间", moves[i])
+		}
+	}
+}
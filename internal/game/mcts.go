@@ -9,12 +9,12 @@ import (
 
 type mctsNode struct {
 	parent       *mctsNode
-	move         Move      // 走到本节点所下的那步（root 的 move 为零值）
-	playerToMove CellState // 轮到谁落子（在“进入本节点的局面”）
-	children     map[Move]*mctsNode
-	prior        float64 // 先验（这里先均匀 = 1/len）
+	move         Move        // 走到本节点所下的那步（root 的 move 为零值）
+	playerToMove CellState   // 轮到谁落子（在“进入本节点的局面”）
+	children     []*mctsNode // 按展开顺序排列，走法存在 child.move 里；遍历顺序确定，便于复现
+	prior        float64     // 先验（这里先均匀 = 1/len）
 	visits       int
-	valueSum     float64 // 累积价值（从 rootPlayer 视角）
+	valueSum     float64 // 累积价值（从“走进本节点的一方”视角，父节点选子时直接取最大）
 	unexpanded   []Move  // 还未展开的走法
 	hash         uint64  // 可选：用来做跨层转置表
 	terminal     bool
@@ -23,24 +23,16 @@ type mctsNode struct {
 	aiCanJump  bool      // 是否允许 AI 方在本次搜索里考虑跳越
 }
 
-func newNode(b *Board, player CellState, parent *mctsNode, mv Move, rootPlayer CellState, aiCanJump bool) *mctsNode {
-	mvs := GenerateMoves(b, player)
-	mvs = filterMovesForSide(b, player, rootPlayer, aiCanJump, mvs)
-
-	n := &mctsNode{
-		parent:       parent,
-		move:         mv,
-		playerToMove: player,
-		children:     make(map[Move]*mctsNode),
-		unexpanded:   make([]Move, 0, len(mvs)),
-		hash:         b.Hash(),
-		terminal:     len(mvs) == 0,
-		rootPlayer:   rootPlayer,
-		aiCanJump:    aiCanJump,
+// child 按走法找子节点（线性查找，只在根或调试时用）
+func (n *mctsNode) child(mv Move) *mctsNode {
+	for _, ch := range n.children {
+		if ch.move == mv {
+			return ch
+		}
 	}
-	n.unexpanded = append(n.unexpanded, mvs...)
-	return n
+	return nil
 }
+
 func (n *mctsNode) q() float64 {
 	if n.visits == 0 {
 		return 0
@@ -48,31 +40,54 @@ func (n *mctsNode) q() float64 {
 	return n.valueSum / float64(n.visits)
 }
 
-// UCT 选择（用 prior 当成 c_puct 里的 P；纯 MCTS 时取均匀）
+// UCT 选择（用 prior 当成 c_puct 里的 P；纯 MCTS 时取均匀）。同分取先展开的子节点
 func selectChild(n *mctsNode, cPUCT float64) (Move, *mctsNode) {
-	var best Move
 	var bestChild *mctsNode
 	bestScore := -math.MaxFloat64
-	parentVisits := math.Max(1, float64(n.visits))
-	for mv, ch := range n.children {
-		u := cPUCT * ch.prior * math.Sqrt(parentVisits) / (1.0 + float64(ch.visits))
-		score := ch.q() + u
+	sqrtParent := cPUCT * math.Sqrt(math.Max(1, float64(n.visits)))
+	for _, ch := range n.children {
+		score := ch.q() + ch.prior*sqrtParent/(1.0+float64(ch.visits))
 		if score > bestScore {
 			bestScore = score
-			best = mv
 			bestChild = ch
 		}
 	}
-	return best, bestChild
+	if bestChild == nil {
+		return Move{}, nil
+	}
+	return bestChild.move, bestChild
 }
 
+// RolloutConfig：rollout 走子策略。TopK<=0 时只用下面的简单随机策略；
+// 否则以 Epsilon 概率走简单策略，其余时间在廉价增量评估的 top-k 里随机挑一步。
+// NNCutoff>0 时 rollout 走满 NNCutoff 步就提前停下，回传 NN 价值与子数差结果的混合。
+type RolloutConfig struct {
+	Epsilon float64
+	TopK    int
+
+	MaxPlies int     // rollout 步数上限（<=0 取 64）
+	NNCutoff int     // 提前截断步数 M（0 = 不截断）
+	NNBlend  float64 // 截断时 NN 价值的权重 [0,1]，其余权重给子数差结果
+}
+
+var rolloutCfg = RolloutConfig{Epsilon: 0.25, TopK: 0, MaxPlies: 64, NNBlend: 0.7}
+
+// SetRolloutConfig 改默认 rollout 策略（FindBestMoveMCTS 等入口用）；
+// 同一进程里要让不同对局用不同策略，改用 FindBestMoveMCTSRollout 逐次传入
+func SetRolloutConfig(c RolloutConfig) { rolloutCfg = c }
+
 // 简单的 rollout 策略：优先克隆、丢弃0感染跳、否则随机
-func rolloutPolicy(b *Board, side, rootPlayer CellState, aiCanJump bool) (Move, bool) {
-	mvs := GenerateMoves(b, side)
+func rolloutPolicy(b *Board, side, rootPlayer CellState, aiCanJump bool, cfg RolloutConfig) (Move, bool) {
+	buf := acquireMoves()
+	mvs := GenerateMovesInto(b, side, *buf)
+	defer releaseMoves(buf, mvs)
 	mvs = filterMovesForSide(b, side, rootPlayer, aiCanJump, mvs)
 	if len(mvs) == 0 {
 		return Move{}, false
 	}
+	if cfg.TopK > 0 && rand.Float64() >= cfg.Epsilon {
+		return rolloutGreedyTopK(b, side, mvs, cfg.TopK), true
+	}
 	// 先选克隆
 	clones := mvs[:0]
 	for _, m := range mvs {
@@ -99,19 +114,62 @@ func rolloutPolicy(b *Board, side, rootPlayer CellState, aiCanJump bool) (Move,
 	return cand[rand.Intn(len(cand))], true
 }
 
-// 模拟到终局或步限，返回 [-1,1] 结果（rootPlayer 视角）
-func rollout(b *Board, toMove, rootPlayer CellState, aiCanJump bool, maxPlies int) float64 {
+// rolloutGain：走完这步后子数差的变化量（克隆 +1，每感染一颗 +2）
+func rolloutGain(b *Board, m Move, side CellState) int {
+	g := 2 * previewInfectedCount(b, m, side)
+	if m.IsClone() {
+		g++
+	}
+	return g
+}
+
+// 在 mvs 里按 rolloutGain 选出前 k 名，再从中均匀随机一步（原地部分选择排序，k 很小）
+func rolloutGreedyTopK(b *Board, side CellState, mvs []Move, k int) Move {
+	if k > len(mvs) {
+		k = len(mvs)
+	}
+	var gainBuf [64]int // 常见着法数放得下，避免每步分配
+	gains := gainBuf[:0]
+	for _, m := range mvs {
+		gains = append(gains, rolloutGain(b, m, side))
+	}
+	for i := 0; i < k; i++ {
+		bi := i
+		for j := i + 1; j < len(mvs); j++ {
+			if gains[j] > gains[bi] {
+				bi = j
+			}
+		}
+		mvs[i], mvs[bi] = mvs[bi], mvs[i]
+		gains[i], gains[bi] = gains[bi], gains[i]
+	}
+	return mvs[rand.Intn(k)]
+}
+
+// 模拟到终局或步限，返回 [-1,1] 结果（rootPlayer 视角）；返回前把棋盘还原
+func rollout(b *Board, toMove, rootPlayer CellState, aiCanJump bool, cfg RolloutConfig) float64 {
 	cur := toMove
 	canJump := aiCanJump // 模拟过程中可动态解锁
 
+	maxPlies := cfg.MaxPlies
+	if maxPlies <= 0 {
+		maxPlies = 64
+	}
+	cutoff := cfg.NNCutoff > 0 && cfg.NNCutoff < maxPlies
+	if cutoff {
+		maxPlies = cfg.NNCutoff
+	}
+	undos := make([]undoInfo, 0, maxPlies)
+
 	for ply := 0; ply < maxPlies; ply++ {
 		// rolloutPolicy 内部会在 side==rootPlayer 且 !canJump 时过滤掉跳越
-		mv, ok := rolloutPolicy(b, cur, rootPlayer, canJump)
+		mv, ok := rolloutPolicy(b, cur, rootPlayer, canJump, cfg)
 		if !ok {
+			cutoff = false // 真正走到了终局，不需要 NN
 			break
 		}
 
-		u := mMakeMoveWithUndo(b, mv, cur)
+		undos = append(undos, mMakeMoveWithUndo(b, mv, cur))
 
 		// 动态解锁：如果刚才走子的是“对手”（相对 rootPlayer）
 		// 且他这步感染了我方，那么之后允许 AI 跳越
@@ -120,30 +178,60 @@ func rollout(b *Board, toMove, rootPlayer CellState, aiCanJump bool, maxPlies in
 		}
 
 		cur = Opponent(cur)
-		b.UnmakeMove(u)
 	}
 
-	// 终结评分：仅子数差（rootPlayer 视角）
+	// 终结评分：子数差（rootPlayer 视角）
 	diff := b.CountPieces(rootPlayer) - b.CountPieces(Opponent(rootPlayer))
+	v := 0.0
 	if diff > 0 {
-		return 1
+		v = 1
 	} else if diff < 0 {
-		return -1
+		v = -1
+	}
+
+	// 提前截断：混入 NN 价值（NN 不可用时只用子数差）
+	if cutoff && cfg.NNBlend > 0 {
+		if p, err := KataWinProb(b, cur); err == nil {
+			nv := float64(p)*2 - 1 // cur 视角 → rootPlayer 视角
+			if cur != rootPlayer {
+				nv = -nv
+			}
+			v = cfg.NNBlend*nv + (1-cfg.NNBlend)*v
+		}
+	}
+
+	for i := len(undos) - 1; i >= 0; i-- {
+		b.UnmakeMove(undos[i])
 	}
-	return 0
+	return v
 }
 
 // 主入口：给定迭代次数或时间预算，返回访问最多的子
 func FindBestMoveMCTS(rootBoard *Board, player CellState, sims int, timeBudget time.Duration, allowJump bool) (Move, bool) {
+	return FindBestMoveMCTSRollout(rootBoard, player, sims, timeBudget, allowJump, rolloutCfg)
+}
+
+// FindBestMoveMCTSRollout 同 FindBestMoveMCTS，但 rollout 策略由调用方给定，不读默认值
+func FindBestMoveMCTSRollout(rootBoard *Board, player CellState, sims int, timeBudget time.Duration, allowJump bool, rcfg RolloutConfig) (Move, bool) {
 	if sims <= 0 && timeBudget <= 0 {
 		sims = 2000
 	}
 	rand.Seed(time.Now().UnixNano())
 
+	ar := acquireArena()
+	defer releaseArena(ar)
+	root := runMCTS(ar, rootBoard, player, sims, timeBudget, allowJump, rcfg)
+	return mostVisitedChild(root)
+}
+
+// runMCTS 执行纯 rollout 版 MCTS，rollout 按 rcfg 走，返回搜索完的根节点（供调用方读取访问分布）。
+// 节点都分在 ar 里，调用方用完根节点后再 releaseArena。
+func runMCTS(ar *nodeArena, rootBoard *Board, player CellState, sims int, timeBudget time.Duration, allowJump bool, rcfg RolloutConfig) *mctsNode {
 	// 根节点闸门：由 UI 持久传入，不看 LastInfect
 	aiCanJump := allowJump
 
-	root := newNode(rootBoard, player, nil, Move{}, player, aiCanJump)
+	root := ar.newNode(rootBoard, player, nil, Move{}, player, aiCanJump)
+	path := make([]undoInfo, 0, 128)
 
 	deadline := time.Now().Add(timeBudget)
 	for iter := 0; ; iter++ {
@@ -156,11 +244,11 @@ func FindBestMoveMCTS(rootBoard *Board, player CellState, sims int, timeBudget t
 
 		b := rootBoard.Clone()
 		cur := root
-		path := make([]undoInfo, 0, 128)
+		path = path[:0]
 
 		// Selection
 		for !cur.terminal && len(cur.unexpanded) == 0 && len(cur.children) > 0 {
-			mv, child := selectChild(cur, 1.4)
+			mv, child := selectChild(cur, mctsCPUCT)
 			u := mMakeMoveWithUndo(b, mv, cur.playerToMove)
 			path = append(path, u)
 			cur = child
@@ -175,7 +263,7 @@ func FindBestMoveMCTS(rootBoard *Board, player CellState, sims int, timeBudget t
 			u := mMakeMoveWithUndo(b, mv, cur.playerToMove)
 			path = append(path, u)
 
-			child := newNode(b, Opponent(cur.playerToMove), cur, mv, root.rootPlayer, root.aiCanJump)
+			child := ar.newNode(b, Opponent(cur.playerToMove), cur, mv, root.rootPlayer, root.aiCanJump)
 
 			total := len(child.unexpanded) + len(child.children)
 			prior := 1.0
@@ -184,12 +272,12 @@ func FindBestMoveMCTS(rootBoard *Board, player CellState, sims int, timeBudget t
 			}
 			child.prior = prior
 
-			cur.children[mv] = child
+			cur.children = append(cur.children, child)
 			cur = child
 		}
 
 		// Evaluation / Rollout（用根的闸门；不在模拟中改写它）
-		v := rollout(b, cur.playerToMove, root.rootPlayer, root.aiCanJump, 64)
+		v := rollout(b, cur.playerToMove, root.rootPlayer, root.aiCanJump, rcfg)
 
 		// 回溯
 		for i := len(path) - 1; i >= 0; i-- {
@@ -199,23 +287,27 @@ func FindBestMoveMCTS(rootBoard *Board, player CellState, sims int, timeBudget t
 		// Backup
 		for n := cur; n != nil; n = n.parent {
 			n.visits++
-			if n.playerToMove == player {
+			if n.playerToMove != player {
 				n.valueSum += v
 			} else {
 				n.valueSum -= v
 			}
 		}
 	}
+	return root
+}
 
+// mostVisitedChild 返回根下访问次数最多的走法
+func mostVisitedChild(root *mctsNode) (Move, bool) {
 	if len(root.children) == 0 {
 		return Move{}, false
 	}
 	var best Move
 	bestN := -1
-	for mv, ch := range root.children {
+	for _, ch := range root.children {
 		if ch.visits > bestN {
 			bestN = ch.visits
-			best = mv
+			best = ch.move
 		}
 	}
 	return best, true
@@ -224,20 +316,47 @@ func FindBestMoveMCTS(rootBoard *Board, player CellState, sims int, timeBudget t
 // FindBestMoveMCTSWithVisits：带 root 访问计数分布的 MCTS（可选 NN 先验）
 // 返回：最佳走法、每个 9x9 格的访问次数（未在棋盘上的格子为 0）、是否成功找到走法
 func FindBestMoveMCTSWithVisits(rootBoard *Board, player CellState, sims int, timeBudget time.Duration, allowJump bool) (Move, []int, bool) {
+	rand.Seed(time.Now().UnixNano())
+	rv, ok := RunMCTSRoot(rootBoard, player, sims, timeBudget, allowJump, MCTSOptions{})
+	if !ok {
+		return Move{}, nil, false
+	}
+	var best Move
+	bestN := -1
+	visits := make([]int, GridSize*GridSize)
+	for _, r := range rv {
+		if r.Visits > bestN {
+			bestN = r.Visits
+			best = r.Move
+		}
+		idx := AxialToIndex(r.Move.To)
+		if idx >= 0 && idx < len(visits) {
+			visits[idx] = r.Visits
+		}
+	}
+	return best, visits, true
+}
+
+// RunMCTSRoot 同 FindBestMoveMCTSWithVisits 的搜索，但按走法返回根节点统计，
+// 并支持自博弈用的根噪声 / 强制 playout（见 MCTSOptions）。结果按走法排序，便于复现。
+func RunMCTSRoot(rootBoard *Board, player CellState, sims int, timeBudget time.Duration, allowJump bool, opt MCTSOptions) ([]RootVisit, bool) {
 	if sims <= 0 && timeBudget <= 0 {
 		sims = 800
 	}
-	rand.Seed(time.Now().UnixNano())
 
 	aiCanJump := allowJump
 
-	root := newNode(rootBoard, player, nil, Move{}, player, aiCanJump)
+	ar := acquireArena()
+	defer releaseArena(ar)
+	root := ar.newNode(rootBoard, player, nil, Move{}, player, aiCanJump)
+	pathUndos := make([]undoInfo, 0, 128)
 
 	// 根节点 NN 先验（softmax 概率）；失败则退化为均匀
-	rootPrior, _, err := PolicyValueNN(rootBoard, player)
+	rootPrior, _, err := opt.policyValue(rootBoard, player)
 	if err != nil || len(rootPrior) != GridSize*GridSize {
 		rootPrior = nil
 	}
+	noise := opt.rootNoise(root.unexpanded)
 
 	deadline := time.Now().Add(timeBudget)
 	for iter := 0; ; iter++ {
@@ -251,11 +370,17 @@ func FindBestMoveMCTSWithVisits(rootBoard *Board, player CellState, sims int, ti
 		b := rootBoard.Clone()
 		cur := root
 		playerToMove := player
-		pathUndos := make([]undoInfo, 0, 128)
+		pathUndos = pathUndos[:0]
 
-		// Selection
+		// Selection（根节点先满足强制 playout 下限）
 		for !cur.terminal && len(cur.unexpanded) == 0 && len(cur.children) > 0 {
-			mv, child := selectChild(cur, 1.4)
+			mv, child := Move{}, (*mctsNode)(nil)
+			if cur == root {
+				mv, child = opt.forcedChild(root)
+			}
+			if child == nil {
+				mv, child = selectChild(cur, mctsCPUCT)
+			}
 			u := mMakeMoveWithUndo(b, mv, playerToMove)
 			pathUndos = append(pathUndos, u)
 			playerToMove = Opponent(playerToMove)
@@ -271,9 +396,9 @@ func FindBestMoveMCTSWithVisits(rootBoard *Board, player CellState, sims int, ti
 			u := mMakeMoveWithUndo(b, mv, playerToMove)
 			pathUndos = append(pathUndos, u)
 
-			child := newNode(b, Opponent(playerToMove), cur, mv, root.rootPlayer, root.aiCanJump)
+			child := ar.newNode(b, Opponent(playerToMove), cur, mv, root.rootPlayer, root.aiCanJump)
 
-			// 设置先验：根节点用 NN，其他节点均匀
+			// 设置先验：根节点用 NN（再混入噪声），其他节点均匀
 			pr := 1.0
 			if cur.parent == nil && rootPrior != nil {
 				idx := AxialToIndex(mv.To)
@@ -286,9 +411,12 @@ func FindBestMoveMCTSWithVisits(rootBoard *Board, player CellState, sims int, ti
 					pr = 1.0 / float64(total)
 				}
 			}
+			if cur == root && noise != nil {
+				pr = (1-opt.NoiseFrac)*pr + opt.NoiseFrac*noise[mv]
+			}
 			child.prior = pr
 
-			cur.children[mv] = child
+			cur.children = append(cur.children, child)
 			cur = child
 			playerToMove = Opponent(playerToMove)
 		}
@@ -306,7 +434,7 @@ func FindBestMoveMCTSWithVisits(rootBoard *Board, player CellState, sims int, ti
 				leafValue = 0.0
 			}
 		} else {
-			vProb := float64(EvaluateNN3(b, playerToMove)) / 100.0 // 当前行棋方胜率
+			vProb := opt.winProb(b, playerToMove) // 当前行棋方胜率
 			if playerToMove != root.rootPlayer {
 				vProb = 1.0 - vProb
 			}
@@ -316,7 +444,7 @@ func FindBestMoveMCTSWithVisits(rootBoard *Board, player CellState, sims int, ti
 		// Backup
 		for n := cur; n != nil; n = n.parent {
 			n.visits++
-			if n.playerToMove == root.rootPlayer {
+			if n.playerToMove != root.rootPlayer {
 				n.valueSum += leafValue
 			} else {
 				n.valueSum -= leafValue
@@ -330,40 +458,16 @@ func FindBestMoveMCTSWithVisits(rootBoard *Board, player CellState, sims int, ti
 	}
 
 	if len(root.children) == 0 {
-		return Move{}, nil, false
+		return nil, false
 	}
-	var best Move
-	bestN := -1
-	for mv, ch := range root.children {
-		if ch.visits > bestN {
-			bestN = ch.visits
-			best = mv
-		}
-	}
-
-	visits := make([]int, GridSize*GridSize)
-	for mv, ch := range root.children {
-		idx := AxialToIndex(mv.To)
-		if idx >= 0 && idx < len(visits) {
-			visits[idx] = ch.visits
-		}
-	}
-	return best, visits, true
+	return opt.rootVisits(root), true
 }
 
 // 仅当 side==rootPlayer 且 aiCanJump==false 时，过滤掉跳越（保底：若没有克隆则不删）
 func filterMovesForSide(b *Board, side, rootPlayer CellState, aiCanJump bool, moves []Move) []Move {
-	if side != rootPlayer || aiCanJump {
+	if side != rootPlayer {
 		return moves
 	}
-	clones := make([]Move, 0, len(moves))
-	for _, m := range moves {
-		if m.IsClone() {
-			clones = append(clones, m)
-		}
-	}
-	if len(clones) > 0 {
-		return clones
-	}
-	return moves
+	// 原地过滤：只剩跳越时不拦
+	return filterJumpsByFlag(b, side, moves, aiCanJump)
 }
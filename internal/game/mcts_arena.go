@@ -0,0 +1,77 @@
+// internal/game/mcts_arena.go
+package game
+
+import "sync"
+
+// MCTS 节点竞技场：一次搜索里所有节点、未展开走法、子节点指针都从整块 slab 里切，
+// 不再逐个分配；搜索结束后整块放回池子，下一次搜索直接复用已有的 slab。
+// 放回后树上的指针全部失效，调用方必须先把需要的统计拷出来。
+
+const arenaChunk = 1024
+
+// slab 只增不减的分块缓冲；take 返回连续 k 个清零元素（cap 恰为 k，append 不会越界写到别人）
+type slab[T any] struct {
+	chunks [][]T
+	c, n   int // 当前块下标、块内已用数
+}
+
+func (s *slab[T]) take(k int) []T {
+	if k == 0 {
+		return nil
+	}
+	for {
+		if s.c == len(s.chunks) {
+			s.chunks = append(s.chunks, make([]T, max(arenaChunk, k)))
+		}
+		if ch := s.chunks[s.c]; s.n+k <= len(ch) {
+			out := ch[s.n : s.n+k : s.n+k]
+			s.n += k
+			clear(out)
+			return out
+		}
+		s.c, s.n = s.c+1, 0
+	}
+}
+
+func (s *slab[T]) reset() { s.c, s.n = 0, 0 }
+
+type nodeArena struct {
+	nodes slab[mctsNode]
+	moves slab[Move]
+	kids  slab[*mctsNode]
+}
+
+var arenaPool = sync.Pool{New: func() any { return new(nodeArena) }}
+
+func acquireArena() *nodeArena { return arenaPool.Get().(*nodeArena) }
+
+// releaseArena 整块回收；此后该竞技场分出的节点不可再访问
+func releaseArena(a *nodeArena) {
+	a.nodes.reset()
+	a.moves.reset()
+	a.kids.reset()
+	arenaPool.Put(a)
+}
+
+// newNode 从竞技场分一个节点：走法列表与子节点指针按着法数一次切够，展开时不再扩容
+func (a *nodeArena) newNode(b *Board, player CellState, parent *mctsNode, mv Move, rootPlayer CellState, aiCanJump bool) *mctsNode {
+	buf := acquireMoves()
+	mvs := GenerateMovesInto(b, player, *buf)
+	defer releaseMoves(buf, mvs)
+	mvs = filterMovesForSide(b, player, rootPlayer, aiCanJump, mvs)
+
+	n := &a.nodes.take(1)[0]
+	*n = mctsNode{
+		parent:       parent,
+		move:         mv,
+		playerToMove: player,
+		unexpanded:   a.moves.take(len(mvs)),
+		children:     a.kids.take(len(mvs))[:0],
+		hash:         b.Hash(),
+		terminal:     len(mvs) == 0,
+		rootPlayer:   rootPlayer,
+		aiCanJump:    aiCanJump,
+	}
+	copy(n.unexpanded, mvs)
+	return n
+}
@@ -0,0 +1,55 @@
+package game
+
+import "testing"
+
+func TestSlabTake(t *testing.T) {
+	var s slab[int]
+	a := s.take(3)
+	if len(a) != 3 || cap(a) != 3 {
+		t.Fatalf("take(3) 得到 len=%d cap=%d", len(a), cap(a))
+	}
+	a[0] = 7
+	big := s.take(arenaChunk + 5) // 超过块大小时单独开一块
+	if len(big) != arenaChunk+5 {
+		t.Fatalf("大块长度 %d", len(big))
+	}
+	s.reset()
+	if b := s.take(3); b[0] != 0 || &b[0] != &a[0] {
+		t.Fatalf("reset 后应复用原块并清零")
+	}
+	if s.take(0) != nil {
+		t.Fatalf("take(0) 应返回 nil")
+	}
+}
+
+// 竞技场复用后再搜一次，树结构应与新分配时一样完整
+func TestArenaReuseAcrossSearches(t *testing.T) {
+	b := NewGameState(boardRadius).Board
+	for i := 0; i < 2; i++ {
+		ar := acquireArena()
+		root := runMCTS(ar, b, PlayerA, 200, 0, true, rolloutCfg)
+		total := 0
+		for _, ch := range root.children {
+			if ch.parent != root {
+				t.Fatalf("子节点 parent 指错")
+			}
+			total += ch.visits
+		}
+		if root.visits != 200 || total != 200 {
+			t.Fatalf("第 %d 次搜索: 根访问 %d，子访问合计 %d", i, root.visits, total)
+		}
+		releaseArena(ar)
+	}
+}
+
+// go test -bench MCTS10k -benchtime 3x -run ^$ ./internal/game
+func BenchmarkMCTS10k(b *testing.B) {
+	bd := NewGameState(boardRadius).Board
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ar := acquireArena()
+		runMCTS(ar, bd, PlayerA, 10000, 0, true, rolloutCfg)
+		releaseArena(ar)
+	}
+	b.ReportMetric(float64(10000*b.N)/b.Elapsed().Seconds(), "sims/s")
+}
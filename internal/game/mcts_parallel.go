@@ -0,0 +1,297 @@
+// game/mcts_parallel.go
+package game
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 多协程 MCTS：所有协程共享一棵树。节点的访问数、价值和、虚拟损失都是原子量，选择和回传不加锁；
+// 下降时给沿途节点加一次虚拟损失（当作这次访问先输了），让同时下降的协程分散到不同分支，
+// 回传时撤掉。节点第一次被访问时一次展开全部子节点（先验均匀），用 CAS 保证只有一个协程展开，
+// 没抢到的协程把它当叶子直接估值。
+//
+// 叶子估值二选一：各协程自己 rollout（与 FindBestMoveMCTS 相同的策略），
+// 或交给 nnLeafQueue 攒批，凑够一批再调一次 KataBatchValueScore，而不是一个叶子跑一次网络。
+//
+// 节点直接 new，不走 nodeArena（竞技场的 slab 不是并发安全的）。
+
+// ParallelMCTSConfig 多协程 MCTS 的参数
+type ParallelMCTSConfig struct {
+	Workers   int           // 协程数，<=0 取 GOMAXPROCS
+	NNLeaves  bool          // 叶子用 NN 价值（攒批，见 nnLeafQueue）；false 用 rollout
+	BatchWait time.Duration // 攒批时等同伴的最长时间，<=0 取 1ms
+}
+
+const mctsVirtualLoss = 1.0 // 每个在途访问按输一局计
+
+type pNode struct {
+	parent       *pNode
+	move         Move
+	playerToMove CellState
+	prior        float64
+
+	visits   atomic.Int32
+	vloss    atomic.Int32  // 正在经过本节点、还没回传的访问数
+	valueSum atomic.Uint64 // float64 位模式；从“走进本节点的一方”视角
+
+	state    atomic.Int32 // pUnexpanded → pExpanding → pExpanded
+	children []*pNode     // state == pExpanded 之后只读；没有子节点即终局
+}
+
+const (
+	pUnexpanded = iota
+	pExpanding
+	pExpanded
+)
+
+func (n *pNode) addValue(v float64) {
+	for {
+		old := n.valueSum.Load()
+		if n.valueSum.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+// pSelect PUCT 选子；在途访问按 mctsVirtualLoss 计入访问数和价值。同分取先生成的
+func pSelect(n *pNode) *pNode {
+	var best *pNode
+	bestScore := -math.MaxFloat64
+	sqrtParent := mctsCPUCT * math.Sqrt(math.Max(1, float64(n.visits.Load()+n.vloss.Load())))
+	for _, ch := range n.children {
+		vl := float64(ch.vloss.Load())
+		visits := float64(ch.visits.Load()) + vl
+		q := 0.0
+		if visits > 0 {
+			q = (math.Float64frombits(ch.valueSum.Load()) - vl*mctsVirtualLoss) / visits
+		}
+		score := q + ch.prior*sqrtParent/(1+visits)
+		if score > bestScore {
+			best, bestScore = ch, score
+		}
+	}
+	return best
+}
+
+// expand 按 b（即 n 的局面）生成全部子节点；返回 false 表示别的协程正在展开或已展开
+func (n *pNode) expand(b *Board, rootPlayer CellState, aiCanJump bool) bool {
+	if !n.state.CompareAndSwap(pUnexpanded, pExpanding) {
+		return false
+	}
+	buf := acquireMoves()
+	mvs := GenerateMovesInto(b, n.playerToMove, *buf)
+	mvs = filterMovesForSide(b, n.playerToMove, rootPlayer, aiCanJump, mvs)
+	kids := make([]pNode, len(mvs))
+	n.children = make([]*pNode, len(mvs))
+	for i, mv := range mvs {
+		kids[i] = pNode{parent: n, move: mv, playerToMove: Opponent(n.playerToMove), prior: 1 / float64(len(mvs))}
+		n.children[i] = &kids[i]
+	}
+	releaseMoves(buf, mvs)
+	n.state.Store(pExpanded)
+	return true
+}
+
+// FindBestMoveMCTSParallel 多协程版 FindBestMoveMCTS：sims 为全部协程合计的模拟次数
+func FindBestMoveMCTSParallel(rootBoard *Board, player CellState, sims int, timeBudget time.Duration, allowJump bool, cfg ParallelMCTSConfig) (Move, bool) {
+	if sims <= 0 && timeBudget <= 0 {
+		sims = 2000
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.GOMAXPROCS(0)
+	}
+	var q *nnLeafQueue
+	if cfg.NNLeaves && ensureKataONNX() == nil {
+		q = newNNLeafQueue(min(cfg.Workers, maxBatchSize), cfg.BatchWait, KataBatchValueScore)
+		defer q.close()
+	}
+	root := runParallelMCTS(rootBoard, player, sims, timeBudget, allowJump, cfg.Workers, rolloutCfg, q)
+	return mostVisitedPChild(root)
+}
+
+// runParallelMCTS q 为 nil 时叶子走 rollout；返回搜索完的根节点
+func runParallelMCTS(rootBoard *Board, player CellState, sims int, timeBudget time.Duration, allowJump bool, workers int, rcfg RolloutConfig, q *nnLeafQueue) *pNode {
+	root := &pNode{playerToMove: player}
+	deadline := time.Now().Add(timeBudget)
+	var claimed atomic.Int64
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b := rootBoard.Clone()
+			defer releaseBoard(b)
+			path := make([]undoInfo, 0, 128)
+			for {
+				if sims > 0 && claimed.Add(1) > int64(sims) {
+					return
+				}
+				if timeBudget > 0 && time.Now().After(deadline) {
+					return
+				}
+
+				// Selection：沿途加虚拟损失
+				cur := root
+				cur.vloss.Add(1)
+				path = path[:0]
+				for cur.state.Load() == pExpanded && len(cur.children) > 0 {
+					child := pSelect(cur)
+					path = append(path, mMakeMoveWithUndo(b, child.move, cur.playerToMove))
+					cur = child
+					cur.vloss.Add(1)
+				}
+
+				// Expansion：抢到的协程展开；终局或没抢到的都直接估值
+				cur.expand(b, player, allowJump)
+				var v float64 // player 视角 [-1,1]
+				if cur.state.Load() == pExpanded && len(cur.children) == 0 {
+					v = pieceDiffValue(b, player)
+				} else if q != nil {
+					v = q.eval(b, cur.playerToMove)
+					if cur.playerToMove != player {
+						v = -v
+					}
+				} else {
+					v = rollout(b, cur.playerToMove, player, allowJump, rcfg)
+				}
+
+				for i := len(path) - 1; i >= 0; i-- {
+					b.UnmakeMove(path[i])
+				}
+
+				// Backup：撤掉虚拟损失
+				for n := cur; n != nil; n = n.parent {
+					if n.playerToMove != player {
+						n.addValue(v)
+					} else {
+						n.addValue(-v)
+					}
+					n.visits.Add(1)
+					n.vloss.Add(-1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return root
+}
+
+// pieceDiffValue 终局按子数差记 ±1/0（player 视角）
+func pieceDiffValue(b *Board, player CellState) float64 {
+	diff := b.CountPieces(player) - b.CountPieces(Opponent(player))
+	switch {
+	case diff > 0:
+		return 1
+	case diff < 0:
+		return -1
+	}
+	return 0
+}
+
+// mostVisitedPChild 返回根下访问次数最多的走法
+func mostVisitedPChild(root *pNode) (Move, bool) {
+	if root.state.Load() != pExpanded || len(root.children) == 0 {
+		return Move{}, false
+	}
+	best := root.children[0]
+	for _, ch := range root.children[1:] {
+		if ch.visits.Load() > best.visits.Load() {
+			best = ch
+		}
+	}
+	return best.move, true
+}
+
+// nnLeafQueue 把各协程的叶子估值请求攒成批：凑满 batch 个或等够 wait 就按执子方分组各跑一次 infer。
+// 请求方阻塞到拿到结果为止，棋盘在此期间不会被改动，所以不用拷贝
+type nnLeafQueue struct {
+	reqs  chan leafReq
+	batch int
+	wait  time.Duration
+	infer func(boards []*Board, me CellState) ([]int, error) // KataBatchValueScore 的签名：me 视角，±1000
+	done  chan struct{}
+}
+
+type leafReq struct {
+	b   *Board
+	me  CellState
+	out chan float64
+}
+
+func newNNLeafQueue(batch int, wait time.Duration, infer func([]*Board, CellState) ([]int, error)) *nnLeafQueue {
+	if batch < 1 {
+		batch = 1
+	}
+	if wait <= 0 {
+		wait = time.Millisecond
+	}
+	q := &nnLeafQueue{reqs: make(chan leafReq, batch), batch: batch, wait: wait, infer: infer, done: make(chan struct{})}
+	go q.loop()
+	return q
+}
+
+// eval 返回 me 视角的价值 [-1,1]；推理失败按 0 计（与 EvaluateNN3 失败时一样不偏向任何一方）
+func (q *nnLeafQueue) eval(b *Board, me CellState) float64 {
+	out := make(chan float64, 1)
+	q.reqs <- leafReq{b: b, me: me, out: out}
+	return <-out
+}
+
+// close 所有请求方都返回之后调用
+func (q *nnLeafQueue) close() {
+	close(q.reqs)
+	<-q.done
+}
+
+func (q *nnLeafQueue) loop() {
+	defer close(q.done)
+	pending := make([]leafReq, 0, q.batch)
+	for first := range q.reqs {
+		pending = append(pending[:0], first)
+		timer := time.NewTimer(q.wait)
+	collect:
+		for len(pending) < q.batch {
+			select {
+			case r, ok := <-q.reqs:
+				if !ok {
+					break collect
+				}
+				pending = append(pending, r)
+			case <-timer.C:
+				break collect
+			}
+		}
+		timer.Stop()
+		q.run(pending)
+	}
+}
+
+// run 按执子方分组（一次推理只能有一个视角）
+func (q *nnLeafQueue) run(reqs []leafReq) {
+	for _, me := range [2]CellState{PlayerA, PlayerB} {
+		var boards []*Board
+		var outs []chan float64
+		for _, r := range reqs {
+			if r.me == me {
+				boards = append(boards, r.b)
+				outs = append(outs, r.out)
+			}
+		}
+		if len(boards) == 0 {
+			continue
+		}
+		scores, err := q.infer(boards, me)
+		for i, out := range outs {
+			v := 0.0
+			if err == nil && i < len(scores) {
+				v = float64(scores[i]) / 1000
+			}
+			out <- v
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package game
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// checkPTree 回传完后虚拟损失全部撤掉，父节点访问数 = 子节点合计 + 停在本节点估值的次数
+func checkPTree(t *testing.T, n *pNode) {
+	t.Helper()
+	if vl := n.vloss.Load(); vl != 0 {
+		t.Fatalf("走法 %v: 残留虚拟损失 %d", n.move, vl)
+	}
+	total := int32(0)
+	for _, ch := range n.children {
+		if ch.parent != n {
+			t.Fatalf("子节点 parent 指错")
+		}
+		total += ch.visits.Load()
+		checkPTree(t, ch)
+	}
+	if total > n.visits.Load() {
+		t.Fatalf("走法 %v: 子访问合计 %d > 本节点 %d", n.move, total, n.visits.Load())
+	}
+}
+
+func TestParallelMCTSRollout(t *testing.T) {
+	st := NewGameState(boardRadius)
+	const sims = 400
+	root := runParallelMCTS(st.Board, PlayerA, sims, 0, true, 4, rolloutCfg, nil)
+	if got := root.visits.Load(); got != sims {
+		t.Fatalf("根访问 %d，期望 %d", got, sims)
+	}
+	checkPTree(t, root)
+	mv, ok := mostVisitedPChild(root)
+	if !ok {
+		t.Fatal("开局应有着可走")
+	}
+	if err := ValidateMove(st, mv); err != nil {
+		t.Fatalf("最佳着法 %v 非法: %v", mv, err)
+	}
+}
+
+// NN 叶子走攒批队列：用假的批量估值代替 KataBatchValueScore，检查确实成批调用且视角分组正确
+func TestParallelMCTSBatchedLeaves(t *testing.T) {
+	var mu sync.Mutex
+	calls, leaves := 0, 0
+	fake := func(boards []*Board, me CellState) ([]int, error) {
+		mu.Lock()
+		calls++
+		leaves += len(boards)
+		mu.Unlock()
+		out := make([]int, len(boards))
+		for i, bd := range boards {
+			out[i] = 100 * (bd.CountPieces(me) - bd.CountPieces(Opponent(me))) // me 视角
+		}
+		return out, nil
+	}
+	const workers, sims = 8, 400
+	q := newNNLeafQueue(workers, 50*time.Millisecond, fake)
+	root := runParallelMCTS(NewGameState(boardRadius).Board, PlayerA, sims, 0, true, workers, rolloutCfg, q)
+	q.close()
+
+	checkPTree(t, root)
+	if leaves == 0 || leaves > sims {
+		t.Fatalf("估值叶子 %d", leaves)
+	}
+	if calls*2 > leaves {
+		t.Fatalf("%d 个叶子用了 %d 次推理，没有攒批", leaves, calls)
+	}
+	if _, ok := mostVisitedPChild(root); !ok {
+		t.Fatal("开局应有着可走")
+	}
+}
+
+func TestNNLeafQueueGroupsBySide(t *testing.T) {
+	st := NewGameState(boardRadius)
+	var mu sync.Mutex
+	var batches [][2]int // {视角, 个数}
+	q := newNNLeafQueue(4, time.Second, func(boards []*Board, me CellState) ([]int, error) {
+		mu.Lock()
+		batches = append(batches, [2]int{int(me), len(boards)})
+		mu.Unlock()
+		out := make([]int, len(boards))
+		for i := range out {
+			out[i] = 500
+			if me == PlayerB {
+				out[i] = -250
+			}
+		}
+		return out, nil
+	})
+	var wg sync.WaitGroup
+	got := make([]float64, 4)
+	for i := range got {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			me := PlayerA
+			if i%2 == 1 {
+				me = PlayerB
+			}
+			got[i] = q.eval(st.Board, me)
+		}(i)
+	}
+	wg.Wait()
+	q.close()
+	if len(batches) != 2 || batches[0][1] != 2 || batches[1][1] != 2 {
+		t.Fatalf("4 个请求应凑成一批、按视角分两次推理: %v", batches)
+	}
+	for i, v := range got {
+		want := 0.5
+		if i%2 == 1 {
+			want = -0.25
+		}
+		if v != want {
+			t.Fatalf("请求 %d 得到 %v，期望 %v", i, v, want)
+		}
+	}
+}
+
+// go test -bench ParallelMCTS -benchtime 3x -run ^$ ./internal/game
+func BenchmarkParallelMCTS10k(b *testing.B) {
+	bd := NewGameState(boardRadius).Board
+	for i := 0; i < b.N; i++ {
+		runParallelMCTS(bd, PlayerA, 10000, 0, true, runtime.GOMAXPROCS(0), rolloutCfg, nil)
+	}
+	b.ReportMetric(float64(10000*b.N)/b.Elapsed().Seconds(), "sims/s")
+}
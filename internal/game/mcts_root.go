@@ -0,0 +1,193 @@
+// game/mcts_root.go
+package game
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+const mctsCPUCT = 1.4
+
+// MCTSOptions 自博弈在根节点用的探索手段（零值 = 与对弈时的搜索完全一致）
+type MCTSOptions struct {
+	// Dirichlet 噪声：根先验改为 (1-NoiseFrac)·P + NoiseFrac·Dir(DirichletAlpha)
+	DirichletAlpha float64
+	NoiseFrac      float64
+
+	// 强制 playout（KataGo）：根下每个子至少访问 sqrt(ForcedK·P·N) 次；0 = 关闭
+	ForcedK float64
+	// 输出策略目标前扣掉强制 playout 带来的“不值得”的访问，避免把噪声学进策略
+	PruneTarget bool
+
+	Rand  *rand.Rand       // 噪声用的随机源；nil 用全局 rand
+	Model PolicyValueModel // 先验与叶子估值用的网络（PVModel、RemoteEvaluator）；nil 用全局模型（PolicyValueNN / EvaluateNN3）
+}
+
+// RootVisit 根下一步走法的统计
+type RootVisit struct {
+	Move   Move
+	Visits int     // 实际访问次数
+	Target int     // 用作策略目标的访问次数（PruneTarget 时已扣除强制 playout，否则 = Visits）
+	Prior  float64 // 混入噪声后的先验
+}
+
+func (o MCTSOptions) randFloat() float64 {
+	if o.Rand != nil {
+		return o.Rand.Float64()
+	}
+	return rand.Float64()
+}
+
+func (o MCTSOptions) randNorm() float64 {
+	if o.Rand != nil {
+		return o.Rand.NormFloat64()
+	}
+	return rand.NormFloat64()
+}
+
+func (o MCTSOptions) policyValue(b *Board, me CellState) ([]float32, float32, error) {
+	if o.Model != nil {
+		return o.Model.PolicyValue(b, me)
+	}
+	return PolicyValueNN(b, me)
+}
+
+// winProb me 的胜率 [0,1]；推理失败时与 EvaluateNN3 一样返回 0
+func (o MCTSOptions) winProb(b *Board, me CellState) float64 {
+	if o.Model == nil {
+		return float64(EvaluateNN3(b, me)) / 100.0
+	}
+	_, v, err := o.Model.PolicyValue(b, me)
+	if err != nil {
+		return 0
+	}
+	return float64(v)
+}
+
+// rootNoise 为根的每步走法抽一份 Dirichlet 噪声；未启用时返回 nil
+func (o MCTSOptions) rootNoise(moves []Move) map[Move]float64 {
+	if o.DirichletAlpha <= 0 || o.NoiseFrac <= 0 || len(moves) == 0 {
+		return nil
+	}
+	eta := o.sampleDirichlet(len(moves))
+	noise := make(map[Move]float64, len(moves))
+	for i, mv := range moves {
+		noise[mv] = eta[i]
+	}
+	return noise
+}
+
+// sampleDirichlet 对称 Dirichlet：n 个 Gamma(α,1) 归一化
+func (o MCTSOptions) sampleDirichlet(n int) []float64 {
+	out := make([]float64, n)
+	sum := 0.0
+	for i := range out {
+		out[i] = o.sampleGamma(o.DirichletAlpha)
+		sum += out[i]
+	}
+	if sum <= 0 {
+		for i := range out {
+			out[i] = 1 / float64(n)
+		}
+		return out
+	}
+	for i := range out {
+		out[i] /= sum
+	}
+	return out
+}
+
+// sampleGamma Marsaglia-Tsang；α<1 时用 Gamma(α+1)·U^(1/α)
+func (o MCTSOptions) sampleGamma(alpha float64) float64 {
+	if alpha < 1 {
+		u := o.randFloat()
+		return o.sampleGamma(alpha+1) * math.Pow(u, 1/alpha)
+	}
+	d := alpha - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := o.randNorm()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := o.randFloat()
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
+
+func (o MCTSOptions) forcedPlayouts(ch *mctsNode, parentVisits int) int {
+	return int(math.Sqrt(o.ForcedK * ch.prior * float64(parentVisits)))
+}
+
+// forcedChild 找一个访问数还没达到强制下限的根子节点；没有则返回 nil，走正常 PUCT
+func (o MCTSOptions) forcedChild(root *mctsNode) (Move, *mctsNode) {
+	if o.ForcedK <= 0 {
+		return Move{}, nil
+	}
+	var best Move
+	var bestChild *mctsNode
+	for _, ch := range root.children {
+		if ch.visits >= o.forcedPlayouts(ch, root.visits) {
+			continue
+		}
+		// 多个不足时挑先验最高的，同先验取先展开的
+		if bestChild == nil || ch.prior > bestChild.prior {
+			best, bestChild = ch.move, ch
+		}
+	}
+	return best, bestChild
+}
+
+func puctScore(ch *mctsNode, visits int, parentVisits int) float64 {
+	return ch.q() + mctsCPUCT*ch.prior*math.Sqrt(math.Max(1, float64(parentVisits)))/(1.0+float64(visits))
+}
+
+// rootVisits 汇总根节点统计；PruneTarget 时对非最佳子逐次扣除强制 playout，
+// 直到其 PUCT 分数追上最佳子为止，剩下 ≤1 次的直接清零（KataGo 的 policy target pruning）
+func (o MCTSOptions) rootVisits(root *mctsNode) []RootVisit {
+	// 输出按走法坐标排序，与展开顺序无关
+	kids := append([]*mctsNode(nil), root.children...)
+	sort.Slice(kids, func(i, j int) bool {
+		a, b := kids[i].move, kids[j].move
+		if a.From != b.From {
+			return a.From.Q < b.From.Q || (a.From.Q == b.From.Q && a.From.R < b.From.R)
+		}
+		return a.To.Q < b.To.Q || (a.To.Q == b.To.Q && a.To.R < b.To.R)
+	})
+	out := make([]RootVisit, 0, len(kids))
+	var bestChild *mctsNode
+	for _, ch := range kids {
+		out = append(out, RootVisit{Move: ch.move, Visits: ch.visits, Target: ch.visits, Prior: ch.prior})
+		if bestChild == nil || ch.visits > bestChild.visits {
+			bestChild = ch
+		}
+	}
+	if !o.PruneTarget || o.ForcedK <= 0 {
+		return out
+	}
+
+	bestScore := puctScore(bestChild, bestChild.visits, root.visits)
+	for i := range out {
+		ch := kids[i]
+		if ch == bestChild {
+			continue
+		}
+		v := ch.visits
+		for forced := o.forcedPlayouts(ch, root.visits); forced > 0 && v > 0; forced-- {
+			if puctScore(ch, v-1, root.visits) >= bestScore {
+				break
+			}
+			v--
+		}
+		if v <= 1 {
+			v = 0
+		}
+		out[i].Target = v
+	}
+	return out
+}
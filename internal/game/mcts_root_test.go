@@ -0,0 +1,86 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSampleDirichlet(t *testing.T) {
+	for _, alpha := range []float64{0.03, 0.3, 2} {
+		o := MCTSOptions{DirichletAlpha: alpha, Rand: rand.New(rand.NewSource(1))}
+		mean := make([]float64, 8)
+		const rounds = 4000
+		for i := 0; i < rounds; i++ {
+			eta := o.sampleDirichlet(len(mean))
+			sum := 0.0
+			for j, v := range eta {
+				if v < 0 || math.IsNaN(v) {
+					t.Fatalf("α=%v: 非法分量 %v", alpha, v)
+				}
+				sum += v
+				mean[j] += v / rounds
+			}
+			if math.Abs(sum-1) > 1e-9 {
+				t.Fatalf("α=%v: 分量和 %v", alpha, sum)
+			}
+		}
+		// 对称 Dirichlet 每个分量期望都是 1/n
+		for j, m := range mean {
+			if math.Abs(m-1.0/8) > 0.02 {
+				t.Errorf("α=%v: 分量 %d 均值 %.3f，期望 0.125", alpha, j, m)
+			}
+		}
+	}
+}
+
+func TestRootVisitsPruneTarget(t *testing.T) {
+	best := &mctsNode{move: Move{From: HexCoord{0, 0}, To: HexCoord{1, 0}}, prior: 0.5, visits: 80, valueSum: 40}
+	weak := &mctsNode{move: Move{From: HexCoord{0, 0}, To: HexCoord{0, 1}}, prior: 0.25, visits: 12, valueSum: -6}
+	single := &mctsNode{move: Move{From: HexCoord{0, 0}, To: HexCoord{-1, 1}}, prior: 0.25, visits: 1, valueSum: 0}
+	root := &mctsNode{visits: 100, children: []*mctsNode{best, weak, single}}
+
+	rv := MCTSOptions{ForcedK: 2, PruneTarget: true}.rootVisits(root)
+	for _, v := range rv {
+		switch root.child(v.Move) {
+		case best:
+			if v.Target != v.Visits {
+				t.Errorf("最佳子不应被剪: %+v", v)
+			}
+		case weak:
+			if v.Target >= v.Visits || v.Target < v.Visits-7 {
+				t.Errorf("弱子应扣除至多 sqrt(2·0.25·100)=7 次强制访问: %+v", v)
+			}
+		case single:
+			if v.Target != 0 {
+				t.Errorf("只剩 1 次访问的子应清零: %+v", v)
+			}
+		}
+	}
+
+	for _, v := range (MCTSOptions{ForcedK: 2}).rootVisits(root) {
+		if v.Target != v.Visits {
+			t.Errorf("未开 PruneTarget 时 Target 应等于 Visits: %+v", v)
+		}
+	}
+}
+
+// 子节点按展开顺序存放：同分时总选先展开的，结果不随 map 遍历顺序变化
+func TestSelectChildDeterministic(t *testing.T) {
+	n := &mctsNode{visits: 3}
+	for _, to := range []HexCoord{{Q: 1, R: 0}, {Q: 0, R: 1}, {Q: -1, R: 1}} {
+		n.children = append(n.children, &mctsNode{move: Move{To: to}, prior: 1.0 / 3, visits: 1})
+	}
+	for i := 0; i < 10; i++ {
+		if mv, ch := selectChild(n, mctsCPUCT); ch != n.children[0] || mv != n.children[0].move {
+			t.Fatalf("同分应选第一个子节点，得到 %v", mv)
+		}
+	}
+	n.children[0].visits = 2
+	if _, ch := selectChild(n, mctsCPUCT); ch != n.children[1] {
+		t.Fatalf("第一个子访问更多后应选第二个")
+	}
+	if n.child(Move{To: HexCoord{Q: -1, R: 1}}) != n.children[2] || n.child(Move{}) != nil {
+		t.Fatalf("child 按走法查找不对")
+	}
+}
@@ -0,0 +1,195 @@
+// internal/game/model_check.go
+package game
+
+import (
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strings"
+	"sync"
+
+	ort "github.com/yalue/onnxruntime_go"
+)
+
+// 加载模型时先对照编码版本检查输入输出的名字与形状。形状对不上时 ORT 未必报错
+//（动态维度、或平面数恰好能广播），那样得到的是一堆看似正常的错误评估，这里提前拒绝。
+
+// tensorSpec 期望的张量：Dims 中 0 表示不限（批维度等）
+type tensorSpec struct {
+	Name string
+	Dims []int64
+}
+
+// modelSpec 某类模型在给定编码版本下的 I/O 约定。Inputs[0] 必须是空间输入 (N,Planes,9,9)，
+// 若编码带全局特征则 Inputs[1] 为 (N,Globals)。
+type modelSpec struct {
+	Features FeatureSet
+	Inputs   []tensorSpec
+	Outputs  []tensorSpec
+}
+
+var (
+	cnnModelSpec = modelSpec{
+		Features: FeaturesCNN3,
+		Inputs:   []tensorSpec{{onnxInputName, []int64{0, featPlanes, GridSize, GridSize}}},
+		Outputs: []tensorSpec{
+			{onnxPolicyName, []int64{0, policyOutDim}},
+			{onnxValueName, []int64{0, 1}},
+		},
+	}
+	kataModelSpec = modelSpec{
+		Features: FeaturesKataV7,
+		Inputs: []tensorSpec{
+			{katagoInputSpatial, []int64{0, katagoPlanes, GridSize, GridSize}},
+			{katagoInputGlobal, []int64{0, katagoGlobals}},
+		},
+		Outputs: []tensorSpec{
+			{katagoOutputPolicy, []int64{0, 0, gridArea + 1}},
+			{katagoOutputValue, []int64{0, 3}},
+		},
+	}
+)
+
+// checkModelIO 对照 spec 检查模型 I/O；返回第一处不兼容
+func checkModelIO(spec modelSpec, ins, outs []ort.InputOutputInfo) error {
+	enc, err := Encoder(spec.Features)
+	if err != nil {
+		return err
+	}
+	find := func(infos []ort.InputOutputInfo, name string) *ort.InputOutputInfo {
+		for i := range infos {
+			if infos[i].Name == name {
+				return &infos[i]
+			}
+		}
+		return nil
+	}
+	names := func(infos []ort.InputOutputInfo) string {
+		s := make([]string, len(infos))
+		for i, info := range infos {
+			s[i] = info.Name
+		}
+		return strings.Join(s, ",")
+	}
+
+	for i, want := range spec.Inputs {
+		got := find(ins, want.Name)
+		if got == nil {
+			return fmt.Errorf("model has no input %q (inputs: %s)", want.Name, names(ins))
+		}
+		d := got.Dimensions
+		// 平面数 / 全局特征数单独给出明确提示，这是换错模型时最常见的情形
+		switch {
+		case i == 0 && len(d) == 4 && d[1] > 0 && d[1] != int64(enc.Planes):
+			return fmt.Errorf("model expects %d planes, encoder %s provides %d", d[1], enc.Name, enc.Planes)
+		case i == 1 && len(d) == 2 && d[1] > 0 && d[1] != int64(enc.Globals):
+			return fmt.Errorf("model expects %d global features, encoder %s provides %d", d[1], enc.Name, enc.Globals)
+		}
+		if !shapeMatches(d, want.Dims) {
+			return fmt.Errorf("input %q has shape %v, want %v", want.Name, d, dimsString(want.Dims))
+		}
+	}
+	for _, want := range spec.Outputs {
+		got := find(outs, want.Name)
+		if got == nil {
+			return fmt.Errorf("model has no output %q (outputs: %s)", want.Name, names(outs))
+		}
+		if !shapeMatches(got.Dimensions, want.Dims) {
+			return fmt.Errorf("output %q has shape %v, want %v", want.Name, got.Dimensions, dimsString(want.Dims))
+		}
+	}
+	return nil
+}
+
+// shapeMatches 模型里的动态维度（<=0）与期望中的 0 都视为通配
+func shapeMatches(got ort.Shape, want []int64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i, w := range want {
+		if w != 0 && got[i] > 0 && got[i] != w {
+			return false
+		}
+	}
+	return true
+}
+
+func dimsString(dims []int64) string {
+	s := make([]string, len(dims))
+	for i, d := range dims {
+		if d == 0 {
+			s[i] = "N"
+		} else {
+			s[i] = fmt.Sprint(d)
+		}
+	}
+	return "[" + strings.Join(s, " ") + "]"
+}
+
+// ModelInfo 已加载（或尝试加载）模型的概况
+type ModelInfo struct {
+	Kind       string   `json:"kind"`   // cnn / katago / snapshot
+	Source     string   `json:"source"` // 文件路径或 embed 内的名字
+	Checksum   uint32   `json:"crc32"`
+	Features   string   `json:"features"` // 编码版本名
+	Inputs     []string `json:"inputs"`
+	Outputs    []string `json:"outputs"`
+	Compatible bool     `json:"compatible"`
+	Error      string   `json:"error,omitempty"` // 不兼容或读取 I/O 失败的原因
+}
+
+var (
+	modelInfoMu sync.Mutex
+	modelInfos  = map[string]ModelInfo{}
+)
+
+// inspectModel 读取 I/O 信息、做兼容性检查并登记到 GetModelInfo；不兼容时返回错误
+func inspectModel(kind, source string, data []byte, spec modelSpec) (ModelInfo, error) {
+	info := ModelInfo{Kind: kind, Source: source, Checksum: crc32.ChecksumIEEE(data)}
+	if enc, err := Encoder(spec.Features); err == nil {
+		info.Features = enc.Name
+	}
+	ins, outs, err := ort.GetInputOutputInfoWithONNXData(data)
+	if err == nil {
+		info.Inputs = describeIO(ins)
+		info.Outputs = describeIO(outs)
+		err = checkModelIO(spec, ins, outs)
+	} else {
+		err = fmt.Errorf("read model I/O: %w", err)
+	}
+	info.Compatible = err == nil
+	if err != nil {
+		err = fmt.Errorf("%s model %s: %w", kind, source, err)
+		info.Error = err.Error()
+	}
+
+	modelInfoMu.Lock()
+	modelInfos[kind+"\x00"+source] = info
+	modelInfoMu.Unlock()
+	return info, err
+}
+
+func describeIO(infos []ort.InputOutputInfo) []string {
+	s := make([]string, len(infos))
+	for i, info := range infos {
+		s[i] = fmt.Sprintf("%s%v", info.Name, info.Dimensions)
+	}
+	return s
+}
+
+// GetModelInfo 返回本进程加载过的所有模型及其兼容性检查结果（按 Kind、Source 排序）
+func GetModelInfo() []ModelInfo {
+	modelInfoMu.Lock()
+	out := make([]ModelInfo, 0, len(modelInfos))
+	for _, info := range modelInfos {
+		out = append(out, info)
+	}
+	modelInfoMu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Kind != out[j].Kind {
+			return out[i].Kind < out[j].Kind
+		}
+		return out[i].Source < out[j].Source
+	})
+	return out
+}
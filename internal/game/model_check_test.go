@@ -0,0 +1,57 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	ort "github.com/yalue/onnxruntime_go"
+)
+
+func kataIO(planes, globals int64) ([]ort.InputOutputInfo, []ort.InputOutputInfo) {
+	ins := []ort.InputOutputInfo{
+		{Name: katagoInputSpatial, Dimensions: ort.NewShape(-1, planes, 9, 9)},
+		{Name: katagoInputGlobal, Dimensions: ort.NewShape(-1, globals)},
+	}
+	outs := []ort.InputOutputInfo{
+		{Name: katagoOutputPolicy, Dimensions: ort.NewShape(-1, 4, 82)},
+		{Name: katagoOutputValue, Dimensions: ort.NewShape(-1, 3)},
+		{Name: "out_ownership", Dimensions: ort.NewShape(-1, 1, 9, 9)},
+	}
+	return ins, outs
+}
+
+func TestCheckModelIO(t *testing.T) {
+	ins, outs := kataIO(katagoPlanes, katagoGlobals)
+	if err := checkModelIO(kataModelSpec, ins, outs); err != nil {
+		t.Fatalf("匹配的 KataGo 模型不应报错: %v", err)
+	}
+
+	cases := []struct {
+		name string
+		spec modelSpec
+		ins  []ort.InputOutputInfo
+		outs []ort.InputOutputInfo
+		want string
+	}{
+		{"KataGo 模型配 CNN 编码", cnnModelSpec, ins, outs, `no input "state"`},
+		{"平面数不对", kataModelSpec, func() []ort.InputOutputInfo { i, _ := kataIO(3, katagoGlobals); return i }(), outs,
+			"model expects 3 planes, encoder katago_v7 provides 22"},
+		{"全局特征数不对", kataModelSpec, func() []ort.InputOutputInfo { i, _ := kataIO(katagoPlanes, 12); return i }(), outs,
+			"model expects 12 global features"},
+		{"缺少 value 输出", kataModelSpec, ins, outs[:1], `no output "out_value"`},
+		{"CNN 把 22 平面模型当 3 平面", cnnModelSpec,
+			[]ort.InputOutputInfo{{Name: onnxInputName, Dimensions: ort.NewShape(1, 22, 9, 9)}},
+			[]ort.InputOutputInfo{{Name: onnxPolicyName, Dimensions: ort.NewShape(1, 81)}, {Name: onnxValueName, Dimensions: ort.NewShape(1, 1)}},
+			"model expects 22 planes, encoder cnn3 provides 3"},
+		{"策略维度不对", cnnModelSpec,
+			[]ort.InputOutputInfo{{Name: onnxInputName, Dimensions: ort.NewShape(1, 3, 9, 9)}},
+			[]ort.InputOutputInfo{{Name: onnxPolicyName, Dimensions: ort.NewShape(1, 82)}, {Name: onnxValueName, Dimensions: ort.NewShape(1, 1)}},
+			`output "policy" has shape`},
+	}
+	for _, c := range cases {
+		err := checkModelIO(c.spec, c.ins, c.outs)
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: 得到 %v，期望包含 %q", c.name, err, c.want)
+		}
+	}
+}
@@ -2,7 +2,7 @@ package game
 
 import (
 	"fmt"
-	"math/bits"
+	"sync"
 )
 
 // Move 表示一次从 From 到 To 的走子
@@ -86,47 +86,72 @@ func (m Move) IsJumpOld() bool {
 	return false
 }
 func GenerateMoves(b *Board, player CellState) []Move {
-	moves := make([]Move, 0, 64) // 预分配
+	return GenerateMovesInto(b, player, make([]Move, 0, 64)) // 预分配
+}
+
+// GenerateMovesInto 与 GenerateMoves 相同，但把结果写进 buf[:0] 复用其底层数组；
+// 容量够用时不分配。返回的切片与 buf 共用内存，下次复用 buf 前要用完。
+func GenerateMovesInto(b *Board, player CellState, buf []Move) []Move {
+	moves := buf[:0]
+	ForEachMove(b, player, func(mv Move) bool {
+		moves = append(moves, mv)
+		return true
+	})
+	return moves
+}
 
+// ForEachMove 按 GenerateMoves 的顺序逐个回调 player 的合法着法，fn 返回 false 时停止。
+// 只需判断“有没有某种着法”时用它，不必生成整张列表。
+func ForEachMove(b *Board, player CellState, fn func(Move) bool) {
 	// 获取当前玩家的棋子位掩码
-	var pBit uint64
+	var pBit CellMask
 	if player == PlayerA {
 		pBit = b.bitA
 	} else if player == PlayerB {
 		pBit = b.bitB
 	} else {
-		return nil
+		return
 	}
 
-	// 使用 TrailingZeros64 快速遍历位掩码中为 1 的位（棋子下标）
-	for pBit != 0 {
-		i := bits.TrailingZeros64(pBit)
-		// 清除最低位的 1
-		pBit &= ^(uint64(1) << uint(i))
+	// 按下标从小到大取出位掩码中为 1 的位（棋子下标）
+	for !pBit.IsZero() {
+		i := pBit.pop()
 
 		fromCoord := CoordOf[i]
 
-		// 克隆（距离=1）
+		// 克隆（距离=1）；克隆上限规则下这一脉次数用完就只剩跳跃
 		for _, to := range NeighI[i] {
-			if b.Cells[to] == Empty {
-				moves = append(moves, Move{
-					From: fromCoord,
-					To:   CoordOf[to],
-				})
+			if b.Cells[to] == Empty && b.canCloneI(i) && !fn(Move{From: fromCoord, To: CoordOf[to]}) {
+				return
 			}
 		}
 
 		// 跳跃（距离=2）
 		for _, to := range JumpI[i] {
-			if b.Cells[to] == Empty {
-				moves = append(moves, Move{
-					From: fromCoord,
-					To:   CoordOf[to],
-				})
+			if b.Cells[to] == Empty && !fn(Move{From: fromCoord, To: CoordOf[to]}) {
+				return
 			}
 		}
 	}
-	return moves
+}
+
+// 搜索节点的着法缓冲池：每个节点取一块、返回前放回，递归各层各用各的，不跨 goroutine 共享
+var moveBufPool = sync.Pool{
+	New: func() any {
+		buf := make([]Move, 0, 64)
+		return &buf
+	},
+}
+
+func acquireMoves() *[]Move { return moveBufPool.Get().(*[]Move) }
+
+// releaseMoves 放回缓冲；moves 是从该缓冲生成的切片（可能因扩容换了底层数组），一并留下给下次用
+func releaseMoves(buf *[]Move, moves []Move) {
+	if cap(moves) > cap(*buf) {
+		*buf = moves
+	}
+	*buf = (*buf)[:0]
+	moveBufPool.Put(buf)
 }
 
 // 1) 把 Apply 改成返回被感染的坐标切片
@@ -154,27 +179,34 @@ func (m Move) Apply(b *Board, player CellState) ([]HexCoord, error) {
 	//   - 跳跃：toIdx 必须在 jumpI[fromIdx] 中
 	// 这里按“调用方保证合法走法”处理，省分支
 
-	opp := Opponent(player)
+	opBit := b.bitB
+	if player == PlayerB {
+		opBit = b.bitA
+	}
 
-	// —— 预先收集将被感染的邻居（以索引存一份，返回时也要 HexCoord）—— //
+	// —— 预先收集将被感染的格子（以索引存一份，返回时也要 HexCoord）—— //
 	infectedIdx := make([]int, 0, 6)
 	infected := make([]HexCoord, 0, 6)
-	for _, nb := range NeighI[toIdx] {
-		if b.Cells[nb] == opp {
-			infectedIdx = append(infectedIdx, nb)
-			infected = append(infected, CoordOf[nb])
-		}
-	}
+	forEachInfection(toIdx, opBit, func(_, dst, _ int) {
+		infectedIdx = append(infectedIdx, dst)
+		infected = append(infected, CoordOf[dst])
+	})
 
 	// —— 执行跳跃/克隆 —— //
 	if m.IsJump() {
 		b.setI(fromIdx, Empty)
 	}
 	b.setI(toIdx, player)
+	b.movePieceID(fromIdx, toIdx, m.IsJump())
 
 	// —— 执行感染 —— //
+	var infMask CellMask
 	for _, nb := range infectedIdx {
 		b.setI(nb, player)
+		infMask.set(nb)
+	}
+	if rules.CloneLimit > 0 {
+		b.moveLineage(fromIdx, toIdx, m.IsJump(), infMask)
 	}
 
 	return infected, nil
@@ -1,110 +1,107 @@
 package game
 
-// 1) 记录被改动的格子 (最多 7: 起点/终点 + 感染 6)
-type undoCell struct {
-	coord HexCoord
-	prev  CellState
-	idx   int
-}
+// 1) 记录一步走子的逆操作：起点/终点的原状态 + 被感染格的位图。
+// 被感染的一定是对方的子，位图就足够还原（连锁感染也一样），整个结构放在栈上，搜索里不分配。
+// 只有开启克隆上限规则时另存一份走子前的脉号（lin），每步多一次分配。
 type undoInfo struct {
-	changed        []undoCell
+	moved    bool // false = 坐标不在盘上，什么都没改
+	jump     bool
+	from, to int
+	prevFrom CellState
+	prevTo   CellState
+	op       CellState // 被感染格原来的颜色
+	infected CellMask
+	lin      *lineageState // 走子前的脉号；规则关闭时为 nil
+	prevID   PieceID       // 落点原来的棋子编号（合法走子时为 0）
+	eval     evalAcc       // 走子前的评估累加器，撤销时原样还原（含已算好的紧三角数）
+
 	prevLastMove   Move
 	prevLastMover  CellState
 	prevLastInfect int
 }
 
-// MakeMove 在原盘执行走子，返回 (感染数, undoInfo)
-func (m Move) MakeMove(b *Board, player CellState) (infectedCoords []HexCoord, undo undoInfo) {
+// makeMove 在原盘执行走子（维护 zobrist + bitmask），返回 undoInfo；不分配。
+// 坐标不在盘上时只记下 LastMove，不改格子。
+func (b *Board) makeMove(m Move, player CellState) undoInfo {
+	u := undoInfo{
+		prevLastMove:   b.LastMove,
+		prevLastMover:  b.LastMover,
+		prevLastInfect: b.LastInfect,
+		eval:           b.eval,
+	}
 	b.LastMove = m
 
-	// 预分配
-	infectedCoords = make([]HexCoord, 0, 6)
-	undo.changed = make([]undoCell, 0, 8)
-
-	// 坐标→下标
 	from, okF := IndexOf[m.From]
 	to, okT := IndexOf[m.To]
 	if !okT || !okF {
-		// 非法坐标（对正式走法一般不会发生）
-		return infectedCoords, undo
-	}
-
-	// 带回溯记录的 setI（维护 zobrist + bitmask）
-	setI := func(i int, s CellState) {
-		prev := b.Cells[i]
-		if prev == s {
-			return
-		}
-		// 记录
-		undo.changed = append(undo.changed, undoCell{idx: i, prev: prev})
-		// 增量更新 hash
-		b.hash ^= zobKeyI(i, prev)
-		// 增量更新 bitmask
-		mask := uint64(1) << uint(i)
-		if prev == PlayerA {
-			b.bitA &= ^mask
-		} else if prev == PlayerB {
-			b.bitB &= ^mask
-		}
-
-		b.Cells[i] = s
-		b.hash ^= zobKeyI(i, s)
-		if s == PlayerA {
-			b.bitA |= mask
-		} else if s == PlayerB {
-			b.bitB |= mask
-		}
+		return u
 	}
+	u.moved, u.from, u.to = true, from, to
 
 	// 1) 跳跃则清起点
 	if m.IsJump() {
-		setI(from, Empty)
+		u.jump, u.prevFrom = true, b.Cells[from]
+		b.setI(from, Empty)
 	}
 	// 2) 落子
-	setI(to, player)
+	u.prevTo = b.Cells[to]
+	b.setI(to, player)
+	u.prevID = b.movePieceID(from, to, u.jump)
 
-	// 3) 感染：把落点的对方相邻翻为我方
-	opp := Opponent(player)
-	for _, nb := range NeighI[to] {
-		if b.Cells[nb] == opp {
-			setI(nb, player)
-			infectedCoords = append(infectedCoords, CoordOf[nb])
-		}
+	// 3) 感染：把落点的对方相邻翻为我方（连锁规则下继续向外传，见 rules.go）
+	opBit, op := b.bitB, PlayerB
+	if player == PlayerB {
+		opBit, op = b.bitA, PlayerA
+	}
+	u.op = op
+	forEachInfection(to, opBit, func(_, dst, _ int) {
+		u.infected.set(dst)
+	})
+	for x := u.infected; !x.IsZero(); {
+		b.setI(x.pop(), player)
 	}
+	if rules.CloneLimit > 0 {
+		prev := b.lin
+		u.lin = &prev
+		b.moveLineage(from, to, u.jump, u.infected)
+	}
+	return u
+}
 
+// MakeMove 在原盘执行走子，返回 (被感染的格子, undoInfo)；格子按感染顺序排列
+func (m Move) MakeMove(b *Board, player CellState) (infectedCoords []HexCoord, undo undoInfo) {
+	undo = b.makeMove(m, player)
+	infectedCoords = make([]HexCoord, 0, undo.infected.Count())
+	if !undo.infected.IsZero() {
+		// 只在被感染的格子里重放一遍，顺序与结算一致
+		forEachInfection(undo.to, undo.infected, func(_, dst, _ int) {
+			infectedCoords = append(infectedCoords, CoordOf[dst])
+		})
+	}
 	return infectedCoords, undo
 }
 
-// UnmakeMove 按相反顺序恢复格子 & hash & bitmask
+// UnmakeMove 按相反顺序恢复格子 & hash & bitmask，评估累加器整个换回走子前的
 func (b *Board) UnmakeMove(u undoInfo) {
 	// 先恢复最近一步元信息
 	b.LastMove = u.prevLastMove
 	b.LastMover = u.prevLastMover
 	b.LastInfect = u.prevLastInfect
+	if !u.moved {
+		return
+	}
 
-	// 再倒序回滚所有格子 & hash & bitmask
-	for i := len(u.changed) - 1; i >= 0; i-- {
-		ch := u.changed[i]
-		cur := b.Cells[ch.idx]
-		if cur == ch.prev {
-			continue
-		}
-		// 恢复 hash
-		b.hash ^= zobKeyI(ch.idx, cur)
-		// 恢复 bitmask
-		mask := uint64(1) << uint(ch.idx)
-		if cur == PlayerA {
-			b.bitA &= ^mask
-		} else if cur == PlayerB {
-			b.bitB &= ^mask
-		}
-
-		b.Cells[ch.idx] = ch.prev
-		b.hash ^= zobKeyI(ch.idx, ch.prev)
-		if ch.prev == PlayerA {
-			b.bitA |= mask
-		} else if ch.prev == PlayerB {
-			b.bitB |= mask
-		}
+	// 再倒序回滚：脉号 → 感染 → 落点 → 起点
+	if u.lin != nil {
+		b.restoreLineage(u.lin)
+	}
+	for x := u.infected; !x.IsZero(); {
+		b.setI(x.pop(), u.op)
+	}
+	b.setI(u.to, u.prevTo)
+	if u.jump {
+		b.setI(u.from, u.prevFrom)
 	}
+	b.unmovePieceID(u.from, u.to, u.jump, u.prevID)
+	b.eval = u.eval
 }
@@ -0,0 +1,68 @@
+package game
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGenerateMovesInto(t *testing.T) {
+	buf := make([]Move, 0, 4) // 容量不够时应自行扩容
+	for n, b := range symTestBoards(t) {
+		for _, side := range []CellState{PlayerA, PlayerB} {
+			want := GenerateMoves(b, side)
+			buf = GenerateMovesInto(b, side, buf)
+			if !slices.Equal(buf, want) {
+				t.Fatalf("局面 %d: GenerateMovesInto 与 GenerateMoves 不一致", n)
+			}
+			var got []Move
+			ForEachMove(b, side, func(mv Move) bool {
+				got = append(got, mv)
+				return len(got) < 3
+			})
+			if len(want) >= 3 && !slices.Equal(got, want[:3]) {
+				t.Fatalf("局面 %d: ForEachMove 应按顺序回调并在返回 false 时停下，得到 %v", n, got)
+			}
+		}
+	}
+}
+
+var benchMovesSink []Move
+
+// go test -bench Moves -run ^$ ./internal/game 对比两种生成方式每次的分配
+func BenchmarkGenerateMoves(b *testing.B) {
+	bd := NewGameState(boardRadius).Board
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchMovesSink = GenerateMoves(bd, PlayerA)
+	}
+}
+
+func BenchmarkGenerateMovesInto(b *testing.B) {
+	bd := NewGameState(boardRadius).Board
+	buf := make([]Move, 0, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = GenerateMovesInto(bd, PlayerA, buf)
+	}
+	benchMovesSink = buf
+}
+
+func BenchmarkAlphaBetaMoves(b *testing.B) {
+	bd := NewGameState(boardRadius).Board
+	oldA, oldB := UseONNXForPlayerA, UseONNXForPlayerB
+	UseONNXForPlayerA, UseONNXForPlayerB = false, false
+	defer func() { UseONNXForPlayerA, UseONNXForPlayerB = oldA, oldB }()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ClearTT()
+		AlphaBeta(bd, PlayerA, 3)
+	}
+}
+
+func BenchmarkRolloutMoves(b *testing.B) {
+	bd := NewGameState(boardRadius).Board
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rollout(bd, PlayerA, PlayerA, true, mcRolloutCfg)
+	}
+}
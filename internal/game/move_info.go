@@ -0,0 +1,82 @@
+// internal/game/move_info.go
+package game
+
+// MoveInfo 一步着法的静态信息，生成着法后算一次，排序、过滤和界面提示共用，免得各处重复推导感染格。
+// 全部由位掩码推出，不改盘。
+type MoveInfo struct {
+	Move
+	Captures      int      // 落子后感染的对方子数（连锁规则下含连锁翻的）
+	Jump          bool     // 是否跳跃（起点会空出）
+	Ring          int      // 落点所在圈：0 = 中心，boardRadius = 最外圈
+	MobilityDelta int      // 走后（我方可落点数 - 对方可落点数）相对走前的变化
+	infected      CellMask // 被感染格的位掩码
+}
+
+// Infected 被感染的格子坐标（按下标顺序）
+func (mi MoveInfo) Infected() []HexCoord {
+	out := make([]HexCoord, 0, mi.Captures)
+	for m := mi.infected; !m.IsZero(); {
+		out = append(out, CoordOf[m.pop()])
+	}
+	return out
+}
+
+// PreviewInfectedCount mv 落下后会感染的对方子数（不改盘）
+func PreviewInfectedCount(b *Board, mv Move, player CellState) int {
+	return previewInfectedCount(b, mv, player)
+}
+
+// DescribeMove 计算 player 走 mv 的 MoveInfo；mv 的落点不在盘上时只填 Move 与 Jump
+func DescribeMove(b *Board, player CellState, mv Move) MoveInfo {
+	mi := MoveInfo{Move: mv, Jump: mv.IsJump()}
+	to, okT := IndexOf[mv.To]
+	from, okF := IndexOf[mv.From]
+	if !okT || !okF {
+		return mi
+	}
+	myBit, opBit := b.bitA, b.bitB
+	if player == PlayerB {
+		myBit, opBit = b.bitB, b.bitA
+	}
+	empty := b.EmptyMask()
+
+	mi.infected = infectMask(to, opBit)
+	mi.Captures = mi.infected.Count()
+	mi.Ring = max(max(abs(mv.To.Q), abs(mv.To.R)), abs(-mv.To.Q-mv.To.R))
+
+	toBit := cellBit(to)
+	myAfter := myBit.Or(toBit).Or(mi.infected)
+	opAfter := opBit.AndNot(mi.infected)
+	emptyAfter := empty.AndNot(toBit)
+	if mi.Jump {
+		fromBit := cellBit(from)
+		myAfter = myAfter.AndNot(fromBit)
+		emptyAfter = emptyAfter.Or(fromBit)
+	}
+	before := b.Mobility(player) - b.Mobility(Opponent(player))
+	after := reachMask(myAfter).And(emptyAfter).Count() - reachMask(opAfter).And(emptyAfter).Count()
+	mi.MobilityDelta = after - before
+	return mi
+}
+
+// DescribeMoves 对 moves 逐个 DescribeMove，顺序与 moves 一致
+func DescribeMoves(b *Board, player CellState, moves []Move) []MoveInfo {
+	out := make([]MoveInfo, len(moves))
+	for i, mv := range moves {
+		out[i] = DescribeMove(b, player, mv)
+	}
+	return out
+}
+
+// reachMask pieces 中任一子克隆或跳跃能落到的格子（未与空位求交）
+func reachMask(pieces CellMask) CellMask {
+	var m CellMask
+	for !pieces.IsZero() {
+		i := pieces.pop()
+		m = m.Or(NeighMask[i])
+		for _, j := range JumpI[i] {
+			m.set(j)
+		}
+	}
+	return m
+}
@@ -0,0 +1,49 @@
+package game
+
+import "testing"
+
+func TestDescribeMove(t *testing.T) {
+	b := seeBoard(t, []HexCoord{{Q: 0, R: 0}}, []HexCoord{{Q: 2, R: 0}, {Q: 2, R: -1}})
+	mi := DescribeMove(b, PlayerA, Move{From: HexCoord{Q: 0, R: 0}, To: HexCoord{Q: 1, R: 0}})
+	if mi.Captures != 2 || mi.Jump || mi.Ring != 1 {
+		t.Fatalf("得到 %+v", mi)
+	}
+	got := map[HexCoord]bool{}
+	for _, c := range mi.Infected() {
+		got[c] = true
+	}
+	if len(got) != 2 || !got[HexCoord{Q: 2, R: 0}] || !got[HexCoord{Q: 2, R: -1}] {
+		t.Fatalf("被感染格不对: %v", mi.Infected())
+	}
+}
+
+// 可落点数之差的变化应与真正走一步后重新生成着法的结果一致
+func TestDescribeMoveMobilityDelta(t *testing.T) {
+	mobility := func(b *Board, me CellState) int {
+		count := func(side CellState) int {
+			dst := map[HexCoord]bool{}
+			for _, mv := range GenerateMoves(b, side) {
+				dst[mv.To] = true
+			}
+			return len(dst)
+		}
+		return count(me) - count(Opponent(me))
+	}
+	for n, b := range symTestBoards(t) {
+		for _, side := range []CellState{PlayerA, PlayerB} {
+			before := mobility(b, side)
+			for _, mv := range GenerateMoves(b, side) {
+				mi := DescribeMove(b, side, mv)
+				u := mMakeMoveWithUndo(b, mv, side)
+				want := mobility(b, side) - before
+				if mi.Captures != b.LastInfect {
+					t.Fatalf("局面 %d %v: Captures %d，实际感染 %d", n, mv, mi.Captures, b.LastInfect)
+				}
+				b.UnmakeMove(u)
+				if mi.MobilityDelta != want {
+					t.Fatalf("局面 %d %v: MobilityDelta %d，期望 %d", n, mv, mi.MobilityDelta, want)
+				}
+			}
+		}
+	}
+}
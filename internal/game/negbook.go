@@ -0,0 +1,182 @@
+// game/negbook.go
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync/atomic"
+)
+
+// 负面开局库：自对弈/锦标赛日志里"走了之后输得多"的 (局面, 着法)。
+// 仓库里还没有正面开局库，这里单独成立：根搜索把库里的已知输棋线剔掉（全都是时保留原样），
+// 哪怕搜索略微偏好它们。库由 cmd/negbook 从 matchlog 日志统计生成，
+// 引擎用 -negbook 加载；分析时把 Search.AvoidLosing 关掉（参数文件或 engine -negbook-avoid=false）即可看到原始搜索结果。
+
+// negKey 走子前局面（Board.Hash，不含执子方）+ 走子方 + 着法
+type negKey struct {
+	hash uint64
+	side CellState
+	mv   Move
+}
+
+// NegStats 一条线的对局统计（从走子方看）
+type NegStats struct {
+	Games  int
+	Losses int // 和棋不算输
+}
+
+// NegBook 负面开局库
+type NegBook struct {
+	Threshold float64 // 败率不低于它算已知输棋线
+	MinGames  int     // 对局数不少于它才下结论
+	lines     map[negKey]NegStats
+}
+
+// NewNegBook 空库
+func NewNegBook(threshold float64, minGames int) *NegBook {
+	return &NegBook{Threshold: threshold, MinGames: minGames, lines: make(map[negKey]NegStats)}
+}
+
+// Add 记一局：hash 为走子前局面的 Board.Hash，lost 表示 side 最后输了
+func (nb *NegBook) Add(hash uint64, side CellState, mv Move, lost bool) {
+	k := negKey{hash, side, mv}
+	st := nb.lines[k]
+	st.Games++
+	if lost {
+		st.Losses++
+	}
+	nb.lines[k] = st
+}
+
+// Stats 一条线的统计
+func (nb *NegBook) Stats(b *Board, side CellState, mv Move) NegStats {
+	return nb.lines[negKey{b.Hash(), side, mv}]
+}
+
+// Losing side 在 b 上走 mv 是否是已知输棋线
+func (nb *NegBook) Losing(b *Board, side CellState, mv Move) bool {
+	return nb.losing(nb.Stats(b, side, mv))
+}
+
+func (nb *NegBook) losing(st NegStats) bool {
+	return st.Games > 0 && st.Games >= nb.MinGames && float64(st.Losses) >= nb.Threshold*float64(st.Games)
+}
+
+// Len 库里的线数（含未达阈值的）
+func (nb *NegBook) Len() int { return len(nb.lines) }
+
+// LosingCount 达到阈值的线数
+func (nb *NegBook) LosingCount() int {
+	n := 0
+	for _, st := range nb.lines {
+		if nb.losing(st) {
+			n++
+		}
+	}
+	return n
+}
+
+// negBookFile 库文件格式
+type negBookFile struct {
+	Threshold float64   `json:"threshold"`
+	MinGames  int       `json:"min_games"`
+	Lines     []negLine `json:"lines"`
+}
+
+type negLine struct {
+	Hash   string    `json:"hash"` // 16 位十六进制，同 matchlog
+	Side   CellState `json:"side"`
+	From   HexCoord  `json:"from"`
+	To     HexCoord  `json:"to"`
+	Games  int       `json:"games"`
+	Losses int       `json:"losses"`
+}
+
+// Save 写出库；对局数不足 MinGames 的线不写
+func (nb *NegBook) Save(path string) error {
+	f := negBookFile{Threshold: nb.Threshold, MinGames: nb.MinGames}
+	for k, st := range nb.lines {
+		if st.Games < nb.MinGames {
+			continue
+		}
+		f.Lines = append(f.Lines, negLine{
+			Hash: fmt.Sprintf("%016x", k.hash), Side: k.side, From: k.mv.From, To: k.mv.To,
+			Games: st.Games, Losses: st.Losses,
+		})
+	}
+	// 固定顺序，重新生成时 diff 干净
+	sort.Slice(f.Lines, func(i, j int) bool {
+		a, b := f.Lines[i], f.Lines[j]
+		if a.Hash != b.Hash {
+			return a.Hash < b.Hash
+		}
+		if a.Side != b.Side {
+			return a.Side < b.Side
+		}
+		if a.From != b.From {
+			return a.From.Q < b.From.Q || a.From.Q == b.From.Q && a.From.R < b.From.R
+		}
+		return a.To.Q < b.To.Q || a.To.Q == b.To.Q && a.To.R < b.To.R
+	})
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// LoadNegBook 读库
+func LoadNegBook(path string) (*NegBook, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f negBookFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if f.Threshold <= 0 || f.Threshold > 1 || f.MinGames < 1 {
+		return nil, fmt.Errorf("%s: threshold 须在 (0,1]、min_games 须 >= 1: %g %d", path, f.Threshold, f.MinGames)
+	}
+	nb := NewNegBook(f.Threshold, f.MinGames)
+	for _, l := range f.Lines {
+		h, err := strconv.ParseUint(l.Hash, 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: hash %q: %w", path, l.Hash, err)
+		}
+		if l.Side != PlayerA && l.Side != PlayerB {
+			return nil, fmt.Errorf("%s: 未知执子方 %d", path, l.Side)
+		}
+		nb.lines[negKey{h, l.Side, Move{From: l.From, To: l.To}}] = NegStats{Games: l.Games, Losses: l.Losses}
+	}
+	return nb, nil
+}
+
+var (
+	negBook     atomic.Pointer[NegBook]
+	avoidLosing = true // Search.AvoidLosing
+)
+
+// SetNegBook 设置根搜索使用的负面开局库；nil = 不用
+func SetNegBook(nb *NegBook) { negBook.Store(nb) }
+
+// filterNegBook 根层剔除已知输棋线；全都是输棋线时原样返回
+func filterNegBook(b *Board, side CellState, moves []Move) []Move {
+	nb := negBook.Load()
+	if nb == nil || !avoidLosing {
+		return moves
+	}
+	kept := make([]Move, 0, len(moves))
+	for _, mv := range moves {
+		if !nb.Losing(b, side, mv) {
+			kept = append(kept, mv)
+		}
+	}
+	if len(kept) == 0 {
+		return moves
+	}
+	return kept
+}
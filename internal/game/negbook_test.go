@@ -0,0 +1,67 @@
+package game
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestNegBookThresholdAndRoundTrip(t *testing.T) {
+	b := NewGameState(boardRadius).Board
+	moves := GenerateMoves(b, PlayerA)
+	bad, ok := moves[0], moves[1]
+	nb := NewNegBook(0.7, 4)
+	for i := 0; i < 4; i++ {
+		nb.Add(b.Hash(), PlayerA, bad, i < 3) // 3/4 输
+		nb.Add(b.Hash(), PlayerA, ok, i < 2)  // 2/4 输
+	}
+	nb.Add(b.Hash(), PlayerA, moves[2], true) // 样本不够
+	if !nb.Losing(b, PlayerA, bad) || nb.Losing(b, PlayerA, ok) || nb.Losing(b, PlayerA, moves[2]) {
+		t.Fatal("败率/样本阈值判断不对")
+	}
+	if nb.Losing(b, PlayerB, bad) {
+		t.Fatal("执子方不同不应命中")
+	}
+
+	path := filepath.Join(t.TempDir(), "negbook.json")
+	if err := nb.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadNegBook(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Len() != 2 || got.LosingCount() != 1 || got.Stats(b, PlayerA, bad) != (NegStats{Games: 4, Losses: 3}) {
+		t.Fatalf("读回不对: len=%d losing=%d", got.Len(), got.LosingCount())
+	}
+}
+
+func TestRootAvoidsLosingLines(t *testing.T) {
+	withOptions(t)
+	t.Cleanup(func() { SetNegBook(nil) })
+	b := NewGameState(boardRadius).Board
+	moves := applyMoveFilters(b, PlayerA, GenerateMoves(b, PlayerA), true)
+	keep := moves[len(moves)-1]
+	nb := NewNegBook(0.5, 1)
+	for _, mv := range moves[:len(moves)-1] {
+		nb.Add(b.Hash(), PlayerA, mv, true)
+	}
+	SetNegBook(nb)
+
+	if mv, ok := FindBestMoveAtDepth(b, PlayerA, 1, true); !ok || mv != keep {
+		t.Fatalf("应避开输棋线走 %v，得到 %v", keep, mv)
+	}
+	// 全是输棋线时不拦
+	nb.Add(b.Hash(), PlayerA, keep, true)
+	if got := filterNegBook(b, PlayerA, moves); len(got) != len(moves) {
+		t.Fatalf("全是输棋线时应原样返回: %d/%d", len(got), len(moves))
+	}
+	// 分析时关掉
+	o := CurrentOptions()
+	o.Search.AvoidLosing = false
+	if err := ApplyOptions(o); err != nil {
+		t.Fatal(err)
+	}
+	if got := filterNegBook(b, PlayerA, moves[:2]); len(got) != 2 {
+		t.Fatal("AvoidLosing=false 时不应过滤")
+	}
+}
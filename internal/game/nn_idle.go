@@ -0,0 +1,93 @@
+// game/nn_idle.go
+package game
+
+import (
+	"fmt"
+	"log"
+	"sync/atomic"
+
+	ort "github.com/yalue/onnxruntime_go"
+)
+
+// 界面闲置（窗口失焦等）时释放 KataGo 批量会话和它绑定的 64 路张量，只留单步会话；
+// 回到前台时按初始化时选中的执行提供者重建。进入闲置时 UI 会用 AbortSearches 叫停还在跑的搜索；
+// 闲置期间万一还有批量推理，也会先就地重建，所以释放只影响显存/内存占用，不影响结果。
+
+var katagoBatchIdle atomic.Bool
+
+// SetNNBatchIdle 标记界面是否闲置，并据此释放或重建批量会话。
+// 会等正在进行的推理结束，重建可能要几百毫秒（TensorRT 有缓存时），UI 应放到协程里调用。
+// 模型还没初始化或初始化失败时什么都不做，不会因此触发加载
+func SetNNBatchIdle(idle bool) {
+	katagoBatchIdle.Store(idle)
+	if !katagoSettled.Load() {
+		return
+	}
+	katagoMu.Lock()
+	defer katagoMu.Unlock()
+	if katagoErr != nil {
+		return
+	}
+	// 以最新的标记为准：连续切换时先后到达的协程不会把状态弄反
+	if katagoBatchIdle.Load() {
+		releaseKataBatch()
+		return
+	}
+	if err := ensureKataBatch(); err != nil {
+		log.Printf("[katago] rebuild batch session: %v%s", err, ansiReset)
+	}
+}
+
+// newKataBatchTensors 分配批量推理的输入输出张量（maxBatchSize 路）
+func newKataBatchTensors() {
+	katagoInSpatialB, _ = ort.NewTensor(ort.NewShape(maxBatchSize, katagoPlanes, katagoGrid, katagoGrid), make([]float32, maxBatchSize*katagoPlanes*katagoGrid*katagoGrid))
+	katagoInGlobalB, _ = ort.NewTensor(ort.NewShape(maxBatchSize, katagoGlobals), make([]float32, maxBatchSize*katagoGlobals))
+	katagoOutPolicyB, _ = ort.NewEmptyTensor[float32](ort.NewShape(maxBatchSize, int64(katagoPolicyHeads), katagoGrid*katagoGrid+1))
+	katagoOutValueB, _ = ort.NewEmptyTensor[float32](ort.NewShape(maxBatchSize, 3))
+}
+
+// destroyKataBatchTensors 释放批量张量；调用方持 katagoMu
+func destroyKataBatchTensors() {
+	for _, t := range []*ort.Tensor[float32]{katagoInSpatialB, katagoInGlobalB, katagoOutPolicyB, katagoOutValueB} {
+		if t != nil {
+			t.Destroy()
+		}
+	}
+	katagoInSpatialB, katagoInGlobalB, katagoOutPolicyB, katagoOutValueB = nil, nil, nil, nil
+}
+
+// releaseKataBatch 销毁批量会话及其张量；调用方持 katagoMu
+func releaseKataBatch() {
+	if katagoSessBatch == nil {
+		return
+	}
+	katagoSessBatch.Destroy()
+	katagoSessBatch = nil
+	destroyKataBatchTensors()
+	log.Printf("[katago] batch session released (idle)%s", ansiReset)
+}
+
+// ensureKataBatch 批量会话被闲置释放过时重建；调用方持 katagoMu，且初始化已成功
+func ensureKataBatch() error {
+	if katagoSessBatch != nil {
+		return nil
+	}
+	if katagoSessOpts == nil {
+		return fmt.Errorf("katago batch session unavailable")
+	}
+	newKataBatchTensors()
+	s, err := ort.NewAdvancedSessionWithONNXData(
+		katagoModelBytes,
+		[]string{katagoInputSpatial, katagoInputGlobal},
+		[]string{katagoOutputPolicy, katagoOutputValue},
+		[]ort.Value{katagoInSpatialB, katagoInGlobalB},
+		[]ort.Value{katagoOutPolicyB, katagoOutValueB},
+		katagoSessOpts,
+	)
+	if err != nil {
+		destroyKataBatchTensors()
+		return fmt.Errorf("rebuild katago batch session: %w", err)
+	}
+	katagoSessBatch = s
+	return nil
+}
@@ -0,0 +1,95 @@
+// game/nn_stats.go
+package game
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// NN 推理计数：每次会话 Run 记一次调用、有效局面数、批容量与耗时，
+// 用来看批量是否填满、推理延迟是否成为瓶颈。常开：相对一次推理，多一次 time.Now 和几次原子加可以忽略。
+
+const (
+	nnCNN       = iota // onnx_infer.go 的 3 平面 CNN
+	nnKata             // KataGo 单样本会话
+	nnKataBatch        // KataGo 固定批量会话
+	nnSnapshot         // PVModel（league 快照等）
+	numNNSessions
+)
+
+var nnSessionNames = [numNNSessions]string{"cnn", "katago", "katago-batch", "snapshot"}
+
+// NNSessionStats 一类会话的累计推理统计
+type NNSessionStats struct {
+	Provider  string        `json:"provider,omitempty"` // 实际生效的 Execution Provider（TensorRT/CUDA/CoreML/CPU...）
+	Calls     uint64        `json:"calls"`
+	Positions uint64        `json:"positions"` // 实际送入的局面数
+	Capacity  uint64        `json:"capacity"`  // 批容量合计；Positions/Capacity 即批占用率
+	RunTime   time.Duration `json:"run_time_ns"`
+}
+
+// Occupancy 平均批占用率 [0,1]
+func (s NNSessionStats) Occupancy() float64 {
+	if s.Capacity == 0 {
+		return 0
+	}
+	return float64(s.Positions) / float64(s.Capacity)
+}
+
+// AvgLatency 每次 Run 的平均耗时
+func (s NNSessionStats) AvgLatency() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.RunTime / time.Duration(s.Calls)
+}
+
+var nnCounters [numNNSessions]struct {
+	calls, positions, capacity uint64
+	runNanos                   int64
+}
+
+var (
+	nnBackendMu sync.Mutex
+	nnBackends  [numNNSessions]BackendSession // 见 backend_report.go
+)
+
+func setNNProvider(slot int, provider string) {
+	nnBackendMu.Lock()
+	nnBackends[slot].Provider = provider
+	nnBackendMu.Unlock()
+}
+
+// countNNRun 在会话 Run 返回后调用；start 为 Run 之前的时间
+func countNNRun(slot, positions, capacity int, start time.Time) {
+	c := &nnCounters[slot]
+	atomic.AddUint64(&c.calls, 1)
+	atomic.AddUint64(&c.positions, uint64(positions))
+	atomic.AddUint64(&c.capacity, uint64(capacity))
+	atomic.AddInt64(&c.runNanos, int64(time.Since(start)))
+}
+
+// GetNNStats 返回各类会话的累计统计（只含至少跑过一次的）
+func GetNNStats() map[string]NNSessionStats {
+	nnBackendMu.Lock()
+	backends := nnBackends
+	nnBackendMu.Unlock()
+
+	out := make(map[string]NNSessionStats)
+	for i := range nnCounters {
+		c := &nnCounters[i]
+		calls := atomic.LoadUint64(&c.calls)
+		if calls == 0 {
+			continue
+		}
+		out[nnSessionNames[i]] = NNSessionStats{
+			Provider:  backends[i].Provider,
+			Calls:     calls,
+			Positions: atomic.LoadUint64(&c.positions),
+			Capacity:  atomic.LoadUint64(&c.capacity),
+			RunTime:   time.Duration(atomic.LoadInt64(&c.runNanos)),
+		}
+	}
+	return out
+}
@@ -0,0 +1,347 @@
+// game/notation.go
+package game
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HGN（Hexxagon Game Notation）：仿 PGN 的纯文本对局格式，一个文件可以连着放任意多盘，方便成批导入导出、
+// 贴进聊天或拿别的语言的脚本解析。一盘由标签区、着法区和结果组成，盘与盘之间空一行：
+//
+//	[Event "selfplay"]
+//	[Red "go-d2"]
+//	[White "mcts-800"]
+//	[Result "0-1"]
+//	[Rules "cascade"]
+//
+//	1. 4,0>4,-1 4,-4>3,-3 -4,4>-3,4 3,-3>3,-2 -4,4>-2,4 0,4>0,2 4,0>2,2 0,2>-2,2 -2,4>-1,3 4,-4>2,-3
+//	11. -2,4>-2,3 0-1
+//
+// 标签值是带引号的字符串（转义同 Go）。Result 为 1-0（红胜）、0-1（白胜）、1/2-1/2（和）或 *（未完或不明），
+// 须与着法区末尾的结果一致；Rules 缺省为 standard，Start（开局的 PositionString）缺省为标准开局；
+// 其余标签（Event、Date、Red、White、Source……）原样保留。
+// 着法写作 起点>落点，坐标缺省为轴坐标，可用 Coords 标签改成 offset/cube（见 coords.go），写出时一律是轴坐标。
+// 无子可走时自动让手（同 GameState.MakeMove），着法区里没有让手。“N.” 是下一手的序号（从 1 数，不分红白），
+// 写出时每 10 手换行并标一次，读入时可有可无，有就核对。; 到行尾、{ } 之间是注释。
+
+// 结果记法，对应 GameRecord.Result
+const (
+	ResultRed     = "1-0"
+	ResultWhite   = "0-1"
+	ResultDraw    = "1/2-1/2"
+	ResultUnknown = "*"
+)
+
+// HGN 里有专门含义的标签名
+const (
+	TagResult = "Result"
+	TagRules  = "Rules"
+	TagStart  = "Start"
+	TagCoords = "Coords"
+)
+
+// 写出时排在前面的标签，其余按名字排序
+var hgnTagOrder = []string{"Event", "Site", "Date", "Round", "Red", "White"}
+
+const hgnMovesPerLine = 10
+
+// GameRecord 一盘 HGN 对局
+type GameRecord struct {
+	Tags   map[string]string // Result/Rules/Start/Coords 以外的标签
+	Rules  string            // Rules.String()；空=standard
+	Start  string            // 开局的 PositionString；空=标准开局
+	Moves  []Move
+	Result string // ResultRed 等；空按 ResultUnknown 写出
+}
+
+// ResultOf 终局胜者对应的结果记法；Empty 为和棋
+func ResultOf(winner CellState) string {
+	switch winner {
+	case PlayerA:
+		return ResultRed
+	case PlayerB:
+		return ResultWhite
+	}
+	return ResultDraw
+}
+
+// Winner 结果对应的胜者（和棋为 Empty）；结果不明时 ok=false
+func (g GameRecord) Winner() (winner CellState, ok bool) {
+	switch g.Result {
+	case ResultRed:
+		return PlayerA, true
+	case ResultWhite:
+		return PlayerB, true
+	case ResultDraw:
+		return Empty, true
+	}
+	return Empty, false
+}
+
+// StartState 开局；Start 为空时是标准开局
+func (g GameRecord) StartState() (*GameState, error) {
+	if g.Start == "" {
+		return NewGameState(boardRadius), nil
+	}
+	return ParsePosition(g.Start)
+}
+
+// Replay 从开局走完全部着法；调用方须先按 Rules 设好规则（同 SavedGame.Restore，这里不替调用方切换）
+func (g GameRecord) Replay() (*GameState, error) {
+	start, err := g.StartState()
+	if err != nil {
+		return nil, fmt.Errorf("hgn: start: %w", err)
+	}
+	return ReplayMoves(start, g.Moves)
+}
+
+// WriteGame 写出一盘 HGN，末尾带一个空行
+func WriteGame(w io.Writer, g GameRecord) error {
+	bw := bufio.NewWriter(w)
+	result := g.Result
+	if result == "" {
+		result = ResultUnknown
+	}
+	tag := func(name, value string) {
+		fmt.Fprintf(bw, "[%s %s]\n", name, strconv.Quote(value))
+	}
+	var rest []string
+	for name := range g.Tags {
+		if !isHGNFieldTag(name) && !containsString(hgnTagOrder, name) {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	for _, name := range hgnTagOrder {
+		if v, ok := g.Tags[name]; ok {
+			tag(name, v)
+		}
+	}
+	tag(TagResult, result)
+	if g.Rules != "" && g.Rules != "standard" {
+		tag(TagRules, g.Rules)
+	}
+	if g.Start != "" {
+		tag(TagStart, g.Start)
+	}
+	for _, name := range rest {
+		tag(name, g.Tags[name])
+	}
+	bw.WriteByte('\n')
+	for i, mv := range g.Moves {
+		switch {
+		case i%hgnMovesPerLine == 0:
+			if i > 0 {
+				bw.WriteByte('\n')
+			}
+			fmt.Fprintf(bw, "%d. ", i+1)
+		default:
+			bw.WriteByte(' ')
+		}
+		bw.WriteString(FormatCoord(mv.From, CoordAxial) + ">" + FormatCoord(mv.To, CoordAxial))
+	}
+	if len(g.Moves) > 0 {
+		bw.WriteByte(' ')
+	}
+	bw.WriteString(result + "\n\n")
+	return bw.Flush()
+}
+
+// ParseGame 解析恰好一盘 HGN
+func ParseGame(s string) (GameRecord, error) {
+	games, err := parseHGN(s)
+	if err != nil {
+		return GameRecord{}, err
+	}
+	if len(games) != 1 {
+		return GameRecord{}, fmt.Errorf("hgn: %d games, want 1", len(games))
+	}
+	return games[0], nil
+}
+
+// ParseGames 解析一个 HGN 文件里的全部对局；只检查格式，着法是否合法由 Replay 判断
+func ParseGames(r io.Reader) ([]GameRecord, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseHGN(string(data))
+}
+
+func isHGNFieldTag(name string) bool {
+	return name == TagResult || name == TagRules || name == TagStart || name == TagCoords
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+type hgnParser struct {
+	s    string
+	pos  int
+	line int
+}
+
+func parseHGN(s string) ([]GameRecord, error) {
+	p := &hgnParser{s: s, line: 1}
+	var games []GameRecord
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.s) {
+			return games, nil
+		}
+		g, err := p.game()
+		if err != nil {
+			return nil, fmt.Errorf("hgn: game %d: line %d: %w", len(games)+1, p.line, err)
+		}
+		games = append(games, g)
+	}
+}
+
+// skipSpace 跳过空白与注释
+func (p *hgnParser) skipSpace() {
+	for p.pos < len(p.s) {
+		switch c := p.s[p.pos]; {
+		case c == '\n':
+			p.line++
+			p.pos++
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == ';':
+			for p.pos < len(p.s) && p.s[p.pos] != '\n' {
+				p.pos++
+			}
+		case c == '{':
+			for p.pos < len(p.s) && p.s[p.pos] != '}' {
+				if p.s[p.pos] == '\n' {
+					p.line++
+				}
+				p.pos++
+			}
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// token 读到空白或注释为止
+func (p *hgnParser) token() string {
+	start := p.pos
+	for p.pos < len(p.s) && !strings.ContainsRune(" \t\r\n;{", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *hgnParser) game() (GameRecord, error) {
+	g := GameRecord{Tags: map[string]string{}}
+	sys := CoordAxial
+	for p.skipSpace(); p.pos < len(p.s) && p.s[p.pos] == '['; p.skipSpace() {
+		name, value, err := p.tag()
+		if err != nil {
+			return g, err
+		}
+		switch name {
+		case TagResult:
+			g.Result = value
+		case TagRules:
+			g.Rules = value
+		case TagStart:
+			g.Start = value
+		case TagCoords:
+			if sys, err = ParseCoordSystem(value); err != nil {
+				return g, err
+			}
+		default:
+			g.Tags[name] = value
+		}
+	}
+	if len(g.Tags) == 0 {
+		g.Tags = nil
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.s) {
+			return g, fmt.Errorf("missing result after %d moves", len(g.Moves))
+		}
+		if p.s[p.pos] == '[' {
+			return g, fmt.Errorf("tag after moves (missing result?)")
+		}
+		tok := p.token()
+		switch {
+		case tok == ResultRed || tok == ResultWhite || tok == ResultDraw || tok == ResultUnknown:
+			if g.Result != "" && g.Result != tok {
+				return g, fmt.Errorf("result %s does not match tag %s", tok, g.Result)
+			}
+			g.Result = tok
+			if r, err := ParseRules(g.Rules); err != nil {
+				return g, err
+			} else if r.IsStandard() {
+				g.Rules = ""
+			}
+			return g, nil
+		case strings.HasSuffix(tok, "."):
+			n, err := strconv.Atoi(strings.TrimSuffix(tok, "."))
+			if err != nil {
+				return g, fmt.Errorf("bad token %q", tok)
+			}
+			if n != len(g.Moves)+1 {
+				return g, fmt.Errorf("move number %d, expected %d", n, len(g.Moves)+1)
+			}
+		default:
+			mv, err := parseHGNMove(tok, sys)
+			if err != nil {
+				return g, err
+			}
+			g.Moves = append(g.Moves, mv)
+		}
+	}
+}
+
+// tag 解析 [Name "value"]
+func (p *hgnParser) tag() (name, value string, err error) {
+	end := strings.IndexByte(p.s[p.pos:], '\n')
+	if end < 0 {
+		end = len(p.s) - p.pos
+	}
+	lineText := strings.TrimSpace(p.s[p.pos : p.pos+end])
+	p.pos += end
+	if !strings.HasSuffix(lineText, "]") {
+		return "", "", fmt.Errorf("tag %q: missing ']'", lineText)
+	}
+	name, quoted, ok := strings.Cut(strings.TrimSpace(lineText[1:len(lineText)-1]), " ")
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("tag %q: want [Name \"value\"]", lineText)
+	}
+	if value, err = strconv.Unquote(strings.TrimSpace(quoted)); err != nil {
+		return "", "", fmt.Errorf("tag %q: %w", lineText, err)
+	}
+	return name, value, nil
+}
+
+func parseHGNMove(tok string, sys CoordSystem) (Move, error) {
+	from, to, ok := strings.Cut(tok, ">")
+	if !ok {
+		return Move{}, fmt.Errorf("bad token %q", tok)
+	}
+	f, err := ParseCoord(from, sys)
+	if err != nil {
+		return Move{}, fmt.Errorf("move %q: %w", tok, err)
+	}
+	t, err := ParseCoord(to, sys)
+	if err != nil {
+		return Move{}, fmt.Errorf("move %q: %w", tok, err)
+	}
+	return Move{From: f, To: t}, nil
+}
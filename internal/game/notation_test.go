@@ -0,0 +1,120 @@
+package game
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// 走 25 手（跨过一次换行）写出再读回，标签、着法、结果都要原样回来，重放出的局面一致
+func TestHGNRoundTrip(t *testing.T) {
+	withRules(t, Rules{Cascade: true})
+	st := NewGameState(boardRadius)
+	var moves []Move
+	for i := 0; i < 25 && !st.GameOver; i++ {
+		mv := GenerateMoves(st.Board, st.CurrentPlayer)[0]
+		if _, _, err := st.MakeMove(mv); err != nil {
+			t.Fatal(err)
+		}
+		moves = append(moves, mv)
+	}
+	g := GameRecord{
+		Tags:   map[string]string{"Red": "go-d2", "White": `mcts "800"`, "Source": "games.json#3"},
+		Rules:  CurrentRules().String(),
+		Moves:  moves,
+		Result: ResultWhite,
+	}
+	var buf bytes.Buffer
+	if err := WriteGame(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteGame(&buf, GameRecord{}); err != nil {
+		t.Fatal(err)
+	}
+	games, err := ParseGames(&buf)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, buf.String())
+	}
+	if len(games) != 2 {
+		t.Fatalf("读回 %d 盘，期望 2", len(games))
+	}
+	if !reflect.DeepEqual(games[0], g) {
+		t.Fatalf("读回 %+v\n期望 %+v", games[0], g)
+	}
+	if games[1].Result != ResultUnknown || len(games[1].Moves) != 0 {
+		t.Fatalf("空对局读回 %+v", games[1])
+	}
+	got, err := games[0].Replay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Board.Cells != st.Board.Cells || got.CurrentPlayer != st.CurrentPlayer {
+		t.Fatal("重放的局面与直接下的不一致")
+	}
+}
+
+// notation.go 文档里的示例必须能读、能重放
+func TestParseGameDocExample(t *testing.T) {
+	const doc = `[Event "selfplay"]
+[Red "go-d2"]
+[White "mcts-800"]
+[Result "0-1"]
+[Rules "cascade"]
+
+1. 4,0>4,-1 4,-4>3,-3 -4,4>-3,4 3,-3>3,-2 -4,4>-2,4 0,4>0,2 4,0>2,2 0,2>-2,2 -2,4>-1,3 4,-4>2,-3
+11. -2,4>-2,3 0-1
+`
+	g, err := ParseGame(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Moves) != 11 || g.Result != ResultWhite || g.Tags["Red"] != "go-d2" {
+		t.Fatalf("读到 %+v", g)
+	}
+	rules, err := ParseRules(g.Rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withRules(t, rules)
+	if _, err := g.Replay(); err != nil {
+		t.Fatalf("重放: %v", err)
+	}
+}
+
+func TestParseGameCommentsAndCoords(t *testing.T) {
+	start := NewGameState(boardRadius)
+	mv := GenerateMoves(start.Board, PlayerA)[0]
+	off := func(c HexCoord) string { return FormatCoord(c, CoordOffset) }
+	text := "; 手写的一盘\n[Coords \"offset\"]\n[Result \"1-0\"]\n\n" +
+		"1. " + off(mv.From) + ">" + off(mv.To) + " {开局} 1-0\n"
+	g, err := ParseGame(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Moves) != 1 || g.Moves[0] != mv || g.Tags != nil {
+		t.Fatalf("读到 %+v，期望着法 %v", g, mv)
+	}
+	if w, ok := g.Winner(); !ok || w != PlayerA {
+		t.Fatalf("胜者 %v %v", w, ok)
+	}
+}
+
+func TestParseGameErrors(t *testing.T) {
+	for name, text := range map[string]string{
+		"noResult":  "[Red \"a\"]\n1. 0,0>1,0",
+		"mismatch":  "[Result \"1-0\"]\n0-1",
+		"moveNo":    "2. -4,0>-3,0 *",
+		"badMove":   "1. -4,0-3,0 *",
+		"badTag":    "[Red a]\n*",
+		"badRules":  "[Rules \"chaos\"]\n*",
+		"badCoords": "[Coords \"polar\"]\n*",
+		"twoGames":  "*\n\n*",
+	} {
+		if _, err := ParseGame(text); err == nil {
+			t.Errorf("%s: 应解析失败", name)
+		} else if !strings.HasPrefix(err.Error(), "hgn: ") {
+			t.Errorf("%s: 错误应带 hgn 前缀: %v", name, err)
+		}
+	}
+}
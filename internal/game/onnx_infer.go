@@ -10,6 +10,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	ort "github.com/yalue/onnxruntime_go"
 )
@@ -25,9 +26,8 @@ const (
 	onnxInputName  = "state"
 	onnxPolicyName = "policy"
 	onnxValueName  = "value"
-	grid           = 9
-	radius         = 4
-	featPlanes     = 3 // [my, opp, mask]
+	grid           = GridSize
+	featPlanes     = 3 // FeaturesCNN3: [my, opp, mask]
 	policyOutDim   = 81
 )
 
@@ -47,6 +47,7 @@ var (
 func ensureONNX() error {
 	//log.Printf("[ensureONNX] invoked")
 	ortOnce.Do(func() {
+		var modelSource string
 		// 0) 外部模型路径优先：设置 HEX_ONNX_PATH 指定
 		if path := os.Getenv("HEX_ONNX_PATH"); path != "" {
 			b, err := os.ReadFile(path)
@@ -55,6 +56,7 @@ func ensureONNX() error {
 				return
 			}
 			externalModel = b
+			modelSource = path
 			log.Printf("[ensureONNX] using external ONNX: %s%s", path, ansiReset)
 		} else {
 			// 尝试从 embed 的 assets 目录找任意 .onnx
@@ -68,6 +70,7 @@ func ensureONNX() error {
 						b, rerr := embeddedFS.ReadFile("assets/" + e.Name())
 						if rerr == nil {
 							externalModel = b
+							modelSource = "assets/" + e.Name()
 							log.Printf("[ensureONNX] using embedded ONNX: assets/%s%s", e.Name(), ansiReset)
 							break
 						}
@@ -86,6 +89,7 @@ func ensureONNX() error {
 		}
 		log.Printf("[ensureONNX] using ORT shared lib: %s%s", libPath, ansiReset)
 		ort.SetSharedLibraryPath(libPath)
+		noteORTLib(libPath)
 
 		// 2) 初始化 ORT
 		if err := ort.InitializeEnvironment(); err != nil {
@@ -94,7 +98,7 @@ func ensureONNX() error {
 			return
 		}
 		fmt.Print(ansiReset)
-		log.Printf("[ensureONNX] InitializeEnvironment succeeded%s", ansiReset)
+		log.Printf("[ensureONNX] InitializeEnvironment succeeded, ORT %s%s", ort.GetVersion(), ansiReset)
 
 		// 3) 模型字节自检（外部优先，否则 embed）
 		modelBytes := externalModel
@@ -102,12 +106,13 @@ func ensureONNX() error {
 			ortErr = fmt.Errorf("no ONNX model: set HEX_ONNX_PATH or place any .onnx under internal/game/assets")
 			return
 		}
-		inputs, outputs, gierr := ort.GetInputOutputInfoWithONNXData(modelBytes)
-		if gierr != nil {
-			ortErr = fmt.Errorf("GetInputOutputInfoWithONNXData: %w", gierr)
+		info, cerr := inspectModel("cnn", modelSource, modelBytes, cnnModelSpec)
+		if cerr != nil {
+			ortErr = cerr
+			log.Printf("[ensureONNX] %v%s", cerr, ansiReset)
 			return
 		}
-		log.Printf("[ensureONNX] model IO info: inputs=%v outputs=%v%s", inputs, outputs, ansiReset)
+		log.Printf("[ensureONNX] model IO info: inputs=%v outputs=%v%s", info.Inputs, info.Outputs, ansiReset)
 
 		// 4) 创建 I/O 张量（必须在 InitializeEnvironment 之后）
 		var e error
@@ -134,6 +139,7 @@ func ensureONNX() error {
 			return
 		}
 		_ = so.SetLogSeverityLevel(3)
+		applyORTConfigLogged("ensureONNX", so)
 		
 		// 优先尝试 GPU 加速
 		gpuEnabled := false
@@ -143,6 +149,7 @@ func ensureONNX() error {
 				"use_ane": "1",
 			}); err == nil {
 				log.Printf("[ensureONNX] CoreML Execution Provider enabled.%s", ansiReset)
+				setNNProvider(nnCNN, "CoreML")
 				gpuEnabled = true
 			}
 		} else if runtime.GOOS == "windows" {
@@ -151,6 +158,7 @@ func ensureONNX() error {
 				trtOpts.Update(map[string]string{"trt_fp16_enable": "1"})
 				if err := so.AppendExecutionProviderTensorRT(trtOpts); err == nil {
 					log.Printf("[ensureONNX] TensorRT Execution Provider enabled.%s", ansiReset)
+					setNNProvider(nnCNN, "TensorRT")
 					gpuEnabled = true
 				}
 				trtOpts.Destroy()
@@ -159,6 +167,7 @@ func ensureONNX() error {
 				if cudaOpts, e := ort.NewCUDAProviderOptions(); e == nil {
 					if err := so.AppendExecutionProviderCUDA(cudaOpts); err == nil {
 						log.Printf("[ensureONNX] CUDA Execution Provider enabled.%s", ansiReset)
+						setNNProvider(nnCNN, "CUDA")
 						gpuEnabled = true
 					}
 					cudaOpts.Destroy()
@@ -167,6 +176,7 @@ func ensureONNX() error {
 			if !gpuEnabled {
 				if err := so.AppendExecutionProviderDirectML(0); err == nil {
 					log.Printf("[ensureONNX] DirectML Execution Provider enabled.%s", ansiReset)
+					setNNProvider(nnCNN, "DirectML")
 					gpuEnabled = true
 				}
 			}
@@ -176,6 +186,7 @@ func ensureONNX() error {
 				trtOpts.Update(map[string]string{"trt_fp16_enable": "1"})
 				if err := so.AppendExecutionProviderTensorRT(trtOpts); err == nil {
 					log.Printf("[ensureONNX] TensorRT Execution Provider enabled.%s", ansiReset)
+					setNNProvider(nnCNN, "TensorRT")
 					gpuEnabled = true
 				}
 				trtOpts.Destroy()
@@ -184,6 +195,7 @@ func ensureONNX() error {
 				if cudaOpts, e := ort.NewCUDAProviderOptions(); e == nil {
 					if err := so.AppendExecutionProviderCUDA(cudaOpts); err == nil {
 						log.Printf("[ensureONNX] CUDA Execution Provider enabled.%s", ansiReset)
+						setNNProvider(nnCNN, "CUDA")
 						gpuEnabled = true
 					}
 					cudaOpts.Destroy()
@@ -193,6 +205,7 @@ func ensureONNX() error {
 
 		if !gpuEnabled {
 			log.Printf("[ensureONNX] No GPU acceleration enabled, falling back to CPU.%s", ansiReset)
+			setNNProvider(nnCNN, "CPU")
 		}
 
 		ortSess, e = ort.NewAdvancedSessionWithONNXData(
@@ -227,40 +240,6 @@ func ShutdownONNX() {
 	ort.DestroyEnvironment()
 }
 
-// 计算 (q,r) 是否在半径为 4 的六边形棋盘内
-func inBounds(q, r int) bool {
-	return abs(q) <= radius && abs(r) <= radius && abs(-q-r) <= radius
-}
-func toIndex(q, r int) int { // 9x9 平面索引
-	return (r+radius)*grid + (q + radius)
-}
-
-// 把 Board 编成 3×9×9：my=1 / opp=1 / mask=1
-func encodeBoard(b *Board, me CellState, dst []float32) {
-	for i := range dst {
-		dst[i] = 0
-	}
-	// plane offsets
-	offMy, offOpp, offMask := 0, grid*grid, 2*grid*grid
-	for r := -radius; r <= radius; r++ {
-		for q := -radius; q <= radius; q++ {
-			idx := toIndex(q, r)
-			if !inBounds(q, r) {
-				continue
-			}
-			for i := 0; i < BoardN; i++ {
-				switch b.Cells[i] {
-				case me:
-					dst[offMy+i] = 1
-				case Opponent(me):
-					dst[offOpp+i] = 1
-				}
-			}
-			dst[offMask+idx] = 1
-		}
-	}
-}
-
 // 只取 value 头做静态评估（返回 int，方便接到你的评分框架）
 //func EvaluateNN(b *Board, me CellState) int {
 //	if err := ensureONNX(); err != nil {
@@ -291,13 +270,18 @@ func EvaluateNN3(b *Board, me CellState) int {
 		fmt.Fprintln(os.Stderr, "Failed to init ONNX:", err)
 		return 0
 	}
+	if !FitsNN(b) {
+		return 0
+	}
 	// 填充输入
 	data := inTensor.GetData()
 	encodeBoard(b, me, data)
 
 	// 跑一次
 	ortMu.Lock()
+	t0 := time.Now()
 	err := ortSess.Run()
+	countNNRun(nnCNN, 1, 1, t0)
 	ortMu.Unlock()
 	if err != nil {
 		return 0
@@ -315,13 +299,18 @@ func PolicyNN(b *Board, me CellState) ([]float32, error) {
 		fmt.Fprintln(os.Stderr, "Failed to init ONNX:", err)
 		return nil, err
 	}
+	if !FitsNN(b) {
+		return nil, ErrBoardTooLargeForNN
+	}
 	// 输入
 	data := inTensor.GetData()
 	encodeBoard(b, me, data)
 
 	// 跑一次
 	ortMu.Lock()
+	t0 := time.Now()
 	err := ortSess.Run()
+	countNNRun(nnCNN, 1, 1, t0)
 	ortMu.Unlock()
 	if err != nil {
 		return nil, err
@@ -353,13 +342,18 @@ func PolicyValueNN(b *Board, me CellState) ([]float32, float32, error) {
 		fmt.Fprintln(os.Stderr, "Failed to init ONNX:", err)
 		return nil, 0, err
 	}
+	if !FitsNN(b) {
+		return nil, 0, ErrBoardTooLargeForNN
+	}
 	// 输入
 	data := inTensor.GetData()
 	encodeBoard(b, me, data)
 
 	// 跑一次
 	ortMu.Lock()
+	t0 := time.Now()
 	err := ortSess.Run()
+	countNNRun(nnCNN, 1, 1, t0)
 	ortMu.Unlock()
 	if err != nil {
 		return nil, 0, err
@@ -395,13 +389,9 @@ func PolicyValueNN(b *Board, me CellState) ([]float32, float32, error) {
 // 直接给 policy 向量打非法格 -Inf
 func MaskPolicyInPlace(p []float32) {
 	const negInf = -1.0e30
-	i := 0
-	for r := -radius; r <= radius; r++ {
-		for q := -radius; q <= radius; q++ {
-			if !inBounds(q, r) {
-				p[i] = negInf
-			}
-			i++
+	for g, in := range gridInBoard {
+		if !in {
+			p[g] = negInf
 		}
 	}
 }
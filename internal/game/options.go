@@ -0,0 +1,194 @@
+// game/options.go
+package game
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"hexxagon_go/internal/migrate"
+)
+
+// 运行期可改的搜索/评估参数。界面和自对弈这类长时间运行、TensorRT 热身很贵的进程，
+// 可以从一个 JSON 文件热加载它们做参数实验，不用重启。
+// 这些参数都是包级变量，搜索过程中不加锁读取：ApplyOptions 必须在没有搜索在跑时调用。
+
+// SearchOptions α-β 搜索参数
+type SearchOptions struct {
+	ExtMax       int          // 强制线延伸上限，见 searchExtMax
+	ExtFlipCount int          // 翻子数达到该值即延伸
+	Verify       VerifyConfig // α-β/MCTS 复核；MCTSTime 在 JSON 里按纳秒写
+	AvoidLosing  bool         // 根层避开负面开局库里的已知输棋线（见 negbook.go）；分析时关掉
+}
+
+// EvalOptions 评估与 rollout 参数
+type EvalOptions struct {
+	Phase   PhaseSwitch
+	Rollout RolloutConfig
+	ParityW int // 残局奇偶项权重，0=关闭，见 parity.go
+}
+
+// Options 可热加载的全部参数
+type Options struct {
+	Search SearchOptions
+	Eval   EvalOptions
+}
+
+// CurrentOptions 当前生效的参数
+func CurrentOptions() Options {
+	return Options{
+		Search: SearchOptions{
+			ExtMax:       searchExtMax,
+			ExtFlipCount: searchExtFlipCount,
+			Verify:       verifyCfg,
+			AvoidLosing:  avoidLosing,
+		},
+		Eval: EvalOptions{Phase: phaseSwitch, Rollout: rolloutCfg, ParityW: parityW},
+	}
+}
+
+// Validate 检查取值范围
+func (o Options) Validate() error {
+	s, e := o.Search, o.Eval
+	switch {
+	case s.ExtMax < 0:
+		return fmt.Errorf("Search.ExtMax 须 >= 0: %d", s.ExtMax)
+	case s.ExtFlipCount < 1:
+		return fmt.Errorf("Search.ExtFlipCount 须 >= 1: %d", s.ExtFlipCount)
+	case s.Verify.Mode < VerifyOff || s.Verify.Mode > VerifyMCTSByAB:
+		return fmt.Errorf("Search.Verify.Mode 未知: %d", s.Verify.Mode)
+	case s.Verify.MCTSSims < 0 || s.Verify.MCTSTime < 0 || s.Verify.MaxExtend < 0:
+		return fmt.Errorf("Search.Verify 的次数/时间/加深层数不能为负")
+	case e.Phase.ROpen < 0 || e.Phase.ROpen > 1 || e.Phase.REnd < 0 || e.Phase.REnd > e.Phase.ROpen:
+		return fmt.Errorf("Eval.Phase 须满足 0 <= REnd <= ROpen <= 1: REnd=%g ROpen=%g", e.Phase.REnd, e.Phase.ROpen)
+	case e.Rollout.Epsilon < 0 || e.Rollout.Epsilon > 1:
+		return fmt.Errorf("Eval.Rollout.Epsilon 须在 [0,1]: %g", e.Rollout.Epsilon)
+	case e.Rollout.NNBlend < 0 || e.Rollout.NNBlend > 1:
+		return fmt.Errorf("Eval.Rollout.NNBlend 须在 [0,1]: %g", e.Rollout.NNBlend)
+	case e.Rollout.NNCutoff < 0:
+		return fmt.Errorf("Eval.Rollout.NNCutoff 须 >= 0: %d", e.Rollout.NNCutoff)
+	case e.ParityW < 0:
+		return fmt.Errorf("Eval.ParityW 须 >= 0: %d", e.ParityW)
+	}
+	return nil
+}
+
+// ApplyOptions 校验后整体生效。评估参数变了，TT 里按旧参数算的分数一并作废
+func ApplyOptions(o Options) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	prev := CurrentOptions()
+	searchExtMax, searchExtFlipCount = o.Search.ExtMax, o.Search.ExtFlipCount
+	avoidLosing = o.Search.AvoidLosing
+	SetVerifyConfig(o.Search.Verify)
+	SetPhaseSwitch(o.Eval.Phase)
+	SetRolloutConfig(o.Eval.Rollout)
+	parityW = o.Eval.ParityW
+	if o != prev {
+		ClearTT()
+	}
+	return nil
+}
+
+// OptionsVersion 参数文件的当前版本。文件里可写 "version"，不写当第 1 版；
+// 字段改名、换单位时加一，并在 optionsFormat 登记升级步骤，老的参数文件照样能读
+const OptionsVersion = 1
+
+var optionsFormat = migrate.NewFormat("options", OptionsVersion, 1)
+
+// LoadOptions 读参数文件。文件里只写要改的字段即可，没写的沿用当前值
+func LoadOptions(path string) (Options, error) {
+	o := CurrentOptions()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return o, err
+	}
+	if _, err := optionsFormat.Decode(data, &o); err != nil {
+		return o, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := o.Validate(); err != nil {
+		return o, fmt.Errorf("%s: %w", path, err)
+	}
+	return o, nil
+}
+
+// OptionsWatcher 监视参数文件：修改时间变了、收到 SIGHUP 或调用 Reload 时重新读取。
+// 读到的参数交给 onLoad，由调用方挑没有搜索在跑的时机 ApplyOptions；读失败交给 onErr
+type OptionsWatcher struct {
+	path    string
+	onLoad  func(Options)
+	onErr   func(error)
+	reload  chan struct{}
+	stop    chan struct{}
+	stopped sync.Once
+}
+
+// WatchOptions 开始监视 path，每 interval 查一次修改时间
+func WatchOptions(path string, interval time.Duration, onLoad func(Options), onErr func(error)) *OptionsWatcher {
+	w := &OptionsWatcher{
+		path:   path,
+		onLoad: onLoad,
+		onErr:  onErr,
+		reload: make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+	}
+	go w.loop(interval)
+	return w
+}
+
+// Reload 立即重读一次（界面按键、控制台命令用）
+func (w *OptionsWatcher) Reload() {
+	select {
+	case w.reload <- struct{}{}:
+	default:
+	}
+}
+
+// Stop 结束监视
+func (w *OptionsWatcher) Stop() {
+	w.stopped.Do(func() { close(w.stop) })
+}
+
+func (w *OptionsWatcher) loop(interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+
+	mtime := w.modTime()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-hup:
+		case <-w.reload:
+		case <-tick.C:
+			m := w.modTime()
+			if m.Equal(mtime) {
+				continue
+			}
+			mtime = m
+		}
+		o, err := LoadOptions(w.path)
+		if err != nil {
+			if w.onErr != nil {
+				w.onErr(err)
+			}
+			continue
+		}
+		w.onLoad(o)
+	}
+}
+
+func (w *OptionsWatcher) modTime() time.Time {
+	fi, err := os.Stat(w.path)
+	if err != nil {
+		return time.Time{}
+	}
+	return fi.ModTime()
+}
@@ -0,0 +1,92 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func withOptions(t *testing.T) {
+	t.Helper()
+	prev := CurrentOptions()
+	t.Cleanup(func() {
+		if err := ApplyOptions(prev); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestLoadOptionsPartialFile(t *testing.T) {
+	withOptions(t)
+	path := filepath.Join(t.TempDir(), "opts.json")
+	if err := os.WriteFile(path, []byte(`{"Search":{"ExtMax":0},"Eval":{"Rollout":{"NNBlend":0.5}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	before := CurrentOptions()
+	o, err := LoadOptions(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Search.ExtMax != 0 || o.Eval.Rollout.NNBlend != 0.5 {
+		t.Fatalf("文件里写的字段没读到: %+v", o)
+	}
+	if o.Search.ExtFlipCount != before.Search.ExtFlipCount || o.Eval.Phase != before.Eval.Phase {
+		t.Fatalf("没写的字段应沿用当前值: %+v", o)
+	}
+	if err := ApplyOptions(o); err != nil {
+		t.Fatal(err)
+	}
+	if searchExtMax != 0 || rolloutCfg.NNBlend != 0.5 {
+		t.Fatalf("ApplyOptions 没生效: ext=%d blend=%g", searchExtMax, rolloutCfg.NNBlend)
+	}
+}
+
+func TestApplyOptionsRejectsInvalid(t *testing.T) {
+	withOptions(t)
+	o := CurrentOptions()
+	o.Eval.Phase.REnd, o.Eval.Phase.ROpen = 0.8, 0.5
+	if err := ApplyOptions(o); err == nil {
+		t.Fatal("REnd > ROpen 应报错")
+	}
+	if phaseSwitch.REnd == 0.8 {
+		t.Fatal("校验失败时不应改动当前参数")
+	}
+}
+
+func TestOptionsWatcherReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "opts.json")
+	if err := os.WriteFile(path, []byte(`{"Search":{"ExtFlipCount":6}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got := make(chan Options, 1)
+	w := WatchOptions(path, time.Hour, func(o Options) { got <- o }, func(err error) { t.Error(err) })
+	defer w.Stop()
+	w.Reload()
+	select {
+	case o := <-got:
+		if o.Search.ExtFlipCount != 6 {
+			t.Fatalf("重读结果不对: %+v", o.Search)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Reload 后没有回调")
+	}
+}
+
+// 每一版参数文件的样例（testdata/options_v*.json）都要能读：第 1 版没有 version 字段
+func TestLoadOptionsFixtures(t *testing.T) {
+	withOptions(t)
+	paths, _ := filepath.Glob("testdata/options_v*.json")
+	if len(paths) != OptionsVersion {
+		t.Fatalf("testdata 里有 %d 个版本的样例，应为 %d（每一版留一个）", len(paths), OptionsVersion)
+	}
+	for _, p := range paths {
+		o, err := LoadOptions(p)
+		if err != nil {
+			t.Fatalf("%s: %v", p, err)
+		}
+		if o.Search.ExtMax != 2 || o.Search.AvoidLosing || o.Eval.ParityW != 4 {
+			t.Fatalf("%s: 读到的参数不对: %+v", p, o)
+		}
+	}
+}
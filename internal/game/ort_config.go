@@ -0,0 +1,105 @@
+// internal/game/ort_config.go
+package game
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	ort "github.com/yalue/onnxruntime_go"
+)
+
+// ORTConfig：创建 ORT 会话时的 CPU 侧选项。零值 = ORT 自己的默认。
+// ORT 默认 intra-op 线程数等于物理核数，hybrid 评估时会和 α-β / MCTS 的搜索线程抢核；
+// 搜索并发开得高时，把 IntraOpThreads 压到 1~2 往往总吞吐更高。
+// 必须在第一次推理（会话创建）之前设置，之后修改不影响已建好的会话。
+type ORTConfig struct {
+	IntraOpThreads int    // 单个算子内部并行线程数（0 = ORT 默认）
+	InterOpThreads int    // 算子之间并行线程数，仅并行执行模式下生效（0 = ORT 默认）
+	NoCPUMemArena  bool   // 关闭 CPU 内存池（省常驻内存，推理稍慢）
+	NoMemPattern   bool   // 关闭按首轮形状预分配的内存规划
+	GraphOpt       string // 图优化级别：none / basic / extended / all（空 = ORT 默认 all）
+}
+
+var ortCfg ORTConfig
+
+func SetORTConfig(c ORTConfig) { ortCfg = c }
+
+func CurrentORTConfig() ORTConfig { return ortCfg }
+
+// parseGraphOptLevel 把 -ort-opt 之类的字符串映射到 ORT 枚举；ok=false 表示沿用默认
+func parseGraphOptLevel(s string) (lvl ort.GraphOptimizationLevel, ok bool, err error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return 0, false, nil
+	case "none", "disable", "0":
+		return ort.GraphOptimizationLevelDisableAll, true, nil
+	case "basic", "1":
+		return ort.GraphOptimizationLevelEnableBasic, true, nil
+	case "extended", "2":
+		return ort.GraphOptimizationLevelEnableExtended, true, nil
+	case "all", "99":
+		return ort.GraphOptimizationLevelEnableAll, true, nil
+	}
+	return 0, false, fmt.Errorf("unknown graph optimization level %q (none/basic/extended/all)", s)
+}
+
+// Validate 检查配置是否合法，供命令行解析后尽早报错
+func (c ORTConfig) Validate() error {
+	if c.IntraOpThreads < 0 || c.InterOpThreads < 0 {
+		return fmt.Errorf("ORT thread counts must be >= 0")
+	}
+	_, _, err := parseGraphOptLevel(c.GraphOpt)
+	return err
+}
+
+func (c ORTConfig) String() string {
+	opt := c.GraphOpt
+	if opt == "" {
+		opt = "default"
+	}
+	return fmt.Sprintf("intra=%d inter=%d arena=%v mempattern=%v opt=%s",
+		c.IntraOpThreads, c.InterOpThreads, !c.NoCPUMemArena, !c.NoMemPattern, opt)
+}
+
+// ApplyORTConfig 把当前配置写进会话选项；各处 NewSessionOptions 之后调用
+func ApplyORTConfig(so *ort.SessionOptions) error {
+	c := ortCfg
+	if c.IntraOpThreads > 0 {
+		if err := so.SetIntraOpNumThreads(c.IntraOpThreads); err != nil {
+			return fmt.Errorf("SetIntraOpNumThreads: %w", err)
+		}
+	}
+	if c.InterOpThreads > 0 {
+		if err := so.SetInterOpNumThreads(c.InterOpThreads); err != nil {
+			return fmt.Errorf("SetInterOpNumThreads: %w", err)
+		}
+	}
+	if c.NoCPUMemArena {
+		if err := so.SetCpuMemArena(false); err != nil {
+			return fmt.Errorf("SetCpuMemArena: %w", err)
+		}
+	}
+	if c.NoMemPattern {
+		if err := so.SetMemPattern(false); err != nil {
+			return fmt.Errorf("SetMemPattern: %w", err)
+		}
+	}
+	lvl, ok, err := parseGraphOptLevel(c.GraphOpt)
+	if err != nil {
+		return err
+	}
+	if ok {
+		if err := so.SetGraphOptimizationLevel(lvl); err != nil {
+			return fmt.Errorf("SetGraphOptimizationLevel: %w", err)
+		}
+	}
+	return nil
+}
+
+// applyORTConfigLogged 会话创建路径上用：选项设置失败只记日志，不影响后续 EP 尝试
+func applyORTConfigLogged(tag string, so *ort.SessionOptions) {
+	if err := ApplyORTConfig(so); err != nil {
+		log.Printf("[%s] ORT options (%s): %v%s", tag, ortCfg, err, ansiReset)
+	}
+}
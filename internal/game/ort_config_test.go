@@ -0,0 +1,33 @@
+package game
+
+import "testing"
+
+func TestORTConfigValidate(t *testing.T) {
+	ok := []ORTConfig{
+		{},
+		{IntraOpThreads: 1, InterOpThreads: 1, GraphOpt: "basic"},
+		{GraphOpt: "ALL"},
+		{GraphOpt: "none", NoCPUMemArena: true},
+	}
+	for _, c := range ok {
+		if err := c.Validate(); err != nil {
+			t.Errorf("%+v: 不应报错: %v", c, err)
+		}
+	}
+	bad := []ORTConfig{
+		{IntraOpThreads: -1},
+		{InterOpThreads: -2},
+		{GraphOpt: "fast"},
+	}
+	for _, c := range bad {
+		if err := c.Validate(); err == nil {
+			t.Errorf("%+v: 应当报错", c)
+		}
+	}
+}
+
+func TestParseGraphOptLevelDefault(t *testing.T) {
+	if _, set, err := parseGraphOptLevel(""); set || err != nil {
+		t.Fatalf("空字符串应沿用 ORT 默认: set=%v err=%v", set, err)
+	}
+}
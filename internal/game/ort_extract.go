@@ -0,0 +1,90 @@
+// internal/game/ort_extract.go
+package game
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// 内置动态库释放到 exe 旁边时的完整性校验。
+// 旧做法是“文件在就不写”，损坏的或上个版本留下的 onnxruntime.dll 会被悄悄复用，
+// 表现为 InitializeEnvironment 报 API 版本不符或者直接崩溃，很难和模型问题区分。
+
+// ensureFile 确保 path 的内容与内置 data 一致：不存在则写出，SHA-256 不符则重新释放
+func ensureFile(path string, data []byte) error {
+	want := sha256.Sum256(data)
+	name := filepath.Base(path)
+
+	got, err := fileSHA256(path)
+	switch {
+	case err == nil && got == want:
+		log.Printf("[ort] %s 校验通过 sha256=%s", name, shortHash(want))
+		return nil
+	case err == nil:
+		log.Printf("[ort] %s 与内置版本不符（磁盘 %s，内置 %s），重新释放", name, shortHash(got), shortHash(want))
+	case errors.Is(err, os.ErrNotExist):
+	default:
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+
+	if err := writeFileAtomic(path, data); err != nil {
+		// 多进程同时释放时对方可能已经写好了同样的内容
+		if again, rerr := fileSHA256(path); rerr == nil && again == want {
+			return nil
+		}
+		return fmt.Errorf("extract %s: %w（文件可能正被其他进程占用）", name, err)
+	}
+	log.Printf("[ort] 已释放 %s sha256=%s", name, shortHash(want))
+	return nil
+}
+
+func fileSHA256(path string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	f, err := os.Open(path)
+	if err != nil {
+		return sum, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return sum, err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+// writeFileAtomic 先写同目录临时文件再改名，别的进程不会读到写了一半的库
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // 改名成功后这里是空操作
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := io.Copy(tmp, bytes.NewReader(data)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func shortHash(sum [sha256.Size]byte) string {
+	return hex.EncodeToString(sum[:6])
+}
@@ -0,0 +1,47 @@
+package game
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnsureFileExtractsAndRepairs(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "libfake.so")
+	payload := []byte("embedded-payload-v2")
+
+	if err := ensureFile(p, payload); err != nil {
+		t.Fatalf("首次释放: %v", err)
+	}
+	if got, _ := os.ReadFile(p); !bytes.Equal(got, payload) {
+		t.Fatalf("释放内容不符: %q", got)
+	}
+
+	// 旧版本 / 损坏的文件应被覆盖
+	if err := os.WriteFile(p, []byte("stale-v1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ensureFile(p, payload); err != nil {
+		t.Fatalf("重新释放: %v", err)
+	}
+	if got, _ := os.ReadFile(p); !bytes.Equal(got, payload) {
+		t.Fatalf("损坏文件未被修复: %q", got)
+	}
+
+	// 一致时不重写
+	fi1, _ := os.Stat(p)
+	if err := ensureFile(p, payload); err != nil {
+		t.Fatal(err)
+	}
+	fi2, _ := os.Stat(p)
+	if !os.SameFile(fi1, fi2) {
+		t.Fatalf("内容一致时不应重新释放")
+	}
+
+	// 不留临时文件
+	if ents, _ := os.ReadDir(dir); len(ents) != 1 {
+		t.Fatalf("目录里残留了 %d 个文件", len(ents))
+	}
+}
@@ -5,8 +5,8 @@ package game
 
 import (
 	_ "embed"
-	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"sync"
@@ -43,6 +43,7 @@ func setWinEnv(key, value string) {
 func prepareORTSharedLib() (string, error) {
 	winLibOnce.Do(func() {
 		if p := os.Getenv("ONNXRUNTIME_SHARED_LIBRARY_PATH"); p != "" {
+			log.Printf("[ort] 使用 ONNXRUNTIME_SHARED_LIBRARY_PATH=%s，跳过内置 DLL 校验", p)
 			winLibPath = p
 			return
 		}
@@ -75,23 +76,3 @@ func prepareORTSharedLib() (string, error) {
 	})
 	return winLibPath, winLibErr
 }
-
-// ensureFile 检查文件是否存在，不存在则写入
-func ensureFile(path string, data []byte) error {
-	if fi, err := os.Stat(path); err == nil && fi.Mode().IsRegular() {
-		return nil // 已存在
-	}
-
-	// 尝试独占创建，避免多进程冲突
-	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
-	if err != nil {
-		if errors.Is(err, os.ErrExist) {
-			return nil
-		}
-		return err
-	}
-	defer f.Close()
-
-	_, err = f.Write(data)
-	return err
-}
@@ -0,0 +1,55 @@
+// internal/game/parity.go
+package game
+
+// 残局奇偶项：格子快填满时，一块双方都落得进去的空格区域，谁走最后一手往往就归谁。
+// 区域空格数为奇数时轮到走的一方占先，偶数时对方占先。
+// 它依赖轮到谁走，不进 EvaluateBitBoard，由搜索叶子在静态评估上另加（见 leafEval）。
+
+// parityW 每块争夺区域的奇偶分，0 = 关闭；可经 Eval.ParityW 热加载或 engine -parity-w 设置。
+// 默认关闭：静态引擎 depth 2 的 tournament 对比里 -parity-w=2 得 23/60，
+// depth 1/3 的小样本互有胜负，还没看到稳定收益。
+var parityW = 0
+
+// ParityScore 从 player 视角的奇偶分，toMove 为轮到走的一方。
+// 只在空格比例不高于残局阈值（phaseSwitch.REnd）时计入，已被一方锁定的区域不算争夺。
+func ParityScore(b *Board, player, toMove CellState) int {
+	if parityW == 0 {
+		return 0
+	}
+	empty := b.EmptyMask()
+	if float64(empty.Count()) > phaseSwitch.REnd*float64(CellCount(ActiveRadius(b))) {
+		return 0
+	}
+	ensurePrecomp()
+	reachA, reachB := b.ReachableMask(PlayerA), b.ReachableMask(PlayerB)
+	n := 0
+	for remain := empty; !remain.IsZero(); {
+		comp := floodComponent(cellBit(remain.first()), empty)
+		remain = remain.AndNot(comp)
+		if comp.And(reachA).IsZero() || comp.And(reachB).IsZero() {
+			continue
+		}
+		if comp.Count()%2 == 1 {
+			n++ // 轮到走的一方走最后一手
+		} else {
+			n--
+		}
+	}
+	if toMove != player {
+		n = -n
+	}
+	return n * parityW
+}
+
+// leafEval 搜索叶子的静态评估：EvaluateBitBoard 加上依赖走子方的奇偶项
+func leafEval(b *Board, player, toMove CellState) int {
+	return EvaluateBitBoard(b, player) + ParityScore(b, player, toMove)
+}
+
+// evaluateAt 同 Evaluate，走静态评估时用 leafEval
+func evaluateAt(b *Board, player, toMove CellState) int {
+	if useNNFor(b, player) {
+		return EvaluateNN(b, player)
+	}
+	return leafEval(b, player, toMove)
+}
@@ -0,0 +1,59 @@
+package game
+
+import "testing"
+
+func withParityW(t *testing.T, w int) {
+	t.Helper()
+	prev := parityW
+	parityW = w
+	t.Cleanup(func() { parityW = prev })
+}
+
+// 几乎填满的棋盘：只留 empties 里的空格，其余 A/B 交替
+func nearlyFullBoard(t *testing.T, empties ...HexCoord) *Board {
+	t.Helper()
+	cells := make([]CellState, CellCount(boardRadius))
+	for i := range cells {
+		cells[i] = PlayerA
+		if CoordOf[i].Q <= 0 {
+			cells[i] = PlayerB
+		}
+	}
+	for _, c := range empties {
+		cells[IndexOf[c]] = Empty
+	}
+	b, err := BoardFromCells(cells)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestParityScore(t *testing.T) {
+	withParityW(t, 4)
+	// 双方交界处的单个空格：奇数，轮到走的一方占先
+	b := nearlyFullBoard(t, HexCoord{Q: 0, R: 0})
+	if got := ParityScore(b, PlayerA, PlayerA); got != 4 {
+		t.Fatalf("A 走时 A 视角应 +4，得到 %d", got)
+	}
+	if got := ParityScore(b, PlayerA, PlayerB); got != -4 {
+		t.Fatalf("B 走时 A 视角应 -4，得到 %d", got)
+	}
+	if ParityScore(b, PlayerB, PlayerB) != ParityScore(b, PlayerA, PlayerA) {
+		t.Fatal("奇偶项应颜色对称")
+	}
+	// 两格相连：偶数，对方占先
+	b2 := nearlyFullBoard(t, HexCoord{Q: 0, R: 0}, HexCoord{Q: 1, R: 0})
+	if got := ParityScore(b2, PlayerA, PlayerA); got != -4 {
+		t.Fatalf("偶数区域应对方占先，得到 %d", got)
+	}
+
+	// 开局不计入；权重为 0 时关闭
+	if got := ParityScore(NewGameState(boardRadius).Board, PlayerA, PlayerA); got != 0 {
+		t.Fatalf("开局不应有奇偶分，得到 %d", got)
+	}
+	parityW = 0
+	if got := ParityScore(b, PlayerA, PlayerA); got != 0 {
+		t.Fatalf("关闭后应为 0，得到 %d", got)
+	}
+}
@@ -0,0 +1,158 @@
+// game/piece_id.go
+package game
+
+// 棋子编号：界面要知道“是哪一颗子跳走了”，复盘要追一颗子的克隆谱系，只看坐标分不清。
+// 开局（或载入局面）时按下标给盘上的子编 1..n；克隆出的子拿下一个新号，跳跃时号跟着子走，
+// 被感染的子只是翻色，号不变。makeMove/UnmakeMove 顺带维护，不进哈希也不进 PositionString。
+
+// PieceID 棋子的稳定编号；0 = 该格没有子
+type PieceID uint16
+
+type pieceIDs struct {
+	at   [BoardN]PieceID
+	next PieceID // 下一颗新子的编号
+}
+
+func isPiece(s CellState) bool { return s == PlayerA || s == PlayerB }
+
+// resetPieceIDs 按下标顺序给盘上的子重新编号；开局和载入局面时调用
+func (b *Board) resetPieceIDs() {
+	b.ids = pieceIDs{next: 1}
+	for i := 0; i < BoardN; i++ {
+		if isPiece(b.Cells[i]) {
+			b.ids.at[i] = b.ids.next
+			b.ids.next++
+		}
+	}
+}
+
+// syncPieceID 格子 i 经走子以外的途径（终局判地）改动后调用：新出现的子给新号，空出来的格清掉
+func (b *Board) syncPieceID(i int) {
+	switch {
+	case !isPiece(b.Cells[i]):
+		b.ids.at[i] = 0
+	case b.ids.at[i] == 0:
+		b.ids.at[i] = b.ids.next
+		b.ids.next++
+	}
+}
+
+// movePieceID 走子落盘时调用：跳跃把号搬到 to，克隆给 to 发新号；返回 to 上原来的号供悔棋
+func (b *Board) movePieceID(from, to int, jump bool) PieceID {
+	prev := b.ids.at[to]
+	if jump {
+		b.ids.at[to], b.ids.at[from] = b.ids.at[from], 0
+	} else {
+		b.ids.at[to] = b.ids.next
+		b.ids.next++
+	}
+	return prev
+}
+
+// unmovePieceID movePieceID 的逆操作
+func (b *Board) unmovePieceID(from, to int, jump bool, prev PieceID) {
+	if jump {
+		b.ids.at[from] = b.ids.at[to]
+	} else {
+		b.ids.next--
+	}
+	b.ids.at[to] = prev
+}
+
+// PieceAt c 上那颗子的编号；没有子或不在盘上时为 0
+func (b *Board) PieceAt(c HexCoord) PieceID {
+	if i, ok := IndexOf[c]; ok {
+		return b.ids.at[i]
+	}
+	return 0
+}
+
+// FindPiece 编号为 id 的子现在在哪；id 为 0 或盘上没有这颗子时 ok=false
+func (b *Board) FindPiece(id PieceID) (HexCoord, bool) {
+	if id == 0 {
+		return HexCoord{}, false
+	}
+	for i, x := range b.ids.at {
+		if x == id {
+			return CoordOf[i], true
+		}
+	}
+	return HexCoord{}, false
+}
+
+// PieceEventKind 棋子事件的种类
+type PieceEventKind uint8
+
+const (
+	PieceSpawn PieceEventKind = iota // 克隆：Piece 是新子，落在 To；Parent 是 From 上的母子
+	PieceJump                        // 跳跃：Piece 从 From 移到 To
+	PieceFlip                        // 感染：To 上的 Piece 被 From 上的 Parent 翻成 Owner 的颜色
+)
+
+// PieceEvent 一手棋里某颗子发生的一件事，带编号，界面按编号放动画、复盘按编号追谱系
+type PieceEvent struct {
+	Kind     PieceEventKind
+	Piece    PieceID
+	Parent   PieceID // Spawn：母子；Flip：感染它的子；Jump：0
+	From, To HexCoord
+	Owner    CellState // 事件之后这颗子的颜色
+	Wave     int       // Flip 的波次，见 Infection
+}
+
+// MoveEvents player 走 mv 会产生的事件（不改盘）：先是落子（Spawn 或 Jump），再按结算顺序的各次感染。
+// 坐标不在盘上时返回 nil
+func MoveEvents(b *Board, player CellState, mv Move) []PieceEvent {
+	from, okF := IndexOf[mv.From]
+	_, okT := IndexOf[mv.To]
+	if !okF || !okT {
+		return nil
+	}
+	moving := b.ids.at[from]
+	ev := PieceEvent{Kind: PieceJump, Piece: moving, From: mv.From, To: mv.To, Owner: player}
+	if !mv.IsJump() {
+		ev.Kind, ev.Piece, ev.Parent = PieceSpawn, b.ids.next, moving
+	}
+	out := []PieceEvent{ev}
+	for _, inf := range InfectionChain(b, player, mv) {
+		parent := b.ids.at[IndexOf[inf.From]]
+		if inf.From == mv.To {
+			parent = ev.Piece
+		}
+		out = append(out, PieceEvent{
+			Kind: PieceFlip, Piece: b.PieceAt(inf.To), Parent: parent,
+			From: inf.From, To: inf.To, Owner: player, Wave: inf.Wave,
+		})
+	}
+	return out
+}
+
+// PieceTree 克隆谱系：子 → 母；开局就在盘上的子不在表里
+type PieceTree map[PieceID]PieceID
+
+// Record 记下一手棋里的克隆
+func (t PieceTree) Record(events []PieceEvent) {
+	for _, ev := range events {
+		if ev.Kind == PieceSpawn && ev.Parent != 0 {
+			t[ev.Piece] = ev.Parent
+		}
+	}
+}
+
+// Ancestors id 自己、母子、母子的母子……直到开局就在盘上的那颗
+func (t PieceTree) Ancestors(id PieceID) []PieceID {
+	out := []PieceID{id}
+	for p, ok := t[id]; ok && len(out) <= len(t); p, ok = t[p] {
+		out = append(out, p)
+	}
+	return out
+}
+
+// DescendsFrom id 是否由 root 一路克隆而来（id == root 也算）
+func (t PieceTree) DescendsFrom(id, root PieceID) bool {
+	for _, a := range t.Ancestors(id) {
+		if a == root {
+			return true
+		}
+	}
+	return false
+}
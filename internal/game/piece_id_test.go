@@ -0,0 +1,104 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// 克隆发新号、跳跃号跟着走、被感染只翻色不换号；事件流与实际落盘一致
+func TestPieceIDsFollowPieces(t *testing.T) {
+	b := seeBoard(t, []HexCoord{{Q: 0, R: 0}}, []HexCoord{{Q: 2, R: 0}, {Q: -4, R: 4}})
+	root, victim := b.PieceAt(HexCoord{Q: 0, R: 0}), b.PieceAt(HexCoord{Q: 2, R: 0})
+	if root == 0 || victim == 0 || root == victim || b.PieceAt(HexCoord{Q: 1, R: 0}) != 0 || b.ids.next != 4 {
+		t.Fatalf("开局三颗子应编 1..3: %+v", b.ids)
+	}
+
+	clone := Move{From: HexCoord{Q: 0, R: 0}, To: HexCoord{Q: 1, R: 0}}
+	evs := MoveEvents(b, PlayerA, clone)
+	want := []PieceEvent{
+		{Kind: PieceSpawn, Piece: 4, Parent: root, From: clone.From, To: clone.To, Owner: PlayerA},
+		{Kind: PieceFlip, Piece: victim, Parent: 4, From: clone.To, To: HexCoord{Q: 2, R: 0}, Owner: PlayerA},
+	}
+	if len(evs) != len(want) || evs[0] != want[0] || evs[1] != want[1] {
+		t.Fatalf("事件流不对: %+v", evs)
+	}
+	u := b.makeMove(clone, PlayerA)
+	if b.PieceAt(clone.To) != 4 || b.PieceAt(clone.From) != root || b.PieceAt(HexCoord{Q: 2, R: 0}) != victim {
+		t.Fatal("克隆后编号不对")
+	}
+
+	jump := Move{From: HexCoord{Q: 2, R: 0}, To: HexCoord{Q: 4, R: -1}}
+	if evs := MoveEvents(b, PlayerA, jump); evs[0].Kind != PieceJump || evs[0].Piece != victim {
+		t.Fatalf("跳跃事件应是被感染的那颗子: %+v", evs)
+	}
+	u2 := b.makeMove(jump, PlayerA)
+	if c, ok := b.FindPiece(victim); !ok || c != jump.To || b.PieceAt(jump.From) != 0 {
+		t.Fatalf("跳跃后编号应跟到落点: %v %v", c, ok)
+	}
+
+	b.UnmakeMove(u2)
+	b.UnmakeMove(u)
+	if b.PieceAt(HexCoord{Q: 2, R: 0}) != victim || b.PieceAt(clone.To) != 0 || b.ids.next != 4 {
+		t.Fatalf("悔棋后编号没还原: %+v", b.ids)
+	}
+}
+
+// 随机对局：编号唯一、有子的格才有号，走子/悔棋完全还原
+func TestPieceIDsPlayoutInvariant(t *testing.T) {
+	rng := rand.New(rand.NewSource(4255))
+	gs := NewGameState(boardRadius)
+	for ply := 0; ply < 120 && !gs.GameOver; ply++ {
+		b := gs.Board
+		seen := map[PieceID]bool{}
+		for i := 0; i < BoardN; i++ {
+			id := b.ids.at[i]
+			if (id != 0) != isPiece(b.Cells[i]) || seen[id] && id != 0 || id >= b.ids.next {
+				t.Fatalf("第 %d 手: 格 %d 编号 %d 不对", ply, i, id)
+			}
+			seen[id] = true
+		}
+		moves := GenerateMoves(b, gs.CurrentPlayer)
+		if len(moves) == 0 {
+			break
+		}
+		ids := b.ids
+		for _, mv := range moves {
+			b.UnmakeMove(b.makeMove(mv, gs.CurrentPlayer))
+			if b.ids != ids {
+				t.Fatalf("%v: 悔棋后编号没还原", mv)
+			}
+		}
+		mv := moves[rng.Intn(len(moves))]
+		evs := MoveEvents(b, gs.CurrentPlayer, mv)
+		if _, _, err := gs.MakeMove(mv); err != nil {
+			t.Fatal(err)
+		}
+		for _, ev := range evs {
+			if gs.Board.PieceAt(ev.To) != ev.Piece {
+				t.Fatalf("%v: 事件 %+v 与落盘结果不符", mv, ev)
+			}
+		}
+	}
+	// 编号不进局面串：载入后按下标重新编 1..n
+	back, err := ParsePosition(gs.PositionString())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, bb := back.GetScores()
+	if int(back.Board.ids.next) != a+bb+1 {
+		t.Fatalf("载入后应重新编号: next=%d，子数 %d", back.Board.ids.next, a+bb)
+	}
+}
+
+func TestPieceTree(t *testing.T) {
+	tree := PieceTree{}
+	tree.Record([]PieceEvent{{Kind: PieceSpawn, Piece: 7, Parent: 1}, {Kind: PieceFlip, Piece: 3, Parent: 7}})
+	tree.Record([]PieceEvent{{Kind: PieceSpawn, Piece: 8, Parent: 7}})
+	got := tree.Ancestors(8)
+	if len(got) != 3 || got[0] != 8 || got[1] != 7 || got[2] != 1 {
+		t.Fatalf("谱系 %v", got)
+	}
+	if !tree.DescendsFrom(8, 1) || tree.DescendsFrom(3, 1) || !tree.DescendsFrom(1, 1) {
+		t.Fatal("感染不算谱系，克隆才算")
+	}
+}
@@ -23,28 +23,6 @@ var policyEntropyHigh = 2.2 // 熵阈值（经验），高于它认为不确定
 var policyCoverHigh = 0.96  // 不确定时更高的覆盖率
 var policyTemp = 1.1        // softmax 温度（>1 更平，<1 更尖）
 
-// 9x9 平面 index （不引入 ml 包，避免 import cycle）
-func toIndex9(b *Board, c HexCoord) int {
-	grid := 2*b.radius + 1 // radius=4 -> grid=9
-	return (c.R+b.radius)*grid + (c.Q + b.radius)
-}
-
-// 计算即时感染数（不改盘）
-func instantInfect(b *Board, mv Move, side CellState) int {
-	cnt := 0
-	toIdx, ok := IndexOf[mv.To]
-	if !ok {
-		return 0 // 非法坐标
-	}
-	opp := Opponent(side)
-	for _, nb := range NeighI[toIdx] {
-		if b.Cells[nb] == opp {
-			cnt++
-		}
-	}
-	return cnt
-}
-
 func policyPruneRoot(b *Board, player CellState, moves []Move) []Move {
 	if !policyPruneEnabled || len(moves) <= policyMinKeep {
 		return moves
@@ -63,8 +41,9 @@ type rec struct {
 recs := make([]rec, 0, len(moves))
 
 	// 先收集每个合法走法的概率与“即时感染数”
-	for _, m := range moves {
-		idx := toIndex9(b, m.To)
+	for _, mi := range DescribeMoves(b, player, moves) {
+		m := mi.Move
+		idx := AxialToIndex(m.To)
 		p := 0.0
 		if idx >= 0 && idx < len(logits) {
 			p = float64(logits[idx])
@@ -72,7 +51,7 @@ recs := make([]rec, 0, len(moves))
 		recs = append(recs, rec{
 			mv:    m,
 			p:     p,
-			inf:   instantInfect(b, m, player),
+			inf:   mi.Captures,
 		})
 	}
 	// 归一化（保险起见）
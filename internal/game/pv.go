@@ -0,0 +1,29 @@
+// game/pv.go
+package game
+
+// PrincipalVariation 从 first 起展开一条主变：之后双方轮流取 ScoreRootMoves 得分最高的应着，
+// 剩余深度每手减一，到 0 层、有一方无着可走或终局为止。α-β 本身不记主变，这里按需补一条给人看，
+// 每手都要再搜一次，只在需要时调用（对战工具的分歧日志、引擎协议的 go ... pv 1）
+func PrincipalVariation(b *Board, player CellState, first Move, depth int, allowJump bool) []Move {
+	pv := []Move{first}
+	work := b.Clone()
+	defer releaseBoard(work)
+	work.ApplyMove(first, player)
+	side := Opponent(player)
+	for d := depth - 1; d > 0; d-- {
+		scores := ScoreRootMoves(work, side, d, allowJump)
+		if len(scores) == 0 {
+			break
+		}
+		best := scores[0]
+		for _, s := range scores[1:] {
+			if s.Score > best.Score {
+				best = s
+			}
+		}
+		pv = append(pv, best.Move)
+		work.ApplyMove(best.Move, side)
+		side = Opponent(side)
+	}
+	return pv
+}
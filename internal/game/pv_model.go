@@ -0,0 +1,145 @@
+// internal/game/pv_model.go
+package game
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	ort "github.com/yalue/onnxruntime_go"
+)
+
+// PVModel 一个独立会话的 policy/value 网络（输入输出与 ensureONNX 的全局模型相同）。
+// 全局模型是单例，league 自博弈需要在同一进程里同时跑多个历史快照，就各自开一个 PVModel。
+type PVModel struct {
+	Path string
+
+	mu   sync.Mutex // 会话绑定了固定张量，Run 与读写张量都要串行
+	sess *ort.AdvancedSession
+	in   *ort.Tensor[float32]
+	outP *ort.Tensor[float32]
+	outV *ort.Tensor[float32]
+}
+
+// LoadPVModel 从 .onnx 或 .onnx.gz 加载模型。快照只做对手，固定走 CPU，不和当前模型抢 GPU。
+func LoadPVModel(path string) (*PVModel, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		gr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		data, err = io.ReadAll(gr)
+		gr.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	if !ort.IsInitialized() {
+		libPath, err := prepareORTSharedLib()
+		if err != nil {
+			return nil, fmt.Errorf("prepare ORT lib: %w", err)
+		}
+		ort.SetSharedLibraryPath(libPath)
+		if err := ort.InitializeEnvironment(); err != nil {
+			return nil, fmt.Errorf("InitializeEnvironment: %w", err)
+		}
+	}
+
+	if _, err := inspectModel("snapshot", path, data, cnnModelSpec); err != nil {
+		return nil, err
+	}
+
+	setNNProvider(nnSnapshot, "CPU")
+	m := &PVModel{Path: path}
+	if m.in, err = ort.NewTensor(ort.NewShape(1, featPlanes, grid, grid), make([]float32, featPlanes*grid*grid)); err != nil {
+		return nil, err
+	}
+	if m.outP, err = ort.NewEmptyTensor[float32](ort.NewShape(1, policyOutDim)); err != nil {
+		m.Close()
+		return nil, err
+	}
+	if m.outV, err = ort.NewEmptyTensor[float32](ort.NewShape(1, 1)); err != nil {
+		m.Close()
+		return nil, err
+	}
+	so, err := ort.NewSessionOptions()
+	if err != nil {
+		m.Close()
+		return nil, err
+	}
+	defer so.Destroy()
+	_ = so.SetLogSeverityLevel(3)
+	if err := ApplyORTConfig(so); err != nil {
+		m.Close()
+		return nil, err
+	}
+
+	m.sess, err = ort.NewAdvancedSessionWithONNXData(data,
+		[]string{onnxInputName}, []string{onnxPolicyName, onnxValueName},
+		[]ort.Value{m.in}, []ort.Value{m.outP, m.outV}, so)
+	if err != nil {
+		m.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// PolicyValue 与 PolicyValueNN 相同：81 维 softmax 策略 + 执子方胜率
+func (m *PVModel) PolicyValue(b *Board, me CellState) ([]float32, float32, error) {
+	if !FitsNN(b) {
+		return nil, 0, ErrBoardTooLargeForNN
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	encodeBoard(b, me, m.in.GetData())
+	t0 := time.Now()
+	if err := m.sess.Run(); err != nil {
+		return nil, 0, err
+	}
+	countNNRun(nnSnapshot, 1, 1, t0)
+
+	logits := m.outP.GetData()
+	policy := make([]float32, policyOutDim)
+	sum := 0.0
+	for i, l := range logits[:policyOutDim] {
+		e := math.Exp(float64(l))
+		policy[i] = float32(e)
+		sum += e
+	}
+	for i := range policy {
+		if sum > 0 {
+			policy[i] = float32(float64(policy[i]) / sum)
+		} else {
+			policy[i] = 1.0 / policyOutDim
+		}
+	}
+	v := float32(1.0 / (1.0 + math.Exp(float64(-m.outV.GetData()[0]))))
+	return policy, v, nil
+}
+
+// Close 释放会话与张量
+func (m *PVModel) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sess != nil {
+		m.sess.Destroy()
+		m.sess = nil
+	}
+	for _, t := range []*ort.Tensor[float32]{m.in, m.outP, m.outV} {
+		if t != nil {
+			t.Destroy()
+		}
+	}
+	m.in, m.outP, m.outV = nil, nil, nil
+}
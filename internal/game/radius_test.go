@@ -0,0 +1,99 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+// 半径 1..6 都能开局、走子、评估、编码往返；超出 MaxBoardRadius 要报错而不是静默越界
+func TestBoardRadii(t *testing.T) {
+	for r := 1; r <= MaxBoardRadius; r++ {
+		gs := NewGameState(r)
+		if got := ActiveRadius(gs.Board); got != r {
+			t.Fatalf("半径 %d: ActiveRadius = %d", r, got)
+		}
+		if n := len(AllCoords(r)); n != 1+3*r*(r+1) {
+			t.Fatalf("半径 %d: AllCoords 有 %d 格", r, n)
+		}
+		if a, b := gs.GetScores(); a != 3 || b != 3 {
+			t.Fatalf("半径 %d: 开局子数 %d-%d", r, a, b)
+		}
+		for ply := 0; ply < 30 && !gs.GameOver; ply++ {
+			moves := GenerateMoves(gs.Board, gs.CurrentPlayer)
+			if len(moves) == 0 {
+				break
+			}
+			for _, mv := range moves {
+				if ringOf(mv.To) > r {
+					t.Fatalf("半径 %d: 走法 %v 落到盘外", r, mv)
+				}
+			}
+			_ = EvaluateStatic(gs.Board, gs.CurrentPlayer)
+			mv, ok := FindBestMoveAtDepth(gs.Board, gs.CurrentPlayer, 1, true)
+			if !ok {
+				break
+			}
+			if _, _, err := gs.MakeMove(mv); err != nil {
+				t.Fatalf("半径 %d 第 %d 手: %v", r, ply, err)
+			}
+		}
+		back, err := ParsePosition(gs.PositionString())
+		if err != nil || back.Board.Hash() != gs.Board.Hash() || ActiveRadius(back.Board) != r {
+			t.Fatalf("半径 %d: 编码往返失败: %v", r, err)
+		}
+		gs.Reset()
+		if ActiveRadius(gs.Board) != r {
+			t.Fatalf("半径 %d: Reset 后半径变成 %d", r, ActiveRadius(gs.Board))
+		}
+	}
+	for _, r := range []int{0, MaxBoardRadius + 1} {
+		if err := CheckBoardRadius(r); err == nil {
+			t.Fatalf("半径 %d 应报错", r)
+		}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("NewBoard(7) 应 panic")
+		}
+	}()
+	NewBoard(MaxBoardRadius + 1)
+}
+
+// 半径 5、6：第 6 圈上能走子、吃子（格子下标跨过位板的第一个字），编码用 v2，网络输入装不下
+func TestLargeBoardRadii(t *testing.T) {
+	gs := NewGameState(MaxBoardRadius)
+	corner := HexCoord{Q: MaxBoardRadius, R: 0}
+	if IndexOf[corner] < 64 || gs.Board.Cells[IndexOf[corner]] != PlayerA {
+		t.Fatalf("半径 6 的角 %v 应是 A 的起始子，下标 %d", corner, IndexOf[corner])
+	}
+	gs.Board.setI(IndexOf[HexCoord{Q: MaxBoardRadius - 1, R: 1}], PlayerB)
+	to := HexCoord{Q: MaxBoardRadius - 1, R: 0}
+	if _, _, err := gs.MakeMove(Move{From: corner, To: to}); err != nil {
+		t.Fatal(err)
+	}
+	if gs.Board.Cells[IndexOf[HexCoord{Q: MaxBoardRadius - 1, R: 1}]] != PlayerA {
+		t.Fatal("第 6 圈旁的 B 子应被感染")
+	}
+	checkReach(t, gs.Board, "半径 6")
+
+	for r, size := range map[int]int{boardRadius: 18, 5: 26, 6: 35} {
+		data, _ := NewGameState(r).MarshalBinary()
+		if len(data) != size {
+			t.Fatalf("半径 %d 编码 %d 字节，期望 %d", r, len(data), size)
+		}
+	}
+
+	cells := make([]CellState, CellCount(5))
+	cells[IndexOf[HexCoord{Q: 5, R: 0}]] = PlayerA
+	b, err := BoardFromCells(cells)
+	if err != nil || ActiveRadius(b) != 5 || b.Cells[IndexOf[HexCoord{Q: 6, R: 0}]] != Blocked {
+		t.Fatalf("91 格的布局应是半径 5 的盘: %v", err)
+	}
+	if FitsNN(b) || !FitsNN(NewBoard(boardRadius)) {
+		t.Fatal("只有半径 4 以内的盘能喂给网络")
+	}
+	e, _ := Encoder(FeaturesGrid3)
+	if err := e.Encode(b, PlayerA, make([]float32, e.SpatialLen()), nil, -1); !errors.Is(err, ErrBoardTooLargeForNN) {
+		t.Fatalf("半径 5 编码应报 ErrBoardTooLargeForNN，得到 %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+// internal/game/reach.go
+package game
+
+// 每方“可落子空位”的位掩码随落子增量维护：对每个格子记下距离 ≤2 内各方的棋子数，
+// 计数从 0 变 1 / 从 1 变 0 时翻转对应位。取掩码时再与空位求交即可，
+// 机动性、被堵死判定、对手能否够到某格都不必再生成着法。
+
+// reachState 挂在 Board 上随格子变化更新；拷贝棋盘时要一并拷贝
+type reachState struct {
+	cnt     [2][BoardN]uint8 // [A/B][格子]：距离 1~2 内该方棋子数
+	mask    [2]CellMask      // cnt > 0 的格子
+	blocked CellMask         // 障碍格（含盘外的格子）
+}
+
+func reachSlot(s CellState) int {
+	switch s {
+	case PlayerA:
+		return 0
+	case PlayerB:
+		return 1
+	}
+	return -1
+}
+
+// trackCell 格子 i 从 prev 变成 s 后调用（所有改 Cells 的地方都要经过这里）
+func (b *Board) trackCell(i int, prev, s CellState) {
+	b.eval.update(i, prev, s)
+	rs := &b.reach
+	if prev == Blocked {
+		rs.blocked.clear(i)
+	}
+	if s == Blocked {
+		rs.blocked.set(i)
+	}
+	if k := reachSlot(prev); k >= 0 {
+		for _, j := range NeighI[i] {
+			if rs.cnt[k][j]--; rs.cnt[k][j] == 0 {
+				rs.mask[k].clear(j)
+			}
+		}
+		for _, j := range JumpI[i] {
+			if rs.cnt[k][j]--; rs.cnt[k][j] == 0 {
+				rs.mask[k].clear(j)
+			}
+		}
+	}
+	if k := reachSlot(s); k >= 0 {
+		for _, j := range NeighI[i] {
+			rs.cnt[k][j]++
+			rs.mask[k].set(j)
+		}
+		for _, j := range JumpI[i] {
+			rs.cnt[k][j]++
+			rs.mask[k].set(j)
+		}
+	}
+}
+
+// EmptyMask 空位掩码
+func (b *Board) EmptyMask() CellMask {
+	return boardMask.AndNot(b.bitA.Or(b.bitB).Or(b.reach.blocked))
+}
+
+// ReachableMask side 下一手（克隆或跳跃）能落到的空位
+func (b *Board) ReachableMask(side CellState) CellMask {
+	k := reachSlot(side)
+	if k < 0 {
+		return CellMask{}
+	}
+	if rules.CloneLimit > 0 {
+		return b.limitedReach(side).And(b.EmptyMask())
+	}
+	return b.reach.mask[k].And(b.EmptyMask())
+}
+
+// Mobility side 可落子的空位数（不同起点到同一落点只算一次）
+func (b *Board) Mobility(side CellState) int {
+	return b.ReachableMask(side).Count()
+}
+
+// HasMoves side 是否还有合法着法
+func (b *Board) HasMoves(side CellState) bool {
+	return !b.ReachableMask(side).IsZero()
+}
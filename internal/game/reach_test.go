@@ -0,0 +1,81 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// reachByGen 用 GenerateMoves 逐个求可落点，作为增量掩码的对照
+func reachByGen(b *Board, side CellState) CellMask {
+	var m CellMask
+	for _, mv := range GenerateMoves(b, side) {
+		m.set(IndexOf[mv.To])
+	}
+	return m
+}
+
+func checkReach(t *testing.T, b *Board, where string) {
+	t.Helper()
+	for _, side := range []CellState{PlayerA, PlayerB} {
+		if got, want := b.ReachableMask(side), reachByGen(b, side); got != want {
+			t.Fatalf("%s: %v 可落点掩码 %x，应为 %x", where, side, got, want)
+		}
+	}
+}
+
+func TestReachMasksIncremental(t *testing.T) {
+	r := rand.New(rand.NewSource(4218))
+	for g := 0; g < 20; g++ {
+		gs := NewGameState(boardRadius)
+		b := gs.Board
+		checkReach(t, b, "开局")
+		side := PlayerA
+		var undos []undoInfo
+		for ply := 0; ply < 40; ply++ {
+			moves := GenerateMoves(b, side)
+			if len(moves) == 0 {
+				if b.HasMoves(side) {
+					t.Fatalf("无着法时 HasMoves 应为 false")
+				}
+				break
+			}
+			mv := moves[r.Intn(len(moves))]
+			if ply%5 == 4 {
+				// 另一条改盘路径：ApplyMoveWithUndo 走一步再撤回
+				_, undo := b.ApplyMoveWithUndo(mv, side)
+				checkReach(t, b, "ApplyMoveWithUndo 后")
+				undo()
+				checkReach(t, b, "ApplyMoveWithUndo 撤回后")
+			}
+			undos = append(undos, mMakeMoveWithUndo(b, mv, side))
+			checkReach(t, b, "落子后")
+			side = Opponent(side)
+		}
+		for i := len(undos) - 1; i >= 0; i-- {
+			b.UnmakeMove(undos[i])
+			checkReach(t, b, "回退后")
+		}
+
+		// 拷贝与编解码路径
+		checkReach(t, b.Clone(), "Clone")
+		data, err := gs.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back GameState
+		if err := back.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		checkReach(t, back.Board, "UnmarshalBinary")
+	}
+}
+
+func TestMobility(t *testing.T) {
+	b := seeBoard(t, []HexCoord{{Q: 0, R: 0}}, nil)
+	if n := b.Mobility(PlayerA); n != 18 {
+		t.Fatalf("中心孤子应有 6+12 个落点，得到 %d", n)
+	}
+	if b.HasMoves(PlayerB) || b.Mobility(PlayerB) != 0 {
+		t.Fatalf("无子一方不应有着法")
+	}
+}
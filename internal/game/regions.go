@@ -0,0 +1,85 @@
+// internal/game/regions.go
+package game
+
+// 空格连通区域分析（六邻接，障碍格与棋子都隔断区域）。
+// 终局时不连最外圈、只与一方棋子相邻的区域整块判给那一方（fillEnclosedRegions）；
+// 静态评估在残局把两类区域算作某方已锁定的地盘：按上面的规则已被它单独封住的，
+// 以及挨着它的棋子、对手一步够不到的。
+
+// Region 一块连通的空格
+type Region struct {
+	Mask    CellMask // 区域内的格子（按下标置位）
+	Outer   bool     // 含最外圈的格子
+	BorderA bool     // 与 A 的棋子相邻
+	BorderB bool     // 与 B 的棋子相邻
+}
+
+// Size 区域格数
+func (r Region) Size() int { return r.Mask.Count() }
+
+// Cells 区域内的格子下标，从小到大
+func (r Region) Cells() []int { return r.Mask.Cells() }
+
+// Owner 终局填充规则下这块区域的归属：不连最外圈且只与一方相邻时为该方，否则 Empty
+func (r Region) Owner() CellState {
+	if r.Outer || r.BorderA == r.BorderB {
+		return Empty
+	}
+	if r.BorderA {
+		return PlayerA
+	}
+	return PlayerB
+}
+
+// EmptyRegions 把全部空格分成连通区域，按区域内最小下标排序
+func EmptyRegions(b *Board) []Region {
+	ensurePrecomp()
+	var out []Region
+	empty, edge := b.EmptyMask(), b.edgeMask()
+	for remain := empty; !remain.IsZero(); {
+		comp := floodComponent(cellBit(remain.first()), empty)
+		remain = remain.AndNot(comp)
+		nb := neighbourMask(comp)
+		out = append(out, Region{
+			Mask:    comp,
+			Outer:   !comp.And(edge).IsZero(),
+			BorderA: !nb.And(b.bitA).IsZero(),
+			BorderB: !nb.And(b.bitB).IsZero(),
+		})
+	}
+	return out
+}
+
+// neighbourMask mask 中各格的邻格并集
+func neighbourMask(mask CellMask) CellMask {
+	var nb CellMask
+	for m := mask; !m.IsZero(); {
+		nb = nb.Or(bbCache.neighMask[m.pop()])
+	}
+	return nb
+}
+
+// SecuredTerritory side 已锁定的空格数减去对方的。
+// 一块区域算 side 锁定：不连最外圈且只与 side 相邻（终局判给 side，哪怕对方还能跳进去），
+// 或者挨着 side 的棋子且对方下一手（克隆或跳跃）落不进去。
+func SecuredTerritory(b *Board, side CellState) int {
+	ensurePrecomp()
+	my, op := boardMasks(b, side)
+	myReach, opReach := b.ReachableMask(side), b.ReachableMask(Opponent(side))
+	empty, edge := b.EmptyMask(), b.edgeMask()
+	n := 0
+	for remain := empty; !remain.IsZero(); {
+		comp := floodComponent(cellBit(remain.first()), empty)
+		remain = remain.AndNot(comp)
+		nb := neighbourMask(comp)
+		inner := comp.And(edge).IsZero()
+		touchMy, touchOp := !nb.And(my).IsZero(), !nb.And(op).IsZero()
+		switch {
+		case touchMy && (comp.And(opReach).IsZero() || inner && !touchOp):
+			n += comp.Count()
+		case touchOp && (comp.And(myReach).IsZero() || inner && !touchMy):
+			n -= comp.Count()
+		}
+	}
+	return n
+}
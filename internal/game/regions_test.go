@@ -0,0 +1,173 @@
+package game
+
+import (
+	"testing"
+)
+
+// 中心空格被 A 六子围住，B 只在角上一子
+func enclosedCenterBoard(t *testing.T) *Board {
+	t.Helper()
+	cells := make([]CellState, CellCount(boardRadius))
+	for _, d := range Directions {
+		cells[IndexOf[d]] = PlayerA
+	}
+	cells[IndexOf[HexCoord{Q: -4, R: 4}]] = PlayerB
+	b, err := BoardFromCells(cells)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestEmptyRegions(t *testing.T) {
+	b := enclosedCenterBoard(t)
+	regions := EmptyRegions(b)
+	if len(regions) != 2 {
+		t.Fatalf("应分成 2 块，得到 %d", len(regions))
+	}
+	total := 0
+	var center *Region
+	for i := range regions {
+		r := &regions[i]
+		total += r.Size()
+		if r.Mask.Has(IndexOf[HexCoord{}]) {
+			center = r
+		}
+	}
+	if total != b.EmptyMask().Count() {
+		t.Fatalf("区域格数之和 %d 与空格数不符", total)
+	}
+	if center == nil || center.Size() != 1 || center.Outer || !center.BorderA || center.BorderB {
+		t.Fatalf("中心区域不对: %+v", center)
+	}
+	if center.Owner() != PlayerA {
+		t.Fatalf("中心区域应判给 A，得到 %v", center.Owner())
+	}
+	for _, r := range regions {
+		if r.Size() > 1 && (!r.Outer || r.Owner() != Empty) {
+			t.Fatalf("外圈大区域不应有归属: %+v", r)
+		}
+	}
+}
+
+func TestSecuredTerritory(t *testing.T) {
+	b := enclosedCenterBoard(t)
+	if got := SecuredTerritory(b, PlayerA); got != 1 {
+		t.Fatalf("A 应锁定 1 格，得到 %d", got)
+	}
+	if got := SecuredTerritory(b, PlayerB); got != -1 {
+		t.Fatalf("B 视角应为 -1，得到 %d", got)
+	}
+	// B 能跳进中心，但中心已被 A 单独封住，仍算 A 的
+	cells := b.Cells
+	cells[IndexOf[HexCoord{Q: 2, R: 0}]] = PlayerB
+	jb, err := BoardFromCells(cells[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !jb.ReachableMask(PlayerB).Has(IndexOf[HexCoord{}]) {
+		t.Fatal("B 应能跳到中心")
+	}
+	if got := SecuredTerritory(jb, PlayerA); got != 1 {
+		t.Fatalf("封闭区域应算 A 锁定，得到 %d", got)
+	}
+	// 开局没有封闭区域
+	if got := SecuredTerritory(NewGameState(boardRadius).Board, PlayerA); got != 0 {
+		t.Fatalf("开局应为 0，得到 %d", got)
+	}
+}
+
+// 评估里的地盘项只在空格不多于 securedMaxEmpties 时计算
+func TestSecuredScoreEndgameOnly(t *testing.T) {
+	b := enclosedCenterBoard(t)
+	if SecuredTerritory(b, PlayerA) == 0 || securedScore(b, PlayerA) != 0 {
+		t.Fatalf("空格多时不应计入评估: secured=%d score=%d", SecuredTerritory(b, PlayerA), securedScore(b, PlayerA))
+	}
+	// 除中心与最外圈的 5 格外全填 B：A 封住中心，B 锁住外圈那几格
+	cells := b.Cells
+	outer := 0
+	for i, c := range AllCoords(boardRadius) {
+		if cells[i] != Empty || c == (HexCoord{}) {
+			continue
+		}
+		if ringOf(c) == boardRadius && outer < 5 {
+			outer++
+			continue
+		}
+		cells[i] = PlayerB
+	}
+	eb, err := BoardFromCells(cells[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := eb.EmptyMask().Count(); n > securedMaxEmpties {
+		t.Fatalf("残局局面有 %d 个空格", n)
+	}
+	want := SecuredTerritory(eb, PlayerA) * securedW
+	if want == 0 || securedScore(eb, PlayerA) != want {
+		t.Fatalf("残局应计入地盘项: score=%d want=%d", securedScore(eb, PlayerA), want)
+	}
+}
+
+func TestFillEnclosedRegions(t *testing.T) {
+	gs := &GameState{Board: enclosedCenterBoard(t)}
+	empties := gs.Board.EmptyMask().Count()
+	gs.fillEnclosedRegions()
+	if s := gs.Board.Cells[IndexOf[HexCoord{}]]; s != PlayerA {
+		t.Fatalf("被 A 围住的中心应填成 A，得到 %v", s)
+	}
+	if got := gs.Board.EmptyMask().Count(); got != empties-1 {
+		t.Fatalf("只应填 1 格，空格 %d → %d", empties, got)
+	}
+	// 填充走 setI，hash 要与按格子重建的一致
+	rebuilt, err := BoardFromCells(gs.Board.Cells[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gs.Board.Hash() != rebuilt.Hash() {
+		t.Fatal("填充后 hash 未同步")
+	}
+}
+
+// 半径 3 的盘：第 4 圈是障碍，外圈是第 3 圈。角上 (3,0) 被 A 三子围住，但它在外圈上，不算封闭；
+// 中心被 A 六子围住，才是封闭区域
+func TestRegionsSmallRadius(t *testing.T) {
+	cells := NewBoard(3).Cells
+	for _, c := range append([]HexCoord{{Q: 3, R: -1}, {Q: 2, R: 0}, {Q: 2, R: 1}}, Directions...) {
+		cells[IndexOf[c]] = PlayerA
+	}
+	cells[IndexOf[HexCoord{Q: -3, R: 0}]] = PlayerB
+	b, err := BoardFromCells(cells[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := ActiveRadius(b); r != 3 {
+		t.Fatalf("ActiveRadius = %d，期望 3", r)
+	}
+	corner, center := IndexOf[HexCoord{Q: 3, R: 0}], IndexOf[HexCoord{}]
+	for _, r := range EmptyRegions(b) {
+		switch {
+		case r.Mask.Has(corner):
+			if r.Size() != 1 || !r.Outer || r.Owner() != Empty {
+				t.Fatalf("外圈角上的空格不应算封闭: %+v", r)
+			}
+		case r.Mask.Has(center):
+			if r.Size() != 1 || r.Outer || r.Owner() != PlayerA {
+				t.Fatalf("中心区域不对: %+v", r)
+			}
+		default:
+			if !r.Outer {
+				t.Fatalf("其余空格连着外圈: %+v", r)
+			}
+		}
+	}
+
+	gs := &GameState{Board: b}
+	gs.fillEnclosedRegions()
+	if s := gs.Board.Cells[corner]; s != Empty {
+		t.Fatalf("外圈角上的空格不应被填，得到 %v", s)
+	}
+	if s := gs.Board.Cells[center]; s != PlayerA {
+		t.Fatalf("中心应填成 A，得到 %v", s)
+	}
+}
@@ -0,0 +1,189 @@
+// internal/game/remote_eval.go
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 远程推理：cmd/nnserver 在一个进程里加载模型、建一份 TensorRT 引擎，同机的多个自博弈进程用 RemoteEvaluator
+// 通过 HTTP 调它，不必每个进程各建一份引擎、各占一份显存。
+//
+// 接口（请求和响应都是 JSON；局面用 Board.MarshalBinary 的 17 字节，JSON 里是 base64）：
+//
+//	POST /v1/policy_value  NNEvalRequest → NNPolicyValueResponse  全局 3 平面 CNN（PolicyValueNN），即 MCTS 的先验与估值
+//	GET  /v1/info          NNServerInfo
+//
+// 编码只含格子，LastMove、脉号等不传——网络的输入本来也只看格子。请求体至多 maxNNRequestBytes，超了回 413。
+// 只服务 MCTS（MCTSOptions.Model）：α-β 叶子的 KataGo 估值仍在本进程里跑，selfplay 的 -policy ab 不接受 -nn_server。
+
+// PolicyValueModel MCTS 取先验与叶子估值的网络：81 维 softmax 策略 + me 的胜率 [0,1]
+type PolicyValueModel interface {
+	PolicyValue(b *Board, me CellState) ([]float32, float32, error)
+}
+
+// MaxRemoteBoards 单个请求最多的局面数
+const MaxRemoteBoards = 4096
+
+// maxNNRequestBytes 请求体上限：每个局面 17 字节，base64 加引号逗号不到 32 字节，另留些给外层字段
+const maxNNRequestBytes = MaxRemoteBoards*32 + 1024
+
+// NNEvalRequest 一批局面，统一从 me 的视角估值
+type NNEvalRequest struct {
+	Me     CellState `json:"me"`
+	Boards [][]byte  `json:"boards"`
+}
+
+type NNPolicyValueResponse struct {
+	Policy [][]float32 `json:"policy"`
+	Value  []float32   `json:"value"`
+}
+
+// NNServerInfo /v1/info 的内容
+type NNServerInfo struct {
+	Uptime   time.Duration             `json:"uptime_ns"`
+	Sessions map[string]NNSessionStats `json:"sessions"`
+}
+
+// NNBackend 服务端实际跑推理的函数；LocalNNBackend 用本进程的全局模型，测试可换成假的
+type NNBackend struct {
+	PolicyValue func(b *Board, me CellState) ([]float32, float32, error)
+}
+
+func LocalNNBackend() NNBackend {
+	return NNBackend{PolicyValue: PolicyValueNN}
+}
+
+// NNHandler 把 be 挂成 HTTP 服务
+func NNHandler(be NNBackend) http.Handler {
+	start := time.Now()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/policy_value", func(w http.ResponseWriter, r *http.Request) {
+		boards, me, ok := readNNRequest(w, r)
+		if !ok {
+			return
+		}
+		resp := NNPolicyValueResponse{Policy: make([][]float32, len(boards)), Value: make([]float32, len(boards))}
+		for i, b := range boards {
+			p, v, err := be.PolicyValue(b, me)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			resp.Policy[i], resp.Value[i] = p, v
+		}
+		writeNNJSON(w, resp)
+	})
+	mux.HandleFunc("GET /v1/info", func(w http.ResponseWriter, r *http.Request) {
+		writeNNJSON(w, NNServerInfo{Uptime: time.Since(start), Sessions: GetNNStats()})
+	})
+	return mux
+}
+
+func readNNRequest(w http.ResponseWriter, r *http.Request) ([]*Board, CellState, bool) {
+	var req NNEvalRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxNNRequestBytes)).Decode(&req); err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+		return nil, 0, false
+	}
+	if req.Me != PlayerA && req.Me != PlayerB {
+		http.Error(w, fmt.Sprintf("me must be %d or %d", PlayerA, PlayerB), http.StatusBadRequest)
+		return nil, 0, false
+	}
+	if len(req.Boards) > MaxRemoteBoards {
+		http.Error(w, fmt.Sprintf("at most %d boards per request", MaxRemoteBoards), http.StatusBadRequest)
+		return nil, 0, false
+	}
+	boards := make([]*Board, len(req.Boards))
+	for i, data := range req.Boards {
+		boards[i] = &Board{}
+		if err := boards[i].UnmarshalBinary(data); err != nil {
+			http.Error(w, fmt.Sprintf("board %d: %v", i, err), http.StatusBadRequest)
+			return nil, 0, false
+		}
+	}
+	return boards, req.Me, true
+}
+
+func writeNNJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// RemoteEvaluator cmd/nnserver 的客户端，并发安全。PolicyValue 满足 PolicyValueModel，可直接放进 MCTSOptions.Model
+type RemoteEvaluator struct {
+	url    string
+	client *http.Client
+}
+
+// NewRemoteEvaluator url 形如 http://127.0.0.1:7791
+func NewRemoteEvaluator(url string) *RemoteEvaluator {
+	return &RemoteEvaluator{url: strings.TrimRight(url, "/"), client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+func (r *RemoteEvaluator) PolicyValue(b *Board, me CellState) ([]float32, float32, error) {
+	if !FitsNN(b) {
+		return nil, 0, ErrBoardTooLargeForNN
+	}
+	var resp NNPolicyValueResponse
+	if err := r.post("/v1/policy_value", []*Board{b}, me, &resp); err != nil {
+		return nil, 0, err
+	}
+	if len(resp.Policy) != 1 || len(resp.Value) != 1 {
+		return nil, 0, fmt.Errorf("nnserver: 1 board sent, %d/%d results", len(resp.Policy), len(resp.Value))
+	}
+	return resp.Policy[0], resp.Value[0], nil
+}
+
+// Info 取服务端状态；启动时调一次可以尽早发现地址写错
+func (r *RemoteEvaluator) Info() (NNServerInfo, error) {
+	var info NNServerInfo
+	resp, err := r.client.Get(r.url + "/v1/info")
+	if err != nil {
+		return info, err
+	}
+	defer resp.Body.Close()
+	if err := checkNNResponse(resp); err != nil {
+		return info, err
+	}
+	return info, json.NewDecoder(resp.Body).Decode(&info)
+}
+
+func (r *RemoteEvaluator) post(path string, boards []*Board, me CellState, out any) error {
+	req := NNEvalRequest{Me: me, Boards: make([][]byte, len(boards))}
+	for i, b := range boards {
+		req.Boards[i], _ = b.MarshalBinary()
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.Post(r.url+path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := checkNNResponse(resp); err != nil {
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func checkNNResponse(resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("nnserver: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+}
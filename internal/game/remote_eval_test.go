@@ -0,0 +1,84 @@
+package game
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeNNBackend 策略在第一个空格上放 1，胜率 = me 的子数占比
+func fakeNNBackend() NNBackend {
+	return NNBackend{
+		PolicyValue: func(b *Board, me CellState) ([]float32, float32, error) {
+			p := make([]float32, policyOutDim)
+			for i := 0; i < BoardN; i++ {
+				if b.Cells[i] == Empty {
+					p[boardIndexToGrid[i]] = 1
+					break
+				}
+			}
+			my, op := b.CountPieces(me), b.CountPieces(Opponent(me))
+			return p, float32(my) / float32(my+op), nil
+		},
+	}
+}
+
+func TestRemoteEvaluator(t *testing.T) {
+	be := fakeNNBackend()
+	srv := httptest.NewServer(NNHandler(be))
+	defer srv.Close()
+	re := NewRemoteEvaluator(srv.URL + "/")
+
+	st := NewGameState(boardRadius)
+	st.MakeMove(GenerateMoves(st.Board, st.CurrentPlayer)[0])
+	p, v, err := re.PolicyValue(st.Board, PlayerB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantP, wantV, _ := be.PolicyValue(st.Board, PlayerB)
+	if v != wantV || len(p) != len(wantP) {
+		t.Fatalf("PolicyValue = %v/%d，期望 %v/%d", v, len(p), wantV, len(wantP))
+	}
+	for i := range p {
+		if p[i] != wantP[i] {
+			t.Fatalf("policy[%d] = %v，期望 %v", i, p[i], wantP[i])
+		}
+	}
+
+	if _, _, err := re.PolicyValue(st.Board, Empty); err == nil || !strings.Contains(err.Error(), "400") {
+		t.Fatalf("me 非法应返回 400，得到 %v", err)
+	}
+	// 请求体超过 maxNNRequestBytes：不解码到底，直接 413
+	big := `{"me":1,"boards":["` + strings.Repeat("A", maxNNRequestBytes) + `"]}`
+	resp, err := http.Post(srv.URL+"/v1/policy_value", "application/json", strings.NewReader(big))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("超长请求返回 %s，期望 413", resp.Status)
+	}
+	if info, err := re.Info(); err != nil || info.Uptime <= 0 {
+		t.Fatalf("Info = %+v, %v", info, err)
+	}
+}
+
+// RemoteEvaluator 直接当 MCTSOptions.Model 用
+func TestRemoteEvaluatorAsMCTSModel(t *testing.T) {
+	srv := httptest.NewServer(NNHandler(fakeNNBackend()))
+	defer srv.Close()
+
+	st := NewGameState(boardRadius)
+	rv, ok := RunMCTSRoot(st.Board, PlayerA, 30, 0, true, MCTSOptions{Model: NewRemoteEvaluator(srv.URL)})
+	if !ok || len(rv) == 0 {
+		t.Fatal("远程模型下 MCTS 应能给出着法")
+	}
+	total := 0
+	for _, r := range rv {
+		total += r.Visits
+	}
+	if total == 0 {
+		t.Fatal("根下没有访问")
+	}
+}
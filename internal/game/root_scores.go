@@ -0,0 +1,32 @@
+// game/root_scores.go
+package game
+
+// RootScore 根着法与它的 α-β 得分（执子方视角，越大越好）
+type RootScore struct {
+	Move  Move
+	Score int
+}
+
+// ScoreRootMoves 对每个合法根着法各做一次全窗口 α-β 搜索，得分彼此可比，用来构造软策略目标。
+// 与 FindBestMoveAtDepth 相同的着法过滤和延伸（被过滤掉的着法没有分），但不查负面开局库、不做对称去重。
+// 单协程顺序搜，调用方（如 selfplay 的多个 worker）自己并行；没有合法着法时返回 nil
+func ScoreRootMoves(b *Board, player CellState, depth int, allowJump bool) []RootScore {
+	syncTTEvaluator()
+	moves := applyMoveFilters(b, player, GenerateMoves(b, player), allowJump)
+	if len(moves) == 0 {
+		return nil
+	}
+	out := make([]RootScore, len(moves))
+	local := b.Clone()
+	defer releaseBoard(local)
+	var nodes nodeCounter
+	for i, mv := range moves {
+		undo := mMakeMoveWithUndo(local, mv, player)
+		d, ext := childDepth(local, player, int64(depth), searchExtMax, allowJump)
+		score := hybridAlphaBeta(local, 0, Opponent(player), player, d, -1000000, 1000000, allowJump, &nodes, ext)
+		local.UnmakeMove(undo)
+		out[i] = RootScore{Move: mv, Score: score}
+	}
+	nodes.flush()
+	return out
+}
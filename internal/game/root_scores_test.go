@@ -0,0 +1,50 @@
+package game
+
+import "testing"
+
+// 过滤后剩下的每个根着法都有分且合法，搜完不改盘；同一局面两次打分一致（可比、可复现）
+func TestScoreRootMoves(t *testing.T) {
+	gs := NewGameState(boardRadius)
+	hash := gs.Board.Hash()
+	scores := ScoreRootMoves(gs.Board, PlayerA, 2, true)
+	legal := map[Move]bool{}
+	for _, mv := range GenerateMoves(gs.Board, PlayerA) {
+		legal[mv] = true
+	}
+	kept := applyMoveFilters(gs.Board, PlayerA, GenerateMoves(gs.Board, PlayerA), true)
+	if len(scores) == 0 || len(scores) != len(kept) {
+		t.Fatalf("%d 个着法有分，过滤后剩 %d 个", len(scores), len(kept))
+	}
+	for _, s := range scores {
+		if !legal[s.Move] {
+			t.Fatalf("打分的着法 %v 不合法", s.Move)
+		}
+	}
+	if gs.Board.Hash() != hash {
+		t.Fatal("打分改动了棋盘")
+	}
+	again := ScoreRootMoves(gs.Board, PlayerA, 2, true)
+	for i := range scores {
+		if scores[i] != again[i] {
+			t.Fatalf("着法 %v 两次得分 %d / %d", scores[i].Move, scores[i].Score, again[i].Score)
+		}
+	}
+}
+
+// 主变从给定着法起、双方轮流，每一手在当时的局面下都合法，长度不超过深度
+func TestPrincipalVariation(t *testing.T) {
+	gs := NewGameState(boardRadius)
+	first := GenerateMoves(gs.Board, PlayerA)[0]
+	pv := PrincipalVariation(gs.Board, PlayerA, first, 3, true)
+	if len(pv) == 0 || len(pv) > 3 || pv[0] != first {
+		t.Fatalf("主变 %v", pv)
+	}
+	for i, mv := range pv {
+		if err := ValidateMove(gs, mv); err != nil {
+			t.Fatalf("第 %d 手 %v: %v", i, mv, err)
+		}
+		if _, _, err := gs.MakeMove(mv); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// game/rules.go
+package game
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rules 可选的规则变体；零值就是原版规则。
+// 进程内全局生效（走子、预览、SEE、UI 动画都读它），对局或搜索进行中不要切换。
+type Rules struct {
+	// Cascade 连锁感染：新翻过来的子立刻感染它相邻的对方子，一层层展开直到没有可翻的。
+	// 结算顺序固定：按离落点的“波次”逐层进行，同一波内按格子下标从小到大。
+	Cascade bool
+
+	// CloneLimit 每颗原始棋子一脉（它克隆出的子、被这一脉感染的子）合计最多克隆几次，用完只能跳跃；
+	// 0 = 不限。脉号的维护见 lineage.go
+	CloneLimit int
+}
+
+var rules Rules
+
+// RuleNames 可选的规则名，供命令行帮助使用；多个变体用 + 连接，如 cascade+limited:2
+var RuleNames = []string{"standard", "cascade", "limited[:N]"}
+
+const (
+	DefaultCloneLimit = 3  // -rules limited 不写次数时的上限
+	MaxCloneLimit     = 99 // 脉里按 uint8 计数，留足余量
+)
+
+// SetRules 切换规则变体。置换表里旧规则下的分数随评估器身份一起失效（见 EvaluatorID）
+func SetRules(r Rules) { rules = r }
+
+// CurrentRules 当前生效的规则变体
+func CurrentRules() Rules { return rules }
+
+// IsStandard 是否原版规则（没有开启任何变体）
+func (r Rules) IsStandard() bool { return r == Rules{} }
+
+// String 与 ParseRules 互逆，不含空白（联机握手按空格切分）
+func (r Rules) String() string {
+	var parts []string
+	if r.Cascade {
+		parts = append(parts, "cascade")
+	}
+	if r.CloneLimit > 0 {
+		parts = append(parts, fmt.Sprintf("limited:%d", r.CloneLimit))
+	}
+	if len(parts) == 0 {
+		return "standard"
+	}
+	return strings.Join(parts, "+")
+}
+
+// ParseRules 解析 -rules 参数；空串视为 standard，多个变体用 + 连接
+func ParseRules(name string) (Rules, error) {
+	var r Rules
+	for _, part := range strings.Split(strings.ToLower(strings.TrimSpace(name)), "+") {
+		key, arg, hasArg := strings.Cut(strings.TrimSpace(part), ":")
+		switch {
+		case !hasArg && (key == "" || key == "standard" || key == "classic"):
+		case !hasArg && (key == "cascade" || key == "chain"):
+			r.Cascade = true
+		case key == "limited" || key == "limit":
+			r.CloneLimit = DefaultCloneLimit
+			if hasArg {
+				n, err := strconv.Atoi(arg)
+				if err != nil || n < 1 || n > MaxCloneLimit {
+					return Rules{}, fmt.Errorf("规则 %q: 克隆次数应为 1..%d", part, MaxCloneLimit)
+				}
+				r.CloneLimit = n
+			}
+		default:
+			return Rules{}, fmt.Errorf("未知规则 %q（可选 %v）", name, RuleNames)
+		}
+	}
+	return r, nil
+}
+
+// infectMask 落点 to 落下后会被感染的格子；opBit 为走子前对方的子
+func infectMask(to int, opBit CellMask) CellMask {
+	inf := NeighMask[to].And(opBit)
+	if !rules.Cascade {
+		return inf
+	}
+	for wave := inf; !wave.IsZero(); {
+		opBit = opBit.AndNot(wave)
+		var next CellMask
+		for m := wave; !m.IsZero(); {
+			next = next.Or(NeighMask[m.pop()])
+		}
+		wave = next.And(opBit)
+		inf = inf.Or(wave)
+	}
+	return inf
+}
+
+// forEachInfection 按结算顺序回调每一颗被感染的子：dst 被 src 感染，wave 为波次（0 = 被落点直接感染）。
+// src 取上一波里与 dst 相邻、下标最小的那颗。只读掩码，回调里改盘不影响遍历
+func forEachInfection(to int, opBit CellMask, fn func(src, dst, wave int)) {
+	prev := cellBit(to)
+	wave := NeighMask[to].And(opBit)
+	for w := 0; !wave.IsZero(); w++ {
+		opBit = opBit.AndNot(wave)
+		var next CellMask
+		for m := wave; !m.IsZero(); {
+			i := m.pop()
+			fn(NeighMask[i].And(prev).first(), i, w)
+			next = next.Or(NeighMask[i])
+		}
+		if !rules.Cascade {
+			return
+		}
+		prev, wave = wave, next.And(opBit)
+	}
+}
+
+// Infection 一次感染：From 上的子把 To 上的对方子翻过来
+type Infection struct {
+	From, To HexCoord
+	Wave     int // 0 = 被落点直接感染；连锁规则下每往外传一层加 1
+}
+
+// InfectionChain player 走 mv 会引发的全部感染，按结算顺序排列（不改盘）。
+// 原版规则下只有第 0 波；UI 按 Wave 依次播放感染动画
+func InfectionChain(b *Board, player CellState, mv Move) []Infection {
+	to, ok := IndexOf[mv.To]
+	if !ok {
+		return nil
+	}
+	opBit := b.bitB
+	if player == PlayerB {
+		opBit = b.bitA
+	}
+	var out []Infection
+	forEachInfection(to, opBit, func(src, dst, wave int) {
+		out = append(out, Infection{From: CoordOf[src], To: CoordOf[dst], Wave: wave})
+	})
+	return out
+}
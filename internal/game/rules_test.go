@@ -0,0 +1,103 @@
+package game
+
+import "testing"
+
+func withRules(t *testing.T, r Rules) {
+	t.Helper()
+	prev := CurrentRules()
+	SetRules(r)
+	t.Cleanup(func() { SetRules(prev) })
+}
+
+// A 在 (0,0) 克隆到 (1,0)；B 三子连成一排 (2,0)-(3,0)-(4,0)
+func chainBoard(t *testing.T) (*Board, Move) {
+	b := seeBoard(t, []HexCoord{{Q: 0, R: 0}}, []HexCoord{{Q: 2, R: 0}, {Q: 3, R: 0}, {Q: 4, R: 0}})
+	return b, Move{From: HexCoord{Q: 0, R: 0}, To: HexCoord{Q: 1, R: 0}}
+}
+
+func TestInfectionChainStandard(t *testing.T) {
+	withRules(t, Rules{})
+	b, mv := chainBoard(t)
+	got := InfectionChain(b, PlayerA, mv)
+	want := []Infection{{From: mv.To, To: HexCoord{Q: 2, R: 0}}}
+	if len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("原版规则只应感染落点相邻: %+v", got)
+	}
+}
+
+func TestInfectionChainCascade(t *testing.T) {
+	withRules(t, Rules{Cascade: true})
+	b, mv := chainBoard(t)
+	want := []Infection{
+		{From: HexCoord{Q: 1, R: 0}, To: HexCoord{Q: 2, R: 0}, Wave: 0},
+		{From: HexCoord{Q: 2, R: 0}, To: HexCoord{Q: 3, R: 0}, Wave: 1},
+		{From: HexCoord{Q: 3, R: 0}, To: HexCoord{Q: 4, R: 0}, Wave: 2},
+	}
+	got := InfectionChain(b, PlayerA, mv)
+	if len(got) != len(want) {
+		t.Fatalf("连锁感染应翻整排: %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("第 %d 个感染 %+v，期望 %+v", i, got[i], want[i])
+		}
+	}
+	if n := PreviewInfectedCount(b, mv, PlayerA); n != 3 {
+		t.Fatalf("预览感染数 %d，期望 3", n)
+	}
+
+	st := &GameState{Board: b, CurrentPlayer: PlayerA}
+	infected, _, err := st.MakeMove(mv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infected) != 3 || b.CountPieces(PlayerB) != 0 {
+		t.Fatalf("走后 B 应全灭: infected=%v", infected)
+	}
+}
+
+// 连锁规则下增量走子/悔棋、预览与实际落子仍应彼此一致
+func TestCascadeMakeUnmakeConsistent(t *testing.T) {
+	withRules(t, Rules{Cascade: true})
+	for n, b := range symTestBoards(t) {
+		for _, side := range []CellState{PlayerA, PlayerB} {
+			h := b.Hash()
+			for _, mv := range GenerateMoves(b, side) {
+				mi := DescribeMove(b, side, mv)
+				u := mMakeMoveWithUndo(b, mv, side)
+				if mi.Captures != b.LastInfect {
+					t.Fatalf("局面 %d %v: Captures %d，实际感染 %d", n, mv, mi.Captures, b.LastInfect)
+				}
+				b.UnmakeMove(u)
+				if b.Hash() != h {
+					t.Fatalf("局面 %d %v: 悔棋后哈希不一致", n, mv)
+				}
+			}
+		}
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	for in, want := range map[string]Rules{
+		"":                  {},
+		"standard":          {},
+		"Cascade":           {Cascade: true},
+		"limited":           {CloneLimit: DefaultCloneLimit},
+		"limited:5":         {CloneLimit: 5},
+		"cascade+limited:2": {Cascade: true, CloneLimit: 2},
+	} {
+		got, err := ParseRules(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseRules(%q) = %v, %v", in, got, err)
+		}
+		// String 要能原样解析回来（联机握手、录像都靠它）
+		if back, err := ParseRules(got.String()); err != nil || back != got {
+			t.Fatalf("%v 往返得到 %v, %v", got, back, err)
+		}
+	}
+	for _, bad := range []string{"atomic", "limited:0", "limited:x", "cascade:2", "limited:100"} {
+		if _, err := ParseRules(bad); err == nil {
+			t.Fatalf("%q 应报错", bad)
+		}
+	}
+}
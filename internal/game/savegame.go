@@ -0,0 +1,91 @@
+// game/savegame.go
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"hexxagon_go/internal/migrate"
+)
+
+// 存档：中断的对局存成一个 JSON 文件，下次接着下。
+// 存的是开局 + 全部着法而不只是当前局面：克隆次数限制的脉号、每格易手次数都不进 PositionString，
+// 只有从开局重放才能还原；当前局面另存一份，载入时核对，文件被改坏了能直接报出来。
+//
+//	{"version": 1, "rules": "cascade", "start": "<PositionString>", "moves": [...], "position": "<PositionString>"}
+
+// SaveVersion 当前写出的存档版本；读到更新的版本直接报错。改格式时加一，并在 saveFormat 登记升级步骤
+const SaveVersion = 1
+
+// saveFormat 存档的版本与升级步骤（见 internal/migrate）；存档从第一版起就带版本号
+var saveFormat = migrate.NewFormat("savegame", SaveVersion, 0)
+
+// SavedGame 一份存档
+type SavedGame struct {
+	Version  int    `json:"version"`
+	Rules    string `json:"rules,omitempty"` // Rules.String()；空=standard
+	Start    string `json:"start"`           // 开局的 PositionString
+	Moves    []Move `json:"moves"`           // 从开局起已走的着法
+	Position string `json:"position"`        // 走完 Moves 后的局面，载入时核对
+}
+
+// NewSavedGame 按当前规则生成存档；cur 须是 start 走完 moves 的结果
+func NewSavedGame(start *GameState, moves []Move, cur *GameState) SavedGame {
+	s := SavedGame{
+		Version:  SaveVersion,
+		Start:    start.PositionString(),
+		Moves:    append([]Move(nil), moves...),
+		Position: cur.PositionString(),
+	}
+	if r := CurrentRules(); !r.IsStandard() {
+		s.Rules = r.String()
+	}
+	return s
+}
+
+// ParsedRules 存档的规则变体
+func (s SavedGame) ParsedRules() (Rules, error) {
+	return ParseRules(s.Rules)
+}
+
+// Restore 从开局逐手重放到存档时的局面，并与存下的局面核对；
+// 调用方须先按 ParsedRules 设好规则（规则是进程全局的，这里不替调用方切换）
+func (s SavedGame) Restore() (start, cur *GameState, err error) {
+	if start, err = ParsePosition(s.Start); err != nil {
+		return nil, nil, fmt.Errorf("savegame: start: %w", err)
+	}
+	if cur, err = ReplayMoves(start, s.Moves); err != nil {
+		return nil, nil, fmt.Errorf("savegame: %w", err)
+	}
+	if got := cur.PositionString(); got != s.Position {
+		return nil, nil, fmt.Errorf("savegame: position after %d moves is %s, file says %s", len(s.Moves), got, s.Position)
+	}
+	return start, cur, nil
+}
+
+// WriteSave 写存档（先写临时文件再改名，中途崩溃不会留下半个文件）
+func WriteSave(path string, s SavedGame) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ReadSave 读存档，老版本按 saveFormat 升到当前版本；局面是否对得上由 Restore 核对
+func ReadSave(path string) (SavedGame, error) {
+	var s SavedGame
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s, err
+	}
+	if _, err := saveFormat.Decode(data, &s); err != nil {
+		return s, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
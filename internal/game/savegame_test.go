@@ -0,0 +1,96 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 走几手后存盘再读回：局面、行棋方、克隆次数都要和直接下的一致
+func TestSaveGameRoundTrip(t *testing.T) {
+	withRules(t, Rules{CloneLimit: 2})
+	start := NewGameState(boardRadius)
+	cur := NewGameState(boardRadius)
+	var moves []Move
+	for i := 0; i < 6; i++ {
+		mv := GenerateMoves(cur.Board, cur.CurrentPlayer)[0]
+		if _, _, err := cur.MakeMove(mv); err != nil {
+			t.Fatal(err)
+		}
+		moves = append(moves, mv)
+	}
+
+	path := filepath.Join(t.TempDir(), "save.json")
+	if err := WriteSave(path, NewSavedGame(start, moves, cur)); err != nil {
+		t.Fatal(err)
+	}
+	s, err := ReadSave(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, err := s.ParsedRules(); err != nil || r != (Rules{CloneLimit: 2}) {
+		t.Fatalf("规则 %+v, %v", r, err)
+	}
+	_, got, err := s.Restore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Board.Cells != cur.Board.Cells || got.CurrentPlayer != cur.CurrentPlayer || got.Flips != cur.Flips {
+		t.Fatal("读回的局面与存盘时不一致")
+	}
+	if len(GenerateMoves(got.Board, got.CurrentPlayer)) != len(GenerateMoves(cur.Board, cur.CurrentPlayer)) {
+		t.Fatal("读回后的合法着法数不同（克隆次数没还原）")
+	}
+}
+
+func TestSaveGameRejectsBadFiles(t *testing.T) {
+	start := NewGameState(boardRadius)
+	cur := NewGameState(boardRadius)
+	mv := GenerateMoves(cur.Board, PlayerA)[0]
+	cur.MakeMove(mv)
+
+	s := NewSavedGame(start, []Move{mv}, cur)
+	s.Position = start.PositionString() // 局面与着法对不上
+	if _, _, err := s.Restore(); err == nil {
+		t.Fatal("局面不符应报错")
+	}
+
+	dir := t.TempDir()
+	for name, body := range map[string]string{
+		"noversion.json": `{"start": "x", "moves": []}`,
+		"future.json":    `{"version": 99, "start": "x", "moves": []}`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadSave(path); err == nil || !strings.Contains(err.Error(), "version") {
+			t.Fatalf("%s: 期望版本错误，得到 %v", name, err)
+		}
+	}
+}
+
+// 每一版存档的样例（testdata/savegame_v*.json）都要能读回同一局面
+func TestReadSaveFixtures(t *testing.T) {
+	paths, _ := filepath.Glob("testdata/savegame_v*.json")
+	if len(paths) != SaveVersion {
+		t.Fatalf("testdata 里有 %d 个版本的样例，应为 %d（每一版留一个）", len(paths), SaveVersion)
+	}
+	for _, p := range paths {
+		s, err := ReadSave(p)
+		if err != nil {
+			t.Fatalf("%s: %v", p, err)
+		}
+		if s.Version != SaveVersion {
+			t.Fatalf("%s: 读回的版本 %d，应升到 %d", p, s.Version, SaveVersion)
+		}
+		_, cur, err := s.Restore()
+		if err != nil {
+			t.Fatalf("%s: %v", p, err)
+		}
+		if len(s.Moves) != 4 || cur.CurrentPlayer != PlayerA {
+			t.Fatalf("%s: %d 手，轮到 %v", p, len(s.Moves), cur.CurrentPlayer)
+		}
+	}
+}
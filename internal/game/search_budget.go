@@ -0,0 +1,109 @@
+// game/search_budget.go
+package game
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// 按时间而不是固定深度搜索：从 1 层起迭代加深，每层把截止时间带进 α-β，
+// 到点后正在搜的那一层整层作废，用上一层完整搜完的结果。
+//
+// 截止时间跟着每个搜索协程私有的 nodeCounter 走，不放全局：对战工具会同时跑好几盘，
+// 各自的时限互不干扰。
+//
+// AbortSearches 是唯一的全局开关：界面进入闲置时用它叫停进程内所有还在跑的搜索，别让它们在后台继续占用 NN。
+
+// MaxBudgetDepth 按时间搜索时的深度上限；实际还不超过剩余空格数。
+// 只按时间、不限深度的搜索把它当 maxDepth 传给 FindBestMoveWithBudgetDepth（或外部引擎的 go depth）
+const MaxBudgetDepth = 64
+
+// nodeCounter 搜索协程私有的节点计数，攒满一批再并进全局计数，省掉热路径上的原子加。
+// deadline 非零时每 64 个节点看一次表，过点后 expired 置位，alphaBeta 各层见到立即返回且不写置换表；
+// 搜索开始后调用过 AbortSearches 也按超时处理
+type nodeCounter struct {
+	n        int64
+	deadline time.Time
+	abortGen uint64 // 搜索开始时的 searchAbortGen
+	expired  bool
+}
+
+var searchAbortGen atomic.Uint64
+
+// AbortSearches 叫停进程内所有正在进行的 α-β 搜索：没搜完的层作废，已搜完的层照常返回。
+// 之后新开的搜索不受影响
+func AbortSearches() {
+	searchAbortGen.Add(1)
+}
+
+// tick 记一个节点；返回 true 表示已超时
+func (c *nodeCounter) tick() bool {
+	c.n++
+	if c.n&63 == 0 {
+		c.timeUp()
+	}
+	if c.n >= 1024 {
+		AddNodes(c.n)
+		c.n = 0
+	}
+	return c.expired
+}
+
+// timeUp 现在就看一次表（顺带看是否被 AbortSearches 叫停）
+func (c *nodeCounter) timeUp() bool {
+	if c.expired {
+		return true
+	}
+	if searchAbortGen.Load() != c.abortGen || !c.deadline.IsZero() && time.Now().After(c.deadline) {
+		c.expired = true
+	}
+	return c.expired
+}
+
+// stopped 已超时；nil 表示不计数也不限时
+func (c *nodeCounter) stopped() bool { return c != nil && c.expired }
+
+// flush 把没攒满一批的零头并进全局计数
+func (c *nodeCounter) flush() {
+	if c.n > 0 {
+		AddNodes(c.n)
+		c.n = 0
+	}
+}
+
+// FindBestMoveWithBudget 在 budget 时间内迭代加深搜索，返回最后一层完整搜完的最佳着法。
+// 第 1 层总会搜完（哪怕已经超时，被 AbortSearches 叫停除外），只要有合法着法就有结果；ok=false 的含义同 FindBestMoveAtDepth
+func FindBestMoveWithBudget(b *Board, player CellState, budget time.Duration, allowJump bool) (Move, bool) {
+	return FindBestMoveWithBudgetProgress(b, player, budget, allowJump, nil)
+}
+
+// FindBestMoveWithBudgetProgress 同 FindBestMoveWithBudget，每搜完一层在搜索协程里同步调用 onDepth（可为 nil）
+func FindBestMoveWithBudgetProgress(b *Board, player CellState, budget time.Duration, allowJump bool, onDepth func(SearchProgress)) (Move, bool) {
+	return FindBestMoveWithBudgetDepth(b, player, MaxBudgetDepth, budget, allowJump, onDepth)
+}
+
+// FindBestMoveWithBudgetDepth 同 FindBestMoveWithBudgetProgress，另外最多加深到 maxDepth 层：
+// 时间够就在 maxDepth 停，不够就用到点前最后一层完整的结果
+func FindBestMoveWithBudgetDepth(b *Board, player CellState, maxDepth int, budget time.Duration, allowJump bool, onDepth func(SearchProgress)) (best Move, ok bool) {
+	start := time.Now()
+	deadline := start.Add(budget)
+	gen := searchAbortGen.Load()
+	limit := min(min(maxDepth, MaxBudgetDepth), max(1, b.EmptyMask().Count()))
+	for depth := 1; depth <= limit; depth++ {
+		dl := deadline
+		if depth == 1 {
+			dl = time.Time{}
+		} else if !time.Now().Before(deadline) || searchAbortGen.Load() != gen {
+			break
+		}
+		mv, hit, complete := findBestMoveAtDepth(b, player, int64(depth), allowJump, dl)
+		if !hit || !complete {
+			break
+		}
+		best, ok = mv, true
+		if onDepth != nil {
+			onDepth(SearchProgress{Depth: depth, Best: mv, Elapsed: time.Since(start), HashFull: HashFull()})
+		}
+	}
+	return
+}
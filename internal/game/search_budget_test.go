@@ -0,0 +1,74 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+// 按时间搜索：到点即停，至少搜完 1 层，回报的深度逐层递增；截止时间已过的那一层整层作废
+func TestFindBestMoveWithBudget(t *testing.T) {
+	gs := NewGameState(boardRadius)
+	legal := map[Move]bool{}
+	for _, mv := range GenerateMoves(gs.Board, PlayerA) {
+		legal[mv] = true
+	}
+
+	var depths []int
+	start := time.Now()
+	mv, ok := FindBestMoveWithBudgetProgress(gs.Board, PlayerA, 200*time.Millisecond, false, func(p SearchProgress) {
+		depths = append(depths, p.Depth)
+	})
+	if took := time.Since(start); took > 5*time.Second {
+		t.Fatalf("200ms 的预算搜了 %v", took)
+	}
+	if !ok || !legal[mv] || len(depths) == 0 || depths[0] != 1 {
+		t.Fatalf("应返回合法着法并至少搜完 1 层: %v %v %v", mv, ok, depths)
+	}
+	for i := 1; i < len(depths); i++ {
+		if depths[i] != depths[i-1]+1 {
+			t.Fatalf("深度应逐层递增: %v", depths)
+		}
+	}
+
+	depths = depths[:0]
+	if mv, ok := FindBestMoveWithBudgetDepth(gs.Board, PlayerA, 2, time.Minute, false, func(p SearchProgress) {
+		depths = append(depths, p.Depth)
+	}); !ok || !legal[mv] || len(depths) != 2 {
+		t.Fatalf("时间充裕时应恰好加深到上限 2 层: %v", depths)
+	}
+
+	if mv, ok := FindBestMoveWithBudget(gs.Board, PlayerA, time.Nanosecond, false); !ok || !legal[mv] {
+		t.Fatal("预算再小也要搜完第 1 层")
+	}
+	if _, ok, complete := findBestMoveAtDepth(gs.Board, PlayerA, 3, false, time.Now().Add(-time.Second)); ok || complete {
+		t.Fatal("截止时间已过，这一层应标记为没搜完且不给着法")
+	}
+	hash := gs.Board.Hash()
+	if mv, ok, complete := findBestMoveAtDepth(gs.Board, PlayerA, 2, false, time.Time{}); !ok || !complete || !legal[mv] || gs.Board.Hash() != hash {
+		t.Fatal("不限时应搜完整层且不改盘")
+	}
+}
+
+// AbortSearches：正在跑的按时间搜索立即收手，返回已搜完的那层；之后的新搜索不受影响
+func TestAbortSearches(t *testing.T) {
+	gs := NewGameState(boardRadius)
+	legal := map[Move]bool{}
+	for _, mv := range GenerateMoves(gs.Board, PlayerA) {
+		legal[mv] = true
+	}
+	start := time.Now()
+	mv, ok := FindBestMoveWithBudgetProgress(gs.Board, PlayerA, time.Minute, false, func(p SearchProgress) {
+		if p.Depth == 1 {
+			go AbortSearches()
+		}
+	})
+	if took := time.Since(start); took > 10*time.Second {
+		t.Fatalf("叫停后还搜了 %v", took)
+	}
+	if !ok || !legal[mv] {
+		t.Fatalf("应返回第 1 层的结果: %v %v", mv, ok)
+	}
+	if _, ok, complete := findBestMoveAtDepth(gs.Board, PlayerA, 2, false, time.Time{}); !ok || !complete {
+		t.Fatal("叫停之后新开的搜索应照常搜完")
+	}
+}
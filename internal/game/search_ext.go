@@ -0,0 +1,67 @@
+// internal/game/search_ext.go
+package game
+
+// 强制线延伸（类似国际象棋的将军延伸）：某步之后对手只剩一种应着，或这一步翻了很多子，
+// 局面往往在下一两步内剧烈变化，固定深度容易在这里看错。对这类着法把子树加深一层，
+// 每条路径最多加 searchExtMax 层，避免连续强制线把搜索拖爆。
+// 深度 1 的 NN 批量叶子评估不走这里（整层一次推理，不逐步判断）。
+
+var (
+	searchExtMax       = 2 // 单条路径上的延伸上限；0 = 关闭
+	searchExtFlipCount = 4 // 翻子数达到该值即延伸
+)
+
+// searchExtension 着法 mover 刚落在 b 上（mMakeMoveWithUndo 之后）时调用，返回子节点应加的深度（0 或 1）。
+// allowJump 与搜索一致：禁跳时对手的跳跃不算应着（除非只剩跳跃，同 filterJumpsByFlag 的兜底）
+func searchExtension(b *Board, mover CellState, extLeft int, allowJump bool) int64 {
+	if extLeft <= 0 {
+		return 0
+	}
+	if b.LastInfect >= searchExtFlipCount {
+		return 1
+	}
+	if countMovesUpTo(b, Opponent(mover), 2, allowJump) == 1 {
+		return 1
+	}
+	return 0
+}
+
+// countMovesUpTo 数 side 的合法着法，数到 limit 就停。先数克隆；allowJump 时再数跳跃，
+// 禁跳时只有一步克隆都没有才数跳跃（搜索里的跳跃闸门在这种局面下放行）
+func countMovesUpTo(b *Board, side CellState, limit int, allowJump bool) int {
+	pBit := b.bitA
+	if side == PlayerB {
+		pBit = b.bitB
+	}
+	n := 0
+	for m := pBit; !m.IsZero(); {
+		i := m.pop()
+		for _, to := range NeighI[i] {
+			if b.Cells[to] == Empty && b.canCloneI(i) {
+				if n++; n >= limit {
+					return n
+				}
+			}
+		}
+	}
+	if !allowJump && n > 0 {
+		return n
+	}
+	for m := pBit; !m.IsZero(); {
+		i := m.pop()
+		for _, to := range JumpI[i] {
+			if b.Cells[to] == Empty {
+				if n++; n >= limit {
+					return n
+				}
+			}
+		}
+	}
+	return n
+}
+
+// childDepth 着法已落在 b 上：返回子节点的搜索深度与剩余延伸额度
+func childDepth(b *Board, mover CellState, depth int64, extLeft int, allowJump bool) (int64, int) {
+	e := searchExtension(b, mover, extLeft, allowJump)
+	return depth - 1 + e, extLeft - int(e)
+}
@@ -0,0 +1,60 @@
+package game
+
+import "testing"
+
+func TestSearchExtension(t *testing.T) {
+	// B 只剩角上一子 (-4,0)，周围只留一个空位：A 走完后 B 只有唯一应着
+	cells := make([]CellState, CellCount(boardRadius))
+	set := func(c HexCoord, s CellState) { cells[IndexOf[c]] = s }
+	for i := range cells {
+		cells[i] = PlayerA
+	}
+	set(HexCoord{Q: -4, R: 0}, PlayerB)
+	set(HexCoord{Q: -3, R: 0}, Empty)
+	set(HexCoord{Q: 2, R: 2}, Empty)
+	b, err := BoardFromCells(cells)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := countMovesUpTo(b, PlayerB, 2, true); n != 1 {
+		t.Fatalf("B 应只有 1 种着法，得到 %d", n)
+	}
+	if n := countMovesUpTo(b, PlayerA, 5, true); n != 5 {
+		t.Fatalf("数到上限应停下，得到 %d", n)
+	}
+	if e := searchExtension(b, PlayerA, 1, true); e != 1 {
+		t.Fatalf("对手唯一应着时应延伸")
+	}
+	if d, ext := childDepth(b, PlayerA, 3, 0, true); d != 2 || ext != 0 {
+		t.Fatalf("额度用完后不应再延伸: d=%d ext=%d", d, ext)
+	}
+
+	// 开局普通着法：不延伸
+	gs := NewGameState(boardRadius)
+	mv := GenerateMoves(gs.Board, PlayerA)[0]
+	undo := mMakeMoveWithUndo(gs.Board, mv, PlayerA)
+	if d, ext := childDepth(gs.Board, PlayerA, 3, searchExtMax, true); d != 2 || ext != searchExtMax {
+		t.Fatalf("普通着法不应延伸: d=%d ext=%d", d, ext)
+	}
+	gs.Board.UnmakeMove(undo)
+
+	// 再空出 (-2,0)：B 多一步跳跃。允许跳跃时有两种应着；禁跳时跳跃不算，仍是唯一应着
+	set(HexCoord{Q: -2, R: 0}, Empty)
+	if b, err = BoardFromCells(cells); err != nil {
+		t.Fatal(err)
+	}
+	if n := countMovesUpTo(b, PlayerB, 2, true); n != 2 {
+		t.Fatalf("允许跳跃时 B 应有 2 种着法，得到 %d", n)
+	}
+	if e := searchExtension(b, PlayerA, 1, false); e != 1 {
+		t.Fatalf("禁跳时对手唯一的克隆应着应延伸")
+	}
+	// 克隆的落点也填上：只剩跳跃时，禁跳搜索同样放行，照数
+	set(HexCoord{Q: -3, R: 0}, PlayerA)
+	if b, err = BoardFromCells(cells); err != nil {
+		t.Fatal(err)
+	}
+	if n := countMovesUpTo(b, PlayerB, 2, false); n != 1 {
+		t.Fatalf("只剩跳跃时应数到 1 种着法，得到 %d", n)
+	}
+}
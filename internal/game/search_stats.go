@@ -0,0 +1,160 @@
+// game/search_stats.go
+package game
+
+import "sync/atomic"
+
+// 剪枝遥测：统计每种过滤/剪枝技术各砍掉了多少着法或子树，
+// 让 bench / 对战报告能把提速归因到具体技术。默认关闭，开启后热路径多几次原子加。
+
+const (
+	filterJumpGate = iota
+	filterOpeningEdge
+	filterZeroInfectJump
+	filterRecaptureJump
+	filterVulnerableClone
+	filterIsolatedClone
+	numMoveFilters
+)
+
+var moveFilterNames = [numMoveFilters]string{
+	"jump-gate",
+	"opening-edge-only",
+	"zero-infect-jump",
+	"dangerous-recapture-jump",
+	"vulnerable-zero-infect-clone",
+	"dangerous-isolated-clone",
+}
+
+var searchTelemetry atomic.Bool
+
+// EnableSearchTelemetry 打开/关闭剪枝计数
+func EnableSearchTelemetry(on bool) { searchTelemetry.Store(on) }
+
+type FilterStats struct {
+	Calls   uint64 // 调用次数
+	In      uint64 // 输入着法数合计
+	Removed uint64 // 剔除着法数合计
+}
+
+type SearchStats struct {
+	Nodes     int64
+	TTProbes  uint64
+	TTHits    uint64
+	TTCutoffs uint64 // 置换表命中后直接返回（精确值或窗口闭合）
+	TTStores  uint64 // 写入置换表的次数
+	TTReplace uint64 // 其中挤掉本代另一个局面的次数（表太小时偏高）
+	HashFull  int    // 读取时的置换表占用率（千分比），见 HashFull
+
+	CutoffsMax       uint64 // MAX 节点 β 截断
+	CutoffsMin       uint64 // MIN 节点 α 截断
+	FirstMoveCutoffs uint64 // 第一个着法就截断（衡量着法排序质量）
+	NNBatchLeaves    uint64 // depth==1 时整层批量 NN 评估代替逐个递归
+
+	PolicyPruneCalls   uint64
+	PolicyPruneIn      uint64
+	PolicyPruneRemoved uint64
+
+	Filters map[string]FilterStats
+}
+
+var statsCounters struct {
+	ttCutoffs, cutMax, cutMin, firstCut, nnBatch uint64
+	ppCalls, ppIn, ppRemoved                     uint64
+	filters                                      [numMoveFilters]FilterStats
+}
+
+func countFilter(idx, in, out int) {
+	if !searchTelemetry.Load() {
+		return
+	}
+	f := &statsCounters.filters[idx]
+	atomic.AddUint64(&f.Calls, 1)
+	atomic.AddUint64(&f.In, uint64(in))
+	atomic.AddUint64(&f.Removed, uint64(in-out))
+}
+
+func countTTCutoff() {
+	if searchTelemetry.Load() {
+		atomic.AddUint64(&statsCounters.ttCutoffs, 1)
+	}
+}
+
+// countCutoff：maxNode 表示在 MAX 节点截断，i 为截断时的着法序号
+func countCutoff(maxNode bool, i int) {
+	if !searchTelemetry.Load() {
+		return
+	}
+	if maxNode {
+		atomic.AddUint64(&statsCounters.cutMax, 1)
+	} else {
+		atomic.AddUint64(&statsCounters.cutMin, 1)
+	}
+	if i == 0 {
+		atomic.AddUint64(&statsCounters.firstCut, 1)
+	}
+}
+
+func countNNBatchLeaf() {
+	if searchTelemetry.Load() {
+		atomic.AddUint64(&statsCounters.nnBatch, 1)
+	}
+}
+
+func countPolicyPrune(in, out int) {
+	if !searchTelemetry.Load() {
+		return
+	}
+	atomic.AddUint64(&statsCounters.ppCalls, 1)
+	atomic.AddUint64(&statsCounters.ppIn, uint64(in))
+	atomic.AddUint64(&statsCounters.ppRemoved, uint64(in-out))
+}
+
+// GetSearchStats 返回自上次 ResetSearchStats 以来的累计计数
+func GetSearchStats() SearchStats {
+	probes, hits, _ := GetTTStats()
+	s := SearchStats{
+		Nodes:              atomic.LoadInt64(&NodesSearched),
+		TTProbes:           probes,
+		TTHits:             hits,
+		TTCutoffs:          atomic.LoadUint64(&statsCounters.ttCutoffs),
+		TTStores:           atomic.LoadUint64(&ttStoreCount),
+		TTReplace:          atomic.LoadUint64(&ttReplaceCount),
+		HashFull:           HashFull(),
+		CutoffsMax:         atomic.LoadUint64(&statsCounters.cutMax),
+		CutoffsMin:         atomic.LoadUint64(&statsCounters.cutMin),
+		FirstMoveCutoffs:   atomic.LoadUint64(&statsCounters.firstCut),
+		NNBatchLeaves:      atomic.LoadUint64(&statsCounters.nnBatch),
+		PolicyPruneCalls:   atomic.LoadUint64(&statsCounters.ppCalls),
+		PolicyPruneIn:      atomic.LoadUint64(&statsCounters.ppIn),
+		PolicyPruneRemoved: atomic.LoadUint64(&statsCounters.ppRemoved),
+		Filters:            make(map[string]FilterStats, numMoveFilters),
+	}
+	for i := range statsCounters.filters {
+		f := &statsCounters.filters[i]
+		s.Filters[moveFilterNames[i]] = FilterStats{
+			Calls:   atomic.LoadUint64(&f.Calls),
+			In:      atomic.LoadUint64(&f.In),
+			Removed: atomic.LoadUint64(&f.Removed),
+		}
+	}
+	return s
+}
+
+// ResetSearchStats 清零剪枝计数、节点数与 TT 命中/写入统计（不清 TT 内容）
+func ResetSearchStats() {
+	ResetNodes()
+	resetTTCounters()
+	for _, p := range []*uint64{
+		&statsCounters.ttCutoffs, &statsCounters.cutMax, &statsCounters.cutMin,
+		&statsCounters.firstCut, &statsCounters.nnBatch,
+		&statsCounters.ppCalls, &statsCounters.ppIn, &statsCounters.ppRemoved,
+	} {
+		atomic.StoreUint64(p, 0)
+	}
+	for i := range statsCounters.filters {
+		f := &statsCounters.filters[i]
+		atomic.StoreUint64(&f.Calls, 0)
+		atomic.StoreUint64(&f.In, 0)
+		atomic.StoreUint64(&f.Removed, 0)
+	}
+}
@@ -0,0 +1,166 @@
+// game/search_tree.go
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// 搜索树导出：把根的所有着法（可选再展开一层应着）连同分数、MCTS 访问数、
+// 被哪个过滤器剔除一并导出为 JSON / graphviz，用来排查“引擎为什么走了这步”。
+
+// SearchTreeNode 一条着法
+type SearchTreeNode struct {
+	From     HexCoord          `json:"from"`
+	To       HexCoord          `json:"to"`
+	Player   string            `json:"player"`
+	Score    int               `json:"score"`            // 根方视角的 α-β 分
+	Visits   int               `json:"visits,omitempty"` // MCTS 访问数（Sims>0 时）
+	Pruned   string            `json:"pruned,omitempty"` // 剔除它的过滤器；空 = 参与了搜索
+	Best     bool              `json:"best,omitempty"`
+	Children []*SearchTreeNode `json:"children,omitempty"`
+}
+
+type SearchTree struct {
+	Position  string            `json:"position"` // GameState.PositionString
+	Player    string            `json:"player"`
+	Depth     int64             `json:"depth"`
+	Evaluator string            `json:"evaluator"`
+	Moves     []*SearchTreeNode `json:"moves"`
+}
+
+type SearchTreeOptions struct {
+	Depth     int64
+	AllowJump bool
+	Plies     int // 1 = 只导出根着法；2 = 再展开对手应着
+	Sims      int // >0 时额外跑 MCTS，填 Visits
+}
+
+// ExportSearchTree 重新搜索一遍根局面并收集导出数据（调试用，不追求速度）
+func ExportSearchTree(b *Board, player CellState, opt SearchTreeOptions) *SearchTree {
+	if opt.Depth < 1 {
+		opt.Depth = 1
+	}
+	gs := &GameState{Board: b, CurrentPlayer: player}
+	t := &SearchTree{
+		Position:  gs.PositionString(),
+		Player:    playerName(player),
+		Depth:     opt.Depth,
+		Evaluator: EvaluatorID(),
+	}
+
+	var visits map[Move]int
+	if opt.Sims > 0 {
+		ar := acquireArena()
+		root := runMCTS(ar, b, player, opt.Sims, 0, opt.AllowJump, rolloutCfg)
+		visits = make(map[Move]int, len(root.children))
+		for _, ch := range root.children {
+			visits[ch.move] = ch.visits
+		}
+		releaseArena(ar)
+	}
+
+	t.Moves = exportPly(b, player, player, opt.Depth, opt.AllowJump, visits)
+	markBest(t.Moves, true)
+
+	if opt.Plies >= 2 && opt.Depth >= 2 {
+		for _, n := range t.Moves {
+			if n.Pruned != "" {
+				continue
+			}
+			nb := b.Clone()
+			mMakeMoveWithUndo(nb, Move{From: n.From, To: n.To}, player)
+			n.Children = exportPly(nb, Opponent(player), player, opt.Depth-1, opt.AllowJump, nil)
+			markBest(n.Children, false) // 对手选对根方最不利的
+		}
+	}
+	return t
+}
+
+// exportPly 列出 side 的全部着法：标注过滤原因，并给每步打 original 视角的分
+func exportPly(b *Board, side, original CellState, depth int64, allowJump bool, visits map[Move]int) []*SearchTreeNode {
+	all := GenerateMoves(b, side)
+	reasons := make(map[Move]string)
+	applyMoveFiltersTraced(b, side, append([]Move(nil), all...), allowJump, func(mv Move, reason string) { reasons[mv] = reason })
+
+	out := make([]*SearchTreeNode, 0, len(all))
+	for _, mv := range all {
+		nb := b.Clone()
+		var nodes nodeCounter
+		mMakeMoveWithUndo(nb, mv, side)
+		d, ext := childDepth(nb, side, depth, searchExtMax, allowJump)
+		score := hybridAlphaBeta(nb, 0, Opponent(side), original, d, -1000000, 1000000, allowJump, &nodes, ext)
+		out = append(out, &SearchTreeNode{
+			From:   mv.From,
+			To:     mv.To,
+			Player: playerName(side),
+			Score:  score,
+			Visits: visits[mv],
+			Pruned: reasons[mv],
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
+
+func markBest(ns []*SearchTreeNode, maximize bool) {
+	var best *SearchTreeNode
+	for _, n := range ns {
+		if n.Pruned != "" {
+			continue
+		}
+		if best == nil || (maximize && n.Score > best.Score) || (!maximize && n.Score < best.Score) {
+			best = n
+		}
+	}
+	if best != nil {
+		best.Best = true
+	}
+}
+
+func (t *SearchTree) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(t)
+}
+
+// WriteDOT 输出 graphviz：最佳着法加粗，被剔除的着法灰色虚线
+func (t *SearchTree) WriteDOT(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "digraph search {\n  rankdir=LR;\n  node [shape=box, fontname=monospace];\n  root [label=\"%s to move\\ndepth %d\"];\n", t.Player, t.Depth); err != nil {
+		return err
+	}
+	id := 0
+	var walk func(parent string, ns []*SearchTreeNode) error
+	walk = func(parent string, ns []*SearchTreeNode) error {
+		for _, n := range ns {
+			id++
+			name := fmt.Sprintf("n%d", id)
+			label := fmt.Sprintf("%s (%d,%d)->(%d,%d)\\nscore %d", n.Player, n.From.Q, n.From.R, n.To.Q, n.To.R, n.Score)
+			if n.Visits > 0 {
+				label += fmt.Sprintf("\\nvisits %d", n.Visits)
+			}
+			style := ""
+			switch {
+			case n.Pruned != "":
+				label += "\\npruned: " + n.Pruned
+				style = ", style=dashed, color=gray, fontcolor=gray"
+			case n.Best:
+				style = ", penwidth=3"
+			}
+			if _, err := fmt.Fprintf(w, "  %s [label=\"%s\"%s];\n  %s -> %s;\n", name, label, style, parent, name); err != nil {
+				return err
+			}
+			if err := walk(name, n.Children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk("root", t.Moves); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
@@ -0,0 +1,254 @@
+// game/search_verify.go
+package game
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// VerifyMode：α-β 与 MCTS 互相复核的方式
+type VerifyMode int
+
+const (
+	VerifyOff      VerifyMode = iota // 不复核，等同 FindBestMoveAtDepth
+	VerifyABByMCTS                   // α-β 提议，短 MCTS 复核
+	VerifyMCTSByAB                   // MCTS 提议，α-β 复核
+)
+
+// VerifyModeNames 可选的复核方式名，下标即 VerifyMode，供命令行帮助使用
+var VerifyModeNames = []string{"off", "ab-mcts", "mcts-ab"}
+
+func (m VerifyMode) String() string {
+	if m >= 0 && int(m) < len(VerifyModeNames) {
+		return VerifyModeNames[m]
+	}
+	return fmt.Sprintf("VerifyMode(%d)", int(m))
+}
+
+// ParseVerifyMode 解析复核方式名；空串视为 off
+func ParseVerifyMode(name string) (VerifyMode, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return VerifyOff, nil
+	}
+	for i, n := range VerifyModeNames {
+		if n == name {
+			return VerifyMode(i), nil
+		}
+	}
+	return VerifyOff, fmt.Errorf("未知复核方式 %q（可选 %v）", name, VerifyModeNames)
+}
+
+type VerifyConfig struct {
+	Mode     VerifyMode
+	MCTSSims int           // 复核用的 MCTS 模拟次数
+	MCTSTime time.Duration // 复核用的 MCTS 时间上限（0 = 只看 MCTSSims）
+
+	// α-β 提议时：提议走法访问数 / MCTS 最多访问数 < MinVisitRatio 视为分歧
+	MinVisitRatio float64
+	// MCTS 提议时：α-β 最佳分 - MCTS 走法分 > MaxScoreGap 视为分歧
+	MaxScoreGap int
+	// 分歧时最多额外加深几层
+	MaxExtend int
+}
+
+// 默认关闭；打开后 IterativeDeepening 的最后一层改走 FindBestMoveVerified（见 ai.go）
+var verifyCfg = VerifyConfig{
+	Mode:          VerifyOff,
+	MCTSSims:      400,
+	MinVisitRatio: 0.5,
+	MaxScoreGap:   30,
+	MaxExtend:     2,
+}
+
+func SetVerifyConfig(c VerifyConfig) { verifyCfg = c }
+
+// VerifyEnabled 当前是否开着复核
+func VerifyEnabled() bool { return verifyCfg.Mode != VerifyOff }
+
+// VerifyStats：分歧统计，用来观察两种算法各自在哪类局面上失手
+type VerifyStats struct {
+	Calls      uint64 // 复核次数
+	Agree      uint64 // 首轮即一致
+	Disagree   uint64 // 首轮分歧
+	Extensions uint64 // 因分歧额外加深的层数合计
+	Resolved   uint64 // 加深后达成一致
+	Unresolved uint64 // 加深到上限仍分歧
+	Overridden uint64 // 最终采用了复核方（而非提议方）的走法
+
+	// 首轮分歧按阶段分桶：0=开局 1=中局 2=残局（阈值沿用 phaseSwitch）
+	DisagreeByPhase [3]uint64
+}
+
+var verifyStats VerifyStats
+
+// String 一行写完，引擎的 "info verify" 和各工具的日志共用这个格式
+func (s VerifyStats) String() string {
+	return fmt.Sprintf("calls %d agree %d disagree %d extensions %d resolved %d unresolved %d overridden %d phase %d/%d/%d",
+		s.Calls, s.Agree, s.Disagree, s.Extensions, s.Resolved, s.Unresolved, s.Overridden,
+		s.DisagreeByPhase[0], s.DisagreeByPhase[1], s.DisagreeByPhase[2])
+}
+
+func GetVerifyStats() VerifyStats {
+	var s VerifyStats
+	s.Calls = atomic.LoadUint64(&verifyStats.Calls)
+	s.Agree = atomic.LoadUint64(&verifyStats.Agree)
+	s.Disagree = atomic.LoadUint64(&verifyStats.Disagree)
+	s.Extensions = atomic.LoadUint64(&verifyStats.Extensions)
+	s.Resolved = atomic.LoadUint64(&verifyStats.Resolved)
+	s.Unresolved = atomic.LoadUint64(&verifyStats.Unresolved)
+	s.Overridden = atomic.LoadUint64(&verifyStats.Overridden)
+	for i := range s.DisagreeByPhase {
+		s.DisagreeByPhase[i] = atomic.LoadUint64(&verifyStats.DisagreeByPhase[i])
+	}
+	return s
+}
+
+func ResetVerifyStats() {
+	atomic.StoreUint64(&verifyStats.Calls, 0)
+	atomic.StoreUint64(&verifyStats.Agree, 0)
+	atomic.StoreUint64(&verifyStats.Disagree, 0)
+	atomic.StoreUint64(&verifyStats.Extensions, 0)
+	atomic.StoreUint64(&verifyStats.Resolved, 0)
+	atomic.StoreUint64(&verifyStats.Unresolved, 0)
+	atomic.StoreUint64(&verifyStats.Overridden, 0)
+	for i := range verifyStats.DisagreeByPhase {
+		atomic.StoreUint64(&verifyStats.DisagreeByPhase[i], 0)
+	}
+}
+
+// FindBestMoveVerified：签名与 FindBestMoveAtDepth 一致，可直接替换
+func FindBestMoveVerified(b *Board, player CellState, depth int64, allowJump bool) (Move, bool) {
+	cfg := verifyCfg
+	switch cfg.Mode {
+	case VerifyABByMCTS:
+		return verifyABByMCTS(b, player, depth, allowJump, cfg)
+	case VerifyMCTSByAB:
+		return verifyMCTSByAB(b, player, depth, allowJump, cfg)
+	default:
+		return FindBestMoveAtDepth(b, player, depth, allowJump)
+	}
+}
+
+// α-β 提议 → MCTS 复核；分歧则加深 α-β，直到 MCTS 认可或到达上限
+func verifyABByMCTS(b *Board, player CellState, depth int64, allowJump bool, cfg VerifyConfig) (Move, bool) {
+	abMv, ok := FindBestMoveAtDepth(b, player, depth, allowJump)
+	if !ok {
+		return Move{}, false
+	}
+	atomic.AddUint64(&verifyStats.Calls, 1)
+
+	ar := acquireArena()
+	defer releaseArena(ar)
+	root := runMCTS(ar, b, player, cfg.MCTSSims, cfg.MCTSTime, allowJump, rolloutCfg)
+	if len(root.children) == 0 {
+		return abMv, true
+	}
+
+	for ext := 0; ; ext++ {
+		if mctsVisitRatio(root, abMv) >= cfg.MinVisitRatio {
+			if ext == 0 {
+				atomic.AddUint64(&verifyStats.Agree, 1)
+			} else {
+				atomic.AddUint64(&verifyStats.Resolved, 1)
+			}
+			return abMv, true
+		}
+		if ext == 0 {
+			recordDisagree(b)
+		}
+		if ext >= cfg.MaxExtend {
+			break
+		}
+		atomic.AddUint64(&verifyStats.Extensions, 1)
+		if mv, ok := FindBestMoveAtDepth(b, player, depth+int64(ext)+1, allowJump); ok {
+			abMv = mv
+		}
+	}
+
+	// 仍有分歧：更相信加深后的 α-β
+	atomic.AddUint64(&verifyStats.Unresolved, 1)
+	return abMv, true
+}
+
+// MCTS 提议 → α-β 复核；α-β 认为差距过大则加深，仍不认可就改用 α-β 的走法
+func verifyMCTSByAB(b *Board, player CellState, depth int64, allowJump bool, cfg VerifyConfig) (Move, bool) {
+	ar := acquireArena()
+	mctsMv, ok := mostVisitedChild(runMCTS(ar, b, player, cfg.MCTSSims, cfg.MCTSTime, allowJump, rolloutCfg))
+	releaseArena(ar)
+	if !ok {
+		return FindBestMoveAtDepth(b, player, depth, allowJump)
+	}
+	atomic.AddUint64(&verifyStats.Calls, 1)
+
+	var abMv Move
+	for ext := 0; ; ext++ {
+		d := depth + int64(ext)
+		mv, ok := FindBestMoveAtDepth(b, player, d, allowJump)
+		if !ok {
+			return mctsMv, true
+		}
+		abMv = mv
+
+		gap := 0
+		if abMv != mctsMv {
+			gap = scoreMoveAB(b, player, abMv, d, allowJump) - scoreMoveAB(b, player, mctsMv, d, allowJump)
+		}
+		if gap <= cfg.MaxScoreGap {
+			if ext == 0 {
+				atomic.AddUint64(&verifyStats.Agree, 1)
+			} else {
+				atomic.AddUint64(&verifyStats.Resolved, 1)
+			}
+			return mctsMv, true
+		}
+		if ext == 0 {
+			recordDisagree(b)
+		}
+		if ext >= cfg.MaxExtend {
+			break
+		}
+		atomic.AddUint64(&verifyStats.Extensions, 1)
+	}
+
+	atomic.AddUint64(&verifyStats.Unresolved, 1)
+	atomic.AddUint64(&verifyStats.Overridden, 1)
+	return abMv, true
+}
+
+// 提议走法的访问数相对 MCTS 首选走法的比例（1 = 就是首选）
+func mctsVisitRatio(root *mctsNode, mv Move) float64 {
+	bestN := 0
+	for _, ch := range root.children {
+		if ch.visits > bestN {
+			bestN = ch.visits
+		}
+	}
+	if bestN == 0 {
+		return 1
+	}
+	ch := root.child(mv)
+	if ch == nil {
+		return 0
+	}
+	return float64(ch.visits) / float64(bestN)
+}
+
+// 单独给某个根走法打 α-β 分（player 视角），与 FindBestMoveAtDepth 的 worker 同口径
+func scoreMoveAB(b *Board, player CellState, mv Move, depth int64, allowJump bool) int {
+	nb := b.Clone()
+	var nodes nodeCounter
+	undo := mMakeMoveWithUndo(nb, mv, player)
+	d, ext := childDepth(nb, player, depth, searchExtMax, allowJump)
+	score := hybridAlphaBeta(nb, 0, Opponent(player), player, d, -1000000, 1000000, allowJump, &nodes, ext)
+	nb.UnmakeMove(undo)
+	nodes.flush()
+	return score
+}
+
+func recordDisagree(b *Board) {
+	atomic.AddUint64(&verifyStats.Disagree, 1)
+	atomic.AddUint64(&verifyStats.DisagreeByPhase[GamePhase(b)], 1)
+}
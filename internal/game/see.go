@@ -0,0 +1,84 @@
+// internal/game/see.go
+package game
+
+// 感染版的静态交换评估（SEE）：只看落点附近，估计“我走这步 + 对手立刻最好的反吃”之后的子数差变化。
+// 子数差口径与 rolloutGain 相同：克隆 +1，每感染一颗 +2；跳跃起点空出不加分。
+// 只做两层、不递归，够便宜，可以在每个节点的过滤器和根排序里用。
+
+// seeDangerMargin 对手反吃比我这步多赚至少这么多（SEE <= -seeDangerMargin）就算危险着法。
+// 取 3：零吃子克隆被一手同时翻回起点和落点、吃 1 子的跳跃被对手克隆翻回两子，都落在这条线上。
+const seeDangerMargin = 3
+
+// seeMove 返回 mv（me 走）的局部交换收益；对手在落点周围无法反吃时就是这步本身的收益
+func seeMove(b *Board, me CellState, mv Move) int {
+	to, okT := IndexOf[mv.To]
+	from, okF := IndexOf[mv.From]
+	if !okT || !okF {
+		return 0
+	}
+	myBit, opBit := b.bitA, b.bitB
+	if me == PlayerB {
+		myBit, opBit = b.bitB, b.bitA
+	}
+	occupied := b.bitA.Or(b.bitB).Or(b.reach.blocked)
+
+	// 走完这步后的局面（只用掩码表示，不改盘）
+	toBit := cellBit(to)
+	infected := infectMask(to, opBit)
+	gain := 2 * infected.Count()
+	myAfter := myBit.Or(toBit).Or(infected)
+	opAfter := opBit.AndNot(infected)
+	occAfter := occupied.Or(toBit)
+	var candidates CellMask
+	if mv.IsJump() {
+		fromBit := cellBit(from)
+		myAfter = myAfter.AndNot(fromBit)
+		occAfter = occAfter.AndNot(fromBit)
+		candidates = candidates.Or(fromBit) // 起点空出后，对手可能落进来翻周围的老子
+	} else {
+		gain++
+	}
+
+	// 对手的反吃落点：与本步改动过的格子（落点、被感染格）相邻的空位
+	for m := toBit.Or(infected); !m.IsZero(); {
+		candidates = candidates.Or(NeighMask[m.pop()])
+	}
+	// 对手子只会变少，走前就够不到的格子走后也够不到
+	candidates = candidates.AndNot(occAfter).And(b.reach.mask[reachSlot(Opponent(me))])
+
+	best := 0
+	for !candidates.IsZero() {
+		x := candidates.pop()
+		flips := infectMask(x, myAfter).Count()
+		if flips == 0 {
+			continue
+		}
+		reply := 0
+		switch {
+		case !NeighMask[x].And(opAfter).IsZero():
+			reply = 2*flips + 1 // 克隆可达
+		case jumpReachable(x, opAfter):
+			reply = 2 * flips
+		default:
+			continue
+		}
+		if reply > best {
+			best = reply
+		}
+	}
+	return gain - best
+}
+
+func jumpReachable(x int, fromMask CellMask) bool {
+	for _, j := range JumpI[x] {
+		if fromMask.Has(j) {
+			return true
+		}
+	}
+	return false
+}
+
+// seeDangerous 对手的即时反吃明显赚回超过这步的收益
+func seeDangerous(b *Board, me CellState, mv Move) bool {
+	return seeMove(b, me, mv) <= -seeDangerMargin
+}
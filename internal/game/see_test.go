@@ -0,0 +1,56 @@
+package game
+
+import "testing"
+
+func seeBoard(t *testing.T, a, b []HexCoord) *Board {
+	t.Helper()
+	cells := make([]CellState, CellCount(boardRadius))
+	for _, c := range a {
+		cells[IndexOf[c]] = PlayerA
+	}
+	for _, c := range b {
+		cells[IndexOf[c]] = PlayerB
+	}
+	bd, err := BoardFromCells(cells)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bd
+}
+
+func TestSeeMove(t *testing.T) {
+	// 安全吃子：B 只有被吃的那一颗，无从反吃
+	b := seeBoard(t, []HexCoord{{Q: 0, R: 0}}, []HexCoord{{Q: 2, R: 0}})
+	if s := seeMove(b, PlayerA, Move{From: HexCoord{Q: 0, R: 0}, To: HexCoord{Q: 1, R: 0}}); s != 3 {
+		t.Fatalf("安全克隆吃 1 子应为 +3，得到 %d", s)
+	}
+
+	// 孤立子零吃克隆：B 可跳到 from/to 的共同邻居 (1,-1) 一次翻回两子
+	b = seeBoard(t, []HexCoord{{Q: 0, R: 0}}, []HexCoord{{Q: 3, R: -1}})
+	risky := Move{From: HexCoord{Q: 0, R: 0}, To: HexCoord{Q: 1, R: 0}}
+	if s := seeMove(b, PlayerA, risky); s != -3 || !seeDangerous(b, PlayerA, risky) {
+		t.Fatalf("被一跳双吃的克隆应为 -3 且危险，得到 %d", s)
+	}
+	safe := Move{From: HexCoord{Q: 0, R: 0}, To: HexCoord{Q: -1, R: 0}}
+	if seeDangerous(b, PlayerA, safe) {
+		t.Fatalf("远离 B 的克隆不应判危险: %d", seeMove(b, PlayerA, safe))
+	}
+	got := filterVulnerableZeroInfClones(b, PlayerA, []Move{risky, safe})
+	if len(got) != 1 || got[0] != safe {
+		t.Fatalf("应只删掉危险克隆，得到 %v", got)
+	}
+	if got := filterVulnerableZeroInfClones(b, PlayerA, []Move{risky}); len(got) != 1 {
+		t.Fatalf("全被删光时应回退原着法")
+	}
+
+	// 跳吃 1 子后 B 可克隆到 (1,-1) 同时翻回落点与被吃子
+	b = seeBoard(t, []HexCoord{{Q: -2, R: 0}}, []HexCoord{{Q: 1, R: 0}, {Q: 2, R: 0}, {Q: 2, R: -1}})
+	jump := Move{From: HexCoord{Q: -2, R: 0}, To: HexCoord{Q: 0, R: 0}}
+	if s := seeMove(b, PlayerA, jump); s != -3 {
+		t.Fatalf("被克隆反吃两子的跳跃应为 2-5=-3，得到 %d", s)
+	}
+	retreat := Move{From: HexCoord{Q: -2, R: 0}, To: HexCoord{Q: -3, R: 0}}
+	if got := filterDangerousRecaptureJumps(b, PlayerA, []Move{jump, retreat}); len(got) != 1 || got[0] != retreat {
+		t.Fatalf("危险跳跃应被过滤，得到 %v", got)
+	}
+}
@@ -0,0 +1,215 @@
+// internal/game/solver.go
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 小棋盘完全求解。棋盘总是按 MaxBoardRadius 存，没有真正的变半径棋盘：
+// 半径 r 的小棋盘用障碍格堵住 r 以外的格子来模拟（SmallBoardState），规则与分数完全一致。
+// Solver 对 (走子方子集, 对方子集) 做带记忆的负极大值搜索，得到双方最优下的最终子数差，
+// 用作校验搜索/评估的标准答案，也可以当作小棋盘练习的"完美"对手（BotPerfect）。
+// 终局规则同 GameState.MakeMove / AdjudicateIfBlocked：轮到的一方无着可走时空格全归对方。
+//
+// 只解不跳跃（allowJump=false）的对局，与搜索的 filterJumpsByFlag 一致：没有克隆可走时才允许跳。
+// 克隆每一手都填掉一个空格，局面图基本无环，记忆化即可精确求解；
+// 双方轮流"只能跳"而绕回路径上局面的极端情况按和（0）截断。截断值取决于走到这里的路径，
+// 凡是用到截断的结果都不进记忆表，表里只有与路径无关的精确值。
+// 终局（轮到的一方无着可走）按对局自己的结算（GameState.settle）数子，规则改了这里跟着变。
+// 带跳跃时局面会成环，状态数也失控——实测半径 2 开局可达局面超过 3000 万，
+// 半径 4 只剩 3 个空格的残局也有数百万，完全求解不现实。
+// 不跳跃时半径 2 开局约 170 万个局面、1 秒左右；半径 3 开局解不动，只能解空格少的残局。
+
+// SolverMaxEmpties 默认求解的空格上限；半径 2 开局 13 空格
+const SolverMaxEmpties = 16
+
+// solverMemoLimit 记忆表超过这么多条就在下次求解前清空，免得长时间运行的进程越吃越多
+const solverMemoLimit = 1 << 22
+
+// ErrTooManyEmpties 空格多于 Solver.MaxEmpties，完全求解不现实
+var ErrTooManyEmpties = errors.New("solver: too many empty cells")
+
+// SmallBoardState 半径 radius（1..MaxBoardRadius）的棋盘开局：r 以外全部堵死，棋子放在小棋盘的六个角上。
+// radius = DefaultBoardRadius 时即标准棋盘（不含中心障碍）。
+func SmallBoardState(radius int) (*GameState, error) {
+	if err := CheckBoardRadius(radius); err != nil {
+		return nil, err
+	}
+	return NewGameStateWithBlocks(radius, nil), nil
+}
+
+// Solver 一个棋盘布局（障碍格）上的不跳跃完全求解器；记忆表跨调用保留，同一盘棋反复求解很快。
+// 只支持标准规则（不含 Cascade、CloneLimit），并发使用需各自 NewSolver。
+type Solver struct {
+	MaxEmpties int   // 空格上限，0 = 不限
+	Nodes      int64 // 累计展开的局面数
+
+	blocked CellMask
+	memo    map[[2]CellMask]int8 // {走子方, 对方} → 走子方视角的终局子数差
+	scratch *GameState           // 终局结算用的草稿局面，障碍格同 blocked
+}
+
+// NewSolver 空格上限取 SolverMaxEmpties
+func NewSolver() *Solver {
+	return &Solver{MaxEmpties: SolverMaxEmpties}
+}
+
+var jumpMask [BoardN]CellMask
+
+func ensureJumpMask() {
+	if !jumpMask[0].IsZero() {
+		return
+	}
+	for i := 0; i < BoardN; i++ {
+		var m CellMask
+		for _, j := range JumpI[i] {
+			m.set(j)
+		}
+		jumpMask[i] = m
+	}
+}
+
+func (s *Solver) prepare(b *Board) error {
+	if !rules.IsStandard() {
+		return fmt.Errorf("solver: rules %s not supported", rules)
+	}
+	if n := b.EmptyMask().Count(); s.MaxEmpties > 0 && n > s.MaxEmpties {
+		return fmt.Errorf("%w: %d > %d", ErrTooManyEmpties, n, s.MaxEmpties)
+	}
+	ensureJumpMask()
+	if s.memo == nil || s.blocked != b.reach.blocked || len(s.memo) > solverMemoLimit {
+		s.blocked = b.reach.blocked
+		s.memo = make(map[[2]CellMask]int8)
+		s.scratch = &GameState{Board: b.Clone(), CurrentPlayer: PlayerA}
+	}
+	return nil
+}
+
+// Solve side 走时双方都只克隆、都最优下的终局子数差（side 视角）
+func (s *Solver) Solve(b *Board, side CellState) (int, error) {
+	if err := s.prepare(b); err != nil {
+		return 0, err
+	}
+	my, op := boardMasks(b, side)
+	v, _ := s.negamax(my, op)
+	return int(v), nil
+}
+
+// SolveMoves side 每个着法的精确值（side 视角）；落到同一格的克隆只保留一个代表
+func (s *Solver) SolveMoves(b *Board, side CellState) ([]Move, []int, error) {
+	if err := s.prepare(b); err != nil {
+		return nil, nil, err
+	}
+	my, op := boardMasks(b, side)
+	var moves []Move
+	var vals []int
+	s.forEachMove(my, op, func(from, to int, nmy, nop CellMask) {
+		moves = append(moves, Move{From: CoordOf[from], To: CoordOf[to]})
+		v, _ := s.negamax(nop, nmy)
+		vals = append(vals, -int(v))
+	})
+	return moves, vals, nil
+}
+
+// BestMove side 的一个最优着法及其值；无着可走时 ok=false
+func (s *Solver) BestMove(b *Board, side CellState) (mv Move, val int, ok bool, err error) {
+	moves, vals, err := s.SolveMoves(b, side)
+	if err != nil || len(moves) == 0 {
+		return Move{}, 0, false, err
+	}
+	best := 0
+	for i := range vals {
+		if vals[i] > vals[best] {
+			best = i
+		}
+	}
+	return moves[best], vals[best], true, nil
+}
+
+// forEachMove 枚举 my 的着法，给出走完后的 (my, op)：有克隆时只给克隆（落到同一格的结果相同，只给一次），
+// 否则给全部跳跃
+func (s *Solver) forEachMove(my, op CellMask, fn func(from, to int, nmy, nop CellMask)) {
+	empty := my.Or(op).Or(s.blocked).Not()
+	cloned := false
+	for e := empty; !e.IsZero(); {
+		to := e.pop()
+		if src := NeighMask[to].And(my); !src.IsZero() {
+			cap := NeighMask[to].And(op)
+			fn(src.first(), to, my.Or(cellBit(to)).Or(cap), op.AndNot(cap))
+			cloned = true
+		}
+	}
+	if cloned {
+		return
+	}
+	for e := empty; !e.IsZero(); {
+		to := e.pop()
+		cap := NeighMask[to].And(op)
+		for j := jumpMask[to].And(my); !j.IsZero(); {
+			from := j.pop()
+			fn(from, to, my.AndNot(cellBit(from)).Or(cellBit(to)).Or(cap), op.AndNot(cap))
+		}
+	}
+}
+
+// solverOnPath 记忆表里"正在求解"的标记；再次遇到说明绕回来了
+const solverOnPath = int8(-128)
+
+// negamax my 走时的终局子数差；cut 表示结果用到了绕圈截断，与路径有关，不能记下
+func (s *Solver) negamax(my, op CellMask) (best int8, cut bool) {
+	key := [2]CellMask{my, op}
+	if v, ok := s.memo[key]; ok {
+		if v == solverOnPath {
+			return 0, true
+		}
+		return v, false
+	}
+	s.Nodes++
+	empty := my.Or(op).Or(s.blocked).Not()
+	var reach CellMask
+	for m := my; !m.IsZero(); {
+		i := m.pop()
+		reach = reach.Or(NeighMask[i]).Or(jumpMask[i])
+	}
+	if reach.And(empty).IsZero() {
+		// 无着可走（含无子、棋盘已满）
+		best = s.settle(my, op)
+		s.memo[key] = best
+		return best, false
+	}
+	s.memo[key] = solverOnPath
+	best = -BoardN
+	s.forEachMove(my, op, func(_, _ int, nmy, nop CellMask) {
+		v, c := s.negamax(nop, nmy)
+		cut = cut || c
+		if -v > best {
+			best = -v
+		}
+	})
+	if cut {
+		delete(s.memo, key)
+	} else {
+		s.memo[key] = best
+	}
+	return best, cut
+}
+
+// settle 轮到 my 却无着可走的终局：在草稿局面上摆出 (my, op)，按对局自己的结算数子，返回 my 视角的子数差。
+// 结算只看颜色对称，my 一律摆成 PlayerA
+func (s *Solver) settle(my, op CellMask) int8 {
+	gs := s.scratch
+	for m := s.blocked.Not(); !m.IsZero(); {
+		i := m.pop()
+		c := Empty
+		switch {
+		case my.Has(i):
+			c = PlayerA
+		case op.Has(i):
+			c = PlayerB
+		}
+		gs.Board.setI(i, c)
+	}
+	gs.settle(PlayerA)
+	return int8(gs.ScoreA - gs.ScoreB)
+}
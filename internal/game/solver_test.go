@@ -0,0 +1,100 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSmallBoardState(t *testing.T) {
+	gs, err := SmallBoardState(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := gs.Board
+	if n := BoardN - b.reach.blocked.Count(); n != 19 {
+		t.Fatalf("半径 2 应有 19 格可用，得到 %d", n)
+	}
+	if gs.ScoreA != 3 || gs.ScoreB != 3 || b.Cells[IndexOf[HexCoord{Q: 2, R: 0}]] != PlayerA {
+		t.Fatalf("开局棋子不对: A=%d B=%d", gs.ScoreA, gs.ScoreB)
+	}
+	if _, err := SmallBoardState(MaxBoardRadius + 1); err == nil {
+		t.Fatal("半径超出棋盘应报错")
+	}
+}
+
+func TestSolveRadius1(t *testing.T) {
+	gs, _ := SmallBoardState(1)
+	mv, v, ok, err := NewSolver().BestMove(gs.Board, PlayerA)
+	if err != nil || !ok {
+		t.Fatal(err)
+	}
+	// 唯一的空格在中心，落下去六个邻居全是 A
+	if v != 7 || mv.To != (HexCoord{}) {
+		t.Fatalf("应克隆到中心得 +7，得到 %v %d", mv, v)
+	}
+}
+
+// bruteForce 用 GameState.MakeMove 穷举不跳跃对局，返回 A 视角的终局子数差
+func bruteForce(gs *GameState) int {
+	if gs.GameOver {
+		return gs.ScoreA - gs.ScoreB
+	}
+	moves := filterJumpsByFlag(gs.Board, gs.CurrentPlayer, GenerateMoves(gs.Board, gs.CurrentPlayer), false)
+	best := 0
+	for i, mv := range moves {
+		ng := *gs
+		ng.Board = gs.Board.Clone()
+		if _, _, err := ng.MakeMove(mv); err != nil {
+			panic(err)
+		}
+		v := bruteForce(&ng)
+		if i == 0 || (gs.CurrentPlayer == PlayerA && v > best) || (gs.CurrentPlayer == PlayerB && v < best) {
+			best = v
+		}
+	}
+	return best
+}
+
+func TestSolverMatchesGameRules(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	s := NewSolver()
+	for trial := 0; trial < 6; trial++ {
+		gs, _ := SmallBoardState(2)
+		for !gs.GameOver && gs.Board.EmptyMask().Count() > 6 {
+			moves := filterJumpsByFlag(gs.Board, gs.CurrentPlayer, GenerateMoves(gs.Board, gs.CurrentPlayer), false)
+			if _, _, err := gs.MakeMove(moves[rng.Intn(len(moves))]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if gs.GameOver {
+			continue
+		}
+		v, err := s.Solve(gs.Board, gs.CurrentPlayer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gs.CurrentPlayer == PlayerB {
+			v = -v
+		}
+		if want := bruteForce(gs); v != want {
+			t.Fatalf("第 %d 局: 求解 %d，按规则穷举 %d\n%v", trial, v, want, gs.Board.Cells)
+		}
+	}
+}
+
+func TestPerfectBotPlaysOptimal(t *testing.T) {
+	gs, _ := SmallBoardState(2)
+	mv, ok, err := BaselineMove(BotPerfect, gs.Board, PlayerA, false, nil)
+	if err != nil || !ok {
+		t.Fatal(err)
+	}
+	s := NewSolver()
+	want, _ := s.Solve(gs.Board, PlayerA)
+	if _, _, err := gs.MakeMove(mv); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := s.Solve(gs.Board, PlayerB)
+	if -got != want {
+		t.Fatalf("perfect 走了 %v，之后值 %d，最优值 %d", mv, -got, want)
+	}
+}
@@ -13,12 +13,28 @@ type GameState struct {
 	ScoreB        int       // 玩家 B 的分数
 	GameOver      bool      // 游戏是否结束
 	Winner        CellState // 胜者 (PlayerA、PlayerB 或 Empty 表示平局)
+	BlockedPlayer CellState // 因无合法走法而结束时，被堵死的一方（否则为 Empty）
 
+	// Flips 每格（按下标）本局易手的次数，即被感染翻色的次数；只由 MakeMove 累计，不进 PositionString
+	Flips [BoardN]uint16
+}
+
+// DefaultBlocks 标准开局中心的三个障碍格
+var DefaultBlocks = []HexCoord{
+	{1, 0},
+	{-1, 1},
+	{0, -1},
 }
 
 // NewGameState 创建并初始化一个新的游戏状态，radius 是棋盘半径
 // 默认在六边形的三个角放置玩家 A 的棋子，在相对三个角放置玩家 B 的棋子
 func NewGameState(radius int) *GameState {
+	return NewGameStateWithBlocks(radius, DefaultBlocks)
+}
+
+// NewGameStateWithBlocks 同 NewGameState，但障碍格由调用方给出（演示模式轮换布局用）；
+// 与棋子落在同一格的障碍被忽略
+func NewGameStateWithBlocks(radius int, blocks []HexCoord) *GameState {
 	// 创建空棋盘
 	b := NewBoard(radius)
 	// 角落坐标 (A 方)
@@ -46,16 +62,13 @@ func NewGameState(radius int) *GameState {
 	}
 
 	// 放置障碍物
-	centerBlocks := []HexCoord{
-		{1, 0},
-		{-1, 1},
-		{0, -1},
-	}
-	for _, c := range centerBlocks {
-		if idx, ok := IndexOf[c]; ok {
+	for _, c := range blocks {
+		if idx, ok := IndexOf[c]; ok && b.Cells[idx] == Empty {
 			b.setI(idx, Blocked)
 		}
 	}
+	b.resetLineage()
+	b.resetPieceIDs()
 
 	// 构造 GameState
 	gs := &GameState{
@@ -141,8 +154,16 @@ func (gs *GameState) MakeMove(m Move) ([]HexCoord, undoInfo, error) {
 	// ★ 先记住这一步是谁在走
 	mover := gs.CurrentPlayer
 
+	// 盘外坐标/空起点等形状错误直接拒绝，不能静默跳过又把回合交出去
+	if r := moveShapeReason(gs.Board, mover, m); r != "" {
+		return nil, undoInfo{}, fmt.Errorf("非法走法 %v->%v: %s", m.From, m.To, r)
+	}
+
 	// 1) 执行克隆/跳跃并感染
 	infected, undo := m.MakeMove(gs.Board, mover)
+	for x := undo.infected; !x.IsZero(); {
+		gs.Flips[x.pop()]++
+	}
 
 	// ★ 立刻记录“上一手是谁 + 感染了多少”，供 UI/MCTS 使用
 	gs.Board.LastMover = mover
@@ -158,17 +179,16 @@ func (gs *GameState) MakeMove(m Move) ([]HexCoord, undoInfo, error) {
 
 	// 3) 计算“下一执子方”并检查他／她有没有合法走法
 	next := Opponent(gs.CurrentPlayer)
-	nextMoves := GenerateMoves(gs.Board, next)
+	nextBlocked := !gs.Board.HasMoves(next)
 
 	// —— 新增：对手无子可走，且棋盘还有空格 ——
-	if len(nextMoves) == 0 && emptyCnt > 0 {
-		// ① 把所有空格判给当前玩家
-		gs.claimAllEmpty(gs.CurrentPlayer)
-		// ② 重新统计分数
-		gs.updateScores()
+	if nextBlocked && emptyCnt > 0 {
+		// ①② 把所有空格判给当前玩家，重新统计分数
+		gs.settle(next)
 
 		// ③ 设置结束标记并决定赢家
 		gs.GameOver = true
+		gs.BlockedPlayer = next
 		if gs.ScoreA > gs.ScoreB {
 			gs.Winner = PlayerA
 		} else if gs.ScoreB > gs.ScoreA {
@@ -198,15 +218,14 @@ func (gs *GameState) MakeMove(m Move) ([]HexCoord, undoInfo, error) {
 		gs.ScoreA == 0 || // 一方无子
 			gs.ScoreB == 0 ||
 			emptyCnt == 0 || // 棋盘已满
-			(len(nextMoves) == 0) // 当前玩家走完后，下一方无合法着
+			nextBlocked // 当前玩家走完后，下一方无合法着
 
 	if gameEnds {
 		// 4.1 处理游戏结束时的分数
 		if gs.ScoreA == 0 || gs.ScoreB == 0 || emptyCnt == 0 {
 			// 如果是因为一方无子或棋盘已满，正常填充封闭区域并计算分数
-			gs.fillEnclosedRegions()
-			gs.updateScores()
-		} else if len(nextMoves) == 0 {
+			gs.settle(Empty)
+		} else if nextBlocked {
 			// 如果是因为下一玩家无合法走法，将所有空格分配给当前玩家
 			totalCells := len(gs.Board.AllCoords())
 			blockedCnt := 0
@@ -251,78 +270,86 @@ func (gs *GameState) MakeMove(m Move) ([]HexCoord, undoInfo, error) {
 	return infected, undo, nil
 }
 
+// HasLegalMoves 当前执子方是否还有合法走法
+func (gs *GameState) HasLegalMoves() bool {
+	return gs.Board.HasMoves(gs.CurrentPlayer)
+}
+
+// AdjudicateIfBlocked 处理“轮到的一方无路可走、对局却没结束”的局面（外部载入的局面等）。
+// 裁定规则与 MakeMove 中对手被堵死时相同：剩余空格全部判给另一方，对局结束。
+// 搜索入口（FindBestMoveAtDepth / IterativeDeepening 等）在此局面下返回 ok=false，
+// 调用方应以此结束对局，而不是等待一个不会到来的走法。返回是否发生了裁定。
+func (gs *GameState) AdjudicateIfBlocked() bool {
+	if gs.GameOver || gs.HasLegalMoves() {
+		return false
+	}
+	blocked := gs.CurrentPlayer
+	gs.settle(blocked)
+
+	gs.GameOver = true
+	gs.BlockedPlayer = blocked
+	switch {
+	case gs.ScoreA > gs.ScoreB:
+		gs.Winner = PlayerA
+	case gs.ScoreB > gs.ScoreA:
+		gs.Winner = PlayerB
+	default:
+		gs.Winner = Empty
+	}
+	return true
+}
+
 // GetScores 返回当前双方的分数 (A, B)
 func (gs *GameState) GetScores() (int, int) {
 	return gs.ScoreA, gs.ScoreB
 }
 
+// FlipCount 格子 c 本局易手的次数；盘外为 0
+func (gs *GameState) FlipCount(c HexCoord) int {
+	if i, ok := IndexOf[c]; ok {
+		return int(gs.Flips[i])
+	}
+	return 0
+}
+
+// MaxFlips 易手最多的格子的次数，用来归一化争夺热力图
+func (gs *GameState) MaxFlips() int {
+	m := 0
+	for _, n := range gs.Flips {
+		m = max(m, int(n))
+	}
+	return m
+}
+
 // Reset 重置游戏到初始状态，保留相同半径
 func (gs *GameState) Reset() {
-	radius := gs.Board.radius
+	radius := ActiveRadius(gs.Board)
 	newGs := NewGameState(radius)
 	*gs = *newGs
 }
 
+// settle 终局结算，MakeMove、AdjudicateIfBlocked 和 Solver 共用：blocked 为无着可走的一方时剩余空格全判给对方，
+// 为 Empty 时（一方无子或棋盘已满）把封闭区域填给包围方；最后重新统计分数
+func (gs *GameState) settle(blocked CellState) {
+	if blocked != Empty {
+		gs.claimAllEmpty(Opponent(blocked))
+	} else {
+		gs.fillEnclosedRegions()
+	}
+	gs.updateScores()
+}
+
 // fillEnclosedRegions 会把那些既不连通到棋盘最外圈、
-// 也只被单一方棋子（不含 Blocked）包围的空格区域填充给该包围方。
+// 也只被单一方棋子（不含 Blocked）包围的空格区域填充给该包围方（Region.Owner）。
 func (gs *GameState) fillEnclosedRegions() {
-	radius := gs.Board.radius
-	visited := make([]bool, BoardN)
-
-	for start := 0; start < BoardN; start++ {
-		// 只对未访问过且是空的格子做 BFS
-		if gs.Board.Cells[start] != Empty || visited[start] {
+	for _, r := range EmptyRegions(gs.Board) {
+		owner := r.Owner()
+		if owner == Empty {
 			continue
 		}
-
-		// BFS 初始化
-		queue := []int{start}
-		region := []int{start}
-		visited[start] = true
-
-		touchesBorder := false
-		borderA, borderB := false, false // 代替 map[CellState]bool
-
-		for len(queue) > 0 {
-			cur := queue[0]
-			queue = queue[1:]
-
-			cq := CoordOf[cur].Q
-			cr := CoordOf[cur].R
-			if abs(cq) == radius || abs(cr) == radius || abs(cq+cr) == radius {
-				touchesBorder = true
-			}
-
-			for _, nb := range NeighI[cur] {
-				s := gs.Board.Cells[nb]
-				switch s {
-				case Empty:
-					if !visited[nb] {
-						visited[nb] = true
-						queue = append(queue, nb)
-						region = append(region, nb)
-					}
-				case Blocked:
-					continue
-				case PlayerA:
-					borderA = true
-				case PlayerB:
-					borderB = true
-				}
-			}
-		}
-
-		// 检查区域是否封闭，且边界只有一种棋子
-		if !touchesBorder && (borderA != borderB) {
-			var owner CellState
-			if borderA {
-				owner = PlayerA
-			} else {
-				owner = PlayerB
-			}
-			for _, idx := range region {
-				gs.Board.setI(idx, owner) // 用 setI 保证 hash 同步
-			}
+		for _, idx := range r.Cells() {
+			gs.Board.setI(idx, owner) // 用 setI 保证 hash 同步
+			gs.Board.syncPieceID(idx)
 		}
 	}
 }
@@ -332,6 +359,7 @@ func (gs *GameState) claimAllEmpty(to CellState) {
 	for i := 0; i < BoardN; i++ {
 		if gs.Board.Cells[i] == Empty {
 			gs.Board.setI(i, to) // 用 setI 保证 hash 同步
+			gs.Board.syncPieceID(i)
 		}
 	}
 }
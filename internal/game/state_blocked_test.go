@@ -0,0 +1,102 @@
+package game
+
+import "testing"
+
+// blockedBState 构造一个 B 方唯一棋子被堵死的局面：
+// 除 B 子 (-4,0) 与两个远处空格外全是 A 子，空格与 B 子距离 ≥ 3。
+func blockedBState(t *testing.T, toMove CellState) *GameState {
+	t.Helper()
+	b := NewBoard(boardRadius)
+	for i := 0; i < CellCount(boardRadius); i++ {
+		b.setI(i, PlayerA)
+	}
+	b.setI(IndexOf[HexCoord{Q: -4, R: 0}], PlayerB)
+	b.setI(IndexOf[HexCoord{Q: 4, R: 0}], Empty)
+	b.setI(IndexOf[HexCoord{Q: 4, R: -1}], Empty)
+
+	gs := &GameState{Board: b, CurrentPlayer: toMove}
+	gs.updateScores()
+	if len(GenerateMoves(b, PlayerB)) != 0 {
+		t.Fatalf("测试局面构造错误：B 仍有走法")
+	}
+	return gs
+}
+
+func TestSearchReturnsNotOKWhenBlocked(t *testing.T) {
+	gs := blockedBState(t, PlayerB)
+
+	if _, ok := FindBestMoveAtDepth(gs.Board, PlayerB, 1, true); ok {
+		t.Fatalf("FindBestMoveAtDepth: 无合法走法时应返回 ok=false")
+	}
+	if _, _, ok := IterativeDeepening(gs.Board, PlayerB, 2, false); ok {
+		t.Fatalf("IterativeDeepening: 无合法走法时应返回 ok=false")
+	}
+	if _, ok := FindBestMoveMCTS(gs.Board, PlayerB, 16, 0, true); ok {
+		t.Fatalf("FindBestMoveMCTS: 无合法走法时应返回 ok=false")
+	}
+}
+
+func TestAdjudicateIfBlocked(t *testing.T) {
+	gs := blockedBState(t, PlayerB)
+
+	if !gs.AdjudicateIfBlocked() {
+		t.Fatalf("B 被堵死时应发生裁定")
+	}
+	if !gs.GameOver || gs.Winner != PlayerA || gs.BlockedPlayer != PlayerB {
+		t.Fatalf("裁定结果错误: over=%v winner=%v blocked=%v", gs.GameOver, gs.Winner, gs.BlockedPlayer)
+	}
+	if gs.ScoreA != CellCount(boardRadius)-1 || gs.ScoreB != 1 {
+		t.Fatalf("空格应全部判给 A: A=%d B=%d", gs.ScoreA, gs.ScoreB)
+	}
+	if gs.AdjudicateIfBlocked() {
+		t.Fatalf("已结束的对局不应重复裁定")
+	}
+}
+
+func TestAdjudicateIfBlockedNoop(t *testing.T) {
+	gs := blockedBState(t, PlayerA) // A 有走法
+	if gs.AdjudicateIfBlocked() || gs.GameOver {
+		t.Fatalf("执子方有走法时不应裁定")
+	}
+}
+
+func TestMakeMoveEndsGameWhenOpponentBlocked(t *testing.T) {
+	gs := blockedBState(t, PlayerA)
+
+	mv := Move{From: HexCoord{Q: 3, R: 0}, To: HexCoord{Q: 4, R: 0}}
+	if _, _, err := gs.MakeMove(mv); err != nil {
+		t.Fatalf("MakeMove: %v", err)
+	}
+	if !gs.GameOver || gs.Winner != PlayerA || gs.BlockedPlayer != PlayerB {
+		t.Fatalf("对手被堵死应结束对局: over=%v winner=%v blocked=%v", gs.GameOver, gs.Winner, gs.BlockedPlayer)
+	}
+}
+
+func TestNewGameStateWithBlocks(t *testing.T) {
+	std, custom := NewGameState(4), NewGameStateWithBlocks(4, DefaultBlocks)
+	if std.Board.Hash() != custom.Board.Hash() || std.Board.Cells != custom.Board.Cells {
+		t.Fatal("DefaultBlocks 应与 NewGameState 完全一致")
+	}
+
+	open := NewGameStateWithBlocks(4, nil)
+	for i := 0; i < CellCount(4); i++ {
+		if open.Board.Cells[i] == Blocked {
+			t.Fatalf("无障碍布局不应有障碍格: %v", CoordOf[i])
+		}
+	}
+	if open.Board.Hash() == std.Board.Hash() {
+		t.Fatal("障碍格应计入哈希")
+	}
+
+	// 压在起始棋子上的障碍被忽略
+	over := NewGameStateWithBlocks(4, []HexCoord{{Q: 4, R: 0}, {Q: 0, R: 0}})
+	if got := over.Board.Cells[IndexOf[HexCoord{Q: 4, R: 0}]]; got != PlayerA {
+		t.Fatalf("角上的 A 子被障碍覆盖: %v", got)
+	}
+	if got := over.Board.Cells[IndexOf[HexCoord{Q: 0, R: 0}]]; got != Blocked {
+		t.Fatalf("中心应为障碍: %v", got)
+	}
+	if !over.Board.HasMoves(PlayerA) || !over.Board.HasMoves(PlayerB) {
+		t.Fatal("自定义布局开局双方都应有着")
+	}
+}
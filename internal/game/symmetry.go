@@ -0,0 +1,126 @@
+// game/symmetry.go
+package game
+
+// 六边形棋盘的 12 个对称变换（6 个旋转 × 是否镜像），以格子下标置换表的形式预计算。
+// 开局局面自身对称，根层很多着法互为镜像/旋转，只需搜索每个等价类里的一个。
+// 导出的 Transform* 给训练数据做增广：同一个样本的 12 个对称像都是合法且等价的样本。
+
+const numSymmetries = 12
+
+// NumSymmetries 对称变换个数。编号 t：0 为恒等，t%6 为顺时针旋转 60° 的次数，t>=6 时先镜像
+const NumSymmetries = numSymmetries
+
+// symPerm[t][i]：格子 i 经变换 t 后的下标；t=0 为恒等变换
+var symPerm [numSymmetries][BoardN]int
+
+// symGrid[t][g]：9×9 网格（AxialToIndex）下标 g 经变换 t 后的下标；盘外格映射到自身
+var symGrid [numSymmetries][GridSize * GridSize]int
+
+// transformCoord：先按需镜像（交换 q、r），再顺时针旋转 rot×60°
+func transformCoord(c HexCoord, rot int, mirror bool) HexCoord {
+	q, r := c.Q, c.R
+	if mirror {
+		q, r = r, q
+	}
+	for k := 0; k < rot; k++ {
+		q, r = -r, q+r
+	}
+	return HexCoord{Q: q, R: r}
+}
+
+func initSymmetryTables() {
+	for t := 0; t < numSymmetries; t++ {
+		for i := 0; i < BoardN; i++ {
+			j, ok := IndexOf[transformCoord(CoordOf[i], t%6, t >= 6)]
+			if !ok {
+				panic("symmetry maps a cell off the board")
+			}
+			symPerm[t][i] = j
+		}
+		for g := range symGrid[t] {
+			symGrid[t][g] = g
+		}
+		for i := 0; i < nnCells; i++ {
+			symGrid[t][AxialToIndex(CoordOf[i])] = AxialToIndex(CoordOf[symPerm[t][i]])
+		}
+	}
+}
+
+// TransformCoord 坐标 c 经变换 t 后的位置
+func TransformCoord(c HexCoord, t int) HexCoord { return transformCoord(c, t%6, t >= 6) }
+
+// TransformMove 着法的起点、终点一起变换
+func TransformMove(m Move, t int) Move {
+	return Move{From: TransformCoord(m.From, t), To: TransformCoord(m.To, t)}
+}
+
+// TransformBoard 返回 b 经变换 t 后的新棋盘（棋子、障碍一起变换，上一手也跟着变换）
+func TransformBoard(b *Board, t int) *Board {
+	var cells [BoardN]CellState
+	for i := 0; i < BoardN; i++ {
+		cells[symPerm[t][i]] = b.Cells[i]
+	}
+	nb, _ := BoardFromCells(cells[:]) // 取值来自合法棋盘，不会出错
+	nb.LastMove = TransformMove(b.LastMove, t)
+	nb.LastMover, nb.LastInfect = b.LastMover, b.LastInfect
+	return nb
+}
+
+// TransformPolicy 按落点格索引（AxialToIndex，9×9）的策略经变换 t 后的新切片
+func TransformPolicy(p []float32, t int) []float32 { return TransformGrid(p, t) }
+
+// TransformGrid 任意 9×9 网格量（策略、归属）经变换 t 后的新切片，g 长度须为 GridSize²；盘外格原样保留
+func TransformGrid[T any](g []T, t int) []T {
+	out := make([]T, len(g))
+	for i, v := range g {
+		out[symGrid[t][i]] = v
+	}
+	return out
+}
+
+// boardSymmetries 返回使 b 保持不变（含颜色、障碍）的变换，恒等变换总在第一个
+func boardSymmetries(b *Board) []int {
+	syms := []int{0}
+	for t := 1; t < numSymmetries; t++ {
+		p := &symPerm[t]
+		same := true
+		for i := 0; i < BoardN; i++ {
+			if b.Cells[p[i]] != b.Cells[i] {
+				same = false
+				break
+			}
+		}
+		if same {
+			syms = append(syms, t)
+		}
+	}
+	return syms
+}
+
+// dedupSymmetricMoves 在 b 存在非平凡对称时，每个等价类只保留第一个出现的着法。
+// 对称局面基本只出现在开局，其余情况只多一次 12×61 的比较。
+func dedupSymmetricMoves(b *Board, moves []Move) []Move {
+	syms := boardSymmetries(b)
+	if len(syms) == 1 || len(moves) < 2 {
+		return moves
+	}
+	seen := make(map[[2]int]bool, len(moves))
+	out := moves[:0]
+	for _, mv := range moves {
+		from, to := IndexOf[mv.From], IndexOf[mv.To]
+		// 等价类的代表：所有对称像中 (from,to) 字典序最小者
+		key := [2]int{from, to}
+		for _, t := range syms[1:] {
+			k := [2]int{symPerm[t][from], symPerm[t][to]}
+			if k[0] < key[0] || (k[0] == key[0] && k[1] < key[1]) {
+				key = k
+			}
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, mv)
+	}
+	return out
+}
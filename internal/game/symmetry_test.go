@@ -0,0 +1,89 @@
+package game
+
+import "testing"
+
+func TestSymmetryTablesArePermutations(t *testing.T) {
+	for s := 0; s < numSymmetries; s++ {
+		var seen [BoardN]bool
+		for i := 0; i < BoardN; i++ {
+			j := symPerm[s][i]
+			if seen[j] {
+				t.Fatalf("变换 %d 不是置换：格子 %d 被映射两次", s, j)
+			}
+			seen[j] = true
+		}
+	}
+}
+
+func TestDedupSymmetricMovesOpening(t *testing.T) {
+	b := NewGameState(boardRadius).Board
+	syms := boardSymmetries(b)
+	if len(syms) < 2 {
+		t.Fatalf("开局局面应存在非平凡对称，得到 %v", syms)
+	}
+
+	all := GenerateMoves(b, PlayerA)
+	dedup := dedupSymmetricMoves(b, append([]Move(nil), all...))
+	if len(dedup)*len(syms) < len(all) {
+		t.Fatalf("去重过度: %d 个着法去重后 %d，对称数 %d", len(all), len(dedup), len(syms))
+	}
+	if len(dedup) >= len(all) {
+		t.Fatalf("开局着法未去重: %d -> %d", len(all), len(dedup))
+	}
+
+	// 每个原着法都必须能由某个保留着法经对称变换得到
+	kept := make(map[[2]int]bool, len(dedup))
+	for _, mv := range dedup {
+		kept[[2]int{IndexOf[mv.From], IndexOf[mv.To]}] = true
+	}
+	for _, mv := range all {
+		from, to := IndexOf[mv.From], IndexOf[mv.To]
+		found := false
+		for _, s := range syms {
+			if kept[[2]int{symPerm[s][from], symPerm[s][to]}] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Fatalf("着法 %v->%v 的等价类被整个丢掉", mv.From, mv.To)
+		}
+	}
+}
+
+// 走子与对称变换可交换：先走再变换 = 先变换再走变换后的着法；策略随落点一起搬
+func TestTransformBoardCommutesWithMoves(t *testing.T) {
+	for n, b := range symTestBoards(t) {
+		moves := GenerateMoves(b, PlayerA)
+		for s := 0; s < NumSymmetries; s++ {
+			tb := TransformBoard(b, s)
+			if tb.CountPieces(PlayerA) != b.CountPieces(PlayerA) || tb.CountPieces(Blocked) != b.CountPieces(Blocked) {
+				t.Fatalf("局面 %d 变换 %d: 子数变了", n, s)
+			}
+			if got := len(GenerateMoves(tb, PlayerA)); got != len(moves) {
+				t.Fatalf("局面 %d 变换 %d: 着法数 %d，原 %d", n, s, got, len(moves))
+			}
+			for _, mv := range moves {
+				after := b.Clone()
+				after.ApplyMove(mv, PlayerA)
+				want := TransformBoard(after, s)
+				got := tb.Clone()
+				got.ApplyMove(TransformMove(mv, s), PlayerA)
+				if got.Cells != want.Cells || got.Hash() != want.Hash() {
+					t.Fatalf("局面 %d 变换 %d 着法 %v: 走子与变换不可交换", n, s, mv)
+				}
+			}
+
+			policy := make([]float32, GridSize*GridSize)
+			for i, mv := range moves {
+				policy[AxialToIndex(mv.To)] += float32(i + 1)
+			}
+			tp := TransformPolicy(policy, s)
+			for _, mv := range moves {
+				if tp[AxialToIndex(TransformMove(mv, s).To)] != policy[AxialToIndex(mv.To)] {
+					t.Fatalf("局面 %d 变换 %d: 落点 %v 的策略没跟着搬", n, s, mv.To)
+				}
+			}
+		}
+	}
+}
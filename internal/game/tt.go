@@ -4,13 +4,11 @@ import (
 	"math/rand"
 	"sync"
 	"sync/atomic"
-	"time"
 )
 
-// -------- 参数：按需调大 --------
-const ttBuckets = 1 << 21 // 桶数量（2M 桶）
-const ttWays = 4          // 组相联路数：2 或 4
-const ttMask = ttBuckets - 1
+// -------- 参数：桶数可用 ResizeTT 运行时调整 --------
+const ttDefaultBuckets = 1 << 21 // 默认桶数量（2M 桶，256MB）
+const ttWays = 4                 // 组相联路数：2 或 4
 
 type ttFlag uint8
 
@@ -27,6 +25,7 @@ type ttEntry struct {
 	depth   int32   // 搜索深度
 	flag    ttFlag  // 类型
 	bestIdx uint8   // 走法索引（可选）
+	gen     uint8   // 写入时的 ttGen（低 8 位），统计占用率和挑替换槽用
 	key     uint64  // 原子发布（最后写）
 	_       [8]byte // 简单填充，减小伪共享（可按需调到 64B）
 }
@@ -35,9 +34,12 @@ var zobristSide [2]uint64
 var zobristStage [2]uint64               // stage 0/1
 var zobristSelected [BoardN]uint64       // 已选子（stage==1 时混入）
 var (
-	ttTable         = make([][ttWays]ttEntry, ttBuckets)
+	ttTable         = make([][ttWays]ttEntry, ttDefaultBuckets)
+	ttMask          = uint64(ttDefaultBuckets - 1)
 	ttProbeCount    uint64
 	ttHitCount      uint64
+	ttStoreCount    uint64 // storeTT 次数
+	ttReplaceCount  uint64 // 其中挤掉了本代另一个局面的次数
 	onceZobristInit sync.Once
 )
 var (
@@ -45,48 +47,85 @@ var (
 	hexCoordToIndex map[HexCoord]int
 )
 
+// zobristBlank 全空盘（BoardN 格都是 Empty）的 hash，新棋盘从它开始再用 setI 摆格子。
+// 它是半径 5、6 那些格子的 Empty 键之和：这些格子是 Blocked 时 hash 里不含它们，
+// 盘外全是障碍的标准盘 hash 与只有半径 4 时相同，PositionKey 不变
+var zobristBlank uint64
+
 var zobCell [BoardN][4]uint64           // [index][state]
 func zobKeyI(i int, s CellState) uint64 { return zobristCell[i][s] }
 
-var ttSalt uint64 // 与 zobrist/side xor 组成最终 key
-// init 在程序启动时执行一次，生成所有随机键。
+// zobrist 键由固定种子生成，跨进程、跨机器一致，持久化的 TT / 开局库 / 局面 key 才能复用。
+// 改动生成顺序或种子会让所有已存的 key 作废，必须同时递增 zobristVersion。
+const (
+	zobristVersion = 1
+	zobristSeed    = 0x48584741_5a4f4231 // "HXGAZOB1"
+)
+
+// ttSalt 与 zobrist/side xor 组成最终 key，只用于 ClearTT 让旧表项失效：
+// 取值为“代数 × 奇常数”，初始代为 0，即不加盐时 TT key 就是 PositionKey。
+var ttSalt uint64
+
+const ttSaltStep = 0x9e3779b97f4a7c15
+
+// ttGen 每次 ClearTT 加一；表项记下写入时的代（低 8 位），旧代的表项视为空槽。
+// 只存 8 位，隔 256 代会撞上，统计略偏高、替换略保守，不影响正确性（key 里有盐）
+var ttGen uint32
+
+// init 在程序启动时执行一次，生成所有键。
 func init() {
 	initBoardTables()
 	initZobrist()
 	initEncodeTables()
-	// 初始化一个随机盐，避免进程内碰撞
-	atomic.StoreUint64(&ttSalt, rand.Uint64()|1) // 确保非零
+	initSymmetryTables()
 }
 func initZobrist() {
 	onceZobristInit.Do(func() {
-		// 1) Seed the RNG for reproducible randomness
-		rand.Seed(time.Now().UnixNano())
+		// 1) 独立的确定性 RNG，不影响也不依赖全局 rand 的播种
+		rng := rand.New(rand.NewSource(zobristSeed))
 
 		// 2) Build per-cell Zobrist keys
-		coords := AllCoords(boardRadius)
-		zobristCell = make([][4]uint64, len(coords))
-		hexCoordToIndex = make(map[HexCoord]int, len(coords))
-		for i, c := range coords {
-			hexCoordToIndex[c] = i
-			zobristCell[i] = [4]uint64{
-				rand.Uint64(), // Empty
-				0,             // Blocked (never participates)
-				rand.Uint64(), // PlayerA
-				rand.Uint64(), // PlayerB
+		// 标准盘的 61 格、行棋方、阶段、已选子按半径 4 时的顺序先取，半径 5、6 多出的格子接在最后，
+		// 标准盘的 key 与只有半径 4 时完全相同
+		std := CellCount(boardRadius)
+		zobristCell = make([][4]uint64, BoardN)
+		hexCoordToIndex = make(map[HexCoord]int, BoardN)
+		cellKeys := func(lo, hi int) {
+			for i := lo; i < hi; i++ {
+				hexCoordToIndex[CoordOf[i]] = i
+				zobristCell[i] = [4]uint64{
+					rng.Uint64(), // Empty
+					0,            // Blocked (never participates)
+					rng.Uint64(), // PlayerA
+					rng.Uint64(), // PlayerB
+				}
 			}
 		}
+		cellKeys(0, std)
 
 		// 3) Build side-to-move Zobrist keys
-		zobristSide[0] = rand.Uint64() // PlayerA to move
-		zobristSide[1] = rand.Uint64() // PlayerB to move
+		zobristSide[0] = rng.Uint64() // PlayerA to move
+		zobristSide[1] = rng.Uint64() // PlayerB to move
 		zobristStage[0] = 0
-		zobristStage[1] = rand.Uint64()
-		for i := 0; i < BoardN; i++ {
-			zobristSelected[i] = rand.Uint64()
+		zobristStage[1] = rng.Uint64()
+		for i := 0; i < std; i++ {
+			zobristSelected[i] = rng.Uint64()
+		}
+
+		// 4) 半径 5、6 的格子
+		cellKeys(std, BoardN)
+		for i := std; i < BoardN; i++ {
+			zobristSelected[i] = rng.Uint64()
+			zobristBlank ^= zobristCell[i][Empty]
 		}
 	})
 }
 
+// PositionKey 返回局面（棋盘 + 执子方）的 zobrist key，同一 zobristVersion 下跨进程稳定
+func PositionKey(b *Board, current CellState) uint64 {
+	return b.hash ^ zobristSide[sideIdx(current)]
+}
+
 func ttKeyFor(b *Board, current CellState) uint64 {
 	return b.hash ^ zobristSide[sideIdx(current)] ^ atomic.LoadUint64(&ttSalt)
 }
@@ -103,12 +142,69 @@ func ttKeyForTwoPhase(b *Board, current CellState, stage int, selectedIdx int) u
 	return key
 }
 
+// ClearTT 让所有现存表项失效（O(1)，不清内存）。表项默认跨着法保留，
+// 只有评估器变化或调用方想要“冷启动”的搜索时才需要调用，见 tt_persist.go。
 func ClearTT() {
 	// 换个盐：让所有旧 key 立刻无法命中
-	atomic.AddUint64(&ttSalt, 1)
+	atomic.AddUint64(&ttSalt, ttSaltStep)
+	atomic.AddUint32(&ttGen, 1)
 	// 统计计数也一起清零
+	resetTTCounters()
+}
+
+func resetTTCounters() {
 	atomic.StoreUint64(&ttProbeCount, 0)
 	atomic.StoreUint64(&ttHitCount, 0)
+	atomic.StoreUint64(&ttStoreCount, 0)
+	atomic.StoreUint64(&ttReplaceCount, 0)
+}
+
+const ttBucketBytes = ttWays * 32 // unsafe.Sizeof(ttEntry{}) == 32
+
+// ResizeTT 把置换表换成不超过 megabytes MB 的最大 2 的幂桶数（至少 1MB），返回实际 MB 数。
+// 新表是空的，原有表项全部丢弃。与 ApplyOptions 一样，必须在没有搜索在跑时调用
+func ResizeTT(megabytes int) int {
+	if megabytes < 1 {
+		megabytes = 1
+	}
+	n := 1
+	for uint64(n)*2*ttBucketBytes <= uint64(megabytes)<<20 {
+		n *= 2
+	}
+	if n != len(ttTable) {
+		ttTable = make([][ttWays]ttEntry, n)
+		ttMask = uint64(n - 1)
+	}
+	ClearTT()
+	return TTSizeMB()
+}
+
+// TTSizeMB 当前置换表大小（MB）
+func TTSizeMB() int {
+	return len(ttTable) * ttBucketBytes >> 20
+}
+
+// ttHashFullSample HashFull 抽样的桶数：与 UCI 引擎一样只看表头一小段，足够估计且开销固定
+const ttHashFullSample = 1000
+
+// HashFull 置换表占用率（千分比，0..1000），只算本代（上次 ClearTT 之后）写入的表项，
+// 即 UCI 引擎 "info hashfull" 的含义。抽样表头的桶，可在搜索进行中调用
+func HashFull() int {
+	n := ttHashFullSample
+	if n > len(ttTable) {
+		n = len(ttTable)
+	}
+	gen := uint8(atomic.LoadUint32(&ttGen))
+	used := 0
+	for bi := 0; bi < n; bi++ {
+		for w := 0; w < ttWays; w++ {
+			e := &ttTable[bi][w]
+			if atomic.LoadUint32(&e.version) != 0 && e.gen == gen {
+				used++
+			}
+		}
+	}
+	return used * 1000 / (n * ttWays)
 }
 
 // 读：循环直到拿到稳定快照（version 偶数且前后一致）
@@ -147,25 +243,42 @@ func probeTT(key uint64, needDepth int) (bool, int, ttFlag) {
 	return false, 0, 0
 }
 
-// 写：优先覆盖同 key；否则覆盖“更浅深度”的槽；再不行覆盖 0 号
+// 写：优先覆盖同 key；否则覆盖空槽或旧代的槽；再不行覆盖“更浅深度”的槽
 func storeTT(key uint64, depth, score int, flag ttFlag) {
 	b := &ttTable[key&ttMask]
+	gen := uint8(atomic.LoadUint32(&ttGen))
+	atomic.AddUint64(&ttStoreCount, 1)
 
 	// 1) 找到要写的路
-	slot := 0
+	slot, stale := 0, -1
 	bestDepth := int(^uint(0) >> 1) // +Inf
+	same := false
 	for w := 0; w < ttWays; w++ {
 		e := &b[w]
 		if atomic.LoadUint64(&e.key) == key {
-			slot = w
+			slot, same = w, true
 			break
 		}
+		if atomic.LoadUint32(&e.version) == 0 || e.gen != gen {
+			// 空槽或 ClearTT 之前的表项：再也命中不了，最先让出
+			if stale < 0 {
+				stale = w
+			}
+			continue
+		}
 		d := int(atomic.LoadInt32(&e.depth))
 		if d < bestDepth {
 			bestDepth = d
 			slot = w
 		}
 	}
+	if !same {
+		if stale >= 0 {
+			slot = stale
+		} else {
+			atomic.AddUint64(&ttReplaceCount, 1) // 挤掉本代的另一个局面
+		}
+	}
 
 	e := &b[slot]
 	// 2) seqlock: version++(odd) → 写字段 → 写 key → version++(even)
@@ -175,6 +288,7 @@ func storeTT(key uint64, depth, score int, flag ttFlag) {
 	atomic.StoreInt32(&e.score, int32(score))
 	atomic.StoreInt32(&e.depth, int32(depth))
 	e.flag = flag // 非原子 OK
+	e.gen = gen
 	// bestIdx 留给 storeBestIdx 来写或置 0
 	atomic.StoreUint64(&e.key, key)
 
@@ -215,6 +329,7 @@ func storeBestIdx(key uint64, idxBest uint8) {
 	}
 }
 
+// GetTTStats 自上次 ClearTT / ResetSearchStats 以来的探测数、命中数和命中率（%）
 func GetTTStats() (probes, hits uint64, rate float64) {
 	probes = atomic.LoadUint64(&ttProbeCount)
 	hits = atomic.LoadUint64(&ttHitCount)
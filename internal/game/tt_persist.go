@@ -0,0 +1,224 @@
+// game/tt_persist.go
+package game
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+)
+
+// 置换表生命周期约定：
+//   - 表项在整个进程内跨着法保留（同一盘棋后续着法可直接复用上一手的搜索结果）；
+//   - 评估器身份变化（切换 ONNX/静态、换模型、换规则变体）时自动 ClearTT，避免混用不同量纲的分数；
+//   - 长时间分析可用 SaveTT/LoadTT 落盘，文件头带版本、zobrist 指纹和评估器身份，任一不符即拒绝加载。
+
+const (
+	ttFileMagic   = "HXTT"
+	ttFileVersion = 2 // v2：key 不含盐，指纹包含 zobristVersion
+)
+
+// ErrTTIncompatible：TT 文件与当前进程的 zobrist 键或评估器不匹配
+var ErrTTIncompatible = errors.New("tt file incompatible")
+
+var ttEvaluator atomic.Value // string：当前表项是用哪个评估器算出来的
+
+// EvaluatorID 描述当前叶子评估器；分数只在同一 EvaluatorID 下可比
+func EvaluatorID() string {
+	return EvaluatorIDFor(UseONNXForPlayerA, UseONNXForPlayerB)
+}
+
+// EvaluatorIDFor 同 EvaluatorID，但 ONNX 开关取 nnA/nnB 而不是全局的；只算身份，不改开关
+func EvaluatorIDFor(nnA, nnB bool) string {
+	id := "bitboard-v4" // v2、v3：每个叶子都算已锁定地盘；v4：只在残局算
+	if parityW != 0 {
+		id += fmt.Sprintf("+parity%d", parityW) // 叶子分数含残局奇偶项
+	}
+	if nnA || nnB {
+		if ensureKataONNX() == nil {
+			id = fmt.Sprintf("kata-%08x:A=%v,B=%v", katagoModelSum, nnA, nnB)
+		}
+	}
+	if !rules.IsStandard() {
+		id += "+" + rules.String() // 同一局面在不同规则下分数不同
+	}
+	return id
+}
+
+// syncTTEvaluator 在评估器变化时清空 TT；搜索入口调用
+func syncTTEvaluator() {
+	id := EvaluatorID()
+	prev, _ := ttEvaluator.Load().(string)
+	if prev == id {
+		return
+	}
+	if prev != "" {
+		ClearTT()
+	}
+	ttEvaluator.Store(id)
+}
+
+// zobristFingerprint 把全部 zobrist 键折叠成一个指纹；键不同则 TT 文件中的 key 无意义
+func zobristFingerprint() uint64 {
+	h := uint64(1469598103934665603)
+	mix := func(v uint64) {
+		h ^= v
+		h *= 1099511628211
+	}
+	for i := range zobristCell {
+		for _, k := range zobristCell[i] {
+			mix(k)
+		}
+	}
+	mix(zobristSide[0])
+	mix(zobristSide[1])
+	mix(zobristVersion)
+	return h
+}
+
+type ttFileHeader struct {
+	Version     uint32
+	Fingerprint uint64
+	Entries     uint64
+}
+
+type ttFileEntry struct {
+	Key     uint64
+	Score   int32
+	Depth   int32
+	Flag    uint8
+	BestIdx uint8
+}
+
+// SaveTT 把当前 TT 的有效表项写到 path
+func SaveTT(path string) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	// 文件里存不含盐的 key（即 PositionKey），与保存时处于第几代 TT 无关
+	entries := snapshotTT()
+	salt := atomic.LoadUint64(&ttSalt)
+	for i := range entries {
+		entries[i].Key ^= salt
+	}
+	w := bufio.NewWriter(f)
+	if _, err = w.WriteString(ttFileMagic); err != nil {
+		return err
+	}
+	if err = writeTTString(w, EvaluatorID()); err != nil {
+		return err
+	}
+	hdr := ttFileHeader{
+		Version:     ttFileVersion,
+		Fingerprint: zobristFingerprint(),
+		Entries:     uint64(len(entries)),
+	}
+	if err = binary.Write(w, binary.LittleEndian, hdr); err != nil {
+		return err
+	}
+	if err = binary.Write(w, binary.LittleEndian, entries); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// LoadTT 读取 SaveTT 的文件并写入当前 TT；不兼容时返回包装了 ErrTTIncompatible 的错误
+func LoadTT(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+
+	magic := make([]byte, len(ttFileMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != ttFileMagic {
+		return 0, fmt.Errorf("%s: not a TT file", path)
+	}
+	evalID, err := readTTString(r)
+	if err != nil {
+		return 0, err
+	}
+	var hdr ttFileHeader
+	if err := binary.Read(r, binary.LittleEndian, &hdr); err != nil {
+		return 0, err
+	}
+	switch {
+	case hdr.Version != ttFileVersion:
+		return 0, fmt.Errorf("%w: version %d, want %d", ErrTTIncompatible, hdr.Version, ttFileVersion)
+	case hdr.Fingerprint != zobristFingerprint():
+		return 0, fmt.Errorf("%w: zobrist keys differ", ErrTTIncompatible)
+	case evalID != EvaluatorID():
+		return 0, fmt.Errorf("%w: evaluator %q, current %q", ErrTTIncompatible, evalID, EvaluatorID())
+	}
+
+	entries := make([]ttFileEntry, hdr.Entries)
+	if err := binary.Read(r, binary.LittleEndian, entries); err != nil {
+		return 0, err
+	}
+	salt := atomic.LoadUint64(&ttSalt)
+	for _, e := range entries {
+		key := e.Key ^ salt
+		storeTT(key, int(e.Depth), int(e.Score), ttFlag(e.Flag))
+		storeBestIdx(key, e.BestIdx)
+	}
+	ttEvaluator.Store(evalID)
+	return len(entries), nil
+}
+
+// snapshotTT 收集当前代所有稳定且非空的表项（写入中的跳过）。
+// ClearTT 之前的表项 key 里是旧盐，按当前盐去盐会变成乱码 key，必须丢掉
+func snapshotTT() []ttFileEntry {
+	out := make([]ttFileEntry, 0, 1<<16)
+	gen := uint8(atomic.LoadUint32(&ttGen))
+	for bi := range ttTable {
+		for w := 0; w < ttWays; w++ {
+			e := &ttTable[bi][w]
+			v1 := atomic.LoadUint32(&e.version)
+			if v1 == 0 || v1&1 == 1 || e.gen != gen {
+				continue
+			}
+			fe := ttFileEntry{
+				Key:     atomic.LoadUint64(&e.key),
+				Score:   atomic.LoadInt32(&e.score),
+				Depth:   atomic.LoadInt32(&e.depth),
+				Flag:    uint8(e.flag),
+				BestIdx: e.bestIdx,
+			}
+			if atomic.LoadUint32(&e.version) == v1 {
+				out = append(out, fe)
+			}
+		}
+	}
+	return out
+}
+
+func writeTTString(w io.Writer, s string) error {
+	if err := binary.Write(w, binary.LittleEndian, uint16(len(s))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, s)
+	return err
+}
+
+func readTTString(r io.Reader) (string, error) {
+	var n uint16
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return "", err
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,62 @@
+package game
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveLoadTTRoundTrip(t *testing.T) {
+	ClearTT()
+	storeTT(ttKeyFor(NewGameState(boardRadius).Board, PlayerB), 5, 77, ttExact) // 上一代的表项，不应存进文件
+	ClearTT()
+	key := ttKeyFor(NewGameState(boardRadius).Board, PlayerA)
+	storeTT(key, 7, 123, ttExact)
+	storeBestIdx(key, 3)
+
+	path := filepath.Join(t.TempDir(), "tt.bin")
+	if err := SaveTT(path); err != nil {
+		t.Fatalf("SaveTT: %v", err)
+	}
+
+	ClearTT() // 换盐：旧 key 失效，加载时应按新盐重写
+	key = ttKeyFor(NewGameState(boardRadius).Board, PlayerA)
+	if hit, _, _ := probeTT(key, 7); hit {
+		t.Fatalf("ClearTT 之后不应命中")
+	}
+
+	if n, err := LoadTT(path); err != nil || n != 1 {
+		t.Fatalf("LoadTT = %d, %v；期望只有当前代的 1 项", n, err)
+	}
+	hit, score, flag := probeTT(key, 7)
+	if !hit || score != 123 || flag != ttExact {
+		t.Fatalf("加载后应命中原表项: hit=%v score=%d flag=%v", hit, score, flag)
+	}
+	if ok, idx := probeBestIdx(key); !ok || idx != 3 {
+		t.Fatalf("bestIdx 丢失: %v %d", ok, idx)
+	}
+}
+
+func TestLoadTTRejectsOtherEvaluator(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tt.bin")
+	if err := SaveTT(path); err != nil {
+		t.Fatalf("SaveTT: %v", err)
+	}
+	// 篡改评估器身份串的第一个字节
+	data, _ := os.ReadFile(path)
+	data[len(ttFileMagic)+2] ^= 0xff
+	os.WriteFile(path, data, 0o644)
+
+	if _, err := LoadTT(path); !errors.Is(err, ErrTTIncompatible) {
+		t.Fatalf("期望 ErrTTIncompatible，得到 %v", err)
+	}
+}
+
+// zobrist 键必须跨进程稳定；这里失败说明键的生成方式变了，需要递增 zobristVersion 并更新期望值
+func TestPositionKeyPinned(t *testing.T) {
+	gs := NewGameState(boardRadius)
+	if got, want := PositionKey(gs.Board, PlayerA), uint64(0x7fb53433e45d6cb2); got != want {
+		t.Fatalf("开局 PositionKey = %#x，期望 %#x", got, want)
+	}
+}
@@ -0,0 +1,79 @@
+package game
+
+import "testing"
+
+// withTTSize 测试里换小表，结束后恢复原大小
+func withTTSize(t *testing.T, mb int) {
+	t.Helper()
+	prev := TTSizeMB()
+	if got := ResizeTT(mb); got != mb {
+		t.Fatalf("ResizeTT(%d) = %d", mb, got)
+	}
+	t.Cleanup(func() { ResizeTT(prev) })
+}
+
+func TestResizeTT(t *testing.T) {
+	withTTSize(t, 1)
+	if len(ttTable) != 1<<20/ttBucketBytes || ttMask != uint64(len(ttTable)-1) {
+		t.Fatalf("1MB 应为 %d 桶: len=%d mask=%#x", 1<<20/ttBucketBytes, len(ttTable), ttMask)
+	}
+	for mb, want := range map[int]int{0: 1, 3: 2, 4: 4, 100: 64} {
+		if got := ResizeTT(mb); got != want {
+			t.Errorf("ResizeTT(%d) = %d，期望 %d", mb, got, want)
+		}
+	}
+
+	ResizeTT(1)
+	key := uint64(12345)
+	storeTT(key, 3, 7, ttExact)
+	if hit, score, _ := probeTT(key, 3); !hit || score != 7 {
+		t.Fatal("换表后应能正常读写")
+	}
+	ResizeTT(2)
+	if hit, _, _ := probeTT(key, 0); hit {
+		t.Fatal("换表后原有表项应丢弃")
+	}
+}
+
+// 同一桶内的第 w 路用 key = bucket + w<<32，桶号只取低位
+func ttTestKey(bucket, w int) uint64 { return uint64(bucket) | uint64(w+1)<<32 }
+
+func TestHashFullAndReplaceStats(t *testing.T) {
+	withTTSize(t, 1)
+	if HashFull() != 0 {
+		t.Fatalf("新表 hashfull = %d", HashFull())
+	}
+	for bi := 0; bi < ttHashFullSample/2; bi++ {
+		for w := 0; w < ttWays; w++ {
+			storeTT(ttTestKey(bi, w), w+1, 0, ttExact)
+		}
+	}
+	if got := HashFull(); got != 500 {
+		t.Fatalf("抽样桶写满一半，hashfull = %d，期望 500", got)
+	}
+	s := GetSearchStats()
+	if s.TTStores != ttHashFullSample/2*ttWays || s.TTReplace != 0 || s.HashFull != 500 {
+		t.Fatalf("只写空槽不算替换: stores=%d replace=%d hashfull=%d", s.TTStores, s.TTReplace, s.HashFull)
+	}
+
+	// 第 5 个局面挤掉桶里最浅的一项
+	storeTT(ttTestKey(0, ttWays), 9, 0, ttExact)
+	if s := GetSearchStats(); s.TTReplace != 1 {
+		t.Fatalf("replace = %d，期望 1", s.TTReplace)
+	}
+	if hit, _, _ := probeTT(ttTestKey(0, 0), 0); hit {
+		t.Fatal("应替换深度最浅的第 0 路")
+	}
+
+	// ClearTT 之后旧表项不计占用，新局面先占旧代的槽，不算替换
+	ClearTT()
+	if HashFull() != 0 {
+		t.Fatalf("ClearTT 后 hashfull = %d", HashFull())
+	}
+	for w := 0; w < ttWays; w++ {
+		storeTT(ttTestKey(1, w+ttWays), 0, 0, ttExact)
+	}
+	if s := GetSearchStats(); s.TTReplace != 0 || s.TTStores != ttWays {
+		t.Fatalf("旧代的槽应直接复用: stores=%d replace=%d", s.TTStores, s.TTReplace)
+	}
+}
@@ -0,0 +1,144 @@
+// game/validate.go
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 外部输入（回放 JSON、布局文件、网络走法）不可信：先过这一层再交给 MakeMove。
+
+// MoveError 描述序列中第一步非法走法
+type MoveError struct {
+	Ply    int // 第几手（从 0 开始）
+	Move   Move
+	Player CellState
+	Reason string
+}
+
+func (e *MoveError) Error() string {
+	return fmt.Sprintf("ply %d: %s %v->%v: %s", e.Ply, playerName(e.Player), e.Move.From, e.Move.To, e.Reason)
+}
+
+func playerName(p CellState) string {
+	switch p {
+	case PlayerA:
+		return "A"
+	case PlayerB:
+		return "B"
+	}
+	return fmt.Sprintf("side(%d)", int(p))
+}
+
+// moveIllegalReason 返回 m 对 side 非法的原因；合法时返回空串。
+// 最终以 GenerateMoves 为准，前面的检查只为给出可读的原因。
+func moveIllegalReason(b *Board, side CellState, m Move) string {
+	if r := moveShapeReason(b, side, m); r != "" {
+		return r
+	}
+	for _, mv := range GenerateMoves(b, side) {
+		if mv == m {
+			return ""
+		}
+	}
+	return "not generated by GenerateMoves"
+}
+
+// moveShapeReason 只做不分配的检查：坐标在盘上、起点是己方子、落点为空、距离 1 或 2，
+// 克隆上限规则下克隆还要有剩余次数。
+// GameState.MakeMove 每步都过这一层，完整校验（含 GenerateMoves）留给 ValidateMove。
+func moveShapeReason(b *Board, side CellState, m Move) string {
+	fromIdx, okFrom := IndexOf[m.From]
+	toIdx, okTo := IndexOf[m.To]
+	switch {
+	case !okFrom:
+		return "from is off the board"
+	case !okTo:
+		return "to is off the board"
+	case b.Cells[fromIdx] != side:
+		return fmt.Sprintf("from holds %s, not the mover's piece", cellName(b.Cells[fromIdx]))
+	case b.Cells[toIdx] != Empty:
+		return fmt.Sprintf("to is %s, not empty", cellName(b.Cells[toIdx]))
+	}
+	d := HexDist(m.From, m.To)
+	if d != 1 && d != 2 {
+		return fmt.Sprintf("distance %d, must be 1 (clone) or 2 (jump)", d)
+	}
+	if d == 1 && !b.canCloneI(fromIdx) {
+		return fmt.Sprintf("this piece's line has used all %d clones", rules.CloneLimit)
+	}
+	return ""
+}
+
+func cellName(s CellState) string {
+	switch s {
+	case Empty:
+		return "empty"
+	case Blocked:
+		return "blocked"
+	case PlayerA:
+		return "A"
+	case PlayerB:
+		return "B"
+	}
+	return fmt.Sprintf("invalid(%d)", int(s))
+}
+
+// ValidateMove 检查 m 对 gs 当前执子方是否合法
+func ValidateMove(gs *GameState, m Move) error {
+	if gs.GameOver {
+		return errors.New("game is already over")
+	}
+	if r := moveIllegalReason(gs.Board, gs.CurrentPlayer, m); r != "" {
+		return errors.New(r)
+	}
+	return nil
+}
+
+// ReplayMoves 从 start（nil = 标准开局）出发逐手校验并执行 moves，不修改 start。
+// 遇到第一步非法走法时返回此前的状态和 *MoveError。
+func ReplayMoves(start *GameState, moves []Move) (*GameState, error) {
+	var gs *GameState
+	if start == nil {
+		gs = NewGameState(boardRadius)
+	} else {
+		cp := *start
+		cp.Board = start.Board.Clone()
+		gs = &cp
+	}
+	for ply, m := range moves {
+		if err := ValidateMove(gs, m); err != nil {
+			return gs, &MoveError{Ply: ply, Move: m, Player: gs.CurrentPlayer, Reason: err.Error()}
+		}
+		if _, _, err := gs.MakeMove(m); err != nil {
+			return gs, &MoveError{Ply: ply, Move: m, Player: gs.CurrentPlayer, Reason: err.Error()}
+		}
+	}
+	return gs, nil
+}
+
+// BoardFromCells 从外部布局（按 CoordOf 顺序的格子数组）构造棋盘，
+// 非法取值报错；哈希与位板经 setI 重新计算，不信任外部数据。
+// 长度是半径 4、5、6 的格数之一（61、91、127），之后的格子都是障碍。
+func BoardFromCells(cells []CellState) (*Board, error) {
+	radius := boardRadius
+	for radius <= MaxBoardRadius && CellCount(radius) != len(cells) {
+		radius++
+	}
+	if radius > MaxBoardRadius {
+		return nil, fmt.Errorf("layout has %d cells, want %d, %d or %d", len(cells),
+			CellCount(boardRadius), CellCount(boardRadius+1), CellCount(MaxBoardRadius))
+	}
+	b := NewBoard(radius)
+	for i, s := range cells {
+		switch s {
+		case Empty, Blocked, PlayerA, PlayerB:
+			b.setI(i, s)
+		default:
+			return nil, fmt.Errorf("cell %d %v: invalid state %d", i, CoordOf[i], int(s))
+		}
+	}
+	b.resetLineage()
+	b.resetPieceIDs()
+	return b, nil
+}
@@ -0,0 +1,54 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReplayMovesReportsPly(t *testing.T) {
+	gs := NewGameState(boardRadius)
+	first := GenerateMoves(gs.Board, PlayerA)[0]
+	gs.MakeMove(first)
+	second := GenerateMoves(gs.Board, PlayerB)[0]
+
+	// 第 2 手（ply=2）让 B 再走一次：轮次不对，应报错并指出 ply
+	bad := []Move{first, second, second}
+	_, err := ReplayMoves(nil, bad)
+	var me *MoveError
+	if !errors.As(err, &me) {
+		t.Fatalf("期望 *MoveError，得到 %v", err)
+	}
+	if me.Ply != 2 || me.Move != second || me.Player != PlayerA {
+		t.Fatalf("错误信息不对: %+v", me)
+	}
+
+	st, err := ReplayMoves(nil, bad[:2])
+	if err != nil {
+		t.Fatalf("合法序列不应报错: %v", err)
+	}
+	gs.MakeMove(second)
+	if st.Board.Cells != gs.Board.Cells || st.CurrentPlayer != gs.CurrentPlayer {
+		t.Fatalf("重放结果与直接落子不一致")
+	}
+}
+
+func TestBoardFromCellsRejectsInvalid(t *testing.T) {
+	gs := NewGameState(boardRadius)
+	cells := gs.Board.Cells[:]
+	b, err := BoardFromCells(cells)
+	if err != nil {
+		t.Fatalf("合法布局不应报错: %v", err)
+	}
+	if b.CountPieces(PlayerA) != gs.Board.CountPieces(PlayerA) {
+		t.Fatalf("位板未同步")
+	}
+
+	bad := append([]CellState(nil), cells...)
+	bad[5] = CellState(9)
+	if _, err := BoardFromCells(bad); err == nil {
+		t.Fatalf("非法取值应报错")
+	}
+	if _, err := BoardFromCells(cells[:10]); err == nil {
+		t.Fatalf("长度不对应报错")
+	}
+}
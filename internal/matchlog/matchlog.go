@@ -0,0 +1,157 @@
+// Package matchlog 引擎对战工具共用的逐手对局日志（JSON Lines）。
+//
+// battle_eval_nn、tournament、selfplay 用 -matchlog 打开后，每走一手写一行 move 记录，
+// 每局结束写一行 end 记录；同一局的行连续写出。分析脚本和报告生成只需认这一种格式：
+//
+//	{"event":"move","tool":"tournament","game":3,"ply":0,"side":"red","player":"go-d2",
+//	 "hash":"9f3c0a1be2d47781","move":"-4,0>-3,0","depth":2,"time_ms":12.5,"nodes":3180}
+//	{"event":"end","tool":"tournament","game":3,"ply":57,"winner":"white"}
+//
+// 字段缺省即“该工具/引擎不提供”：MCTS 没有深度与节点数，α-β 没有模拟次数。
+package matchlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"hexxagon_go/internal/engine"
+	"hexxagon_go/internal/game"
+)
+
+const (
+	EventMove = "move"
+	EventEnd  = "end"
+)
+
+// Record 日志的一行
+type Record struct {
+	Event  string  `json:"event"`
+	Tool   string  `json:"tool"`
+	Game   int     `json:"game"`
+	Ply    int     `json:"ply"`              // move: 从 0 起的手数；end: 总手数
+	Side   string  `json:"side,omitempty"`   // 走子方 red/white
+	Player string  `json:"player,omitempty"` // 走子方的引擎或设置名
+	Hash   string  `json:"hash,omitempty"`   // 走子前局面的 Board.Hash（不含执子方），16 位十六进制
+	Move   string  `json:"move,omitempty"`   // 与引擎协议相同的 "q,r>q,r"
+	Depth  int     `json:"depth,omitempty"`  // α-β 完成的深度
+	Sims   int     `json:"sims,omitempty"`   // MCTS 模拟次数
+	TimeMS float64 `json:"time_ms,omitempty"`
+	Nodes  int64   `json:"nodes,omitempty"`
+
+	Comment string `json:"comment,omitempty"` // move: 给人看的一行解说，见 game.CommentMove
+
+	Winner string `json:"winner,omitempty"` // end: red/white/draw
+	Reason string `json:"reason,omitempty"` // end: 非正常结束的原因（崩溃、超时、手数上限等）
+}
+
+// SideName 日志里的执子方名字
+func SideName(s game.CellState) string {
+	switch s {
+	case game.PlayerA:
+		return "red"
+	case game.PlayerB:
+		return "white"
+	}
+	return "draw"
+}
+
+// MoveRecord 填好局面相关字段的 move 记录；b 是走子前的局面
+func MoveRecord(b *game.Board, side game.CellState, mv game.Move, ply int, took time.Duration) Record {
+	return Record{
+		Event:  EventMove,
+		Ply:    ply,
+		Side:   SideName(side),
+		Hash:   fmt.Sprintf("%016x", b.Hash()),
+		Move:   engine.FormatMove(mv),
+		TimeMS: float64(took.Microseconds()) / 1000,
+
+		Comment: game.CommentMove(b, side, mv),
+	}
+}
+
+// EndRecord 一局的结束记录；winner 为 game.Empty 表示和棋
+func EndRecord(plies int, winner game.CellState, reason string) Record {
+	return Record{Event: EventEnd, Ply: plies, Winner: SideName(winner), Reason: reason}
+}
+
+// Writer 并发安全；nil Writer 的方法什么都不做，调用方不必判断是否开启
+type Writer struct {
+	mu   sync.Mutex
+	f    *os.File
+	bw   *bufio.Writer
+	tool string
+}
+
+// Create 新建日志文件；path 为空时返回 nil（不记录）
+func Create(path, tool string) (*Writer, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{f: f, bw: bufio.NewWriter(f), tool: tool}, nil
+}
+
+// Append 同 Create，但文件已存在时接在末尾写（续跑中断的生成任务）
+func Append(path, tool string) (*Writer, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{f: f, bw: bufio.NewWriter(f), tool: tool}, nil
+}
+
+// WriteGame 写出一局的全部记录并刷盘：各局的行不会交错，tail -f 也能及时看到
+func (w *Writer) WriteGame(gameNo int, recs []Record) error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	enc := json.NewEncoder(w.bw)
+	enc.SetEscapeHTML(false) // 走法里的 '>' 原样写出
+	for _, r := range recs {
+		r.Tool, r.Game = w.tool, gameNo
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return w.bw.Flush()
+}
+
+// Close 刷盘并关闭文件
+func (w *Writer) Close() error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return errors.Join(w.bw.Flush(), w.f.Close())
+}
+
+// Read 逐行解析日志，每条记录交给 fn；fn 返回错误时停止
+func Read(r io.Reader, fn func(Record) error) error {
+	dec := json.NewDecoder(r)
+	for {
+		var rec Record
+		if err := dec.Decode(&rec); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package matchlog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"hexxagon_go/internal/game"
+)
+
+func TestWriteGameRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "games.jsonl")
+	w, err := Create(path, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := game.NewGameState(4)
+	mv := game.GenerateMoves(st.Board, game.PlayerA)[0]
+	rec := MoveRecord(st.Board, game.PlayerA, mv, 0, 1500*time.Microsecond)
+	rec.Depth, rec.Nodes = 2, 100
+	if err := w.WriteGame(7, []Record{rec, EndRecord(1, game.Empty, "max plies")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var got []Record
+	if err := Read(f, func(r Record) error { got = append(got, r); return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("应读回 2 行，得到 %d", len(got))
+	}
+	m := got[0]
+	if m.Event != EventMove || m.Tool != "test" || m.Game != 7 || m.Side != "red" || m.Depth != 2 || m.TimeMS != 1.5 {
+		t.Fatalf("move 记录不对: %+v", m)
+	}
+	if len(m.Hash) != 16 || !strings.HasPrefix(m.Comment, "Red clones") {
+		t.Fatalf("hash 应为 16 位十六进制: %+v", m)
+	}
+	if e := got[1]; e.Event != EventEnd || e.Winner != "draw" || e.Reason != "max plies" {
+		t.Fatalf("end 记录不对: %+v", e)
+	}
+}
+
+func TestNilWriterIsNoop(t *testing.T) {
+	w, err := Create("", "test")
+	if err != nil || w != nil {
+		t.Fatalf("空路径应返回 nil: %v %v", w, err)
+	}
+	if err := w.WriteGame(1, []Record{{Event: EventMove}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
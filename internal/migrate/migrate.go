@@ -0,0 +1,138 @@
+// Package migrate 带版本号的 JSON 文件（存档、录像、参数文件）的升级登记表。
+//
+// 每种格式一个 Format：文件里的 "version" 字段记它是第几版，每次改格式就把 Current 加一，
+// 并登记一个把上一版升到这一版的 Step。读文件时先解成通用的 Doc，从文件的版本一步步升到 Current，
+// 再交给正常的 json.Unmarshal；老版本的 Go 结构体不用留着，老文件也一直能读。
+//
+//	var settings = migrate.NewFormat("settings", 2, 1) // 没有 version 的老文件当第 1 版
+//	settings.Register(1, func(d migrate.Doc) error { d["depth"] = d["max_depth"]; delete(d, "max_depth"); return nil })
+//	from, err := settings.Decode(data, &cfg)
+//
+// 数字按 json.Number 保留原样（纳秒时长、哈希等大整数不经过 float64），Step 里读数字用 Int。
+// 每一版都应在使用方的 testdata 里留一个样例文件，测试逐个读一遍，防止以后的改动把老版本读坏。
+package migrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// VersionKey 文件里记版本号的字段
+const VersionKey = "version"
+
+// Doc 解成通用结构的一个 JSON 对象
+type Doc = map[string]any
+
+// Step 把 Doc 从第 n 版原地改成第 n+1 版；VersionKey 由 Format 维护，Step 不用管
+type Step func(Doc) error
+
+// Format 一种文件格式的版本信息与升级步骤
+type Format struct {
+	Name        string
+	Current     int // 当前写出的版本
+	Unversioned int // 没有 version 字段的文件当第几版（加版本号之前写出的老文件）；0 = 不接受
+	steps       map[int]Step
+}
+
+// NewFormat 登记一种格式；current >= 1
+func NewFormat(name string, current, unversioned int) *Format {
+	if current < 1 || unversioned < 0 || unversioned > current {
+		panic(fmt.Sprintf("migrate: %s: bad versions current=%d unversioned=%d", name, current, unversioned))
+	}
+	return &Format{Name: name, Current: current, Unversioned: unversioned, steps: map[int]Step{}}
+}
+
+// Register 登记从第 from 版升到 from+1 版的步骤；版本越界或重复登记直接 panic（属于编程错误）
+func (f *Format) Register(from int, step Step) *Format {
+	if from < 1 || from >= f.Current {
+		panic(fmt.Sprintf("migrate: %s: step from version %d outside 1..%d", f.Name, from, f.Current-1))
+	}
+	if _, dup := f.steps[from]; dup {
+		panic(fmt.Sprintf("migrate: %s: step from version %d registered twice", f.Name, from))
+	}
+	f.steps[from] = step
+	return f
+}
+
+// Version 读 doc 的版本号；没有版本号时返回 Unversioned
+func (f *Format) Version(doc Doc) (int, error) {
+	v, ok := doc[VersionKey]
+	if !ok {
+		if f.Unversioned == 0 {
+			return 0, fmt.Errorf("%s: missing %s", f.Name, VersionKey)
+		}
+		return f.Unversioned, nil
+	}
+	n, err := Int(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %s: %w", f.Name, VersionKey, err)
+	}
+	return n, nil
+}
+
+// Upgrade 把 doc 原地升到 Current，返回它原来的版本
+func (f *Format) Upgrade(doc Doc) (from int, err error) {
+	if from, err = f.Version(doc); err != nil {
+		return 0, err
+	}
+	switch {
+	case from < 1:
+		return from, fmt.Errorf("%s: invalid version %d", f.Name, from)
+	case from > f.Current:
+		return from, fmt.Errorf("%s: version %d is newer than supported %d", f.Name, from, f.Current)
+	}
+	for v := from; v < f.Current; v++ {
+		step, ok := f.steps[v]
+		if !ok {
+			return from, fmt.Errorf("%s: no migration from version %d", f.Name, v)
+		}
+		if err := step(doc); err != nil {
+			return from, fmt.Errorf("%s: migrate %d -> %d: %w", f.Name, v, v+1, err)
+		}
+	}
+	doc[VersionKey] = f.Current
+	return from, nil
+}
+
+// Decode 解析一个 JSON 对象，升到 Current 后解进 out；返回文件原来的版本
+func (f *Format) Decode(data []byte, out any) (from int, err error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc Doc
+	if err := dec.Decode(&doc); err != nil {
+		return 0, err
+	}
+	if doc == nil {
+		return 0, fmt.Errorf("%s: not a JSON object", f.Name)
+	}
+	_, versioned := doc[VersionKey]
+	if from, err = f.Upgrade(doc); err != nil {
+		return from, err
+	}
+	if versioned && from == f.Current {
+		return from, json.Unmarshal(data, out) // 已是当前版本：不必再编码一遍
+	}
+	up, err := json.Marshal(doc)
+	if err != nil {
+		return from, err
+	}
+	return from, json.Unmarshal(up, out)
+}
+
+// Int 把 Doc 里的数字（json.Number、float64 或 int）转成 int
+func Int(v any) (int, error) {
+	switch n := v.(type) {
+	case int:
+		return n, nil
+	case float64:
+		if n != float64(int(n)) {
+			return 0, fmt.Errorf("not an integer: %v", n)
+		}
+		return int(n), nil
+	case json.Number:
+		i, err := n.Int64()
+		return int(i), err
+	}
+	return 0, fmt.Errorf("not a number: %v", v)
+}
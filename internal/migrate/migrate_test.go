@@ -0,0 +1,73 @@
+package migrate
+
+import (
+	"strings"
+	"testing"
+)
+
+type settingsV3 struct {
+	Version int   `json:"version"`
+	Depth   int   `json:"depth"`
+	Budget  int64 `json:"budget_ns"`
+	Jump    bool  `json:"jump"`
+}
+
+// 第 1 版叫 max_depth，第 2 版改名 depth，第 3 版加了默认开启的 jump
+func settingsFormat() *Format {
+	return NewFormat("settings", 3, 1).
+		Register(1, func(d Doc) error {
+			d["depth"] = d["max_depth"]
+			delete(d, "max_depth")
+			return nil
+		}).
+		Register(2, func(d Doc) error {
+			d["jump"] = true
+			return nil
+		})
+}
+
+func TestDecodeUpgradesEachVersion(t *testing.T) {
+	f := settingsFormat()
+	for _, c := range []struct {
+		in   string
+		from int
+	}{
+		{`{"max_depth": 4, "budget_ns": 9007199254740993}`, 1}, // 没有 version：当第 1 版
+		{`{"version": 1, "max_depth": 4, "budget_ns": 9007199254740993}`, 1},
+		{`{"version": 2, "depth": 4, "budget_ns": 9007199254740993}`, 2},
+		{`{"version": 3, "depth": 4, "budget_ns": 9007199254740993, "jump": true}`, 3},
+	} {
+		var s settingsV3
+		from, err := f.Decode([]byte(c.in), &s)
+		if err != nil {
+			t.Fatalf("%s: %v", c.in, err)
+		}
+		want := settingsV3{Version: 3, Depth: 4, Budget: 9007199254740993, Jump: true}
+		if from != c.from || s != want {
+			t.Fatalf("%s: from=%d %+v，应为 from=%d %+v", c.in, from, s, c.from, want)
+		}
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	f := settingsFormat()
+	for in, want := range map[string]string{
+		`{"version": 4}`:   "newer",
+		`{"version": 0}`:   "invalid",
+		`{"version": "x"}`: "version",
+		`[1, 2]`:           "",
+	} {
+		var s settingsV3
+		if _, err := f.Decode([]byte(in), &s); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: 期望含 %q 的错误，得到 %v", in, want, err)
+		}
+	}
+
+	strict := NewFormat("strict", 2, 0)
+	if _, err := strict.Decode([]byte(`{}`), &struct{}{}); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Fatalf("不接受无版本号的格式应报 missing，得到 %v", err)
+	}
+	if _, err := strict.Decode([]byte(`{"version": 1}`), &struct{}{}); err == nil || !strings.Contains(err.Error(), "no migration") {
+		t.Fatalf("缺少升级步骤应报错，得到 %v", err)
+	}
+}
@@ -86,6 +86,10 @@ func ensureInit() error {
 			return
 		}
 		defer sessOpts.Destroy()
+		if err := game.ApplyORTConfig(sessOpts); err != nil {
+			initErr = err
+			return
+		}
 
 		gpuEnabled := false
 		if runtime.GOOS == "darwin" {
@@ -0,0 +1,97 @@
+// Package net 联机对战：两台机器上的玩家通过 TCP 对下一盘。
+//
+// 一行一条消息，字段以空格分隔，双方对等：
+//
+//	hello <版本>                 连上后双方各发一次；版本不同即断开
+//	game <pos> <side> <rules>    主机紧接着发：<pos> 为开局 GameState.PositionString（含地图），
+//	                             <side> 为客户端执的一方（A/B），<rules> 为规则名（见 game.ParseRules）
+//	sync <n>                     握手时双方各发一次：自己已有 n 手；对方据此补发第 n 手起的 move。
+//	                             收到的 move 手数对不上时也会发，让对方从缺口重发
+//	move <ply> <走法>             第 ply 手（从 0 起），走法格式同 engine.FormatMove
+//	state <n> <pos>              发出自己的一手后紧跟着发：走完第 n 手后的局面（PositionString）。
+//	                             对方走到同一手时用 Board.Diff 核对，不一致的格子记进 Desync
+//	ping / pong                  心跳；ReadTimeout 内没收到任何消息视为断线
+//	bye                          对方主动退出，不再重连
+//
+// 断线后主机继续在原地址监听等对方回来，客户端每隔 RetryInterval 重拨；
+// 重连握手里的 sync 补齐断线期间没送到的着法，重复的 move 按 ply 丢弃。
+// 双方各自维护一份按着法推进的局面，对方发来的着法先过 game.ValidateMove。
+// 只有 TCP：WebSocket 要引入新依赖，暂未实现；一个会话只下一盘，不支持悔棋和再来一局。
+package net
+
+import (
+	"fmt"
+	"strconv"
+
+	"hexxagon_go/internal/engine"
+	"hexxagon_go/internal/game"
+)
+
+// protocolVersion hello 里的协议版本，改了消息格式就加一
+const protocolVersion = 2
+
+func formatSide(s game.CellState) string {
+	if s == game.PlayerB {
+		return "B"
+	}
+	return "A"
+}
+
+func parseSide(s string) (game.CellState, error) {
+	switch s {
+	case "A":
+		return game.PlayerA, nil
+	case "B":
+		return game.PlayerB, nil
+	}
+	return game.Empty, fmt.Errorf("side %q: want A or B", s)
+}
+
+func formatMoveMsg(ply int, mv game.Move) string {
+	return fmt.Sprintf("move %d %s", ply, engine.FormatMove(mv))
+}
+
+func parseMoveMsg(args []string) (int, game.Move, error) {
+	if len(args) != 2 {
+		return 0, game.Move{}, fmt.Errorf("move: want 2 arguments, got %d", len(args))
+	}
+	ply, err := strconv.Atoi(args[0])
+	if err != nil || ply < 0 {
+		return 0, game.Move{}, fmt.Errorf("move: bad ply %q", args[0])
+	}
+	mv, err := engine.ParseMove(args[1])
+	if err != nil {
+		return 0, game.Move{}, err
+	}
+	return ply, mv, nil
+}
+
+func formatStateMsg(n int, gs *game.GameState) string {
+	return fmt.Sprintf("state %d %s", n, gs.PositionString())
+}
+
+func parseStateMsg(args []string) (int, *game.GameState, error) {
+	if len(args) != 2 {
+		return 0, nil, fmt.Errorf("state: want 2 arguments, got %d", len(args))
+	}
+	n, err := parseCount("state", args[:1])
+	if err != nil {
+		return 0, nil, err
+	}
+	gs, err := game.ParsePosition(args[1])
+	if err != nil {
+		return 0, nil, fmt.Errorf("state: %w", err)
+	}
+	return n, gs, nil
+}
+
+func parseCount(cmd string, args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("%s: want 1 argument, got %d", cmd, len(args))
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s: bad count %q", cmd, args[0])
+	}
+	return n, nil
+}
@@ -0,0 +1,565 @@
+package net
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	stdnet "net"
+	"strings"
+	"sync"
+	"time"
+
+	"hexxagon_go/internal/game"
+)
+
+// 心跳、超时与重拨间隔；测试里调小
+var (
+	PingInterval     = 2 * time.Second
+	ReadTimeout      = 10 * time.Second // 这么久没收到任何消息视为断线
+	RetryInterval    = time.Second      // 客户端断线后的重拨间隔
+	HandshakeTimeout = 5 * time.Second  // 拨号与握手的时限，也用作单次写的时限
+)
+
+// Status 连接状态
+type Status int
+
+const (
+	Connecting   Status = iota // 主机等对方第一次连入
+	Connected                  // 已连上
+	Reconnecting               // 断线：主机等对方回来，客户端在重拨
+	PeerLeft                   // 对方发了 bye，不会再回来
+	Closed                     // 本地已 Close
+)
+
+func (s Status) String() string {
+	switch s {
+	case Connecting:
+		return "connecting"
+	case Connected:
+		return "connected"
+	case Reconnecting:
+		return "reconnecting"
+	case PeerLeft:
+		return "peer left"
+	case Closed:
+		return "closed"
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// Session 一盘联机对局的一端。着法双向按 ply 编号，断线重连后自动补齐。
+type Session struct {
+	mu     sync.Mutex
+	wmu    sync.Mutex        // 串行化连接上的写：一组消息不被别的写插进来。Send 写网络时只拿它，不占 mu
+	remote game.CellState    // 对方执的一方
+	rules  game.Rules        // 主机的规则；客户端握手时得到
+	start  *game.GameState   // 开局；客户端握手时得到
+	state  *game.GameState   // 开局按 moves 推进的副本，用来校验双方着法
+	moves  []game.Move       // 双方已走的全部着法
+	inbox  []game.Move       // 已收到、还没被 Poll 取走的对方着法
+	desync []game.CellChange // 最近一次 state 核对出的差异（本方 → 对方）；一致时为空
+	conn   stdnet.Conn       // 当前连接；断线时为 nil
+	status Status
+	ln     stdnet.Listener // 主机的监听
+	addr   string          // 客户端的重拨地址
+
+	ready chan struct{} // inbox 有新着法（容量 1）
+	done  chan struct{} // Close 时关闭
+}
+
+var errPeerLeft = errors.New("net: peer left the game")
+
+func newSession() *Session {
+	return &Session{ready: make(chan struct{}, 1), done: make(chan struct{})}
+}
+
+// Listen 在 addr 上开一盘，等对方用 Dial 连入。开局取 gs（含地图和先手方，不会被修改），
+// 规则取当前的 game.CurrentRules()；本方执 remote 的对手。立即返回，对方连入前 Status 为 Connecting。
+func Listen(addr string, gs *game.GameState, remote game.CellState) (*Session, error) {
+	if remote != game.PlayerA && remote != game.PlayerB {
+		return nil, fmt.Errorf("net: remote side must be A or B, got %d", remote)
+	}
+	ln, err := stdnet.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := newSession()
+	s.remote, s.rules, s.ln = remote, game.CurrentRules(), ln
+	s.setStart(gs)
+	go s.acceptLoop()
+	return s, nil
+}
+
+// Dial 连上 addr 上主机开的一盘；开局、执子方和规则都以主机为准（见 Start、LocalSide、Rules）。
+// 第一次连接失败直接返回错误；之后断线在后台自动重拨，直到 Close 或对方退出。
+func Dial(addr string) (*Session, error) {
+	c, err := stdnet.DialTimeout("tcp", addr, HandshakeTimeout)
+	if err != nil {
+		return nil, err
+	}
+	s := newSession()
+	s.addr = addr
+	r, err := s.handshake(c, false)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	go s.dialLoop(c, r)
+	return s, nil
+}
+
+func (s *Session) setStart(gs *game.GameState) {
+	s.start = cloneState(gs)
+	s.state = cloneState(gs)
+}
+
+func cloneState(gs *game.GameState) *game.GameState {
+	st := *gs
+	st.Board = gs.Board.Clone()
+	return &st
+}
+
+// Addr 主机实际监听的地址（addr 端口写 0 时由系统分配）；客户端为拨号地址
+func (s *Session) Addr() string {
+	if s.ln != nil {
+		return s.ln.Addr().String()
+	}
+	return s.addr
+}
+
+// Start 开局的副本
+func (s *Session) Start() *game.GameState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return cloneState(s.start)
+}
+
+// Rules 本局规则（以主机为准）
+func (s *Session) Rules() game.Rules {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rules
+}
+
+// RemoteSide 对方执的一方
+func (s *Session) RemoteSide() game.CellState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.remote
+}
+
+// LocalSide 本方执的一方
+func (s *Session) LocalSide() game.CellState { return game.Opponent(s.RemoteSide()) }
+
+// Status 当前连接状态
+func (s *Session) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// Moves 双方已走的全部着法（副本）
+func (s *Session) Moves() []game.Move {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]game.Move(nil), s.moves...)
+}
+
+// Send 走出本方的一手。不是本方回合或着法非法时返回错误；
+// 断线期间照样记下，重连后按对方的 sync 补发。
+func (s *Session) Send(mv game.Move) error {
+	s.mu.Lock()
+	if s.status == Closed {
+		s.mu.Unlock()
+		return errors.New("net: session closed")
+	}
+	if s.state.CurrentPlayer == s.remote {
+		s.mu.Unlock()
+		return fmt.Errorf("net: not our turn, %v", mv)
+	}
+	if err := game.ValidateMove(s.state, mv); err != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("net: %v: %w", mv, err)
+	}
+	ply := len(s.moves)
+	s.apply(mv)
+	c := s.conn
+	move, state := formatMoveMsg(ply, mv), formatStateMsg(ply+1, s.state)
+	s.mu.Unlock()
+	// 网络慢时不挡住界面每帧的 Poll/Status；写失败由读循环发现断线，
+	// 这期间换了连接的话，新连接握手时会按对方的 sync 补发这一手
+	if c != nil {
+		s.send(c, move, state)
+	}
+	return nil
+}
+
+// Poll 取出一手已收到的对方着法；没有时 ok=false。界面每帧调用一次
+func (s *Session) Poll() (mv game.Move, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.inbox) == 0 {
+		return game.Move{}, false
+	}
+	mv = s.inbox[0]
+	s.inbox = s.inbox[1:]
+	return mv, true
+}
+
+// Desync 最近一次和对方核对局面时不一致的格子（Old 为本方、New 为对方）；一致或还没核对过时为空
+func (s *Session) Desync() []game.CellChange {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]game.CellChange(nil), s.desync...)
+}
+
+// Ready 收到新的对方着法时可读；不想每帧轮询的调用方可以等它再 Poll
+func (s *Session) Ready() <-chan struct{} { return s.ready }
+
+// Close 通知对方退出并断开；之后不再重连
+func (s *Session) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status == Closed {
+		return nil
+	}
+	s.status = Closed
+	close(s.done)
+	if s.conn != nil {
+		s.writeLocked(s.conn, "bye")
+		s.conn.Close()
+		s.conn = nil
+	}
+	if s.ln != nil {
+		return s.ln.Close()
+	}
+	return nil
+}
+
+func (s *Session) apply(mv game.Move) {
+	if _, _, err := s.state.MakeMove(mv); err != nil {
+		// ValidateMove 已经过了，不应发生
+		log.Printf("联机: 第 %d 手 %v 落盘失败: %v", len(s.moves), mv, err)
+	}
+	s.moves = append(s.moves, mv)
+}
+
+// writeLocked 写一行；调用方持有 s.mu（握手、补发要和着法列表保持一致）
+func (s *Session) writeLocked(c stdnet.Conn, line string) error {
+	return s.send(c, line)
+}
+
+// send 依次写几行，遇错即停；不要求持有 s.mu
+func (s *Session) send(c stdnet.Conn, lines ...string) error {
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	for _, line := range lines {
+		c.SetWriteDeadline(time.Now().Add(HandshakeTimeout))
+		if _, err := fmt.Fprintf(c, "%s\n", line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Session) write(c stdnet.Conn, line string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.writeLocked(c, line)
+}
+
+// handshake 交换 hello / game / sync，并补发对方缺的着法。返回的 Reader 里可能已经缓冲了后续消息，读循环要接着用
+func (s *Session) handshake(c stdnet.Conn, host bool) (*bufio.Reader, error) {
+	c.SetDeadline(time.Now().Add(HandshakeTimeout))
+	defer c.SetDeadline(time.Time{})
+
+	s.mu.Lock()
+	hello := []string{fmt.Sprintf("hello %d", protocolVersion)}
+	if host {
+		hello = append(hello, fmt.Sprintf("game %s %s %s", s.start.PositionString(), formatSide(s.remote), s.rules))
+	}
+	hello = append(hello, fmt.Sprintf("sync %d", len(s.moves)))
+	var err error
+	for _, line := range hello {
+		if err == nil {
+			err = s.writeLocked(c, line)
+		}
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	r := bufio.NewReader(c)
+	gotHello, gotGame, peerHas := false, host, -1
+	for !gotHello || !gotGame || peerHas < 0 {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("net: handshake: %w", err)
+		}
+		f := strings.Fields(line)
+		if len(f) == 0 {
+			continue
+		}
+		switch f[0] {
+		case "hello":
+			if len(f) != 2 || f[1] != fmt.Sprint(protocolVersion) {
+				return nil, fmt.Errorf("net: protocol version mismatch: %q, want %d", strings.TrimSpace(line), protocolVersion)
+			}
+			gotHello = true
+		case "game":
+			if host {
+				continue
+			}
+			if err := s.adoptGame(f[1:]); err != nil {
+				return nil, err
+			}
+			gotGame = true
+		case "sync":
+			if peerHas, err = parseCount("sync", f[1:]); err != nil {
+				return nil, err
+			}
+		case "bye":
+			return nil, errPeerLeft
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ply := peerHas; ply < len(s.moves); ply++ {
+		if err := s.writeLocked(c, formatMoveMsg(ply, s.moves[ply])); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// adoptGame 客户端处理主机的 game：第一次连接时采用，重连时必须和原来的一致
+func (s *Session) adoptGame(args []string) error {
+	if len(args) != 3 {
+		return fmt.Errorf("net: game: want 3 arguments, got %d", len(args))
+	}
+	gs, err := game.ParsePosition(args[0])
+	if err != nil {
+		return fmt.Errorf("net: game: %w", err)
+	}
+	side, err := parseSide(args[1])
+	if err != nil {
+		return fmt.Errorf("net: game: %w", err)
+	}
+	rules, err := game.ParseRules(args[2])
+	if err != nil {
+		return fmt.Errorf("net: game: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.start != nil {
+		if s.start.PositionString() != args[0] || s.remote != game.Opponent(side) || s.rules != rules {
+			return errors.New("net: host restarted with a different game")
+		}
+		return nil
+	}
+	s.remote, s.rules = game.Opponent(side), rules
+	s.setStart(gs)
+	return nil
+}
+
+func (s *Session) acceptLoop() {
+	for {
+		c, err := s.ln.Accept()
+		if err != nil {
+			return // Close 关掉了监听
+		}
+		go func() {
+			r, err := s.handshake(c, true)
+			if err != nil {
+				log.Printf("联机: %v 握手失败: %v", c.RemoteAddr(), err)
+				c.Close()
+				return
+			}
+			s.serve(c, r)
+		}()
+	}
+}
+
+func (s *Session) dialLoop(c stdnet.Conn, r *bufio.Reader) {
+	for {
+		s.serve(c, r)
+		for {
+			if st := s.Status(); st == Closed || st == PeerLeft {
+				return
+			}
+			select {
+			case <-s.done:
+				return
+			case <-time.After(RetryInterval):
+			}
+			nc, err := stdnet.DialTimeout("tcp", s.addr, HandshakeTimeout)
+			if err != nil {
+				continue
+			}
+			nr, err := s.handshake(nc, false)
+			if err != nil {
+				nc.Close()
+				if errors.Is(err, errPeerLeft) {
+					s.setStatus(PeerLeft)
+				} else {
+					log.Printf("联机: 重连 %s 握手失败: %v", s.addr, err)
+				}
+				continue
+			}
+			c, r = nc, nr
+			break
+		}
+	}
+}
+
+func (s *Session) setStatus(st Status) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status != Closed {
+		s.status = st
+	}
+}
+
+// serve 在 c 上收消息直到断线；同一时刻只保留最新的一条连接
+func (s *Session) serve(c stdnet.Conn, r *bufio.Reader) {
+	s.mu.Lock()
+	if s.status == Closed {
+		s.mu.Unlock()
+		c.Close()
+		return
+	}
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	s.conn, s.status = c, Connected
+	s.mu.Unlock()
+
+	stop := make(chan struct{})
+	go s.ping(c, stop)
+	defer func() {
+		close(stop)
+		c.Close()
+		s.mu.Lock()
+		if s.conn == c {
+			s.conn = nil
+			if s.status == Connected {
+				s.status = Reconnecting
+			}
+		}
+		s.mu.Unlock()
+	}()
+
+	for {
+		c.SetReadDeadline(time.Now().Add(ReadTimeout))
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		if !s.handle(c, strings.Fields(line)) {
+			return
+		}
+	}
+}
+
+func (s *Session) ping(c stdnet.Conn, stop <-chan struct{}) {
+	t := time.NewTicker(PingInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+			if s.write(c, "ping") != nil {
+				return
+			}
+		}
+	}
+}
+
+// handle 处理一条消息；返回 false 表示对方退出
+func (s *Session) handle(c stdnet.Conn, f []string) bool {
+	if len(f) == 0 {
+		return true
+	}
+	switch f[0] {
+	case "move":
+		ply, mv, err := parseMoveMsg(f[1:])
+		if err != nil {
+			log.Printf("联机: %v", err)
+			return true
+		}
+		s.receive(c, ply, mv)
+	case "sync":
+		n, err := parseCount("sync", f[1:])
+		if err != nil {
+			log.Printf("联机: %v", err)
+			return true
+		}
+		s.mu.Lock()
+		for ply := n; ply < len(s.moves); ply++ {
+			s.writeLocked(c, formatMoveMsg(ply, s.moves[ply]))
+		}
+		s.mu.Unlock()
+	case "state":
+		n, peer, err := parseStateMsg(f[1:])
+		if err != nil {
+			log.Printf("联机: %v", err)
+			return true
+		}
+		s.check(n, peer)
+	case "ping":
+		s.write(c, "pong")
+	case "bye":
+		s.setStatus(PeerLeft)
+		return false
+	case "pong", "hello", "game":
+	default:
+		log.Printf("联机: 未知消息 %q", f[0])
+	}
+	return true
+}
+
+// receive 对方的第 ply 手：重复的丢掉，跳号的要求对方从缺口重发，非法的记日志丢掉
+func (s *Session) receive(c stdnet.Conn, ply int, mv game.Move) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case ply < len(s.moves):
+		return
+	case ply > len(s.moves):
+		s.writeLocked(c, fmt.Sprintf("sync %d", len(s.moves)))
+		return
+	}
+	if s.state.CurrentPlayer != s.remote {
+		log.Printf("联机: 第 %d 手轮到本方，忽略对方的 %v", ply, mv)
+		return
+	}
+	if err := game.ValidateMove(s.state, mv); err != nil {
+		log.Printf("联机: 对方第 %d 手 %v 非法，已忽略: %v", ply, mv, err)
+		return
+	}
+	s.apply(mv)
+	s.inbox = append(s.inbox, mv)
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+// check 对方走完第 n 手后的局面：本方也正好走到第 n 手时逐格核对；手数不同说明还有着法在路上，不比
+func (s *Session) check(n int, peer *game.GameState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n != len(s.moves) {
+		return
+	}
+	d := s.state.Board.Diff(peer.Board)
+	if len(d) > 0 || peer.CurrentPlayer != s.state.CurrentPlayer {
+		log.Printf("联机: 第 %d 手后局面与对方不一致: %d 格不同 %v，轮到 %v / 对方认为轮到 %v",
+			n, len(d), d, s.state.CurrentPlayer, peer.CurrentPlayer)
+	}
+	s.desync = d
+}
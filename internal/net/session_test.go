@@ -0,0 +1,204 @@
+package net
+
+import (
+	stdnet "net"
+	"testing"
+	"time"
+
+	"hexxagon_go/internal/game"
+)
+
+func init() {
+	PingInterval = 20 * time.Millisecond
+	ReadTimeout = 500 * time.Millisecond
+	RetryInterval = 20 * time.Millisecond
+}
+
+func pair(t *testing.T) (host, client *Session) {
+	t.Helper()
+	host, err := Listen("127.0.0.1:0", game.NewGameState(4), game.PlayerB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { host.Close() })
+	client, err = Dial(host.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	waitStatus(t, host, Connected)
+	return host, client
+}
+
+func waitStatus(t *testing.T, s *Session, want Status) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for s.Status() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("状态 %v，等不到 %v", s.Status(), want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func recv(t *testing.T, s *Session) game.Move {
+	t.Helper()
+	select {
+	case <-s.Ready():
+	case <-time.After(3 * time.Second):
+		t.Fatal("等不到对方着法")
+	}
+	mv, ok := s.Poll()
+	if !ok {
+		t.Fatal("Ready 了却 Poll 不到")
+	}
+	return mv
+}
+
+// play side 在 s 的局面里走第一步合法着
+func play(t *testing.T, s *Session, st *game.GameState) game.Move {
+	t.Helper()
+	mv := game.GenerateMoves(st.Board, st.CurrentPlayer)[0]
+	if err := s.Send(mv); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := st.MakeMove(mv); err != nil {
+		t.Fatal(err)
+	}
+	return mv
+}
+
+func TestExchangeMoves(t *testing.T) {
+	host, client := pair(t)
+	if client.LocalSide() != game.PlayerB || host.LocalSide() != game.PlayerA {
+		t.Fatalf("执子方: host %v client %v", host.LocalSide(), client.LocalSide())
+	}
+	if client.Start().PositionString() != host.Start().PositionString() {
+		t.Fatal("客户端开局与主机不一致")
+	}
+	st := host.Start()
+	for ply := 0; ply < 6; ply++ {
+		from, to := host, client
+		if st.CurrentPlayer == game.PlayerB {
+			from, to = client, host
+		}
+		if got, want := recvAfter(t, from, to, st), st.Board.LastMove; got != want {
+			t.Fatalf("第 %d 手: 收到 %v，发出 %v", ply, got, want)
+		}
+	}
+	if len(host.Moves()) != 6 || len(client.Moves()) != 6 {
+		t.Fatalf("着法数: host %d client %d", len(host.Moves()), len(client.Moves()))
+	}
+}
+
+func recvAfter(t *testing.T, from, to *Session, st *game.GameState) game.Move {
+	t.Helper()
+	play(t, from, st)
+	return recv(t, to)
+}
+
+func TestRejectsOutOfTurnAndIllegal(t *testing.T) {
+	host, client := pair(t)
+	st := host.Start()
+	mvB := game.GenerateMoves(st.Board, game.PlayerB)[0]
+	if err := client.Send(mvB); err == nil {
+		t.Fatal("没轮到白方，Send 应报错")
+	}
+	bad := game.Move{From: game.HexCoord{Q: 0, R: 0}, To: game.HexCoord{Q: 1, R: 0}}
+	if err := host.Send(bad); err == nil {
+		t.Fatal("非法着法 Send 应报错")
+	}
+	// 对方绕过 Send 直接发非法着：记日志丢掉，不进 inbox
+	host.mu.Lock()
+	host.writeLocked(host.conn, formatMoveMsg(0, bad))
+	host.mu.Unlock()
+	time.Sleep(50 * time.Millisecond)
+	if _, ok := client.Poll(); ok {
+		t.Fatal("非法着法不应交给界面")
+	}
+}
+
+func TestReconnectResendsMissedMoves(t *testing.T) {
+	host, client := pair(t)
+	st := host.Start()
+	recvAfter(t, host, client, st)
+
+	// 掐断连接：白方在断线期间走的一手要在重连后送到
+	client.mu.Lock()
+	client.conn.Close()
+	client.mu.Unlock()
+	waitStatus(t, client, Reconnecting)
+	mv := play(t, client, st)
+	waitStatus(t, client, Connected)
+	if got := recv(t, host); got != mv {
+		t.Fatalf("重连后收到 %v，应为 %v", got, mv)
+	}
+	if got := recvAfter(t, host, client, st); got != st.Board.LastMove {
+		t.Fatalf("重连后继续走: 收到 %v", got)
+	}
+}
+
+func TestPeerLeft(t *testing.T) {
+	host, client := pair(t)
+	host.Close()
+	waitStatus(t, client, PeerLeft)
+	if err := host.Send(game.Move{}); err == nil {
+		t.Fatal("Close 后 Send 应报错")
+	}
+}
+
+func TestStateCheckReportsDesync(t *testing.T) {
+	host, client := pair(t)
+	st := host.Start()
+	recvAfter(t, host, client, st)
+	time.Sleep(50 * time.Millisecond) // 等 state 核对完
+	if d := client.Desync(); len(d) != 0 {
+		t.Fatalf("局面一致时不应有差异: %v", d)
+	}
+
+	// 白方本地的局面被悄悄改坏一格：下一次核对要报出来
+	client.mu.Lock()
+	i := game.IndexOf[game.HexCoord{Q: 0, R: 0}]
+	client.state.Board.Cells[i] = game.Blocked
+	client.mu.Unlock()
+	play(t, client, st)
+	recv(t, host)
+	recvAfter(t, host, client, st)
+	deadline := time.Now().Add(time.Second)
+	for len(client.Desync()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("改坏的格子没被核对出来")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if d := client.Desync(); len(d) != 1 || d[0].Old != game.Blocked || d[0].New != game.Empty {
+		t.Fatalf("差异应只有被改的那一格: %v", d)
+	}
+}
+
+// 对方不读、写阻塞时，Send 不应占着锁：界面照样能读状态
+func TestSendDoesNotHoldLockWhileWriting(t *testing.T) {
+	local, peer := stdnet.Pipe()
+	defer peer.Close()
+	s := newSession()
+	s.remote, s.status, s.conn = game.PlayerB, Connected, local
+	s.setStart(game.NewGameState(4))
+
+	mv := game.GenerateMoves(s.state.Board, game.PlayerA)[0]
+	sent := make(chan error, 1)
+	go func() { sent <- s.Send(mv) }()
+	start := time.Now()
+	for len(s.Moves()) == 0 {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("Send 写网络时锁住了会话 %s", d)
+	}
+	if s.Status() != Connected {
+		t.Fatalf("状态 %v", s.Status())
+	}
+	local.Close() // 让阻塞的写返回
+	if err := <-sent; err != nil {
+		t.Fatal(err)
+	}
+}
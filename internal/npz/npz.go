@@ -0,0 +1,150 @@
+// Package npz 写 NumPy 的 .npz：zip 包里每个数组一个 .npy（格式 1.0，小端），
+// np.load 直接按名字取数组，不需要自定义读取代码。只写不读。
+package npz
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const npyMagic = "\x93NUMPY\x01\x00"
+
+// Writer 依次 Add 数组，最后 Close 写出 zip 目录
+type Writer struct {
+	zw     *zip.Writer
+	method uint16
+	names  map[string]bool
+}
+
+// NewWriter compress=true 对应 np.savez_compressed（deflate），false 对应 np.savez（不压缩）
+func NewWriter(w io.Writer, compress bool) *Writer {
+	method := zip.Store
+	if compress {
+		method = zip.Deflate
+	}
+	return &Writer{zw: zip.NewWriter(w), method: method, names: map[string]bool{}}
+}
+
+// Add 写一个数值数组；data 是按行优先展平的切片，元素个数须等于 shape 各维之积
+func (w *Writer) Add(name string, shape []int, data any) error {
+	descr, size, err := dtypeOf(data)
+	if err != nil {
+		return fmt.Errorf("npz %s: %w", name, err)
+	}
+	return w.add(name, "'"+descr+"'", size, shape, data)
+}
+
+// AddStruct 写结构化数组：fields 为 {字段名, dtype} 按结构体字段顺序，data 为无填充的定长结构体切片
+func (w *Writer) AddStruct(name string, fields [][2]string, shape []int, data any) error {
+	var sb strings.Builder
+	sb.WriteByte('[')
+	size := 0
+	for i, f := range fields {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "('%s', '%s')", f[0], f[1])
+		n, err := dtypeSize(f[1])
+		if err != nil {
+			return fmt.Errorf("npz %s.%s: %w", name, f[0], err)
+		}
+		size += n
+	}
+	sb.WriteByte(']')
+	return w.add(name, sb.String(), size, shape, data)
+}
+
+func (w *Writer) add(name, descr string, itemSize int, shape []int, data any) error {
+	if w.names[name] {
+		return fmt.Errorf("npz: duplicate array %q", name)
+	}
+	n := 1
+	for _, d := range shape {
+		n *= d
+	}
+	if got := binary.Size(data); got != n*itemSize {
+		return fmt.Errorf("npz %s: shape %v needs %d bytes, data has %d", name, shape, n*itemSize, got)
+	}
+	f, err := w.zw.CreateHeader(&zip.FileHeader{Name: name + ".npy", Method: w.method})
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(npyHeader(descr, shape)); err != nil {
+		return err
+	}
+	if err := binary.Write(f, binary.LittleEndian, data); err != nil {
+		return err
+	}
+	w.names[name] = true
+	return nil
+}
+
+func (w *Writer) Close() error { return w.zw.Close() }
+
+// npyHeader 魔数 + 版本 + 头长 + 描述字典；字典用空格补齐到整个头为 64 字节的倍数，以换行结尾
+func npyHeader(descr string, shape []int) []byte {
+	dict := fmt.Sprintf("{'descr': %s, 'fortran_order': False, 'shape': %s, }", descr, shapeString(shape))
+	pad := 64 - (len(npyMagic)+2+len(dict)+1)%64
+	if pad == 64 {
+		pad = 0
+	}
+	dict += strings.Repeat(" ", pad) + "\n"
+	hdr := make([]byte, 0, len(npyMagic)+2+len(dict))
+	hdr = append(hdr, npyMagic...)
+	hdr = binary.LittleEndian.AppendUint16(hdr, uint16(len(dict)))
+	return append(hdr, dict...)
+}
+
+// shapeString Python 元组写法：() (n,) (a, b)
+func shapeString(shape []int) string {
+	if len(shape) == 1 {
+		return fmt.Sprintf("(%d,)", shape[0])
+	}
+	parts := make([]string, len(shape))
+	for i, d := range shape {
+		parts[i] = fmt.Sprint(d)
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+func dtypeOf(data any) (descr string, size int, err error) {
+	switch data.(type) {
+	case []int8:
+		return "|i1", 1, nil
+	case []uint8:
+		return "|u1", 1, nil
+	case []int16:
+		return "<i2", 2, nil
+	case []uint16:
+		return "<u2", 2, nil
+	case []int32:
+		return "<i4", 4, nil
+	case []uint32:
+		return "<u4", 4, nil
+	case []int64:
+		return "<i8", 8, nil
+	case []uint64:
+		return "<u8", 8, nil
+	case []float32:
+		return "<f4", 4, nil
+	case []float64:
+		return "<f8", 8, nil
+	}
+	return "", 0, fmt.Errorf("unsupported element type %T", data)
+}
+
+// dtypeSize 字段 dtype 的字节数，如 "<u2"、"u1"、"<f4"
+func dtypeSize(descr string) (int, error) {
+	s := strings.TrimLeft(descr, "<>|=")
+	var n int
+	if len(s) < 2 {
+		return 0, fmt.Errorf("bad dtype %q", descr)
+	}
+	if _, err := fmt.Sscanf(s[1:], "%d", &n); err != nil || n <= 0 {
+		return 0, fmt.Errorf("bad dtype %q", descr)
+	}
+	return n, nil
+}
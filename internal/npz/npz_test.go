@@ -0,0 +1,91 @@
+package npz
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+type rec struct {
+	Ply   uint16
+	Phase uint8
+	Value float32
+}
+
+func readNPY(t *testing.T, zr *zip.Reader, name string) (header string, data []byte) {
+	t.Helper()
+	f, err := zr.Open(name + ".npy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(b, []byte(npyMagic)) {
+		t.Fatalf("%s: 魔数不对 % x", name, b[:8])
+	}
+	hl := int(binary.LittleEndian.Uint16(b[8:10]))
+	if (10+hl)%64 != 0 || b[10+hl-1] != '\n' {
+		t.Fatalf("%s: 头长 %d 未按 64 字节对齐或缺换行", name, hl)
+	}
+	return strings.TrimRight(string(b[10:10+hl]), " \n"), b[10+hl:]
+}
+
+func TestWriteNPZ(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		var buf bytes.Buffer
+		w := NewWriter(&buf, compress)
+		if err := w.Add("X", []int{2, 3}, []float32{1, 2, 3, 4, 5, 6}); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Add("Z", []int{2}, []int8{1, -1}); err != nil {
+			t.Fatal(err)
+		}
+		fields := [][2]string{{"ply", "<u2"}, {"phase", "u1"}, {"value", "<f4"}}
+		if err := w.AddStruct("M", fields, []int{1}, []rec{{Ply: 7, Phase: 2, Value: 0.5}}); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Add("Z", []int{1}, []int8{0}); err == nil {
+			t.Fatal("重名数组应报错")
+		}
+		if err := w.Add("bad", []int{4}, []int8{1}); err == nil {
+			t.Fatal("形状与数据长度不符应报错")
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(zr.File) != 3 {
+			t.Fatalf("应有 3 个数组，得到 %d", len(zr.File))
+		}
+
+		h, data := readNPY(t, zr, "X")
+		if h != "{'descr': '<f4', 'fortran_order': False, 'shape': (2, 3), }" {
+			t.Fatalf("X 头: %s", h)
+		}
+		if len(data) != 24 || math.Float32frombits(binary.LittleEndian.Uint32(data[20:])) != 6 {
+			t.Fatalf("X 数据 % x", data)
+		}
+		h, data = readNPY(t, zr, "Z")
+		if h != "{'descr': '|i1', 'fortran_order': False, 'shape': (2,), }" || !bytes.Equal(data, []byte{1, 0xff}) {
+			t.Fatalf("Z: %s % x", h, data)
+		}
+		h, data = readNPY(t, zr, "M")
+		if h != "{'descr': [('ply', '<u2'), ('phase', 'u1'), ('value', '<f4')], 'fortran_order': False, 'shape': (1,), }" {
+			t.Fatalf("M 头: %s", h)
+		}
+		if !bytes.Equal(data, []byte{7, 0, 2, 0, 0, 0, 0x3f}) {
+			t.Fatalf("M 数据 % x", data)
+		}
+	}
+}
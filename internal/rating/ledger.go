@@ -0,0 +1,129 @@
+// Package rating 跨次运行的战绩账本与等级分。
+//
+// arena、battle_eval_nn 每下完一局往账本（JSON Lines）追加一行，记下双方身份与结果：
+//
+//	{"time":"2026-10-16T09:12:03Z","tool":"arena","player":{"name":"nn:depth=2","hash":"5c1e07a2"},
+//	 "opponent":{"name":"mcts:sims=800","hash":"0b9d44f1"},"score":1,"red":true}
+//
+// 身份 = 引擎写法 + 配置哈希；哈希由调用方把影响棋力的一切（引擎参数、评估器身份即模型 CRC、规则变体）喂进去，
+// 换了模型或改了静态评估，同一个写法就成了新的参赛者，新旧版本在 Rate 的表里各占一行，进步多少一眼可见。
+// 多个进程可以同时往同一个账本追加（每条记录一次 write）。
+package rating
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Player 参赛者身份
+type Player struct {
+	Name string `json:"name"` // 引擎写法，如 "ab:depth=3"
+	Hash string `json:"hash"` // ConfigHash，8 位十六进制
+}
+
+// NewPlayer 名字加上由 name 与 config 算出的配置哈希
+func NewPlayer(name string, config ...string) Player {
+	return Player{Name: name, Hash: ConfigHash(append([]string{name}, config...)...)}
+}
+
+// ID 账本与表格里区分参赛者用的键："名字@哈希"
+func (p Player) ID() string { return p.Name + "@" + p.Hash }
+
+// ConfigHash 各部分依次做 FNV-1a（部分之间加分隔，"ab"+"c" 与 "a"+"bc" 不同）
+func ConfigHash(parts ...string) string {
+	h := fnv.New32a()
+	io.WriteString(h, strings.Join(parts, "\x00"))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// Result 账本的一行：一局棋，从 Player 一方看
+type Result struct {
+	Time     time.Time `json:"time"`
+	Tool     string    `json:"tool"`
+	Player   Player    `json:"player"`
+	Opponent Player    `json:"opponent"`
+	Score    float64   `json:"score"` // Player 的得分：1 胜、0.5 和、0 负
+	Red      bool      `json:"red"`   // Player 执红
+}
+
+// Ledger 并发安全；nil Ledger 的方法什么都不做，调用方不必判断是否开启
+type Ledger struct {
+	mu   sync.Mutex
+	f    *os.File
+	tool string
+}
+
+// OpenLedger 以追加方式打开账本（不存在则新建）；path 为空时返回 nil（不记录）
+func OpenLedger(path, tool string) (*Ledger, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &Ledger{f: f, tool: tool}, nil
+}
+
+// Append 记一局；Time 为零时取当前时间，Tool 取打开时给的工具名
+func (l *Ledger) Append(r Result) error {
+	if l == nil {
+		return nil
+	}
+	if r.Time.IsZero() {
+		r.Time = time.Now().UTC().Truncate(time.Second)
+	}
+	r.Tool = l.tool
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.f.Write(append(line, '\n'))
+	return err
+}
+
+// Close 关闭文件
+func (l *Ledger) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Close()
+}
+
+// ReadLedger 解析全部记录；得分不是 0/0.5/1 的行报错并带上行号
+func ReadLedger(r io.Reader) ([]Result, error) {
+	dec := json.NewDecoder(r)
+	var out []Result
+	for {
+		var res Result
+		if err := dec.Decode(&res); err == io.EOF {
+			return out, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("ledger record %d: %w", len(out)+1, err)
+		}
+		if res.Score != 0 && res.Score != 0.5 && res.Score != 1 {
+			return nil, fmt.Errorf("ledger record %d: score %v, want 0, 0.5 or 1", len(out)+1, res.Score)
+		}
+		out = append(out, res)
+	}
+}
+
+// LoadLedger 读账本文件
+func LoadLedger(path string) ([]Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadLedger(f)
+}
@@ -0,0 +1,129 @@
+package rating
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"hexxagon_go/internal/elo"
+)
+
+// 两种等级分都以 1500 为基准：
+//   - Elo：用全部记录一次性拟合 Bradley-Terry 模型（elo.BradleyTerry，每对参赛者加一局虚拟和棋，全胜也有限），
+//     与先后次序无关，适合比较“现在谁强”；全体平均为 1500，彼此没下过（也没有共同对手）的两群之间不可比。
+//   - Glicko：Glicko-1，按时间顺序逐局更新，RD 是评分的不确定度（约 95% 区间 ±2RD），久不出场的参赛者 RD 回涨。
+const (
+	BaseRating = 1500
+	InitialRD  = 350
+	MinRD      = 30   // RD 下限，免得老参赛者的评分再也动不了
+	GlickoC    = 34.6 // 每闲置一天 RD² 增加 C²：约 100 天从 50 涨回 350
+)
+
+// Rating 一个参赛者的汇总
+type Rating struct {
+	Player  Player
+	W, D, L int
+	Elo     float64
+	Glicko  float64
+	RD      float64
+	Last    time.Time // 最后一局的时间
+}
+
+func (r *Rating) Games() int { return r.W + r.D + r.L }
+
+// Score 得分率
+func (r *Rating) Score() float64 {
+	if r.Games() == 0 {
+		return 0
+	}
+	return (float64(r.W) + 0.5*float64(r.D)) / float64(r.Games())
+}
+
+// Rate 由账本记录算出每个参赛者的战绩与两种等级分，按 Elo 从高到低排
+func Rate(results []Result) []*Rating {
+	byID := map[string]int{}
+	var out []*Rating
+	index := func(p Player) int {
+		id := p.ID()
+		if i, ok := byID[id]; ok {
+			return i
+		}
+		byID[id] = len(out)
+		out = append(out, &Rating{Player: p, Glicko: BaseRating, RD: InitialRD})
+		return len(out) - 1
+	}
+	sorted := append([]Result(nil), results...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	for _, res := range sorted {
+		index(res.Player)
+		index(res.Opponent)
+	}
+	points, games := make([][]float64, len(out)), make([][]float64, len(out))
+	for i := range out {
+		points[i], games[i] = make([]float64, len(out)), make([]float64, len(out))
+	}
+	for _, res := range sorted {
+		i, j := index(res.Player), index(res.Opponent)
+		points[i][j] += res.Score
+		points[j][i] += 1 - res.Score
+		games[i][j]++
+		games[j][i]++
+		a, b := out[i], out[j]
+		a.tally(res.Score)
+		b.tally(1 - res.Score)
+		glickoUpdate(a, b, res.Score, res.Time)
+	}
+
+	fit := elo.BradleyTerry(points, games, -1)
+	for i, r := range out {
+		r.Elo = BaseRating + fit[i]
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Elo > out[j].Elo })
+	return out
+}
+
+func (r *Rating) tally(score float64) {
+	switch score {
+	case 1:
+		r.W++
+	case 0:
+		r.L++
+	default:
+		r.D++
+	}
+}
+
+const glickoQ = math.Ln10 / 400
+
+func glickoG(rd float64) float64 {
+	return 1 / math.Sqrt(1+3*glickoQ*glickoQ*rd*rd/(math.Pi*math.Pi))
+}
+
+// inflate 按闲置天数放大 RD
+func (r *Rating) inflate(now time.Time) {
+	if !r.Last.IsZero() && now.After(r.Last) {
+		days := now.Sub(r.Last).Hours() / 24
+		r.RD = math.Min(math.Sqrt(r.RD*r.RD+GlickoC*GlickoC*days), InitialRD)
+	}
+	if now.After(r.Last) {
+		r.Last = now
+	}
+}
+
+// glickoUpdate 一局一个评级期，双方都用赛前的值更新；score 是 a 的得分
+func glickoUpdate(a, b *Rating, score float64, at time.Time) {
+	a.inflate(at)
+	b.inflate(at)
+	ra, rda := a.Glicko, a.RD
+	a.Glicko, a.RD = glickoStep(ra, rda, b.Glicko, b.RD, score)
+	b.Glicko, b.RD = glickoStep(b.Glicko, b.RD, ra, rda, 1-score)
+}
+
+func glickoStep(r, rd, ro, rdo, score float64) (float64, float64) {
+	g := glickoG(rdo)
+	e := 1 / (1 + math.Pow(10, -g*(r-ro)/400))
+	d2 := 1 / (glickoQ * glickoQ * g * g * e * (1 - e))
+	denom := 1/(rd*rd) + 1/d2
+	return r + glickoQ/denom*g*(score-e), math.Max(math.Sqrt(1/denom), MinRD)
+}
@@ -0,0 +1,106 @@
+package rating
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigHash(t *testing.T) {
+	a := NewPlayer("nn:depth=2", "kata-1a2b3c4d")
+	if a != NewPlayer("nn:depth=2", "kata-1a2b3c4d") {
+		t.Fatal("同样的配置哈希应相同")
+	}
+	if a.Hash == NewPlayer("nn:depth=2", "kata-99999999").Hash {
+		t.Fatal("换模型哈希应变")
+	}
+	if ConfigHash("ab", "c") == ConfigHash("a", "bc") {
+		t.Fatal("各部分之间应有分隔")
+	}
+	if !strings.HasPrefix(a.ID(), "nn:depth=2@") || len(a.Hash) != 8 {
+		t.Fatalf("ID %q", a.ID())
+	}
+}
+
+func TestLedgerRoundTrip(t *testing.T) {
+	var nilLedger *Ledger
+	if nilLedger.Append(Result{}) != nil || nilLedger.Close() != nil {
+		t.Fatal("nil Ledger 应什么都不做")
+	}
+	path := filepath.Join(t.TempDir(), "ratings.jsonl")
+	a, b := NewPlayer("ab:depth=3"), NewPlayer("mcts:sims=800")
+	// 打开两次：第二次接在末尾写
+	for run, score := range []float64{1, 0.5} {
+		l, err := OpenLedger(path, "arena")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := l.Append(Result{Player: a, Opponent: b, Score: score, Red: run == 0}); err != nil {
+			t.Fatal(err)
+		}
+		if err := l.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := LoadLedger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Player != a || got[1].Opponent != b || got[1].Score != 0.5 || !got[0].Red {
+		t.Fatalf("读回 %+v", got)
+	}
+	if got[0].Tool != "arena" || got[0].Time.IsZero() {
+		t.Fatalf("Tool/Time 未填: %+v", got[0])
+	}
+	if _, err := ReadLedger(strings.NewReader(`{"score":2}`)); err == nil {
+		t.Fatal("得分 2 应报错")
+	}
+}
+
+func TestRate(t *testing.T) {
+	strong, weak, idle := NewPlayer("nn:depth=2", "v2"), NewPlayer("nn:depth=2", "v1"), NewPlayer("random")
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var results []Result
+	for i := 0; i < 40; i++ {
+		score := 1.0
+		if i%4 == 0 {
+			score = 0 // 强者 30 胜 10 负
+		}
+		results = append(results, Result{Time: t0.Add(time.Duration(i) * time.Minute), Player: strong, Opponent: weak, Score: score})
+	}
+	results = append(results, Result{Time: t0, Player: weak, Opponent: idle, Score: 1})
+
+	table := Rate(results)
+	if len(table) != 3 || table[0].Player != strong || table[2].Player != idle {
+		t.Fatalf("排序 %v %v %v", table[0].Player, table[1].Player, table[2].Player)
+	}
+	s, w := table[0], table[1]
+	if s.W != 30 || s.L != 10 || w.Games() != 41 {
+		t.Fatalf("战绩 %+v %+v", s, w)
+	}
+	// 75% 得分约 +191 Elo，虚拟和棋把它往回拉一点
+	if d := s.Elo - w.Elo; d < 150 || d > 191 {
+		t.Fatalf("Elo 差 %v", d)
+	}
+	if !(s.Glicko > w.Glicko) || s.RD >= 100 || table[2].RD < 250 {
+		t.Fatalf("Glicko %v±%v / %v±%v / RD %v", s.Glicko, s.RD, w.Glicko, w.RD, table[2].RD)
+	}
+	mean := 0.0
+	for _, r := range table {
+		mean += r.Elo
+	}
+	if mean/3 < BaseRating-1e-6 || mean/3 > BaseRating+1e-6 {
+		t.Fatalf("Elo 平均 %v", mean/3)
+	}
+
+	// 闲置 100 天后再下一局，RD 回涨到接近初值
+	late := Result{Time: t0.Add(100 * 24 * time.Hour), Player: strong, Opponent: idle, Score: 1}
+	before := s.RD
+	after := Rate(append(results, late))
+	for _, r := range after {
+		if r.Player == strong && r.RD <= before {
+			t.Fatalf("闲置后 RD %v，闲置前 %v", r.RD, before)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package replaybuf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"hexxagon_go/internal/samplefmt"
+)
+
+// gRPC 接口：与 HTTP 的三个操作一一对应，消息全用 protobuf 自带的常用类型，
+// 任何语言拿标准的 wrappers/struct/empty 定义就能生成客户端，不用另带 .proto 之外的文件：
+//
+//	syntax = "proto3";
+//	package replaybuf;
+//	import "google/protobuf/empty.proto";
+//	import "google/protobuf/struct.proto";
+//	import "google/protobuf/wrappers.proto";
+//
+//	service ReplayBuffer {
+//	  // value 是 samplefmt 流（Header + 若干 Sample），至多 MaxPushBytes 字节；回 {"added":N,"duplicates":M}
+//	  rpc Push(google.protobuf.BytesValue) returns (google.protobuf.Struct);
+//	  // value 是条数 n（1..MaxBatch）；回 samplefmt 流，窗口为空时 UNAVAILABLE
+//	  rpc Batch(google.protobuf.UInt32Value) returns (google.protobuf.BytesValue);
+//	  // 回 Stats 的各字段
+//	  rpc Stats(google.protobuf.Empty) returns (google.protobuf.Struct);
+//	}
+//
+// 样本流本身就是 protobuf，BytesValue 只是再包一层，Python 侧解开后与读 .pb 分片的代码相同。
+
+// GRPCService 服务全名
+const GRPCService = "replaybuf.ReplayBuffer"
+
+// maxGRPCMessage 收发消息的上限：样本流外加 BytesValue 的几字节包装
+const maxGRPCMessage = MaxPushBytes + 64
+
+// grpcHandler 只是给 ServiceDesc.HandlerType 做类型检查
+type grpcHandler interface {
+	push(ctx context.Context, in *wrapperspb.BytesValue) (*structpb.Struct, error)
+	grpcBatch(ctx context.Context, in *wrapperspb.UInt32Value) (*wrapperspb.BytesValue, error)
+	stats(ctx context.Context, in *emptypb.Empty) (*structpb.Struct, error)
+}
+
+// unary 把一个处理函数包成 grpc.MethodDesc，和 protoc 生成的代码做的事一样
+func unary[In any, Out any](name string, newIn func() *In, call func(grpcHandler, context.Context, *In) (*Out, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			in := newIn()
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			h := srv.(grpcHandler)
+			if interceptor == nil {
+				return call(h, ctx, in)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod(name)}
+			return interceptor(ctx, in, info, func(ctx context.Context, req any) (any, error) {
+				return call(h, ctx, req.(*In))
+			})
+		},
+	}
+}
+
+var grpcDesc = grpc.ServiceDesc{
+	ServiceName: GRPCService,
+	HandlerType: (*grpcHandler)(nil),
+	Methods: []grpc.MethodDesc{
+		unary("Push", func() *wrapperspb.BytesValue { return new(wrapperspb.BytesValue) }, grpcHandler.push),
+		unary("Batch", func() *wrapperspb.UInt32Value { return new(wrapperspb.UInt32Value) }, grpcHandler.grpcBatch),
+		unary("Stats", func() *emptypb.Empty { return new(emptypb.Empty) }, grpcHandler.stats),
+	},
+	Metadata: "replaybuf.proto",
+}
+
+// NewGRPCServer 把 b 挂成 gRPC 服务；opts 追加在消息大小上限之后
+func NewGRPCServer(b *Buffer, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{grpc.MaxRecvMsgSize(maxGRPCMessage)}, opts...)
+	srv := grpc.NewServer(opts...)
+	srv.RegisterService(&grpcDesc, newServer(b))
+	return srv
+}
+
+func (s *server) push(_ context.Context, in *wrapperspb.BytesValue) (*structpb.Struct, error) {
+	if len(in.GetValue()) > MaxPushBytes {
+		return nil, status.Errorf(codes.ResourceExhausted, "sample stream exceeds %d bytes", MaxPushBytes)
+	}
+	h, samples, err := readStream(bytes.NewReader(in.GetValue()))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	added, dups, err := s.b.Add(h, samples)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return structpb.NewStruct(map[string]any{"added": added, "duplicates": dups})
+}
+
+func (s *server) grpcBatch(_ context.Context, in *wrapperspb.UInt32Value) (*wrapperspb.BytesValue, error) {
+	h, samples, err := s.batch(int(in.GetValue()))
+	switch {
+	case errors.Is(err, errBadBatch):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, ErrEmpty):
+		return nil, status.Error(codes.Unavailable, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	var out bytes.Buffer
+	if err := writeStream(&out, h, samples); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return wrapperspb.Bytes(out.Bytes()), nil
+}
+
+func (s *server) stats(context.Context, *emptypb.Empty) (*structpb.Struct, error) {
+	// 经 JSON 转一道，字段名与 /stats 相同
+	raw, err := json.Marshal(s.b.Stats())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	var m map[string]any
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return structpb.NewStruct(m)
+}
+
+func fullMethod(name string) string { return "/" + GRPCService + "/" + name }
+
+// PushGRPC 同 Push，经 gRPC 连接 cc 推送
+func PushGRPC(ctx context.Context, cc grpc.ClientConnInterface, h samplefmt.Header, samples []samplefmt.Sample) error {
+	var body bytes.Buffer
+	if err := writeStream(&body, h, samples); err != nil {
+		return err
+	}
+	return cc.Invoke(ctx, fullMethod("Push"), wrapperspb.Bytes(body.Bytes()), new(structpb.Struct))
+}
+
+// BatchGRPC 经 gRPC 取 n 条样本
+func BatchGRPC(ctx context.Context, cc grpc.ClientConnInterface, n int) (samplefmt.Header, []samplefmt.Sample, error) {
+	out := new(wrapperspb.BytesValue)
+	if err := cc.Invoke(ctx, fullMethod("Batch"), wrapperspb.UInt32(uint32(n)), out, grpc.MaxCallRecvMsgSize(1<<30)); err != nil {
+		return samplefmt.Header{}, nil, err
+	}
+	return readStream(bytes.NewReader(out.GetValue()))
+}
+
+// StatsGRPC 经 gRPC 取缓冲状态
+func StatsGRPC(ctx context.Context, cc grpc.ClientConnInterface) (Stats, error) {
+	out := new(structpb.Struct)
+	if err := cc.Invoke(ctx, fullMethod("Stats"), new(emptypb.Empty), out); err != nil {
+		return Stats{}, err
+	}
+	raw, err := out.MarshalJSON()
+	if err != nil {
+		return Stats{}, err
+	}
+	var st Stats
+	err = json.Unmarshal(raw, &st)
+	return st, err
+}
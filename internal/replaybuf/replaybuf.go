@@ -0,0 +1,315 @@
+// Package replaybuf 在线训练用的回放缓冲：多个 selfplay 进程把样本推进来，训练器随时按批随机取，
+// 不用再手工把分片文件打散拼接。
+//
+// 样本落在磁盘上的段文件里（seg_000001.pb …，每个都是完整的 samplefmt 流，离线也能直接读），
+// 内存里只留每条样本的偏移、长度和 zobrist key，百万条样本的索引约 24MB。
+// 窗口按段整体滑动：总数超过 Capacity 时删掉最旧的段，窗口内同一 key 的局面只收第一条。
+package replaybuf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+
+	"hexxagon_go/internal/samplefmt"
+)
+
+// Config 缓冲参数
+type Config struct {
+	Dir         string // 段文件目录；启动时已有的段照旧算进窗口
+	Capacity    int    // 窗口内至少保留的样本数，多出一个段以上就淘汰最旧的段
+	SegmentSize int    // 每个段文件的样本数
+}
+
+// Stats 缓冲状态，/stats 原样返回
+type Stats struct {
+	Samples    int   `json:"samples"`    // 窗口内样本数
+	Segments   int   `json:"segments"`   // 段文件数
+	Ingested   int64 `json:"ingested"`   // 本次启动以来收下的样本数
+	Duplicates int64 `json:"duplicates"` // 因窗口内已有同一局面而丢弃的样本数
+	Evicted    int64 `json:"evicted"`    // 随旧段淘汰的样本数
+	Served     int64 `json:"served"`     // 发给训练器的样本数
+}
+
+// ErrEmpty 窗口里还没有样本
+var ErrEmpty = errors.New("replaybuf: buffer is empty")
+
+type entry struct {
+	off int64  // 在段文件中的偏移（含长度前缀）
+	n   uint32 // 长度前缀 + 消息体的字节数
+	key uint64
+}
+
+type segment struct {
+	id      int
+	f       *os.File
+	w       *samplefmt.Writer // 只有最新的段在写，封口后为 nil
+	entries []entry
+}
+
+// Buffer 回放缓冲，可并发使用
+type Buffer struct {
+	mu     sync.Mutex
+	cfg    Config
+	header *samplefmt.Header // 第一次写入（或已有段）的 Header；之后的写入形状必须一致
+	segs   []*segment        // 从旧到新
+	keys   map[uint64]int32  // 窗口内各 key 的条数，dedup 用
+	total  int
+	nextID int
+	stats  Stats
+}
+
+// Open 打开（或新建）Dir 下的缓冲，已有的段文件重建索引后只读，新样本写进新段
+func Open(cfg Config) (*Buffer, error) {
+	if cfg.Capacity <= 0 || cfg.SegmentSize <= 0 {
+		return nil, fmt.Errorf("replaybuf: capacity %d and segment size %d must be positive", cfg.Capacity, cfg.SegmentSize)
+	}
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return nil, err
+	}
+	b := &Buffer{cfg: cfg, keys: make(map[uint64]int32), nextID: 1}
+	paths, err := filepath.Glob(filepath.Join(cfg.Dir, "seg_*.pb"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		var id int
+		if _, err := fmt.Sscanf(filepath.Base(p), "seg_%d.pb", &id); err != nil {
+			continue
+		}
+		seg, h, err := scanSegment(p, id)
+		if err != nil {
+			b.Close()
+			return nil, fmt.Errorf("replaybuf: %s: %w", p, err)
+		}
+		if err := b.checkHeader(h); err != nil {
+			seg.f.Close()
+			b.Close()
+			return nil, fmt.Errorf("replaybuf: %s: %w", p, err)
+		}
+		b.segs = append(b.segs, seg)
+		for _, e := range seg.entries {
+			b.keys[e.key]++
+		}
+		b.total += len(seg.entries)
+		b.nextID = max(b.nextID, id+1)
+	}
+	b.evict()
+	return b, nil
+}
+
+// scanSegment 读一遍段文件，记下每条样本的位置
+func scanSegment(path string, id int) (*segment, *samplefmt.Header, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := samplefmt.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	seg := &segment{id: id}
+	var s samplefmt.Sample
+	for {
+		off := r.Offset()
+		if err := r.Next(&s); err != nil {
+			// 写到一半被杀掉的段：末尾那条不完整，前面的照用
+			break
+		}
+		seg.entries = append(seg.entries, entry{off: off, n: uint32(r.Offset() - off), key: s.Key})
+	}
+	seg.f = f
+	return seg, &r.Header, nil
+}
+
+// checkHeader 第一次见到的 Header 定下形状，之后的必须一致
+func (b *Buffer) checkHeader(h *samplefmt.Header) error {
+	if b.header == nil {
+		hc := *h
+		b.header = &hc
+		return nil
+	}
+	if !slices.Equal(h.StateShape, b.header.StateShape) || h.PolicyLen != b.header.PolicyLen {
+		return fmt.Errorf("sample shape %v/%d does not match buffer %v/%d",
+			h.StateShape, h.PolicyLen, b.header.StateShape, b.header.PolicyLen)
+	}
+	return nil
+}
+
+// Add 收下一批样本，返回实际写入与按 key 去重丢掉的条数。key 为 0 的样本不去重
+func (b *Buffer) Add(h samplefmt.Header, samples []samplefmt.Sample) (added, dups int, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.checkHeader(&h); err != nil {
+		return 0, 0, err
+	}
+	for i := range samples {
+		s := &samples[i]
+		if s.Key != 0 && b.keys[s.Key] > 0 {
+			dups++
+			continue
+		}
+		seg, err := b.writable()
+		if err != nil {
+			return added, dups, err
+		}
+		off := seg.w.Offset()
+		if err := seg.w.Write(s); err != nil {
+			return added, dups, err
+		}
+		seg.entries = append(seg.entries, entry{off: off, n: uint32(seg.w.Offset() - off), key: s.Key})
+		b.keys[s.Key]++
+		b.total++
+		added++
+	}
+	if n := len(b.segs); n > 0 && b.segs[n-1].w != nil {
+		// 写完一批就落盘，随后的 Sample 用 ReadAt 读得到
+		if err := b.segs[n-1].w.Flush(); err != nil {
+			return added, dups, err
+		}
+	}
+	b.stats.Ingested += int64(added)
+	b.stats.Duplicates += int64(dups)
+	b.evict()
+	return added, dups, nil
+}
+
+// writable 当前可写的段；最新段写满（或是启动时留下的只读段）就开新段
+func (b *Buffer) writable() (*segment, error) {
+	if n := len(b.segs); n > 0 {
+		seg := b.segs[n-1]
+		if seg.w != nil && len(seg.entries) < b.cfg.SegmentSize {
+			return seg, nil
+		}
+		if seg.w != nil {
+			if err := seg.w.Flush(); err != nil {
+				return nil, err
+			}
+			seg.w = nil
+		}
+	}
+	id := b.nextID
+	f, err := os.OpenFile(b.segPath(id), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+	w, err := samplefmt.NewWriter(f, *b.header)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	b.nextID++
+	seg := &segment{id: id, f: f, w: w}
+	b.segs = append(b.segs, seg)
+	return seg, nil
+}
+
+func (b *Buffer) segPath(id int) string {
+	return filepath.Join(b.cfg.Dir, fmt.Sprintf("seg_%06d.pb", id))
+}
+
+// evict 去掉最旧的段，直到再去一个就不够 Capacity；正在写的段不动
+func (b *Buffer) evict() {
+	for len(b.segs) > 1 && b.total-len(b.segs[0].entries) >= b.cfg.Capacity {
+		seg := b.segs[0]
+		b.segs = b.segs[1:]
+		for _, e := range seg.entries {
+			if b.keys[e.key]--; b.keys[e.key] <= 0 {
+				delete(b.keys, e.key)
+			}
+		}
+		b.total -= len(seg.entries)
+		b.stats.Evicted += int64(len(seg.entries))
+		seg.f.Close()
+		os.Remove(b.segPath(seg.id))
+	}
+}
+
+// Sample 从窗口里均匀有放回地抽 n 条
+func (b *Buffer) Sample(n int, r *rand.Rand) ([]samplefmt.Sample, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.total == 0 {
+		return nil, ErrEmpty
+	}
+	out := make([]samplefmt.Sample, n)
+	var raw []byte
+	for i := range out {
+		seg, e := b.locate(r.Intn(b.total))
+		if cap(raw) < int(e.n) {
+			raw = make([]byte, e.n)
+		}
+		raw = raw[:e.n]
+		if _, err := seg.f.ReadAt(raw, e.off); err != nil {
+			return nil, fmt.Errorf("replaybuf: read segment %d: %w", seg.id, err)
+		}
+		size, k := binary.Uvarint(raw)
+		if k <= 0 || uint64(len(raw)-k) != size {
+			return nil, fmt.Errorf("replaybuf: segment %d offset %d: bad length prefix", seg.id, e.off)
+		}
+		if err := out[i].Unmarshal(raw[k:]); err != nil {
+			return nil, fmt.Errorf("replaybuf: segment %d offset %d: %w", seg.id, e.off, err)
+		}
+	}
+	b.stats.Served += int64(n)
+	return out, nil
+}
+
+// locate 窗口内第 i 条样本；段数不多，顺序找即可
+func (b *Buffer) locate(i int) (*segment, entry) {
+	for _, seg := range b.segs {
+		if i < len(seg.entries) {
+			return seg, seg.entries[i]
+		}
+		i -= len(seg.entries)
+	}
+	panic("replaybuf: index out of range")
+}
+
+// Header 样本流的 Header（还没收过样本时为 false）
+func (b *Buffer) Header() (samplefmt.Header, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.header == nil {
+		return samplefmt.Header{}, false
+	}
+	return *b.header, true
+}
+
+// Stats 当前状态
+func (b *Buffer) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := b.stats
+	st.Samples = b.total
+	st.Segments = len(b.segs)
+	return st
+}
+
+// Close 刷出正在写的段并关闭所有文件
+func (b *Buffer) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var first error
+	for _, seg := range b.segs {
+		if seg.w != nil {
+			if err := seg.w.Flush(); err != nil && first == nil {
+				first = err
+			}
+			seg.w = nil
+		}
+		if err := seg.f.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	b.segs = nil
+	return first
+}
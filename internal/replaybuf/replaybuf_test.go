@@ -0,0 +1,231 @@
+package replaybuf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"hexxagon_go/internal/samplefmt"
+)
+
+var testHeader = samplefmt.Header{StateShape: []uint32{1, 2, 2}, PolicyLen: 4, Generator: "test"}
+
+func testSamples(from, to int) []samplefmt.Sample {
+	var out []samplefmt.Sample
+	for k := from; k < to; k++ {
+		out = append(out, samplefmt.Sample{State: []float32{float32(k), 0, 0, 0}, Policy: []float32{1, 0, 0, 0}, Key: uint64(k)})
+	}
+	return out
+}
+
+// 窗口按段滑动：淘汰最旧的段，被淘汰的 key 可以再次收下；抽到的样本都在窗口里
+func TestBufferWindowAndDedup(t *testing.T) {
+	b, err := Open(Config{Dir: t.TempDir(), Capacity: 10, SegmentSize: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	if _, err := b.Sample(1, rand.New(rand.NewSource(1))); err != ErrEmpty {
+		t.Fatalf("空缓冲应返回 ErrEmpty，得到 %v", err)
+	}
+	if added, dups, err := b.Add(testHeader, testSamples(1, 9)); err != nil || added != 8 || dups != 0 {
+		t.Fatalf("added=%d dups=%d err=%v", added, dups, err)
+	}
+	if added, dups, _ := b.Add(testHeader, testSamples(7, 11)); added != 2 || dups != 2 {
+		t.Fatalf("窗口内已有 7、8，应去重: added=%d dups=%d", added, dups)
+	}
+	// 1..10 共 10 条；再加 4 条后最旧的段（1..4）该淘汰
+	b.Add(testHeader, testSamples(11, 15))
+	st := b.Stats()
+	if st.Samples != 10 || st.Evicted != 4 || st.Duplicates != 2 {
+		t.Fatalf("stats %+v", st)
+	}
+	if added, _, _ := b.Add(testHeader, testSamples(1, 2)); added != 1 {
+		t.Fatal("已淘汰的局面应能再次收下")
+	}
+
+	got, err := b.Sample(200, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range got {
+		if k := s.Key; k != 1 && (k < 5 || k > 14) || s.State[0] != float32(k) {
+			t.Fatalf("抽到窗口外或读错的样本: key=%d state=%v", k, s.State)
+		}
+	}
+
+	bad := testHeader
+	bad.PolicyLen = 81
+	if _, _, err := b.Add(bad, testSamples(20, 21)); err == nil {
+		t.Fatal("形状不一致应拒收")
+	}
+}
+
+// 重启后已有的段照旧在窗口里，新样本写进新段
+func TestBufferReopen(t *testing.T) {
+	dir := t.TempDir()
+	b, err := Open(Config{Dir: dir, Capacity: 100, SegmentSize: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Add(testHeader, testSamples(1, 6))
+	b.Close()
+
+	b, err = Open(Config{Dir: dir, Capacity: 100, SegmentSize: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	if st := b.Stats(); st.Samples != 5 || st.Segments != 2 {
+		t.Fatalf("重开后 %+v", st)
+	}
+	if added, dups, _ := b.Add(testHeader, testSamples(5, 7)); added != 1 || dups != 1 {
+		t.Fatalf("重开后仍应按已有 key 去重: added=%d dups=%d", added, dups)
+	}
+	if st := b.Stats(); st.Segments != 3 {
+		t.Fatalf("旧段只读，新样本应写进新段: %+v", st)
+	}
+}
+
+func TestHTTPRoundTrip(t *testing.T) {
+	b, err := Open(Config{Dir: t.TempDir(), Capacity: 100, SegmentSize: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	srv := httptest.NewServer(Handler(b))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/batch?n=4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("空缓冲取批应 503，得到 %s", resp.Status)
+	}
+
+	if err := Push(srv.Client(), srv.URL, testHeader, testSamples(1, 4)); err != nil {
+		t.Fatal(err)
+	}
+	resp, err = http.Get(srv.URL + "/batch?n=5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, samples, err := readStream(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 5 || h.PolicyLen != testHeader.PolicyLen {
+		t.Fatalf("取到 %d 条，header %+v", len(samples), h)
+	}
+
+	resp, err = http.Get(srv.URL + "/stats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var st Stats
+	err = json.NewDecoder(resp.Body).Decode(&st)
+	resp.Body.Close()
+	if err != nil || st.Samples != 3 || st.Served != 5 {
+		t.Fatalf("stats %+v err %v", st, err)
+	}
+
+	resp, err = http.Post(srv.URL+"/samples", ContentType, bytes.NewReader([]byte{0xff}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("坏的样本流应 400，得到 %s", resp.Status)
+	}
+}
+
+// 超过 MaxPushBytes 的请求体读到上限就断开，回 413
+func TestHTTPPushTooLarge(t *testing.T) {
+	b, err := Open(Config{Dir: t.TempDir(), Capacity: 100, SegmentSize: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	srv := httptest.NewServer(Handler(b))
+	defer srv.Close()
+
+	// 单条样本受 samplefmt 的消息长度限制，凑 65 条各 1MB 的
+	big := samplefmt.Sample{State: make([]float32, 1<<18), Policy: []float32{1, 0, 0, 0}}
+	samples := make([]samplefmt.Sample, MaxPushBytes>>20+1)
+	for i := range samples {
+		samples[i] = big
+	}
+	pr, pw := io.Pipe()
+	go func() { pw.CloseWithError(writeStream(pw, testHeader, samples)) }()
+	resp, err := http.Post(srv.URL+"/samples", ContentType, pr)
+	pr.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("超长请求体应 413，得到 %s", resp.Status)
+	}
+	if st := b.Stats(); st.Samples != 0 {
+		t.Fatalf("超长的推送不应收下样本: %+v", st)
+	}
+}
+
+func TestGRPCRoundTrip(t *testing.T) {
+	b, err := Open(Config{Dir: t.TempDir(), Capacity: 100, SegmentSize: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := NewGRPCServer(b)
+	go srv.Serve(lis)
+	defer srv.Stop()
+	cc, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close()
+	ctx := context.Background()
+
+	if _, _, err := BatchGRPC(ctx, cc, 4); status.Code(err) != codes.Unavailable {
+		t.Fatalf("空缓冲取批应 UNAVAILABLE，得到 %v", err)
+	}
+	if err := PushGRPC(ctx, cc, testHeader, testSamples(1, 4)); err != nil {
+		t.Fatal(err)
+	}
+	other := testHeader
+	other.PolicyLen = 9
+	if err := PushGRPC(ctx, cc, other, testSamples(5, 6)); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("形状不一致应 FAILED_PRECONDITION，得到 %v", err)
+	}
+	h, samples, err := BatchGRPC(ctx, cc, 5)
+	if err != nil || len(samples) != 5 || h.PolicyLen != testHeader.PolicyLen {
+		t.Fatalf("取到 %d 条，header %+v，err %v", len(samples), h, err)
+	}
+	if _, _, err := BatchGRPC(ctx, cc, 0); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("n=0 应 INVALID_ARGUMENT，得到 %v", err)
+	}
+	st, err := StatsGRPC(ctx, cc)
+	if err != nil || st.Samples != 3 || st.Served != 5 {
+		t.Fatalf("stats %+v err %v", st, err)
+	}
+}
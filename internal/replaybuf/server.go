@@ -0,0 +1,175 @@
+package replaybuf
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"hexxagon_go/internal/samplefmt"
+)
+
+// HTTP 接口（请求和响应体都是 samplefmt 流，Python 侧与读 .pb 分片的代码相同）：
+//
+//	POST /samples        生成器推样本：Header + 若干 Sample，至多 MaxPushBytes 字节；回 {"added":N,"duplicates":M}，超长 413
+//	GET  /batch?n=256    训练器取一批：Header + n 条从窗口里均匀抽的 Sample；窗口为空时 503
+//	GET  /stats          Stats 的 JSON
+//
+// 同样三个操作也有 gRPC 版本，见 grpc.go。
+
+// ContentType 样本流的 MIME 类型
+const ContentType = "application/x-protobuf"
+
+// MaxBatch /batch 单次最多取的样本数
+const MaxBatch = 1 << 16
+
+// MaxPushBytes 单次推送的样本流上限（HTTP 请求体与 gRPC 消息同一上限）。
+// 一局自博弈的样本一般不到 1MB，selfplay 每局推一次
+const MaxPushBytes = 64 << 20
+
+// server HTTP 与 gRPC 共用的处理逻辑
+type server struct {
+	b     *Buffer
+	rngMu sync.Mutex
+	rng   *rand.Rand
+}
+
+func newServer(b *Buffer) *server {
+	return &server{b: b, rng: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// errBadBatch 取批的 n 超出范围
+var errBadBatch = fmt.Errorf("n must be 1..%d", MaxBatch)
+
+// batch 从窗口里均匀抽 n 条；每次请求用独立的随机源，不必在抽样时持锁
+func (s *server) batch(n int) (samplefmt.Header, []samplefmt.Sample, error) {
+	if n < 1 || n > MaxBatch {
+		return samplefmt.Header{}, nil, errBadBatch
+	}
+	s.rngMu.Lock()
+	r := rand.New(rand.NewSource(s.rng.Int63()))
+	s.rngMu.Unlock()
+	samples, err := s.b.Sample(n, r)
+	if err != nil {
+		return samplefmt.Header{}, nil, err
+	}
+	h, _ := s.b.Header()
+	return h, samples, nil
+}
+
+// Handler 把 b 挂成 HTTP 服务
+func Handler(b *Buffer) http.Handler {
+	s := newServer(b)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /samples", func(w http.ResponseWriter, r *http.Request) {
+		h, samples, err := readStream(http.MaxBytesReader(w, r.Body, MaxPushBytes))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("sample stream exceeds %d bytes", MaxPushBytes), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		added, dups, err := b.Add(h, samples)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		writeJSON(w, map[string]int{"added": added, "duplicates": dups})
+	})
+	mux.HandleFunc("GET /batch", func(w http.ResponseWriter, r *http.Request) {
+		n := 256
+		if q := r.URL.Query().Get("n"); q != "" {
+			v, err := strconv.Atoi(q)
+			if err != nil {
+				http.Error(w, errBadBatch.Error(), http.StatusBadRequest)
+				return
+			}
+			n = v
+		}
+		h, samples, err := s.batch(n)
+		switch {
+		case errors.Is(err, errBadBatch):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, ErrEmpty):
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", ContentType)
+		if err := writeStream(w, h, samples); err != nil {
+			// 头已经发出，只能断开
+			panic(http.ErrAbortHandler)
+		}
+	})
+	mux.HandleFunc("GET /stats", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, b.Stats())
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func readStream(r io.Reader) (samplefmt.Header, []samplefmt.Sample, error) {
+	sr, err := samplefmt.NewReader(r)
+	if err != nil {
+		return samplefmt.Header{}, nil, err
+	}
+	var samples []samplefmt.Sample
+	for {
+		var s samplefmt.Sample
+		err := sr.Next(&s)
+		if err == io.EOF {
+			return sr.Header, samples, nil
+		}
+		if err != nil {
+			return samplefmt.Header{}, nil, err
+		}
+		samples = append(samples, s)
+	}
+}
+
+func writeStream(w io.Writer, h samplefmt.Header, samples []samplefmt.Sample) error {
+	sw, err := samplefmt.NewWriter(w, h)
+	if err != nil {
+		return err
+	}
+	for i := range samples {
+		if err := sw.Write(&samples[i]); err != nil {
+			return err
+		}
+	}
+	return sw.Flush()
+}
+
+// Push 把一批样本推给 url 上的回放缓冲（url 形如 http://host:7790）
+func Push(client *http.Client, url string, h samplefmt.Header, samples []samplefmt.Sample) error {
+	var body bytes.Buffer
+	if err := writeStream(&body, h, samples); err != nil {
+		return err
+	}
+	resp, err := client.Post(url+"/samples", ContentType, &body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("replaybuf push: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package samplefmt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// AuxRecord 每个样本的辅助字段，selfplay 写入 _M.bin，供训练侧做阶段均衡或按“意外程度”加权抽样。
+// 固定 AuxRecordSize 字节小端，字段顺序与 AuxDtype（meta.json 里的 aux_dtype）一致，可直接 numpy.fromfile，
+// 也可以用 binary.Write/binary.Read 整条读写。
+type AuxRecord struct {
+	Ply      uint16  // 该局第几手（随机开局之后从 0 计）
+	Empties  uint8   // 空格数
+	Phase    uint8   // 0=开局 1=中局 2=残局
+	AbsValue float32 // |价值标签|
+	Entropy  float32 // 搜索访问分布的熵（nat）
+	Game     uint32  // 本次运行内的对局编号，由 writer 按到达顺序分配
+	Opponent uint32  // league 对手编号（见 run_meta.json），0 = 自对弈
+}
+
+const AuxRecordSize = 20
+
+var AuxDtype = [][2]string{
+	{"ply", "<u2"}, {"empties", "u1"}, {"phase", "u1"},
+	{"abs_value", "<f4"}, {"entropy", "<f4"}, {"game", "<u4"}, {"opponent", "<u4"},
+}
+
+// DecodeAux 解析整个 _M.bin 的内容；长度不是 AuxRecordSize 的整数倍时报错
+func DecodeAux(b []byte) ([]AuxRecord, error) {
+	if len(b)%AuxRecordSize != 0 {
+		return nil, fmt.Errorf("aux records: %d bytes is not a multiple of %d", len(b), AuxRecordSize)
+	}
+	out := make([]AuxRecord, len(b)/AuxRecordSize)
+	for i := range out {
+		r := b[i*AuxRecordSize:]
+		out[i] = AuxRecord{
+			Ply:      binary.LittleEndian.Uint16(r[0:]),
+			Empties:  r[2],
+			Phase:    r[3],
+			AbsValue: math.Float32frombits(binary.LittleEndian.Uint32(r[4:])),
+			Entropy:  math.Float32frombits(binary.LittleEndian.Uint32(r[8:])),
+			Game:     binary.LittleEndian.Uint32(r[12:]),
+			Opponent: binary.LittleEndian.Uint32(r[16:]),
+		}
+	}
+	return out, nil
+}
+
+// SampleAux 从 .pb 样本取出与 _M.bin 相同的辅助字段
+func SampleAux(s *Sample) AuxRecord {
+	return AuxRecord{
+		Ply: uint16(s.Ply), Empties: uint8(s.Empties), Phase: uint8(s.Phase),
+		AbsValue: float32(math.Abs(float64(s.Value))), Entropy: s.Entropy,
+		Game: s.Game, Opponent: s.Opponent,
+	}
+}
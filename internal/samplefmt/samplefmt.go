@@ -0,0 +1,261 @@
+// Package samplefmt 读写 samples.proto 描述的训练样本流：
+// 一个 Header 加若干 Sample，每条消息前带 varint 长度。
+// 与 X/P/Z 三个裸数组不同，形状、平面含义和生成参数都随文件走，Python 侧按 .proto 生成代码即可解析。
+package samplefmt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// SchemaVersion 破坏性改动时递增；只追加字段不需要改
+const SchemaVersion = 1
+
+// 单条消息的长度上限，防止读到损坏的长度前缀时一次分配过大
+const maxMessageLen = 1 << 24
+
+type Header struct {
+	SchemaVersion uint32
+	StateShape    []uint32
+	PolicyLen     uint32
+	Generator     string
+	Planes        []string
+	RunMetaJSON   string
+}
+
+type Sample struct {
+	State    []float32
+	Policy   []float32
+	Value    int32
+	Ply      uint32
+	Empties  uint32
+	Phase    uint32
+	Entropy  float32
+	Game     uint32
+	Opponent uint32
+	Key      uint64
+
+	// 可选的附加价值目标（生成端未开启时为零值/空）
+	Score     float32
+	Ownership []int32
+}
+
+func (h *Header) marshal() []byte {
+	var e encoder
+	e.uint32Field(1, h.SchemaVersion)
+	e.packedUint32s(2, h.StateShape)
+	e.uint32Field(3, h.PolicyLen)
+	e.stringField(4, h.Generator)
+	for _, p := range h.Planes {
+		e.tag(5, wireBytes)
+		e.buf = binary.AppendUvarint(e.buf, uint64(len(p)))
+		e.buf = append(e.buf, p...)
+	}
+	e.stringField(6, h.RunMetaJSON)
+	return e.buf
+}
+
+func (h *Header) unmarshal(b []byte) error {
+	d := decoder{buf: b}
+	for d.more() {
+		field, wire := d.tag()
+		switch {
+		case field == 1 && wire == wireVarint:
+			h.SchemaVersion = uint32(d.varint())
+		case field == 2:
+			h.StateShape = d.uint32s(wire, h.StateShape)
+		case field == 3 && wire == wireVarint:
+			h.PolicyLen = uint32(d.varint())
+		case field == 4 && wire == wireBytes:
+			h.Generator = string(d.bytes())
+		case field == 5 && wire == wireBytes:
+			h.Planes = append(h.Planes, string(d.bytes()))
+		case field == 6 && wire == wireBytes:
+			h.RunMetaJSON = string(d.bytes())
+		default:
+			d.skip(wire)
+		}
+	}
+	return d.err
+}
+
+// AppendMarshal 把 s 编码后追加到 buf
+func (s *Sample) AppendMarshal(buf []byte) []byte {
+	e := encoder{buf: buf}
+	e.packedFloats(1, s.State)
+	e.packedFloats(2, s.Policy)
+	e.sint32Field(3, s.Value)
+	e.uint32Field(4, s.Ply)
+	e.uint32Field(5, s.Empties)
+	e.uint32Field(6, s.Phase)
+	e.floatField(7, s.Entropy)
+	e.uint32Field(8, s.Game)
+	e.uint32Field(9, s.Opponent)
+	e.fixed64Field(10, s.Key)
+	e.floatField(11, s.Score)
+	e.packedSint32s(12, s.Ownership)
+	return e.buf
+}
+
+func (s *Sample) Unmarshal(b []byte) error {
+	*s = Sample{State: s.State[:0], Policy: s.Policy[:0], Ownership: s.Ownership[:0]}
+	d := decoder{buf: b}
+	for d.more() {
+		field, wire := d.tag()
+		switch {
+		case field == 1:
+			s.State = d.floats(wire, s.State)
+		case field == 2:
+			s.Policy = d.floats(wire, s.Policy)
+		case field == 3 && wire == wireVarint:
+			s.Value = d.sint32()
+		case field == 4 && wire == wireVarint:
+			s.Ply = uint32(d.varint())
+		case field == 5 && wire == wireVarint:
+			s.Empties = uint32(d.varint())
+		case field == 6 && wire == wireVarint:
+			s.Phase = uint32(d.varint())
+		case field == 7 && wire == wireFixed32:
+			s.Entropy = math.Float32frombits(d.fixed32())
+		case field == 8 && wire == wireVarint:
+			s.Game = uint32(d.varint())
+		case field == 9 && wire == wireVarint:
+			s.Opponent = uint32(d.varint())
+		case field == 10 && wire == wireFixed64:
+			s.Key = d.fixed64()
+		case field == 11 && wire == wireFixed32:
+			s.Score = math.Float32frombits(d.fixed32())
+		case field == 12:
+			s.Ownership = d.sint32s(wire, s.Ownership)
+		default:
+			d.skip(wire)
+		}
+	}
+	return d.err
+}
+
+// Writer 顺序写一个样本流；NewWriter 时立即写出 Header。用完需 Flush。
+type Writer struct {
+	w   *bufio.Writer
+	buf []byte
+	n   int
+	off int64
+}
+
+func NewWriter(w io.Writer, h Header) (*Writer, error) {
+	if h.SchemaVersion == 0 {
+		h.SchemaVersion = SchemaVersion
+	}
+	sw := &Writer{w: bufio.NewWriterSize(w, 1<<16)}
+	if err := sw.writeMessage(h.marshal()); err != nil {
+		return nil, err
+	}
+	return sw, nil
+}
+
+// AppendWriter 接着一个已有的流往后写（Header 已在 w 之前写过），off/n 为流中已有的字节数和样本数；
+// 续写中断的分片时用，调用方负责先把文件截到一条完整消息的边界
+func AppendWriter(w io.Writer, off int64, n int) *Writer {
+	return &Writer{w: bufio.NewWriterSize(w, 1<<16), n: n, off: off}
+}
+
+func (w *Writer) Write(s *Sample) error {
+	w.buf = s.AppendMarshal(w.buf[:0])
+	if err := w.writeMessage(w.buf); err != nil {
+		return err
+	}
+	w.n++
+	return nil
+}
+
+// Count 已写出的样本数
+func (w *Writer) Count() int { return w.n }
+
+// Offset 已写出的字节数（含还在缓冲里的），即下一条消息在流中的起点
+func (w *Writer) Offset() int64 { return w.off }
+
+func (w *Writer) Flush() error { return w.w.Flush() }
+
+func (w *Writer) writeMessage(b []byte) error {
+	var lenBuf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(lenBuf[:], uint64(len(b)))
+	if _, err := w.w.Write(lenBuf[:n]); err != nil {
+		return err
+	}
+	if _, err := w.w.Write(b); err != nil {
+		return err
+	}
+	w.off += int64(n + len(b))
+	return nil
+}
+
+// Reader 顺序读样本流
+type Reader struct {
+	Header Header
+
+	r   *bufio.Reader
+	buf []byte
+	off int64
+}
+
+// NewReader 读取并校验 Header；SchemaVersion 比本包新时报错
+func NewReader(r io.Reader) (*Reader, error) {
+	sr := &Reader{r: bufio.NewReaderSize(r, 1<<16)}
+	b, err := sr.readMessage()
+	if err == io.EOF {
+		return nil, errors.New("samplefmt: empty stream")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := sr.Header.unmarshal(b); err != nil {
+		return nil, fmt.Errorf("samplefmt header: %w", err)
+	}
+	if sr.Header.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("samplefmt: schema version %d is newer than supported %d", sr.Header.SchemaVersion, SchemaVersion)
+	}
+	return sr, nil
+}
+
+// Offset 已读过的字节数，即下一条消息在流中的起点
+func (r *Reader) Offset() int64 { return r.off }
+
+// Next 读下一条样本到 s（复用其切片）；流结束返回 io.EOF
+func (r *Reader) Next(s *Sample) error {
+	b, err := r.readMessage()
+	if err != nil {
+		return err
+	}
+	return s.Unmarshal(b)
+}
+
+func (r *Reader) readMessage() ([]byte, error) {
+	n, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, errTruncated
+		}
+		return nil, err
+	}
+	if n > maxMessageLen {
+		return nil, fmt.Errorf("samplefmt: message length %d exceeds limit", n)
+	}
+	if cap(r.buf) < int(n) {
+		r.buf = make([]byte, n)
+	}
+	r.buf = r.buf[:n]
+	if _, err := io.ReadFull(r.r, r.buf); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, errTruncated
+		}
+		// 底层读出错（如 http.MaxBytesReader 超限）原样交给调用方
+		return nil, err
+	}
+	var lenBuf [binary.MaxVarintLen64]byte
+	r.off += int64(binary.PutUvarint(lenBuf[:], n)) + int64(n)
+	return r.buf, nil
+}
@@ -0,0 +1,193 @@
+package samplefmt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// 与 protoc 生成代码的编码逐字节对照（字段号、zigzag、fixed64、默认值省略）
+func TestSampleWireBytes(t *testing.T) {
+	s := Sample{Value: -1, Ply: 3, Key: 1}
+	want := []byte{
+		0x18, 0x01, // value = -1 (sint32 zigzag)
+		0x20, 0x03, // ply = 3
+		0x51, 1, 0, 0, 0, 0, 0, 0, 0, // key = 1 (fixed64)
+	}
+	if got := s.AppendMarshal(nil); !bytes.Equal(got, want) {
+		t.Fatalf("编码 % x，期望 % x", got, want)
+	}
+
+	s = Sample{Policy: []float32{1}}
+	want = []byte{0x12, 0x04, 0x00, 0x00, 0x80, 0x3f} // packed float
+	if got := s.AppendMarshal(nil); !bytes.Equal(got, want) {
+		t.Fatalf("编码 % x，期望 % x", got, want)
+	}
+
+	s = Sample{Ownership: []int32{1, -1, 0}}
+	want = []byte{0x62, 0x03, 0x02, 0x01, 0x00} // packed sint32
+	if got := s.AppendMarshal(nil); !bytes.Equal(got, want) {
+		t.Fatalf("编码 % x，期望 % x", got, want)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	h := Header{
+		StateShape:  []uint32{3, 9, 9},
+		PolicyLen:   81,
+		Generator:   "test",
+		Planes:      []string{"mine", "opponent", "off_board"},
+		RunMetaJSON: `{"sims":800}`,
+	}
+	samples := []Sample{
+		{State: []float32{1, 0, 0.5}, Policy: []float32{0.25, 0.75}, Value: 1, Ply: 7, Empties: 40, Phase: 1, Entropy: 0.56, Game: 2, Opponent: 3, Key: 0xdeadbeefcafef00d,
+			Score: -0.25, Ownership: []int32{1, -1, 0, 1}},
+		{State: []float32{0}, Policy: []float32{1}, Value: -1},
+		{Value: 0},
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range samples {
+		if err := w.Write(&samples[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	total := int64(buf.Len())
+	if w.Offset() != total {
+		t.Fatalf("写端偏移 %d，流长 %d", w.Offset(), total)
+	}
+
+	r, err := NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.SchemaVersion = SchemaVersion
+	if !reflect.DeepEqual(r.Header, h) {
+		t.Fatalf("header %+v，期望 %+v", r.Header, h)
+	}
+	for i, want := range samples {
+		var got Sample
+		if err := r.Next(&got); err != nil {
+			t.Fatalf("样本 %d: %v", i, err)
+		}
+		if len(got.State) == 0 {
+			got.State = nil
+		}
+		if len(got.Policy) == 0 {
+			got.Policy = nil
+		}
+		if len(got.Ownership) == 0 {
+			got.Ownership = nil
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("样本 %d: %+v，期望 %+v", i, got, want)
+		}
+	}
+	var s Sample
+	if err := r.Next(&s); err != io.EOF {
+		t.Fatalf("流末尾应返回 io.EOF，得到 %v", err)
+	}
+	if r.Offset() != total {
+		t.Fatalf("读端偏移 %d，流长 %d", r.Offset(), total)
+	}
+}
+
+func TestUnknownFieldsSkipped(t *testing.T) {
+	b := (&Sample{Ply: 5}).AppendMarshal(nil)
+	// 追加未来版本的字段：99 号 varint 与 100 号 bytes
+	b = append(b, 0x98, 0x06, 0x2a, 0xa2, 0x06, 0x02, 'h', 'i')
+	var s Sample
+	if err := s.Unmarshal(b); err != nil {
+		t.Fatal(err)
+	}
+	if s.Ply != 5 {
+		t.Fatalf("ply = %d", s.Ply)
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	b := (&Sample{Policy: []float32{1, 2}}).AppendMarshal(nil)
+	var s Sample
+	if err := s.Unmarshal(b[:len(b)-1]); err == nil {
+		t.Fatal("截断的消息应报错")
+	}
+}
+
+// 写到一半截断在消息边界，AppendWriter 接着写，读出来与一口气写完相同
+func TestAppendWriter(t *testing.T) {
+	h := Header{PolicyLen: 81, Generator: "test"}
+	samples := []Sample{{Ply: 1, Value: 1}, {Ply: 2, Value: -1}, {Ply: 3}}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(&samples[0])
+	w.Flush()
+	off, n := w.Offset(), w.Count()
+	w.Write(&samples[1]) // 这一条“崩溃时”没记进进度，续写时丢掉
+	w.Flush()
+
+	buf.Truncate(int(off))
+	w = AppendWriter(&buf, off, n)
+	for i := 1; i < len(samples); i++ {
+		if err := w.Write(&samples[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if w.Count() != len(samples) || w.Offset() != int64(buf.Len()) {
+		t.Fatalf("count=%d offset=%d，流长 %d", w.Count(), w.Offset(), buf.Len())
+	}
+
+	r, err := NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range samples {
+		var s Sample
+		if err := r.Next(&s); err != nil {
+			t.Fatalf("第 %d 条: %v", i, err)
+		}
+		if s.Ply != samples[i].Ply || s.Value != samples[i].Value {
+			t.Fatalf("第 %d 条 = %+v，期望 %+v", i, s, samples[i])
+		}
+	}
+	if err := r.Next(new(Sample)); err != io.EOF {
+		t.Fatalf("流末尾应 io.EOF，得到 %v", err)
+	}
+}
+
+// binary.Write 写出的 _M.bin 正好 AuxRecordSize 字节/条，DecodeAux 读回原样
+func TestAuxRecord(t *testing.T) {
+	recs := []AuxRecord{
+		{Ply: 300, Empties: 12, Phase: 2, AbsValue: 1, Entropy: 0.75, Game: 7, Opponent: 3},
+		{Ply: 1, Empties: 58, Game: 1 << 20},
+	}
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, recs); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != len(recs)*AuxRecordSize {
+		t.Fatalf("%d 字节，期望 %d", buf.Len(), len(recs)*AuxRecordSize)
+	}
+	got, err := DecodeAux(buf.Bytes())
+	if err != nil || !reflect.DeepEqual(got, recs) {
+		t.Fatalf("DecodeAux = %+v, %v", got, err)
+	}
+	if _, err := DecodeAux(buf.Bytes()[1:]); err == nil {
+		t.Fatal("长度不整应报错")
+	}
+}
@@ -0,0 +1,110 @@
+package samplefmt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+)
+
+// TFRecord 输出：每条记录一个 tf.train.Example，特征按名字取——
+// X、P（float_list，展平）、Z（int64_list），辅助量 ply/empties/phase/game/opponent/key（int64_list）、entropy（float_list），
+// 开启附加目标时另有 S（float_list）、O（int64_list）。
+// tf.data.TFRecordDataset 加 tf.io.parse_single_example 直接读；X 的形状在分片的 meta.json 里。
+//
+// 记录格式：长度 uint64、长度的 masked CRC32C、数据、数据的 masked CRC32C，均为小端。
+
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+// maskedCRC TFRecord 规定的 CRC 掩码
+func maskedCRC(b []byte) uint32 {
+	c := crc32.Checksum(b, crc32c)
+	return (c>>15 | c<<17) + 0xa282ead8
+}
+
+// TFRecordWriter 顺序写 TFRecord；没有文件头，续写中断的分片时截到记录边界、新建一个接着写即可。用完需 Flush
+type TFRecordWriter struct {
+	w     *bufio.Writer
+	Score bool // 写 S 特征（Sample.Score 为零值时分不清是否开启，由调用方说）
+	ex    encoder
+	n     int
+}
+
+func NewTFRecordWriter(w io.Writer) *TFRecordWriter {
+	return &TFRecordWriter{w: bufio.NewWriterSize(w, 1<<16)}
+}
+
+func (w *TFRecordWriter) Write(s *Sample) error {
+	var fs encoder // Features
+	featureFloats(&fs, "X", s.State)
+	featureFloats(&fs, "P", s.Policy)
+	featureInts(&fs, "Z", int64(s.Value))
+	featureInts(&fs, "ply", int64(s.Ply))
+	featureInts(&fs, "empties", int64(s.Empties))
+	featureInts(&fs, "phase", int64(s.Phase))
+	featureFloats(&fs, "entropy", []float32{s.Entropy})
+	featureInts(&fs, "game", int64(s.Game))
+	featureInts(&fs, "opponent", int64(s.Opponent))
+	featureInts(&fs, "key", int64(s.Key))
+	if w.Score {
+		featureFloats(&fs, "S", []float32{s.Score})
+	}
+	if len(s.Ownership) > 0 {
+		own := make([]int64, len(s.Ownership))
+		for i, o := range s.Ownership {
+			own[i] = int64(o)
+		}
+		featureInts(&fs, "O", own...)
+	}
+	w.ex.buf = w.ex.buf[:0]
+	w.ex.message(1, fs.buf) // Example.features
+
+	var hdr [12]byte
+	binary.LittleEndian.PutUint64(hdr[:8], uint64(len(w.ex.buf)))
+	binary.LittleEndian.PutUint32(hdr[8:], maskedCRC(hdr[:8]))
+	if _, err := w.w.Write(hdr[:]); err != nil {
+		return err
+	}
+	if _, err := w.w.Write(w.ex.buf); err != nil {
+		return err
+	}
+	if err := binary.Write(w.w, binary.LittleEndian, maskedCRC(w.ex.buf)); err != nil {
+		return err
+	}
+	w.n++
+	return nil
+}
+
+// Count 已写出的记录数
+func (w *TFRecordWriter) Count() int { return w.n }
+
+func (w *TFRecordWriter) Flush() error { return w.w.Flush() }
+
+// featureFloats Features.feature 的一项：map<string, Feature>，值为 Feature.float_list
+func featureFloats(fs *encoder, name string, vs []float32) {
+	var list encoder
+	list.packedFloats(1, vs)
+	var feat encoder
+	feat.message(2, list.buf)
+	featureEntry(fs, name, feat.buf)
+}
+
+// featureInts 值为 Feature.int64_list（packed varint，负数按补码）
+func featureInts(fs *encoder, name string, vs ...int64) {
+	var body []byte
+	for _, v := range vs {
+		body = binary.AppendUvarint(body, uint64(v))
+	}
+	var list encoder
+	list.message(1, body)
+	var feat encoder
+	feat.message(3, list.buf)
+	featureEntry(fs, name, feat.buf)
+}
+
+func featureEntry(fs *encoder, name string, feature []byte) {
+	var entry encoder
+	entry.stringField(1, name)
+	entry.message(2, feature)
+	fs.message(1, entry.buf)
+}
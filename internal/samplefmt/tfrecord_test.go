@@ -0,0 +1,120 @@
+package samplefmt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+)
+
+// parseExample 把 tf.train.Example 解回 名字 → 原始 Feature 字节
+func parseExample(t *testing.T, b []byte) map[string][]byte {
+	t.Helper()
+	out := map[string][]byte{}
+	ex := decoder{buf: b}
+	for ex.more() {
+		if f, w := ex.tag(); f != 1 || w != wireBytes {
+			t.Fatalf("Example 字段 %d/%d", f, w)
+		}
+		fs := decoder{buf: ex.bytes()}
+		for fs.more() {
+			fs.tag()
+			entry := decoder{buf: fs.bytes()}
+			entry.tag()
+			name := string(entry.bytes())
+			entry.tag()
+			out[name] = entry.bytes()
+		}
+		if fs.err != nil {
+			t.Fatal(fs.err)
+		}
+	}
+	return out
+}
+
+func featureInt64s(t *testing.T, feat []byte) []int64 {
+	t.Helper()
+	d := decoder{buf: feat}
+	if f, _ := d.tag(); f != 3 {
+		t.Fatalf("应为 int64_list，得到字段 %d", f)
+	}
+	list := decoder{buf: d.bytes()}
+	list.tag()
+	vals := decoder{buf: list.bytes()}
+	var out []int64
+	for vals.more() {
+		out = append(out, int64(vals.varint()))
+	}
+	return out
+}
+
+func TestTFRecordWriter(t *testing.T) {
+	if got := crc32.Checksum([]byte("123456789"), crc32c); got != 0xe3069283 {
+		t.Fatalf("CRC32C 校验值 %#x", got)
+	}
+
+	var buf bytes.Buffer
+	w := NewTFRecordWriter(&buf)
+	w.Score = true
+	samples := []Sample{
+		{State: []float32{1, 0, 0.5}, Policy: []float32{0.25, 0.75}, Value: -1, Ply: 7, Score: -0.5, Ownership: []int32{1, -1, 0}},
+		{State: []float32{0}, Policy: []float32{1}, Value: 1, Key: 1 << 63},
+	}
+	for i := range samples {
+		if err := w.Write(&samples[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	b := buf.Bytes()
+	for i, s := range samples {
+		if len(b) < 16 {
+			t.Fatalf("记录 %d 截断", i)
+		}
+		n := binary.LittleEndian.Uint64(b)
+		if binary.LittleEndian.Uint32(b[8:]) != maskedCRC(b[:8]) {
+			t.Fatalf("记录 %d 长度 CRC 不对", i)
+		}
+		data := b[12 : 12+n]
+		if binary.LittleEndian.Uint32(b[12+n:]) != maskedCRC(data) {
+			t.Fatalf("记录 %d 数据 CRC 不对", i)
+		}
+		b = b[16+n:]
+
+		feats := parseExample(t, data)
+		for _, name := range []string{"X", "P", "Z", "ply", "entropy", "key", "S"} {
+			if feats[name] == nil {
+				t.Fatalf("记录 %d 缺特征 %s", i, name)
+			}
+		}
+		if z := featureInt64s(t, feats["Z"]); len(z) != 1 || z[0] != int64(s.Value) {
+			t.Fatalf("记录 %d Z = %v", i, z)
+		}
+		if k := featureInt64s(t, feats["key"]); uint64(k[0]) != s.Key {
+			t.Fatalf("记录 %d key = %v", i, k)
+		}
+		if (feats["O"] != nil) != (len(s.Ownership) > 0) {
+			t.Fatalf("记录 %d 的 O 特征应只在有归属目标时出现", i)
+		}
+		if s.Ownership != nil {
+			if o := featureInt64s(t, feats["O"]); len(o) != 3 || o[1] != -1 {
+				t.Fatalf("O = %v", o)
+			}
+		}
+		x := decoder{buf: feats["X"]}
+		if f, _ := x.tag(); f != 2 {
+			t.Fatalf("X 应为 float_list，得到字段 %d", f)
+		}
+		list := decoder{buf: x.bytes()}
+		list.tag()
+		if got := list.floats(wireBytes, nil); len(got) != len(s.State) || got[0] != s.State[0] {
+			t.Fatalf("X = %v", got)
+		}
+	}
+	if len(b) != 0 || w.Count() != len(samples) {
+		t.Fatalf("多出 %d 字节，count=%d", len(b), w.Count())
+	}
+}
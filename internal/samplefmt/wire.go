@@ -0,0 +1,246 @@
+package samplefmt
+
+// 手写的 protobuf 线格式编解码，只覆盖 samples.proto 用到的类型，免得为两个消息引入 protobuf 运行时。
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("samplefmt: truncated message")
+
+type encoder struct{ buf []byte }
+
+func (e *encoder) tag(field, wire int) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(field)<<3|uint64(wire))
+}
+
+func (e *encoder) uint32Field(field int, v uint32) {
+	if v == 0 {
+		return
+	}
+	e.tag(field, wireVarint)
+	e.buf = binary.AppendUvarint(e.buf, uint64(v))
+}
+
+func (e *encoder) sint32Field(field int, v int32) {
+	if v == 0 {
+		return
+	}
+	e.tag(field, wireVarint)
+	e.buf = binary.AppendUvarint(e.buf, uint64(uint32(v<<1)^uint32(v>>31)))
+}
+
+func (e *encoder) floatField(field int, v float32) {
+	if v == 0 {
+		return
+	}
+	e.tag(field, wireFixed32)
+	e.buf = binary.LittleEndian.AppendUint32(e.buf, math.Float32bits(v))
+}
+
+func (e *encoder) fixed64Field(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	e.tag(field, wireFixed64)
+	e.buf = binary.LittleEndian.AppendUint64(e.buf, v)
+}
+
+func (e *encoder) stringField(field int, s string) {
+	if s == "" {
+		return
+	}
+	e.tag(field, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+// message 嵌套消息或 bytes 字段；与标量不同，空的也要写出（表示字段存在）
+func (e *encoder) message(field int, b []byte) {
+	e.tag(field, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// packedFloats proto3 的 repeated float 默认 packed
+func (e *encoder) packedFloats(field int, vs []float32) {
+	if len(vs) == 0 {
+		return
+	}
+	e.tag(field, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(4*len(vs)))
+	for _, v := range vs {
+		e.buf = binary.LittleEndian.AppendUint32(e.buf, math.Float32bits(v))
+	}
+}
+
+func (e *encoder) packedUint32s(field int, vs []uint32) {
+	if len(vs) == 0 {
+		return
+	}
+	var body []byte
+	for _, v := range vs {
+		body = binary.AppendUvarint(body, uint64(v))
+	}
+	e.tag(field, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(body)))
+	e.buf = append(e.buf, body...)
+}
+
+func (e *encoder) packedSint32s(field int, vs []int32) {
+	if len(vs) == 0 {
+		return
+	}
+	var body []byte
+	for _, v := range vs {
+		body = binary.AppendUvarint(body, uint64(uint32(v<<1)^uint32(v>>31)))
+	}
+	e.tag(field, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(body)))
+	e.buf = append(e.buf, body...)
+}
+
+// decoder 按字段顺序读；调用方对不认识的字段调用 skip，以兼容新版本追加的字段
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) more() bool { return d.err == nil && len(d.buf) > 0 }
+
+func (d *decoder) varint() uint64 {
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) tag() (field, wire int) {
+	t := d.varint()
+	return int(t >> 3), int(t & 7)
+}
+
+func (d *decoder) fixed32() uint32 {
+	if len(d.buf) < 4 {
+		d.fail()
+		return 0
+	}
+	v := binary.LittleEndian.Uint32(d.buf)
+	d.buf = d.buf[4:]
+	return v
+}
+
+func (d *decoder) fixed64() uint64 {
+	if len(d.buf) < 8 {
+		d.fail()
+		return 0
+	}
+	v := binary.LittleEndian.Uint64(d.buf)
+	d.buf = d.buf[8:]
+	return v
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.varint()
+	if d.err != nil || uint64(len(d.buf)) < n {
+		d.fail()
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) sint32() int32 {
+	v := uint32(d.varint())
+	return int32(v>>1) ^ -int32(v&1)
+}
+
+// floats 同时接受 packed 与逐个编码（proto 规范要求解析器两种都认）
+func (d *decoder) floats(wire int, dst []float32) []float32 {
+	switch wire {
+	case wireFixed32:
+		return append(dst, math.Float32frombits(d.fixed32()))
+	case wireBytes:
+		b := d.bytes()
+		if len(b)%4 != 0 {
+			d.fail()
+			return dst
+		}
+		for i := 0; i < len(b); i += 4 {
+			dst = append(dst, math.Float32frombits(binary.LittleEndian.Uint32(b[i:])))
+		}
+		return dst
+	}
+	d.skip(wire)
+	return dst
+}
+
+func (d *decoder) uint32s(wire int, dst []uint32) []uint32 {
+	switch wire {
+	case wireVarint:
+		return append(dst, uint32(d.varint()))
+	case wireBytes:
+		sub := decoder{buf: d.bytes()}
+		for sub.more() {
+			dst = append(dst, uint32(sub.varint()))
+		}
+		if sub.err != nil {
+			d.fail()
+		}
+		return dst
+	}
+	d.skip(wire)
+	return dst
+}
+
+func (d *decoder) sint32s(wire int, dst []int32) []int32 {
+	switch wire {
+	case wireVarint:
+		return append(dst, d.sint32())
+	case wireBytes:
+		sub := decoder{buf: d.bytes()}
+		for sub.more() {
+			dst = append(dst, sub.sint32())
+		}
+		if sub.err != nil {
+			d.fail()
+		}
+		return dst
+	}
+	d.skip(wire)
+	return dst
+}
+
+func (d *decoder) skip(wire int) {
+	switch wire {
+	case wireVarint:
+		d.varint()
+	case wireFixed64:
+		d.fixed64()
+	case wireBytes:
+		d.bytes()
+	case wireFixed32:
+		d.fixed32()
+	default:
+		d.err = errors.New("samplefmt: unsupported wire type")
+	}
+}
+
+func (d *decoder) fail() {
+	if d.err == nil {
+		d.err = errTruncated
+	}
+}
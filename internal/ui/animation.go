@@ -22,13 +22,14 @@ type FrameAnim struct {
 	To         game.HexCoord // 目标格
 	MidX, MidY float64       // new: pixel midpoint in offscreen coords
 	FrameIndex int
+	Piece      game.PieceID // 播的是哪颗子（跳跃/克隆的子、被感染的子）；0 = 不对应具体棋子
 }
 
-func (a *FrameAnim) Current() *ebiten.Image {
+func (a *FrameAnim) Current(now time.Time) *ebiten.Image {
 	if len(a.Frames) == 0 {
 		return nil
 	}
-	elapsed := time.Since(a.Start).Seconds()
+	elapsed := now.Sub(a.Start).Seconds()
 	if elapsed < 0 {
 		a.FrameIndex = 0
 		return nil // 尚未到起始时间
@@ -43,6 +44,11 @@ func (a *FrameAnim) Current() *ebiten.Image {
 	return a.Frames[idx]
 }
 
+// finished 按时间判断是否播完；Update 据此清理，不依赖 Draw 有没有被调用（最小化、无头测试）
+func (a *FrameAnim) finished(now time.Time) bool {
+	return a.Done || now.Sub(a.Start).Seconds()*a.FPS >= float64(len(a.Frames))
+}
+
 var dirAngle = map[[2]int]float64{
 	{+1, 0}:  0,
 	{+1, -1}: -math.Pi / 3,
@@ -66,7 +72,7 @@ func (gs *GameScreen) startInfectAnim(from, to game.HexCoord, player game.CellSt
 	anim := &FrameAnim{
 		Frames: frames,
 		FPS:    5,
-		Start:  time.Now(),
+		Start:  gs.now(),
 		Coord:  to,            // 在被感染格播放
 		Angle:  dirAngle[key], // 旋转角
 		Key:    base,          // ✅ 渲染时要用来查 trimOffsets / AnimOffset
@@ -75,7 +81,7 @@ func (gs *GameScreen) startInfectAnim(from, to game.HexCoord, player game.CellSt
 }
 
 // 启动跳跃 / 复制动画
-func (gs *GameScreen) addMoveAnim(move game.Move, player game.CellState) {
+func (gs *GameScreen) addMoveAnim(move game.Move, player game.CellState, piece game.PieceID) {
 	dirKey := directionKey(move.From, move.To)
 
 	base := ""
@@ -98,11 +104,12 @@ func (gs *GameScreen) addMoveAnim(move game.Move, player game.CellState) {
 	//fmt.Println("ADD", base, "off=", AnimOffset[base])
 	gs.anims = append(gs.anims, &FrameAnim{
 		Frames: frames,
-		FPS:    30,
-		Start:  time.Now(),
+		FPS:    gs.animFPS(),
+		Start:  gs.now(),
 		Coord:  move.From,
 		Angle:  0,
 		Key:    base, // ← Draw() 里会用这个 key 去取 AnimOffset[base]
+		Piece:  piece,
 	})
 }
 
@@ -113,6 +120,7 @@ func (gs *GameScreen) addInfectAnim(
 	from, to game.HexCoord,
 	player game.CellState,
 	delay time.Duration, // 新增：启动延迟
+	piece game.PieceID, // 被感染的那颗子
 ) {
 	base := "redEatWhite"
 	if player == game.PlayerB {
@@ -142,8 +150,8 @@ func (gs *GameScreen) addInfectAnim(
 	//fmt.Printf("ang %v", ang)
 	gs.anims = append(gs.anims, &FrameAnim{
 		Frames: frames,
-		FPS:    30,
-		Start:  time.Now().Add(delay), // ← 这里用 delay
+		FPS:    gs.animFPS(),
+		Start:  gs.now().Add(delay), // ← 这里用 delay
 		Coord:  from,
 		From:   from,
 		To:     to,
@@ -151,6 +159,7 @@ func (gs *GameScreen) addInfectAnim(
 		Key:    base,
 		MidY:   midY,
 		MidX:   midX,
+		Piece:  piece,
 	})
 }
 
@@ -227,26 +236,6 @@ func directionKey(from, to game.HexCoord) string {
 	}
 }
 
-// 计算指定 move 在当前 board 上会感染到的邻居格（不改 board）
-func computeInfections(b *game.Board, mv game.Move, player game.CellState) []game.HexCoord {
-	op := game.PlayerA
-	if player == game.PlayerA {
-		op = game.PlayerB
-	}
-	toIdx, ok := game.IndexOf[mv.To]
-	if !ok {
-		return nil
-	}
-
-	var out []game.HexCoord
-	for _, n := range game.NeighI[toIdx] {
-		if b.Cells[n] == op {
-			out = append(out, game.CoordOf[n])
-		}
-	}
-	return out
-}
-
 // 查询某个动画资源的播放时长（按 30fps 或帧率参数）
 func animDuration(base string, fps float64) time.Duration {
 	frames := assets.AnimFrames[base]
@@ -262,6 +251,7 @@ func (gs *GameScreen) addBecomeAnim(
 	to game.HexCoord,
 	player game.CellState,
 	delay time.Duration,
+	piece game.PieceID,
 ) {
 	base := "whiteBecomeRed" // 红方吃对白方，白子变红
 	if player == game.PlayerB {
@@ -275,10 +265,11 @@ func (gs *GameScreen) addBecomeAnim(
 	}
 	gs.anims = append(gs.anims, &FrameAnim{
 		Frames: frames,
-		FPS:    30,
-		Start:  time.Now().Add(delay),
+		FPS:    gs.animFPS(),
+		Start:  gs.now().Add(delay),
 		Coord:  to,   // 在被感染格居中播放
 		Angle:  0,    // 不需要旋转
 		Key:    base, // 用于 Draw 分支：中心贴合
+		Piece:  piece,
 	})
 }
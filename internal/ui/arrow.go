@@ -0,0 +1,78 @@
+// File /ui/arrow.go
+package ui
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 两格之间的箭头（落点提示、演示时的上一手、复盘的最佳着法）：
+// 箭杆加三角箭头拼成一个七边形，一次 DrawTriangles 画完并开抗锯齿，斜向箭头边缘也是平滑的。
+// 宽度由调用方按棋盘缩放换算好，所以窗口缩放时箭头与格子一起缩放。
+
+// 箭头各部分相对箭杆宽度的比例
+const (
+	arrowHeadLen   = 3.2 // 箭头长
+	arrowHeadHalf  = 1.6 // 箭头底边半宽
+	arrowStartGap  = 1.5 // 起点离格心的距离，别压住起点棋子正中
+	arrowTipGap    = 2.0 // 箭尖离终点格心的距离，留出格内文字
+	arrowMinLength = 4.0 // 扣掉两端空隙后短于 arrowMinLength 倍宽度就不画
+)
+
+var arrowPixel *ebiten.Image
+
+// arrowPolygon 箭头轮廓，从箭尖开始按顺序排列（以箭尖为中心可扇形三角化）；太短时返回 nil
+func arrowPolygon(x0, y0, x1, y1, width float64) [][2]float64 {
+	dx, dy := x1-x0, y1-y0
+	dist := math.Hypot(dx, dy)
+	length := dist - (arrowStartGap+arrowTipGap)*width
+	if width <= 0 || length < arrowMinLength*width {
+		return nil
+	}
+	ux, uy := dx/dist, dy/dist // 方向
+	nx, ny := -uy, ux          // 法向
+	sx, sy := x0+ux*arrowStartGap*width, y0+uy*arrowStartGap*width
+	tx, ty := sx+ux*length, sy+uy*length
+	bx, by := tx-ux*arrowHeadLen*width, ty-uy*arrowHeadLen*width // 箭头底边中点
+	at := func(x, y, off float64) [2]float64 { return [2]float64{x + nx*off, y + ny*off} }
+	half := width / 2
+	return [][2]float64{
+		{tx, ty},
+		at(bx, by, arrowHeadHalf*width),
+		at(bx, by, half),
+		at(sx, sy, half),
+		at(sx, sy, -half),
+		at(bx, by, -half),
+		at(bx, by, -arrowHeadHalf*width),
+	}
+}
+
+// drawArrow 在 dst 上从 (x0,y0) 向 (x1,y1) 画一支宽 width 像素的箭头
+func drawArrow(dst *ebiten.Image, x0, y0, x1, y1, width float64, clr color.Color) {
+	pts := arrowPolygon(x0, y0, x1, y1, width)
+	if pts == nil {
+		return
+	}
+	if arrowPixel == nil {
+		arrowPixel = ebiten.NewImage(1, 1)
+		arrowPixel.Fill(color.White)
+	}
+	c := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	vs := make([]ebiten.Vertex, len(pts))
+	for i, p := range pts {
+		vs[i] = ebiten.Vertex{
+			DstX: float32(p[0]), DstY: float32(p[1]),
+			SrcX: 0.5, SrcY: 0.5,
+			ColorR: float32(c.R) / 255, ColorG: float32(c.G) / 255,
+			ColorB: float32(c.B) / 255, ColorA: float32(c.A) / 255,
+		}
+	}
+	// 以箭尖为中心的扇形：轮廓对箭尖是星形的，各三角形不重叠
+	idx := make([]uint16, 0, 3*(len(pts)-2))
+	for i := 1; i+1 < len(pts); i++ {
+		idx = append(idx, 0, uint16(i), uint16(i+1))
+	}
+	dst.DrawTriangles(vs, idx, arrowPixel, &ebiten.DrawTrianglesOptions{AntiAlias: true})
+}
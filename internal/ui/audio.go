@@ -0,0 +1,17 @@
+// File /ui/audio.go
+package ui
+
+import "hexxagon_go/internal/game"
+
+// maxPan 最边上的格子的声像；不打满，免得戴耳机时一侧完全没声
+const maxPan = 0.7
+
+// cellPan 按格子在屏幕上的横坐标算声像：左边缘 -maxPan，右边缘 +maxPan
+func (gs *GameScreen) cellPan(c game.HexCoord) float64 {
+	if gs.tileImage == nil {
+		return 0
+	}
+	scale, originX, _, tileW, _, _ := getBoardTransform(gs.tileImage)
+	x := originX + ((float64(c.Q)+BoardRadius)*tileW*0.75+tileW/2)*scale
+	return (2*x/WindowWidth - 1) * maxPan
+}
@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"math"
+	"testing"
+
+	"hexxagon_go/internal/game"
+)
+
+func TestCellPanFollowsScreenX(t *testing.T) {
+	c, err := NewHeadless(pvpConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := c.Screen()
+	left := gs.cellPan(game.HexCoord{Q: -4, R: 4})
+	mid := gs.cellPan(game.HexCoord{Q: 0, R: 0})
+	right := gs.cellPan(game.HexCoord{Q: 4, R: 0})
+	if math.Abs(mid) > 1e-9 {
+		t.Fatalf("中心格声像 %v，应为 0", mid)
+	}
+	if left >= 0 || right <= 0 || math.Abs(left+right) > 1e-9 {
+		t.Fatalf("左右角声像 %v / %v，应左负右正且对称", left, right)
+	}
+	if right > maxPan+1e-9 {
+		t.Fatalf("声像 %v 超过上限 %v", right, maxPan)
+	}
+}
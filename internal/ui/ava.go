@@ -0,0 +1,256 @@
+// File /ui/ava.go
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"hexxagon_go/internal/game"
+)
+
+// AI 对 AI 观战（-mode ava）：双方各按自己的 AIPlayer 走——引擎、深度/模拟次数、NN 开关互不相干，带完整动画，
+// 用来演示，或者对着画面看两种评估到底哪里走得不一样。与演示模式不同，不轮换布局和设置；
+// 一局终了停在终局画面，按 N 用同样的设置再开一局（对局中按 N 也立即重开）。
+// 人机模式下给了 White 时，AI 一方同样按它走。
+//
+// 写法沿用 cmd/arena：引擎[:键=值,...]，例如
+//
+//	ab:depth=3,nn=false   进程内 α-β（迭代加深）3 层，静态评估
+//	mcts:sims=800         MCTS 800 次模拟，叶子 rollout；nn=true 时叶子用 NN 价值（多协程攒批）
+//	mcts:time=2s          MCTS 按时间停
+//	greedy                内置基线对手（random/greedy；perfect 只解不跳跃的对局，界面里不能选）
+
+// AI 引擎名，对应 AIPlayer.Engine；另可用 game.BaselineBots 里的基线对手
+const (
+	AIEngineAB   = "ab"
+	AIEngineMCTS = "mcts"
+)
+
+// DefaultAISims mcts 没给 sims/time 时的模拟次数
+const DefaultAISims = 800
+
+// AIPlayer 一方 AI 的设置
+type AIPlayer struct {
+	Engine string        // AIEngineAB、AIEngineMCTS 或基线对手名
+	Depth  int           // ab 的搜索深度，1..MaxAIDepth
+	Sims   int           // mcts 的模拟次数；0=只按 Time
+	Time   time.Duration // mcts 的时间上限；0=只按 Sims
+	NN     bool          // ab 叶子用 ONNX 评估；mcts 叶子用 NN 价值。基线对手忽略
+}
+
+// DefaultAIPlayer 不单独配置时 AI 一方的样子：按 Depth/Evaluator 的 α-β。ParseAIPlayer 以它为缺省值
+func (c GameConfig) DefaultAIPlayer() AIPlayer {
+	return AIPlayer{Engine: AIEngineAB, Depth: c.Depth, NN: c.Evaluator == EvalNN}
+}
+
+// ParseAIPlayer 解析 "engine[:k=v,...]"，没写的项取 def
+func ParseAIPlayer(s string, def AIPlayer) (*AIPlayer, error) {
+	engine, opts, _ := strings.Cut(s, ":")
+	p := def
+	p.Engine = engine
+	simsSet := false
+	if opts != "" {
+		for _, kv := range strings.Split(opts, ",") {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok {
+				return nil, fmt.Errorf("AI %q: 选项 %q 不是 键=值", s, kv)
+			}
+			var err error
+			switch k {
+			case "depth":
+				p.Depth, err = strconv.Atoi(v)
+			case "sims":
+				p.Sims, err = strconv.Atoi(v)
+				simsSet = true
+			case "time":
+				p.Time, err = time.ParseDuration(v)
+			case "nn":
+				p.NN, err = strconv.ParseBool(v)
+			default:
+				err = fmt.Errorf("未知选项（可选 depth/sims/time/nn）")
+			}
+			if err != nil {
+				return nil, fmt.Errorf("AI %q: %s: %w", s, k, err)
+			}
+		}
+	}
+	if p.Time > 0 && !simsSet {
+		p.Sims = 0 // 只给了 time：按时间停，不限次数
+	}
+	if p.Engine == AIEngineMCTS && !simsSet && p.Time == 0 && p.Sims == 0 {
+		p.Sims = DefaultAISims
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("AI %q: %w", s, err)
+	}
+	return &p, nil
+}
+
+func (p AIPlayer) Validate() error {
+	switch {
+	case p.Engine == AIEngineAB:
+		if p.Depth < 1 || p.Depth > MaxAIDepth {
+			return fmt.Errorf("搜索深度 %d 超出范围 1..%d", p.Depth, MaxAIDepth)
+		}
+		if p.Sims != 0 || p.Time != 0 {
+			// 给了也不会生效：ab 只按 depth 搜，按时间搜用 -time
+			return fmt.Errorf("ab 不接受 sims/time（按时间搜索用 -time）: sims=%d time=%v", p.Sims, p.Time)
+		}
+	case p.Engine == AIEngineMCTS:
+		if p.Sims < 0 || p.Time < 0 || (p.Sims == 0 && p.Time == 0) {
+			return fmt.Errorf("mcts 须给出正的 sims 或 time: sims=%d time=%v", p.Sims, p.Time)
+		}
+	case !game.IsBaselineBot(p.Engine):
+		return fmt.Errorf("未知引擎 %q（可选 %s/%s/%s）", p.Engine, AIEngineAB, AIEngineMCTS, strings.Join(game.BaselineBots, "/"))
+	default:
+		return checkUIBot(p.Engine)
+	}
+	return nil
+}
+
+// checkUIBot 界面对局里能不能用基线对手 bot：人随时可以跳，perfect 的不跳跃完全解在这里不是最优着法
+func checkUIBot(bot string) error {
+	if err := game.CheckBaselineBot(bot, true); err != nil {
+		if game.IsBaselineBot(bot) {
+			return fmt.Errorf("基线对手 %s 只解不跳跃的对局，界面对局允许跳跃，不能选: %w", bot, err)
+		}
+		return fmt.Errorf("未知的基线对手 %q（可选 %v）: %w", bot, game.BaselineBots, err)
+	}
+	return nil
+}
+
+// String 画面上显示的简写，如 "ab d3 nn"、"mcts 800 sims static"、"greedy"
+func (p AIPlayer) String() string {
+	eval := "static"
+	if p.NN {
+		eval = "nn"
+	}
+	switch p.Engine {
+	case AIEngineAB:
+		return fmt.Sprintf("ab d%d %s", p.Depth, eval)
+	case AIEngineMCTS:
+		var limit []string
+		if p.Sims > 0 {
+			limit = append(limit, fmt.Sprintf("%d sims", p.Sims))
+		}
+		if p.Time > 0 {
+			limit = append(limit, p.Time.String())
+		}
+		return fmt.Sprintf("mcts %s %s", strings.Join(limit, "/"), eval)
+	}
+	return p.Engine
+}
+
+// bot mcts 与基线对手包成 botFunc；ab 返回 nil，照常走 searchWith 的进程内搜索（能推送迭代进度）。
+// ab 的 NN 开关是按执子方的全局变量，由 applyEvaluator 设好
+func (p AIPlayer) bot() botFunc {
+	switch p.Engine {
+	case AIEngineAB:
+		return nil
+	case AIEngineMCTS:
+		sims, limit, nn := p.Sims, p.Time, p.NN
+		return func(b *game.Board, side game.CellState, allowJump bool) (game.Move, bool, error) {
+			if nn {
+				mv, ok := game.FindBestMoveMCTSParallel(b, side, sims, limit, allowJump, game.ParallelMCTSConfig{NNLeaves: true})
+				return mv, ok, nil
+			}
+			mv, ok := game.FindBestMoveMCTS(b, side, sims, limit, allowJump)
+			return mv, ok, nil
+		}
+	}
+	name := p.Engine
+	return func(b *game.Board, side game.CellState, allowJump bool) (game.Move, bool, error) {
+		return game.BaselineMove(name, b, side, allowJump, nil)
+	}
+}
+
+// sidePlayers 按方单独配置的 AI：ava 双方都有（没给的一方用 DefaultAIPlayer），pve 只有给了 White 时的白方
+func (c GameConfig) sidePlayers() map[game.CellState]*AIPlayer {
+	switch c.Mode {
+	case "ava":
+		red, white := c.Red, c.White
+		if red == nil {
+			def := c.DefaultAIPlayer()
+			red = &def
+		}
+		if white == nil {
+			def := c.DefaultAIPlayer()
+			white = &def
+		}
+		return map[game.CellState]*AIPlayer{game.PlayerA: red, game.PlayerB: white}
+	case "pve":
+		if c.White != nil {
+			return map[game.CellState]*AIPlayer{game.PlayerB: c.White}
+		}
+	}
+	return nil
+}
+
+type avaState struct {
+	n       int // 第几局
+	started time.Time
+	counted bool // 本局胜负已记
+	wins    map[game.CellState]int
+}
+
+// aiPlayer side 这一方单独配置的 AI；没有时 nil，沿用 aiDepth/bot/engine。
+// 按方配置的 AI 都在进程内搜，不走外部引擎（它的评估开关管不到）；演示模式轮换自己的深度，不看这里
+func (gs *GameScreen) aiPlayer(side game.CellState) *AIPlayer {
+	if gs.demo != nil || gs.remote != nil {
+		return nil
+	}
+	return gs.players[side]
+}
+
+// sidesShareTT 双方都是进程内 α-β 但设置不同（深度、评估器）：同一张置换表里存着另一方的分数，
+// 每手搜索前要清表，同 cmd/arena 的 shareTT
+func (gs *GameScreen) sidesShareTT() bool {
+	red, white := gs.aiPlayer(game.PlayerA), gs.aiPlayer(game.PlayerB)
+	return red != nil && white != nil && red.Engine == AIEngineAB && white.Engine == AIEngineAB && *red != *white
+}
+
+// spectating 双方都是 AI、没有人在下：演示或 AI 对 AI 观战
+func (gs *GameScreen) spectating() bool {
+	return gs.demo != nil || (gs.ava != nil && gs.mode == "ava")
+}
+
+func (gs *GameScreen) nextAvAGame() {
+	a := gs.ava
+	a.n++
+	a.started = gs.now()
+	a.counted = false
+	gs.resetGame(gs.newState())
+}
+
+// updateAvA 处理观战时的按键和终局记分；返回 true 表示本帧已处理完
+func (gs *GameScreen) updateAvA(now time.Time) bool {
+	if gs.ava == nil || gs.mode != "ava" || gs.demo != nil {
+		return false
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyN) {
+		gs.nextAvAGame()
+		return true
+	}
+	a := gs.ava
+	if gs.state.GameOver && !gs.isAnimating && !a.counted {
+		a.counted = true
+		a.wins[gs.state.Winner]++
+		r, w := gs.state.GetScores()
+		log.Printf("[ava] #%d red %s vs white %s: %d-%d, %d plies, %s (red %d / white %d / draw %d)",
+			a.n, gs.players[game.PlayerA], gs.players[game.PlayerB], r, w, len(gs.history.moves),
+			now.Sub(a.started).Round(time.Second), a.wins[game.PlayerA], a.wins[game.PlayerB], a.wins[game.Empty])
+	}
+	return false
+}
+
+func (gs *GameScreen) avaText() string {
+	a := gs.ava
+	return fmt.Sprintf("AI vs AI #%d  red %s vs white %s  (%d-%d-%d, N: new game)",
+		a.n, gs.players[game.PlayerA], gs.players[game.PlayerB], a.wins[game.PlayerA], a.wins[game.PlayerB], a.wins[game.Empty])
+}
@@ -0,0 +1,114 @@
+// File /ui/blitz.go
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"math/rand"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"hexxagon_go/internal/game"
+)
+
+// 快棋模式：动画按 blitzAnimSpeed 倍速播放，人类每步限时，超时替他随机走一步；
+// AI 的思考展示与搜索时间也压进同一限时，音效只留落子和翻子两声。
+// 计时从“轮到人且能点”开始：对手的落子动画、提交前的等待都不算在内。
+
+// DefaultBlitzMoveLimit 快棋每步默认限时
+const DefaultBlitzMoveLimit = 5 * time.Second
+
+// blitzAnimSpeed 快棋下动画的播放倍速
+const blitzAnimSpeed = 2.0
+
+// blitzWarn 剩余时间少于它时倒计时变红
+const blitzWarn = 2 * time.Second
+
+// blitzToast 超时代走提示的显示时长
+const blitzToast = 2 * time.Second
+
+type blitzConfig struct {
+	enabled bool
+	limit   time.Duration
+}
+
+// animFPS 落子/感染/变色动画的帧率；所有动画时长都由它折算
+func (gs *GameScreen) animFPS() float64 {
+	if gs.blitz.enabled {
+		return 30 * blitzAnimSpeed
+	}
+	return 30
+}
+
+// blitzPacing 把 AI 的思考节奏压缩到快棋的节奏里
+func blitzPacing(p Pacing) Pacing {
+	p.MinThink = time.Duration(float64(p.MinThink) / blitzAnimSpeed)
+	p.MaxThink = time.Duration(float64(p.MaxThink) / blitzAnimSpeed)
+	return p
+}
+
+// blitzTimeBudget AI 单步搜索的时间上限：不超过每步限时
+func blitzTimeBudget(budget, limit time.Duration) time.Duration {
+	if budget <= 0 || budget > limit {
+		return limit
+	}
+	return budget
+}
+
+// blitzClocked 当前是否在给人类计时
+func (gs *GameScreen) blitzClocked() bool {
+	return gs.blitz.enabled && gs.demo == nil && !gs.state.GameOver && !gs.isAITurn() &&
+		gs.pendingCommit == nil && !gs.isAnimating
+}
+
+// updateBlitz 推进人类的步时；超时随机走一步。返回 true 表示本帧已替人落子
+func (gs *GameScreen) updateBlitz(now time.Time) bool {
+	if !gs.blitzClocked() {
+		gs.turnStart = time.Time{}
+		return false
+	}
+	if gs.turnStart.IsZero() {
+		gs.turnStart = now
+		return false
+	}
+	if now.Sub(gs.turnStart) < gs.blitz.limit {
+		return false
+	}
+	side := gs.state.CurrentPlayer
+	mvs := game.GenerateMoves(gs.state.Board, side)
+	if len(mvs) == 0 {
+		return false // 无路可走交给 6.5 的裁定
+	}
+	mv := mvs[rand.Intn(len(mvs))]
+	gs.selected = nil
+	gs.premove = nil
+	gs.turnStart = time.Time{}
+	total, err := gs.performMove(mv, side)
+	if err != nil {
+		return false
+	}
+	gs.aiDelayUntil = now.Add(total)
+	gs.showToast(fmt.Sprintf("Time out (%s): random move played", sideName(side)), blitzToast)
+	return true
+}
+
+// drawBlitzClock 右上角画当前步的剩余时间
+func (gs *GameScreen) drawBlitzClock(screen *ebiten.Image) {
+	if !gs.blitzClocked() {
+		return
+	}
+	left := gs.blitz.limit
+	if !gs.turnStart.IsZero() {
+		left -= gs.now().Sub(gs.turnStart)
+	}
+	if left < 0 {
+		left = 0
+	}
+	clr := color.Color(color.White)
+	if left < blitzWarn {
+		clr = color.RGBA{255, 90, 90, 255}
+	}
+	msg := fmt.Sprintf("%s %.1fs", sideName(gs.state.CurrentPlayer), left.Seconds())
+	text.Draw(screen, msg, gs.fontFace, WindowWidth-20-len(msg)*7, 24, clr)
+}
@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"hexxagon_go/internal/game"
+)
+
+func blitzTestConfig(limit time.Duration) GameConfig {
+	cfg := pvpConfig()
+	cfg.Blitz = true
+	cfg.BlitzMoveLimit = limit
+	return cfg
+}
+
+func TestBlitzTimeoutPlaysRandomMove(t *testing.T) {
+	c := newTestController(t, blitzTestConfig(time.Second))
+	gs := c.Screen()
+
+	// 限时内不代走
+	for i := 0; i < 20; i++ {
+		if err := c.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if gs.pendingCommit != nil {
+		t.Fatal("未超时不应代走")
+	}
+	stepUntil(t, c, func() bool { return gs.pendingCommit != nil })
+	if gs.pendingCommit.player != game.PlayerA || !legalMove(gs.state.Board, game.PlayerA, gs.pendingCommit.move) {
+		t.Fatalf("超时应替红方走一步合法着: %+v", gs.pendingCommit)
+	}
+	if gs.toast == nil {
+		t.Fatal("超时代走应有提示")
+	}
+	if err := c.Settle(10 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if gs.state.CurrentPlayer != game.PlayerB || !gs.blitzClocked() {
+		t.Fatal("代走落盘后应轮到白方并开始计时")
+	}
+}
+
+func TestBlitzClockPausedDuringAnimation(t *testing.T) {
+	c := newTestController(t, blitzTestConfig(time.Second))
+	gs := c.Screen()
+	if err := c.Move(firstMove(gs.state)); err != nil {
+		t.Fatal(err)
+	}
+	if gs.blitzClocked() {
+		t.Fatal("落子动画期间不应计时")
+	}
+	c.Advance(2 * time.Second) // 只推时间不跑 Update：下一帧才开始给白方计时
+	if err := c.Step(); err != nil {
+		t.Fatal(err)
+	}
+	if gs.pendingCommit != nil && gs.pendingCommit.player == game.PlayerB {
+		t.Fatal("对手动画时间不应算进白方步时")
+	}
+}
+
+func TestBlitzSpeedsUpAnimations(t *testing.T) {
+	normal := newTestController(t, pvpConfig()).Screen()
+	fast := newTestController(t, blitzTestConfig(time.Second)).Screen()
+	if fast.animFPS() != normal.animFPS()*blitzAnimSpeed {
+		t.Fatalf("快棋动画帧率 %v，应为常速 %v 的 %v 倍", fast.animFPS(), normal.animFPS(), blitzAnimSpeed)
+	}
+	if fast.pacing.MaxThink >= normal.pacing.MaxThink || fast.timeBudget != time.Second {
+		t.Fatalf("快棋应压缩 AI 节奏并以限时为搜索上限: pacing=%+v budget=%v", fast.pacing, fast.timeBudget)
+	}
+}
@@ -0,0 +1,144 @@
+// File /ui/commentary.go
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+
+	"hexxagon_go/internal/game"
+)
+
+// 回放和演示时右侧的解说栏：每提交一手追加一行 game.CommentMove，终局再补一句结果。
+// 鼠标滚轮翻看前面的；翻在旧记录上时新的一手不会把视野顶走，滚回底部才重新跟着最新。
+// 人机、人人对局也照样记（录像要导出），只是不画。
+
+const (
+	commentRows  = 14 // 可见行数
+	commentCols  = 34 // 每行字符数；basicfont 每字 7 像素
+	commentLineH = 16
+)
+
+var (
+	commentBgColor   = color.RGBA{0x10, 0x14, 0x20, 0xFF}
+	commentTextColor = color.RGBA{0xD8, 0xD8, 0xD8, 0xFF}
+	commentEndColor  = color.RGBA{0xFF, 0xD0, 0x80, 0xFF}
+)
+
+type commentRow struct {
+	text string
+	end  bool // 终局那句，换个颜色
+}
+
+// commentLog 按显示行存（长句已折行），滚动以行计
+type commentLog struct {
+	rows   []commentRow
+	plies  int
+	scroll int // 从最底下往上翻了几行；0 = 跟着最新
+}
+
+// addMove 记一手；comment 为空（走不了的着法）时什么都不记
+func (l *commentLog) addMove(comment string) {
+	if comment == "" {
+		return
+	}
+	l.plies++
+	l.add(fmt.Sprintf("%d. %s", l.plies, comment), false)
+}
+
+func (l *commentLog) addEnd(msg string) { l.add(msg, true) }
+
+func (l *commentLog) add(s string, end bool) {
+	rows := wrapComment(s, commentCols)
+	for _, r := range rows {
+		l.rows = append(l.rows, commentRow{text: r, end: end})
+	}
+	if l.scroll > 0 {
+		l.scroll += len(rows)
+	}
+}
+
+// scrollBy 正数往上翻（看更早的）
+func (l *commentLog) scrollBy(n int) {
+	l.scroll = max(0, min(l.scroll+n, len(l.rows)-commentRows))
+}
+
+func (l *commentLog) visible() []commentRow {
+	end := len(l.rows) - l.scroll
+	return l.rows[max(0, end-commentRows):end]
+}
+
+// wrapComment 按词折行，续行缩进三格
+func wrapComment(s string, cols int) []string {
+	const indent = "   "
+	var rows []string
+	for len(s) > cols {
+		cut := strings.LastIndexByte(s[:cols+1], ' ')
+		if cut <= len(indent) {
+			cut = cols
+		}
+		rows = append(rows, s[:cut])
+		s = indent + strings.TrimLeft(s[cut:], " ")
+	}
+	return append(rows, s)
+}
+
+// commentsShown 只有回放、演示和观战画解说栏
+func (gs *GameScreen) commentsShown() bool {
+	return gs.spectating() || gs.mode == "replay"
+}
+
+// pollCommentWheel 每帧读滚轮
+func (gs *GameScreen) pollCommentWheel() {
+	if !gs.commentsShown() {
+		return
+	}
+	if _, dy := ebiten.Wheel(); dy != 0 {
+		gs.comments.scrollBy(int(dy * 3))
+	}
+}
+
+// commitComment 一手提交成功后记解说；before 是走子前的局面
+func (gs *GameScreen) commitComment(before *game.Board, player game.CellState, mv game.Move) string {
+	c := game.CommentMove(before, player, mv)
+	gs.comments.addMove(c)
+	if gs.state.GameOver {
+		gs.comments.addEnd(gameOverText(gs.state))
+	}
+	return c
+}
+
+func (gs *GameScreen) drawComments(screen *ebiten.Image) {
+	rows := gs.comments.visible()
+	if !gs.commentsShown() || len(rows) == 0 {
+		return
+	}
+	const pad = 8
+	w := commentCols*7 + 2*pad
+	h := (len(rows)+1)*commentLineH + 2*pad
+	x := screen.Bounds().Dx() - w - 12
+	y := 140
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(float64(w), float64(h))
+	op.GeoM.Translate(float64(x), float64(y))
+	op.ColorScale.ScaleWithColor(commentBgColor)
+	op.ColorScale.ScaleAlpha(0.75)
+	screen.DrawImage(whitePixel(), op)
+
+	title := "Commentary"
+	if n := gs.comments.scroll; n > 0 {
+		title = fmt.Sprintf("Commentary (%d newer below)", n)
+	}
+	text.Draw(screen, title, gs.fontFace, x+pad, y+pad+commentLineH-4, color.RGBA{0x90, 0xC0, 0xFF, 0xFF})
+	for i, r := range rows {
+		clr := commentTextColor
+		if r.end {
+			clr = commentEndColor
+		}
+		text.Draw(screen, r.text, gs.fontFace, x+pad, y+pad+(i+2)*commentLineH-4, clr)
+	}
+}
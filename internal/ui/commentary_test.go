@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+// 长句按词折行；翻到旧记录时新行不把视野顶走，滚轮不越界
+func TestCommentLogScroll(t *testing.T) {
+	rows := wrapComment("White jumps (2,0) -> (0,1), converting 3 red pieces -- evaluation swings +34", commentCols)
+	if len(rows) < 2 || !strings.HasPrefix(rows[1], "   ") {
+		t.Fatalf("折行: %q", rows)
+	}
+	for _, r := range rows {
+		if len(r) > commentCols {
+			t.Fatalf("行超宽: %q", r)
+		}
+	}
+	if got := wrapComment(strings.Repeat("x", 80), 34); len(got) != 3 || got[0] != strings.Repeat("x", 34) {
+		t.Fatalf("没有空格的长串应硬折: %q", got)
+	}
+
+	var l commentLog
+	l.addMove("")
+	if l.plies != 0 || len(l.rows) != 0 {
+		t.Fatal("空解说不应记")
+	}
+	for i := 0; i < 20; i++ {
+		l.addMove("Red clones (4,0) -> (3,0)")
+	}
+	if got := l.visible(); len(got) != commentRows || got[len(got)-1].text != "20. Red clones (4,0) -> (3,0)" {
+		t.Fatalf("应显示最新 %d 行: %+v", commentRows, got)
+	}
+	l.scrollBy(100)
+	if l.scroll != 20-commentRows || l.visible()[0].text != "1. Red clones (4,0) -> (3,0)" {
+		t.Fatalf("翻到顶: scroll=%d", l.scroll)
+	}
+	top := l.visible()[0]
+	l.addEnd("Game over: draw")
+	if l.visible()[0] != top || !l.rows[len(l.rows)-1].end {
+		t.Fatal("翻看旧记录时新行不应移动视野")
+	}
+	l.scrollBy(-100)
+	if l.scroll != 0 || l.visible()[commentRows-1].text != "Game over: draw" {
+		t.Fatal("滚回底部应跟着最新")
+	}
+}
@@ -0,0 +1,268 @@
+// File /ui/config.go
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"hexxagon_go/internal/botscript"
+	"hexxagon_go/internal/game"
+)
+
+// 评估函数名，对应 GameConfig.Evaluator
+const (
+	EvalNN     = "nn"     // 神经网络（ONNX），失败时 game 包自行回退
+	EvalStatic = "static" // 手写静态评估
+)
+
+// 迷雾模式下 AI 的搜索方式，对应 GameConfig.FogAI
+const (
+	FogAIFull   = "full"   // 直接搜完整局面（AI 看得见迷雾里的子）
+	FogAISample = "sample" // 按 AI 自己的视野抽确定化局面分别搜索后投票，见 game.FogSearch
+)
+
+// 开局地图，对应 GameConfig.Map
+const (
+	MapClassic = "classic" // 原版中心三障碍
+	MapRandom  = "random"  // 按种子随机放障碍，保证双方公平，见 game.RandomBlocks
+	MapSmall   = "small"   // 半径 2 小棋盘（外圈堵死），见 game.SmallBoardState
+)
+
+// MaxAIDepth -depth 的上限；再深在 NN 评估下一步要算几十秒，界面基本不可用
+const MaxAIDepth = 8
+
+// GameConfig 界面的全部启动配置，一次性传给 NewGameScreen。
+// 命令行参数只负责填这个结构，校验统一走 Validate。
+type GameConfig struct {
+	Mode       string        // "pve"（人机）、"pvp"（人人）、"net"（联机，对方由 SetRemote 接入）、"demo"（AI 对 AI 演示）、"ava"（AI 对 AI 观战，双方按 Red/White）、"replay"（播放 ReplayFile）
+	Depth      int           // AI 搜索深度，1..MaxAIDepth
+	Evaluator  string        // EvalNN 或 EvalStatic；作用于 AI 一方，演示模式下双方都用
+	TimeBudget time.Duration // 单步搜索时间上限：迭代加深到 Depth 层或到点为止，到点用已完成的最深一层（game.FindBestMoveWithBudgetDepth）；0=只按深度
+	SearchTime time.Duration // 非 0 时 AI 不看 Depth（演示轮换的深度也不看），按这个时间一直迭代加深到 game.MaxBudgetDepth，优先于 TimeBudget
+	ShowTips   bool          // 显示玩家棋子评分提示
+	Pacing     Pacing        // 思考节奏，见 pacing.go
+	Bot        string        // 非空时 AI 用内置基线对手（最低难度），忽略 Depth
+	BotScript  string        // 非空时 AI 按该脚本挑着法（见 internal/botscript），忽略 Depth；与 Bot 二选一
+	Red        *AIPlayer     // "ava" 模式红方的 AI；nil=DefaultAIPlayer，见 ava.go
+	White      *AIPlayer     // "ava" 模式白方的 AI；"pve" 下非 nil 时代替 Depth/Evaluator/Bot 作为 AI 一方
+	Rules      game.Rules    // 规则变体（如连锁感染）；走子、AI 与动画都按它来
+	Fog        bool          // 迷雾：只画出观看方棋子距离 2 以内的格子，见 fog.go
+	FogAI      string        // 迷雾下 AI 的搜索方式：FogAIFull 或 FogAISample
+	FogSamples int           // FogAISample 的确定化样本数
+	Map        string        // MapClassic 或 MapRandom
+	MapBlocks  int           // 随机地图的障碍数，0..game.MaxRandomBlocks
+	MapSeed    int64         // 随机地图种子；0=按时间取一个，画面上会显示以便分享
+
+	BoardRadius int // 棋盘半径 1..game.MaxBoardRadius；小于最大值时盘外的格子是障碍。MapSmall 固定用 SmallBoardRadius
+
+	Heatmap bool // 终局默认显示争夺热力图（每格易手次数），对局中按 H 切换，见 heatmap.go
+
+	RecordFile  string        // 非空时每盘终局把着法追加到该 JSON 文件（演示与回放不录），见 replay.go
+	ReplayFile  string        // "replay" 模式播放的录像文件
+	ReplayDelay time.Duration // 回放每手之间的间隔，播放中按 Up/Down 调整
+
+	Blitz          bool          // 快棋：动画加速、音效精简，人类每步限时，超时随机代走，见 blitz.go
+	BlitzMoveLimit time.Duration // 快棋每步限时；AI 的搜索时间也不超过它
+
+	OptionsFile string // 搜索/评估参数文件（JSON，见 game.Options）；启动时加载，改动后热加载
+
+	SaveFile string          // 对局中按 S 存档、按 L 读档的文件；空=不支持，见 savegame.go
+	Resume   *game.SavedGame // 非 nil 时从这份存档接着下（pve/pvp），Rules 须与存档一致
+}
+
+// DefaultGameConfig 与命令行默认值一致
+func DefaultGameConfig() GameConfig {
+	return GameConfig{
+		Mode:       "pve",
+		Depth:      1,
+		Evaluator:  EvalNN,
+		Pacing:     DefaultPacing,
+		FogAI:      FogAISample,
+		FogSamples: 8,
+		Map:        MapClassic,
+		MapBlocks:  6,
+
+		BoardRadius: game.DefaultBoardRadius,
+
+		ReplayDelay: DefaultReplayDelay,
+
+		BlitzMoveLimit: DefaultBlitzMoveLimit,
+	}
+}
+
+// Validate 检查取值范围；NewGameScreen 会先调用它
+func (c GameConfig) Validate() error {
+	switch c.Mode {
+	case "pve", "pvp", "net", "demo", "ava":
+	case "replay":
+		if c.ReplayFile == "" {
+			return fmt.Errorf("回放模式须指定录像文件")
+		}
+		if c.ReplayDelay <= 0 {
+			return fmt.Errorf("回放间隔须为正: %v", c.ReplayDelay)
+		}
+	default:
+		return fmt.Errorf("未知模式 %q（可选 pve/pvp/net/demo/ava/replay）", c.Mode)
+	}
+	if c.Depth < 1 || c.Depth > MaxAIDepth {
+		return fmt.Errorf("搜索深度 %d 超出范围 1..%d", c.Depth, MaxAIDepth)
+	}
+	switch c.Evaluator {
+	case EvalNN, EvalStatic:
+	default:
+		return fmt.Errorf("未知评估函数 %q（可选 %s/%s）", c.Evaluator, EvalNN, EvalStatic)
+	}
+	if c.TimeBudget < 0 {
+		return fmt.Errorf("时间预算不能为负: %v", c.TimeBudget)
+	}
+	if c.SearchTime < 0 {
+		return fmt.Errorf("搜索时间不能为负: %v", c.SearchTime)
+	}
+	if c.Pacing.MinThink < 0 || c.Pacing.MaxThink < 0 {
+		return fmt.Errorf("思考展示时间不能为负: min=%v max=%v", c.Pacing.MinThink, c.Pacing.MaxThink)
+	}
+	if c.Fog {
+		if c.ShowTips {
+			return fmt.Errorf("迷雾模式不支持评分提示（会泄露看不见的子）")
+		}
+		switch c.FogAI {
+		case FogAIFull, FogAISample:
+		default:
+			return fmt.Errorf("未知的迷雾搜索方式 %q（可选 %s/%s）", c.FogAI, FogAIFull, FogAISample)
+		}
+		if c.FogAI == FogAISample && c.FogSamples < 1 {
+			return fmt.Errorf("确定化样本数须 >= 1: %d", c.FogSamples)
+		}
+	}
+	switch c.Map {
+	case MapClassic:
+	case MapRandom:
+		if c.MapBlocks < 0 || c.MapBlocks > game.MaxRandomBlocks {
+			return fmt.Errorf("随机地图障碍数 %d 超出范围 0..%d", c.MapBlocks, game.MaxRandomBlocks)
+		}
+	case MapSmall:
+	default:
+		return fmt.Errorf("未知地图 %q（可选 %s/%s/%s）", c.Map, MapClassic, MapRandom, MapSmall)
+	}
+	if err := game.CheckBoardRadius(c.BoardRadius); err != nil {
+		return err
+	}
+	if c.Blitz && c.BlitzMoveLimit <= 0 {
+		return fmt.Errorf("快棋每步限时须为正: %v", c.BlitzMoveLimit)
+	}
+	if c.Bot != "" {
+		if err := checkUIBot(c.Bot); err != nil {
+			return err
+		}
+	}
+	if c.Bot != "" && c.BotScript != "" {
+		return fmt.Errorf("基线对手 %q 与脚本对手 %q 只能选一个", c.Bot, c.BotScript)
+	}
+	for i, p := range []*AIPlayer{c.Red, c.White} {
+		if p == nil {
+			continue
+		}
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("%s AI: %w", [...]string{"红方", "白方"}[i], err)
+		}
+	}
+	if c.Red != nil && c.Mode != "ava" {
+		return fmt.Errorf("红方 AI 只在 ava 模式下有效，当前 %q", c.Mode)
+	}
+	if c.White != nil && c.Mode != "ava" && c.Mode != "pve" {
+		return fmt.Errorf("白方 AI 只在 pve/ava 模式下有效，当前 %q", c.Mode)
+	}
+	if c.Resume != nil && c.Mode != "pve" && c.Mode != "pvp" {
+		return fmt.Errorf("读档只支持 pve/pvp 模式，当前 %q", c.Mode)
+	}
+	return nil
+}
+
+// ApplyPreset 按难度阶梯的一档设置 AI（见 game.Ladder）；基线对手档不改 Depth
+func (c *GameConfig) ApplyPreset(p game.Preset) {
+	c.Bot = p.Bot
+	c.BotScript = ""
+	if p.Bot != "" {
+		return
+	}
+	c.Depth = p.Depth
+	c.Evaluator = EvalStatic
+	if p.Evaluator == game.PresetEvalNN {
+		c.Evaluator = EvalNN
+	}
+}
+
+// botFunc 不搜索的对手：基线对手或脚本对手，给 searchWith 用
+type botFunc func(b *game.Board, side game.CellState, allowJump bool) (game.Move, bool, error)
+
+// newBot 按 Bot / BotScript 生成对手；都没设时为 nil（正常搜索）。脚本在这里加载，语法错误启动时就报
+func (c GameConfig) newBot() (botFunc, error) {
+	if c.BotScript != "" {
+		s, err := botscript.Load(c.BotScript)
+		if err != nil {
+			return nil, fmt.Errorf("加载脚本对手失败: %w", err)
+		}
+		return func(b *game.Board, side game.CellState, allowJump bool) (game.Move, bool, error) {
+			return s.Move(b, side, allowJump, nil)
+		}, nil
+	}
+	if c.Bot != "" {
+		bot := c.Bot
+		return func(b *game.Board, side game.CellState, allowJump bool) (game.Move, bool, error) {
+			return game.BaselineMove(bot, b, side, allowJump, nil)
+		}, nil
+	}
+	return nil, nil
+}
+
+// blocks 开局障碍；随机地图种子为 0 时就地补上实际用的种子
+func (c *GameConfig) blocks() ([]game.HexCoord, error) {
+	if c.Map != MapRandom {
+		return game.DefaultBlocks, nil
+	}
+	if c.MapSeed == 0 {
+		c.MapSeed = time.Now().UnixNano() % 1000000
+		if c.MapSeed == 0 {
+			c.MapSeed = 1
+		}
+	}
+	return game.RandomBlocks(c.MapBlocks, c.MapSeed)
+}
+
+// SmallBoardRadius MapSmall 的棋盘半径
+const SmallBoardRadius = 2
+
+// newState 本局地图的开局生成函数，退出演示时按它重开
+func (c *GameConfig) newState() (func() *game.GameState, error) {
+	if c.Map == MapSmall {
+		if _, err := game.SmallBoardState(SmallBoardRadius); err != nil {
+			return nil, err
+		}
+		return func() *game.GameState {
+			st, _ := game.SmallBoardState(SmallBoardRadius)
+			return st
+		}, nil
+	}
+	blocks, err := c.blocks()
+	if err != nil {
+		return nil, err
+	}
+	radius := c.BoardRadius
+	return func() *game.GameState { return game.NewGameStateWithBlocks(radius, blocks) }, nil
+}
+
+// applyEvaluator 按配置切换 game 包的评估开关与规则变体（全局变量，整个进程只有一个界面）。
+// 两个开关每次都显式设：上一局（比如演示模式）留下的值不能带进人机、双人对局
+func (c GameConfig) applyEvaluator() {
+	game.SetRules(c.Rules)
+	nn := c.Evaluator == EvalNN
+	// 人机、双人时红方是人，不用 NN；演示模式双方都按 Evaluator
+	game.UseONNXForPlayerA, game.UseONNXForPlayerB = c.Mode == "demo" && nn, nn
+	for side, p := range c.sidePlayers() {
+		if side == game.PlayerA {
+			game.UseONNXForPlayerA = p.NN
+		} else {
+			game.UseONNXForPlayerB = p.NN
+		}
+	}
+}
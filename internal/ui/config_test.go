@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"hexxagon_go/internal/game"
+)
+
+func TestGameConfigValidate(t *testing.T) {
+	if err := DefaultGameConfig().Validate(); err != nil {
+		t.Fatalf("默认配置应合法: %v", err)
+	}
+	bad := map[string]func(*GameConfig){
+		"mode":    func(c *GameConfig) { c.Mode = "online" },
+		"depth0":  func(c *GameConfig) { c.Depth = 0 },
+		"depthHi": func(c *GameConfig) { c.Depth = MaxAIDepth + 1 },
+		"eval":    func(c *GameConfig) { c.Evaluator = "mcts" },
+		"budget":  func(c *GameConfig) { c.TimeBudget = -time.Second },
+		"time":    func(c *GameConfig) { c.SearchTime = -time.Second },
+		"pacing":  func(c *GameConfig) { c.Pacing.MinThink = -1 },
+		"bot":     func(c *GameConfig) { c.Bot = "minimax" },
+		"fogTips": func(c *GameConfig) { c.Fog, c.ShowTips = true, true },
+		"fogAI":   func(c *GameConfig) { c.Fog, c.FogAI = true, "peek" },
+		"fogN":    func(c *GameConfig) { c.Fog, c.FogSamples = true, 0 },
+		"map":     func(c *GameConfig) { c.Map = "maze" },
+		"mapN":    func(c *GameConfig) { c.Map, c.MapBlocks = MapRandom, -1 },
+		"blitz":   func(c *GameConfig) { c.Blitz, c.BlitzMoveLimit = true, 0 },
+		"radius":  func(c *GameConfig) { c.BoardRadius = 5 },
+		"redPvE":  func(c *GameConfig) { c.Red = &AIPlayer{Engine: AIEngineAB, Depth: 2} },
+		"whitePvP": func(c *GameConfig) {
+			c.Mode, c.White = "pvp", &AIPlayer{Engine: AIEngineAB, Depth: 2}
+		},
+		"avaDepth": func(c *GameConfig) { c.Mode, c.Red = "ava", &AIPlayer{Engine: AIEngineAB, Depth: 0} },
+	}
+	for name, mutate := range bad {
+		cfg := DefaultGameConfig()
+		mutate(&cfg)
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: 应校验失败", name)
+		}
+	}
+}
+
+func TestGameConfigRandomMapSeed(t *testing.T) {
+	cfg := DefaultGameConfig()
+	cfg.Map = MapRandom
+	blocks, err := cfg.blocks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MapSeed == 0 || len(blocks) != cfg.MapBlocks {
+		t.Fatalf("应补上实际种子并按障碍数生成: seed=%d blocks=%d", cfg.MapSeed, len(blocks))
+	}
+	again := cfg
+	if b2, _ := again.blocks(); len(b2) != len(blocks) || b2[0] != blocks[0] {
+		t.Fatal("同一种子应生成同一张图")
+	}
+}
+
+func TestGameConfigApplyPreset(t *testing.T) {
+	cfg := DefaultGameConfig()
+	cfg.ApplyPreset(game.Preset{Name: "hard", Depth: 3, Evaluator: game.PresetEvalStatic})
+	if cfg.Bot != "" || cfg.Depth != 3 || cfg.Evaluator != EvalStatic {
+		t.Fatalf("搜索档应设置深度与评估: %+v", cfg)
+	}
+	cfg.ApplyPreset(game.Preset{Name: "easy", Bot: game.BotGreedy})
+	if cfg.Bot != game.BotGreedy || cfg.Validate() != nil {
+		t.Fatalf("基线档应改用基线对手且仍合法: %+v", cfg)
+	}
+}
+
+func TestParseAIPlayer(t *testing.T) {
+	def := DefaultGameConfig().DefaultAIPlayer()
+	cases := map[string]AIPlayer{
+		"ab":                  {Engine: AIEngineAB, Depth: 1, NN: true},
+		"ab:depth=3,nn=false": {Engine: AIEngineAB, Depth: 3},
+		"mcts":                {Engine: AIEngineMCTS, Depth: 1, Sims: DefaultAISims, NN: true},
+		"mcts:sims=200":       {Engine: AIEngineMCTS, Depth: 1, Sims: 200, NN: true},
+		"mcts:time=2s":        {Engine: AIEngineMCTS, Depth: 1, Time: 2 * time.Second, NN: true},
+		"greedy":              {Engine: game.BotGreedy, Depth: 1, NN: true},
+	}
+	for spec, want := range cases {
+		p, err := ParseAIPlayer(spec, def)
+		if err != nil {
+			t.Errorf("%s: %v", spec, err)
+			continue
+		}
+		if *p != want {
+			t.Errorf("%s: got %+v, want %+v", spec, *p, want)
+		}
+	}
+	for _, spec := range []string{"minimax", "ab:depth=0", "ab:depth", "mcts:sims=0", "ab:width=3", "ab:time=2s", "ab:sims=100"} {
+		if _, err := ParseAIPlayer(spec, def); err == nil {
+			t.Errorf("%s: 应解析失败", spec)
+		}
+	}
+}
+
+// 演示模式之后切回人机：红方的 NN 开关要复位，不能沿用上一局的
+func TestGameConfigApplyEvaluator(t *testing.T) {
+	prevA, prevB := game.UseONNXForPlayerA, game.UseONNXForPlayerB
+	defer func() { game.UseONNXForPlayerA, game.UseONNXForPlayerB = prevA, prevB }()
+
+	cfg := DefaultGameConfig()
+	cfg.Evaluator = EvalNN
+	cfg.Mode = "demo"
+	cfg.applyEvaluator()
+	if !game.UseONNXForPlayerA || !game.UseONNXForPlayerB {
+		t.Fatal("演示模式双方都应用 NN")
+	}
+	for _, mode := range []string{"pve", "pvp"} {
+		cfg.Mode = mode
+		cfg.applyEvaluator()
+		if game.UseONNXForPlayerA || !game.UseONNXForPlayerB {
+			t.Fatalf("%s: A=%v B=%v，期望 A 关、B 开", mode, game.UseONNXForPlayerA, game.UseONNXForPlayerB)
+		}
+	}
+}
+
+func TestGameConfigSidePlayers(t *testing.T) {
+	cfg := DefaultGameConfig()
+	if cfg.sidePlayers() != nil {
+		t.Fatal("默认人机不应有单独配置的 AI")
+	}
+	cfg.Mode = "ava"
+	cfg.White = &AIPlayer{Engine: AIEngineMCTS, Sims: 100}
+	ps := cfg.sidePlayers()
+	if *ps[game.PlayerA] != cfg.DefaultAIPlayer() || ps[game.PlayerB] != cfg.White {
+		t.Fatalf("ava 没给的一方应按默认补上: %+v", ps)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,240 @@
+// File /ui/controller.go
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"hexxagon_go/internal/game"
+)
+
+// Controller 无头驱动 GameScreen：注入着法、推进虚拟时间、读取画面上实际“看得到”的状态。
+// 用于集成测试整盘走完 UI 层（落子 → 动画 → pendingCommit → AI 应着），
+// 专门抓 pendingCommit 卡住、提交与动画先后错位、幽灵棋子/隐藏格没清干净这类只在界面层出现的问题。
+// 只调用 Update，不调用 Draw；不需要窗口和音频设备。
+type Controller struct {
+	gs    *GameScreen
+	now   time.Time
+	Frame time.Duration // 每次 Step 推进的虚拟时长
+}
+
+// NewController 接管 gs 的时钟；之后 gs 只应通过 Controller 驱动
+func NewController(gs *GameScreen) *Controller {
+	c := &Controller{gs: gs, now: time.Now(), Frame: time.Second / 60}
+	gs.clock = c.Now
+	gs.didShrink = true // 缩帧要 ReadPixels，游戏循环外不能调用
+	return c
+}
+
+// NewHeadless 按 cfg 创建不带音频的 GameScreen 并接管
+func NewHeadless(cfg GameConfig) (*Controller, error) {
+	gs, err := NewGameScreen(nil, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewController(gs), nil
+}
+
+func (c *Controller) Screen() *GameScreen { return c.gs }
+
+func (c *Controller) Now() time.Time { return c.now }
+
+// Advance 推进虚拟时间（不跑 Update）
+func (c *Controller) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// Step 推进一帧并跑一次 Update
+func (c *Controller) Step() error {
+	c.Advance(c.Frame)
+	return c.gs.Update()
+}
+
+// Busy 还有动画、待提交的落子、未到期的隐藏窗口/幽灵棋子或 AI 搜索没结束。
+// 隐藏窗口和幽灵棋子按到期时间算：终局后 Update 提前返回不再清理列表，但过期的已经不会画出来
+func (c *Controller) Busy() bool {
+	gs := c.gs
+	if gs.pendingCommit != nil || len(gs.anims) > 0 || gs.aiBusy() || gs.aiQueuedMove != nil {
+		return true
+	}
+	for _, w := range gs.hideWindows {
+		if c.now.Before(w.end) {
+			return true
+		}
+	}
+	for _, g := range gs.tempGhosts {
+		if !c.now.After(g.hideAt) {
+			return true
+		}
+	}
+	return false
+}
+
+// Move 以当前行棋方走 mv，等同于玩家点击起点和终点
+func (c *Controller) Move(mv game.Move) error {
+	gs := c.gs
+	switch {
+	case gs.state.GameOver:
+		return fmt.Errorf("ui: game over, cannot play %v", mv)
+	case gs.mode == "replay":
+		return fmt.Errorf("ui: replaying, cannot play %v", mv)
+	case gs.isAITurn():
+		return fmt.Errorf("ui: %s is AI-controlled", sideName(gs.state.CurrentPlayer))
+	case c.Busy():
+		return fmt.Errorf("ui: screen busy, settle before moving (%s)", c.describe())
+	}
+	player := gs.state.CurrentPlayer
+	if !legalMove(gs.state.Board, player, mv) {
+		return fmt.Errorf("ui: illegal move %v for %s", mv, sideName(player))
+	}
+	total, err := gs.performMove(mv, player)
+	if err != nil {
+		return err
+	}
+	gs.aiDelayUntil = c.now.Add(total)
+	gs.selected = nil
+	return nil
+}
+
+// Premove 对手落子提交前预走 mv，等同于动画期间点击起点和终点；提交后同一帧落下
+func (c *Controller) Premove(mv game.Move) error {
+	gs := c.gs
+	if gs.pendingCommit == nil {
+		return fmt.Errorf("ui: nothing pending, use Move")
+	}
+	st, _, ok := gs.inputState()
+	if !ok {
+		return fmt.Errorf("ui: no human to move after the pending commit")
+	}
+	return gs.queuePremove(st, mv)
+}
+
+// Settle 一直跑到画面空闲（动画放完、落子已提交、轮到人走或终局），并检查画面与局面一致。
+// 虚拟时间超过 limit 仍未空闲视为卡死；AI 搜索在后台真实运行，等待它时不推进虚拟时间。
+func (c *Controller) Settle(limit time.Duration) error {
+	start := c.now
+	realDeadline := time.Now().Add(30 * time.Second)
+	for {
+		if !c.Busy() && (c.gs.state.GameOver || !c.gs.isAITurn()) {
+			return c.checkVisible()
+		}
+		if c.now.Sub(start) > limit || time.Now().After(realDeadline) {
+			return fmt.Errorf("ui: not settled after %s: %s", c.now.Sub(start), c.describe())
+		}
+		if c.gs.aiBusy() {
+			time.Sleep(time.Millisecond)
+			if err := c.gs.Update(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := c.Step(); err != nil {
+			return err
+		}
+	}
+}
+
+// PlayGame 人类一方由 pick 选着，AI 一方照常搜索，直到终局或走满 maxPlies 手
+func (c *Controller) PlayGame(pick func(st *game.GameState) game.Move, maxPlies int, limit time.Duration) error {
+	for ply := 0; ply < maxPlies; ply++ {
+		if err := c.Settle(limit); err != nil {
+			return fmt.Errorf("ply %d: %w", ply, err)
+		}
+		if c.gs.state.GameOver {
+			return nil
+		}
+		st := *c.gs.state
+		st.Board = c.gs.state.Board.Clone()
+		if err := c.Move(pick(&st)); err != nil {
+			return fmt.Errorf("ply %d: %w", ply, err)
+		}
+	}
+	return c.Settle(limit)
+}
+
+// View 画面上可见的状态
+type View struct {
+	Cells         [game.BoardN]game.CellState // 每格画出来的棋子：临时隐藏的格子算空，幽灵棋子算对应一方
+	Board         *game.Board                 // 逻辑局面（副本）
+	ToMove        game.CellState
+	GameOver      bool
+	Winner        game.CellState
+	Selected      *game.HexCoord
+	Premove       *game.Move // 已排队的预走
+	PendingCommit bool
+	Animations    int
+	AIThinking    bool
+}
+
+func (c *Controller) View() View {
+	gs := c.gs
+	v := View{
+		Board:         gs.state.Board.Clone(),
+		ToMove:        gs.state.CurrentPlayer,
+		GameOver:      gs.state.GameOver,
+		Winner:        gs.state.Winner,
+		PendingCommit: gs.pendingCommit != nil,
+		Animations:    len(gs.anims),
+		AIThinking:    gs.showThinking,
+	}
+	if gs.selected != nil {
+		sel := *gs.selected
+		v.Selected = &sel
+	}
+	if gs.premove != nil {
+		mv := *gs.premove
+		v.Premove = &mv
+	}
+	// 与 Draw 的顺序一致：先画局面（跳过隐藏格），再叠幽灵棋子
+	for i := 0; i < game.BoardN; i++ {
+		v.Cells[i] = gs.state.Board.Cells[i]
+		if _, hidden := gs.tempHide[game.CoordOf[i]]; hidden && v.Cells[i] != game.Blocked {
+			v.Cells[i] = game.Empty
+		}
+		if _, hidden := gs.hiddenPieces[gs.state.Board.PieceAt(game.CoordOf[i])]; hidden {
+			v.Cells[i] = game.Empty
+		}
+	}
+	for _, g := range gs.tempGhosts {
+		if c.now.Before(g.showAt) || c.now.After(g.hideAt) {
+			continue
+		}
+		if i, ok := game.IndexOf[g.coord]; ok {
+			v.Cells[i] = g.player
+		}
+	}
+	return v
+}
+
+// checkVisible 空闲时画面必须和局面完全一致
+func (c *Controller) checkVisible() error {
+	v := c.View()
+	for i := 0; i < game.BoardN; i++ {
+		if v.Cells[i] != v.Board.Cells[i] {
+			return fmt.Errorf("ui: settled but %v shows %s, board has %s",
+				game.CoordOf[i], sideName(v.Cells[i]), sideName(v.Board.Cells[i]))
+		}
+	}
+	return nil
+}
+
+func (c *Controller) describe() string {
+	gs := c.gs
+	s := fmt.Sprintf("toMove=%s anims=%d hideWindows=%d ghosts=%d aiRunning=%v aiQueued=%v",
+		sideName(gs.state.CurrentPlayer), len(gs.anims), len(gs.hideWindows), len(gs.tempGhosts),
+		gs.aiRunning, gs.aiQueuedMove != nil)
+	if pc := gs.pendingCommit; pc != nil {
+		s += fmt.Sprintf(" pendingCommit=%v due in %s", pc.move, pc.when.Sub(c.now))
+	}
+	return s
+}
+
+func sideName(s game.CellState) string {
+	switch s {
+	case game.PlayerA:
+		return "red"
+	case game.PlayerB:
+		return "white"
+	case game.Blocked:
+		return "blocked"
+	}
+	return "empty"
+}
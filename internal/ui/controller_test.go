@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"hexxagon_go/internal/game"
+)
+
+func newTestController(t *testing.T, cfg GameConfig) *Controller {
+	t.Helper()
+	c, err := NewHeadless(cfg)
+	if err != nil {
+		t.Skipf("无法创建无头界面: %v", err)
+	}
+	return c
+}
+
+func pvpConfig() GameConfig {
+	cfg := DefaultGameConfig()
+	cfg.Mode = "pvp"
+	return cfg
+}
+
+// firstMove 优先克隆，保证对局能推进
+func firstMove(st *game.GameState) game.Move {
+	mvs := game.GenerateMoves(st.Board, st.CurrentPlayer)
+	for _, mv := range mvs {
+		if mv.IsClone() {
+			return mv
+		}
+	}
+	return mvs[0]
+}
+
+func TestControllerCommitsAfterAnimation(t *testing.T) {
+	c := newTestController(t, pvpConfig())
+	before := c.View().Board
+
+	mv := firstMove(c.Screen().state)
+	if err := c.Move(mv); err != nil {
+		t.Fatal(err)
+	}
+	v := c.View()
+	if !v.PendingCommit {
+		t.Fatal("落子后应先播动画，不应立即提交")
+	}
+	if v.Board.Hash() != before.Hash() {
+		t.Fatal("动画结束前逻辑局面不应改变")
+	}
+	if err := c.Move(mv); err == nil {
+		t.Fatal("画面忙时应拒绝再次落子")
+	}
+
+	if err := c.Settle(10 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	v = c.View()
+	if v.PendingCommit || v.ToMove != game.PlayerB {
+		t.Fatalf("提交后应轮到白方: pending=%v toMove=%v", v.PendingCommit, v.ToMove)
+	}
+	if v.Board.Cells[game.IndexOf[mv.To]] != game.PlayerA {
+		t.Fatalf("落点 %v 应为红子", mv.To)
+	}
+}
+
+// 跳跃动画期间按棋子编号隐藏起点那颗子，提交后它出现在落点
+func TestControllerHidesJumpingPiece(t *testing.T) {
+	c := newTestController(t, pvpConfig())
+	gs := c.Screen()
+	mv := game.Move{From: game.HexCoord{Q: 4, R: 0}, To: game.HexCoord{Q: 2, R: 1}}
+	id := gs.state.Board.PieceAt(mv.From)
+	if err := c.Move(mv); err != nil {
+		t.Fatal(err)
+	}
+	if _, hidden := gs.hiddenPieces[id]; !hidden || c.View().Cells[game.IndexOf[mv.From]] != game.Empty {
+		t.Fatal("动画期间起点的子应隐藏")
+	}
+	if err := c.Settle(10 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if len(gs.hiddenPieces) != 0 || gs.state.Board.PieceAt(mv.To) != id {
+		t.Fatalf("提交后应取消隐藏，编号跟到落点: %v", gs.hiddenPieces)
+	}
+	if v := c.View(); v.Cells[game.IndexOf[mv.To]] != game.PlayerA || v.Cells[game.IndexOf[mv.From]] != game.Empty {
+		t.Fatal("提交后画面不对")
+	}
+}
+
+func TestControllerRejectsIllegalMove(t *testing.T) {
+	c := newTestController(t, pvpConfig())
+	if err := c.Move(game.Move{From: game.HexCoord{Q: 0, R: 0}, To: game.HexCoord{Q: 0, R: 1}}); err == nil {
+		t.Fatal("非法着法应报错")
+	}
+}
+
+func TestControllerPlaysFullGameAgainstBot(t *testing.T) {
+	cfg := DefaultGameConfig()
+	cfg.Evaluator = EvalStatic
+	cfg.Bot = game.BotGreedy
+	cfg.Pacing = Pacing{InstantForced: true}
+	c := newTestController(t, cfg)
+
+	if err := c.PlayGame(firstMove, 200, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if v := c.View(); !v.GameOver {
+		t.Fatalf("200 手内应下完: toMove=%v", v.ToMove)
+	}
+}
+
+// 半径 3 的棋盘整盘走完，棋子始终不出盘
+func TestControllerPlaysSmallerRadius(t *testing.T) {
+	cfg := DefaultGameConfig()
+	cfg.Evaluator = EvalStatic
+	cfg.Bot = game.BotGreedy
+	cfg.Pacing = Pacing{InstantForced: true}
+	cfg.BoardRadius = 3
+	c := newTestController(t, cfg)
+	if r := game.ActiveRadius(c.View().Board); r != 3 {
+		t.Fatalf("开局半径 %d", r)
+	}
+
+	if err := c.PlayGame(firstMove, 200, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	v := c.View()
+	if !v.GameOver || game.ActiveRadius(v.Board) != 3 {
+		t.Fatalf("应在半径 3 内下完: over=%v radius=%d", v.GameOver, game.ActiveRadius(v.Board))
+	}
+}
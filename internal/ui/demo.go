@@ -0,0 +1,191 @@
+// File /ui/demo.go
+package ui
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"hexxagon_go/internal/game"
+)
+
+// 演示模式：双方都由 AI 走，带完整动画；每局轮换障碍布局和双方难度，连续不停。
+// 既当展示用，也当渲染 + 推理路径的长时间烤机测试——每局结束打一行日志，卡死或泄漏一眼能看出来。
+// -mode demo 启动即进入；对局中按 D 进入，Esc 退回进入前的模式（开一盘新局）。
+
+const demoRestartDelay = 3 * time.Second // 终局画面停留多久再开下一局
+
+type demoLayout struct {
+	name   string
+	blocks []game.HexCoord
+}
+
+// 布局都保持三重旋转对称，双方起点处境相同
+var demoLayouts = []demoLayout{
+	{"classic", game.DefaultBlocks},
+	{"open", nil},
+	{"ring", []game.HexCoord{{Q: 1, R: 0}, {Q: 1, R: -1}, {Q: 0, R: -1}, {Q: -1, R: 0}, {Q: -1, R: 1}, {Q: 0, R: 1}}},
+	{"spokes", []game.HexCoord{{Q: 2, R: 0}, {Q: -2, R: 2}, {Q: 0, R: -2}, {Q: -2, R: 0}, {Q: 2, R: -2}, {Q: 0, R: 2}}},
+}
+
+// 红、白双方的搜索深度，每轮完所有布局换下一组
+var demoDepths = [][2]int{{1, 1}, {1, 2}, {2, 1}, {2, 2}, {1, 3}, {3, 1}}
+
+type demoState struct {
+	n              int // 已开局数
+	layout         demoLayout
+	depthA, depthB int
+	started        time.Time
+	overAt         time.Time // 终局时刻（零值=进行中）
+	plies          int
+	wins           map[game.CellState]int
+
+	prevMode string // 退出时恢复
+	prevAI   bool
+}
+
+// StartDemo 进入演示模式并立即开第一局
+func (gs *GameScreen) StartDemo() {
+	if gs.demo != nil {
+		return
+	}
+	gs.demo = &demoState{wins: make(map[game.CellState]int), prevMode: gs.mode, prevAI: gs.aiEnabled}
+	gs.mode = "demo"
+	gs.aiEnabled = true
+	gs.nextDemoGame()
+}
+
+func (gs *GameScreen) stopDemo() {
+	d := gs.demo
+	gs.demo = nil
+	gs.mode, gs.aiEnabled = d.prevMode, d.prevAI
+	gs.resetGame(gs.newState())
+}
+
+func (gs *GameScreen) nextDemoGame() {
+	d := gs.demo
+	d.layout = demoLayouts[d.n%len(demoLayouts)]
+	dp := demoDepths[(d.n/len(demoLayouts))%len(demoDepths)]
+	d.depthA, d.depthB = dp[0], dp[1]
+	d.n++
+	d.started = gs.now()
+	d.overAt = time.Time{}
+	d.plies = 0
+	gs.resetGame(game.NewGameStateWithBlocks(game.DefaultBoardRadius, d.layout.blocks))
+}
+
+// resetGame 换成新局面并清掉上一局残留的搜索、动画和提示
+func (gs *GameScreen) resetGame(st *game.GameState) {
+	if gs.aiRunning {
+		close(gs.aiCancelCh)
+		gs.aiRunning = false
+	}
+	gs.state = st
+	gs.recorder.begin(st)
+	gs.history.begin(st)
+	gs.selected = nil
+	gs.premove = nil
+	gs.lastMove = nil
+	gs.ui = UIState{}
+	gs.aiJumpUnlocked = false
+	gs.aiQueuedMove = nil
+	gs.aiProgress = nil
+	gs.showThinking = false
+	gs.aiDelayUntil = time.Time{}
+	gs.aiThinkingUntil = time.Time{}
+	gs.turnStart = time.Time{}
+	gs.pendingClone = nil
+	gs.pendingCommit = nil
+	gs.anims = nil
+	gs.tempGhosts = nil
+	gs.hideWindows = nil
+	gs.tempHide = make(map[game.HexCoord]struct{})
+	gs.hiddenPieces = make(map[game.PieceID]struct{})
+	gs.pieceTree = game.PieceTree{}
+	gs.comments = commentLog{}
+	gs.boardBakedOK = false // 障碍格画在底图里
+	if gs.showScores {
+		gs.refreshMoveScores()
+	}
+}
+
+// updateDemo 处理演示模式的按键和终局换局；返回 true 表示本帧已处理完
+func (gs *GameScreen) updateDemo(now time.Time) bool {
+	if gs.demo == nil {
+		// 联机对局不能切演示，否则和对方的局面失步；回放里 D 也不管用
+		if gs.remote == nil && gs.mode != "replay" && inpututil.IsKeyJustPressed(ebiten.KeyD) {
+			gs.StartDemo()
+			return true
+		}
+		return false
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		gs.stopDemo()
+		return true
+	}
+	d := gs.demo
+	if !gs.state.GameOver || gs.isAnimating {
+		return false
+	}
+	if d.overAt.IsZero() {
+		d.overAt = now
+		d.wins[gs.state.Winner]++
+		gs.logDemoGame(now)
+		return false
+	}
+	if now.Sub(d.overAt) >= demoRestartDelay {
+		gs.nextDemoGame()
+		return true
+	}
+	return false
+}
+
+func (gs *GameScreen) logDemoGame(now time.Time) {
+	d := gs.demo
+	a, b := gs.state.GetScores()
+	msg := fmt.Sprintf("[demo] #%d %s red d%d vs white d%d: %d-%d, %d plies, %s (red %d / white %d / draw %d)",
+		d.n, d.layout.name, d.depthA, d.depthB, a, b, d.plies, now.Sub(d.started).Round(time.Second),
+		d.wins[game.PlayerA], d.wins[game.PlayerB], d.wins[game.Empty])
+	if st, ok := game.GetNNStats()["katago"]; ok {
+		msg += fmt.Sprintf(", nn %s avg %s over %d calls", st.Provider, st.AvgLatency().Round(time.Microsecond), st.Calls)
+	}
+	log.Print(msg)
+}
+
+// aiDepthFor 演示模式下双方各用各的深度；单独配置了 AI 的一方用它的深度
+func (gs *GameScreen) aiDepthFor(side game.CellState) int {
+	if d := gs.demo; d != nil {
+		if side == game.PlayerA {
+			return d.depthA
+		}
+		return d.depthB
+	}
+	if p := gs.aiPlayer(side); p != nil && p.Engine == AIEngineAB {
+		return p.Depth
+	}
+	return gs.aiDepth
+}
+
+// isAITurn 人机模式只有白方是 AI，演示和观战模式双方都是；联机时对方一方也算（界面不接受点击，着法从网络来）
+func (gs *GameScreen) isAITurn() bool {
+	return gs.aiControls(gs.state.CurrentPlayer)
+}
+
+// aiControls side 这一方是否由 AI 走
+func (gs *GameScreen) aiControls(side game.CellState) bool {
+	if gs.remote != nil {
+		return side == gs.remote.RemoteSide()
+	}
+	return gs.aiEnabled && (gs.spectating() || side == game.PlayerB)
+}
+
+func (gs *GameScreen) demoText() string {
+	d := gs.demo
+	if gs.searchTime > 0 {
+		return fmt.Sprintf("DEMO #%d  %s  %v per move  (Esc to exit)", d.n, d.layout.name, gs.searchTime)
+	}
+	return fmt.Sprintf("DEMO #%d  %s  red d%d vs white d%d  (Esc to exit)", d.n, d.layout.name, d.depthA, d.depthB)
+}
@@ -0,0 +1,81 @@
+// File /ui/fog.go
+package ui
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"hexxagon_go/internal/engine"
+	"hexxagon_go/internal/game"
+)
+
+// 迷雾模式：屏幕前的人只看得见自己棋子距离 2 以内的格子，其余格子盖上暗色、不画棋子和动画。
+// 人机时永远按红方视野；人人对战时按当前行棋方（轮流坐到屏幕前）；演示和终局不遮挡。
+type fogConfig struct {
+	enabled bool
+	ai      string // FogAIFull / FogAISample
+	samples int
+}
+
+func (f fogConfig) sampling() bool { return f.enabled && f.ai == FogAISample }
+
+// fogHidden 当前要遮住的格子；不遮挡时 ok=false
+func (gs *GameScreen) fogHidden() (hidden game.CellMask, ok bool) {
+	if !gs.fog.enabled || gs.spectating() || gs.state.GameOver {
+		return game.CellMask{}, false
+	}
+	viewer := gs.state.CurrentPlayer
+	if gs.aiEnabled {
+		viewer = game.PlayerA
+	} else if gs.remote != nil {
+		viewer = gs.remote.LocalSide()
+	}
+	return game.VisibleMask(gs.state.Board, viewer).Not(), true
+}
+
+func fogged(hidden game.CellMask, c game.HexCoord) bool {
+	i, ok := game.IndexOf[c]
+	return ok && hidden.Has(i)
+}
+
+// drawFog 用压暗的瓦片盖住看不见的格子
+func (gs *GameScreen) drawFog(dst *ebiten.Image, hidden game.CellMask) {
+	scale, originX, originY, tileW, tileH, vs := getBoardTransform(gs.tileImage)
+	iw, ih := float64(gs.tileImage.Bounds().Dx()), float64(gs.tileImage.Bounds().Dy())
+	for _, i := range hidden.Cells() {
+		c := game.CoordOf[i]
+		x := (float64(c.Q) + BoardRadius) * tileW * 0.75
+		y := (float64(c.R) + BoardRadius + float64(c.Q)/2) * vs
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(scale, scale)
+		op.GeoM.Translate(originX+(x+tileW/2)*scale-iw*scale/2, originY+(y+tileH/2)*scale-ih*scale/2)
+		op.ColorScale.Scale(0.18, 0.18, 0.24, 1)
+		dst.DrawImage(gs.tileImage, op)
+	}
+}
+
+// fogSearchWith 迷雾下按 AI 自己的视野采样确定化局面再搜索；不采样时等同 searchWith。
+// 按时间搜索时各样本平分 budget；任一样本的外部引擎出错即整体返回该错误
+func fogSearchWith(fog fogConfig, eng *engine.Client, bot botFunc, b *game.Board, side game.CellState, depth int, budget time.Duration, allowJump bool, onDepth func(game.SearchProgress)) (game.Move, bool, error) {
+	if !fog.sampling() {
+		return searchWith(eng, bot, b, side, depth, budget, allowJump, onDepth)
+	}
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	per := budget / time.Duration(fog.samples)
+	var firstErr error
+	mv, ok := game.FogSearch(b, side, fog.samples, rng, func(sample *game.Board) (game.Move, bool) {
+		if firstErr != nil {
+			return game.Move{}, false
+		}
+		mv, ok, err := searchWith(eng, bot, sample, side, depth, per, allowJump, onDepth)
+		if err != nil {
+			firstErr = err
+		}
+		return mv, ok
+	})
+	if firstErr != nil {
+		return game.Move{}, false, firstErr
+	}
+	return mv, ok, nil
+}
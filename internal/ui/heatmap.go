@@ -0,0 +1,59 @@
+// File /ui/heatmap.go
+package ui
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"hexxagon_go/internal/game"
+)
+
+// 终局复盘的“争夺热力图”：按每格本局易手（被感染翻色）的次数着色，次数越多越红越实，
+// 格子里写上次数。只在终局画面显示；H 随时切换（人机对局轮到人时 H 是提示，见 hint.go），-heatmap 让终局时默认打开。
+
+var heatColor = color.RGBA{0xFF, 0x70, 0x20, 0xFF}
+
+// pollHeatmapKey 每帧检查 H；能提示时交给 requestHint
+func (gs *GameScreen) pollHeatmapKey() {
+	if !inpututil.IsKeyJustPressed(ebiten.KeyH) {
+		return
+	}
+	if gs.canHint() {
+		gs.requestHint()
+		return
+	}
+	gs.showHeatmap = !gs.showHeatmap
+}
+
+// heatmapOn 终局且开着热力图；演示模式的终局只停几秒，不画
+func (gs *GameScreen) heatmapOn() bool {
+	return gs.showHeatmap && gs.state.GameOver && gs.demo == nil
+}
+
+// addHeatmap 把易手次数叠到标注层
+func (gs *GameScreen) addHeatmap(o *overlay) {
+	top := gs.state.MaxFlips()
+	if top == 0 {
+		return
+	}
+	for i, n := range gs.state.Flips {
+		if n == 0 {
+			continue
+		}
+		c := game.CoordOf[i]
+		a := 0.25 + 0.6*float64(n)/float64(top)
+		o.Fill(c, color.NRGBA{heatColor.R, heatColor.G, heatColor.B, uint8(255 * a)}, 0.8)
+		o.Text(c, fmt.Sprint(n), color.White, 0)
+	}
+}
+
+// heatmapText 终局时的提示行
+func (gs *GameScreen) heatmapText() string {
+	if !gs.heatmapOn() {
+		return "Press H for battle heatmap"
+	}
+	return fmt.Sprintf("Battle heatmap: most contested cell changed hands %d times (H to hide)", gs.state.MaxFlips())
+}
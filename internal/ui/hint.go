@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"image/color"
+	"math"
+	"time"
+
+	"hexxagon_go/internal/game"
+)
+
+// 提示：人机对局轮到人时按 H，后台给人这一方做一次浅层搜索，算完后把建议着法的起点、落点
+// 和两者之间的箭头画成一闪一闪的高亮，直到局面变了（走了一步、读档、换局）为止。
+// 搜索在 goSearch 协程里做、有时间上限，Update 每帧非阻塞地取结果；算的时候照常能点子走棋，
+// 走了之后回来的结果对不上局面，直接丢掉。终局时 H 仍是热力图开关，见 heatmap.go。
+// 迷雾模式不给提示（和评分提示一样会泄露看不见的子）。
+
+const (
+	hintDepth  = 2                      // 提示的搜索深度：求快，不求和 AI 一样强
+	hintBudget = 800 * time.Millisecond // 时间上限，到点用已完成的最深一层
+	hintPulse  = 900 * time.Millisecond // 高亮一明一暗的周期
+)
+
+var hintPulseColor = color.RGBA{0x40, 0xE0, 0x40, 0xFF}
+
+type hintResult struct {
+	gen  int
+	move game.Move
+	ok   bool
+}
+
+type hintState struct {
+	gen     int             // 最新一次请求的编号，用来丢弃过期结果
+	ch      chan hintResult // 后台搜索的结果
+	pending bool            // 有请求在算
+	hash    uint64          // 提示对应的局面；局面变了就不再画
+	move    *game.Move      // 算好的建议着法
+	shownAt time.Time       // 开始显示的时刻，脉冲相位从这里算
+}
+
+// canHint 现在按 H 是否要提示：人机对局、轮到人、未终局、没开迷雾
+func (gs *GameScreen) canHint() bool {
+	return gs.mode == "pve" && gs.demo == nil && gs.remote == nil && !gs.fog.enabled &&
+		!gs.state.GameOver && !gs.isAITurn()
+}
+
+// requestHint 为当前局面启动一次提示搜索；同一局面已有提示或正在算时不重复
+func (gs *GameScreen) requestHint() {
+	h := &gs.hint
+	hash := gs.state.Board.Hash()
+	if h.hash == hash && (h.pending || h.move != nil) {
+		return
+	}
+	if h.ch == nil {
+		h.ch = make(chan hintResult, 1)
+	}
+	h.gen++
+	h.pending = true
+	h.hash = hash
+	h.move = nil
+	gen, out := h.gen, h.ch
+	b := gs.state.Board.Clone()
+	side := gs.state.CurrentPlayer
+	allowJump := gs.aiJumpUnlocked // 与 AI 相同的跳跃门槛，浅层搜索不至于早早建议跳
+	goSearch(func() {
+		mv, ok := game.FindBestMoveWithBudgetDepth(b, side, hintDepth, hintBudget, allowJump, nil)
+		// 容量 1：旧结果还没被取走就换成新的
+		select {
+		case <-out:
+		default:
+		}
+		out <- hintResult{gen: gen, move: mv, ok: ok}
+	})
+}
+
+// pollHint 每帧调用：取回提示结果，局面已经变了的丢掉
+func (gs *GameScreen) pollHint(now time.Time) {
+	h := &gs.hint
+	select {
+	case r := <-h.ch:
+		if r.gen != h.gen {
+			return
+		}
+		h.pending = false
+		if h.hash != gs.state.Board.Hash() {
+			return
+		}
+		if !r.ok {
+			gs.showToast("No hint available", 2*time.Second)
+			return
+		}
+		mv := r.move
+		h.move = &mv
+		h.shownAt = now
+	default:
+	}
+}
+
+// hintShown 当前局面是否有提示要画
+func (gs *GameScreen) hintShown() bool {
+	return gs.hint.move != nil && gs.hint.hash == gs.state.Board.Hash() && !gs.state.GameOver
+}
+
+// hintAlpha 脉冲的不透明度，在 [0.25, 0.85] 间按正弦来回
+func hintAlpha(elapsed time.Duration) float64 {
+	phase := 2 * math.Pi * float64(elapsed%hintPulse) / float64(hintPulse)
+	return 0.55 - 0.3*math.Cos(phase)
+}
+
+// addHint 把提示画进标注层：起点、落点一闪一闪，中间一支箭头
+func (gs *GameScreen) addHint(o *overlay) {
+	if !gs.hintShown() {
+		return
+	}
+	mv := *gs.hint.move
+	a := uint8(255 * hintAlpha(gs.now().Sub(gs.hint.shownAt)))
+	clr := color.NRGBA{hintPulseColor.R, hintPulseColor.G, hintPulseColor.B, a}
+	o.Fill(mv.From, clr, 0.7)
+	o.Fill(mv.To, clr, 0.9)
+	o.Arrow(mv.From, mv.To, clr, 0.08)
+}
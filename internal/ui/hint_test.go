@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"hexxagon_go/internal/game"
+)
+
+// 轮到人时请求提示：结果回来后起点、落点各一块高亮加一支箭头；走了一步就不再画
+func TestHintHighlightsSuggestedMove(t *testing.T) {
+	cfg := DefaultGameConfig()
+	cfg.Evaluator = EvalStatic
+	c := newTestController(t, cfg)
+	gs := c.Screen()
+	if !gs.canHint() {
+		t.Fatal("人机对局开局轮到人，应能提示")
+	}
+	gs.requestHint()
+	deadline := time.Now().Add(10 * time.Second)
+	for gs.hint.pending && time.Now().Before(deadline) {
+		gs.pollHint(c.Now())
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !gs.hintShown() {
+		t.Fatal("提示没有算出来")
+	}
+	mv := *gs.hint.move
+	if err := game.ValidateMove(gs.state, mv); err != nil {
+		t.Fatalf("提示的着法不合法: %v", err)
+	}
+
+	gs.buildOverlay()
+	o := gs.overlay
+	if len(o.fills) != 2 || o.fills[0].c != mv.From || o.fills[1].c != mv.To || len(o.arrows) != 1 {
+		t.Fatalf("提示应高亮起点、落点并画箭头: fills=%+v arrows=%+v", o.fills, o.arrows)
+	}
+
+	if err := c.Move(mv); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Settle(10 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if gs.hintShown() {
+		t.Fatal("局面变了，提示应消失")
+	}
+}
+
+func TestHintAlphaPulses(t *testing.T) {
+	lo, hi := 1.0, 0.0
+	for d := time.Duration(0); d < hintPulse; d += hintPulse / 20 {
+		a := hintAlpha(d)
+		lo, hi = min(lo, a), max(hi, a)
+	}
+	if lo < 0.2 || hi > 0.9 || hi-lo < 0.5 {
+		t.Fatalf("脉冲范围 [%.2f, %.2f] 不对", lo, hi)
+	}
+}
@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"hexxagon_go/internal/game"
+)
+
+// 闲置节流：窗口失焦，或终局画面放着没人管超过 idleAfter 时，
+// 降到最低帧率（只在需要时重绘，Update 每秒 idleTPS 次）、暂停音效、不再启动新的 AI 搜索和蒙特卡洛提示，
+// 并在后台释放 NN 批量会话（见 game.SetNNBatchIdle）。回到前台当帧恢复：帧率和音效立即还原，
+// 批量会话在后台重建。进入闲置时已经在跑的 AI 搜索当场叫停（game.AbortSearches）、结果作废，
+// 回到前台后从头重搜。
+// 这个界面没有菜单页，终局画面就是“停在那里等人”的地方。
+// 无头运行（Controller 的虚拟时钟）不看焦点，永远不闲置。
+
+const idleAfter = 30 * time.Second
+
+type idleState struct {
+	on        bool
+	overSince time.Time // 进入终局画面的时刻（零值=不在终局）
+	staleTips bool      // 闲置期间跳过了提示刷新，恢复时补算
+}
+
+// idleWanted 是否应处于闲置：失焦，或终局画面已放了 idleAfter
+func idleWanted(focused bool, overFor time.Duration) bool {
+	return !focused || overFor >= idleAfter
+}
+
+// updateIdle 每帧调用：按焦点和终局时长切换闲置状态，返回当前是否闲置
+func (gs *GameScreen) updateIdle(now time.Time) bool {
+	if gs.clock != nil {
+		return false
+	}
+	over := gs.state.GameOver && gs.demo == nil && gs.mode != "replay"
+	switch {
+	case !over:
+		gs.idle.overSince = time.Time{}
+	case gs.idle.overSince.IsZero():
+		gs.idle.overSince = now
+	}
+	var overFor time.Duration
+	if !gs.idle.overSince.IsZero() {
+		overFor = now.Sub(gs.idle.overSince)
+	}
+
+	want := idleWanted(ebiten.IsFocused(), overFor)
+	if want == gs.idle.on {
+		return want
+	}
+	gs.idle.on = want
+	idleOn = want
+	applyPerf()
+	if want && gs.aiRunning {
+		close(gs.aiCancelCh)
+		gs.aiRunning = false
+		gs.showThinking = false
+		gs.aiThinkingUntil = time.Time{}
+		game.AbortSearches()
+	}
+	go game.SetNNBatchIdle(want)
+	if want {
+		gs.audioManager.Pause()
+		return true
+	}
+	gs.audioManager.Resume()
+	if gs.idle.staleTips && gs.showScores {
+		gs.refreshMoveScores()
+	}
+	gs.idle.staleTips = false
+	return false
+}
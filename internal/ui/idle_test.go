@@ -0,0 +1,33 @@
+package ui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIdleWanted(t *testing.T) {
+	cases := []struct {
+		focused bool
+		overFor time.Duration
+		want    bool
+	}{
+		{true, 0, false},
+		{false, 0, true},
+		{true, idleAfter - time.Second, false},
+		{true, idleAfter, true},
+	}
+	for _, c := range cases {
+		if got := idleWanted(c.focused, c.overFor); got != c.want {
+			t.Errorf("idleWanted(%v, %v) = %v，应为 %v", c.focused, c.overFor, got, c.want)
+		}
+	}
+}
+
+// 无头控制器不看焦点：失焦判断不能卡住 AI 搜索
+func TestHeadlessNeverIdles(t *testing.T) {
+	c := newTestController(t, pvpConfig())
+	gs := c.Screen()
+	if gs.updateIdle(c.Now()) || gs.idle.on {
+		t.Fatal("无头界面进入了闲置")
+	}
+}
@@ -4,31 +4,39 @@ package ui
 import (
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"math"
-	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"hexxagon_go/internal/game"
 )
 
 type UIState struct {
-	From       *game.HexCoord            // 当前选中的起点（nil 表示未选中）
-	MoveScores map[game.HexCoord]float64 // 起点到各个合法终点的评估分数
-	WinProbA   float64                   // 始终存储玩家 A (红色) 的胜率 [0, 1]
+	From       *game.HexCoord                  // 当前选中的起点（nil 表示未选中）
+	MoveScores map[game.HexCoord]float64       // 起点到各个合法终点的评估分数
+	WinProbA   float64                         // 始终存储玩家 A (红色) 的胜率 [0, 1]
+	Ownership  []float32                       // 模型预测的各格最终归属（A 视角，+1 红 / -1 白，AxialToIndex 排列）；模型无此头时为 nil
+	MoveInfo   map[game.HexCoord]game.MoveInfo // 选中起点后各终点的着法信息（吃子数等），不依赖模型
 }
 
+// viewRadius 画面放大到的半径：小棋盘（盘外全是障碍）铺满窗口，而不是缩在正中。
+// 棋盘底图重新烘焙时按当前局面更新（换局面必然重烘焙），整个进程只有一个界面
+var viewRadius = BoardRadius
+
+// getBoardTransform 格子像素坐标 → 屏幕的缩放与平移。各处的像素坐标都以半径 BoardRadius 的
+// 整盘左上角为原点，这里把 viewRadius 以内的部分居中铺满窗口
 func getBoardTransform(tileImg *ebiten.Image) (scale, orgX, orgY, tileW, tileH, vs float64) {
 	tileW = float64(tileImg.Bounds().Dx())
 	tileH = float64(tileImg.Bounds().Dy())
 	vs = tileH * math.Sqrt(3) / 2
 
-	cols := 2*BoardRadius + 1
-	rows := 2*BoardRadius + 1
+	cols := 2*viewRadius + 1
+	rows := 2*viewRadius + 1
 	boardW := float64(cols-1)*tileW*0.75 + tileW
 	boardH := vs*float64(rows-1) + tileH
 
 	scale = math.Min(float64(WindowWidth)/boardW, float64(WindowHeight)/boardH)
-	orgX = (float64(WindowWidth) - boardW*scale) / 2
-	orgY = (float64(WindowHeight) - boardH*scale) / 2
+	inset := float64(BoardRadius - viewRadius)
+	orgX = (float64(WindowWidth)-boardW*scale)/2 - inset*tileW*0.75*scale
+	orgY = (float64(WindowHeight)-boardH*scale)/2 - inset*vs*scale
 	return
 }
 
@@ -81,22 +89,35 @@ func pixelToAxial(fx, fy float64, board *game.Board, tileImg *ebiten.Image) (gam
 	return coord, board.InBounds(coord)
 }
 
-// handleInput 处理鼠标点击事件，用于选中、移动并播放音效
+// handleInput 处理鼠标点击事件，用于选中、移动并播放音效。
+// 对手的落子还在播放时按提交后的局面预选/预走，见 premove.go
 func (gs *GameScreen) handleInput() {
 	// 只处理鼠标左键刚按下
 	if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
 		return
 	}
+	st, pre, ok := gs.inputState()
+	if !ok {
+		return
+	}
+	if pre && gs.premove != nil {
+		// 已排队的预走：再点一次就撤销，接着按这次点击重新选
+		gs.premove = nil
+		gs.selected = nil
+	}
+	board := st.Board
 
 	// 屏幕坐标 -> 棋盘坐标
 	mx, my := ebiten.CursorPosition()
-	coord, ok := pixelToAxial(float64(mx), float64(my), gs.state.Board, gs.tileImage)
+	coord, ok := pixelToAxial(float64(mx), float64(my), board, gs.tileImage)
 	if !ok {
 		gs.audioManager.Play("cancel_select_piece")
 		return
 	}
 
-	player := gs.state.CurrentPlayer
+	player := st.CurrentPlayer
+	// 预走时局面还没落盘，评分提示等提交后再刷新
+	refresh := gs.showScores && !pre
 
 	// 坐标 -> 下标
 	toIdx, okTo := game.IndexOf[coord] // 如果你没导出 indexOf，就在本包内用 indexOf[coord]
@@ -107,10 +128,10 @@ func (gs *GameScreen) handleInput() {
 
 	// —— 尚未选中：尝试选中自己的棋子 —— //
 	if gs.selected == nil {
-		if gs.state.Board.Cells[toIdx] == player { // 数组下标直读
+		if board.Cells[toIdx] == player { // 数组下标直读
 			gs.selected = &game.HexCoord{Q: coord.Q, R: coord.R}
 			gs.audioManager.Play("select_piece")
-			if gs.showScores {
+			if refresh {
 				gs.refreshMoveScores()
 			}
 		} else {
@@ -123,15 +144,15 @@ func (gs *GameScreen) handleInput() {
 	move := game.Move{From: *gs.selected, To: coord}
 
 	// 目标必须为空；若点到自己棋子＝切换选中；否则取消
-	if gs.state.Board.Cells[toIdx] != game.Empty {
-		if gs.state.Board.Cells[toIdx] == player {
+	if board.Cells[toIdx] != game.Empty {
+		if board.Cells[toIdx] == player {
 			gs.selected = &game.HexCoord{Q: coord.Q, R: coord.R}
 			gs.audioManager.Play("select_piece")
 		} else {
 			gs.selected = nil
 			gs.audioManager.Play("cancel_select_piece")
 		}
-		if gs.showScores {
+		if refresh {
 			gs.refreshMoveScores()
 		}
 		return
@@ -156,22 +177,32 @@ func (gs *GameScreen) handleInput() {
 	}
 	if !valid {
 		// 非法落点：同上逻辑，点到自己＝切换选中；否则取消
-		if gs.state.Board.Cells[toIdx] == player {
+		if board.Cells[toIdx] == player {
 			gs.selected = &game.HexCoord{Q: coord.Q, R: coord.R}
 			gs.audioManager.Play("select_piece")
 		} else {
 			gs.selected = nil
 			gs.audioManager.Play("cancel_select_piece")
 		}
-		if gs.showScores {
+		if refresh {
 			gs.refreshMoveScores()
 		}
 		return
 	}
 
+	if pre {
+		if err := gs.queuePremove(st, move); err != nil {
+			gs.selected = nil
+			gs.audioManager.Play("cancel_select_piece")
+		} else {
+			gs.audioManager.Play("select_piece")
+		}
+		return
+	}
+
 	// 真正落子
 	if total, err := gs.performMove(move, player); err != nil {
-		if gs.state.Board.Cells[toIdx] == player {
+		if board.Cells[toIdx] == player {
 			enterPerf()
 			gs.selected = &game.HexCoord{Q: coord.Q, R: coord.R}
 			gs.audioManager.Play("select_piece")
@@ -181,10 +212,10 @@ func (gs *GameScreen) handleInput() {
 		}
 	} else {
 		// 成功：设置 AI 延迟并清空选中
-		gs.aiDelayUntil = time.Now().Add(total)
+		gs.aiDelayUntil = gs.now().Add(total)
 		gs.selected = nil
 	}
-	if gs.showScores {
+	if refresh {
 		gs.refreshMoveScores()
 	}
 }
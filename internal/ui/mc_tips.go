@@ -0,0 +1,89 @@
+// File /ui/mc_tips.go
+package ui
+
+import (
+	"time"
+
+	"hexxagon_go/internal/game"
+)
+
+// 没有 ONNX 时，胜率条和落点提示改用蒙特卡洛快速对局估计。
+// 估计放在后台协程里做并有时间上限，Update 每帧非阻塞地取结果，不会卡住画面。
+const (
+	mcPlayouts = 200                    // 全局胜率的对局数
+	mcPerMove  = 40                     // 每个落点的对局数
+	mcBudget   = 300 * time.Millisecond // 全局胜率、落点提示各自的时间上限
+)
+
+type mcTips struct {
+	gen    int
+	winA   float64
+	hasWin bool
+	scores map[game.HexCoord]float64
+}
+
+// startMCTips 以当前局面启动一次估计；selected 非 nil 时顺带估计该子各落点的胜率
+func (gs *GameScreen) startMCTips(selected *game.HexCoord) {
+	if gs.idle.on {
+		gs.idle.staleTips = true // 闲置时不起后台估计，回到前台再算
+		return
+	}
+	if gs.mcCh == nil {
+		gs.mcCh = make(chan mcTips, 8)
+	}
+	gs.mcGen++
+	gen, out := gs.mcGen, gs.mcCh
+	b := gs.state.Board.Clone()
+	player := gs.state.CurrentPlayer
+
+	var moves []game.Move
+	if selected != nil {
+		for _, mv := range game.GenerateMoves(b, player) {
+			if mv.From == *selected {
+				moves = append(moves, mv)
+			}
+		}
+	}
+
+	goSearch(func() {
+		winA, n := game.MCWinProb(b, player, game.PlayerA, mcPlayouts, time.Now().Add(mcBudget))
+		res := mcTips{gen: gen, winA: winA, hasWin: n > 0}
+		if len(moves) > 0 {
+			res.scores = make(map[game.HexCoord]float64, len(moves))
+			probs := game.MCMoveWinProbs(b, player, moves, mcPerMove, time.Now().Add(mcBudget))
+			for i, p := range probs {
+				if p >= 0 {
+					res.scores[moves[i].To] = p * 100
+				}
+			}
+		}
+		// 通道满了说明 UI 还没来得及取，丢掉这份即可（更新的请求会再算）
+		select {
+		case out <- res:
+		default:
+		}
+	})
+}
+
+// pollMCTips 每帧调用：取回最新一次估计，过期的结果丢弃
+func (gs *GameScreen) pollMCTips() {
+	for {
+		select {
+		case r := <-gs.mcCh:
+			if r.gen != gs.mcGen || !gs.showScores {
+				continue
+			}
+			if r.hasWin {
+				gs.ui.WinProbA = r.winA
+			}
+			if gs.ui.MoveScores == nil {
+				gs.ui.MoveScores = make(map[game.HexCoord]float64)
+			}
+			for to, s := range r.scores {
+				gs.ui.MoveScores[to] = s
+			}
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// File /ui/netplay.go
+package ui
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"hexxagon_go/internal/game"
+	"hexxagon_go/internal/net"
+)
+
+// 联机对战：对方一方在界面里按 AI 一方处理（aiControls 为真，不接受点击、可以预走），
+// 只是着法不来自搜索而是 net.Session；收到的着法放进 aiQueuedMove，和 AI 落子走同一条 performMove 动画。
+// 本方的每一手（含预走、快棋超时代走）都经过 performMove，在那里发给对方。
+
+// SetRemote 接入联机会话：remote 一方由网络另一端的玩家走。
+// 局面换成会话的开局（客户端的地图、先手与规则都以主机为准），要在第一手之前调用。
+func (gs *GameScreen) SetRemote(s *net.Session) {
+	gs.remote = s
+	gs.mode = "net"
+	gs.aiEnabled = false
+	start := s.Start()
+	gs.newState = func() *game.GameState {
+		st := *start
+		st.Board = start.Board.Clone()
+		return &st
+	}
+	gs.state = gs.newState()
+	gs.recorder.begin(gs.state)
+	gs.history.begin(gs.state)
+	gs.boardBakedOK = false // 障碍格画在底图里
+	if gs.showScores {
+		gs.refreshMoveScores()
+	}
+}
+
+// OpeningState 本局地图的开局（新副本）；联机主机拿它开会话
+func (gs *GameScreen) OpeningState() *game.GameState { return gs.newState() }
+
+// sendRemote 本方落子发给对方；对方的着法是从网络收来的，不回发
+func (gs *GameScreen) sendRemote(mv game.Move, player game.CellState) {
+	if gs.remote == nil || player == gs.remote.RemoteSide() {
+		return
+	}
+	if err := gs.remote.Send(mv); err != nil {
+		log.Printf("联机: 发送 %v 失败: %v", mv, err)
+	}
+}
+
+// pollRemote 轮到对方时每帧调用：收到着法就排进 aiQueuedMove，下一帧开始播
+func (gs *GameScreen) pollRemote(now time.Time) {
+	if gs.aiQueuedMove != nil {
+		return
+	}
+	if mv, ok := gs.remote.Poll(); ok {
+		gs.aiQueuedMove = &mv
+		gs.aiThinkingUntil = now
+	}
+}
+
+// remoteText 联机状态提示；一切正常且轮到本方时为空
+func (gs *GameScreen) remoteText() string {
+	if gs.remote == nil || gs.state.GameOver {
+		return ""
+	}
+	switch gs.remote.Status() {
+	case net.Connecting:
+		return "Waiting for opponent to connect on " + gs.remote.Addr()
+	case net.Reconnecting:
+		return "Opponent disconnected, reconnecting..."
+	case net.PeerLeft:
+		return "Opponent left the game"
+	}
+	if d := gs.remote.Desync(); len(d) > 0 {
+		return fmt.Sprintf("Board out of sync with opponent (%d cells differ)", len(d))
+	}
+	if gs.isAITurn() {
+		return "Waiting for opponent's move..."
+	}
+	return ""
+}
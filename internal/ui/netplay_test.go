@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"hexxagon_go/internal/game"
+	"hexxagon_go/internal/net"
+)
+
+// 主机界面执红，对方（白）在另一端用 net.Session 直接走：红方落子要发到对方，白方着法要像本地一样播完再提交
+func TestNetRemoteMovesAnimateLikeLocal(t *testing.T) {
+	cfg := DefaultGameConfig()
+	cfg.Mode = "net"
+	c := newTestController(t, cfg)
+	gs := c.Screen()
+
+	host, err := net.Listen("127.0.0.1:0", gs.OpeningState(), game.PlayerB)
+	if err != nil {
+		t.Skipf("无法监听: %v", err)
+	}
+	defer host.Close()
+	gs.SetRemote(host)
+	peer, err := net.Dial(host.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer peer.Close()
+	peerState := peer.Start()
+
+	mv := firstMove(gs.state)
+	if err := c.Move(mv); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-peer.Ready():
+	case <-time.After(3 * time.Second):
+		t.Fatal("对方没收到红方的着法")
+	}
+	if got, _ := peer.Poll(); got != mv {
+		t.Fatalf("对方收到 %v，应为 %v", got, mv)
+	}
+	if _, _, err := peerState.MakeMove(mv); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Settle(10 * time.Second); err == nil {
+		t.Fatal("轮到对方且还没收到着法，不应空闲")
+	}
+	if err := c.Move(firstMove(peerState)); err == nil {
+		t.Fatal("对方的回合界面不应接受本地落子")
+	}
+
+	reply := firstMove(peerState)
+	if err := peer.Send(reply); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-host.Ready():
+	case <-time.After(3 * time.Second):
+		t.Fatal("主机没收到白方的着法")
+	}
+	sawPending := false
+	for i := 0; i < 5 && !sawPending; i++ {
+		c.Step()
+		sawPending = c.View().PendingCommit
+	}
+	if !sawPending {
+		t.Fatal("对方着法应先播动画再提交")
+	}
+	if err := c.Settle(10 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	v := c.View()
+	if v.ToMove != game.PlayerA || v.Board.Cells[game.IndexOf[reply.To]] != game.PlayerB {
+		t.Fatalf("白方着法 %v 未落盘: toMove=%v", reply, v.ToMove)
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"runtime"
 	"runtime/debug"
 	"sync"
-	"time"
 )
 
 // AnimOffset 给每个动画 key 一个手动微调 (X, Y)，单位：像素
@@ -68,14 +67,6 @@ var AnimOffset = map[string]struct{ X, Y float64 }{
 	"whiteBecomeRed": {X: 117, Y: -1},
 }
 
-var soundDurations = map[string]time.Duration{
-	"white_split":              470 * time.Millisecond,
-	"white_jump":               496 * time.Millisecond,
-	"white_capture_red_before": 653 * time.Millisecond,
-	"white_capture_red_after":  548 * time.Millisecond,
-	"all_capture_after":        400 * time.Millisecond,
-	// 如果还有别的 key 也记得加上
-}
 var trimOffsets = map[string][]struct{ X, Y int }{}
 
 func getTrimOffset(key string, i int) (float64, float64) {
@@ -0,0 +1,107 @@
+// File /ui/options.go
+package ui
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"hexxagon_go/internal/game"
+)
+
+// 搜索/评估参数热加载：参数文件改动、收到 SIGHUP 或按 F5 时重读。
+// 读到的新参数先排队，等后台没有任何搜索在跑时才生效，不会改到一半的搜索。
+
+// optionsPollInterval 多久查一次参数文件的修改时间
+const optionsPollInterval = time.Second
+
+// searchGate 参数是无锁读取的包级变量（见 game.ApplyOptions）。每个后台搜索协程
+// （AI 落子、蒙特卡洛提示，含被取消后还在收尾的）从启动到退出都持读锁；
+// 换参数时 TryLock 拿写锁，拿不到就留到下一帧。与 cmd/selfplay 的 optionsGate 同一做法。
+var searchGate sync.RWMutex
+
+// goSearch 在后台协程里跑 fn，全程持 searchGate 读锁。读锁在调用方（界面协程）里拿，
+// 所以协程还没被调度时参数也不会被换掉
+func goSearch(fn func()) {
+	searchGate.RLock()
+	go func() {
+		defer searchGate.RUnlock()
+		fn()
+	}()
+}
+
+type optionsReload struct {
+	path    string
+	watcher *game.OptionsWatcher
+	ch      chan game.Options // 容量 1，只留最新一份
+	errCh   chan error
+}
+
+// startOptionsReload 启动时先加载一次参数文件，再开始监视
+func (gs *GameScreen) startOptionsReload(path string) error {
+	o, err := game.LoadOptions(path)
+	if err != nil {
+		return fmt.Errorf("读取参数文件失败: %w", err)
+	}
+	if err := game.ApplyOptions(o); err != nil {
+		return err
+	}
+	r := &optionsReload{
+		path:  path,
+		ch:    make(chan game.Options, 1),
+		errCh: make(chan error, 1),
+	}
+	r.watcher = game.WatchOptions(path, optionsPollInterval,
+		func(o game.Options) {
+			select {
+			case <-r.ch: // 丢掉还没生效的旧版本
+			default:
+			}
+			r.ch <- o
+		},
+		func(err error) {
+			select {
+			case r.errCh <- err:
+			default:
+			}
+		})
+	gs.options = r
+	return nil
+}
+
+// pollOptions 每帧检查 F5 与重读结果；有搜索在跑就留到下一帧
+func (gs *GameScreen) pollOptions() {
+	r := gs.options
+	if r == nil {
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF5) {
+		r.watcher.Reload()
+	}
+	select {
+	case err := <-r.errCh:
+		log.Printf("参数热加载失败: %v", err)
+		gs.showToast("Options reload failed, see log", toastDuration)
+	default:
+	}
+	if len(r.ch) == 0 || !searchGate.TryLock() {
+		return // 没有新参数，或还有搜索在跑
+	}
+	defer searchGate.Unlock()
+	select {
+	case o := <-r.ch:
+		if err := game.ApplyOptions(o); err != nil {
+			log.Printf("参数热加载失败: %v", err)
+			gs.showToast("Options reload failed, see log", toastDuration)
+			return
+		}
+		log.Printf("已重新加载参数: %s %+v", r.path, o)
+		gs.showToast("Options reloaded: "+filepath.Base(r.path), 3*time.Second)
+	default:
+	}
+}
@@ -0,0 +1,200 @@
+// File /ui/overlay.go
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"hexxagon_go/internal/game"
+)
+
+// 棋盘叠加标注层：评分提示、归属热力图、策略显示、预走箭头等功能只管往 gs.overlay 里放标注
+// （格子填色、文字、两格之间的箭头），坐标换算和绘制统一在这里做，各功能不再自己算格子像素。
+// 标注每帧由 buildOverlay 重建，画在棋子之上、动画之下；同类标注按加入顺序画。
+
+// cellFill 在格子中心叠一个半透明六边形
+type cellFill struct {
+	c    game.HexCoord
+	clr  color.Color // 带 alpha 的颜色（如 color.NRGBA）
+	size float64     // 相对整格的大小，(0,1]
+}
+
+// cellText 在格子里写一行字
+type cellText struct {
+	c   game.HexCoord
+	s   string
+	clr color.Color
+	dy  float64 // 相对格子中心的竖直偏移，以格高为单位（正=向下）
+}
+
+// cellArrow 从一格中心指向另一格中心
+type cellArrow struct {
+	from, to game.HexCoord
+	clr      color.Color
+	width    float64 // 箭杆宽度，以格高为单位
+}
+
+type overlay struct {
+	fills  []cellFill
+	arrows []cellArrow
+	texts  []cellText
+}
+
+// Reset 清空，保留底层数组
+func (o *overlay) Reset() {
+	o.fills, o.arrows, o.texts = o.fills[:0], o.arrows[:0], o.texts[:0]
+}
+
+// Fill 给格子 c 叠一层颜色
+func (o *overlay) Fill(c game.HexCoord, clr color.Color, size float64) {
+	o.fills = append(o.fills, cellFill{c: c, clr: clr, size: size})
+}
+
+// Text 在格子 c 里居中写字，dy 为以格高计的竖直偏移
+func (o *overlay) Text(c game.HexCoord, s string, clr color.Color, dy float64) {
+	o.texts = append(o.texts, cellText{c: c, s: s, clr: clr, dy: dy})
+}
+
+// Arrow 画一支从 from 指向 to 的箭头，width 为以格高计的箭杆宽度
+func (o *overlay) Arrow(from, to game.HexCoord, clr color.Color, width float64) {
+	o.arrows = append(o.arrows, cellArrow{from: from, to: to, clr: clr, width: width})
+}
+
+// 标注层里各功能的配色
+var (
+	ownershipRed   = color.RGBA{0xE0, 0x40, 0x40, 0xFF}
+	ownershipWhite = color.RGBA{0xF0, 0xF0, 0xF0, 0xFF}
+	capturesColor  = color.RGBA{0xE0, 0xC0, 0x40, 0xFF}
+	premoveColor   = color.NRGBA{0x60, 0xC0, 0xFF, 0xC0}
+	hintColor      = color.NRGBA{0x40, 0xE0, 0x40, 0xA0}
+	lastMoveColor  = color.NRGBA{0xFF, 0xE0, 0x60, 0x90}
+	clonesColor    = color.RGBA{0x60, 0xE0, 0xFF, 0xFF}
+	noClonesColor  = color.RGBA{0x90, 0x90, 0x90, 0xFF}
+)
+
+// buildOverlay 按当前界面状态重建标注层
+func (gs *GameScreen) buildOverlay() {
+	o := &gs.overlay
+	o.Reset()
+	if gs.heatmapOn() {
+		// 复盘热力图占满格子，其余提示都不画
+		gs.addHeatmap(o)
+		return
+	}
+	gs.addCloneBadges(o)
+	gs.addLineageTrace(o)
+	if gs.showScores {
+		// 归属图：红 = 预测归 A，白 = 归 B，越不透明越确定
+		own := gs.ui.Ownership
+		for i := 0; i < game.BoardN && own != nil; i++ {
+			c := game.CoordOf[i]
+			idx := game.AxialToIndex(c)
+			if idx < 0 || idx >= len(own) {
+				continue
+			}
+			v := own[idx]
+			a := math.Abs(float64(v)) * 0.6
+			if a < 0.05 {
+				continue
+			}
+			base := ownershipWhite
+			if v > 0 {
+				base = ownershipRed
+			}
+			o.Fill(c, color.NRGBA{base.R, base.G, base.B, uint8(255 * a)}, 0.55)
+		}
+		// 策略评分（百分比），数值越大越亮
+		for to, score := range gs.ui.MoveScores {
+			clr := color.RGBA{0x20, uint8(100 + score*1.55), 0x20, 0xFF}
+			if score < 1.0 {
+				clr = color.RGBA{0x80, 0x80, 0x80, 0xFF} // 极低概率灰色
+			}
+			o.Text(to, fmt.Sprintf("%.1f%%", score), clr, 0)
+		}
+		// 吃子数标在评分下方，没有模型评分时也显示
+		for to, mi := range gs.ui.MoveInfo {
+			if mi.Captures > 0 {
+				o.Text(to, fmt.Sprintf("+%d", mi.Captures), capturesColor, 0.3)
+			}
+		}
+		// 选中棋子时从它指向评分最高的落点
+		if to, ok := bestScored(gs.ui.MoveScores); ok && gs.selected != nil {
+			o.Arrow(*gs.selected, to, hintColor, 0.1)
+		}
+	}
+	gs.addHint(o)
+	// 演示、观战（回放）时标出上一手
+	if gs.spectating() && gs.lastMove != nil {
+		o.Arrow(gs.lastMove.From, gs.lastMove.To, lastMoveColor, 0.07)
+	}
+	if gs.premove != nil {
+		o.Arrow(gs.premove.From, gs.premove.To, premoveColor, 0.08)
+	}
+}
+
+// addCloneBadges 克隆上限规则下在每颗子下方标出它这一脉还能克隆几次，用完的标灰
+func (gs *GameScreen) addCloneBadges(o *overlay) {
+	b := gs.state.Board
+	for i := 0; i < game.BoardN; i++ {
+		c := game.CoordOf[i]
+		n, limited := b.ClonesLeft(c)
+		if !limited {
+			continue
+		}
+		clr := clonesColor
+		if n == 0 {
+			clr = noClonesColor
+		}
+		o.Text(c, fmt.Sprint(n), clr, 0.3)
+	}
+}
+
+// bestScored 评分最高的落点；同分取坐标小的，免得箭头在两格间来回跳
+func bestScored(scores map[game.HexCoord]float64) (game.HexCoord, bool) {
+	var best game.HexCoord
+	found := false
+	for c, s := range scores {
+		if !found || s > scores[best] || s == scores[best] && (c.Q < best.Q || c.Q == best.Q && c.R < best.R) {
+			best, found = c, true
+		}
+	}
+	return best, found
+}
+
+// cellCenter 格子 c 在 offscreen 上的像素中心
+func cellCenter(c game.HexCoord, boardScale, originX, originY, tileW, tileH, vs float64) (float64, float64) {
+	cx := (float64(c.Q)+BoardRadius)*tileW*0.75 + tileW/2
+	cy := (float64(c.R)+BoardRadius+float64(c.Q)/2)*vs + tileH/2
+	return originX + cx*boardScale, originY + cy*boardScale
+}
+
+// draw 把标注画到 dst（offscreen 坐标系）；顺序为 填色 → 箭头 → 文字
+func (o *overlay) draw(dst, tileImg *ebiten.Image) {
+	boardScale, originX, originY, tileW, tileH, vs := getBoardTransform(tileImg)
+	for _, f := range o.fills {
+		mw, mh := int(tileW*f.size), int(tileH*f.size)
+		if mw <= 0 || mh <= 0 {
+			continue
+		}
+		px, py := cellCenter(f.c, boardScale, originX, originY, tileW, tileH, vs)
+		op := &ebiten.DrawImageOptions{}
+		op.Filter = ebiten.FilterLinear
+		op.GeoM.Translate(-float64(mw)/2, -float64(mh)/2)
+		op.GeoM.Scale(boardScale, boardScale)
+		op.GeoM.Translate(px, py)
+		op.ColorScale.ScaleWithColor(f.clr)
+		dst.DrawImage(hexBase(mw, mh, color.White), op)
+	}
+	for _, a := range o.arrows {
+		x0, y0 := cellCenter(a.from, boardScale, originX, originY, tileW, tileH, vs)
+		x1, y1 := cellCenter(a.to, boardScale, originX, originY, tileW, tileH, vs)
+		drawArrow(dst, x0, y0, x1, y1, tileH*boardScale*a.width, a.clr)
+	}
+	for _, t := range o.texts {
+		px, py := cellCenter(t.c, boardScale, originX, originY, tileW, tileH, vs)
+		drawTextCentered(dst, t.s, px, py+t.dy*tileH*boardScale, t.clr)
+	}
+}
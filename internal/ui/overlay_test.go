@@ -0,0 +1,155 @@
+package ui
+
+import (
+	"image/color"
+	"testing"
+	"time"
+
+	"hexxagon_go/internal/game"
+)
+
+func TestBuildOverlayFromTipsAndPremove(t *testing.T) {
+	gs := newTestController(t, pvpConfig()).Screen()
+	to := game.HexCoord{Q: -3, R: 4}
+	gs.showScores = true
+	gs.ui.MoveScores = map[game.HexCoord]float64{to: 42}
+	gs.ui.MoveInfo = map[game.HexCoord]game.MoveInfo{to: {Captures: 2}}
+	gs.ui.Ownership = make([]float32, game.GridSize*game.GridSize)
+	gs.ui.Ownership[game.AxialToIndex(to)] = 0.9
+	gs.premove = &game.Move{From: game.HexCoord{Q: -4, R: 4}, To: to}
+
+	gs.buildOverlay()
+	o := gs.overlay
+	if len(o.fills) != 1 || o.fills[0].c != to {
+		t.Fatalf("归属图应只给确定的那一格填色: %+v", o.fills)
+	}
+	if len(o.texts) != 2 || o.texts[0].s != "42.0%" || o.texts[1].s != "+2" {
+		t.Fatalf("评分与吃子数文字不对: %+v", o.texts)
+	}
+	if len(o.arrows) != 1 || o.arrows[0].from != gs.premove.From || o.arrows[0].to != to {
+		t.Fatalf("预走应画成箭头: %+v", o.arrows)
+	}
+
+	// 关掉提示后只剩箭头，且上一帧的标注不残留
+	gs.showScores = false
+	gs.buildOverlay()
+	if len(gs.overlay.fills)+len(gs.overlay.texts) != 0 || len(gs.overlay.arrows) != 1 {
+		t.Fatalf("重建后应只剩预走箭头: %+v", gs.overlay)
+	}
+}
+
+func TestBuildOverlayHintAndLastMoveArrows(t *testing.T) {
+	gs := newTestController(t, pvpConfig()).Screen()
+	from := game.HexCoord{Q: -4, R: 4}
+	gs.showScores = true
+	gs.selected = &from
+	gs.ui.MoveScores = map[game.HexCoord]float64{{Q: -3, R: 4}: 10, {Q: -4, R: 3}: 60, {Q: -2, R: 4}: 60}
+	gs.lastMove = &game.Move{From: game.HexCoord{Q: 4, R: 0}, To: game.HexCoord{Q: 3, R: 0}}
+
+	gs.buildOverlay()
+	if len(gs.overlay.arrows) != 1 {
+		t.Fatalf("非演示时只应有提示箭头: %+v", gs.overlay.arrows)
+	}
+	// 同分取坐标小的
+	if a := gs.overlay.arrows[0]; a.from != from || a.to != (game.HexCoord{Q: -4, R: 3}) {
+		t.Fatalf("提示箭头应指向评分最高的落点: %+v", a)
+	}
+
+	gs.demo = &demoState{}
+	gs.buildOverlay()
+	if len(gs.overlay.arrows) != 2 || gs.overlay.arrows[1].to != gs.lastMove.To {
+		t.Fatalf("演示时应标出上一手: %+v", gs.overlay.arrows)
+	}
+}
+
+func TestArrowPolygon(t *testing.T) {
+	const w = 4.0
+	pts := arrowPolygon(0, 0, 100, 0, w)
+	if len(pts) != 7 {
+		t.Fatalf("箭头应为七边形: %v", pts)
+	}
+	if tip := pts[0]; tip[0] != 100-arrowTipGap*w || tip[1] != 0 {
+		t.Fatalf("箭尖位置 %v", tip)
+	}
+	// 关于箭杆轴线对称
+	for i := 1; i < 4; i++ {
+		a, b := pts[i], pts[7-i]
+		if a[0] != b[0] || a[1] != -b[1] {
+			t.Fatalf("第 %d 点与第 %d 点不对称: %v %v", i, 7-i, a, b)
+		}
+	}
+	if arrowPolygon(0, 0, 10, 0, w) != nil {
+		t.Fatal("太短的箭头不应画")
+	}
+}
+
+func TestBuildOverlayHeatmapAfterGameOver(t *testing.T) {
+	gs := newTestController(t, pvpConfig()).Screen()
+	hot, warm := game.HexCoord{Q: 0, R: 2}, game.HexCoord{Q: -2, R: 1}
+	gs.state.Flips[game.IndexOf[hot]] = 4
+	gs.state.Flips[game.IndexOf[warm]] = 1
+	gs.showHeatmap = true
+	gs.showScores = true
+	gs.ui.MoveScores = map[game.HexCoord]float64{{Q: -3, R: 4}: 42}
+
+	gs.buildOverlay()
+	if len(gs.overlay.texts) != 1 {
+		t.Fatalf("对局中不画热力图: %+v", gs.overlay.texts)
+	}
+
+	gs.state.GameOver = true
+	gs.buildOverlay()
+	o := gs.overlay
+	if len(o.fills) != 2 || len(o.texts) != 2 {
+		t.Fatalf("终局应只画两格热力: %+v %+v", o.fills, o.texts)
+	}
+	alpha := func(f cellFill) uint8 { return f.clr.(color.NRGBA).A }
+	if o.fills[0].c != warm || o.fills[1].c != hot || alpha(o.fills[0]) >= alpha(o.fills[1]) {
+		t.Fatalf("易手越多应越不透明: %+v", o.fills)
+	}
+	if o.texts[1].s != "4" {
+		t.Fatalf("格子里应写易手次数: %+v", o.texts)
+	}
+
+	gs.showHeatmap = false
+	gs.buildOverlay()
+	if len(gs.overlay.fills) != 0 {
+		t.Fatalf("关掉后不应再画: %+v", gs.overlay.fills)
+	}
+}
+
+func TestCloneBadges(t *testing.T) {
+	t.Cleanup(func() { game.SetRules(game.Rules{}) })
+	cfg := pvpConfig()
+	cfg.Rules = game.Rules{CloneLimit: 1}
+	c := newTestController(t, cfg)
+	gs := c.Screen()
+	gs.buildOverlay()
+	if len(gs.overlay.texts) != 6 || gs.overlay.texts[0].s != "1" {
+		t.Fatalf("开局六颗子都应标上剩 1 次: %+v", gs.overlay.texts)
+	}
+
+	mv := game.Move{From: game.HexCoord{Q: 4, R: 0}, To: game.HexCoord{Q: 3, R: 0}}
+	if err := c.Move(mv); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Settle(10 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	gs.buildOverlay()
+	grey := 0
+	for _, tx := range gs.overlay.texts {
+		if tx.s == "0" && tx.clr == noClonesColor {
+			grey++
+		}
+	}
+	if len(gs.overlay.texts) != 7 || grey != 2 {
+		t.Fatalf("克隆后母子和新子应标灰的 0: %+v", gs.overlay.texts)
+	}
+
+	game.SetRules(game.Rules{})
+	gs.buildOverlay()
+	if len(gs.overlay.texts) != 0 {
+		t.Fatalf("标准规则下不画次数: %+v", gs.overlay.texts)
+	}
+}
@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Pacing 控制人机对战中 AI 的“思考”节奏：搜索再快也至少展示一段随机的思考时间，
+// 只有一步可走时可以立即应着，避免机械感。
+type Pacing struct {
+	MinThink      time.Duration // 最短思考展示时间
+	MaxThink      time.Duration // 在 [MinThink, MaxThink] 内均匀随机；<MinThink 视为固定 MinThink
+	InstantForced bool          // 只有一步合法着时不搜索、不等待
+}
+
+var DefaultPacing = Pacing{
+	MinThink:      time.Second,
+	MaxThink:      2 * time.Second,
+	InstantForced: true,
+}
+
+// thinkTime 本回合的最短思考展示时间；搜索本身更慢时以搜索为准
+func (p Pacing) thinkTime() time.Duration {
+	if p.MaxThink <= p.MinThink {
+		return p.MinThink
+	}
+	return p.MinThink + time.Duration(rand.Int63n(int64(p.MaxThink-p.MinThink)+1))
+}
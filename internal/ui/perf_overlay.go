@@ -0,0 +1,206 @@
+// File /ui/perf_overlay.go
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"runtime"
+	"runtime/metrics"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+
+	"hexxagon_go/internal/game"
+)
+
+// 性能浮层（F3 开关）：最近几秒每帧的帧间隔、Update/Draw 各自耗时、动画数和 GC 停顿，
+// 用来定位“大量动画 + AI 推理同时进行时卡一下”这类问题到底卡在哪。
+// 计时用真实时钟（不受 Controller 的虚拟时钟影响）；关闭时每帧只多一次按键检查。
+// 进程内搜索时第一行末尾附带置换表大小和占用率（game.HashFull），用来判断 -hash 够不够大。
+
+const (
+	perfSamples  = 300 // 60 FPS 下约 5 秒
+	perfGraphH   = 80  // 图高（像素）
+	perfMsPerPix = 0.5 // 纵轴刻度：每像素 0.5ms，满高 40ms
+	perfBoxW     = 640 // 背景宽度，容下两行文字
+)
+
+type perfSample struct {
+	frame, update, draw time.Duration
+	anims               int
+	gcPause             time.Duration // 这一帧内结束的 GC 的 STW 停顿（没有则为 0）
+}
+
+type perfOverlay struct {
+	on      bool
+	samples [perfSamples]perfSample
+	head, n int
+
+	lastDraw   time.Time
+	updateAcc  time.Duration // 上次 Draw 以来 Update 的累计耗时
+	drawStart  time.Time
+	gcSeen     uint64
+	gcMetric   []metrics.Sample
+	lastPause  time.Duration
+	totalPause time.Duration
+	showTT     bool // 进程内搜索（没用外部引擎）时才显示置换表占用率
+}
+
+func (p *perfOverlay) toggle() {
+	p.on = !p.on
+	p.head, p.n, p.updateAcc = 0, 0, 0
+	p.lastDraw = time.Time{}
+	if p.gcMetric == nil {
+		p.gcMetric = []metrics.Sample{{Name: "/gc/cycles/total:gc-cycles"}}
+	}
+	p.gcSeen = p.gcCycles()
+}
+
+// pollPerfKey 每帧检查开关；开启时返回本次 Update 的收尾函数
+func (gs *GameScreen) pollPerfKey() func() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyF3) {
+		gs.perf.toggle()
+	}
+	if !gs.perf.on {
+		return func() {}
+	}
+	start := time.Now()
+	return func() { gs.perf.updateAcc += time.Since(start) }
+}
+
+func (p *perfOverlay) beginDraw() {
+	if p.on {
+		p.drawStart = time.Now()
+	}
+}
+
+// endDraw 在 Draw 末尾记一帧
+func (p *perfOverlay) endDraw(anims int) {
+	if !p.on {
+		return
+	}
+	now := time.Now()
+	s := perfSample{update: p.updateAcc, draw: now.Sub(p.drawStart), anims: anims}
+	if !p.lastDraw.IsZero() {
+		s.frame = now.Sub(p.lastDraw)
+	}
+	p.lastDraw = now
+	p.updateAcc = 0
+
+	// GC 周期数用 runtime/metrics 读，几乎无开销；只有确实跑过 GC 才 ReadMemStats 取停顿（它本身要 STW）
+	if c := p.gcCycles(); c != p.gcSeen {
+		var ms runtime.MemStats
+		runtime.ReadMemStats(&ms)
+		for i := p.gcSeen + 1; i <= uint64(ms.NumGC) && i <= p.gcSeen+256; i++ {
+			s.gcPause += time.Duration(ms.PauseNs[(i+255)%256])
+		}
+		p.gcSeen = c
+		p.lastPause = s.gcPause
+		p.totalPause += s.gcPause
+	}
+
+	p.samples[p.head] = s
+	p.head = (p.head + 1) % perfSamples
+	if p.n < perfSamples {
+		p.n++
+	}
+}
+
+func (p *perfOverlay) gcCycles() uint64 {
+	metrics.Read(p.gcMetric)
+	if p.gcMetric[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return p.gcMetric[0].Value.Uint64()
+}
+
+// at 第 i 个样本（0 = 最旧）
+func (p *perfOverlay) at(i int) perfSample {
+	return p.samples[(p.head-p.n+i+perfSamples)%perfSamples]
+}
+
+func perfBarH(d time.Duration) float64 {
+	h := float64(d.Microseconds()) / 1000 / perfMsPerPix
+	if h > perfGraphH {
+		h = perfGraphH
+	}
+	return h
+}
+
+func fillRect(dst *ebiten.Image, x, y, w, h float64, clr color.Color) {
+	if toastPixel == nil {
+		toastPixel = ebiten.NewImage(1, 1)
+		toastPixel.Fill(color.White)
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(w, h)
+	op.GeoM.Translate(x, y)
+	op.ColorScale.ScaleWithColor(clr)
+	dst.DrawImage(toastPixel, op)
+}
+
+// draw 左下角画图：每帧一列，底部蓝=Update、其上绿=Draw、灰=其余（等待垂直同步等），
+// GC 停顿在顶部标红；黄线为 16.7ms
+func (p *perfOverlay) draw(dst *ebiten.Image, face font.Face) {
+	if !p.on {
+		return
+	}
+	h := float64(dst.Bounds().Dy())
+	x0, base := 10.0, h-30
+	fillRect(dst, x0-4, base-perfGraphH-40, perfBoxW, perfGraphH+48, color.RGBA{0, 0, 0, 0xB0})
+
+	var sumFrame, maxFrame, sumUpd, sumDraw time.Duration
+	frames, maxAnims := 0, 0
+	for i := 0; i < p.n; i++ {
+		s := p.at(i)
+		x := x0 + float64(i)
+		total := perfBarH(s.frame)
+		upd := perfBarH(s.update)
+		drw := perfBarH(s.draw)
+		fillRect(dst, x, base-total, 1, total, color.RGBA{0x60, 0x60, 0x60, 0xFF})
+		fillRect(dst, x, base-upd-drw, 1, drw, color.RGBA{0x40, 0xC0, 0x40, 0xFF})
+		fillRect(dst, x, base-upd, 1, upd, color.RGBA{0x40, 0x80, 0xFF, 0xFF})
+		if s.gcPause > 0 {
+			fillRect(dst, x, base-perfGraphH, 1, 4, color.RGBA{0xFF, 0x30, 0x30, 0xFF})
+		}
+		if s.frame > 0 {
+			sumFrame += s.frame
+			frames++
+			if s.frame > maxFrame {
+				maxFrame = s.frame
+			}
+		}
+		sumUpd += s.update
+		sumDraw += s.draw
+		if s.anims > maxAnims {
+			maxAnims = s.anims
+		}
+	}
+	vsync := base - perfBarH(time.Second/60)
+	fillRect(dst, x0, vsync, perfSamples, 1, color.RGBA{0xFF, 0xE0, 0x40, 0xC0})
+
+	if p.n == 0 {
+		return
+	}
+	avg := func(d time.Duration, n int) float64 {
+		if n == 0 {
+			return 0
+		}
+		return float64(d.Microseconds()) / 1000 / float64(n)
+	}
+	last := p.at(p.n - 1)
+	line1 := fmt.Sprintf("frame avg %.1fms max %.1fms  fps %.0f tps %.0f",
+		avg(sumFrame, frames), float64(maxFrame.Microseconds())/1000, ebiten.ActualFPS(), ebiten.ActualTPS())
+	if p.showTT {
+		line1 += fmt.Sprintf("  tt %dMB hashfull %.1f%%", game.TTSizeMB(), float64(game.HashFull())/10)
+	}
+	line2 := fmt.Sprintf("update %.2fms draw %.2fms  anims %d (max %d)  gc last %s total %s",
+		avg(sumUpd, p.n), avg(sumDraw, p.n), last.anims, maxAnims,
+		p.lastPause.Round(time.Microsecond), p.totalPause.Round(time.Microsecond))
+	white := color.RGBA{0xE0, 0xE0, 0xE0, 0xFF}
+	text.Draw(dst, line1, face, int(x0), int(base-perfGraphH-24), white)
+	text.Draw(dst, line2, face, int(x0), int(base-perfGraphH-10), white)
+}
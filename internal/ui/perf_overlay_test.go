@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestPerfOverlayRing(t *testing.T) {
+	var p perfOverlay
+	p.endDraw(1)
+	if p.n != 0 {
+		t.Fatal("关闭时不应记录")
+	}
+	p.toggle()
+	for i := 0; i < perfSamples+25; i++ {
+		p.beginDraw()
+		p.endDraw(i)
+	}
+	if p.n != perfSamples {
+		t.Fatalf("样本数应封顶 %d，实际 %d", perfSamples, p.n)
+	}
+	if first, last := p.at(0).anims, p.at(p.n-1).anims; first != 25 || last != perfSamples+24 {
+		t.Fatalf("环形顺序错误: first=%d last=%d", first, last)
+	}
+}
+
+func TestPerfOverlayCountsGC(t *testing.T) {
+	var p perfOverlay
+	p.toggle()
+	runtime.GC()
+	p.beginDraw()
+	p.endDraw(0)
+	if p.at(p.n-1).gcPause <= 0 || p.totalPause <= 0 {
+		t.Fatalf("手动 GC 后应记到停顿: %+v", p.at(p.n-1))
+	}
+}
@@ -5,24 +5,40 @@ import "github.com/hajimehoshi/ebiten/v2"
 var (
 	perfOn = true // 默认以高刷新启动，保证首帧流程正常
 	booted bool   // 首帧是否已经进入稳定状态
+	idleOn bool   // 闲置节流中（见 idle.go），期间 perfOn 只记账，回到前台再生效
 )
 
+const idleTPS = 5 // 闲置时每秒 Update 次数：焦点回来最多晚 200ms 察觉
+
 func enterPerf() {
 	if perfOn {
 		return
 	}
-	ebiten.SetFPSMode(ebiten.FPSModeVsyncOn)
-	ebiten.SetTPS(10)
 	perfOn = true
+	applyPerf()
 }
 
 func leavePerf(force bool) {
 	if !perfOn && !force {
 		return
 	}
-	ebiten.SetFPSMode(ebiten.FPSModeVsyncOffMinimum)
-	ebiten.SetTPS(60)
 	perfOn = false
+	applyPerf()
+}
+
+// applyPerf 按 idleOn / perfOn 设置帧率
+func applyPerf() {
+	switch {
+	case idleOn:
+		ebiten.SetFPSMode(ebiten.FPSModeVsyncOffMinimum)
+		ebiten.SetTPS(idleTPS)
+	case perfOn:
+		ebiten.SetFPSMode(ebiten.FPSModeVsyncOn)
+		ebiten.SetTPS(10)
+	default:
+		ebiten.SetFPSMode(ebiten.FPSModeVsyncOffMinimum)
+		ebiten.SetTPS(60)
+	}
 }
 
 func ensurePerf(active bool) {
@@ -0,0 +1,73 @@
+// File /ui/premove.go
+package ui
+
+import (
+	"fmt"
+
+	"hexxagon_go/internal/game"
+)
+
+// 预走：对手（AI 或另一位玩家）的落子动画还没提交时，接下来要走的人类玩家就可以先选子、定好下一步，
+// 不必干等动画放完。选子和校验都按“提交后”的局面算；定好的着法在提交完成的同一帧落下。
+// 预走期间再点一次棋盘即取消/改选。
+
+// inputState 人类此刻可操作的局面。
+// 没有待提交落子时就是当前局面；有时返回提交后的局面副本（pre=true）。
+// 轮到 AI、已终局或自己的落子还在播（下一手归对手）时 ok=false，点击一律忽略
+func (gs *GameScreen) inputState() (st *game.GameState, pre, ok bool) {
+	if gs.pendingCommit == nil {
+		return gs.state, false, !gs.state.GameOver && !gs.isAITurn()
+	}
+	next := *gs.state
+	next.Board = gs.state.Board.Clone()
+	if _, _, err := next.MakeMove(gs.pendingCommit.move); err != nil {
+		return nil, true, false
+	}
+	if next.GameOver || gs.aiControls(next.CurrentPlayer) || next.CurrentPlayer == gs.pendingCommit.player {
+		return nil, true, false
+	}
+	return &next, true, true
+}
+
+// queuePremove 记下预走着法；mv 须是 st（提交后的局面）里的合法着
+func (gs *GameScreen) queuePremove(st *game.GameState, mv game.Move) error {
+	if !legalMove(st.Board, st.CurrentPlayer, mv) {
+		return fmt.Errorf("ui: illegal premove %v for %s", mv, sideName(st.CurrentPlayer))
+	}
+	gs.premove = &mv
+	from := mv.From
+	gs.selected = &from // 选中框留在起点，提示这一步已排队
+	return nil
+}
+
+// applyPremove 待提交落子刚落盘后调用：局面仍允许就立即走出排队的着法，否则丢弃
+func (gs *GameScreen) applyPremove() {
+	mv := gs.premove
+	if mv == nil {
+		return
+	}
+	gs.premove = nil
+	gs.selected = nil
+	player := gs.state.CurrentPlayer
+	if gs.state.GameOver || gs.isAITurn() || !legalMove(gs.state.Board, player, *mv) {
+		gs.audioManager.Play("cancel_select_piece")
+		return
+	}
+	if total, err := gs.performMove(*mv, player); err == nil {
+		gs.aiDelayUntil = gs.now().Add(total)
+	}
+}
+
+func premoveText(mv *game.Move) string {
+	return fmt.Sprintf("Next move queued: (%d,%d)->(%d,%d), click to change",
+		mv.From.Q, mv.From.R, mv.To.Q, mv.To.R)
+}
+
+func legalMove(b *game.Board, side game.CellState, mv game.Move) bool {
+	for _, m := range game.GenerateMoves(b, side) {
+		if m == mv {
+			return true
+		}
+	}
+	return false
+}
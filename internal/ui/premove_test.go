@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"hexxagon_go/internal/game"
+)
+
+// stepUntil 推进到 cond 成立；AI 在后台搜索时等真实时间
+func stepUntil(t *testing.T, c *Controller, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(30 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待超时: %s", c.describe())
+		}
+		if c.gs.aiRunning {
+			time.Sleep(time.Millisecond)
+			if err := c.gs.Update(); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := c.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// afterPending 待提交落子落盘后的局面
+func afterPending(t *testing.T, gs *GameScreen) *game.GameState {
+	t.Helper()
+	st, pre, ok := gs.inputState()
+	if !pre || !ok {
+		t.Fatalf("应处于可预走状态: pre=%v ok=%v", pre, ok)
+	}
+	return st
+}
+
+func TestPremoveAppliedWhenAICommits(t *testing.T) {
+	cfg := DefaultGameConfig()
+	cfg.Evaluator = EvalStatic
+	cfg.Bot = game.BotGreedy
+	cfg.Pacing = Pacing{InstantForced: true}
+	c := newTestController(t, cfg)
+	gs := c.Screen()
+
+	if err := c.Move(firstMove(gs.state)); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := gs.inputState(); ok {
+		t.Fatal("自己的落子还在播、下一手归 AI 时不应接受点击")
+	}
+	stepUntil(t, c, func() bool { return gs.pendingCommit != nil && gs.pendingCommit.player == game.PlayerB })
+
+	mv := firstMove(afterPending(t, gs))
+	if err := c.Premove(mv); err != nil {
+		t.Fatal(err)
+	}
+	if v := c.View(); v.Premove == nil || *v.Premove != mv || v.ToMove != game.PlayerB {
+		t.Fatalf("AI 提交前只应排队: %+v", v.Premove)
+	}
+
+	stepUntil(t, c, func() bool { return gs.pendingCommit == nil || gs.pendingCommit.player == game.PlayerA })
+	if gs.pendingCommit == nil || gs.pendingCommit.move != mv {
+		t.Fatal("AI 落盘的同一帧应走出预走着法")
+	}
+	if gs.premove != nil || gs.selected != nil {
+		t.Fatal("预走走出后应清空排队和选中")
+	}
+	if err := c.Settle(10 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPremoveRejectsIllegalMove(t *testing.T) {
+	c := newTestController(t, pvpConfig())
+	gs := c.Screen()
+	if err := c.Premove(firstMove(gs.state)); err == nil {
+		t.Fatal("没有待提交落子时不应接受预走")
+	}
+	if err := c.Move(firstMove(gs.state)); err != nil {
+		t.Fatal(err)
+	}
+	// 红方刚走，白方预走红方的着法
+	if err := c.Premove(firstMove(gs.state)); err == nil {
+		t.Fatal("预走须是提交后轮到一方的合法着")
+	}
+	mv := firstMove(afterPending(t, gs))
+	if err := c.Premove(mv); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Settle(10 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if v := c.View(); v.Board.Cells[game.IndexOf[mv.To]] != game.PlayerB || v.ToMove != game.PlayerA {
+		t.Fatalf("白方预走应在红方提交后走出: toMove=%v", v.ToMove)
+	}
+}
@@ -32,8 +32,6 @@ func Fragment(pos vec4, uv vec2, col vec4) vec4 {
 
 var gradShader *ebiten.Shader
 
-var TipSearchDepth = 1 // 默认为 1
-
 func init() {
 	s, err := ebiten.NewShader([]byte(gradKage))
 	if err != nil {
@@ -42,12 +40,17 @@ func init() {
 	gradShader = s
 }
 
-// 放到文件顶部做简单缓存
-var hexBaseCache = map[[2]int]*ebiten.Image{}
+// 放到文件顶部做简单缓存；同尺寸不同颜色（整格底色与叠加标注）各缓存一份
+type hexBaseKey struct {
+	w, h int
+	fill color.RGBA
+}
+
+var hexBaseCache = map[hexBaseKey]*ebiten.Image{}
 
 // 生成一个与 tile 同尺寸的实心六边形底色
 func hexBase(w, h int, fill color.Color) *ebiten.Image {
-	key := [2]int{w, h}
+	key := hexBaseKey{w, h, color.RGBAModel.Convert(fill).(color.RGBA)}
 	if img := hexBaseCache[key]; img != nil {
 		return img
 	}
@@ -150,17 +153,11 @@ func (gs *GameScreen) bakeBoardBase() {
 	img := ebiten.NewImage(w, h) // 临时层：先画底色+紫环
 	img.Clear()
 
-	// 复用你原来的坐标计算
+	// 复用你原来的坐标计算；换了局面（可能换了半径）才会重新烘焙，顺便更新画面放大到的半径
+	viewRadius = max(game.ActiveRadius(gs.state.Board), 1)
 	tileW := gs.tileImage.Bounds().Dx()
 	tileH := gs.tileImage.Bounds().Dy()
-	vs := float64(tileH) * math.Sqrt(3) / 2
-	cols := 2*BoardRadius + 1
-	rows := 2*BoardRadius + 1
-	boardW := float64(cols-1)*float64(tileW)*0.75 + float64(tileW)
-	boardH := vs*float64(rows-1) + float64(tileH)
-	scale := math.Min(float64(WindowWidth)/boardW, float64(WindowHeight)/boardH)
-	originX := (float64(WindowWidth) - boardW*scale) / 2
-	originY := (float64(WindowHeight) - boardH*scale) / 2
+	scale, originX, originY, _, _, vs := getBoardTransform(gs.tileImage)
 
 	base := hexBase(tileW, tileH, color.RGBA{49, 83, 127, 0xFF})
 	hintSY := 0.9
@@ -381,11 +378,21 @@ func (gs *GameScreen) refreshMoveScores() {
 	for k := range gs.ui.MoveScores {
 		delete(gs.ui.MoveScores, k)
 	}
+	gs.refreshMoveInfo()
 
 	// 1) 计算全局胜率 (始终转为玩家 A 视角)
 	winProb, err := game.KataWinProb(gs.state.Board, game.PlayerA)
-	if err == nil {
-		gs.ui.WinProbA = float64(winProb)
+	if err != nil {
+		// 没有 ONNX：胜率与落点提示都改用后台蒙特卡洛估计
+		gs.ui.Ownership = nil
+		gs.startMCTips(gs.selected)
+		return
+	}
+	gs.ui.WinProbA = float64(winProb)
+	// 模型带 ownership 头时顺带取归属图，Draw 里画成底色
+	gs.ui.Ownership = nil
+	if own, err := game.KataOwnership(gs.state.Board, game.PlayerA); err == nil {
+		gs.ui.Ownership = own
 	}
 
 	if gs.selected == nil {
@@ -409,6 +416,21 @@ func (gs *GameScreen) refreshMoveScores() {
 	}
 }
 
+// refreshMoveInfo 选中起点时记下各终点的吃子数，Draw 里标在评分下方
+func (gs *GameScreen) refreshMoveInfo() {
+	gs.ui.MoveInfo = nil
+	if gs.selected == nil {
+		return
+	}
+	player := gs.state.CurrentPlayer
+	gs.ui.MoveInfo = make(map[game.HexCoord]game.MoveInfo)
+	for _, mv := range game.GenerateMoves(gs.state.Board, player) {
+		if mv.From == *gs.selected {
+			gs.ui.MoveInfo[mv.To] = game.DescribeMove(gs.state.Board, player, mv)
+		}
+	}
+}
+
 // 居中绘制文本（用 basicfont）
 // x, y 传入“目标中心点”的屏幕坐标
 func drawTextCentered(dst *ebiten.Image, s string, x, y float64, col color.Color) {
@@ -0,0 +1,441 @@
+// File /ui/replay.go
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"hexxagon_go/internal/game"
+	"hexxagon_go/internal/migrate"
+)
+
+// 对局录像与回放。
+//
+// 录像：-record 指定文件后，人机、人人、联机对局每结束一盘就追加一条 ReplayMatch；
+// 文件是 ReplayMatch 的 JSON 数组，与 cmd/hexxagon/replay 读的格式相同。演示与回放本身不录。
+// 开局和每手走完后的局面都用 game.PositionString 的紧凑编码（一个局面 24 个字符）。
+// 每手带一句解说（game.CommentMove），给别的工具看；回放时解说栏按着法重新生成，不读这一项。
+// 每盘带版本号（ReplayVersion），老格式读入时按 replayFormat 升级，见 internal/migrate。
+//
+// 回放：-mode replay -replay 文件，逐手播放（和对局一样的动画），每手之间停 ReplayDelay。
+//
+//	Space        暂停 / 继续
+//	Left/Right   后退 / 前进一手（并暂停）
+//	Home/End     跳到本盘开头 / 结尾
+//	PgUp/PgDn    上一盘 / 下一盘
+//	Up/Down      加速 / 减速
+//	鼠标左键     点一颗子追踪它的克隆谱系，见 trace.go
+//	鼠标滚轮     翻看解说栏，见 commentary.go
+
+// 回放间隔的调节范围，Up/Down 每次减半 / 加倍
+const (
+	DefaultReplayDelay = 600 * time.Millisecond
+	minReplayDelay     = 50 * time.Millisecond
+	maxReplayDelay     = 5 * time.Second
+)
+
+type ReplayStep struct {
+	Move     game.Move `json:"move"`
+	Comment  string    `json:"comment,omitempty"`  // 录像时生成的解说；老文件没有
+	Position string    `json:"position,omitempty"` // 走完这手后的局面（PositionString），载入时核对；老文件没有
+}
+
+// ReplayVersion 录像每盘的格式版本：
+//
+//	1  加版本号之前的录像：没有 version；解说、逐手局面可能没有（开局、规则也可能没有，空=标准开局/原版规则）
+//	2  每手都有解说和走完后的局面
+const ReplayVersion = 2
+
+// replayFormat 第 1 版升第 2 版时按着法重放，补上缺的解说和局面
+var replayFormat = migrate.NewFormat("replay", ReplayVersion, 1).Register(1, fillReplaySteps)
+
+type ReplayMatch struct {
+	Version int          `json:"version"`
+	Winner  string       `json:"winner"` // "red"、"white" 或 "draw"
+	Steps   []ReplayStep `json:"steps"`
+	Start   string       `json:"start,omitempty"` // 开局的 PositionString；空=标准开局（老文件没有这一项）
+	Rules   string       `json:"rules,omitempty"` // 规则变体；空=standard
+}
+
+func (m ReplayMatch) moves() []game.Move {
+	mv := make([]game.Move, len(m.Steps))
+	for i, st := range m.Steps {
+		mv[i] = st.Move
+	}
+	return mv
+}
+
+// walk 从开局逐手走前 n 手，每手走之前把局面交给 fn；规则由调用方先按本盘设好（见 replayTo）
+func (m ReplayMatch) walk(n int, fn func(b *game.Board, side game.CellState, mv game.Move)) error {
+	st, err := m.startState()
+	if err != nil {
+		return err
+	}
+	if st == nil {
+		st = game.NewGameState(game.DefaultBoardRadius)
+	}
+	for i, mv := range m.moves()[:n] {
+		fn(st.Board, st.CurrentPlayer, mv)
+		if _, _, err := st.MakeMove(mv); err != nil {
+			return fmt.Errorf("move %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// commentary 前 n 手的解说，与对局时边下边记的一样
+func (m ReplayMatch) commentary(n int) (commentLog, error) {
+	var l commentLog
+	err := m.walk(n, func(b *game.Board, side game.CellState, mv game.Move) {
+		l.addMove(game.CommentMove(b, side, mv))
+	})
+	return l, err
+}
+
+// startState 本盘开局；nil 表示标准开局，交给 game.ReplayMoves
+func (m ReplayMatch) startState() (*game.GameState, error) {
+	if m.Start == "" {
+		return nil, nil
+	}
+	return game.ParsePosition(m.Start)
+}
+
+// replayTo 按本盘规则从开局重放前 n 手
+func (m ReplayMatch) replayTo(n int) (*game.GameState, error) {
+	rules, err := game.ParseRules(m.Rules)
+	if err != nil {
+		return nil, err
+	}
+	start, err := m.startState()
+	if err != nil {
+		return nil, err
+	}
+	game.SetRules(rules)
+	return game.ReplayMoves(start, m.moves()[:n])
+}
+
+// verify 按本盘规则重放全部着法；带了局面的手，重放出来的局面须与录像一致
+func (m ReplayMatch) verify() error {
+	if _, err := m.replayTo(len(m.Steps)); err != nil {
+		return err
+	}
+	start, err := m.startState()
+	if err != nil {
+		return err
+	}
+	st, _ := game.ReplayMoves(start, nil)
+	for i, step := range m.Steps {
+		if _, _, err := st.MakeMove(step.Move); err != nil {
+			return fmt.Errorf("move %d: %w", i+1, err)
+		}
+		if step.Position != "" && step.Position != st.PositionString() {
+			return fmt.Errorf("move %d: replayed position %s, record has %s", i+1, st.PositionString(), step.Position)
+		}
+	}
+	return nil
+}
+
+// LoadReplays 读入录像文件并逐盘校验：非法走法、与录像对不上的局面直接报出第几盘第几手，
+// 而不是播放到一半画出错乱的局面
+func LoadReplays(path string) ([]ReplayMatch, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer game.SetRules(game.CurrentRules()) // verify 也按各盘规则重放
+	matches, err := decodeReplays(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%s: no matches", path)
+	}
+	for i, m := range matches {
+		if err := m.verify(); err != nil {
+			return nil, fmt.Errorf("%s: match %d: %w", path, i+1, err)
+		}
+	}
+	return matches, nil
+}
+
+// decodeReplays 解析录像数组，老版本的盘逐盘升到 ReplayVersion。
+// 升级要按各盘规则重放，会临时切换全局规则，返回前（含出错时）恢复原样
+func decodeReplays(data []byte) ([]ReplayMatch, error) {
+	defer game.SetRules(game.CurrentRules())
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	matches := make([]ReplayMatch, len(raw))
+	for i, r := range raw {
+		if _, err := replayFormat.Decode(r, &matches[i]); err != nil {
+			return nil, fmt.Errorf("match %d: %w", i+1, err)
+		}
+	}
+	return matches, nil
+}
+
+// fillReplaySteps 第 1 版 → 第 2 版：按本盘规则从开局重放，补上缺的解说与局面（已有的不动，后面 verify 照样核对）
+func fillReplaySteps(d migrate.Doc) error {
+	raw, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	var m ReplayMatch
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return err
+	}
+	st, err := m.replayTo(0)
+	if err != nil {
+		return err
+	}
+	for i := range m.Steps {
+		step := &m.Steps[i]
+		if step.Comment == "" {
+			step.Comment = game.CommentMove(st.Board, st.CurrentPlayer, step.Move)
+		}
+		if _, _, err := st.MakeMove(step.Move); err != nil {
+			return fmt.Errorf("move %d: %w", i+1, err)
+		}
+		if step.Position == "" {
+			step.Position = st.PositionString()
+		}
+	}
+	d["steps"] = m.Steps
+	return nil
+}
+
+// appendReplay 把 m 追加到 path 的数组末尾（文件不存在就新建），返回追加后的盘数；
+// 文件里的老版本录像顺带升到当前版本
+func appendReplay(path string, m ReplayMatch) (int, error) {
+	var matches []ReplayMatch
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return 0, err
+	default:
+		if matches, err = decodeReplays(data); err != nil {
+			return 0, fmt.Errorf("%s: %w（不覆盖已有内容）", path, err)
+		}
+	}
+	matches = append(matches, m)
+	out, err := json.MarshalIndent(matches, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(out, '\n'), 0644); err != nil {
+		return 0, err
+	}
+	return len(matches), os.Rename(tmp, path)
+}
+
+// replayRecorder 记录当前这一盘，终局时写一次
+type replayRecorder struct {
+	path  string
+	start string // 开局 PositionString
+	rules game.Rules
+	steps []ReplayStep
+	saved bool
+}
+
+// begin 换了新局面（开局、演示退出、联机接入）时重新开始记
+func (r *replayRecorder) begin(st *game.GameState) {
+	if r == nil {
+		return
+	}
+	r.start = st.PositionString()
+	r.rules = game.CurrentRules()
+	r.steps = r.steps[:0]
+	r.saved = false
+}
+
+// recordMove 提交成功的一手及其解说；gs.state 已经走完这一手
+func (gs *GameScreen) recordMove(mv game.Move, comment string) {
+	gs.history.moves = append(gs.history.moves, mv)
+	if r := gs.recorder; r != nil && gs.demo == nil {
+		r.steps = append(r.steps, ReplayStep{Move: mv, Comment: comment, Position: gs.state.PositionString()})
+	}
+}
+
+// saveRecord 终局时调用，每盘只写一次；演示模式的对局不录
+func (gs *GameScreen) saveRecord() {
+	r := gs.recorder
+	if r == nil || r.saved || gs.demo != nil || len(r.steps) == 0 {
+		return
+	}
+	r.saved = true
+	m := ReplayMatch{Version: ReplayVersion, Winner: replayWinner(gs.state.Winner), Start: r.start, Steps: append([]ReplayStep(nil), r.steps...)}
+	if !r.rules.IsStandard() {
+		m.Rules = r.rules.String()
+	}
+	n, err := appendReplay(r.path, m)
+	if err != nil {
+		log.Printf("录像保存失败: %v", err)
+		gs.showToast("Failed to save replay: "+err.Error(), toastDuration)
+		return
+	}
+	log.Printf("录像已保存: %s 第 %d 盘，%d 手", r.path, n, len(m.Steps))
+	gs.showToast(fmt.Sprintf("Replay saved to %s (match %d)", r.path, n), 4*time.Second)
+}
+
+func replayWinner(w game.CellState) string {
+	if w == game.Empty {
+		return "draw"
+	}
+	return sideName(w)
+}
+
+// startReplay 进入回放模式，从第一盘开头自动播放
+func (gs *GameScreen) startReplay(matches []ReplayMatch, delay time.Duration) error {
+	gs.replayMatches = matches
+	gs.replayDelay = delay
+	gs.mode = "replay"
+	gs.aiEnabled = false
+	gs.replayPlaying = true
+	return gs.seekReplay(0, 0)
+}
+
+// seekReplay 跳到第 mi 盘走完 si 手后的局面（不播动画）；越界的取到边上
+func (gs *GameScreen) seekReplay(mi, si int) error {
+	mi = max(0, min(mi, len(gs.replayMatches)-1))
+	m := gs.replayMatches[mi]
+	si = max(0, min(si, len(m.Steps)))
+	st, err := m.replayTo(si)
+	if err != nil {
+		return fmt.Errorf("match %d: %w", mi+1, err)
+	}
+	tree, err := m.pieceTree(si)
+	if err != nil {
+		return fmt.Errorf("match %d: %w", mi+1, err)
+	}
+	comments, err := m.commentary(si)
+	if err != nil {
+		return fmt.Errorf("match %d: %w", mi+1, err)
+	}
+	if st.GameOver {
+		comments.addEnd(gameOverText(st))
+	}
+	gs.resetGame(st)
+	gs.pieceTree = tree
+	gs.comments = comments
+	if mi != gs.replayMi {
+		gs.tracePiece = 0 // 编号只在同一盘里有意义
+	}
+	gs.replayMi, gs.replaySi = mi, si
+	if si > 0 {
+		last := m.Steps[si-1].Move
+		gs.lastMove = &last
+	}
+	gs.lastAdvance = gs.now()
+	return nil
+}
+
+// replayIdle 上一手已经播完并提交
+func (gs *GameScreen) replayIdle() bool {
+	return gs.pendingCommit == nil && !gs.isAnimating
+}
+
+// replayForward 带动画走下一手；上一手还在播就先跳到它播完的局面
+func (gs *GameScreen) replayForward(now time.Time) {
+	steps := gs.replayMatches[gs.replayMi].Steps
+	if !gs.replayIdle() {
+		gs.seekReplayKey(gs.replayMi, gs.replaySi)
+	}
+	if gs.replaySi >= len(steps) {
+		return
+	}
+	total, err := gs.performMove(steps[gs.replaySi].Move, gs.state.CurrentPlayer)
+	if err != nil {
+		log.Printf("回放: 第 %d 盘第 %d 手: %v", gs.replayMi+1, gs.replaySi+1, err)
+		gs.replayPlaying = false
+		return
+	}
+	gs.replaySi++
+	gs.lastAdvance = now.Add(total) // 间隔从动画放完算起
+}
+
+func (gs *GameScreen) seekReplayKey(mi, si int) {
+	if err := gs.seekReplay(mi, si); err != nil {
+		log.Printf("回放: %v", err)
+	}
+}
+
+// updateReplay 回放模式下每帧处理按键和自动播放；返回 true 表示本帧已处理完。
+// 落子的提交、动画清理仍走 Update 的常规流程
+func (gs *GameScreen) updateReplay(now time.Time) bool {
+	if gs.mode != "replay" {
+		return false
+	}
+	mi, si := gs.replayMi, gs.replaySi
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeySpace):
+		gs.replayPlaying = !gs.replayPlaying
+		gs.lastAdvance = now
+	case inpututil.IsKeyJustPressed(ebiten.KeyRight):
+		gs.replayPlaying = false
+		gs.replayForward(now)
+		return true
+	case inpututil.IsKeyJustPressed(ebiten.KeyLeft):
+		gs.replayPlaying = false
+		gs.seekReplayKey(mi, si-1)
+		return true
+	case inpututil.IsKeyJustPressed(ebiten.KeyHome):
+		gs.seekReplayKey(mi, 0)
+		return true
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnd):
+		gs.seekReplayKey(mi, len(gs.replayMatches[mi].Steps))
+		return true
+	case inpututil.IsKeyJustPressed(ebiten.KeyPageUp):
+		gs.seekReplayKey(mi-1, 0)
+		return true
+	case inpututil.IsKeyJustPressed(ebiten.KeyPageDown):
+		gs.seekReplayKey(mi+1, 0)
+		return true
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp):
+		gs.replayDelay = max(gs.replayDelay/2, minReplayDelay)
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown):
+		gs.replayDelay = min(gs.replayDelay*2, maxReplayDelay)
+	case inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft):
+		gs.traceClick()
+	}
+	gs.advanceReplay(now)
+	return false
+}
+
+// advanceReplay 自动播放：空闲且间隔已到就走下一手；一盘放完停一个间隔再换下一盘，最后一盘放完暂停
+func (gs *GameScreen) advanceReplay(now time.Time) {
+	if !gs.replayPlaying || !gs.replayIdle() || now.Sub(gs.lastAdvance) < gs.replayDelay {
+		return
+	}
+	if gs.replaySi < len(gs.replayMatches[gs.replayMi].Steps) {
+		gs.replayForward(now)
+		return
+	}
+	if gs.replayMi+1 >= len(gs.replayMatches) {
+		gs.replayPlaying = false
+		return
+	}
+	gs.seekReplayKey(gs.replayMi+1, 0)
+}
+
+func (gs *GameScreen) replayText() string {
+	m := gs.replayMatches[gs.replayMi]
+	state := "paused"
+	if gs.replayPlaying {
+		state = "playing"
+	}
+	return fmt.Sprintf("REPLAY match %d/%d  move %d/%d  winner %s  [%s, %s/move]",
+		gs.replayMi+1, len(gs.replayMatches), gs.replaySi, len(m.Steps), m.Winner, state, gs.replayDelay)
+}
+
+const replayHelp = "Space pause  Left/Right step  Home/End  PgUp/PgDn match  Up/Down speed  Click piece: lineage"
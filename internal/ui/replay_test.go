@@ -0,0 +1,199 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"hexxagon_go/internal/game"
+)
+
+// playOut 人人对战一直走第一步克隆直到终局
+func playOut(t *testing.T, c *Controller) {
+	t.Helper()
+	for i := 0; !c.Screen().state.GameOver; i++ {
+		if i > 200 {
+			t.Fatal("200 手仍未终局")
+		}
+		if err := c.Move(firstMove(c.Screen().state)); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Settle(10 * time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c.Step() // 终局那一帧写录像
+}
+
+// 小棋盘下完一盘：录像追加进文件，开局、着法、胜方都能原样读回；回放逐手播完得到同一终局
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "games.json")
+	cfg := pvpConfig()
+	cfg.Map = MapSmall
+	cfg.RecordFile = path
+	c := newTestController(t, cfg)
+	playOut(t, c)
+	final := c.Screen().state
+	c.Step()
+
+	matches, err := LoadReplays(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("录像 %d 盘，应为 1（终局后多跑的帧不应重复写）", len(matches))
+	}
+	m := matches[0]
+	if m.Start == "" || m.Winner != replayWinner(final.Winner) {
+		t.Fatalf("开局 %q 胜方 %q，终局胜方 %v", m.Start, m.Winner, final.Winner)
+	}
+
+	cfg = pvpConfig()
+	cfg.Mode = "replay"
+	cfg.ReplayFile = path
+	cfg.ReplayDelay = 100 * time.Millisecond
+	r := newTestController(t, cfg)
+	gs := r.Screen()
+	if gs.state.PositionString() != m.Start {
+		t.Fatal("回放没有从录像的开局开始")
+	}
+	if err := r.Move(firstMove(gs.state)); err == nil {
+		t.Fatal("回放时不应接受落子")
+	}
+	for i := 0; gs.replaySi < len(m.Steps) || r.Busy(); i++ {
+		if i > 100000 {
+			t.Fatalf("回放卡在第 %d/%d 手", gs.replaySi, len(m.Steps))
+		}
+		r.Step()
+	}
+	if gs.state.Board.Hash() != final.Board.Hash() || !gs.state.GameOver || gs.state.Winner != final.Winner {
+		t.Fatal("回放终局与原对局不一致")
+	}
+
+	// 录像里每手都带解说和走完后的局面；边播边记的解说栏和直接跳到终局重建的一样
+	for i, st := range m.Steps {
+		before, err := m.replayTo(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st.Comment == "" || st.Comment != game.CommentMove(before.Board, before.CurrentPlayer, st.Move) {
+			t.Fatalf("第 %d 手解说 %q 与重新生成的不同", i+1, st.Comment)
+		}
+		after, _ := m.replayTo(i + 1)
+		if st.Position != after.PositionString() {
+			t.Fatalf("第 %d 手录下的局面 %q，重放得到 %q", i+1, st.Position, after.PositionString())
+		}
+	}
+	live := gs.comments
+	gs.seekReplayKey(0, len(m.Steps))
+	if live.plies != len(m.Steps) || !slices.Equal(live.rows, gs.comments.rows) || !live.rows[len(live.rows)-1].end {
+		t.Fatalf("解说栏 %d 手，应为 %d；跳转重建后应一致并以终局结尾", live.plies, len(m.Steps))
+	}
+
+	// 回退是直接重放，不播动画
+	gs.seekReplayKey(0, len(m.Steps)-1)
+	want, _ := game.ReplayMoves(mustStart(t, m), m.moves()[:len(m.Steps)-1])
+	if r.Busy() || gs.state.Board.Hash() != want.Board.Hash() {
+		t.Fatal("后退一手后局面不对")
+	}
+}
+
+func mustStart(t *testing.T, m ReplayMatch) *game.GameState {
+	t.Helper()
+	st, err := m.startState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return st
+}
+
+// 第二盘追加在后面；内容坏了的文件不覆盖；着法非法的录像加载时报错
+func TestAppendReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "games.json")
+	mv := firstMove(game.NewGameState(BoardRadius))
+	m := ReplayMatch{Winner: "draw", Steps: []ReplayStep{{Move: mv}}}
+	for want := 1; want <= 2; want++ {
+		if n, err := appendReplay(path, m); err != nil || n != want {
+			t.Fatalf("第 %d 次追加: n=%d err=%v", want, n, err)
+		}
+	}
+	if ms, err := LoadReplays(path); err != nil || len(ms) != 2 {
+		t.Fatalf("读回 %d 盘: %v", len(ms), err)
+	}
+
+	junk := filepath.Join(t.TempDir(), "junk.json")
+	os.WriteFile(junk, []byte("not json"), 0644)
+	if _, err := appendReplay(junk, m); err == nil {
+		t.Fatal("内容坏了的文件应报错")
+	}
+	if data, _ := os.ReadFile(junk); string(data) != "not json" {
+		t.Fatal("内容坏了的文件被覆盖")
+	}
+
+	// 录像里的局面与重放对不上（走完第一手还记着开局）：加载时报错
+	wrong := filepath.Join(t.TempDir(), "wrong.json")
+	m.Steps[0].Position = game.NewGameState(BoardRadius).PositionString()
+	if _, err := appendReplay(wrong, m); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadReplays(wrong); err == nil {
+		t.Fatal("局面对不上的录像应在加载时报错")
+	}
+	m.Steps[0].Position = ""
+
+	bad := filepath.Join(t.TempDir(), "bad.json")
+	m.Steps[0].Move.To = m.Steps[0].Move.From
+	if _, err := appendReplay(bad, m); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadReplays(bad); err == nil {
+		t.Fatal("非法着法的录像应在加载时报错")
+	}
+}
+
+// 追加到老版本文件：升级时按各盘规则重放，写完后全局规则要恢复原样
+func TestAppendReplayRestoresRules(t *testing.T) {
+	data, err := os.ReadFile("testdata/replay_v1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "games.json")
+	os.WriteFile(path, data, 0644)
+
+	cascade := game.Rules{Cascade: true}
+	game.SetRules(cascade)
+	defer game.SetRules(game.Rules{})
+	m := ReplayMatch{Winner: "draw", Rules: cascade.String()}
+	if _, err := appendReplay(path, m); err != nil {
+		t.Fatal(err)
+	}
+	if game.CurrentRules() != cascade {
+		t.Fatalf("追加后规则变成了 %v", game.CurrentRules())
+	}
+}
+
+// testdata 里每个版本的录像样例都能读：老版本补齐解说和局面，读回的都是当前版本
+func TestLoadReplayFixtures(t *testing.T) {
+	paths, _ := filepath.Glob("testdata/replay_v*.json")
+	if len(paths) != ReplayVersion {
+		t.Fatalf("testdata 里有 %d 个版本的样例，应为 %d（每一版留一个）", len(paths), ReplayVersion)
+	}
+	for _, p := range paths {
+		matches, err := LoadReplays(p)
+		if err != nil {
+			t.Fatalf("%s: %v", p, err)
+		}
+		for i, m := range matches {
+			if m.Version != ReplayVersion {
+				t.Fatalf("%s: 第 %d 盘版本 %d，应升到 %d", p, i+1, m.Version, ReplayVersion)
+			}
+			for j, step := range m.Steps {
+				if step.Comment == "" || step.Position == "" {
+					t.Fatalf("%s: 第 %d 盘第 %d 手缺解说或局面: %+v", p, i+1, j+1, step)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"hexxagon_go/internal/game"
+)
+
+// 存档与读档（人机、人人对局；联机、演示、回放不支持）：
+//
+//	S   把当前对局存到 GameConfig.SaveFile
+//	L   从 SaveFile 读回，接着下
+//
+// 文件格式见 game.SavedGame。读档要求规则变体与当前一致（规则是进程全局的，对局中不切换），
+// 不一致时提示用 -load 重新启动。-load 启动时直接从存档接着下，见 GameConfig.Resume。
+
+// DefaultSaveFile 没给 -save / -load 时 S/L 用的文件
+const DefaultSaveFile = "hexxagon_save.json"
+
+// gameHistory 本局的开局和已提交的着法，存档用
+type gameHistory struct {
+	start *game.GameState
+	moves []game.Move
+}
+
+// begin 换了新局面时重新开始记
+func (h *gameHistory) begin(st *game.GameState) {
+	cp := *st
+	cp.Board = st.Board.Clone()
+	h.start = &cp
+	h.moves = h.moves[:0]
+}
+
+// canSave 当前模式是否支持存读档
+func (gs *GameScreen) canSave() bool {
+	return gs.saveFile != "" && gs.demo == nil && gs.remote == nil && (gs.mode == "pve" || gs.mode == "pvp")
+}
+
+// pollSaveKeys 每帧检查 S/L
+func (gs *GameScreen) pollSaveKeys() {
+	if !gs.canSave() {
+		return
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyS):
+		gs.saveGame()
+	case inpututil.IsKeyJustPressed(ebiten.KeyL):
+		s, err := game.ReadSave(gs.saveFile)
+		if err == nil {
+			err = gs.loadGame(s)
+		}
+		if err != nil {
+			log.Printf("读档失败: %v", err)
+			gs.showToast("Failed to load game: "+err.Error(), toastDuration)
+			return
+		}
+		gs.showToast(fmt.Sprintf("Game loaded from %s (%d moves)", gs.saveFile, len(s.Moves)), 3*time.Second)
+	}
+}
+
+// saveGame 存下已提交的局面；正在播的这一手还没提交，不进存档
+func (gs *GameScreen) saveGame() {
+	s := game.NewSavedGame(gs.history.start, gs.history.moves, gs.state)
+	if err := game.WriteSave(gs.saveFile, s); err != nil {
+		log.Printf("存档失败: %v", err)
+		gs.showToast("Failed to save game: "+err.Error(), toastDuration)
+		return
+	}
+	log.Printf("已存档: %s，%d 手", gs.saveFile, len(s.Moves))
+	gs.showToast(fmt.Sprintf("Game saved to %s (%d moves)", gs.saveFile, len(s.Moves)), 3*time.Second)
+}
+
+// loadGame 换成存档里的对局：从开局重放，录像、跳跃解锁都按重放的着法补齐
+func (gs *GameScreen) loadGame(s game.SavedGame) error {
+	rules, err := s.ParsedRules()
+	if err != nil {
+		return err
+	}
+	if cur := game.CurrentRules(); rules != cur {
+		return fmt.Errorf("save uses %s rules, this game uses %s; restart with -load", rules, cur)
+	}
+	start, cur, err := s.Restore()
+	if err != nil {
+		return err
+	}
+
+	gs.resetGame(start) // 记下开局，清掉当前这局的搜索、动画和提示
+	walk := *start
+	walk.Board = start.Board.Clone()
+	for _, mv := range s.Moves {
+		comment := game.CommentMove(walk.Board, walk.CurrentPlayer, mv)
+		infected, _, _ := walk.MakeMove(mv) // Restore 已经校验过整串着法
+		gs.aiJumpUnlocked = gs.aiJumpUnlocked || len(infected) > 0
+		gs.history.moves = append(gs.history.moves, mv)
+		if r := gs.recorder; r != nil {
+			r.steps = append(r.steps, ReplayStep{Move: mv, Comment: comment, Position: walk.PositionString()})
+		}
+	}
+	gs.state = cur
+	if n := len(s.Moves); n > 0 {
+		gs.lastMove = &s.Moves[n-1]
+	}
+	if gs.showScores {
+		gs.refreshMoveScores()
+	}
+	return nil
+}
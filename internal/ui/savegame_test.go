@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"hexxagon_go/internal/game"
+)
+
+// 下几手存档，再下一手后读档：回到存档时的局面和着法记录；-load 启动同样接着下
+func TestSaveAndLoadGame(t *testing.T) {
+	cfg := pvpConfig()
+	cfg.SaveFile = filepath.Join(t.TempDir(), "save.json")
+	c := newTestController(t, cfg)
+	gs := c.Screen()
+	for i := 0; i < 4; i++ {
+		if err := c.Move(firstMove(gs.state)); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Settle(10 * time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+	saved := gs.state.PositionString()
+	gs.saveGame()
+
+	if err := c.Move(firstMove(gs.state)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Settle(10 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	s, err := game.ReadSave(cfg.SaveFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gs.loadGame(s); err != nil {
+		t.Fatal(err)
+	}
+	if gs.state.PositionString() != saved || len(gs.history.moves) != 4 {
+		t.Fatalf("读档后局面 %s（%d 手），应为 %s（4 手）", gs.state.PositionString(), len(gs.history.moves), saved)
+	}
+
+	cfg.Resume = &s
+	c2 := newTestController(t, cfg)
+	if got := c2.Screen().state.PositionString(); got != saved {
+		t.Fatalf("-load 启动局面 %s，应为 %s", got, saved)
+	}
+}
+
+func TestLoadGameRejectsOtherRules(t *testing.T) {
+	c := newTestController(t, pvpConfig())
+	gs := c.Screen()
+	s := game.NewSavedGame(gs.state, nil, gs.state)
+	s.Rules = "cascade"
+	if err := gs.loadGame(s); err == nil {
+		t.Fatal("规则不同的存档应拒绝读入")
+	}
+}
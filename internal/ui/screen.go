@@ -3,6 +3,7 @@ package ui
 
 import (
 	"fmt"
+	"log"
 
 	"github.com/hajimehoshi/ebiten/v2/audio"
 	"github.com/hajimehoshi/ebiten/v2/text"
@@ -18,6 +19,8 @@ import (
 	"github.com/hajimehoshi/ebiten/v2"
 
 	"hexxagon_go/internal/assets"
+	"hexxagon_go/internal/engine"
+	"hexxagon_go/internal/net"
 	"hexxagon_go/internal/game"
 
 	"golang.org/x/image/font"
@@ -31,8 +34,8 @@ const (
 	// 窗口尺寸
 	WindowWidth  = 800
 	WindowHeight = 600
-	// 棋盘半径
-	BoardRadius = 4
+	// 像素坐标按最大半径的整盘排布；实际半径见 GameConfig.BoardRadius，画面按可下的半径放大（viewRadius）
+	BoardRadius = game.MaxBoardRadius
 )
 
 type pendingClone struct {
@@ -48,6 +51,7 @@ type GameScreen struct {
 	tileImage   *ebiten.Image                    // 棋盘格子贴图
 	pieceImages map[game.CellState]*ebiten.Image // 棋子贴图映射
 	selected    *game.HexCoord                   // 当前选中的源格
+	premove     *game.Move                       // 对手落子提交前排好的下一步，见 premove.go
 	// 高亮提示图
 	hintGreenImage  *ebiten.Image // 复制移动近距离高亮图
 	hintYellowImage *ebiten.Image // 跳跃移动远距离高亮图
@@ -60,11 +64,13 @@ type GameScreen struct {
 	isAnimating     bool          // 标记是否正在播放动画
 	pendingClone    *pendingClone // 等待执行的 Clone 动作
 
-	mode               string // "pve", "pvp", "replay"
+	mode               string // "pve", "pvp", "net", "replay", "demo", "ava"
 	lastAdvance        time.Time
 	replayDelay        time.Duration
 	replayMi, replaySi int
 	replayMatches      []ReplayMatch
+	replayPlaying      bool            // 回放自动播放中，见 replay.go
+	recorder           *replayRecorder // 非 nil 时每盘终局写录像，见 replay.go
 
 	ui             UIState
 	showScores     bool
@@ -77,26 +83,78 @@ type GameScreen struct {
 		when   time.Time
 		// 仅用于 Sparkle/音效：这回合新增
 		newborns []game.HexCoord // move.To + infections
+		events   []game.PieceEvent // 这一手的棋子事件，提交成功后记进 pieceTree
 	}
 	// 思考图标与AI缓存
 	aiThinkingStart time.Time
 	aiThinkingUntil time.Time
 	aiQueuedMove    *game.Move // 已算出但尚未应用
 	showThinking    bool
-	aiThinkingImg   *ebiten.Image // 思考中图标
+	aiThinkingImg   *ebiten.Image  // 思考中图标
+	pacing          Pacing         // 思考节奏，见 pacing.go
+	engine          *engine.Client // 非 nil 时 AI 走子进程引擎，见 SetEngine
+	bot             botFunc        // 非 nil 时 AI 用内置基线对手或脚本对手，不搜索，见 GameConfig.Bot / BotScript
+	timeBudget      time.Duration  // 单步搜索时间上限，0=不限，见 GameConfig.TimeBudget
+	searchTime      time.Duration  // 按时间搜索、不限深度，0=按深度，见 GameConfig.SearchTime
+	fog             fogConfig      // 迷雾模式，见 fog.go
+	mcCh            chan mcTips    // 无 ONNX 时蒙特卡洛提示的结果，见 mc_tips.go
+	mcGen           int            // 最新一次估计的编号，用来丢弃过期结果
 
 	tempGhosts []tempGhost                // 幽灵棋子（视觉层）
 	tempHide   map[game.HexCoord]struct{} // 临时隐藏：坐标→到期时间（跳跃旧位）
 
+	hiddenPieces map[game.PieceID]struct{} // 正在播跳跃动画的子：按编号隐藏，提交前它还在起点
+	pieceTree    game.PieceTree            // 本局的克隆谱系，复盘追踪用，见 trace.go
+	tracePiece   game.PieceID              // 回放时点选追踪谱系的子；0 = 没有
+
 	boardBaked   *ebiten.Image // 预渲染好的整盘底图(含渐变)
 	boardBakedOK bool          // 标志是否已烘焙
 
 	aiResultCh chan game.Move // 后台AI结果传回（容量1）
+	aiNoMoveCh chan struct{}  // 后台AI无着可走（容量1）
+	aiErrCh    chan error     // 外部引擎重启后仍搜索失败（容量1），见 searchWith
 	aiCancelCh chan struct{}  // 取消信号（close 即取消）
+	aiDone     chan struct{}  // 后台搜索协程退出时 close；取消后 aiRunning 先落下，协程要等它才算真退出
 	aiRunning  bool           // 是否有AI在后台跑
 
+	aiProgressCh chan game.SearchProgress // 迭代加深每完成一层推送一次（容量1，只留最新）
+	aiProgress   *game.SearchProgress     // 最近一次进度，用于思考提示
+
 	hideWindows []timedHide
 
+	demo *demoState // 非 nil 时处于演示模式，见 demo.go
+
+	players map[game.CellState]*AIPlayer // 按方单独配置的 AI（ava 双方、pve 给了 White 时的白方），见 ava.go
+	ava     *avaState                    // -mode ava 时非 nil：观战的局数与比分
+
+	remote *net.Session // 非 nil 时为联机对局，对方一方由网络另一端走，见 netplay.go
+
+	newState func() *game.GameState // 本局地图的开局，退出演示时按它重开
+	mapLabel string          // 随机地图的种子说明，画在左上角便于分享；原版地图为空
+
+	blitz     blitzConfig // 快棋模式，见 blitz.go
+	turnStart time.Time   // 快棋：人类本步开始计时的时刻（零值=未在计时）
+
+	options *optionsReload // 搜索/评估参数热加载，未指定参数文件时为 nil，见 options.go
+
+	overlay  overlay    // 棋盘叠加标注层，每帧重建，见 overlay.go
+	lastMove *game.Move // 最近一手，演示时画成箭头
+	comments commentLog // 逐手解说，回放和演示时画在右侧，见 commentary.go
+
+	showHeatmap bool // 终局画争夺热力图，见 heatmap.go
+
+	clock func() time.Time // 非 nil 时替代 time.Now（无头测试的虚拟时钟，见 controller.go）
+	perf  perfOverlay      // F3 性能浮层，见 perf_overlay.go
+
+	toast          *toast // 底部提示条，见 toast.go
+	backendNoticed bool   // NN 后端回退提示已判断过
+
+	idle idleState // 失焦/终局放置时的节流，见 idle.go
+	hint hintState // H 提示，见 hint.go
+
+	saveFile string      // S/L 存读档的文件；空=不支持，见 savegame.go
+	history  gameHistory // 本局开局与着法，存档用
+
 	didShrink bool
 }
 
@@ -114,29 +172,70 @@ type tempGhost struct {
 	hideAt time.Time // 提交时隐藏（提交后棋盘有真子）
 }
 
-type ReplayStep struct {
-	Move game.Move `json:"move"`
-}
-
-type ReplayMatch struct {
-	Winner string       `json:"winner"`
-	Steps  []ReplayStep `json:"steps"`
-}
-
-// NewGameScreen 构造并初始化游戏界面
-func NewGameScreen(ctx *audio.Context, aiEnabled bool, aiDepth int, showScores bool) (*GameScreen, error) {
-	var err error
-	TipSearchDepth = aiDepth // 同步提示功能使用的搜索深度
+// NewGameScreen 按 cfg 构造并初始化游戏界面；cfg 不合法时直接返回错误
+func NewGameScreen(ctx *audio.Context, cfg GameConfig) (*GameScreen, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	cfg.applyEvaluator()
+	newState, err := cfg.newState()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Map == MapRandom {
+		log.Printf("随机地图: %d 障碍, 种子 %d（-map random -map-blocks %d -map-seed %d 可复现）",
+			cfg.MapBlocks, cfg.MapSeed, cfg.MapBlocks, cfg.MapSeed)
+	}
 	gs := &GameScreen{
-		state:       game.NewGameState(BoardRadius),
+		state:       newState(),
+		newState:    newState,
 		pieceImages: make(map[game.CellState]*ebiten.Image),
-		aiEnabled:   aiEnabled,
-		aiDepth:     aiDepth,
-		showScores:  showScores,
+		mode:        cfg.Mode, // demo 在最后由 StartDemo 切入
+		aiEnabled:   cfg.Mode == "pve" || cfg.Mode == "ava",
+		aiDepth:     cfg.Depth,
+		showScores:  cfg.ShowTips,
 		ui:          UIState{}, // 初始化 UIState
 		fontFace:    basicfont.Face7x13,
+		pacing:      cfg.Pacing,
+		timeBudget:  cfg.TimeBudget,
+		searchTime:  cfg.SearchTime,
+		fog:         fogConfig{enabled: cfg.Fog, ai: cfg.FogAI, samples: cfg.FogSamples},
+		showHeatmap: cfg.Heatmap,
+		saveFile:    cfg.SaveFile,
+		players:     cfg.sidePlayers(),
+	}
+	gs.history.begin(gs.state)
+	if gs.bot, err = cfg.newBot(); err != nil {
+		return nil, err
+	}
+	if cfg.Blitz {
+		gs.blitz = blitzConfig{enabled: true, limit: cfg.BlitzMoveLimit}
+		gs.pacing = blitzPacing(gs.pacing)
+		gs.timeBudget = blitzTimeBudget(gs.timeBudget, cfg.BlitzMoveLimit)
+		if gs.searchTime > 0 {
+			gs.searchTime = blitzTimeBudget(gs.searchTime, cfg.BlitzMoveLimit)
+		}
+	}
+	switch cfg.Map {
+	case MapRandom:
+		gs.mapLabel = fmt.Sprintf("Random map: %d blocks, seed %d", cfg.MapBlocks, cfg.MapSeed)
+	case MapSmall:
+		gs.mapLabel = fmt.Sprintf("Small board: radius %d", SmallBoardRadius)
+	}
+	if cfg.Map != MapSmall && cfg.BoardRadius != game.DefaultBoardRadius {
+		if gs.mapLabel != "" {
+			gs.mapLabel += ", "
+		}
+		gs.mapLabel += fmt.Sprintf("Board radius %d", cfg.BoardRadius)
+	}
+	if cfg.OptionsFile != "" {
+		if err := gs.startOptionsReload(cfg.OptionsFile); err != nil {
+			return nil, err
+		}
 	}
 	gs.tempHide = make(map[game.HexCoord]struct{})
+	gs.hiddenPieces = make(map[game.PieceID]struct{})
+	gs.pieceTree = game.PieceTree{}
 	// 加载贴图
 	if gs.tileImage, err = assets.LoadImage("hex_space"); err != nil {
 		return nil, err
@@ -192,23 +291,73 @@ func NewGameScreen(ctx *audio.Context, aiEnabled bool, aiDepth int, showScores b
 	gs.offscreen = ebiten.NewImage(WindowWidth, WindowHeight)
 
 	gs.aiResultCh = make(chan game.Move, 1)
+	gs.aiNoMoveCh = make(chan struct{}, 1)
+	gs.aiErrCh = make(chan error, 1)
 	gs.aiCancelCh = make(chan struct{})
+	gs.aiDone = make(chan struct{})
+	close(gs.aiDone)
+	gs.aiProgressCh = make(chan game.SearchProgress, 1)
+
+	switch cfg.Mode {
+	case "demo":
+		gs.mode = "pvp" // Esc 退出演示后回到人人对战
+		gs.StartDemo()
+	case "ava":
+		gs.ava = &avaState{wins: make(map[game.CellState]int)}
+		gs.nextAvAGame()
+	case "replay":
+		matches, err := LoadReplays(cfg.ReplayFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取录像失败: %w", err)
+		}
+		if err := gs.startReplay(matches, cfg.ReplayDelay); err != nil {
+			return nil, err
+		}
+	default:
+		if cfg.RecordFile != "" {
+			gs.recorder = &replayRecorder{path: cfg.RecordFile}
+			gs.recorder.begin(gs.state)
+		}
+		if cfg.Resume != nil {
+			if err := gs.loadGame(*cfg.Resume); err != nil {
+				return nil, fmt.Errorf("读档失败: %w", err)
+			}
+		}
+	}
 	return gs, nil
 }
 
 var frameEps = time.Second / 30
 
+func (gs *GameScreen) now() time.Time {
+	if gs.clock != nil {
+		return gs.clock()
+	}
+	return time.Now()
+}
+
 // performMove 执行一次完整落子，返回本次行动需要的总耗时（用于 aiDelayUntil）
 // 在 performMove 函数中，修改幽灵棋子的时机设置
 
 // 在 performMove 函数中，修改幽灵棋子的时机设置
 
 func (gs *GameScreen) performMove(move game.Move, player game.CellState) (time.Duration, error) {
-	baseNow := time.Now()
-	gs.isAnimating = true
+	// 动画按棋子编号对应：跳走的是哪颗子、翻色的是哪颗子，见 game.MoveEvents
+	events := game.MoveEvents(gs.state.Board, player, move)
+	if len(events) == 0 {
+		return 0, fmt.Errorf("move %v off the board", move)
+	}
+	moving, chain := events[0].Piece, events[1:]
 
-	infected := computeInfections(gs.state.Board, move, player)
-	gs.addMoveAnim(move, player)
+	baseNow := gs.now()
+	gs.isAnimating = true
+	gs.lastMove = &move
+	gs.sendRemote(move, player)
+	infected := make([]game.HexCoord, len(chain))
+	for i, inf := range chain {
+		infected[i] = inf.To
+	}
+	gs.addMoveAnim(move, player, moving)
 
 	dirKey := directionKey(move.From, move.To)
 	var moveBase string
@@ -222,7 +371,7 @@ func (gs *GameScreen) performMove(move game.Move, player game.CellState) (time.D
 	default:
 		moveBase = "whiteClone/" + dirKey
 	}
-	moveDur := animDuration(moveBase, 30)
+	moveDur := animDuration(moveBase, gs.animFPS())
 
 	var infectDur, becomeDur time.Duration
 	if len(infected) > 0 {
@@ -232,27 +381,43 @@ func (gs *GameScreen) performMove(move game.Move, player game.CellState) (time.D
 			infectBase = "whiteEatRed"
 			becomeBase = "redBecomeWhite"
 		}
-		infectDur = animDuration(infectBase, 30)
-		becomeDur = animDuration(becomeBase, 30)
+		infectDur = animDuration(infectBase, gs.animFPS())
+		becomeDur = animDuration(becomeBase, gs.animFPS())
 
-		for _, inf := range infected {
-			gs.addInfectAnim(move.To, inf, player, moveDur)
-			gs.addBecomeAnim(inf, player, moveDur+infectDur)
+		// 连锁感染逐波播放：第 w 波在上一波开始翻色时出手；原版规则只有第 0 波
+		waves := chain[len(chain)-1].Wave + 1
+		chainEnd := baseNow.Add(moveDur + time.Duration(waves)*infectDur + becomeDur)
+		for _, inf := range chain {
+			start := moveDur + time.Duration(inf.Wave)*infectDur
+			gs.addInfectAnim(inf.From, inf.To, player, start, inf.Piece)
+			gs.addBecomeAnim(inf.To, player, start+infectDur, inf.Piece)
 
-			becomeStart := baseNow.Add(moveDur + infectDur)
-			becomeEnd := baseNow.Add(moveDur + infectDur + becomeDur)
+			becomeStart := baseNow.Add(start + infectDur)
+			becomeEnd := becomeStart.Add(becomeDur)
 
 			gs.hideWindows = append(gs.hideWindows, timedHide{
-				coord: inf,
+				coord: inf.To,
 				start: becomeStart.Add(-frameEps),
-				end:   becomeEnd,
+				end:   chainEnd,
 			})
+			if becomeEnd.Before(chainEnd) {
+				// 早几波翻完的子：翻色动画放完到提交前由幽灵棋子顶着
+				gs.tempGhosts = append(gs.tempGhosts, tempGhost{
+					coord:  inf.To,
+					player: player,
+					showAt: becomeEnd,
+					hideAt: chainEnd.Add(frameEps * 3),
+				})
+			}
 		}
+		infectDur *= time.Duration(waves)
 	} else {
 		infectDur, becomeDur = 0, 0
 	}
 
-	// 音效触发保持不变
+	// 音效按落点左右定声像，每个音效随机挑一版录音；快棋只留落子和翻子两声
+	pan := gs.cellPan(move.To)
+	blitz := gs.blitz.enabled
 	time.AfterFunc(moveDur, func() {
 		var seq []string
 		if move.IsJump() {
@@ -268,15 +433,21 @@ func (gs *GameScreen) performMove(move game.Move, player game.CellState) (time.D
 				seq = append(seq, "white_split")
 			}
 		}
-		if len(infected) > 0 {
-			if player == game.PlayerA {
-				seq = append(seq, "red_capture_white_before", "red_capture_white_after")
-			} else {
-				seq = append(seq, "white_capture_red_before", "white_capture_red_after")
-			}
+		switch {
+		case len(infected) == 0:
+		case blitz && player == game.PlayerA:
+			seq = append(seq, "red_capture_white_after")
+		case blitz:
+			seq = append(seq, "white_capture_red_after")
+		case player == game.PlayerA:
+			seq = append(seq, "red_capture_white_before", "red_capture_white_after")
+		default:
+			seq = append(seq, "white_capture_red_before", "white_capture_red_after")
+		}
+		if !blitz {
+			seq = append(seq, "all_capture_after")
 		}
-		seq = append(seq, "all_capture_after")
-		gs.audioManager.PlaySequential(seq...)
+		gs.audioManager.PlaySequentialAt(pan, seq...)
 	})
 
 	// 🔧 关键修改：让真实棋子和幽灵棋子完美衔接
@@ -307,7 +478,7 @@ func (gs *GameScreen) performMove(move game.Move, player game.CellState) (time.D
 		})
 	}
 	if move.IsJump() {
-		gs.tempHide[move.From] = struct{}{}
+		gs.hiddenPieces[moving] = struct{}{}
 	}
 
 	newborns := make([]game.HexCoord, 0, 1+len(infected))
@@ -319,11 +490,13 @@ func (gs *GameScreen) performMove(move game.Move, player game.CellState) (time.D
 		player   game.CellState
 		when     time.Time
 		newborns []game.HexCoord
+		events   []game.PieceEvent
 	}{
 		move:     move,
 		player:   player,
 		when:     commitAt,
 		newborns: newborns,
+		events:   events,
 	}
 
 	return moveDur + infectDur, nil
@@ -333,7 +506,8 @@ func (gs *GameScreen) performMove(move game.Move, player game.CellState) (time.D
 
 // Update 更新游戏状态
 func (gs *GameScreen) Update() error {
-	now := time.Now()
+	defer gs.pollPerfKey()()
+	now := gs.now()
 
 	if !gs.didShrink {
 		// 需要的话先计算 spriteScale（固定值就不用算）
@@ -345,10 +519,21 @@ func (gs *GameScreen) Update() error {
 
 	// 1) 音频更新
 	gs.audioManager.Update()
+	gs.updateIdle(now)
+
+	if gs.showScores {
+		gs.pollMCTips()
+	}
+	gs.pollBackendNotice()
+	gs.pollOptions()
+	gs.pollHeatmapKey()
+	gs.pollHint(now)
+	gs.pollSaveKeys()
+	gs.pollCommentWheel()
 
 	// 2) prune finished animations before handling game over
 	for i := 0; i < len(gs.anims); {
-		if gs.anims[i].Done {
+		if gs.anims[i].finished(now) {
 			gs.anims = append(gs.anims[:i], gs.anims[i+1:]...)
 			continue
 		}
@@ -356,13 +541,19 @@ func (gs *GameScreen) Update() error {
 	}
 	gs.isAnimating = len(gs.anims) > 0
 
+	if gs.updateReplay(now) || gs.updateDemo(now) || gs.updateAvA(now) {
+		return nil
+	}
+
 	if gs.state.GameOver {
+		gs.saveRecord()
 		if gs.aiRunning {
 			close(gs.aiCancelCh)
 			gs.aiRunning = false
 		}
 		gs.showThinking = false
 		gs.aiQueuedMove = nil
+		gs.premove = nil
 		gs.aiThinkingUntil = time.Time{}
 		gs.aiDelayUntil = time.Time{}
 		return nil
@@ -376,6 +567,7 @@ func (gs *GameScreen) Update() error {
 	// 4) 优先处理pendingCommit：确保真实棋盘状态及时更新
 	if pc := gs.pendingCommit; pc != nil && now.After(pc.when) {
 		// 真正更新棋盘
+		before := gs.state.Board.Clone()
 		infectedCoords, _, err := gs.state.MakeMove(pc.move)
 		if err != nil {
 			fmt.Println("MakeMove error:", err)
@@ -383,19 +575,27 @@ func (gs *GameScreen) Update() error {
 			if len(infectedCoords) > 0 {
 				gs.aiJumpUnlocked = true
 			}
+			gs.recordMove(pc.move, gs.commitComment(before, pc.player, pc.move))
+			gs.pieceTree.Record(pc.events)
 		}
 
-		// 清理临时隐藏
-		delete(gs.tempHide, pc.move.From)
+		// 清理临时隐藏：跳走的子已经落到终点；其余以提交前后的实际变化为准（含终局判空格等额外改动）
+		if len(pc.events) > 0 {
+			delete(gs.hiddenPieces, pc.events[0].Piece)
+		}
 		for _, c := range pc.newborns {
 			delete(gs.tempHide, c)
 		}
+		for _, c := range before.Diff(gs.state.Board) {
+			delete(gs.tempHide, c.Coord)
+		}
 
 		gs.pendingCommit = nil
 		// 刷新胜率显示
 		if gs.showScores {
 			gs.refreshMoveScores()
 		}
+		gs.applyPremove()
 	}
 
 	// 5) 处理隐藏窗口（在pendingCommit之后）
@@ -430,9 +630,24 @@ func (gs *GameScreen) Update() error {
 	}
 	gs.tempGhosts = keptGhosts
 
-	// 7) AI回合处理（保持不变）
-	if gs.aiEnabled && gs.state.CurrentPlayer == game.PlayerB {
+	// 6.5) 轮到的一方无路可走但对局未结束：按规则裁定，避免界面空等
+	if gs.pendingCommit == nil && !gs.isAnimating && !gs.aiRunning && gs.aiQueuedMove == nil {
+		if gs.state.AdjudicateIfBlocked() {
+			gs.selected = nil
+			return nil
+		}
+	}
+
+	// 6.6) 快棋计时：人类超时替他随机走一步
+	if gs.updateBlitz(now) {
+		return nil
+	}
+
+	// 7) AI回合处理（演示模式下双方都走这里）
+	if gs.isAITurn() {
+		side := gs.state.CurrentPlayer
 		if gs.isAnimating || gs.pendingCommit != nil || now.Before(gs.aiDelayUntil) {
+			gs.handleInput() // AI 的落子还在播：人类可以先预选/预走
 			return nil
 		}
 
@@ -441,60 +656,160 @@ func (gs *GameScreen) Update() error {
 			gs.aiQueuedMove = nil
 			gs.showThinking = false
 
-			if total, err := gs.performMove(mv, game.PlayerB); err == nil {
+			if total, err := gs.performMove(mv, side); err == nil {
 				gs.aiDelayUntil = now.Add(total)
+				if gs.demo != nil {
+					gs.demo.plies++
+				}
 			}
 			gs.selected = nil
 			return nil
 		}
 
-		if !gs.aiRunning && gs.aiQueuedMove == nil {
+		if gs.remote != nil {
+			gs.pollRemote(now)
+			return nil
+		}
+
+		if !gs.aiBusy() && gs.aiQueuedMove == nil && !gs.idle.on {
+			// 只有一步可走：不必搜索，也不必装作思考
+			if gs.pacing.InstantForced {
+				if mvs := game.GenerateMoves(gs.state.Board, side); len(mvs) == 1 {
+					gs.aiQueuedMove = &mvs[0]
+					gs.aiThinkingUntil = now
+					return nil
+				}
+			}
+
 			gs.aiThinkingStart = now
-			gs.aiThinkingUntil = gs.aiThinkingStart.Add(2 * time.Second)
+			gs.aiThinkingUntil = gs.aiThinkingStart.Add(gs.pacing.thinkTime())
 			gs.showThinking = true
 			gs.aiRunning = true
 
+			// 上一轮若是取消的，它退出前可能已把结果塞进通道，先清掉
+			select {
+			case <-gs.aiResultCh:
+			default:
+			}
+			select {
+			case <-gs.aiNoMoveCh:
+			default:
+			}
+			select {
+			case <-gs.aiErrCh:
+			default:
+			}
 			gs.aiCancelCh = make(chan struct{})
+			gs.aiDone = make(chan struct{})
+			gs.aiProgressCh = make(chan game.SearchProgress, 1) // 新通道：丢弃上一轮残留进度
+			gs.aiProgress = nil
 			boardCopy := gs.state.Board.Clone()
 			allowJump := gs.aiJumpUnlocked
-			depthLim := gs.aiDepth
+			depthLim := gs.aiDepthFor(side)
+			budget := gs.timeBudget
+			if gs.searchTime > 0 && gs.aiPlayer(side) == nil {
+				// -time：只按时间，深度放到搜索允许的上限（演示模式也一样，轮换的深度不再起作用）；
+				// 单独配置了 AI 的一方按它自己的设置
+				budget, depthLim = gs.searchTime, game.MaxBudgetDepth
+			}
 
-			go func(b *game.Board, d int, allow bool, out chan<- game.Move, cancel <-chan struct{}) {
-				mv, _, ok := game.IterativeDeepening(b, game.PlayerB, d, allow)
+			eng, bot, fog := gs.engine, gs.bot, gs.fog
+			if p := gs.aiPlayer(side); p != nil {
+				eng, bot = nil, p.bot()
+			}
+			if gs.sidesShareTT() {
+				game.ClearTT()
+			}
+			b, d, allow := boardCopy, depthLim, allowJump
+			out, noMove, errCh, progress, cancel, done := gs.aiResultCh, gs.aiNoMoveCh, gs.aiErrCh, gs.aiProgressCh, gs.aiCancelCh, gs.aiDone
+			goSearch(func() {
+				defer close(done)
+				onDepth := func(p game.SearchProgress) {
+					// 只保留最新一条：先取走旧的再放入
+					select {
+					case <-progress:
+					default:
+					}
+					select {
+					case progress <- p:
+					default:
+					}
+				}
+				mv, ok, err := fogSearchWith(fog, eng, bot, b, side, d, budget, allow, onDepth)
 				select {
 				case <-cancel:
 					return
 				default:
 				}
-				if ok {
+				if err != nil {
+					select {
+					case errCh <- err:
+					default:
+					}
+					return
+				}
+				if !ok {
 					select {
-					case out <- mv:
+					case noMove <- struct{}{}:
 					default:
 					}
+					return
 				}
-			}(boardCopy, depthLim, allowJump, gs.aiResultCh, gs.aiCancelCh)
+				select {
+				case out <- mv:
+				default:
+				}
+			})
+		}
+
+		if !gs.aiRunning {
+			return nil // 只剩已取消、还没退出的上一轮，它的进度和结果都不收
+		}
+		select {
+		case p := <-gs.aiProgressCh:
+			gs.aiProgress = &p
+		default:
 		}
 
 		select {
 		case mv := <-gs.aiResultCh:
 			gs.aiQueuedMove = &mv
 			gs.aiRunning = false
+		case err := <-gs.aiErrCh:
+			// 引擎重启过也没搜出来：报出来，隔一会儿再试（下一轮还会重启它），不悄悄换成进程内搜索
+			log.Printf("外部引擎搜索失败: %v", err)
+			gs.aiRunning = false
+			gs.showThinking = false
+			gs.aiDelayUntil = now.Add(engineRetryDelay)
+			gs.showToast("Engine failed, restarting - see log\n"+err.Error(), toastDuration)
+		case <-gs.aiNoMoveCh:
+			gs.aiRunning = false
+			gs.showThinking = false
+			if !gs.state.AdjudicateIfBlocked() {
+				// 搜索没给出走法但其实还有合法着：兜底走第一步，别让对局卡住
+				if mvs := game.GenerateMoves(gs.state.Board, side); len(mvs) > 0 {
+					gs.aiQueuedMove = &mvs[0]
+				}
+			}
 		default:
 		}
 
 		return nil
 	}
 
-	// 8) 人类输入处理
-	gs.handleInput()
+	// 8) 人类输入处理（回放时不接受落子）
+	if gs.mode != "replay" {
+		gs.handleInput()
+	}
 	markBooted()
 
-	ensurePerf(gs.isAnimating || gs.aiRunning || gs.aiQueuedMove != nil || gs.selected != nil)
+	ensurePerf(gs.isAnimating || gs.aiRunning || gs.aiQueuedMove != nil || gs.selected != nil || gs.hint.pending || gs.hintShown())
 	return nil
 }
 
 // Draw 每帧渲染：先清空背景，再绘制棋盘与棋子
 func (gs *GameScreen) Draw(screen *ebiten.Image) {
+	gs.perf.beginDraw()
 	// 1) 清空屏幕背景（window 上）
 	screen.Fill(color.Black)
 
@@ -506,6 +821,15 @@ func (gs *GameScreen) Draw(screen *ebiten.Image) {
 	for c := range gs.tempHide {
 		skip[c] = true
 	}
+	for id := range gs.hiddenPieces {
+		if c, ok := gs.state.Board.FindPiece(id); ok {
+			skip[c] = true
+		}
+	}
+	hidden, fogOn := gs.fogHidden()
+	for _, i := range hidden.Cells() {
+		skip[game.CoordOf[i]] = true
+	}
 
 	gs.drawBoardAndPiecesWithHints(
 		gs.offscreen,
@@ -517,6 +841,9 @@ func (gs *GameScreen) Draw(screen *ebiten.Image) {
 		gs.selected,
 		skip,
 	)
+	if fogOn {
+		gs.drawFog(gs.offscreen, hidden)
+	}
 	// —— 思考图标（右上角）——
 	if gs.showThinking && gs.aiThinkingImg != nil {
 		iw, ih := gs.aiThinkingImg.Bounds().Dx(), gs.aiThinkingImg.Bounds().Dy()
@@ -541,42 +868,21 @@ func (gs *GameScreen) Draw(screen *ebiten.Image) {
 	}
 	boardScale, originX, originY, tileW, tileH, vs := getBoardTransform(gs.tileImage)
 
-	now := time.Now()
+	now := gs.now()
 	for _, g := range gs.tempGhosts {
-		if now.Before(g.showAt) || now.After(g.hideAt) {
+		if now.Before(g.showAt) || now.After(g.hideAt) || fogged(hidden, g.coord) {
 			continue
 		}
 		// 用与真实棋子相同的 drawPiece 叠加（你也可以降低 alpha 做“淡入”）
 		drawPiece(gs.offscreen, gs.pieceImages[g.player], g.coord, originX, originY, int(tileW), int(tileH), vs, boardScale)
 	}
-	// —— 新增：把评分画到每个目标格的中心 ——
-	if gs.showScores {
-		for to, score := range gs.ui.MoveScores {
-			// 1) 计算格子在 offscreen 上的像素中心
-			cx := (float64(to.Q)+BoardRadius)*tileW*0.75 + tileW/2
-			cy := (float64(to.R)+BoardRadius+float64(to.Q)/2)*vs + tileH/2
-
-			px := originX + cx*boardScale
-			py := originY + cy*boardScale
-
-			// 2) 格式化分数（百分比），数值越大颜色越亮
-			str := fmt.Sprintf("%.1f%%", score)
-			
-			// 根据概率调整亮度 (0-100 映射到 100-255)
-			brightness := uint8(100 + (score * 1.55))
-			clr := color.RGBA{0x20, brightness, 0x20, 0xFF} 
-			if score < 1.0 {
-				clr = color.RGBA{0x80, 0x80, 0x80, 0xFF} // 极低概率灰色
-			}
-
-			// 3) 画字（居中）
-			drawTextCentered(gs.offscreen, str, px, py, clr)
-		}
-	}
+	// 评分、归属图、预走箭头等叠加标注，见 overlay.go
+	gs.buildOverlay()
+	gs.overlay.draw(gs.offscreen, gs.tileImage)
 	//fmt.Println(gs.anims)
 	for _, a := range gs.anims {
-		img := a.Current()
-		if img == nil {
+		img := a.Current(now)
+		if img == nil || fogged(hidden, a.Coord) {
 			continue
 		}
 		w, h := img.Size()
@@ -664,6 +970,12 @@ func (gs *GameScreen) Draw(screen *ebiten.Image) {
 	if gs.showScores {
 		redInfo = fmt.Sprintf("Red: %d (%.1f%%)", aCnt, probA*100)
 		whiteInfo = fmt.Sprintf("White: %d (%.1f%%)", bCnt, probB*100)
+		// 已锁定的空格（对手够不到），终局都会归这一方
+		if sec := game.SecuredTerritory(gs.state.Board, game.PlayerA); sec > 0 {
+			redInfo += fmt.Sprintf(" +%d", sec)
+		} else if sec < 0 {
+			whiteInfo += fmt.Sprintf(" +%d", -sec)
+		}
 	} else {
 		redInfo = fmt.Sprintf("Red: %d", aCnt)
 		whiteInfo = fmt.Sprintf("White: %d", bCnt)
@@ -674,6 +986,113 @@ func (gs *GameScreen) Draw(screen *ebiten.Image) {
 	// 粗略计算红色文本宽度来决定白色文本的起点 (每个字符约 7 像素)
 	whiteX := 20 + len(redInfo)*7 + 30
 	text.Draw(screen, whiteInfo, gs.fontFace, whiteX, 24, whiteColor)
+
+	if gs.state.GameOver {
+		text.Draw(screen, gameOverText(gs.state), gs.fontFace, 20, 44, color.White)
+		if gs.demo == nil {
+			text.Draw(screen, gs.heatmapText(), gs.fontFace, 20, 84, color.RGBA{0xFF, 0xB0, 0x80, 0xFF})
+		}
+	} else if gs.showThinking {
+		text.Draw(screen, thinkingText(gs.aiProgress, gs.now().Sub(gs.aiThinkingStart)), gs.fontFace, 20, 44, color.White)
+	} else if gs.premove != nil {
+		text.Draw(screen, premoveText(gs.premove), gs.fontFace, 20, 44, color.White)
+	} else if msg := gs.remoteText(); msg != "" {
+		text.Draw(screen, msg, gs.fontFace, 20, 44, color.White)
+	}
+	if gs.demo != nil {
+		text.Draw(screen, gs.demoText(), gs.fontFace, 20, 64, color.RGBA{0x90, 0xC0, 0xFF, 0xFF})
+	} else if gs.spectating() {
+		text.Draw(screen, gs.avaText(), gs.fontFace, 20, 64, color.RGBA{0x90, 0xC0, 0xFF, 0xFF})
+	} else if gs.mode == "replay" {
+		text.Draw(screen, gs.replayText(), gs.fontFace, 20, 64, color.RGBA{0x90, 0xC0, 0xFF, 0xFF})
+		text.Draw(screen, replayHelp, gs.fontFace, 20, 104, color.RGBA{0xA0, 0xA0, 0xA0, 0xFF})
+		if msg := gs.traceText(); msg != "" {
+			text.Draw(screen, msg, gs.fontFace, 20, 124, traceSelfColor)
+		}
+	} else if gs.mapLabel != "" {
+		text.Draw(screen, gs.mapLabel, gs.fontFace, 20, 64, color.RGBA{0xA0, 0xA0, 0xA0, 0xFF})
+	}
+	gs.drawComments(screen)
+	gs.drawBlitzClock(screen)
+	gs.drawToast(screen)
+	gs.perf.endDraw(len(gs.anims)) // 先记本帧，浮层自身的绘制不计入
+	gs.perf.showTT = gs.engine == nil
+	gs.perf.draw(screen, gs.fontFace)
+}
+
+// SetEngine 让 AI 改用子进程引擎搜索（nil = 进程内搜索）。
+// 引擎出错或崩溃时重启它重搜，还不行就在底部提示条报错、稍后再试（见 searchWith），界面不受影响。
+func (gs *GameScreen) SetEngine(c *engine.Client) {
+	gs.engine = c
+}
+
+// engineRetryDelay 外部引擎连重启都救不回来时，隔这么久再试下一轮
+const engineRetryDelay = 2 * time.Second
+
+// searchWith 在后台协程里执行一次 AI 搜索；budget 非 0 时迭代加深到 depth 层或到点为止（外部引擎带 movetime）。
+// 外部引擎出错或崩溃时重启它再搜一次，仍失败就返回 err，由界面报出来；不退回进程内搜索，免得换了评估还没人知道
+func searchWith(eng *engine.Client, bot botFunc, b *game.Board, side game.CellState, depth int, budget time.Duration, allowJump bool, onDepth func(game.SearchProgress)) (game.Move, bool, error) {
+	if bot != nil {
+		mv, ok, err := bot(b, side, allowJump)
+		if err == nil {
+			return mv, ok, nil
+		}
+		log.Printf("内置对手出错，改用内置搜索: %v", err)
+	}
+	if eng != nil {
+		st := &game.GameState{Board: b, CurrentPlayer: side}
+		mv, ok, err := eng.SearchBudget(st, depth, budget, allowJump, onDepth)
+		if err == nil {
+			return mv, ok, nil
+		}
+		log.Printf("外部引擎搜索失败，重启后重试: %v", err)
+		if rerr := eng.Restart(); rerr != nil {
+			return game.Move{}, false, fmt.Errorf("%v; restart: %w", err, rerr)
+		}
+		return eng.SearchBudget(st, depth, budget, allowJump, onDepth)
+	}
+	if budget > 0 {
+		mv, ok := game.FindBestMoveWithBudgetDepth(b, side, depth, budget, allowJump, onDepth)
+		return mv, ok, nil
+	}
+	mv, _, ok := game.IterativeDeepeningProgress(b, side, depth, allowJump, onDepth)
+	return mv, ok, nil
+}
+
+// aiBusy 后台搜索还没退出：正在跑，或已取消但协程还没返回。没退出前不开新一轮，两轮不会抢同一组通道
+func (gs *GameScreen) aiBusy() bool {
+	if gs.aiRunning {
+		return true
+	}
+	select {
+	case <-gs.aiDone:
+		return false
+	default:
+		return true
+	}
+}
+
+// thinkingText AI 思考提示：已完成的深度与当前最佳着法
+func thinkingText(p *game.SearchProgress, elapsed time.Duration) string {
+	secs := elapsed.Seconds()
+	if p == nil {
+		return fmt.Sprintf("AI thinking... %.1fs", secs)
+	}
+	return fmt.Sprintf("AI thinking... depth %d, best (%d,%d)->(%d,%d), %.1fs",
+		p.Depth, p.Best.From.Q, p.Best.From.R, p.Best.To.Q, p.Best.To.R, secs)
+}
+
+// gameOverText 终局提示；被堵死结束时说明原因
+func gameOverText(st *game.GameState) string {
+	names := map[game.CellState]string{game.PlayerA: "Red", game.PlayerB: "White"}
+	msg := "Game over: draw"
+	if w, ok := names[st.Winner]; ok {
+		msg = "Game over: " + w + " wins"
+	}
+	if b, ok := names[st.BlockedPlayer]; ok {
+		msg = b + " has no legal moves, empty cells go to " + names[game.Opponent(st.BlockedPlayer)] + ". " + msg
+	}
+	return msg
 }
 
 // Layout 定义窗口尺寸
@@ -683,16 +1102,6 @@ func (gs *GameScreen) Layout(outsideWidth, outsideHeight int) (int, int) {
 
 // return boardScale, originX, originY, tileW, tileH, vs
 func boardTransform(tileImg *ebiten.Image) (float64, float64, float64, int, int, float64) {
-	tileW := tileImg.Bounds().Dx()
-	tileH := tileImg.Bounds().Dy()
-	vs := float64(tileH) * math.Sqrt(3) / 2
-
-	cols, rows := 2*BoardRadius+1, 2*BoardRadius+1
-	boardW := float64(cols-1)*float64(tileW)*0.75 + float64(tileW)
-	boardH := vs*float64(rows-1) + float64(tileH)
-
-	scale := math.Min(float64(WindowWidth)/boardW, float64(WindowHeight)/boardH)
-	originX := (float64(WindowWidth) - boardW*scale) / 2
-	originY := (float64(WindowHeight) - boardH*scale) / 2
-	return scale, originX, originY, tileW, tileH, vs
+	scale, originX, originY, tileW, tileH, vs := getBoardTransform(tileImg)
+	return scale, originX, originY, int(tileW), int(tileH), vs
 }
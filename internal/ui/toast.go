@@ -0,0 +1,96 @@
+// File /ui/toast.go
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+
+	"hexxagon_go/internal/game"
+)
+
+// 不打断对局的底部提示条：显示几秒后淡出，不拦截任何输入。
+// 目前用于提醒 NN 后端回退到了 CPU——否则玩家只会觉得 AI 突然变慢；快棋超时代走也在这里说一声。
+const (
+	toastDuration = 10 * time.Second
+	toastFade     = time.Second
+)
+
+type toast struct {
+	text  string
+	until time.Time
+}
+
+var toastPixel *ebiten.Image
+
+// whitePixel 1x1 白点，缩放着色后画半透明底框
+func whitePixel() *ebiten.Image {
+	if toastPixel == nil {
+		toastPixel = ebiten.NewImage(1, 1)
+		toastPixel.Fill(color.White)
+	}
+	return toastPixel
+}
+
+func (gs *GameScreen) showToast(msg string, d time.Duration) {
+	gs.toast = &toast{text: msg, until: gs.now().Add(d)}
+}
+
+// pollBackendNotice 每帧非阻塞地看一眼后端初始化结果，结束后只判断一次
+func (gs *GameScreen) pollBackendNotice() {
+	if gs.backendNoticed || !gs.aiEnabled {
+		return
+	}
+	reason, fellBack, settled := game.CPUFallback()
+	if !settled {
+		return
+	}
+	gs.backendNoticed = true
+	if fellBack {
+		log.Printf("GPU acceleration unavailable: %s; using CPU", reason)
+		// basicfont 只有 ASCII，原因单独一行，免得整句超出窗口宽度
+		gs.showToast(fmt.Sprintf("GPU acceleration unavailable; using CPU - consider lowering difficulty\n%s", reason), toastDuration)
+	}
+}
+
+func (gs *GameScreen) drawToast(screen *ebiten.Image) {
+	t := gs.toast
+	if t == nil {
+		return
+	}
+	left := t.until.Sub(gs.now())
+	if left <= 0 {
+		gs.toast = nil
+		return
+	}
+	alpha := float32(1)
+	if left < toastFade {
+		alpha = float32(left) / float32(toastFade)
+	}
+	const pad, lineH = 8, 16
+	lines := strings.Split(t.text, "\n")
+	tw := 0
+	for _, ln := range lines {
+		tw = max(tw, text.BoundString(gs.fontFace, ln).Dx())
+	}
+	w, h := screen.Bounds().Dx(), screen.Bounds().Dy()
+	boxW, boxH := float64(tw+2*pad), float64(len(lines)*lineH+2*pad)
+	x, y := (float64(w)-boxW)/2, float64(h)-boxH-16
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(boxW, boxH)
+	op.GeoM.Translate(x, y)
+	op.ColorScale.ScaleWithColor(color.RGBA{0x30, 0x18, 0x18, 0xFF})
+	op.ColorScale.ScaleAlpha(0.85 * alpha)
+	screen.DrawImage(whitePixel(), op)
+
+	clr := color.NRGBA{0xFF, 0xD0, 0x80, uint8(255 * alpha)}
+	for i, ln := range lines {
+		text.Draw(screen, ln, gs.fontFace, int(x)+pad, int(y)+pad+(i+1)*lineH-4, clr)
+	}
+}
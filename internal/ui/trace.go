@@ -0,0 +1,88 @@
+// File /ui/trace.go
+package ui
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"hexxagon_go/internal/game"
+)
+
+// 回放时追踪一颗子的谱系：点一颗子，标出它自己、把它克隆出来的祖先、由它克隆出去的后代（都按棋子编号认，
+// 跳到哪里都跟得上）。被感染翻过来的子不算谱系。再点它或点空格取消；换盘时清掉。
+
+var (
+	traceSelfColor     = color.NRGBA{0xFF, 0xFF, 0x60, 0xC0}
+	traceAncestorColor = color.NRGBA{0xC0, 0x80, 0xFF, 0x90}
+	traceChildColor    = color.NRGBA{0x60, 0xD0, 0xFF, 0x90}
+)
+
+// pieceTree 按本盘规则走完前 n 手时的克隆谱系；编号与 replayTo(n) 的局面一致
+func (m ReplayMatch) pieceTree(n int) (game.PieceTree, error) {
+	tree := game.PieceTree{}
+	err := m.walk(n, func(b *game.Board, side game.CellState, mv game.Move) {
+		tree.Record(game.MoveEvents(b, side, mv))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// traceClick 回放里点了一下棋盘：点到子就追踪它，点到正在追踪的子或空处就取消
+func (gs *GameScreen) traceClick() {
+	mx, my := ebiten.CursorPosition()
+	c, ok := pixelToAxial(float64(mx), float64(my), gs.state.Board, gs.tileImage)
+	id := game.PieceID(0)
+	if ok {
+		id = gs.state.Board.PieceAt(c)
+	}
+	if id == gs.tracePiece {
+		id = 0
+	}
+	gs.tracePiece = id
+}
+
+// addLineageTrace 把追踪中的谱系叠到标注层
+func (gs *GameScreen) addLineageTrace(o *overlay) {
+	if gs.tracePiece == 0 {
+		return
+	}
+	b := gs.state.Board
+	ancestors := map[game.PieceID]bool{}
+	for _, id := range gs.pieceTree.Ancestors(gs.tracePiece)[1:] {
+		ancestors[id] = true
+	}
+	for i := 0; i < game.BoardN; i++ {
+		c := game.CoordOf[i]
+		switch id := b.PieceAt(c); {
+		case id == 0:
+		case id == gs.tracePiece:
+			o.Fill(c, traceSelfColor, 0.9)
+		case ancestors[id]:
+			o.Fill(c, traceAncestorColor, 0.8)
+		case gs.pieceTree.DescendsFrom(id, gs.tracePiece):
+			o.Fill(c, traceChildColor, 0.8)
+		}
+	}
+}
+
+// traceText 追踪中的提示行；没在追踪时为空
+func (gs *GameScreen) traceText() string {
+	if gs.tracePiece == 0 {
+		return ""
+	}
+	if _, ok := gs.state.Board.FindPiece(gs.tracePiece); !ok {
+		return fmt.Sprintf("Piece #%d is not on the board yet (click to clear)", gs.tracePiece)
+	}
+	gen := len(gs.pieceTree.Ancestors(gs.tracePiece)) - 1
+	kids := 0
+	for id := range gs.pieceTree {
+		if id != gs.tracePiece && gs.pieceTree.DescendsFrom(id, gs.tracePiece) {
+			kids++
+		}
+	}
+	return fmt.Sprintf("Piece #%d: generation %d, %d clones descend from it (yellow self, purple ancestors, blue descendants)", gs.tracePiece, gen, kids)
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"hexxagon_go/internal/game"
+)
+
+// 回放里点一颗子：它自己、克隆出它的祖先、由它克隆出去（哪怕后来跳走了）的后代分别标色
+func TestReplayTracesLineage(t *testing.T) {
+	hc := func(q, r int) game.HexCoord { return game.HexCoord{Q: q, R: r} }
+	steps := []game.Move{
+		{From: hc(4, 0), To: hc(3, 0)},   // A 克隆出 mid
+		{From: hc(-4, 0), To: hc(-3, 0)}, // B
+		{From: hc(3, 0), To: hc(2, 0)},   // mid 克隆出 kid
+		{From: hc(-3, 0), To: hc(-2, 0)}, // B
+		{From: hc(2, 0), To: hc(0, 1)},   // kid 跳走
+	}
+	m := ReplayMatch{Winner: "draw"}
+	for _, mv := range steps {
+		m.Steps = append(m.Steps, ReplayStep{Move: mv})
+	}
+	path := filepath.Join(t.TempDir(), "games.json")
+	if _, err := appendReplay(path, m); err != nil {
+		t.Fatal(err)
+	}
+	cfg := pvpConfig()
+	cfg.Mode = "replay"
+	cfg.ReplayFile = path
+	gs := newTestController(t, cfg).Screen()
+
+	gs.seekReplayKey(0, len(steps))
+	b := gs.state.Board
+	gs.tracePiece = b.PieceAt(hc(3, 0))
+	gs.buildOverlay()
+	got := map[game.HexCoord]any{}
+	for _, f := range gs.overlay.fills {
+		got[f.c] = f.clr
+	}
+	want := map[game.HexCoord]any{hc(3, 0): traceSelfColor, hc(4, 0): traceAncestorColor, hc(0, 1): traceChildColor}
+	if len(got) != len(want) {
+		t.Fatalf("标色格子 %v，应为 %v", got, want)
+	}
+	for c, clr := range want {
+		if got[c] != clr {
+			t.Fatalf("%v 标成 %v，应为 %v", c, got[c], clr)
+		}
+	}
+	if msg := gs.traceText(); !strings.Contains(msg, "generation 1, 1 clones") {
+		t.Fatalf("提示行: %q", msg)
+	}
+
+	// 同一盘里往回退，编号照旧，只是那颗子还没出生
+	gs.seekReplayKey(0, 0)
+	if gs.tracePiece == 0 || !strings.Contains(gs.traceText(), "not on the board") {
+		t.Fatalf("后退后仍应追踪同一颗子: %q", gs.traceText())
+	}
+	gs.seekReplayKey(0, len(steps))
+	if c, ok := gs.state.Board.FindPiece(gs.pieceTree.Ancestors(gs.tracePiece)[0]); !ok || c != hc(3, 0) {
+		t.Fatal("重放后编号应与第一次一致")
+	}
+}